@@ -7,12 +7,18 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
 	"github.com/MitulShah1/expense-tracker-bot/internal/bot"
 	"github.com/MitulShah1/expense-tracker-bot/internal/config"
 	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
 	"github.com/MitulShah1/expense-tracker-bot/internal/health"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ocr"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ratelimit"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
 )
 
 // App represents the main application
@@ -58,8 +64,67 @@ func (a *App) Initialize(ctx context.Context) error {
 	}
 	a.database = dbStorage
 
+	// Initialize the embedder used for semantic search
+	embedder, err := embedding.New(embedding.Config{
+		Provider: cfg.EmbeddingProvider,
+		URL:      cfg.EmbeddingURL,
+		APIKey:   cfg.EmbeddingAPIKey,
+		Model:    cfg.EmbeddingModel,
+		Timeout:  cfg.EmbeddingTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+
+	// Initialize the blob store receipt attachments are kept in
+	blobs, err := blobstore.New(blobstore.Config{
+		Provider:        cfg.BlobStore,
+		Dir:             cfg.BlobDir,
+		Endpoint:        cfg.S3Endpoint,
+		Bucket:          cfg.S3Bucket,
+		Region:          cfg.S3Region,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+		Timeout:         cfg.S3Timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize blob store: %w", err)
+	}
+
+	// Initialize receipt OCR, which is optional: without it receipt photos are only attached
+	scanner, err := ocr.New(ocr.Config{
+		Provider:  cfg.OCRProvider,
+		Path:      cfg.TesseractPath,
+		Languages: cfg.OCRLanguages,
+		Timeout:   cfg.OCRTimeout,
+	})
+	if errors.Is(err, ocr.ErrUnavailable) {
+		loggerLog.Info(ctx, "Receipt OCR disabled", logger.ErrorField(err))
+	} else if err != nil {
+		return fmt.Errorf("failed to initialize receipt OCR: %w", err)
+	}
+
+	// Initialize the store conversations are kept in between updates
+	states, err := statestore.New(statestore.Config{
+		Provider: cfg.StateStore,
+		TTL:      cfg.StateTTL,
+	}, dbStorage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	// Per-user rate limits, with the ban window and idle timeout left at their defaults
+	limits := ratelimit.DefaultConfig()
+	limits.Limits = map[string]ratelimit.Limit{
+		ratelimit.ClassDefault: cfg.RateLimitDefault,
+		ratelimit.ClassHeavy:   cfg.RateLimitHeavy,
+		ratelimit.ClassUpload:  cfg.RateLimitUpload,
+	}
+	limits.BanAfter = cfg.RateLimitBanAfter
+	limits.BanDuration = cfg.RateLimitBanDuration
+
 	// Initialize bot
-	botInstance, err := bot.NewBot(ctx, cfg.TelegramToken, dbStorage, loggerLog)
+	botInstance, err := bot.NewBot(ctx, cfg.TelegramToken, dbStorage, loggerLog, embedder, blobs, scanner, states, ratelimit.New(limits))
 	if err != nil {
 		return fmt.Errorf("failed to create bot: %w", err)
 	}
@@ -83,6 +148,15 @@ func (a *App) Start(ctx context.Context) error {
 		return errors.New("bot stopped with error: bot not initialized")
 	}
 
+	// In webhook mode Telegram posts updates to the health check server
+	webhook := a.config != nil && a.config.UpdateMode == config.UpdateModeWebhook
+	if webhook {
+		if a.healthChecker == nil {
+			return errors.New("bot stopped with error: webhook mode needs the health check server")
+		}
+		a.healthChecker.Handle(a.config.WebhookPath(), a.bot.WebhookHandler(a.config.WebhookSecret))
+	}
+
 	// Start health checker
 	if a.healthChecker != nil {
 		port := os.Getenv("PORT")
@@ -94,7 +168,21 @@ func (a *App) Start(ctx context.Context) error {
 		}
 	}
 
+	// Start background jobs
+	schedulerInterval := time.Minute
+	if a.config != nil {
+		schedulerInterval = a.config.SchedulerInterval
+	}
+	a.bot.StartScheduler(ctx, schedulerInterval)
+	a.bot.StartEmbeddingWorker(ctx)
+
 	// Start bot
+	if webhook {
+		if err := a.bot.StartWebhook(ctx, a.config.WebhookURL, a.config.WebhookSecret); err != nil {
+			return fmt.Errorf("bot stopped with error: %w", err)
+		}
+		return nil
+	}
 	if err := a.bot.Start(ctx); err != nil {
 		return fmt.Errorf("bot stopped with error: %w", err)
 	}
@@ -0,0 +1,67 @@
+// Package blobstore keeps files such as receipt photos outside the database.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Supported blob store providers
+const (
+	ProviderLocal = "local"
+	ProviderS3    = "s3"
+)
+
+// ErrNotFound is returned when no blob has the requested key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key. Keys are slash-separated relative paths such as
+// "receipts/42/7/abc.jpg"; the same key always refers to the same blob across stores.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes a blob; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects and configures a blob store
+type Config struct {
+	Provider        string        // local or s3
+	Dir             string        // Root directory of the local store
+	Endpoint        string        // S3-compatible endpoint, e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+	Bucket          string        // Bucket the S3 store keeps blobs in
+	Region          string        // Region requests are signed for
+	AccessKeyID     string        // Access key of the S3 credentials
+	SecretAccessKey string        // Secret key of the S3 credentials
+	Timeout         time.Duration // Request timeout for the S3 API
+}
+
+// New creates the blob store selected by the config
+func New(cfg Config) (Store, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderLocal:
+		if cfg.Dir == "" {
+			return nil, errors.New("the local blob store needs a directory")
+		}
+		return NewLocalStore(cfg.Dir)
+	case ProviderS3:
+		if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, errors.New("the s3 blob store needs an endpoint, a bucket and credentials")
+		}
+		return NewS3Store(cfg.Endpoint, cfg.Bucket, cfg.Region, cfg.AccessKeyID, cfg.SecretAccessKey, cfg.Timeout)
+	}
+	return nil, fmt.Errorf("unknown blob store provider %q", cfg.Provider)
+}
+
+// validateKey rejects keys that are empty or could escape the store, such as "../x" or "/etc/passwd"
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
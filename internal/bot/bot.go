@@ -1,22 +1,31 @@
 package bot
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
 	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ocr"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ratelimit"
 	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 )
 
 // BotAPIInterface defines the methods used from tgbotapi.BotAPI
@@ -24,25 +33,41 @@ import (
 type BotAPIInterface interface {
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
 	GetUpdatesChan(u tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	GetFileDirectURL(fileID string) (string, error)
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
 }
 
 // Bot represents the Telegram bot
 type Bot struct {
-	api    BotAPIInterface
-	db     database.Storage
-	logger logger.Logger
+	api      BotAPIInterface
+	db       database.Storage
+	logger   logger.Logger
+	username string         // The bot's @username, which group chat members address it by
+	mention  *regexp.Regexp // Matches mentions of username, from mentionPattern
 	// Services
-	expenseService  *services.ExpenseService
-	categoryService *services.CategoryService
-	userService     *services.UserService
-	vectorService   services.VectorServiceInterface
-	states          map[int64]*models.UserState
-	// Add new fields for state management
-	stateTimeout  time.Duration
-	stateMutex    sync.RWMutex
-	cleanupTicker *time.Ticker
-	// Add rate limiting
-	rateLimiter *rate.Limiter
+	expenseService    *services.ExpenseService
+	categoryService   *services.CategoryService
+	userService       *services.UserService
+	vectorService     services.VectorServiceInterface
+	budgetService     *services.BudgetService
+	recurringService  *services.RecurringExpenseService
+	reminderService   *services.ReminderService
+	settingsService   *services.SettingsService
+	currencyService   *services.CurrencyService
+	exportService     *services.ExportService
+	importService     *services.ImportService
+	vehicleService    *services.VehicleService
+	ledgerService     *services.LedgerService
+	splitService      *services.SplitService
+	attachmentService *services.AttachmentService
+	historyService    *services.HistoryService
+	embeddingWorker   *services.EmbeddingWorker
+	scanner           ocr.OCR          // Reads receipt photos into expenses, nil when OCR is not available
+	stateStore        statestore.Store // Conversations between updates
+	blobs             blobstore.Store  // Uploads conversations still need, staged outside the state
+	cleanupTicker     *time.Ticker
+	// Per-user rate limits; nil lets every request through
+	rateLimiter *ratelimit.Limiter
 	// Add metrics
 	metrics struct {
 		messageCount   int64
@@ -51,46 +76,89 @@ type Bot struct {
 		expenseCount   int64
 		activeUsers    int64
 		lastUpdateTime time.Time
+		latency        map[string]*latencyStats // Processing time by command, button or message
 	}
 	metricsMutex sync.RWMutex
+	// Processes updates concurrently, in order for each conversation; nil handles them right away
+	dispatcher *dispatcher
+}
+
+// latencyStats sums up how long updates of one kind took to process
+type latencyStats struct {
+	count int64
+	total time.Duration
+	max   time.Duration
 }
 
 // NewBot creates a new bot instance
-func NewBot(ctx context.Context, token string, dbClient database.Storage, logger logger.Logger) (*Bot, error) {
+func NewBot(ctx context.Context, token string, dbClient database.Storage, logger logger.Logger, embedder embedding.Embedder, blobs blobstore.Store, scanner ocr.OCR, states statestore.Store, limiter *ratelimit.Limiter) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 
 	// Initialize services
-	expenseService := services.NewExpenseService(dbClient, logger)
-	categoryService := services.NewCategoryService(dbClient, logger)
+	vectorService := services.NewVectorService(dbClient, logger, embedder)
+	categoryService := services.NewCategoryService(dbClient, logger, vectorService)
+	vectorService.SetCategoryService(categoryService)
+	currencyService := services.NewCurrencyService(dbClient, logger)
+	ledgerService := services.NewLedgerService(dbClient, logger, currencyService)
+	expenseService := services.NewExpenseService(dbClient, logger, vectorService, currencyService, categoryService, ledgerService)
 	userService := services.NewUserService(dbClient, logger)
-	vectorService := services.NewVectorService(dbClient, logger)
+	budgetService := services.NewBudgetService(dbClient, logger, categoryService)
+	recurringService := services.NewRecurringExpenseService(dbClient, logger, expenseService, categoryService)
+	reminderService := services.NewReminderService(dbClient, logger)
+	settingsService := services.NewSettingsService(dbClient, logger)
+	exportService := services.NewExportService(dbClient, logger)
+	importService := services.NewImportService(dbClient, logger, vectorService, currencyService, categoryService)
+	vehicleService := services.NewVehicleService(dbClient, logger, currencyService)
+	splitService := services.NewSplitService(dbClient, logger, currencyService, ledgerService)
+	attachmentService := services.NewAttachmentService(dbClient, logger, blobs, ledgerService)
+	historyService := services.NewHistoryService(dbClient, logger, ledgerService, categoryService, expenseService)
+	embeddingWorker := services.NewEmbeddingWorker(dbClient, logger, vectorService, services.DefaultEmbeddingWorkerConfig())
 
 	bot := &Bot{
-		api:             api, // Use the real API here
-		db:              dbClient,
-		logger:          logger,
-		expenseService:  expenseService,
-		categoryService: categoryService,
-		userService:     userService,
-		vectorService:   vectorService,
-		states:          make(map[int64]*models.UserState),
-		stateTimeout:    30 * time.Minute,                                      // Default timeout of 30 minutes
-		rateLimiter:     rate.NewLimiter(rate.Every(100*time.Millisecond), 10), // 10 requests per second
+		api:               instrumentedAPI{api}, // Use the real API here, counting failed requests
+		username:          api.Self.UserName,
+		mention:           mentionPattern(api.Self.UserName),
+		db:                dbClient,
+		logger:            logger,
+		expenseService:    expenseService,
+		categoryService:   categoryService,
+		userService:       userService,
+		vectorService:     vectorService,
+		budgetService:     budgetService,
+		recurringService:  recurringService,
+		reminderService:   reminderService,
+		settingsService:   settingsService,
+		currencyService:   currencyService,
+		exportService:     exportService,
+		importService:     importService,
+		vehicleService:    vehicleService,
+		ledgerService:     ledgerService,
+		splitService:      splitService,
+		attachmentService: attachmentService,
+		historyService:    historyService,
+		embeddingWorker:   embeddingWorker,
+		scanner:           scanner,
+		stateStore:        states,
+		blobs:             blobs,
+		rateLimiter:       limiter,
 	}
 
 	// Initialize metrics
 	bot.metrics.lastUpdateTime = time.Now()
 
+	bot.dispatcher = newDispatcher(DefaultDispatcherConfig(), bot.processUpdate)
+	bot.registerMetrics()
+
 	// Start cleanup routine
 	bot.startCleanupRoutine(ctx)
 
 	return bot, nil
 }
 
-// HandleUpdate processes incoming updates from Telegram
+// HandleUpdate processes an update from Telegram, received by long polling or the webhook
 func (b *Bot) HandleUpdate(ctx context.Context, update *tgbotapi.Update) error {
 	// Create context with request ID
 	reqCtx := context.WithValue(ctx, logger.RequestIDKey, fmt.Sprintf("update_%d", update.UpdateID))
@@ -105,23 +173,7 @@ func (b *Bot) HandleUpdate(ctx context.Context, update *tgbotapi.Update) error {
 		return nil
 	}
 
-	// Get or create user state
-	state := b.getState(update.Message.Chat.ID)
-	if state == nil {
-		state = models.NewUserState()
-		b.setState(update.Message.Chat.ID, state)
-	}
-
-	// Handle commands
-	if update.Message.IsCommand() {
-		return b.handleCommand(reqCtx, update.Message)
-	}
-
-	// Handle state-based messages
-	if state.Step == models.StepStart {
-		return b.sendWelcome(reqCtx, update.Message)
-	}
-	return b.sendMessage(reqCtx, update.Message.Chat.ID, "Please use one of the available commands.")
+	return b.handleMessage(reqCtx, update.Message)
 }
 
 // GetMetrics returns the current metrics
@@ -129,6 +181,20 @@ func (b *Bot) GetMetrics() map[string]any {
 	b.metricsMutex.RLock()
 	defer b.metricsMutex.RUnlock()
 
+	latency := make(map[string]any, len(b.metrics.latency))
+	for kind, stats := range b.metrics.latency {
+		latency[kind] = map[string]any{
+			"count":  stats.count,
+			"avg_ms": stats.total.Milliseconds() / stats.count,
+			"max_ms": stats.max.Milliseconds(),
+		}
+	}
+
+	var queueDepth int64
+	if b.dispatcher != nil {
+		queueDepth = b.dispatcher.queueDepth()
+	}
+
 	return map[string]any{
 		"message_count":    b.metrics.messageCount,
 		"command_count":    b.metrics.commandCount,
@@ -136,43 +202,35 @@ func (b *Bot) GetMetrics() map[string]any {
 		"expense_count":    b.metrics.expenseCount,
 		"active_users":     b.metrics.activeUsers,
 		"last_update_time": b.metrics.lastUpdateTime,
+		"queue_depth":      queueDepth,
+		"update_latency":   latency,
 	}
 }
 
-// Start starts the bot
+// Start starts the bot, receiving updates by long polling until ctx is done
 func (b *Bot) Start(ctx context.Context) error {
 	b.logger.Info(ctx, "Starting bot...")
 
+	// Telegram does not answer long polling while a webhook is set, e.g. after running in webhook mode
+	if _, err := b.api.MakeRequest("deleteWebhook", nil); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := b.api.GetUpdatesChan(u)
 
+	stopDispatcher := b.startDispatcher(ctx)
+	defer stopDispatcher()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case update := <-updates:
-			// Create context with request ID
-			reqCtx := context.WithValue(ctx, logger.RequestIDKey, fmt.Sprintf("bot_%d", update.UpdateID))
-
-			// Handle callback queries
-			if update.CallbackQuery != nil {
-				if err := b.handleCallbackQuery(reqCtx, update.CallbackQuery); err != nil {
-					b.logger.Error(reqCtx, "Failed to handle callback query", logger.ErrorField(err))
-				}
-				continue
-			}
-
-			// Handle messages
-			if update.Message == nil {
-				continue
-			}
-
-			// Handle message
-			if err := b.handleMessage(reqCtx, update.Message); err != nil {
-				b.logger.Error(reqCtx, "Failed to handle message", logger.ErrorField(err))
-			}
+			// Errors are logged; the update is dropped only when the bot is stopping
+			_ = b.dispatchUpdate(ctx, &update)
 		}
 	}
 }
@@ -182,36 +240,211 @@ func (b *Bot) startCleanupRoutine(ctx context.Context) {
 	b.cleanupTicker = time.NewTicker(5 * time.Minute)
 	go func() {
 		for range b.cleanupTicker.C {
-			b.cleanupExpiredStates(ctx)
+			b.purgeExpiredStates(ctx)
+			b.evictRateLimits(ctx)
 		}
 	}()
 }
 
-func (b *Bot) cleanupExpiredStates(ctx context.Context) {
-	b.stateMutex.Lock()
-	defer b.stateMutex.Unlock()
+// purgeExpiredStates removes conversations that have been idle for longer than the state store's TTL
+func (b *Bot) purgeExpiredStates(ctx context.Context) {
+	purged, err := b.stateStore.DeleteExpired(ctx)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to purge expired states", logger.ErrorField(err))
+		return
+	}
+	if purged > 0 {
+		b.logger.Info(ctx, "Cleaned up expired states", zap.Int("count", purged))
+	}
+}
 
-	now := time.Now()
-	for userID, state := range b.states {
-		if now.Sub(state.LastActivity) > b.stateTimeout {
-			delete(b.states, userID)
-			b.logger.Info(ctx, "Cleaned up expired state",
-				zap.Int64("user_id", userID))
+// stateKey identifies a conversation with one user in one chat, so members of a group chat
+// each have their own state. In private chats both IDs are the same.
+type stateKey struct {
+	chatID int64
+	userID int64
+}
+
+type stateSessionKeyType struct{}
+
+// stateSessionKey is the context key for the state session of the update being handled
+var stateSessionKey = stateSessionKeyType{}
+
+// stateRefreshInterval is how often the activity of a conversation that has not changed is
+// saved, so it does not expire while the user is still answering
+const stateRefreshInterval = time.Minute
+
+// stateSession holds the conversation of the user an update is from while the update is being
+// handled. Stores return copies and handlers change the state in place, so it is loaded from the
+// store once and saved back once the update has been handled, if it changed.
+type stateSession struct {
+	key     stateKey
+	state   *models.UserState
+	loaded  []byte   // The state as loaded, to tell whether handlers changed it in place
+	staged  []string // Keys of the uploads staged by the state as loaded
+	fetched bool
+	dirty   bool // Set by setState
+	cleared bool
+}
+
+// openState starts the state session of an update from userID in chatID. The returned function
+// saves the conversation to the state store and must be called once the update is handled.
+func (b *Bot) openState(ctx context.Context, chatID, userID int64) (context.Context, func()) {
+	session := &stateSession{key: stateKey{chatID: chatID, userID: userID}}
+	return context.WithValue(ctx, stateSessionKey, session), func() {
+		b.closeState(ctx, session)
+	}
+}
+
+// closeState saves the conversation of a state session when it needs saving, or deletes it when
+// it was cleared. Uploads the conversation no longer refers to are then deleted.
+func (b *Bot) closeState(ctx context.Context, session *stateSession) {
+	var err error
+	switch {
+	case session.state != nil && session.needsSaving():
+		err = b.stateStore.Set(ctx, session.key.chatID, session.key.userID, session.state)
+	case session.cleared:
+		err = b.stateStore.Delete(ctx, session.key.chatID, session.key.userID)
+	}
+	if err != nil {
+		b.logger.Error(ctx, "Failed to save state", logger.ErrorField(err),
+			zap.Int64("chat_id", session.key.chatID),
+			zap.Int64("user_id", session.key.userID))
+		return
+	}
+
+	kept := stagedUploads(session.state)
+	for _, key := range session.staged {
+		if !slices.Contains(kept, key) {
+			b.deleteUpload(ctx, key)
 		}
 	}
 }
 
-func (b *Bot) getState(userID int64) *models.UserState {
-	b.stateMutex.RLock()
-	defer b.stateMutex.RUnlock()
-	return b.states[userID]
+// needsSaving reports whether the state was set or changed in place, which counts as activity,
+// or its activity has not been saved for stateRefreshInterval
+func (s *stateSession) needsSaving() bool {
+	if s.dirty {
+		return true
+	}
+	if data, err := json.Marshal(s.state); err != nil || !bytes.Equal(data, s.loaded) {
+		s.state.UpdateActivity()
+		return true
+	}
+	if time.Since(s.state.LastActivity) >= stateRefreshInterval {
+		s.state.UpdateActivity()
+		return true
+	}
+	return false
+}
+
+// session returns the state session of the update being handled if it is for the conversation
+// with userID in chatID
+func (b *Bot) session(ctx context.Context, chatID, userID int64) *stateSession {
+	session, ok := ctx.Value(stateSessionKey).(*stateSession)
+	if !ok || session.key != (stateKey{chatID: chatID, userID: userID}) {
+		return nil
+	}
+	return session
+}
+
+// loadState reads a conversation from the state store. A conversation that cannot be read is
+// treated as none, so the user starts over rather than getting stuck.
+func (b *Bot) loadState(ctx context.Context, chatID, userID int64) *models.UserState {
+	state, err := b.stateStore.Get(ctx, chatID, userID)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to load state", logger.ErrorField(err),
+			zap.Int64("chat_id", chatID),
+			zap.Int64("user_id", userID))
+		return nil
+	}
+	return state
 }
 
-func (b *Bot) setState(userID int64, state *models.UserState) {
-	b.stateMutex.Lock()
-	defer b.stateMutex.Unlock()
+func (b *Bot) getState(ctx context.Context, chatID, userID int64) *models.UserState {
+	session := b.session(ctx, chatID, userID)
+	if session == nil {
+		return b.loadState(ctx, chatID, userID)
+	}
+	if !session.fetched {
+		session.state = b.loadState(ctx, chatID, userID)
+		if session.state != nil {
+			session.loaded, _ = json.Marshal(session.state)
+		}
+		session.staged = stagedUploads(session.state)
+		session.fetched = true
+	}
+	return session.state
+}
+
+func (b *Bot) setState(ctx context.Context, chatID, userID int64, state *models.UserState) {
 	state.LastActivity = time.Now()
-	b.states[userID] = state
+	if session := b.session(ctx, chatID, userID); session != nil {
+		session.state, session.fetched, session.dirty, session.cleared = state, true, true, false
+		return
+	}
+	if err := b.stateStore.Set(ctx, chatID, userID, state); err != nil {
+		b.logger.Error(ctx, "Failed to save state", logger.ErrorField(err))
+	}
+}
+
+func (b *Bot) clearState(ctx context.Context, chatID, userID int64) {
+	if session := b.session(ctx, chatID, userID); session != nil {
+		session.state, session.fetched, session.dirty, session.cleared = nil, true, false, true
+		return
+	}
+	if err := b.stateStore.Delete(ctx, chatID, userID); err != nil {
+		b.logger.Error(ctx, "Failed to delete state", logger.ErrorField(err))
+	}
+}
+
+// Names of the uploads a conversation stages in the blob store. Statements and receipt photos are
+// megabytes, too large to keep in the state, which is saved on every update.
+const (
+	stagedStatement = "statement.csv"
+	stagedReceipt   = "receipt"
+)
+
+// stageUpload keeps an upload the conversation with userID in chatID still needs in the blob
+// store and returns its key. Each conversation has one upload of each name; staging another
+// replaces it.
+func (b *Bot) stageUpload(ctx context.Context, chatID, userID int64, name string, data []byte) (string, error) {
+	key := fmt.Sprintf("staged/%d/%d/%s", chatID, userID, name)
+	if err := b.blobs.Put(ctx, key, data, http.DetectContentType(data)); err != nil {
+		return "", apperrors.NewInternalError("Failed to keep the upload", err)
+	}
+	return key, nil
+}
+
+// getUpload returns an upload staged by stageUpload
+func (b *Bot) getUpload(ctx context.Context, key string) ([]byte, error) {
+	data, err := b.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to read the upload", err)
+	}
+	return data, nil
+}
+
+// deleteUpload deletes a staged upload. Failures are logged; the upload is replaced the next time
+// the conversation stages one.
+func (b *Bot) deleteUpload(ctx context.Context, key string) {
+	if err := b.blobs.Delete(ctx, key); err != nil {
+		b.logger.Warn(ctx, "Failed to delete staged upload", logger.ErrorField(err), zap.String("key", key))
+	}
+}
+
+// stagedUploads returns the keys of the uploads a conversation state refers to
+func stagedUploads(state *models.UserState) []string {
+	if state == nil {
+		return nil
+	}
+	var keys []string
+	for _, key := range []string{state.ImportKey, state.ReceiptKey} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 func (b *Bot) incrementMetric(metric *int64) {
@@ -220,6 +453,33 @@ func (b *Bot) incrementMetric(metric *int64) {
 	*metric++
 }
 
+// maxLatencyKinds caps the kinds of updates latency is kept for, as users can send any command;
+// the rest are counted as "other"
+const maxLatencyKinds = 100
+
+// observeLatency records how long processing an update of a kind, such as "/add", took
+func (b *Bot) observeLatency(kind string, elapsed time.Duration) {
+	updateDuration.Observe(elapsed.Seconds(), kind)
+
+	b.metricsMutex.Lock()
+	defer b.metricsMutex.Unlock()
+
+	if b.metrics.latency == nil {
+		b.metrics.latency = make(map[string]*latencyStats)
+	}
+	if _, ok := b.metrics.latency[kind]; !ok && len(b.metrics.latency) >= maxLatencyKinds {
+		kind = "other"
+	}
+	stats := b.metrics.latency[kind]
+	if stats == nil {
+		stats = &latencyStats{}
+		b.metrics.latency[kind] = stats
+	}
+	stats.count++
+	stats.total += elapsed
+	stats.max = max(stats.max, elapsed)
+}
+
 func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) error {
 	// Update metrics
 	b.incrementMetric(&b.metrics.messageCount)
@@ -227,18 +487,32 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) erro
 	b.metrics.lastUpdateTime = time.Now()
 	b.metricsMutex.Unlock()
 
+	// Load the conversation once and save it back once the message is handled
+	ctx, saveState := b.openState(ctx, message.Chat.ID, message.From.ID)
+	defer saveState()
+
+	// In group chats, only answer what is meant for the bot, and mention who it answers
+	if isGroupChat(message.Chat) {
+		if !b.isForBot(message, b.getState(ctx, message.Chat.ID, message.From.ID)) {
+			return nil
+		}
+		ctx = withSubmitter(ctx, message.From)
+		if !message.IsCommand() {
+			message.Text = b.stripMention(message.Text)
+		}
+	}
+
 	// Check rate limit
-	if !b.rateLimiter.Allow() {
-		b.incrementMetric(&b.metrics.errorCount)
-		b.logger.Warn(ctx, "Rate limit exceeded", zap.Int64("user_id", message.From.ID))
-		return b.sendMessage(ctx, message.Chat.ID, "Too many requests. Please try again later.")
+	class := messageRateClass(message, b.getState(ctx, message.Chat.ID, message.From.ID))
+	if allowed, err := b.checkRateLimit(ctx, message.Chat.ID, message.From.ID, class); !allowed {
+		return err
 	}
 
 	// Get or create user state
-	state := b.getState(message.From.ID)
+	state := b.getState(ctx, message.Chat.ID, message.From.ID)
 	if state == nil {
 		state = models.NewUserState()
-		b.setState(message.From.ID, state)
+		b.setState(ctx, message.Chat.ID, message.From.ID, state)
 		b.metricsMutex.Lock()
 		b.metrics.activeUsers++
 		b.metricsMutex.Unlock()
@@ -247,6 +521,12 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) erro
 	// Update activity
 	state.UpdateActivity()
 
+	// Anything but a receipt photo or PDF ends attaching receipts
+	if state.Step == models.StepAttachReceipts && getReceiptFile(message) == nil {
+		state.Step = models.StepStart
+		state.AttachExpenseID = 0
+	}
+
 	// Special-case: if user is in StepNotes and sends /skip, treat as message, not command
 	if state.Step == models.StepNotes && message.Text == "/skip" {
 		return b.handleState(ctx, message, state)
@@ -282,10 +562,44 @@ func (b *Bot) handleCommand(ctx context.Context, message *tgbotapi.Message) erro
 		return b.handleDashboardCommand(ctx, message)
 	case "search":
 		return b.handleSearchCommand(ctx, message)
+	case "budget":
+		return b.handleBudgetCommand(ctx, message)
+	case "recurring":
+		return b.handleRecurringCommand(ctx, message)
+	case "reminders":
+		return b.handleReminderCommand(ctx, message)
+	case "settings":
+		return b.handleSettingsCommand(ctx, message)
+	case "rates":
+		return b.handleRatesCommand(ctx, message)
+	case "export":
+		return b.handleExportCommand(ctx, message)
+	case "import":
+		return b.handleImportCommand(ctx, message)
+	case "vehicle":
+		return b.handleVehicleCommand(ctx, message)
+	case "categories":
+		return b.handleCategoriesCommand(ctx, message)
+	case "ledger":
+		return b.handleLedgerCommand(ctx, message)
+	case "split":
+		return b.handleSplitCommand(ctx, message)
+	case "balances":
+		return b.handleBalancesCommand(ctx, message)
+	case "undo":
+		return b.handleUndoCommand(ctx, message)
+	case "history":
+		return b.handleHistoryCommand(ctx, message)
+	case "trash":
+		return b.handleTrashCommand(ctx, message)
 	case "cancel":
-		delete(b.states, message.Chat.ID)
+		b.clearState(ctx, message.Chat.ID, message.From.ID)
 		return b.sendMessage(ctx, message.Chat.ID, "Operation cancelled.")
 	default:
+		// Group chats may have other bots whose commands are not for us
+		if isGroupChat(message.Chat) && !b.commandForBot(message) {
+			return nil
+		}
 		return b.sendMessage(ctx, message.Chat.ID, "Unknown command. Use /help to see available commands.")
 	}
 }
@@ -313,8 +627,36 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 	}
 
 	switch state.Step {
-	case models.StepStart:
+	case models.StepStart, models.StepQuickAddConfirm:
+		// A receipt photo is read into an expense to confirm
+		if file := getReceiptFile(message); file != nil {
+			return b.handleReceiptScan(ctx, message, state, file)
+		}
+		// Free text such as "450 dining lunch" adds an expense in one line
+		if handled, err := b.handleQuickAdd(ctx, message, state); handled {
+			return err
+		}
 		return b.sendWelcome(ctx, message)
+	case models.StepAttachReceipts:
+		return b.handleReceiptUpload(ctx, message, state)
+	case models.StepBudgetAmount:
+		return b.handleBudgetAmount(ctx, message, state)
+	case models.StepBudgetLimits:
+		return b.handleBudgetLimits(ctx, message, state)
+	case models.StepRecurringDetails:
+		return b.handleRecurringDetails(ctx, message)
+	case models.StepReminderDetails:
+		return b.handleReminderDetails(ctx, message, state)
+	case models.StepRatesImport:
+		return b.handleRatesImport(ctx, message)
+	case models.StepImportFile:
+		return b.handleImportFile(ctx, message, state)
+	case models.StepImportMapping:
+		return b.handleImportMapping(ctx, message, state)
+	case models.StepImportConfirm:
+		return b.sendMessage(ctx, message.Chat.ID, "Use the buttons above to import the statement, or /cancel.")
+	case models.StepSplitDetails:
+		return b.handleSplitDetails(ctx, message, state)
 	case models.StepOdometer:
 		// Parse odometer reading using helper
 		odometer, err := b.parseFloatOrReply(ctx, message.Chat.ID, message.Text, "odometer reading")
@@ -323,7 +665,7 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 		}
 		state.TempExpense.Odometer = odometer
 		state.Step = models.StepPetrolPrice
-		return b.sendMessage(ctx, message.Chat.ID, "⛽ Please enter the petrol price per liter:")
+		return b.sendMessage(ctx, message.Chat.ID, fuelPricePrompt(state.FuelType))
 	case models.StepPetrolPrice:
 		// Parse petrol price using helper
 		price, err := b.parseFloatOrReply(ctx, message.Chat.ID, message.Text, "petrol price")
@@ -334,12 +676,10 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 		state.Step = models.StepTotalPrice
 		return b.sendMessage(ctx, message.Chat.ID, "💰 Please enter the total price:")
 	case models.StepTotalPrice:
-		// Parse total price using helper
-		total, err := b.parseFloatOrReply(ctx, message.Chat.ID, message.Text, "total price")
-		if err != nil {
+		// Parse total price, optionally in a foreign currency
+		if ok, err := b.setAmountOrReply(ctx, message, state.TempExpense, "total price"); !ok {
 			return err
 		}
-		state.TempExpense.TotalPrice = total
 		state.Step = models.StepNotes
 		return b.sendMessage(ctx, message.Chat.ID, "📝 Add any notes (or send /skip to skip):")
 	case models.StepNotes:
@@ -360,7 +700,7 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 		}
 
 		// Get category by name
-		category, err := b.categoryService.GetCategoryByName(ctx, state.TempExpense.CategoryName)
+		category, err := b.categoryService.GetCategoryByName(ctx, message.From.ID, state.TempExpense.CategoryName)
 		if err != nil {
 			b.logger.Error(ctx, "Failed to get category", logger.ErrorField(err))
 			return b.sendError(ctx, message.Chat.ID, err)
@@ -371,12 +711,22 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 
 		// Create expense object
 		expense := &models.Expense{
-			CategoryName: state.TempExpense.CategoryName,
-			TotalPrice:   state.TempExpense.TotalPrice,
-			Odometer:     state.TempExpense.Odometer,
-			PetrolPrice:  state.TempExpense.PetrolPrice,
-			Notes:        state.TempExpense.Notes,
-			Timestamp:    time.Now(),
+			CategoryName:   state.TempExpense.CategoryName,
+			TotalPrice:     state.TempExpense.TotalPrice,
+			Currency:       state.TempExpense.Currency,
+			OriginalAmount: state.TempExpense.OriginalAmount,
+			VehicleType:    state.TempExpense.VehicleType,
+			VehicleID:      state.TempExpense.VehicleID,
+			Odometer:       state.TempExpense.Odometer,
+			PetrolPrice:    state.TempExpense.PetrolPrice,
+			Notes:          state.TempExpense.Notes,
+			Timestamp:      time.Now(),
+		}
+
+		// Add it to the group chat's ledger, or the shared ledger the user picked with /ledger use
+		ledger, err := b.applyActiveLedger(ctx, message.Chat, message.From.ID, expense)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
 		}
 
 		// Save expense to database
@@ -385,11 +735,11 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 			return b.sendError(ctx, message.Chat.ID, err)
 		}
 
-		// Reset state
-		delete(b.states, message.Chat.ID)
+		// Photos and PDFs sent next are attached to the new expense as receipts
+		b.startAttachingReceipts(ctx, message.Chat.ID, message.From.ID, expense.ID)
 
 		// Send confirmation
-		return b.sendMessage(ctx, message.Chat.ID, "✅ Expense added successfully!")
+		return b.sendMessage(ctx, message.Chat.ID, expenseAddedMessage(ledger)+"\n\n"+receiptPrompt)
 	case models.StepEditOdometer:
 		// Parse odometer reading for editing using helper
 		odometer, err := b.parseFloatOrReply(ctx, message.Chat.ID, message.Text, "odometer reading")
@@ -400,10 +750,9 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 		state.Step = models.StepEditExpense
 
 		// Show updated expense and edit options
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s - %s: ₹%.2f\nOdometer: %.1f km\n\nSelect what to edit:",
-			state.TempExpense.Timestamp.Format("02 Jan 2006"),
-			state.TempExpense.CategoryName,
-			state.TempExpense.TotalPrice,
+		settings := b.getUserSettings(ctx, message.From.ID)
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s\nOdometer: %.1f km\n\nSelect what to edit:",
+			formatExpenseLine(state.TempExpense, settings),
 			state.TempExpense.Odometer))
 		msg.ReplyMarkup = GetEditFieldKeyboard()
 		_, err = b.api.Send(msg)
@@ -418,30 +767,27 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 		state.Step = models.StepEditExpense
 
 		// Show updated expense and edit options
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s - %s: ₹%.2f\nPetrol Price: ₹%.2f/L\n\nSelect what to edit:",
-			state.TempExpense.Timestamp.Format("02 Jan 2006"),
-			state.TempExpense.CategoryName,
-			state.TempExpense.TotalPrice,
-			state.TempExpense.PetrolPrice))
+		settings := b.getUserSettings(ctx, message.From.ID)
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s\nFuel Price: %s/%s\n\nSelect what to edit:",
+			formatExpenseLine(state.TempExpense, settings),
+			settings.FormatAmount(state.TempExpense.PetrolPrice),
+			b.expenseFuelType(ctx, message.From.ID, state.TempExpense).Unit()))
 		msg.ReplyMarkup = GetEditFieldKeyboard()
 		_, err = b.api.Send(msg)
 		return err
 	case models.StepEditTotalPrice:
-		// Parse total price for editing using helper
-		total, err := b.parseFloatOrReply(ctx, message.Chat.ID, message.Text, "total price")
-		if err != nil {
+		// Parse total price for editing, optionally in a foreign currency
+		if ok, err := b.setAmountOrReply(ctx, message, state.TempExpense, "total price"); !ok {
 			return err
 		}
-		state.TempExpense.TotalPrice = total
 		state.Step = models.StepEditExpense
 
 		// Show updated expense and edit options
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s - %s: ₹%.2f\n\nSelect what to edit:",
-			state.TempExpense.Timestamp.Format("02 Jan 2006"),
-			state.TempExpense.CategoryName,
-			state.TempExpense.TotalPrice))
+		settings := b.getUserSettings(ctx, message.From.ID)
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s\n\nSelect what to edit:",
+			formatExpenseLine(state.TempExpense, settings)))
 		msg.ReplyMarkup = GetEditFieldKeyboard()
-		_, err = b.api.Send(msg)
+		_, err := b.api.Send(msg)
 		return err
 	case models.StepEditNotes:
 		// Handle notes for editing
@@ -453,10 +799,9 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 		state.Step = models.StepEditExpense
 
 		// Show updated expense and edit options
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s - %s: ₹%.2f\nNotes: %s\n\nSelect what to edit:",
-			state.TempExpense.Timestamp.Format("02 Jan 2006"),
-			state.TempExpense.CategoryName,
-			state.TempExpense.TotalPrice,
+		settings := b.getUserSettings(ctx, message.From.ID)
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Updated expense:\n%s\nNotes: %s\n\nSelect what to edit:",
+			formatExpenseLine(state.TempExpense, settings),
 			state.TempExpense.Notes))
 		msg.ReplyMarkup = GetEditFieldKeyboard()
 		_, err := b.api.Send(msg)
@@ -468,6 +813,7 @@ func (b *Bot) handleState(ctx context.Context, message *tgbotapi.Message, state
 
 func (b *Bot) sendMessage(ctx context.Context, chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
+	mentionSubmitter(ctx, &msg)
 	if _, err := b.api.Send(msg); err != nil {
 		b.logger.Error(ctx, "Failed to send message", logger.ErrorField(err))
 		return err
@@ -476,6 +822,7 @@ func (b *Bot) sendMessage(ctx context.Context, chatID int64, text string) error
 }
 
 func (b *Bot) sendError(ctx context.Context, chatID int64, err error) error {
+	recordError(err)
 	b.logger.Error(ctx, "Error occurred", logger.ErrorField(err))
 	return b.sendMessage(ctx, chatID, fmt.Sprintf("An error occurred: %v", err))
 }
@@ -492,7 +839,7 @@ I can help you track your vehicle expenses. Here's what you can do:
 📊 Reports - Generate expense reports
 📈 Dashboard - View expense dashboard
 
-You can also use commands like /add, /list, /report, etc.
+You can also use commands like /add, /list, /report, etc., or just send a line like "450 dining lunch".
 
 Let's get started! Use the buttons below or type /add to record your first expense.`
 
@@ -510,27 +857,57 @@ func (b *Bot) sendHelp(ctx context.Context, message *tgbotapi.Message) error {
 /edit - Edit an existing expense
 /delete - Delete an expense
 /search - Search expenses using natural language
+/budget - Set budgets and track spending against them
+/recurring - Manage recurring expenses like rent, EMIs and subscriptions
+/reminders - Set daily or weekly reminders
+/settings - Choose currency, date format, language and notifications
+/rates - View, set or import exchange rates for foreign currency expenses
+/export - Download your expenses as CSV or XLSX, optionally for a date range
+/import - Import a bank statement CSV, e.g. /import hdfc
+/vehicle - Fuel efficiency and running costs per vehicle; /vehicle add, list and remove manage your vehicles
+/categories - List categories; add your own, group, rename, archive, restore or merge them
+/ledger - Share a ledger with your household or trip; create, join, use, report, members, role, leave, and link in a group chat
+/split [ledger] - Split a shared expense equally, by shares or by exact amounts
+/balances [ledger] - See who owes whom and record payments with /balances settle
+/undo - Undo your last add, edit or delete
+/history [id] - See every change to an expense and who made it
+/trash - Restore recently deleted expenses
 /help - Show this help message
 /cancel - Cancel current operation
 
 To add an expense:
 1. Use /add
 2. Select a category
-3. Enter vehicle type
+3. Pick your vehicle
 4. Enter odometer reading
-5. Enter petrol price
-6. Enter total price
+5. Enter the price per litre, or per kWh for an electric vehicle
+6. Enter total price, e.g. 450 or 45 EUR
 7. Add optional notes
+8. Send a photo or PDF of the receipt to attach it
+
+Or add an expense in one line, e.g.:
+450 dining lunch with team yesterday
+petrol 2000 car odo 45210 @104.5
+
+Or send a photo of a receipt: I read the total, date and merchant, and the litres and price per litre of fuel receipts, for you to confirm
 
 To edit or delete an expense:
 1. Use /edit or /delete
 2. Select the expense from the list
-3. Follow the prompts
+3. Follow the prompts; 📎 Receipt attaches a photo or PDF
+
+Tap 📎 receipt under /list or /search results to see an attached receipt again.
 
 To search expenses:
 1. Use /search
-2. Enter a natural language query
-3. View matching expenses`
+2. Enter a query, e.g. "fuel over 1000 last month"
+3. Dates, amounts, categories and car or bike filter the results
+4. Page through matching expenses
+
+In a group chat:
+1. Use /ledger create Home or /ledger link Home so expenses logged in the chat are shared
+2. Mention me or reply to me to log an expense, e.g. @bot 450 dining
+3. Commands work as usual; each member has their own conversation with me`
 	return b.sendMessage(ctx, message.Chat.ID, text)
 }
 
@@ -540,17 +917,30 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		return errors.New("invalid callback query")
 	}
 
+	if isGroupChat(callback.Message.Chat) {
+		ctx = withSubmitter(ctx, callback.From)
+	}
+
+	if allowed, err := b.checkRateLimit(ctx, callback.Message.Chat.ID, callback.From.ID, callbackRateClass(callback.Data)); !allowed {
+		return err
+	}
+
+	// Load the conversation once and save it back once the button press is handled
+	ctx, saveState := b.openState(ctx, callback.Message.Chat.ID, callback.From.ID)
+	defer saveState()
+
 	// Get or create user state
-	state := b.getState(callback.Message.Chat.ID)
+	state := b.getState(ctx, callback.Message.Chat.ID, callback.From.ID)
 	if state == nil {
 		state = models.NewUserState()
-		b.setState(callback.Message.Chat.ID, state)
+		b.setState(ctx, callback.Message.Chat.ID, callback.From.ID, state)
 	}
+	state.UpdateActivity()
 
 	// Initialize TempExpense if nil
 	if state.TempExpense == nil {
 		state.TempExpense = &models.Expense{
-			UserID: callback.Message.Chat.ID,
+			UserID: callback.From.ID,
 		}
 	}
 
@@ -561,7 +951,7 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 	case strings.HasPrefix(data, "group_"):
 		// Handle category group selection
 		groupName := strings.TrimPrefix(data, "group_")
-		categories, err := b.categoryService.GetCategoriesByGroup(ctx, groupName)
+		categories, err := b.categoryService.GetCategoriesByGroup(ctx, callback.From.ID, groupName)
 		if err != nil {
 			return b.sendError(ctx, callback.Message.Chat.ID, err)
 		}
@@ -577,7 +967,7 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 	case strings.HasPrefix(data, "category_"):
 		// Handle category selection
 		categoryName := strings.TrimPrefix(data, "category_")
-		category, err := b.categoryService.GetCategoryByName(ctx, categoryName)
+		category, err := b.categoryService.GetCategoryByName(ctx, callback.From.ID, categoryName)
 		if err != nil {
 			return b.sendError(ctx, callback.Message.Chat.ID, err)
 		}
@@ -589,22 +979,23 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		state.TempExpense.CategoryName = category.Name
 
 		if category.Group == "Vehicle" {
-			// Vehicle categories: Vehicle Type → Odometer/Petrol Price → Total Price → Notes
+			// Vehicle categories: Vehicle → Odometer/Fuel Price → Total Price → Notes
 			state.Step = models.StepVehicleType
 
-			// Create vehicle type keyboard
-			keyboard := tgbotapi.NewInlineKeyboardMarkup(
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("🚗 Car", "vehicle_CAR"),
-					tgbotapi.NewInlineKeyboardButtonData("🏍️ Bike", "vehicle_BIKE"),
-				),
-			)
+			vehicles, err := b.getVehicles(ctx, callback.From.ID)
+			if err != nil {
+				return b.sendError(ctx, callback.Message.Chat.ID, err)
+			}
 
+			prompt := "Select vehicle:"
+			if len(vehicles) == 0 {
+				prompt = "Select vehicle type (add named vehicles with /vehicle add):"
+			}
 			msg := tgbotapi.NewEditMessageTextAndMarkup(
 				callback.Message.Chat.ID,
 				callback.Message.MessageID,
-				fmt.Sprintf("Selected category: %s %s\nSelect vehicle type:", category.Emoji, category.Name),
-				keyboard,
+				fmt.Sprintf("Selected category: %s %s\n%s", category.Emoji, category.Name, prompt),
+				GetVehicleKeyboard(vehicles, "vehicle_"),
 			)
 			_, err = b.api.Send(msg)
 			return err
@@ -621,24 +1012,27 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		return err
 
 	case strings.HasPrefix(data, "vehicle_"):
-		// Handle vehicle type selection
-		vehicleType := strings.TrimPrefix(data, "vehicle_")
-		state.TempExpense.VehicleType = sql.NullString{String: vehicleType, Valid: true}
+		// Handle vehicle selection
+		vehicle, err := b.selectVehicle(ctx, callback.From.ID, state.TempExpense, strings.TrimPrefix(data, "vehicle_"))
+		if err != nil {
+			return b.sendError(ctx, callback.Message.Chat.ID, err)
+		}
+		state.FuelType = vehicle.FuelType
 
 		// Determine next step based on category
-		if state.TempExpense.CategoryName == "Petrol" {
-			// Petrol category: Vehicle Type → Odometer → Petrol Price → Total Price → Notes
+		if state.TempExpense.IsFuel() {
+			// Petrol and EV Charging: Vehicle → Odometer → Price per litre or kWh → Total Price → Notes
 			state.Step = models.StepOdometer
 			msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "🔢 Please enter the odometer reading (in km):")
 			msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 			_, err := b.api.Send(msg)
 			return err
 		}
-		// Other vehicle categories: Vehicle Type → Total Price → Notes
+		// Other vehicle categories: Vehicle → Total Price → Notes
 		state.Step = models.StepTotalPrice
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "💰 Please enter the total price:")
 		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
-		_, err := b.api.Send(msg)
+		_, err = b.api.Send(msg)
 		return err
 
 	case strings.HasPrefix(data, "edit_field_"):
@@ -647,29 +1041,31 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		switch field {
 		case "category":
 			state.Step = models.StepEditCategory
+			keyboard, err := b.categoryKeyboard(ctx, callback.From.ID)
+			if err != nil {
+				return b.sendError(ctx, callback.Message.Chat.ID, err)
+			}
 			msg := tgbotapi.NewEditMessageTextAndMarkup(
 				callback.Message.Chat.ID,
 				callback.Message.MessageID,
 				"Select new category:",
-				GetCategoryKeyboard(),
+				keyboard,
 			)
-			_, err := b.api.Send(msg)
+			_, err = b.api.Send(msg)
 			return err
 		case "vehicle":
 			state.Step = models.StepEditVehicleType
-			keyboard := tgbotapi.NewInlineKeyboardMarkup(
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("🚗 Car", "edit_vehicle_CAR"),
-					tgbotapi.NewInlineKeyboardButtonData("🏍️ Bike", "edit_vehicle_BIKE"),
-				),
-			)
+			vehicles, err := b.getVehicles(ctx, callback.From.ID)
+			if err != nil {
+				return b.sendError(ctx, callback.Message.Chat.ID, err)
+			}
 			msg := tgbotapi.NewEditMessageTextAndMarkup(
 				callback.Message.Chat.ID,
 				callback.Message.MessageID,
-				"Select new vehicle type:",
-				keyboard,
+				"Select new vehicle:",
+				GetVehicleKeyboard(vehicles, "edit_vehicle_"),
 			)
-			_, err := b.api.Send(msg)
+			_, err = b.api.Send(msg)
 			return err
 		case "odometer":
 			state.Step = models.StepEditOdometer
@@ -679,7 +1075,11 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 			return err
 		case "petrol":
 			state.Step = models.StepEditPetrolPrice
-			msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "⛽ Enter new petrol price per liter:")
+			prompt := "⛽ Enter new petrol price per liter:"
+			if state.TempExpense != nil && b.expenseFuelType(ctx, callback.From.ID, state.TempExpense) == models.FuelTypeElectric {
+				prompt = "🔌 Enter new charging price per kWh:"
+			}
+			msg := tgbotapi.NewMessage(callback.Message.Chat.ID, prompt)
 			msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 			_, err := b.api.Send(msg)
 			return err
@@ -695,27 +1095,35 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 			msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 			_, err := b.api.Send(msg)
 			return err
+		case "receipt":
+			// Quick-add expenses are not saved yet, so receipts are attached once they are
+			if state.TempExpense.ID == 0 {
+				return b.sendMessage(ctx, callback.Message.Chat.ID, "Save the expense first, then attach receipts to it.")
+			}
+			state.Step = models.StepAttachReceipts
+			state.AttachExpenseID = state.TempExpense.ID
+			return b.sendMessage(ctx, callback.Message.Chat.ID, "📎 Send a photo or PDF of the receipt to attach it.")
 		default:
 			return b.sendMessage(ctx, callback.Message.Chat.ID, "Invalid edit field selection.")
 		}
 
 	case strings.HasPrefix(data, "edit_vehicle_"):
-		// Handle vehicle type selection for editing
-		vehicleType := strings.TrimPrefix(data, "edit_vehicle_")
+		// Handle vehicle selection for editing
 		if state.TempExpense != nil {
-			state.TempExpense.VehicleType = sql.NullString{String: vehicleType, Valid: true}
+			vehicle, err := b.selectVehicle(ctx, callback.From.ID, state.TempExpense, strings.TrimPrefix(data, "edit_vehicle_"))
+			if err != nil {
+				return b.sendError(ctx, callback.Message.Chat.ID, err)
+			}
 			// Show updated expense and edit options
 			msg := tgbotapi.NewEditMessageTextAndMarkup(
 				callback.Message.Chat.ID,
 				callback.Message.MessageID,
-				fmt.Sprintf("Updated expense:\n%s - %s: ₹%.2f\nVehicle: %s\n\nSelect what to edit:",
-					state.TempExpense.Timestamp.Format("02 Jan 2006"),
-					state.TempExpense.CategoryName,
-					state.TempExpense.TotalPrice,
-					state.TempExpense.VehicleType.String),
+				fmt.Sprintf("Updated expense:\n%s\nVehicle: %s\n\nSelect what to edit:",
+					formatExpenseLine(state.TempExpense, b.getUserSettings(ctx, callback.From.ID)),
+					vehicleName(vehicle)),
 				GetEditFieldKeyboard(),
 			)
-			_, err := b.api.Send(msg)
+			_, err = b.api.Send(msg)
 			return err
 		}
 		return b.sendError(ctx, callback.Message.Chat.ID, errors.New("no expense to edit"))
@@ -726,14 +1134,19 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 			return b.sendMessage(ctx, callback.Message.Chat.ID, "No expense to save.")
 		}
 
+		// Quick-add expenses are not saved yet, so editing them ends by creating them
+		if state.TempExpense.ID == 0 {
+			return b.saveQuickAddExpense(ctx, callback, state)
+		}
+
 		// Update expense in database
-		if err := b.expenseService.UpdateExpense(ctx, state.TempExpense, callback.Message.Chat.ID); err != nil {
+		if err := b.expenseService.UpdateExpense(ctx, state.TempExpense, callback.From.ID); err != nil {
 			b.logger.Error(ctx, "Failed to update expense", logger.ErrorField(err))
 			return b.sendError(ctx, callback.Message.Chat.ID, err)
 		}
 
 		// Reset state
-		delete(b.states, callback.Message.Chat.ID)
+		b.clearState(ctx, callback.Message.Chat.ID, callback.From.ID)
 
 		// Send confirmation
 		msg := tgbotapi.NewEditMessageText(
@@ -745,7 +1158,7 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 
 	case data == "edit_cancel":
 		// Cancel editing
-		delete(b.states, callback.Message.Chat.ID)
+		b.clearState(ctx, callback.Message.Chat.ID, callback.From.ID)
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "❌ Edit cancelled.")
 		msg.ReplyMarkup = GetMainMenuKeyboard()
 		_, err := b.api.Send(msg)
@@ -769,7 +1182,7 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		}
 
 		// Check if user owns this expense using helper
-		if err := b.checkExpenseOwnership(ctx, callback.Message.Chat.ID, expenseToEdit); err != nil {
+		if err := b.checkExpenseOwnership(ctx, callback.From.ID, expenseToEdit); err != nil {
 			return b.sendMessage(ctx, callback.Message.Chat.ID, err.Error())
 		}
 
@@ -781,10 +1194,8 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		msg := tgbotapi.NewEditMessageTextAndMarkup(
 			callback.Message.Chat.ID,
 			callback.Message.MessageID,
-			fmt.Sprintf("Editing expense:\n%s - %s: ₹%.2f\n\nSelect what to edit:",
-				expenseToEdit.Timestamp.Format("02 Jan 2006"),
-				expenseToEdit.CategoryName,
-				expenseToEdit.TotalPrice),
+			fmt.Sprintf("Editing expense:\n%s\n\nSelect what to edit:",
+				formatExpenseLine(expenseToEdit, b.getUserSettings(ctx, callback.From.ID))),
 			GetEditFieldKeyboard(),
 		)
 		_, err = b.api.Send(msg)
@@ -808,7 +1219,7 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		}
 
 		// Check if user owns this expense using helper
-		if err := b.checkExpenseOwnership(ctx, callback.Message.Chat.ID, expenseToDelete); err != nil {
+		if err := b.checkExpenseOwnership(ctx, callback.From.ID, expenseToDelete); err != nil {
 			return b.sendMessage(ctx, callback.Message.Chat.ID, err.Error())
 		}
 
@@ -820,10 +1231,8 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		msg := tgbotapi.NewEditMessageTextAndMarkup(
 			callback.Message.Chat.ID,
 			callback.Message.MessageID,
-			fmt.Sprintf("Are you sure you want to delete this expense?\n\n%s - %s: ₹%.2f",
-				expenseToDelete.Timestamp.Format("02 Jan 2006"),
-				expenseToDelete.CategoryName,
-				expenseToDelete.TotalPrice),
+			fmt.Sprintf("Are you sure you want to delete this expense?\n\n%s",
+				formatExpenseLine(expenseToDelete, b.getUserSettings(ctx, callback.From.ID))),
 			GetConfirmationKeyboard(),
 		)
 		_, err = b.api.Send(msg)
@@ -836,31 +1245,83 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 		}
 
 		// Delete expense from database
-		if err := b.expenseService.DeleteExpense(ctx, state.DeleteExpense.ID, callback.Message.Chat.ID); err != nil {
+		if err := b.expenseService.DeleteExpense(ctx, state.DeleteExpense.ID, callback.From.ID); err != nil {
 			b.logger.Error(ctx, "Failed to delete expense", logger.ErrorField(err))
 			return b.sendError(ctx, callback.Message.Chat.ID, err)
 		}
 
 		// Reset state
-		delete(b.states, callback.Message.Chat.ID)
+		b.clearState(ctx, callback.Message.Chat.ID, callback.From.ID)
 
 		// Send confirmation
 		msg := tgbotapi.NewEditMessageText(
 			callback.Message.Chat.ID,
 			callback.Message.MessageID,
-			"✅ Expense deleted successfully!")
+			"✅ Expense deleted successfully! Use /undo to bring it back, or find it in /trash.")
 		_, err := b.api.Send(msg)
 		return err
 
+	case strings.HasPrefix(data, "budget_"):
+		// Handle budget management
+		return b.handleBudgetCallback(ctx, callback, state, strings.TrimPrefix(data, "budget_"))
+
+	case strings.HasPrefix(data, "recurring_"):
+		// Handle recurring expense management
+		return b.handleRecurringCallback(ctx, callback, state, strings.TrimPrefix(data, "recurring_"))
+
+	case strings.HasPrefix(data, "reminder_"):
+		// Handle reminder management
+		return b.handleReminderCallback(ctx, callback, state, strings.TrimPrefix(data, "reminder_"))
+
+	case strings.HasPrefix(data, "settings_"):
+		// Handle user settings
+		return b.handleSettingsCallback(ctx, callback, strings.TrimPrefix(data, "settings_"))
+
+	case strings.HasPrefix(data, "quick_"):
+		// Handle quick-add confirmation
+		return b.handleQuickAddCallback(ctx, callback, state, strings.TrimPrefix(data, "quick_"))
+
+	case strings.HasPrefix(data, "import_"):
+		// Handle bank statement import confirmation
+		return b.handleImportCallback(ctx, callback, state, strings.TrimPrefix(data, "import_"))
+
+	case strings.HasPrefix(data, "split_"):
+		// Handle shared expense splits
+		return b.handleSplitCallback(ctx, callback, state, strings.TrimPrefix(data, "split_"))
+
+	case strings.HasPrefix(data, "receipt_"):
+		// Handle re-sending a receipt from list or search results
+		return b.handleReceiptCallback(ctx, callback, strings.TrimPrefix(data, "receipt_"))
+
+	case strings.HasPrefix(data, "history_"):
+		// Handle showing the history of an expense picked from /history
+		return b.handleHistoryCallback(ctx, callback, strings.TrimPrefix(data, "history_"))
+
+	case strings.HasPrefix(data, "trash_"):
+		// Handle the trash of deleted expenses
+		return b.handleTrashCallback(ctx, callback, strings.TrimPrefix(data, "trash_"))
+
+	case data == "report_vehicle":
+		// Handle vehicle report
+		return b.sendVehicleReport(ctx, callback.Message.Chat.ID, callback.From.ID)
+
+	case strings.HasPrefix(data, "search_page_"):
+		// Handle search result pages
+		return b.handleSearchPageCallback(ctx, callback, state, strings.TrimPrefix(data, "search_page_"))
+
 	case data == "back_to_groups":
 		// Handle back to groups
+		keyboard, err := b.categoryKeyboard(ctx, callback.From.ID)
+		if err != nil {
+			return b.sendError(ctx, callback.Message.Chat.ID, err)
+		}
 		msg := tgbotapi.NewEditMessageTextAndMarkup(
 			callback.Message.Chat.ID,
 			callback.Message.MessageID,
 			"Select a category group:",
-			GetCategoryKeyboard(),
+			keyboard,
 		)
-		_, err := b.api.Send(msg)
+		_, err = b.api.Send(msg)
 		return err
 
 	case data == "back_to_main":
@@ -886,13 +1347,13 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Callba
 					msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "✅ Expense deleted successfully!")
 					msg.ReplyMarkup = GetMainMenuKeyboard()
 					_, err := b.api.Send(msg)
-					delete(b.states, callback.Message.Chat.ID)
+					b.clearState(ctx, callback.Message.Chat.ID, callback.From.ID)
 					return err
 				}
 			}
 		}
 		// Reset state and return to main menu
-		delete(b.states, callback.Message.Chat.ID)
+		b.clearState(ctx, callback.Message.Chat.ID, callback.From.ID)
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "Operation cancelled.")
 		msg.ReplyMarkup = GetMainMenuKeyboard()
 		_, err := b.api.Send(msg)
@@ -9,6 +9,7 @@ import (
 	"github.com/MitulShah1/expense-tracker-bot/internal/database"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,8 +25,7 @@ func TestBot_GetMetrics(t *testing.T) {
 			expenseService:  nil, // Not needed for this test
 			categoryService: nil, // Not needed for this test
 			userService:     nil, // Not needed for this test
-			states:          make(map[int64]*models.UserState),
-			stateTimeout:    30 * time.Minute,
+			stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
 			rateLimiter:     nil, // Not needed for this test
 		}
 
@@ -57,8 +57,7 @@ func TestBot_GetMetrics(t *testing.T) {
 			expenseService:  nil,
 			categoryService: nil,
 			userService:     nil,
-			states:          make(map[int64]*models.UserState),
-			stateTimeout:    30 * time.Minute,
+			stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
 			rateLimiter:     nil,
 		}
 
@@ -87,6 +86,7 @@ func TestBot_GetMetrics(t *testing.T) {
 
 func TestBot_StateManagement(t *testing.T) {
 	t.Run("should get and set state correctly", func(t *testing.T) {
+		ctx := context.Background()
 		mockDB := database.NewMockStorage()
 		mockLogger := logger.NewMockLogger()
 
@@ -97,8 +97,7 @@ func TestBot_StateManagement(t *testing.T) {
 			expenseService:  nil,
 			categoryService: nil,
 			userService:     nil,
-			states:          make(map[int64]*models.UserState),
-			stateTimeout:    30 * time.Minute,
+			stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
 			rateLimiter:     nil,
 		}
 
@@ -107,19 +106,20 @@ func TestBot_StateManagement(t *testing.T) {
 		state.Step = models.StepCategory
 
 		// Initially no state
-		retrievedState := bot.getState(userID)
+		retrievedState := bot.getState(ctx, userID, userID)
 		require.Nil(t, retrievedState)
 
 		// Set state
-		bot.setState(userID, state)
+		bot.setState(ctx, userID, userID, state)
 
 		// Retrieve state
-		retrievedState = bot.getState(userID)
+		retrievedState = bot.getState(ctx, userID, userID)
 		require.NotNil(t, retrievedState)
 		require.Equal(t, models.StepCategory, retrievedState.Step)
 	})
 
 	t.Run("should handle concurrent state access", func(t *testing.T) {
+		ctx := context.Background()
 		mockDB := database.NewMockStorage()
 		mockLogger := logger.NewMockLogger()
 
@@ -130,8 +130,7 @@ func TestBot_StateManagement(t *testing.T) {
 			expenseService:  nil,
 			categoryService: nil,
 			userService:     nil,
-			states:          make(map[int64]*models.UserState),
-			stateTimeout:    30 * time.Minute,
+			stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
 			rateLimiter:     nil,
 		}
 
@@ -143,8 +142,8 @@ func TestBot_StateManagement(t *testing.T) {
 			go func() {
 				state := models.NewUserState()
 				state.Step = models.StepCategory
-				bot.setState(userID, state)
-				bot.getState(userID)
+				bot.setState(ctx, userID, userID, state)
+				bot.getState(ctx, userID, userID)
 				done <- true
 			}()
 		}
@@ -155,7 +154,7 @@ func TestBot_StateManagement(t *testing.T) {
 		}
 
 		// Verify state exists
-		retrievedState := bot.getState(userID)
+		retrievedState := bot.getState(ctx, userID, userID)
 		require.NotNil(t, retrievedState)
 	})
 }
@@ -173,8 +172,7 @@ func TestBot_CleanupRoutine(t *testing.T) {
 			expenseService:  nil,
 			categoryService: nil,
 			userService:     nil,
-			states:          make(map[int64]*models.UserState),
-			stateTimeout:    30 * time.Minute,
+			stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
 			rateLimiter:     nil,
 		}
 
@@ -184,20 +182,19 @@ func TestBot_CleanupRoutine(t *testing.T) {
 		state.LastActivity = time.Now().Add(-35 * time.Minute) // Expired
 
 		// Set state directly to avoid LastActivity being updated
-		bot.stateMutex.Lock()
-		bot.states[userID] = state
-		bot.stateMutex.Unlock()
+		require.NoError(t, bot.stateStore.Set(ctx, userID, userID, state))
 
-		// Verify state exists
-		retrievedState := bot.getState(userID)
-		require.NotNil(t, retrievedState)
+		// Expired conversations are over even before they are purged
+		retrievedState := bot.getState(ctx, userID, userID)
+		require.Nil(t, retrievedState)
 
 		// Run cleanup
-		bot.cleanupExpiredStates(ctx)
+		bot.purgeExpiredStates(ctx)
 
 		// Verify state was cleaned up
-		retrievedState = bot.getState(userID)
-		require.Nil(t, retrievedState)
+		purged, err := bot.stateStore.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.Zero(t, purged)
 	})
 
 	t.Run("should not cleanup active states", func(t *testing.T) {
@@ -212,8 +209,7 @@ func TestBot_CleanupRoutine(t *testing.T) {
 			expenseService:  nil,
 			categoryService: nil,
 			userService:     nil,
-			states:          make(map[int64]*models.UserState),
-			stateTimeout:    30 * time.Minute,
+			stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
 			rateLimiter:     nil,
 		}
 
@@ -223,19 +219,101 @@ func TestBot_CleanupRoutine(t *testing.T) {
 		state.LastActivity = time.Now().Add(-10 * time.Minute) // Still active
 
 		// Set state directly to avoid LastActivity being updated
-		bot.stateMutex.Lock()
-		bot.states[userID] = state
-		bot.stateMutex.Unlock()
+		require.NoError(t, bot.stateStore.Set(ctx, userID, userID, state))
 
 		// Verify state exists
-		retrievedState := bot.getState(userID)
+		retrievedState := bot.getState(ctx, userID, userID)
 		require.NotNil(t, retrievedState)
 
 		// Run cleanup
-		bot.cleanupExpiredStates(ctx)
+		bot.purgeExpiredStates(ctx)
 
 		// Verify state still exists
-		retrievedState = bot.getState(userID)
+		retrievedState = bot.getState(ctx, userID, userID)
 		require.NotNil(t, retrievedState)
 	})
 }
+
+func TestBot_PersistentState(t *testing.T) {
+	ctx := context.Background()
+	mockDB := database.NewMockStorage()
+	newBot := func() *Bot {
+		return &Bot{
+			db:         mockDB,
+			logger:     logger.NewMockLogger(),
+			stateStore: statestore.NewPostgresStore(mockDB, statestore.DefaultTTL),
+		}
+	}
+	chatID, userID := int64(-100), int64(123)
+
+	t.Run("changes made while handling an update are saved", func(t *testing.T) {
+		bot := newBot()
+		updateCtx, saveState := bot.openState(ctx, chatID, userID)
+		state := models.NewUserState()
+		bot.setState(updateCtx, chatID, userID, state)
+
+		// Handlers change the state in place, and get the same state back within the update
+		state.Step = models.StepNotes
+		state.TempExpense = &models.Expense{CategoryName: "Dining", TotalPrice: 450}
+		state.ExpenseSelection = []*models.Expense{{ID: 7, CategoryName: "Fuel"}}
+		require.Same(t, state, bot.getState(updateCtx, chatID, userID))
+		saveState()
+
+		// A restarted bot, or another replica, continues the conversation
+		restored := newBot().getState(ctx, chatID, userID)
+		require.NotNil(t, restored)
+		require.Equal(t, models.StepNotes, restored.Step)
+		require.Equal(t, "Dining", restored.TempExpense.CategoryName)
+		require.Equal(t, int64(7), restored.ExpenseSelection[0].ID)
+	})
+
+	t.Run("clearing the state ends the conversation", func(t *testing.T) {
+		bot := newBot()
+		updateCtx, saveState := bot.openState(ctx, chatID, userID)
+		require.NotNil(t, bot.getState(updateCtx, chatID, userID))
+		bot.clearState(updateCtx, chatID, userID)
+		require.Nil(t, bot.getState(updateCtx, chatID, userID))
+		saveState()
+
+		require.Nil(t, newBot().getState(ctx, chatID, userID))
+	})
+
+	t.Run("only changed or idle conversations are saved", func(t *testing.T) {
+		store := &countingStore{Store: statestore.NewMemoryStore(statestore.DefaultTTL)}
+		bot := &Bot{logger: logger.NewMockLogger(), stateStore: store}
+		handle := func(handler func(ctx context.Context, state *models.UserState)) {
+			updateCtx, saveState := bot.openState(ctx, chatID, userID)
+			handler(updateCtx, bot.getState(updateCtx, chatID, userID))
+			saveState()
+		}
+		bot.setState(ctx, chatID, userID, models.NewUserState())
+		require.Equal(t, 1, store.sets)
+
+		handle(func(context.Context, *models.UserState) {})
+		require.Equal(t, 1, store.sets, "an unchanged conversation is not saved")
+
+		handle(func(_ context.Context, state *models.UserState) { state.Step = models.StepNotes })
+		require.Equal(t, 2, store.sets, "changes made in place are saved")
+
+		handle(func(ctx context.Context, state *models.UserState) { bot.setState(ctx, chatID, userID, state) })
+		require.Equal(t, 3, store.sets)
+
+		idle := models.NewUserState()
+		idle.LastActivity = time.Now().Add(-2 * stateRefreshInterval)
+		require.NoError(t, store.Store.Set(ctx, chatID, userID, idle))
+		handle(func(context.Context, *models.UserState) {})
+		require.Equal(t, 4, store.sets, "activity is refreshed so the conversation does not expire")
+		require.WithinDuration(t, time.Now(), bot.getState(ctx, chatID, userID).LastActivity, time.Second)
+	})
+}
+
+// countingStore counts the conversations saved to a state store
+type countingStore struct {
+	statestore.Store
+	sets int
+}
+
+func (s *countingStore) Set(ctx context.Context, chatID, userID int64, state *models.UserState) error {
+	s.sets++
+	return s.Store.Set(ctx, chatID, userID, state)
+}
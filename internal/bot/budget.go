@@ -0,0 +1,287 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleBudgetCommand handles the /budget command
+func (b *Bot) handleBudgetCommand(ctx context.Context, message *tgbotapi.Message) error {
+	msg := tgbotapi.NewMessage(message.Chat.ID, "💰 Budget management:")
+	msg.ReplyMarkup = GetBudgetKeyboard()
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// handleBudgetCallback handles callback data with the budget_ prefix
+func (b *Bot) handleBudgetCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, action string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	switch {
+	case action == "menu":
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, "💰 Budget management:", GetBudgetKeyboard())
+		_, err := b.api.Send(msg)
+		return err
+
+	case action == "set":
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, "Select budget period:", GetBudgetPeriodKeyboard())
+		_, err := b.api.Send(msg)
+		return err
+
+	case strings.HasPrefix(action, "period_"):
+		period := models.BudgetPeriod(strings.TrimPrefix(action, "period_"))
+		state.BudgetPeriod = period
+		state.Step = models.StepBudgetAmount
+		msg := tgbotapi.NewEditMessageText(chatID, messageID,
+			fmt.Sprintf("💰 Enter the total %s budget amount:", period))
+		_, err := b.api.Send(msg)
+		return err
+
+	case action == "view":
+		progress, err := b.budgetService.GetBudgetProgress(ctx, callback.From.ID, time.Now())
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		return b.sendMessage(ctx, chatID, b.buildBudgetProgressMessage(progress, b.getUserSettings(ctx, callback.From.ID)))
+
+	case action == "history":
+		budgets, err := b.budgetService.GetBudgetHistory(ctx, callback.From.ID)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		return b.sendMessage(ctx, chatID, b.buildBudgetHistoryMessage(budgets, b.getUserSettings(ctx, callback.From.ID)))
+
+	case action == "settings":
+		budgets, err := b.budgetService.GetActiveBudgets(ctx, callback.From.ID)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		if len(budgets) == 0 {
+			return b.sendMessage(ctx, chatID, "No active budgets. Use 💰 Set Budget to create one first.")
+		}
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			"Select a budget to set category limits or stop tracking it:",
+			GetBudgetSettingsKeyboard(budgets))
+		_, err = b.api.Send(msg)
+		return err
+
+	case strings.HasPrefix(action, "limits_"):
+		budgetID, err := strconv.ParseInt(strings.TrimPrefix(action, "limits_"), 10, 64)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		state.BudgetID = budgetID
+		state.Step = models.StepBudgetLimits
+		return b.sendMessage(ctx, chatID, `🏷️ Send category limits, one per line, as "<category> <amount>".
+
+Example:
+Dining 5000
+Grocery 8000
+Petrol 4000`)
+
+	case strings.HasPrefix(action, "deactivate_"):
+		budgetID, err := strconv.ParseInt(strings.TrimPrefix(action, "deactivate_"), 10, 64)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		if err := b.budgetService.DeactivateBudget(ctx, callback.From.ID, budgetID); err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, "✅ Budget stopped. It is still listed in Budget History.")
+		_, err = b.api.Send(msg)
+		return err
+
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+}
+
+// handleBudgetAmount handles the budget amount entered after choosing a period
+func (b *Bot) handleBudgetAmount(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	amount, err := b.parseFloatOrReply(ctx, message.Chat.ID, message.Text, "budget amount")
+	if err != nil {
+		return err
+	}
+
+	// Budgets may be the first thing a user sets up, so make sure the user exists
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	budget, err := b.budgetService.SetBudget(ctx, message.From.ID, state.BudgetPeriod, amount)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	// Reset state
+	b.clearState(ctx, message.Chat.ID, message.From.ID)
+
+	return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf(
+		"✅ %s budget of %s set!\n\nUse /budget → ⚙️ Budget Settings to add per-category limits.",
+		periodLabel(budget.Period), b.getUserSettings(ctx, message.From.ID).FormatAmount(budget.Amount)))
+}
+
+// handleBudgetLimits handles category limit lines for the budget stored in state
+func (b *Bot) handleBudgetLimits(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	settings := b.getUserSettings(ctx, message.From.ID)
+	var sb strings.Builder
+	saved := 0
+
+	for _, line := range strings.Split(message.Text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		categoryName, amount, err := parseBudgetLimitLine(line)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("❌ %s: %v\n", line, err))
+			continue
+		}
+
+		limit, err := b.budgetService.SetCategoryLimit(ctx, message.From.ID, state.BudgetID, categoryName, amount)
+		if err != nil {
+			b.logger.Warn(ctx, "Failed to set budget limit", logger.ErrorField(err))
+			sb.WriteString(fmt.Sprintf("❌ %s: could not set limit\n", line))
+			continue
+		}
+
+		saved++
+		sb.WriteString(fmt.Sprintf("✅ %s %s: %s\n", limit.CategoryEmoji, limit.CategoryName, settings.FormatAmount(limit.LimitAmount)))
+	}
+
+	if saved > 0 {
+		b.clearState(ctx, message.Chat.ID, message.From.ID)
+	} else {
+		sb.WriteString("\nNo limits were saved. Please try again or send /cancel.")
+	}
+
+	return b.sendMessage(ctx, message.Chat.ID, sb.String())
+}
+
+// parseBudgetLimitLine parses a "<category> <amount>" line
+func parseBudgetLimitLine(line string) (string, float64, error) {
+	idx := strings.LastIndex(line, " ")
+	if idx <= 0 {
+		return "", 0, errors.New(`expected "<category> <amount>"`)
+	}
+
+	categoryName := strings.TrimSpace(line[:idx])
+	amount, err := strconv.ParseFloat(strings.TrimSpace(line[idx+1:]), 64)
+	if err != nil || amount <= 0 {
+		return "", 0, errors.New("amount must be a positive number")
+	}
+
+	return categoryName, amount, nil
+}
+
+// buildBudgetProgressMessage builds a formatted spent-vs-limit message for active budgets
+func (b *Bot) buildBudgetProgressMessage(progress []*models.BudgetProgress, settings *models.UserSettings) string {
+	if len(progress) == 0 {
+		return "No active budgets. Use /budget → 💰 Set Budget to create one."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📊 Budget Progress\n")
+
+	for _, p := range progress {
+		sb.WriteString(fmt.Sprintf("\n📅 %s (%s – %s)\n",
+			periodLabel(p.Budget.Period),
+			settings.FormatDate(p.PeriodStart),
+			settings.FormatDate(p.PeriodEnd)))
+		sb.WriteString(fmt.Sprintf("%s %s %.1f%%\n", budgetStatusEmoji(p.Percent()), formatProgressBar(p.Percent()), p.Percent()))
+		sb.WriteString(fmt.Sprintf("Spent: %s of %s\n", settings.FormatAmount(p.Spent), settings.FormatAmount(p.Budget.Amount)))
+		if remaining := p.Remaining(); remaining >= 0 {
+			sb.WriteString(fmt.Sprintf("Remaining: %s\n", settings.FormatAmount(remaining)))
+		} else {
+			sb.WriteString(fmt.Sprintf("Over budget by: %s\n", settings.FormatAmount(-remaining)))
+		}
+
+		for _, c := range p.Categories {
+			sb.WriteString(fmt.Sprintf("  %s %s %s: %s / %s (%.1f%%)\n",
+				budgetStatusEmoji(c.Percent()),
+				c.CategoryEmoji,
+				c.CategoryName,
+				settings.FormatAmount(c.Spent),
+				settings.FormatAmount(c.Limit),
+				c.Percent()))
+		}
+	}
+
+	return sb.String()
+}
+
+// buildBudgetHistoryMessage builds a formatted list of current and past budgets
+func (b *Bot) buildBudgetHistoryMessage(budgets []*models.Budget, settings *models.UserSettings) string {
+	if len(budgets) == 0 {
+		return "No budgets found."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📈 Budget History\n\n")
+
+	for _, budget := range budgets {
+		status := "🟢 active"
+		until := "now"
+		if !budget.IsActive {
+			status = "⚪ ended"
+			if budget.EndDate != nil {
+				until = settings.FormatDate(*budget.EndDate)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("• %s %s: %s (%s → %s)\n",
+			periodLabel(budget.Period),
+			status,
+			settings.FormatAmount(budget.Amount),
+			settings.FormatDate(budget.StartDate),
+			until))
+		for _, limit := range budget.Limits {
+			sb.WriteString(fmt.Sprintf("   %s %s: %s\n", limit.CategoryEmoji, limit.CategoryName, settings.FormatAmount(limit.LimitAmount)))
+		}
+	}
+
+	return sb.String()
+}
+
+// periodLabel returns a capitalised label for a budget period
+func periodLabel(period models.BudgetPeriod) string {
+	if period == "" {
+		return ""
+	}
+	return strings.ToUpper(string(period[:1])) + string(period[1:])
+}
+
+// budgetStatusEmoji returns a traffic light emoji for a spent percentage
+func budgetStatusEmoji(percent float64) string {
+	switch {
+	case percent >= 100:
+		return "🔴"
+	case percent >= 80:
+		return "🟡"
+	default:
+		return "🟢"
+	}
+}
+
+// formatProgressBar renders a ten-segment progress bar for a percentage
+func formatProgressBar(percent float64) string {
+	const segments = 10
+	filled := int(percent / 100 * segments)
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > segments {
+		filled = segments
+	}
+	return strings.Repeat("▓", filled) + strings.Repeat("░", segments-filled)
+}
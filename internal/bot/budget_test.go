@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBudgetLimitLine(t *testing.T) {
+	tests := []struct {
+		name             string
+		line             string
+		expectedCategory string
+		expectedAmount   float64
+		expectError      bool
+	}{
+		{"simple", "Dining 5000", "Dining", 5000, false},
+		{"multi word category", "Home Loan EMI 25000", "Home Loan EMI", 25000, false},
+		{"with emoji", "⛽ Petrol 4000.50", "⛽ Petrol", 4000.50, false},
+		{"missing amount", "Dining", "", 0, true},
+		{"invalid amount", "Dining lots", "", 0, true},
+		{"negative amount", "Dining -5", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, amount, err := parseBudgetLimitLine(tt.line)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCategory, category)
+			assert.InDelta(t, tt.expectedAmount, amount, 0.001)
+		})
+	}
+}
+
+func TestBuildBudgetProgressMessage(t *testing.T) {
+	t.Run("no budgets", func(t *testing.T) {
+		bot := createTestBot()
+		assert.Equal(t, "No active budgets. Use /budget → 💰 Set Budget to create one.", bot.buildBudgetProgressMessage(nil, models.DefaultUserSettings(0)))
+	})
+
+	t.Run("budget with category limits", func(t *testing.T) {
+		bot := createTestBot()
+		progress := []*models.BudgetProgress{
+			{
+				Budget:      &models.Budget{Amount: 10000, Period: models.BudgetPeriodMonthly},
+				PeriodStart: parseTestDate("2026-10-01"),
+				PeriodEnd:   parseTestDate("2026-10-31"),
+				Spent:       4000,
+				Categories: []*models.CategoryProgress{
+					{CategoryName: "Dining", CategoryEmoji: "🍽️", Limit: 1000, Spent: 1200},
+				},
+			},
+		}
+
+		expected := "📊 Budget Progress\n" +
+			"\n📅 Monthly (01 Oct 2026 – 31 Oct 2026)\n" +
+			"🟢 ▓▓▓▓░░░░░░ 40.0%\n" +
+			"Spent: ₹4000.00 of ₹10000.00\n" +
+			"Remaining: ₹6000.00\n" +
+			"  🔴 🍽️ Dining: ₹1200.00 / ₹1000.00 (120.0%)\n"
+		assert.Equal(t, expected, bot.buildBudgetProgressMessage(progress, models.DefaultUserSettings(0)))
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		bot := createTestBot()
+		progress := []*models.BudgetProgress{
+			{
+				Budget:      &models.Budget{Amount: 500, Period: models.BudgetPeriodDaily},
+				PeriodStart: parseTestDate("2026-10-16"),
+				PeriodEnd:   parseTestDate("2026-10-16"),
+				Spent:       750,
+			},
+		}
+
+		result := bot.buildBudgetProgressMessage(progress, models.DefaultUserSettings(0))
+		assert.Contains(t, result, "🔴 ▓▓▓▓▓▓▓▓▓▓ 150.0%")
+		assert.Contains(t, result, "Over budget by: ₹250.00")
+	})
+}
+
+func TestBuildBudgetHistoryMessage(t *testing.T) {
+	bot := createTestBot()
+	endDate := parseTestDate("2026-10-01")
+	budgets := []*models.Budget{
+		{Amount: 12000, Period: models.BudgetPeriodMonthly, StartDate: parseTestDate("2026-10-01"), IsActive: true},
+		{Amount: 10000, Period: models.BudgetPeriodMonthly, StartDate: parseTestDate("2026-09-01"), EndDate: &endDate},
+	}
+
+	expected := "📈 Budget History\n\n" +
+		"• Monthly 🟢 active: ₹12000.00 (01 Oct 2026 → now)\n" +
+		"• Monthly ⚪ ended: ₹10000.00 (01 Sep 2026 → 01 Oct 2026)\n"
+	assert.Equal(t, expected, bot.buildBudgetHistoryMessage(budgets, models.DefaultUserSettings(0)))
+	assert.Equal(t, "No budgets found.", bot.buildBudgetHistoryMessage(nil, models.DefaultUserSettings(0)))
+}
@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const categoriesUsage = `Usage:
+/categories - List your categories
+/categories add 🐶 Pet Food in Pets - Add a category; the group defaults to Other and the emoji to the group's
+/categories group 🐾 Pets - Add a category group
+/categories rename Pet Food to 🦴 Pet Supplies - Rename one of your categories or groups, optionally with a new emoji
+/categories archive Pet Food - Hide one of your categories; its expenses are kept
+/categories restore Pet Food - Show an archived category again
+/categories merge Pet Food into Other - Move your expenses, budgets and recurring expenses to another category`
+
+// handleCategoriesCommand handles the /categories command: listing the categories or managing the user's own
+func (b *Bot) handleCategoriesCommand(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		groups, err := b.categoryService.GetCategoryGroups(ctx, message.From.ID)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		archived, err := b.categoryService.GetArchivedCategories(ctx, message.From.ID)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildCategoriesMessage(groups, archived))
+	}
+
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		nameArgs, groupArgs, _ := splitArgsAt(args[1:], "in")
+		emoji, name := parseEmojiName(nameArgs)
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, categoriesUsage)
+		}
+		category, err := b.categoryService.AddCategory(ctx, message.From.ID, name, emoji, strings.Join(groupArgs, " "))
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Added %s %s to %s. Pick it with /add or type it in a quick add line.", category.Emoji, category.Name, category.Group))
+
+	case "group":
+		emoji, name := parseEmojiName(args[1:])
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, categoriesUsage)
+		}
+		group, err := b.categoryService.AddGroup(ctx, message.From.ID, name, emoji)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Added the group %s %s. Add categories to it with /categories add NAME in %s", group.Emoji, group.Name, group.Name))
+
+	case "rename":
+		oldArgs, newArgs, ok := splitArgsAt(args[1:], "to")
+		emoji, newName := parseEmojiName(newArgs)
+		if !ok || newName == "" {
+			return b.sendMessage(ctx, message.Chat.ID, categoriesUsage)
+		}
+		oldName := strings.Join(oldArgs, " ")
+
+		category, err := b.categoryService.RenameCategory(ctx, message.From.ID, oldName, newName, emoji)
+		if err == nil {
+			return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✏️ Renamed to %s %s.", category.Emoji, category.Name))
+		}
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) || !appErr.IsNotFoundError() {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+
+		// Not a category, so try a group of the same name
+		group, err := b.categoryService.RenameGroup(ctx, message.From.ID, oldName, newName, emoji)
+		if err != nil {
+			if errors.As(err, &appErr) && appErr.IsNotFoundError() {
+				return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("You have no category or group named %s.", oldName))
+			}
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✏️ Renamed the group to %s %s.", group.Emoji, group.Name))
+
+	case "archive", "restore":
+		name := strings.Join(args[1:], " ")
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, categoriesUsage)
+		}
+		archive := strings.EqualFold(args[0], "archive")
+		category, err := b.categoryService.SetCategoryArchived(ctx, message.From.ID, name, archive)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		if archive {
+			return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("🗄️ Archived %s %s. Its expenses are kept; bring it back with /categories restore %s", category.Emoji, category.Name, category.Name))
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("♻️ Restored %s %s.", category.Emoji, category.Name))
+
+	case "merge":
+		fromArgs, toArgs, ok := splitArgsAt(args[1:], "into")
+		if !ok {
+			return b.sendMessage(ctx, message.Chat.ID, categoriesUsage)
+		}
+		from, to, moved, err := b.categoryService.MergeCategories(ctx, message.From.ID, strings.Join(fromArgs, " "), strings.Join(toArgs, " "))
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		text := fmt.Sprintf("🔀 Moved %d expenses from %s %s to %s %s.", moved, from.Emoji, from.Name, to.Emoji, to.Name)
+		if from.IsCustom() {
+			text += fmt.Sprintf(" %s was removed.", from.Name)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, text)
+
+	default:
+		return b.sendMessage(ctx, message.Chat.ID, categoriesUsage)
+	}
+}
+
+// categoryKeyboard returns the category group keyboard for the user, with their own groups
+// after the built-in ones
+func (b *Bot) categoryKeyboard(ctx context.Context, telegramID int64) (tgbotapi.InlineKeyboardMarkup, error) {
+	groups, err := b.categoryService.GetCategoryGroups(ctx, telegramID)
+	if err != nil {
+		return tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	return GetCategoryKeyboard(groups), nil
+}
+
+// splitArgsAt splits arguments at the last occurrence of word, ignoring case, so that
+// "Back to school to School" splits into "Back to school" and "School" at "to". It reports
+// false when word is missing or has nothing on either side.
+func splitArgsAt(args []string, word string) (before, after []string, ok bool) {
+	for i := len(args) - 2; i > 0; i-- {
+		if strings.EqualFold(args[i], word) {
+			return args[:i], args[i+1:], true
+		}
+	}
+	return args, nil, false
+}
+
+// parseEmojiName splits an optional leading emoji from a category or group name
+func parseEmojiName(args []string) (emoji, name string) {
+	if len(args) > 1 && strings.IndexFunc(args[0], func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0 {
+		return args[0], strings.Join(args[1:], " ")
+	}
+	return "", strings.Join(args, " ")
+}
+
+// buildCategoriesMessage lists the categories in each group, then the archived ones
+func buildCategoriesMessage(groups []*models.CategoryGroup, archived []*models.Category) string {
+	var sb strings.Builder
+	sb.WriteString("🏷️ Categories\n")
+
+	for _, group := range groups {
+		if len(group.Categories) == 0 && !group.IsCustom() {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s %s: ", group.Emoji, group.Name)
+		if len(group.Categories) == 0 {
+			sb.WriteString("no categories yet")
+			continue
+		}
+		for i, category := range group.Categories {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(string(category))
+		}
+	}
+
+	if len(archived) > 0 {
+		sb.WriteString("\n\n🗄️ Archived: ")
+		for i, category := range archived {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(category.Emoji + " " + category.Name)
+		}
+	}
+
+	sb.WriteString("\n\nAdd your own with /categories add 🐶 Pet Food in Pets. Send /categories help for more.")
+	return sb.String()
+}
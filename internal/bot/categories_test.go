@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgsAt(t *testing.T) {
+	tests := []struct {
+		args       string
+		word       string
+		wantBefore string
+		wantAfter  string
+		wantOK     bool
+	}{
+		{args: "Pet Food into Other", word: "into", wantBefore: "Pet Food", wantAfter: "Other", wantOK: true},
+		{args: "Back to school to School", word: "to", wantBefore: "Back to school", wantAfter: "School", wantOK: true},
+		{args: "Pet Food IN Pets", word: "in", wantBefore: "Pet Food", wantAfter: "Pets", wantOK: true},
+		{args: "Pet Food", word: "in", wantBefore: "Pet Food"},
+		{args: "Pet Food into", word: "into", wantBefore: "Pet Food into"},
+		{args: "into Other", word: "into", wantBefore: "into Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			before, after, ok := splitArgsAt(strings.Fields(tt.args), tt.word)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantBefore, strings.Join(before, " "))
+			assert.Equal(t, tt.wantAfter, strings.Join(after, " "))
+		})
+	}
+}
+
+func TestParseEmojiName(t *testing.T) {
+	tests := []struct {
+		args      string
+		wantEmoji string
+		wantName  string
+	}{
+		{args: "🐶 Pet Food", wantEmoji: "🐶", wantName: "Pet Food"},
+		{args: "Pet Food", wantName: "Pet Food"},
+		{args: "🐶", wantName: "🐶"},
+		{args: "24x7 Pharmacy", wantName: "24x7 Pharmacy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			emoji, name := parseEmojiName(strings.Fields(tt.args))
+			assert.Equal(t, tt.wantEmoji, emoji)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
+
+func TestBuildCategoriesMessage(t *testing.T) {
+	custom := sql.NullInt64{Int64: 1, Valid: true}
+	groups := []*models.CategoryGroup{
+		{Name: "Vehicle", Emoji: "🚗", Categories: []models.CategoryType{"⛽ Petrol", "🔧 Service"}},
+		{Name: "Gifts", Emoji: "🎁"},
+		{Name: "Other", Emoji: "📌", Categories: []models.CategoryType{"📌 Other", "🐶 Pet Food"}},
+		{UserID: custom, Name: "Kids", Emoji: "🧸"},
+	}
+	archived := []*models.Category{{UserID: custom, Name: "Vet", Emoji: "🐾", Archived: true}}
+
+	assert.Equal(t, `🏷️ Categories
+
+🚗 Vehicle: ⛽ Petrol, 🔧 Service
+📌 Other: 📌 Other, 🐶 Pet Food
+🧸 Kids: no categories yet
+
+🗄️ Archived: 🐾 Vet
+
+Add your own with /categories add 🐶 Pet Food in Pets. Send /categories help for more.`, buildCategoriesMessage(groups, archived))
+}
@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// errDispatcherStopped is returned for updates dispatched after the dispatcher has stopped
+var errDispatcherStopped = errors.New("dispatcher stopped")
+
+// DispatcherConfig tunes how updates are processed concurrently
+type DispatcherConfig struct {
+	Workers   int           // Updates processed at once
+	QueueSize int           // Updates waiting per worker before dispatching blocks
+	Timeout   time.Duration // How long handling one update may take
+}
+
+// DefaultDispatcherConfig returns the dispatcher settings used by the bot
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Workers:   8,
+		QueueSize: 64,
+		Timeout:   2 * time.Minute,
+	}
+}
+
+// dispatcher fans updates out to a pool of workers. Every conversation, a user in a chat, is
+// always handled by the same worker, so its updates are processed one at a time and in the
+// order they arrived while other users' updates go on in parallel. When a worker's queue is
+// full, dispatching waits for room, which slows down receiving updates instead of piling them up.
+type dispatcher struct {
+	config DispatcherConfig
+	handle func(ctx context.Context, update *tgbotapi.Update)
+	queues []chan *tgbotapi.Update
+	seed   maphash.Seed // Spreads conversations over the queues
+	depth  atomic.Int64 // Updates waiting in the queues
+
+	mu      sync.RWMutex // Guards stopped against dispatching to closed queues
+	stopped bool
+	workers sync.WaitGroup
+}
+
+// newDispatcher creates a dispatcher that handles each update with handle
+func newDispatcher(config DispatcherConfig, handle func(ctx context.Context, update *tgbotapi.Update)) *dispatcher {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	d := &dispatcher{
+		config: config,
+		handle: handle,
+		queues: make([]chan *tgbotapi.Update, config.Workers),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan *tgbotapi.Update, config.QueueSize)
+	}
+	return d
+}
+
+// start runs the workers. Updates are handled with a context derived from ctx that is not
+// canceled with it, so updates already queued are finished by stop rather than cut short.
+func (d *dispatcher) start(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	for _, queue := range d.queues {
+		d.workers.Add(1)
+		go func(queue chan *tgbotapi.Update) {
+			defer d.workers.Done()
+			for update := range queue {
+				d.depth.Add(-1)
+				d.process(ctx, update)
+			}
+		}(queue)
+	}
+}
+
+// process handles one update within the configured timeout
+func (d *dispatcher) process(ctx context.Context, update *tgbotapi.Update) {
+	if d.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.config.Timeout)
+		defer cancel()
+	}
+	d.handle(ctx, update)
+}
+
+// dispatch queues an update for the worker of its conversation. It blocks while that worker's
+// queue is full, until ctx is done.
+func (d *dispatcher) dispatch(ctx context.Context, update *tgbotapi.Update) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.stopped {
+		return errDispatcherStopped
+	}
+
+	queue := d.queues[d.shard(update)]
+	d.depth.Add(1)
+	select {
+	case queue <- update:
+		return nil
+	case <-ctx.Done():
+		d.depth.Add(-1)
+		return ctx.Err()
+	}
+}
+
+// stop stops accepting updates and waits for the queued ones to be handled
+func (d *dispatcher) stop() {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.mu.Unlock()
+	d.workers.Wait()
+}
+
+// queueDepth returns how many updates are waiting to be handled
+func (d *dispatcher) queueDepth() int64 {
+	return d.depth.Load()
+}
+
+// shard picks the worker of the conversation an update belongs to
+func (d *dispatcher) shard(update *tgbotapi.Update) int {
+	chatID, userID := updateConversation(update)
+	key := strconv.FormatInt(chatID, 10) + ":" + strconv.FormatInt(userID, 10)
+	return int(maphash.String(d.seed, key) % uint64(len(d.queues)))
+}
+
+// updateConversation returns the chat and user an update is from, as the state is keyed
+func updateConversation(update *tgbotapi.Update) (chatID, userID int64) {
+	switch {
+	case update.CallbackQuery != nil:
+		if update.CallbackQuery.From != nil {
+			userID = update.CallbackQuery.From.ID
+		}
+		if update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil {
+			chatID = update.CallbackQuery.Message.Chat.ID
+		}
+	case update.Message != nil:
+		if update.Message.From != nil {
+			userID = update.Message.From.ID
+		}
+		if update.Message.Chat != nil {
+			chatID = update.Message.Chat.ID
+		}
+	}
+	return chatID, userID
+}
+
+// updateKind names what an update asks for, to break processing latency down by: the command
+// such as "/add", "callback:<action>" for button presses, or "message" for other messages
+func updateKind(update *tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		action, _, _ := strings.Cut(update.CallbackQuery.Data, "_")
+		if action == "" {
+			return "callback"
+		}
+		return "callback:" + action
+	case update.Message != nil && update.Message.IsCommand():
+		return "/" + strings.ToLower(update.Message.Command())
+	case update.Message != nil:
+		return "message"
+	}
+	return "other"
+}
+
+// startDispatcher starts the workers that process dispatched updates. The returned function
+// stops them once the queued updates have been handled.
+func (b *Bot) startDispatcher(ctx context.Context) func() {
+	if b.dispatcher == nil {
+		return func() {}
+	}
+	b.dispatcher.start(ctx)
+	return b.dispatcher.stop
+}
+
+// dispatchUpdate hands an update to the dispatcher, or handles it right away when the bot has
+// none. Dispatch failures are logged.
+func (b *Bot) dispatchUpdate(ctx context.Context, update *tgbotapi.Update) error {
+	if b.dispatcher == nil {
+		b.processUpdate(context.WithoutCancel(ctx), update)
+		return nil
+	}
+	if err := b.dispatcher.dispatch(ctx, update); err != nil {
+		b.logger.Error(ctx, "Failed to dispatch update", logger.ErrorField(err),
+			logger.Int("update_id", update.UpdateID))
+		return err
+	}
+	return nil
+}
+
+// processUpdate handles an update and records how long it took. Failures are logged, as
+// nobody is waiting for the result.
+func (b *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
+	kind := updateKind(update)
+	updatesTotal.Inc(kind)
+
+	started := time.Now()
+	if err := b.HandleUpdate(ctx, update); err != nil {
+		recordError(err)
+		b.logger.Error(ctx, "Failed to handle update", logger.ErrorField(err),
+			logger.Int("update_id", update.UpdateID))
+	}
+	b.observeLatency(kind, time.Since(started))
+}
@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func messageUpdate(updateID int, userID int64, text string) *tgbotapi.Update {
+	return &tgbotapi.Update{
+		UpdateID: updateID,
+		Message: &tgbotapi.Message{
+			Text: text,
+			From: &tgbotapi.User{ID: userID},
+			Chat: &tgbotapi.Chat{ID: userID},
+		},
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps each user's updates in order while users run in parallel", func(t *testing.T) {
+		var mu sync.Mutex
+		handled := make(map[int64][]int)
+		running, maxRunning := 0, 0
+		d := newDispatcher(DispatcherConfig{Workers: 4, QueueSize: 100}, func(ctx context.Context, update *tgbotapi.Update) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			handled[update.Message.From.ID] = append(handled[update.Message.From.ID], update.UpdateID)
+			mu.Unlock()
+		})
+		d.start(ctx)
+
+		for i := range 20 {
+			for userID := int64(1); userID <= 8; userID++ {
+				require.NoError(t, d.dispatch(ctx, messageUpdate(i, userID, "hi")))
+			}
+		}
+		d.stop()
+
+		require.Len(t, handled, 8)
+		for userID, updates := range handled {
+			require.Len(t, updates, 20, "user %d", userID)
+			for i, updateID := range updates {
+				assert.Equal(t, i, updateID, "user %d", userID)
+			}
+		}
+		assert.Greater(t, maxRunning, 1, "different users are handled concurrently")
+		assert.Zero(t, d.queueDepth())
+	})
+
+	t.Run("blocks dispatching while the queue is full", func(t *testing.T) {
+		release := make(chan struct{})
+		d := newDispatcher(DispatcherConfig{Workers: 1, QueueSize: 1}, func(ctx context.Context, update *tgbotapi.Update) {
+			<-release
+		})
+		d.start(ctx)
+		defer d.stop()
+
+		require.NoError(t, d.dispatch(ctx, messageUpdate(1, 1, "hi"))) // Being handled
+		require.Eventually(t, func() bool { return d.queueDepth() == 0 }, time.Second, time.Millisecond)
+		require.NoError(t, d.dispatch(ctx, messageUpdate(2, 1, "hi"))) // Queued
+		assert.Equal(t, int64(1), d.queueDepth())
+
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, d.dispatch(waitCtx, messageUpdate(3, 1, "hi")), context.DeadlineExceeded)
+		assert.Equal(t, int64(1), d.queueDepth())
+		close(release)
+	})
+
+	t.Run("gives each update a timeout", func(t *testing.T) {
+		deadlines := make(chan bool, 1)
+		d := newDispatcher(DispatcherConfig{Workers: 1, Timeout: time.Minute}, func(ctx context.Context, update *tgbotapi.Update) {
+			_, ok := ctx.Deadline()
+			deadlines <- ok
+		})
+		d.start(ctx)
+		require.NoError(t, d.dispatch(ctx, messageUpdate(1, 1, "hi")))
+		d.stop()
+		assert.True(t, <-deadlines)
+	})
+
+	t.Run("finishes queued updates when stopped and then refuses more", func(t *testing.T) {
+		var handled int
+		d := newDispatcher(DispatcherConfig{Workers: 2, QueueSize: 10}, func(ctx context.Context, update *tgbotapi.Update) {
+			time.Sleep(time.Millisecond)
+			handled++
+		})
+		runCtx, cancel := context.WithCancel(ctx)
+		d.start(runCtx)
+		for i := range 5 {
+			require.NoError(t, d.dispatch(ctx, messageUpdate(i, 1, "hi")))
+		}
+		cancel()
+		d.stop()
+
+		assert.Equal(t, 5, handled)
+		assert.ErrorIs(t, d.dispatch(ctx, messageUpdate(6, 1, "hi")), errDispatcherStopped)
+	})
+}
+
+func TestUpdateKind(t *testing.T) {
+	command := messageUpdate(1, 1, "/Add 450 lunch")
+	command.Message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 4}}
+	assert.Equal(t, "/add", updateKind(command))
+	assert.Equal(t, "message", updateKind(messageUpdate(1, 1, "450 lunch")))
+	assert.Equal(t, "callback:history", updateKind(&tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{Data: "history_42"}}))
+	assert.Equal(t, "callback", updateKind(&tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{}}))
+	assert.Equal(t, "other", updateKind(&tgbotapi.Update{}))
+}
+
+func TestBot_UpdateMetrics(t *testing.T) {
+	bot := &Bot{logger: logger.NewMockLogger()}
+	bot.dispatcher = newDispatcher(DispatcherConfig{Workers: 1, QueueSize: 1}, bot.processUpdate)
+
+	bot.observeLatency("/add", 10*time.Millisecond)
+	bot.observeLatency("/add", 30*time.Millisecond)
+	for i := range maxLatencyKinds {
+		bot.observeLatency(fmt.Sprintf("/command%d", i), time.Millisecond)
+	}
+
+	metrics := bot.GetMetrics()
+	assert.Equal(t, int64(0), metrics["queue_depth"])
+	latency := metrics["update_latency"].(map[string]any)
+	assert.Equal(t, map[string]any{"count": int64(2), "avg_ms": int64(20), "max_ms": int64(30)}, latency["/add"])
+	assert.Len(t, latency, maxLatencyKinds+1, "kinds beyond the cap are counted as other")
+	assert.Contains(t, latency, "other")
+}
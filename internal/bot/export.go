@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/export"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exportUsage explains the /export arguments
+const exportUsage = `Usage:
+/export - Download all expenses as CSV
+/export xlsx - Download all expenses as an Excel workbook
+/export 2026-01-01 - Expenses from that date until today
+/export csv 2026-01-01 2026-03-31 - Expenses between two dates (inclusive)`
+
+// exportRange is the parsed /export request. Zero dates leave the range open and
+// endDate is exclusive so the last requested day is included in full.
+type exportRange struct {
+	format    export.Format
+	startDate time.Time
+	endDate   time.Time
+}
+
+// handleExportCommand handles the /export command. The file is streamed to Telegram
+// while expenses are read from storage, so exports of any size use constant memory.
+func (b *Bot) handleExportCommand(ctx context.Context, message *tgbotapi.Message) error {
+	req, err := parseExportArgs(strings.Fields(message.CommandArguments()))
+	if err != nil {
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("%v\n\n%s", err, exportUsage))
+	}
+
+	telegramID := message.From.ID
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	go func() {
+		_, err := b.exportService.ExportExpenses(ctx, telegramID, req.startDate, req.endDate, req.format, pw)
+		pw.CloseWithError(err)
+	}()
+
+	doc := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FileReader{
+		Name:   fmt.Sprintf("expenses_%s.%s", time.Now().Format("2006-01-02"), req.format),
+		Reader: pr,
+	})
+	doc.Caption = buildExportCaption(req, b.getUserSettings(ctx, telegramID).FormatDate)
+
+	if _, err := b.api.Send(doc); err != nil {
+		b.logger.Error(ctx, "Failed to send export", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	return nil
+}
+
+// parseExportArgs parses "[csv|xlsx] [start YYYY-MM-DD] [end YYYY-MM-DD]"
+func parseExportArgs(args []string) (*exportRange, error) {
+	req := &exportRange{format: export.FormatCSV}
+	if len(args) > 0 {
+		if format, ok := export.ParseFormat(args[0]); ok {
+			req.format = format
+			args = args[1:]
+		}
+	}
+	if len(args) > 2 {
+		return nil, fmt.Errorf("too many arguments")
+	}
+
+	dates := make([]time.Time, len(args))
+	for i, arg := range args {
+		date, err := time.ParseInLocation("2006-01-02", arg, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, use YYYY-MM-DD", arg)
+		}
+		dates[i] = date
+	}
+
+	if len(dates) > 0 {
+		req.startDate = dates[0]
+	}
+	if len(dates) == 2 {
+		if dates[1].Before(dates[0]) {
+			return nil, fmt.Errorf("the end date is before the start date")
+		}
+		req.endDate = dates[1].AddDate(0, 0, 1)
+	}
+	return req, nil
+}
+
+// buildExportCaption describes the exported date range
+func buildExportCaption(req *exportRange, formatDate func(time.Time) string) string {
+	switch {
+	case req.startDate.IsZero():
+		return "📤 All your expenses"
+	case req.endDate.IsZero():
+		return fmt.Sprintf("📤 Your expenses since %s", formatDate(req.startDate))
+	default:
+		return fmt.Sprintf("📤 Your expenses from %s to %s", formatDate(req.startDate), formatDate(req.endDate.AddDate(0, 0, -1)))
+	}
+}
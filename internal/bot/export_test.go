@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/export"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportArgs(t *testing.T) {
+	day := func(s string) time.Time {
+		date, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		require.NoError(t, err)
+		return date
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected *exportRange
+	}{
+		{
+			name:     "defaults to all expenses as CSV",
+			args:     nil,
+			expected: &exportRange{format: export.FormatCSV},
+		},
+		{
+			name:     "format only",
+			args:     []string{"XLSX"},
+			expected: &exportRange{format: export.FormatXLSX},
+		},
+		{
+			name:     "start date only",
+			args:     []string{"2026-01-01"},
+			expected: &exportRange{format: export.FormatCSV, startDate: day("2026-01-01")},
+		},
+		{
+			name:     "inclusive date range",
+			args:     []string{"xlsx", "2026-01-01", "2026-03-31"},
+			expected: &exportRange{format: export.FormatXLSX, startDate: day("2026-01-01"), endDate: day("2026-04-01")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseExportArgs(tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+		})
+	}
+
+	invalid := [][]string{
+		{"pdf"},
+		{"01/01/2026"},
+		{"2026-03-01", "2026-01-01"},
+		{"csv", "2026-01-01", "2026-02-01", "2026-03-01"},
+	}
+	for _, args := range invalid {
+		_, err := parseExportArgs(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestHandleExportCommand(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMockStorage()
+	user := &models.User{TelegramID: 12345}
+	require.NoError(t, db.CreateUser(ctx, user))
+	require.NoError(t, db.CreateExpense(ctx, &models.Expense{
+		UserID: user.ID, CategoryName: "Petrol", TotalPrice: 2000, Currency: "INR", OriginalAmount: 2000,
+		Notes: "full tank", Timestamp: time.Now(),
+	}))
+
+	var records [][]string
+	var name, caption string
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.AnythingOfType("tgbotapi.DocumentConfig")).Run(func(args mock.Arguments) {
+		doc := args.Get(0).(tgbotapi.DocumentConfig)
+		file := doc.File.(tgbotapi.FileReader)
+		name, caption = file.Name, doc.Caption
+
+		var err error
+		records, err = csv.NewReader(file.Reader).ReadAll()
+		require.NoError(t, err)
+	}).Return(tgbotapi.Message{}, nil)
+
+	mockLogger := logger.NewMockLogger()
+	bot := &Bot{
+		db:              db,
+		logger:          mockLogger,
+		api:             mockAPI,
+		exportService:   services.NewExportService(db, mockLogger),
+		settingsService: services.NewSettingsService(db, mockLogger),
+	}
+
+	err := bot.handleExportCommand(ctx, &tgbotapi.Message{
+		Text:     "/export",
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 7}},
+		Chat:     &tgbotapi.Chat{ID: 12345},
+		From:     &tgbotapi.User{ID: 12345},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "expenses_"+time.Now().Format("2006-01-02")+".csv", name)
+	assert.Equal(t, "📤 All your expenses", caption)
+	require.Len(t, records, 2)
+	assert.Equal(t, export.Columns, records[0])
+	assert.Equal(t, "full tank", records[1][9])
+	mockAPI.AssertExpectations(t)
+}
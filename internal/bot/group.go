@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type submitterKeyType struct{}
+
+// submitterKey is the context key for the group chat member whose message or button press is
+// being handled, so replies can mention them
+var submitterKey = submitterKeyType{}
+
+// isGroupChat reports whether a chat is a group or supergroup, where several users talk to the bot
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// withSubmitter returns a context whose replies mention user
+func withSubmitter(ctx context.Context, user *tgbotapi.User) context.Context {
+	return context.WithValue(ctx, submitterKey, user)
+}
+
+// mentionSubmitter prefixes a reply with a mention of the group chat member it answers
+func mentionSubmitter(ctx context.Context, msg *tgbotapi.MessageConfig) {
+	user, ok := ctx.Value(submitterKey).(*tgbotapi.User)
+	if !ok || user == nil {
+		return
+	}
+
+	name := user.FirstName
+	if name == "" {
+		name = "@" + user.UserName
+	}
+	msg.Text = name + " " + msg.Text
+	msg.Entities = append(msg.Entities, tgbotapi.MessageEntity{
+		Type:   "text_mention",
+		Offset: 0,
+		Length: len(utf16.Encode([]rune(name))), // Telegram measures entities in UTF-16 code units
+		User:   user,
+	})
+}
+
+// isForBot reports whether a group chat message is meant for the bot: commands that are not
+// addressed to another bot, replies to the bot, messages mentioning it, answers to a question
+// the bot asked the sender and receipts they send after adding an expense
+func (b *Bot) isForBot(message *tgbotapi.Message, state *models.UserState) bool {
+	if message.IsCommand() {
+		_, botName, addressed := strings.Cut(message.CommandWithAt(), "@")
+		return !addressed || strings.EqualFold(botName, b.username)
+	}
+
+	if state != nil {
+		switch state.Step {
+		case models.StepStart, models.StepNone, models.StepQuickAddConfirm:
+		case models.StepAttachReceipts:
+			// Members share photos in groups; only those sent while attaching receipts are for the bot
+			if getReceiptFile(message) != nil {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+
+	if reply := message.ReplyToMessage; reply != nil && reply.From != nil && reply.From.IsBot &&
+		strings.EqualFold(reply.From.UserName, b.username) {
+		return true
+	}
+	// Photos such as receipts carry the mention in their caption
+	text := strings.ToLower(message.Text + " " + message.Caption)
+	return b.username != "" && strings.Contains(text, "@"+strings.ToLower(b.username))
+}
+
+// commandForBot reports whether a group chat command names the bot, e.g. /add@ExpenseBot
+func (b *Bot) commandForBot(message *tgbotapi.Message) bool {
+	_, botName, addressed := strings.Cut(message.CommandWithAt(), "@")
+	return addressed && strings.EqualFold(botName, b.username)
+}
+
+// mentionPattern matches mentions of the bot with the given username, and the spaces after them.
+// It returns nil when the username is not known.
+func mentionPattern(username string) *regexp.Regexp {
+	if username == "" {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(username) + `\b[ \t]*`)
+}
+
+// stripMention removes mentions of the bot from a message, so "@ExpenseBot 450 dining" reads as
+// "450 dining"
+func (b *Bot) stripMention(text string) string {
+	if b.mention == nil {
+		return text
+	}
+	return strings.TrimSpace(b.mention.ReplaceAllString(text, ""))
+}
+
+// groupLedgerName returns the ledger to use when a command names none: in a group chat linked to
+// a ledger, that ledger, joining the sender to it. Otherwise name is returned as is.
+func (b *Bot) groupLedgerName(ctx context.Context, message *tgbotapi.Message, name string) (string, error) {
+	if strings.TrimSpace(name) != "" || !isGroupChat(message.Chat) {
+		return name, nil
+	}
+
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		return "", err
+	}
+	ledger, err := b.ledgerService.JoinChatLedger(ctx, message.From.ID, message.Chat.ID)
+	if err != nil || ledger == nil {
+		return name, err
+	}
+	return ledger.Name, nil
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsForBot(t *testing.T) {
+	bot := &Bot{username: "ExpenseBot"}
+	command := func(text string) *tgbotapi.Message {
+		return &tgbotapi.Message{
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+		}
+	}
+
+	assert.True(t, bot.isForBot(command("/add"), nil))
+	assert.True(t, bot.isForBot(command("/add@expensebot"), nil))
+	assert.False(t, bot.isForBot(command("/add@OtherBot"), nil))
+
+	assert.False(t, bot.isForBot(&tgbotapi.Message{Text: "450 dining"}, nil))
+	assert.False(t, bot.isForBot(&tgbotapi.Message{Text: "450 dining"}, &models.UserState{Step: models.StepStart}))
+	assert.True(t, bot.isForBot(&tgbotapi.Message{Text: "450 dining @ExpenseBot"}, nil))
+	assert.True(t, bot.isForBot(&tgbotapi.Message{Text: "Asha 2, Ravi 1"}, &models.UserState{Step: models.StepSplitDetails}))
+
+	photo := []tgbotapi.PhotoSize{{FileID: "receipt"}}
+	assert.False(t, bot.isForBot(&tgbotapi.Message{Photo: photo}, &models.UserState{Step: models.StepStart}))
+	assert.True(t, bot.isForBot(&tgbotapi.Message{Photo: photo, Caption: "@ExpenseBot"}, &models.UserState{Step: models.StepStart}))
+
+	reply := &tgbotapi.Message{From: &tgbotapi.User{IsBot: true, UserName: "ExpenseBot"}}
+	assert.True(t, bot.isForBot(&tgbotapi.Message{Text: "450 dining", ReplyToMessage: reply}, nil))
+}
+
+func TestCommandForBot(t *testing.T) {
+	bot := &Bot{username: "ExpenseBot"}
+	command := func(text string) *tgbotapi.Message {
+		return &tgbotapi.Message{
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+		}
+	}
+
+	assert.True(t, bot.commandForBot(command("/poll@ExpenseBot")))
+	assert.False(t, bot.commandForBot(command("/poll")))
+	assert.False(t, bot.commandForBot(command("/poll@OtherBot")))
+}
+
+func TestStripMention(t *testing.T) {
+	bot := &Bot{username: "ExpenseBot", mention: mentionPattern("ExpenseBot")}
+	assert.Equal(t, "450 dining", bot.stripMention("@expensebot 450 dining"))
+	assert.Equal(t, "450 dining lunch", bot.stripMention("450 dining @ExpenseBot lunch"))
+	assert.Equal(t, "@ExpenseBotFan hi", bot.stripMention("@ExpenseBotFan hi"))
+	assert.Equal(t, "Asha 2\nRavi 1", bot.stripMention("Asha 2\nRavi 1"))
+}
+
+func TestMentionSubmitter(t *testing.T) {
+	msg := tgbotapi.NewMessage(-100, "✅ Expense added!")
+	mentionSubmitter(context.Background(), &msg)
+	assert.Equal(t, "✅ Expense added!", msg.Text)
+	assert.Empty(t, msg.Entities)
+
+	user := &tgbotapi.User{ID: 7, FirstName: "Åsa"}
+	mentionSubmitter(withSubmitter(context.Background(), user), &msg)
+	assert.Equal(t, "Åsa ✅ Expense added!", msg.Text)
+	require.Len(t, msg.Entities, 1)
+	assert.Equal(t, "text_mention", msg.Entities[0].Type)
+	assert.Equal(t, 3, msg.Entities[0].Length)
+	assert.Equal(t, user, msg.Entities[0].User)
+
+	msg = tgbotapi.NewMessage(-100, "hi")
+	mentionSubmitter(withSubmitter(context.Background(), &tgbotapi.User{ID: 8, UserName: "ravi"}), &msg)
+	assert.Equal(t, "@ravi hi", msg.Text)
+}
@@ -4,6 +4,7 @@ package bot
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
@@ -19,15 +20,26 @@ func (b *Bot) handleListCommand(ctx context.Context, message *tgbotapi.Message)
 		return b.sendError(ctx, message.Chat.ID, err)
 	}
 
-	// Build and send message using helper
-	messageText := b.buildExpenseListMessage(expenses)
-	return b.sendMessage(ctx, message.Chat.ID, messageText)
+	// Build and send message in the user's home currency
+	settings := b.getUserSettings(ctx, message.From.ID)
+	note := b.convertToHomeCurrency(ctx, expenses, settings)
+	messageText := b.buildExpenseListMessage(expenses, settings)
+	receipts := b.getReceiptButtons(ctx, expenses, settings)
+	if len(receipts) == 0 {
+		return b.sendMessage(ctx, message.Chat.ID, messageText+note)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, messageText+note)
+	mentionSubmitter(ctx, &msg)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(receipts...)
+	_, err = b.api.Send(msg)
+	return err
 }
 
 // handleEditCommand handles the /edit command
 func (b *Bot) handleEditCommand(ctx context.Context, message *tgbotapi.Message) error {
 	// Use helper to prepare expense selection
-	expenses, err := b.prepareExpenseSelection(ctx, message.From.ID, 10)
+	expenses, err := b.prepareExpenseSelection(ctx, message.Chat.ID, message.From.ID, 10)
 	if err != nil {
 		return b.sendError(ctx, message.Chat.ID, err)
 	}
@@ -38,7 +50,7 @@ func (b *Bot) handleEditCommand(ctx context.Context, message *tgbotapi.Message)
 
 	// Send message with inline keyboard for expense selection
 	msg := tgbotapi.NewMessage(message.Chat.ID, "Select an expense to edit:")
-	msg.ReplyMarkup = GetEditExpenseKeyboard(expenses)
+	msg.ReplyMarkup = GetEditExpenseKeyboard(expenses, b.getUserSettings(ctx, message.From.ID))
 	_, err = b.api.Send(msg)
 	return err
 }
@@ -46,7 +58,7 @@ func (b *Bot) handleEditCommand(ctx context.Context, message *tgbotapi.Message)
 // handleDeleteCommand handles the /delete command
 func (b *Bot) handleDeleteCommand(ctx context.Context, message *tgbotapi.Message) error {
 	// Use helper to prepare expense selection
-	expenses, err := b.prepareExpenseSelection(ctx, message.From.ID, 10)
+	expenses, err := b.prepareExpenseSelection(ctx, message.Chat.ID, message.From.ID, 10)
 	if err != nil {
 		return b.sendError(ctx, message.Chat.ID, err)
 	}
@@ -57,7 +69,7 @@ func (b *Bot) handleDeleteCommand(ctx context.Context, message *tgbotapi.Message
 
 	// Send message with inline keyboard for expense selection
 	msg := tgbotapi.NewMessage(message.Chat.ID, "Select an expense to delete:")
-	msg.ReplyMarkup = GetDeleteExpenseKeyboard(expenses)
+	msg.ReplyMarkup = GetDeleteExpenseKeyboard(expenses, b.getUserSettings(ctx, message.From.ID))
 	_, err = b.api.Send(msg)
 	return err
 }
@@ -72,9 +84,11 @@ func (b *Bot) handleReportCommand(ctx context.Context, message *tgbotapi.Message
 		return b.sendError(ctx, message.Chat.ID, err)
 	}
 
-	// Build and send message using helper
-	messageText := b.buildReportMessage(expenses)
-	return b.sendMessage(ctx, message.Chat.ID, messageText)
+	// Build and send message in the user's home currency
+	settings := b.getUserSettings(ctx, message.From.ID)
+	note := b.convertToHomeCurrency(ctx, expenses, settings)
+	messageText := b.buildReportMessage(expenses, settings)
+	return b.sendMessage(ctx, message.Chat.ID, messageText+note)
 }
 
 // handleDashboardCommand handles the /dashboard command
@@ -86,17 +100,28 @@ func (b *Bot) handleDashboardCommand(ctx context.Context, message *tgbotapi.Mess
 		return b.sendError(ctx, message.Chat.ID, err)
 	}
 
-	// Build and send message using helper
-	messageText := b.buildDashboardMessage(expenses)
-	return b.sendMessage(ctx, message.Chat.ID, messageText)
+	// Build and send message in the user's home currency
+	settings := b.getUserSettings(ctx, message.From.ID)
+	note := b.convertToHomeCurrency(ctx, expenses, settings)
+	vehicles, err := b.vehicleService.GetVehicleStats(ctx, message.From.ID, settings.Currency)
+	if err != nil {
+		// The dashboard is still useful without fuel efficiency
+		b.logger.Warn(ctx, "Failed to get vehicle stats for dashboard", zap.Error(err))
+	}
+	messageText := b.buildDashboardMessage(expenses, vehicles, settings)
+	return b.sendMessage(ctx, message.Chat.ID, messageText+note)
 }
 
 // handleAddCommand handles the /add command
 func (b *Bot) handleAddCommand(ctx context.Context, message *tgbotapi.Message) error {
 	// Send category group selection keyboard
+	keyboard, err := b.categoryKeyboard(ctx, message.From.ID)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
 	msg := tgbotapi.NewMessage(message.Chat.ID, "Select a category group:")
-	msg.ReplyMarkup = GetCategoryKeyboard()
-	_, err := b.api.Send(msg)
+	msg.ReplyMarkup = keyboard
+	_, err = b.api.Send(msg)
 	return err
 }
 
@@ -116,15 +141,24 @@ func (b *Bot) handleSearchCommand(ctx context.Context, message *tgbotapi.Message
 	}
 
 	// Set user state to search mode
-	state := b.getState(userID)
+	state := b.getState(ctx, chatID, userID)
 	if state == nil {
 		state = models.NewUserState()
-		b.setState(userID, state)
+		b.setState(ctx, chatID, userID, state)
 	}
 	state.Step = models.StepSearchExpense
 
 	// Send search instructions
-	searchInstructions := `🔍 Semantic Search\n\nYou can search for expenses using natural language. Examples:\n• Find all fuel expenses from last month\n• Show me expensive car repairs\n• Find expenses related to maintenance\n\nType your search query:`
+	searchInstructions := `🔍 Search
+
+Search your expenses in your own words. Dates, amounts, categories and car or bike are used as filters, and the rest is matched against your notes. Examples:
+• Find all fuel expenses from last month
+• Show me expensive car repairs
+• Find expenses related to maintenance
+• dining over 500 this week
+• between 1k and 5k in march
+
+Type your search query:`
 
 	return b.sendMessage(ctx, chatID, searchInstructions)
 }
@@ -139,38 +173,136 @@ func (b *Bot) handleSearchQuery(ctx context.Context, message *tgbotapi.Message)
 		return b.sendMessage(ctx, chatID, "Please enter a search query.")
 	}
 
-	// Perform semantic search with lower threshold for placeholder embeddings
-	expenses, err := b.vectorService.SearchExpensesByQuery(ctx, userID, query, 0.1, 10)
+	result, err := b.vectorService.SearchExpenses(ctx, userID, query, 1)
 	if err != nil {
 		return b.sendError(ctx, chatID, err)
 	}
 
-	if len(expenses) == 0 {
+	// Keep the query so the result pages can be browsed
+	state := b.getState(ctx, chatID, userID)
+	if state != nil {
+		state.Step = models.StepNone
+		state.SearchQuery = query
+	}
+
+	if len(result.Expenses) == 0 {
 		return b.sendMessage(ctx, chatID, fmt.Sprintf("No expenses found matching: %q\n\nTry a different search term or be more specific.", query))
 	}
 
-	// Build search results message
+	settings := b.getUserSettings(ctx, userID)
+	msg := tgbotapi.NewMessage(chatID, buildSearchResultsMessage(query, result, settings))
+	if keyboard := b.searchResultsKeyboard(ctx, result, settings); keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleSearchPageCallback shows another page of the last search results
+func (b *Bot) handleSearchPageCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, pageData string) error {
+	chatID := callback.Message.Chat.ID
+
+	page, err := strconv.Atoi(pageData)
+	if err != nil || state.SearchQuery == "" {
+		return b.sendMessage(ctx, chatID, "These search results have expired. Please use /search again.")
+	}
+
+	result, err := b.vectorService.SearchExpenses(ctx, callback.From.ID, state.SearchQuery, page)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+	if len(result.Expenses) == 0 {
+		return b.sendMessage(ctx, chatID, fmt.Sprintf("No expenses found matching: %q", state.SearchQuery))
+	}
+
+	settings := b.getUserSettings(ctx, callback.From.ID)
+	text := buildSearchResultsMessage(state.SearchQuery, result, settings)
+	var msg tgbotapi.EditMessageTextConfig
+	if keyboard := b.searchResultsKeyboard(ctx, result, settings); keyboard != nil {
+		msg = tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID, text, *keyboard)
+	} else {
+		msg = tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+	}
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// searchResultsKeyboard returns the receipt buttons of a page of search results and the buttons
+// to the other pages, or nil when there are neither
+func (b *Bot) searchResultsKeyboard(ctx context.Context, result *models.SearchResult, settings *models.UserSettings) *tgbotapi.InlineKeyboardMarkup {
+	rows := b.getReceiptButtons(ctx, result.Expenses, settings)
+	if result.Pages() > 1 {
+		rows = append(rows, GetSearchPageKeyboard(result.Page, result.Pages()).InlineKeyboard...)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// buildSearchResultsMessage lists one page of search results, with the filters read from the query
+func buildSearchResultsMessage(query string, result *models.SearchResult, settings *models.UserSettings) string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("🔍 Search Results for: %q\n\n", query))
-	sb.WriteString(fmt.Sprintf("Found %d matching expenses:\n\n", len(expenses)))
-
-	for i, expense := range expenses {
-		sb.WriteString(fmt.Sprintf("%d. %s - %s: ₹%.2f\n",
-			i+1,
-			expense.Timestamp.Format("02 Jan 2006"),
-			expense.CategoryName,
-			expense.TotalPrice))
+	sb.WriteString(fmt.Sprintf("🔍 Search Results for: %q\n", query))
+	if filters := describeSearchFilters(result.Query, settings); filters != "" {
+		sb.WriteString(fmt.Sprintf("Filters: %s\n", filters))
+	}
+	if result.Pages() > 1 {
+		sb.WriteString(fmt.Sprintf("\nFound %d matching expenses (page %d of %d):\n\n", result.Total, result.Page, result.Pages()))
+	} else {
+		sb.WriteString(fmt.Sprintf("\nFound %d matching expenses:\n\n", result.Total))
+	}
+
+	first := (result.Page-1)*result.PageSize + 1
+	for i, expense := range result.Expenses {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", first+i, formatExpenseLine(expense, settings)))
 		if expense.Notes != "" {
 			sb.WriteString(fmt.Sprintf("   Notes: %s\n", expense.Notes))
 		}
 		sb.WriteString("\n")
 	}
+	return sb.String()
+}
 
-	// Reset user state
-	state := b.getState(userID)
-	if state != nil {
-		state.Step = models.StepNone
+// describeSearchFilters summarises the filters of a search query, e.g. "01 Feb 2026 – 28 Feb 2026 · at least ₹500.00 · Petrol"
+func describeSearchFilters(query *models.SearchQuery, settings *models.UserSettings) string {
+	if query == nil {
+		return ""
 	}
 
-	return b.sendMessage(ctx, chatID, sb.String())
+	var parts []string
+	switch {
+	case query.From != nil && query.To != nil:
+		// To is exclusive
+		last := query.To.AddDate(0, 0, -1)
+		if last.Equal(*query.From) {
+			parts = append(parts, settings.FormatDate(*query.From))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s – %s", settings.FormatDate(*query.From), settings.FormatDate(last)))
+		}
+	case query.From != nil:
+		parts = append(parts, "since "+settings.FormatDate(*query.From))
+	}
+
+	switch {
+	case query.MinAmount != nil && query.MaxAmount != nil:
+		parts = append(parts, fmt.Sprintf("%s – %s", settings.FormatAmount(*query.MinAmount), settings.FormatAmount(*query.MaxAmount)))
+	case query.MinAmount != nil:
+		parts = append(parts, "at least "+settings.FormatAmount(*query.MinAmount))
+	case query.MaxAmount != nil:
+		parts = append(parts, "at most "+settings.FormatAmount(*query.MaxAmount))
+	}
+
+	for _, category := range query.Categories {
+		parts = append(parts, category.Name)
+	}
+	parts = append(parts, query.Groups...)
+	if query.VehicleType != "" {
+		parts = append(parts, query.VehicleType)
+	}
+	if query.ByAmount {
+		parts = append(parts, "largest first")
+	}
+	return strings.Join(parts, " · ")
 }
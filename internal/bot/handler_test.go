@@ -9,10 +9,12 @@ import (
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
 	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockStorage is a mock implementation of database.Storage
@@ -57,8 +59,8 @@ func (m *MockStorage) GetCategoryByName(ctx context.Context, name string) (*mode
 	return args.Get(0).(*models.Category), args.Error(1)
 }
 
-func (m *MockStorage) CreateExpense(ctx context.Context, expense *models.Expense) error {
-	args := m.Called(ctx, expense)
+func (m *MockStorage) CreateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expense, history)
 	return args.Error(0)
 }
 
@@ -78,13 +80,13 @@ func (m *MockStorage) GetExpenseByID(ctx context.Context, expenseID int64) (*mod
 	return args.Get(0).(*models.Expense), args.Error(1)
 }
 
-func (m *MockStorage) UpdateExpense(ctx context.Context, expense *models.Expense) error {
-	args := m.Called(ctx, expense)
+func (m *MockStorage) UpdateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expense, history)
 	return args.Error(0)
 }
 
-func (m *MockStorage) DeleteExpense(ctx context.Context, expenseID, userID int64) error {
-	args := m.Called(ctx, expenseID, userID)
+func (m *MockStorage) DeleteExpense(ctx context.Context, expenseID, userID int64, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expenseID, userID, history)
 	return args.Error(0)
 }
 
@@ -155,6 +157,548 @@ func (m *MockStorage) GetExpenseEmbedding(ctx context.Context, expenseID int64)
 	return args.Get(0).(*models.ExpenseEmbedding), args.Error(1)
 }
 
+func (m *MockStorage) SearchExpenses(ctx context.Context, search *models.ExpenseSearch) ([]*models.Expense, int, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.Expense), args.Int(1), args.Error(2)
+}
+
+// BudgetStorage stubs
+func (m *MockStorage) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	args := m.Called(ctx, budget)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetActiveBudgets(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Budget), args.Error(1)
+}
+
+func (m *MockStorage) GetBudgetsByUserID(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Budget), args.Error(1)
+}
+
+func (m *MockStorage) GetBudgetByID(ctx context.Context, id int64) (*models.Budget, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockStorage) SetBudgetLimit(ctx context.Context, limit *models.BudgetLimit) error {
+	args := m.Called(ctx, limit)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeactivateBudget(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+// RecurringExpenseStorage stubs
+func (m *MockStorage) CreateRecurringExpense(ctx context.Context, recurring *models.RecurringExpense) error {
+	args := m.Called(ctx, recurring)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetRecurringExpensesByUserID(ctx context.Context, userID int64) ([]*models.RecurringExpense, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RecurringExpense), args.Error(1)
+}
+
+func (m *MockStorage) GetRecurringExpenseByID(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecurringExpense), args.Error(1)
+}
+
+func (m *MockStorage) GetDueRecurringExpenses(ctx context.Context, now time.Time, limit int) ([]*models.RecurringExpense, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RecurringExpense), args.Error(1)
+}
+
+func (m *MockStorage) AdvanceRecurringExpense(ctx context.Context, id int64, currentDue, nextDue time.Time) (bool, error) {
+	args := m.Called(ctx, id, currentDue, nextDue)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) SetRecurringExpenseActive(ctx context.Context, id, userID int64, active bool) error {
+	args := m.Called(ctx, id, userID, active)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteRecurringExpense(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+// ReminderStorage stubs
+func (m *MockStorage) CreateReminder(ctx context.Context, reminder *models.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetRemindersByUserID(ctx context.Context, userID int64) ([]*models.Reminder, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) GetReminderByID(ctx context.Context, id int64) (*models.Reminder, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) UpdateReminder(ctx context.Context, reminder *models.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteReminder(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) ClaimReminder(ctx context.Context, id int64, currentRun, nextRun time.Time) (bool, error) {
+	args := m.Called(ctx, id, currentRun, nextRun)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) GetUserSettings(ctx context.Context, userID int64) (*models.UserSettings, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSettings), args.Error(1)
+}
+
+func (m *MockStorage) UpsertUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UpsertExchangeRates(ctx context.Context, rates []*models.ExchangeRate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetExchangeRate(ctx context.Context, base, quote string, on time.Time) (*models.ExchangeRate, error) {
+	args := m.Called(ctx, base, quote, on)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockStorage) GetLatestExchangeRates(ctx context.Context, limit int) ([]*models.ExchangeRate, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockStorage) StreamExpenses(ctx context.Context, userID int64, startDate, endDate time.Time, fn func(*models.Expense) error) error {
+	args := m.Called(ctx, userID, startDate, endDate, fn)
+	if expenses, ok := args.Get(0).([]*models.Expense); ok {
+		for _, expense := range expenses {
+			if err := fn(expense); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStorage) CreateExpenses(ctx context.Context, expenses []*models.Expense, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expenses, history)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetImportProfile(ctx context.Context, userID int64, name string) (*models.ImportProfile, error) {
+	args := m.Called(ctx, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportProfile), args.Error(1)
+}
+
+func (m *MockStorage) UpsertImportProfile(ctx context.Context, profile *models.ImportProfile) error {
+	args := m.Called(ctx, profile)
+	return args.Error(0)
+}
+
+func (m *MockStorage) EnqueueEmbeddingJobs(ctx context.Context, expenseIDs []int64) error {
+	args := m.Called(ctx, expenseIDs)
+	return args.Error(0)
+}
+
+func (m *MockStorage) EnqueueMissingEmbeddings(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStorage) ClaimEmbeddingJobs(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingJob, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.EmbeddingJob), args.Error(1)
+}
+
+func (m *MockStorage) CompleteEmbeddingJob(ctx context.Context, job *models.EmbeddingJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RetryEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, runAt time.Time, lastError string) error {
+	args := m.Called(ctx, job, runAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockStorage) FailEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, lastError string) error {
+	args := m.Called(ctx, job, lastError)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetEmbeddingQueueStats(ctx context.Context) (*models.EmbeddingQueueStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmbeddingQueueStats), args.Error(1)
+}
+
+func (m *MockStorage) GetCachedEmbedding(ctx context.Context, contentHash string) ([]float32, error) {
+	args := m.Called(ctx, contentHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]float32), args.Error(1)
+}
+
+func (m *MockStorage) SaveCachedEmbedding(ctx context.Context, contentHash string, embedding []float32) error {
+	args := m.Called(ctx, contentHash, embedding)
+	return args.Error(0)
+}
+
+func (m *MockStorage) CreateVehicle(ctx context.Context, vehicle *models.Vehicle) error {
+	args := m.Called(ctx, vehicle)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetVehiclesByUserID(ctx context.Context, userID int64) ([]*models.Vehicle, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Vehicle), args.Error(1)
+}
+
+func (m *MockStorage) GetVehicleByID(ctx context.Context, id int64) (*models.Vehicle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Vehicle), args.Error(1)
+}
+
+func (m *MockStorage) DeleteVehicle(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetCategoriesByUserID(ctx context.Context, userID int64) ([]*models.Category, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Category), args.Error(1)
+}
+
+func (m *MockStorage) CreateCategory(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UpdateCategory(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockStorage) MergeCategory(ctx context.Context, userID, fromID, toID int64) ([]int64, error) {
+	args := m.Called(ctx, userID, fromID, toID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockStorage) GetCategoryGroups(ctx context.Context, userID int64) ([]*models.CategoryGroup, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CategoryGroup), args.Error(1)
+}
+
+func (m *MockStorage) CreateCategoryGroup(ctx context.Context, group *models.CategoryGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RenameCategoryGroup(ctx context.Context, group *models.CategoryGroup, oldName string) error {
+	args := m.Called(ctx, group, oldName)
+	return args.Error(0)
+}
+
+func (m *MockStorage) CreateLedger(ctx context.Context, ledger *models.Ledger, ownerID int64) error {
+	args := m.Called(ctx, ledger, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetLedgersByUserID(ctx context.Context, userID int64) ([]*models.Ledger, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Ledger), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerByInviteCode(ctx context.Context, inviteCode string) (*models.Ledger, error) {
+	args := m.Called(ctx, inviteCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Ledger), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerByChatID(ctx context.Context, chatID int64) (*models.Ledger, error) {
+	args := m.Called(ctx, chatID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Ledger), args.Error(1)
+}
+
+func (m *MockStorage) LinkLedgerChat(ctx context.Context, ledgerID, chatID int64) error {
+	args := m.Called(ctx, ledgerID, chatID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UnlinkLedgerChat(ctx context.Context, chatID int64) error {
+	args := m.Called(ctx, chatID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteLedger(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetLedgerMembers(ctx context.Context, ledgerID int64) ([]*models.LedgerMember, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LedgerMember), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerMember(ctx context.Context, ledgerID, userID int64) (*models.LedgerMember, error) {
+	args := m.Called(ctx, ledgerID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerMember), args.Error(1)
+}
+
+func (m *MockStorage) AddLedgerMember(ctx context.Context, member *models.LedgerMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UpdateLedgerMemberRole(ctx context.Context, ledgerID, userID int64, role models.LedgerRole) error {
+	args := m.Called(ctx, ledgerID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RemoveLedgerMember(ctx context.Context, ledgerID, userID int64) error {
+	args := m.Called(ctx, ledgerID, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) SetActiveLedger(ctx context.Context, userID, ledgerID int64) error {
+	args := m.Called(ctx, userID, ledgerID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetLedgerExpenses(ctx context.Context, ledgerID int64, startDate, endDate time.Time) ([]*models.Expense, error) {
+	args := m.Called(ctx, ledgerID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) SetExpenseSplits(ctx context.Context, expenseID int64, splits []*models.ExpenseSplit) error {
+	args := m.Called(ctx, expenseID, splits)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetExpenseSplits(ctx context.Context, expenseID int64) ([]*models.ExpenseSplit, error) {
+	args := m.Called(ctx, expenseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExpenseSplit), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerSplits(ctx context.Context, ledgerID int64) ([]*models.ExpenseSplit, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExpenseSplit), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerSplitExpenses(ctx context.Context, ledgerID int64) ([]*models.Expense, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	args := m.Called(ctx, settlement)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetSettlementsByLedgerID(ctx context.Context, ledgerID int64) ([]*models.Settlement, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Settlement), args.Error(1)
+}
+
+func (m *MockStorage) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetAttachmentByID(ctx context.Context, id int64) (*models.Attachment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockStorage) GetAttachmentsByExpenseIDs(ctx context.Context, expenseIDs []int64) ([]*models.Attachment, error) {
+	args := m.Called(ctx, expenseIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+func (m *MockStorage) GetDeletedExpenseByID(ctx context.Context, id int64) (*models.Expense, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) GetDeletedExpenses(ctx context.Context, userID int64, limit int) ([]*models.Expense, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) RestoreExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, id, userID, history)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetExpenseHistory(ctx context.Context, expenseID int64) ([]*models.ExpenseHistory, error) {
+	args := m.Called(ctx, expenseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExpenseHistory), args.Error(1)
+}
+
+func (m *MockStorage) GetLastUndoableChange(ctx context.Context, userID int64) (*models.ExpenseHistory, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExpenseHistory), args.Error(1)
+}
+
+func (m *MockStorage) GetUserState(ctx context.Context, chatID, userID int64) (*models.UserState, error) {
+	args := m.Called(ctx, chatID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserState), args.Error(1)
+}
+
+func (m *MockStorage) SaveUserState(ctx context.Context, chatID, userID int64, state *models.UserState, expiresAt time.Time) error {
+	args := m.Called(ctx, chatID, userID, state, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteUserState(ctx context.Context, chatID, userID int64) error {
+	args := m.Called(ctx, chatID, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteExpiredUserStates(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStorage) CountUserStates(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 // MockVectorService is a mock implementation of VectorServiceInterface
 type MockVectorService struct {
 	mock.Mock
@@ -181,6 +725,19 @@ func (m *MockVectorService) UpdateExpenseEmbeddings(ctx context.Context, expense
 	return args.Error(0)
 }
 
+func (m *MockVectorService) EnqueueEmbeddings(ctx context.Context, expenseIDs ...int64) error {
+	args := m.Called(ctx, expenseIDs)
+	return args.Error(0)
+}
+
+func (m *MockVectorService) SearchExpenses(ctx context.Context, telegramID int64, text string, page int) (*models.SearchResult, error) {
+	args := m.Called(ctx, telegramID, text, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SearchResult), args.Error(1)
+}
+
 func (m *MockVectorService) BatchUpdateEmbeddings(ctx context.Context, telegramID int64) error {
 	args := m.Called(ctx, telegramID)
 	return args.Error(0)
@@ -193,7 +750,14 @@ type MockExpenseService struct {
 }
 
 func NewMockExpenseService(db database.Storage, logger logger.Logger) *services.ExpenseService {
-	return services.NewExpenseService(db, logger)
+	currencyService := services.NewCurrencyService(db, logger)
+	ledgerService := services.NewLedgerService(db, logger, currencyService)
+	return services.NewExpenseService(db, logger, nil, currencyService, services.NewCategoryService(db, logger, nil), ledgerService)
+}
+
+// newTestAttachmentService creates an attachment service without a blob store, for listing attachments
+func newTestAttachmentService(db database.Storage, logger logger.Logger) *services.AttachmentService {
+	return services.NewAttachmentService(db, logger, nil, services.NewLedgerService(db, logger, services.NewCurrencyService(db, logger)))
 }
 
 func (m *MockExpenseService) GetExpensesByTelegramID(ctx context.Context, telegramID int64, limit, offset int) ([]*models.Expense, error) {
@@ -230,6 +794,19 @@ func (m *MockBotAPI) GetUpdatesChan(u tgbotapi.UpdateConfig) tgbotapi.UpdatesCha
 	return ch
 }
 
+func (m *MockBotAPI) GetFileDirectURL(fileID string) (string, error) {
+	args := m.Called(fileID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockBotAPI) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	args := m.Called(endpoint, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tgbotapi.APIResponse), args.Error(1)
+}
+
 func TestBot_handleSearchCommand(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -272,10 +849,12 @@ func TestBot_handleSearchCommand(t *testing.T) {
 			bot := &Bot{
 				db:             mockDB,
 				logger:         mockLogger,
-				states:         make(map[int64]*models.UserState),
+				stateStore:     statestore.NewMemoryStore(statestore.DefaultTTL),
 				expenseService: NewMockExpenseService(mockDB, mockLogger),
-				vectorService:  mockVector,
-				api:            mockAPI,
+				// Users without saved settings get the defaults
+				settingsService: services.NewSettingsService(database.NewMockStorage(), mockLogger),
+				vectorService:   mockVector,
+				api:             mockAPI,
 			}
 
 			// Create a mock message
@@ -300,39 +879,62 @@ func TestBot_handleSearchCommand(t *testing.T) {
 }
 
 func TestBot_handleSearchQuery(t *testing.T) {
+	petrol := &models.Category{ID: 1, Name: "Petrol"}
+	lastMonth := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	endOfMonth := lastMonth.AddDate(0, 1, 0)
+	expense := &models.Expense{ID: 1, TotalPrice: 100.0, CategoryName: "⛽ Petrol", Notes: "fuel", Timestamp: time.Now()}
+
 	tests := []struct {
-		name        string
-		userID      int64
-		query       string
-		setupMock   func(*MockStorage, *MockVectorService)
-		expectError bool
+		name      string
+		query     string
+		setupMock func(*MockVectorService)
+		expected  []string
+		keyboard  bool
 	}{
 		{
-			name:   "successful search query",
-			userID: 12345,
-			query:  "petrol",
-			setupMock: func(mockDB *MockStorage, mockVector *MockVectorService) {
-				mockVector.On("SearchExpensesByQuery", mock.Anything, int64(12345), "petrol", float32(0.1), 10).Return([]*models.Expense{{ID: 1, TotalPrice: 100.0, CategoryName: "⛽ Petrol", Notes: "fuel", Timestamp: time.Now()}}, nil)
+			name:  "successful search query",
+			query: "fuel last month",
+			setupMock: func(mockVector *MockVectorService) {
+				mockVector.On("SearchExpenses", mock.Anything, int64(12345), "fuel last month", 1).Return(&models.SearchResult{
+					Query:    &models.SearchQuery{From: &lastMonth, To: &endOfMonth, Categories: []*models.Category{petrol}},
+					Expenses: []*models.Expense{expense},
+					Total:    1,
+					Page:     1,
+					PageSize: 10,
+				}, nil)
 			},
-			expectError: false,
+			expected: []string{"Filters: 01 Feb 2026 – 28 Feb 2026 · Petrol", "Found 1 matching expenses:", "1. ", "Notes: fuel"},
 		},
 		{
-			name:   "empty query",
-			userID: 12345,
-			query:  "",
-			setupMock: func(mockDB *MockStorage, mockVector *MockVectorService) {
-				// No mock setup needed for empty query
+			name:  "results over several pages",
+			query: "fuel",
+			setupMock: func(mockVector *MockVectorService) {
+				mockVector.On("SearchExpenses", mock.Anything, int64(12345), "fuel", 1).Return(&models.SearchResult{
+					Query:    &models.SearchQuery{Text: "fuel"},
+					Expenses: []*models.Expense{expense},
+					Total:    25,
+					Page:     1,
+					PageSize: 10,
+				}, nil)
 			},
-			expectError: false,
+			expected: []string{"Found 25 matching expenses (page 1 of 3):"},
+			keyboard: true,
 		},
 		{
-			name:   "no results found",
-			userID: 12345,
-			query:  "nonexistent",
-			setupMock: func(mockDB *MockStorage, mockVector *MockVectorService) {
-				mockVector.On("SearchExpensesByQuery", mock.Anything, int64(12345), "nonexistent", float32(0.1), 10).Return([]*models.Expense{}, nil)
+			name:      "empty query",
+			query:     "",
+			setupMock: func(mockVector *MockVectorService) {},
+			expected:  []string{"Please enter a search query."},
+		},
+		{
+			name:  "no results found",
+			query: "nonexistent",
+			setupMock: func(mockVector *MockVectorService) {
+				mockVector.On("SearchExpenses", mock.Anything, int64(12345), "nonexistent", 1).Return(&models.SearchResult{
+					Query: &models.SearchQuery{Text: "nonexistent"}, Page: 1, PageSize: 10,
+				}, nil)
 			},
-			expectError: false,
+			expected: []string{"No expenses found matching"},
 		},
 	}
 
@@ -342,35 +944,39 @@ func TestBot_handleSearchQuery(t *testing.T) {
 			mockVector := &MockVectorService{}
 			mockLogger := &logger.MockLogger{}
 			mockAPI := &MockBotAPI{}
-			mockAPI.On("Send", mock.Anything).Return(tgbotapi.Message{}, nil)
+			tt.setupMock(mockVector)
 
-			// Set up the vectorService mock expectation for this test
-			if tt.query != "" {
-				mockVector.On("SearchExpensesByQuery", mock.Anything, tt.userID, tt.query, float32(0.1), 10).Return([]*models.Expense{{ID: 1, TotalPrice: 100.0, CategoryName: "⛽ Petrol", Notes: "fuel", Timestamp: time.Now()}}, nil)
-			}
+			var sent tgbotapi.MessageConfig
+			mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+				sent = args.Get(0).(tgbotapi.MessageConfig)
+			}).Return(tgbotapi.Message{}, nil)
 
 			bot := &Bot{
 				db:             mockDB,
 				logger:         mockLogger,
-				states:         make(map[int64]*models.UserState),
+				stateStore:     statestore.NewMemoryStore(statestore.DefaultTTL),
 				expenseService: NewMockExpenseService(mockDB, mockLogger),
-				vectorService:  mockVector,
-				api:            mockAPI,
+				// Users without saved settings get the defaults
+				settingsService:   services.NewSettingsService(database.NewMockStorage(), mockLogger),
+				vectorService:     mockVector,
+				attachmentService: newTestAttachmentService(database.NewMockStorage(), mockLogger),
+				api:               mockAPI,
 			}
+			bot.setState(context.Background(), 12345, 12345, &models.UserState{Step: models.StepSearchExpense})
 
 			message := &tgbotapi.Message{
-				Chat: &tgbotapi.Chat{ID: tt.userID},
-				From: &tgbotapi.User{ID: tt.userID},
+				Chat: &tgbotapi.Chat{ID: 12345},
+				From: &tgbotapi.User{ID: 12345},
 				Text: tt.query,
 			}
 
 			err := bot.handleSearchQuery(context.Background(), message)
+			require.NoError(t, err)
 
-			if tt.expectError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, sent.Text, expected)
 			}
+			assert.Equal(t, tt.keyboard, sent.ReplyMarkup != nil)
 
 			mockDB.AssertExpectations(t)
 			mockVector.AssertExpectations(t)
@@ -379,6 +985,59 @@ func TestBot_handleSearchQuery(t *testing.T) {
 	}
 }
 
+func TestBot_handleSearchPageCallback(t *testing.T) {
+	mockVector := &MockVectorService{}
+	mockLogger := &logger.MockLogger{}
+	mockAPI := &MockBotAPI{}
+	bot := &Bot{
+		logger:            mockLogger,
+		stateStore:        statestore.NewMemoryStore(statestore.DefaultTTL),
+		settingsService:   services.NewSettingsService(database.NewMockStorage(), mockLogger),
+		vectorService:     mockVector,
+		attachmentService: newTestAttachmentService(database.NewMockStorage(), mockLogger),
+		api:               mockAPI,
+	}
+
+	var sent []tgbotapi.Chattable
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(tgbotapi.Chattable))
+	}).Return(tgbotapi.Message{}, nil)
+
+	callback := &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 12345},
+		Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: 12345}},
+		Data:    "search_page_2",
+	}
+
+	t.Run("expired results", func(t *testing.T) {
+		require.NoError(t, bot.handleCallbackQuery(context.Background(), callback))
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, "expired")
+	})
+
+	t.Run("edits the message with the next page", func(t *testing.T) {
+		sent = nil
+		bot.setState(context.Background(), 12345, 12345, &models.UserState{SearchQuery: "fuel"})
+		expenses := make([]*models.Expense, 5)
+		for i := range expenses {
+			expenses[i] = &models.Expense{ID: int64(i + 11), TotalPrice: 100, CategoryName: "Petrol", Timestamp: time.Now()}
+		}
+		mockVector.On("SearchExpenses", mock.Anything, int64(12345), "fuel", 2).Return(&models.SearchResult{
+			Query: &models.SearchQuery{Text: "fuel"}, Expenses: expenses, Total: 15, Page: 2, PageSize: 10,
+		}, nil)
+
+		require.NoError(t, bot.handleCallbackQuery(context.Background(), callback))
+		require.Len(t, sent, 1)
+		edit := sent[0].(tgbotapi.EditMessageTextConfig)
+		assert.Equal(t, 7, edit.MessageID)
+		assert.Contains(t, edit.Text, "page 2 of 2")
+		assert.Contains(t, edit.Text, "11. ")
+		require.NotNil(t, edit.ReplyMarkup)
+		assert.Equal(t, "search_page_1", *edit.ReplyMarkup.InlineKeyboard[0][0].CallbackData)
+		assert.Len(t, edit.ReplyMarkup.InlineKeyboard[0], 1, "no next page after the last")
+	})
+}
+
 func TestBot_handleListCommand(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -422,7 +1081,11 @@ func TestBot_handleListCommand(t *testing.T) {
 				db:             mockDB,
 				logger:         mockLogger,
 				expenseService: NewMockExpenseService(mockDB, mockLogger),
-				api:            mockAPI,
+				// Users without saved settings get the defaults
+				settingsService:   services.NewSettingsService(database.NewMockStorage(), mockLogger),
+				currencyService:   services.NewCurrencyService(database.NewMockStorage(), mockLogger),
+				attachmentService: newTestAttachmentService(database.NewMockStorage(), mockLogger),
+				api:               mockAPI,
 			}
 
 			// Create a mock message
@@ -4,18 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/parser"
 	"github.com/MitulShah1/expense-tracker-bot/pkg/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// fileDownloadClient downloads files users send to the bot
+var fileDownloadClient = &http.Client{Timeout: 30 * time.Second}
+
 // prepareExpenseSelection fetches expenses for a user, limits them to maxExpenses,
-// creates a deep copy, and stores them in user state. Returns the expenses to show.
-func (b *Bot) prepareExpenseSelection(ctx context.Context, userID int64, maxExpenses int) ([]*models.Expense, error) {
+// creates a deep copy, and stores them in the user's state in the chat. Returns the expenses to show.
+func (b *Bot) prepareExpenseSelection(ctx context.Context, chatID, userID int64, maxExpenses int) ([]*models.Expense, error) {
 	// Get expenses from service by Telegram ID
 	expenses, err := b.expenseService.GetExpensesByTelegramID(ctx, userID, 100, 0) // Get all expenses
 	if err != nil {
@@ -37,10 +45,10 @@ func (b *Bot) prepareExpenseSelection(ctx context.Context, userID int64, maxExpe
 	copy(expensesCopy, expensesToShow)
 
 	// Store in user state
-	state := b.getState(userID)
+	state := b.getState(ctx, chatID, userID)
 	if state == nil {
 		state = models.NewUserState()
-		b.setState(userID, state)
+		b.setState(ctx, chatID, userID, state)
 	}
 	state.ExpenseSelection = expensesCopy
 
@@ -63,7 +71,94 @@ func (b *Bot) parseFloatOrReply(ctx context.Context, chatID int64, text, fieldNa
 	return value, nil
 }
 
-// checkExpenseOwnership verifies that the user owns the expense and returns an error if not
+// setAmountOrReply parses the message as an amount with an optional currency and stores it on
+// the expense, converted to the user's home currency. It replies and returns false if the
+// amount is invalid or cannot be converted.
+func (b *Bot) setAmountOrReply(ctx context.Context, message *tgbotapi.Message, expense *models.Expense, fieldName string) (bool, error) {
+	amount, currency, err := parser.ParseAmount(message.Text)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Please enter a valid number for the %s, e.g. 450 or 45 EUR.", fieldName)
+		return false, b.sendMessage(ctx, message.Chat.ID, errorMsg)
+	}
+
+	if err := b.setAmount(ctx, message.From.ID, expense, amount, currency); err != nil {
+		return false, b.sendError(ctx, message.Chat.ID, err)
+	}
+	return true, nil
+}
+
+// setAmount stores an amount paid in currency on the expense, with TotalPrice converted to the
+// user's home currency. An empty currency means the home currency.
+func (b *Bot) setAmount(ctx context.Context, telegramID int64, expense *models.Expense, amount float64, currency string) error {
+	settings := b.getUserSettings(ctx, telegramID)
+	if currency == "" {
+		currency = settings.Currency
+	}
+
+	total := amount
+	if currency != settings.Currency {
+		on := expense.Timestamp
+		if on.IsZero() {
+			on = time.Now()
+		}
+		var err error
+		total, err = b.currencyService.Convert(ctx, amount, currency, settings.Currency, on)
+		if err != nil {
+			return err
+		}
+	}
+
+	expense.Currency = currency
+	expense.OriginalAmount = amount
+	expense.TotalPrice = total
+	return nil
+}
+
+// convertToHomeCurrency converts expense amounts to the user's home currency at each
+// expense date's rate. It returns a note to show when some expenses had no rate.
+func (b *Bot) convertToHomeCurrency(ctx context.Context, expenses []*models.Expense, settings *models.UserSettings) string {
+	unconverted := b.currencyService.ConvertExpenses(ctx, expenses, settings.Currency)
+	if unconverted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n⚠️ %d expense(s) have no exchange rate to %s and use the amount recorded at entry. Add rates with /rates.",
+		unconverted, settings.Currency)
+}
+
+// downloadFile downloads a file sent to the bot, refusing files larger than maxSize bytes
+func (b *Bot) downloadFile(ctx context.Context, fileID string, maxSize int64) ([]byte, error) {
+	url, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fileDownloadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file is larger than %d KB", maxSize/1024)
+	}
+	return data, nil
+}
+
+// checkExpenseOwnership verifies that the user may modify the expense and returns an error if not:
+// their own personal expenses, or expenses in a shared ledger where they are an owner or editor
 func (b *Bot) checkExpenseOwnership(ctx context.Context, userID int64, expense *models.Expense) error {
 	if expense == nil {
 		return errors.New("expense not found")
@@ -75,15 +170,22 @@ func (b *Bot) checkExpenseOwnership(ctx context.Context, userID int64, expense *
 		return errors.New("could not find your user record")
 	}
 
-	if expense.UserID != user.ID {
-		return errors.New("you can only modify your own expenses")
+	if !expense.LedgerID.Valid {
+		if expense.UserID != user.ID {
+			return errors.New("you can only modify your own expenses")
+		}
+		return nil
+	}
+
+	if err := b.ledgerService.CheckExpenseAccess(ctx, userID, expense); err != nil {
+		return errors.New("you can only modify expenses in ledgers where you are an owner or editor")
 	}
 
 	return nil
 }
 
 // buildExpenseListMessage builds a formatted message showing expenses grouped by category
-func (b *Bot) buildExpenseListMessage(expenses []*models.Expense) string {
+func (b *Bot) buildExpenseListMessage(expenses []*models.Expense, settings *models.UserSettings) string {
 	if len(expenses) == 0 {
 		return "No expenses found."
 	}
@@ -110,18 +212,18 @@ func (b *Bot) buildExpenseListMessage(expenses []*models.Expense) string {
 		sb.WriteString(fmt.Sprintf("📊 %s:\n", category))
 		var prices []float64
 		for _, expense := range categoryExpenses {
-			sb.WriteString(fmt.Sprintf("• %s: %s\n", utils.FormatDate(expense.Timestamp), utils.FormatCurrency(expense.TotalPrice)))
+			sb.WriteString(fmt.Sprintf("• %s: %s\n", settings.FormatDate(expense.Timestamp), formatExpenseAmount(expense, settings)))
 			prices = append(prices, expense.TotalPrice)
 		}
 		total := utils.CalculateTotal(prices)
-		sb.WriteString(fmt.Sprintf("Total: %s\n\n", utils.FormatCurrency(total)))
+		sb.WriteString(fmt.Sprintf("Total: %s\n\n", settings.FormatAmount(total)))
 	}
 
 	return sb.String()
 }
 
 // buildReportMessage builds a formatted expense report message
-func (b *Bot) buildReportMessage(expenses []*models.Expense) string {
+func (b *Bot) buildReportMessage(expenses []*models.Expense, settings *models.UserSettings) string {
 	if len(expenses) == 0 {
 		return "No expenses found to generate report."
 	}
@@ -130,13 +232,17 @@ func (b *Bot) buildReportMessage(expenses []*models.Expense) string {
 	var totalExpense float64
 	categoryTotals := make(map[string]float64)
 	monthlyTotals := make(map[string]float64)
+	foreignTotals := make(map[string]float64)
 
 	for _, expense := range expenses {
 		totalExpense += expense.TotalPrice
 		categoryTotals[expense.CategoryName] += expense.TotalPrice
+		if expense.Currency != "" && expense.Currency != settings.Currency {
+			foreignTotals[expense.Currency] += expense.OriginalAmount
+		}
 
 		// Group by month
-		monthKey := expense.Timestamp.Format("January 2006")
+		monthKey := settings.FormatMonth(expense.Timestamp)
 		monthlyTotals[monthKey] += expense.TotalPrice
 	}
 
@@ -145,7 +251,7 @@ func (b *Bot) buildReportMessage(expenses []*models.Expense) string {
 	sb.WriteString("📊 Expense Report\n\n")
 
 	// Overall total
-	sb.WriteString(fmt.Sprintf("💰 Total Expenses: %s\n\n", utils.FormatCurrency(totalExpense)))
+	sb.WriteString(fmt.Sprintf("💰 Total Expenses: %s\n\n", settings.FormatAmount(totalExpense)))
 
 	// Category breakdown
 	sb.WriteString("📈 Category Breakdown:\n")
@@ -153,7 +259,7 @@ func (b *Bot) buildReportMessage(expenses []*models.Expense) string {
 		percentage := (total / totalExpense) * 100
 		sb.WriteString(fmt.Sprintf("• %s: %s (%.1f%%)\n",
 			category,
-			utils.FormatCurrency(total),
+			settings.FormatAmount(total),
 			percentage))
 	}
 	sb.WriteString("\n")
@@ -161,14 +267,28 @@ func (b *Bot) buildReportMessage(expenses []*models.Expense) string {
 	// Monthly breakdown
 	sb.WriteString("📅 Monthly Breakdown:\n")
 	for month, total := range monthlyTotals {
-		sb.WriteString(fmt.Sprintf("• %s: %s\n", month, utils.FormatCurrency(total)))
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", month, settings.FormatAmount(total)))
+	}
+
+	// Amounts paid in other currencies, before conversion
+	if len(foreignTotals) > 0 {
+		currencies := make([]string, 0, len(foreignTotals))
+		for currency := range foreignTotals {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+
+		sb.WriteString("\n💱 Paid in Other Currencies:\n")
+		for _, currency := range currencies {
+			sb.WriteString(fmt.Sprintf("• %s: %s\n", currency, models.FormatMoney(foreignTotals[currency], currency)))
+		}
 	}
 
 	return sb.String()
 }
 
-// buildDashboardMessage builds a formatted dashboard message
-func (b *Bot) buildDashboardMessage(expenses []*models.Expense) string {
+// buildDashboardMessage builds a formatted dashboard message with a fuel efficiency line per vehicle
+func (b *Bot) buildDashboardMessage(expenses []*models.Expense, vehicles []*models.VehicleStats, settings *models.UserSettings) string {
 	if len(expenses) == 0 {
 		return "No expenses found to show dashboard."
 	}
@@ -187,35 +307,25 @@ func (b *Bot) buildDashboardMessage(expenses []*models.Expense) string {
 	// Calculate dashboard metrics
 	var totalExpense float64
 	var totalFuelExpense float64
-	var totalDistance float64
-	var avgFuelEfficiency float64
-
-	// Calculate metrics
 	for _, expense := range expenses {
 		totalExpense += expense.TotalPrice
-		if expense.CategoryName == "⛽ Petrol" {
+		if expense.IsFuel() {
 			totalFuelExpense += expense.TotalPrice
-			if expense.Odometer > 0 {
-				totalDistance += expense.Odometer
-			}
 		}
 	}
 
-	// Calculate average fuel efficiency if possible
-	if totalFuelExpense > 0 && totalDistance > 0 {
-		avgFuelEfficiency = totalDistance / (totalFuelExpense / 100) // km per 100 rupees
-	}
-
 	// Build dashboard message
 	var sb strings.Builder
 	sb.WriteString("📱 Expense Dashboard\n\n")
 
 	// Overall metrics
 	sb.WriteString("📊 Overall Metrics:\n")
-	sb.WriteString(fmt.Sprintf("• Total Expenses: %s\n", utils.FormatCurrency(totalExpense)))
-	sb.WriteString(fmt.Sprintf("• Total Fuel Expenses: %s\n", utils.FormatCurrency(totalFuelExpense)))
-	if avgFuelEfficiency > 0 {
-		sb.WriteString(fmt.Sprintf("• Average Fuel Efficiency: %.1f km/₹100\n", avgFuelEfficiency))
+	sb.WriteString(fmt.Sprintf("• Total Expenses: %s\n", settings.FormatAmount(totalExpense)))
+	sb.WriteString(fmt.Sprintf("• Total Fuel Expenses: %s\n", settings.FormatAmount(totalFuelExpense)))
+	for _, vehicle := range vehicles {
+		if vehicle.FuelEfficiency > 0 {
+			sb.WriteString(fmt.Sprintf("• %s Fuel Efficiency: %.1f km/%s\n", statsLabel(vehicle), vehicle.FuelEfficiency, vehicle.FuelType.Unit()))
+		}
 	}
 	sb.WriteString("\n")
 
@@ -223,10 +333,29 @@ func (b *Bot) buildDashboardMessage(expenses []*models.Expense) string {
 	sb.WriteString("🕒 Recent Expenses:\n")
 	for _, expense := range recentExpenses {
 		sb.WriteString(fmt.Sprintf("• %s - %s: %s\n",
-			utils.FormatDate(expense.Timestamp),
+			settings.FormatDate(expense.Timestamp),
 			expense.CategoryName,
-			utils.FormatCurrency(expense.TotalPrice)))
+			formatExpenseAmount(expense, settings)))
 	}
 
 	return sb.String()
 }
+
+// formatExpenseLine formats an expense as "date - category: amount" using the user's settings
+func formatExpenseLine(expense *models.Expense, settings *models.UserSettings) string {
+	return fmt.Sprintf("%s - %s: %s",
+		settings.FormatDate(expense.Timestamp),
+		expense.CategoryName,
+		formatExpenseAmount(expense, settings))
+}
+
+// formatExpenseAmount formats the amount of an expense in the user's currency.
+// Expenses paid in another currency show the amount as paid first, e.g. "€45.00 (₹4050.00)".
+func formatExpenseAmount(expense *models.Expense, settings *models.UserSettings) string {
+	if expense.Currency == "" || expense.Currency == settings.Currency {
+		return settings.FormatAmount(expense.TotalPrice)
+	}
+	return fmt.Sprintf("%s (%s)",
+		models.FormatMoney(expense.OriginalAmount, expense.Currency),
+		settings.FormatAmount(expense.TotalPrice))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,8 +19,8 @@ func parseTestDate(dateStr string) time.Time {
 // createTestBot creates a minimal bot instance for testing helper functions
 func createTestBot() *Bot {
 	return &Bot{
-		logger: logger.NewMockLogger(),
-		states: make(map[int64]*models.UserState),
+		logger:     logger.NewMockLogger(),
+		stateStore: statestore.NewMemoryStore(statestore.DefaultTTL),
 	}
 }
 
@@ -85,7 +86,7 @@ func TestBuildExpenseListMessage(t *testing.T) {
 			bot := createTestBot()
 
 			// Execute
-			result := bot.buildExpenseListMessage(tt.expenses)
+			result := bot.buildExpenseListMessage(tt.expenses, models.DefaultUserSettings(0))
 
 			// Assert
 			assert.Equal(t, tt.expectedResult, result)
@@ -123,7 +124,7 @@ func TestBuildReportMessage(t *testing.T) {
 			bot := createTestBot()
 
 			// Execute
-			result := bot.buildReportMessage(tt.expenses)
+			result := bot.buildReportMessage(tt.expenses, models.DefaultUserSettings(0))
 
 			// Assert
 			assert.Equal(t, tt.expectedResult, result)
@@ -146,7 +147,7 @@ func TestBuildReportMessage(t *testing.T) {
 			},
 		}
 
-		result := bot.buildReportMessage(expenses)
+		result := bot.buildReportMessage(expenses, models.DefaultUserSettings(0))
 
 		// Check overall structure
 		assert.Contains(t, result, "📊 Expense Report")
@@ -178,7 +179,7 @@ func TestBuildReportMessage(t *testing.T) {
 			},
 		}
 
-		result := bot.buildReportMessage(expenses)
+		result := bot.buildReportMessage(expenses, models.DefaultUserSettings(0))
 
 		// Check overall structure
 		assert.Contains(t, result, "📊 Expense Report")
@@ -199,6 +200,7 @@ func TestBuildDashboardMessage(t *testing.T) {
 	tests := []struct {
 		name           string
 		expenses       []*models.Expense
+		vehicles       []*models.VehicleStats
 		expectedResult string
 	}{
 		{
@@ -207,7 +209,7 @@ func TestBuildDashboardMessage(t *testing.T) {
 			expectedResult: "No expenses found to show dashboard.",
 		},
 		{
-			name: "fuel_expenses_with_efficiency",
+			name: "fuel_expenses_with_vehicle_efficiency",
 			expenses: []*models.Expense{
 				{
 					CategoryName: "⛽ Petrol",
@@ -216,7 +218,11 @@ func TestBuildDashboardMessage(t *testing.T) {
 					Timestamp:    parseTestDate("2024-01-01"),
 				},
 			},
-			expectedResult: "📱 Expense Dashboard\n\n📊 Overall Metrics:\n• Total Expenses: ₹100.00\n• Total Fuel Expenses: ₹100.00\n• Average Fuel Efficiency: 50.0 km/₹100\n\n🕒 Recent Expenses:\n• 01 Jan 2024 - ⛽ Petrol: ₹100.00\n",
+			vehicles: []*models.VehicleStats{
+				{VehicleType: "CAR", FuelEfficiency: 14.25},
+				{VehicleType: "BIKE"},
+			},
+			expectedResult: "📱 Expense Dashboard\n\n📊 Overall Metrics:\n• Total Expenses: ₹100.00\n• Total Fuel Expenses: ₹100.00\n• 🚗 Car Fuel Efficiency: 14.2 km/L\n\n🕒 Recent Expenses:\n• 01 Jan 2024 - ⛽ Petrol: ₹100.00\n",
 		},
 		{
 			name: "mixed_expenses_no_fuel_efficiency",
@@ -236,7 +242,8 @@ func TestBuildDashboardMessage(t *testing.T) {
 			expectedResult: "📱 Expense Dashboard\n\n📊 Overall Metrics:\n• Total Expenses: ₹300.00\n• Total Fuel Expenses: ₹100.00\n\n🕒 Recent Expenses:\n• 02 Jan 2024 - ⛽ Petrol: ₹100.00\n• 01 Jan 2024 - 🔧 Service: ₹200.00\n",
 		},
 		{
-			name: "multiple_fuel_expenses_with_efficiency",
+			// Odometer readings alone are not distance; efficiency comes from the vehicle stats
+			name: "multiple_fuel_expenses_without_vehicle_stats",
 			expenses: []*models.Expense{
 				{
 					CategoryName: "⛽ Petrol",
@@ -251,7 +258,7 @@ func TestBuildDashboardMessage(t *testing.T) {
 					Timestamp:    parseTestDate("2024-01-02"),
 				},
 			},
-			expectedResult: "📱 Expense Dashboard\n\n📊 Overall Metrics:\n• Total Expenses: ₹300.00\n• Total Fuel Expenses: ₹300.00\n• Average Fuel Efficiency: 50.0 km/₹100\n\n🕒 Recent Expenses:\n• 02 Jan 2024 - ⛽ Petrol: ₹200.00\n• 01 Jan 2024 - ⛽ Petrol: ₹100.00\n",
+			expectedResult: "📱 Expense Dashboard\n\n📊 Overall Metrics:\n• Total Expenses: ₹300.00\n• Total Fuel Expenses: ₹300.00\n\n🕒 Recent Expenses:\n• 02 Jan 2024 - ⛽ Petrol: ₹200.00\n• 01 Jan 2024 - ⛽ Petrol: ₹100.00\n",
 		},
 	}
 
@@ -261,7 +268,7 @@ func TestBuildDashboardMessage(t *testing.T) {
 			bot := createTestBot()
 
 			// Execute
-			result := bot.buildDashboardMessage(tt.expenses)
+			result := bot.buildDashboardMessage(tt.expenses, tt.vehicles, models.DefaultUserSettings(0))
 
 			// Assert
 			assert.Equal(t, tt.expectedResult, result)
@@ -300,7 +307,7 @@ func TestParseTestDate(t *testing.T) {
 func TestBuildExpenseListMessageEdgeCases(t *testing.T) {
 	t.Run("nil_expenses", func(t *testing.T) {
 		bot := createTestBot()
-		result := bot.buildExpenseListMessage(nil)
+		result := bot.buildExpenseListMessage(nil, models.DefaultUserSettings(0))
 		assert.Equal(t, "No expenses found.", result)
 	})
 
@@ -313,7 +320,7 @@ func TestBuildExpenseListMessageEdgeCases(t *testing.T) {
 				Timestamp:    time.Time{}, // Zero time
 			},
 		}
-		result := bot.buildExpenseListMessage(expenses)
+		result := bot.buildExpenseListMessage(expenses, models.DefaultUserSettings(0))
 		assert.Contains(t, result, "⛽ Petrol")
 		assert.Contains(t, result, "₹100.00")
 	})
@@ -322,7 +329,7 @@ func TestBuildExpenseListMessageEdgeCases(t *testing.T) {
 func TestBuildReportMessageEdgeCases(t *testing.T) {
 	t.Run("nil_expenses", func(t *testing.T) {
 		bot := createTestBot()
-		result := bot.buildReportMessage(nil)
+		result := bot.buildReportMessage(nil, models.DefaultUserSettings(0))
 		assert.Equal(t, "No expenses found to generate report.", result)
 	})
 
@@ -335,7 +342,7 @@ func TestBuildReportMessageEdgeCases(t *testing.T) {
 				Timestamp:    parseTestDate("2024-01-01"),
 			},
 		}
-		result := bot.buildReportMessage(expenses)
+		result := bot.buildReportMessage(expenses, models.DefaultUserSettings(0))
 		assert.Contains(t, result, "Total Expenses: ₹0.00")
 		assert.Contains(t, result, "⛽ Petrol: ₹0.00 (NaN%)")
 	})
@@ -344,7 +351,7 @@ func TestBuildReportMessageEdgeCases(t *testing.T) {
 func TestBuildDashboardMessageEdgeCases(t *testing.T) {
 	t.Run("nil_expenses", func(t *testing.T) {
 		bot := createTestBot()
-		result := bot.buildDashboardMessage(nil)
+		result := bot.buildDashboardMessage(nil, nil, models.DefaultUserSettings(0))
 		assert.Equal(t, "No expenses found to show dashboard.", result)
 	})
 
@@ -358,10 +365,27 @@ func TestBuildDashboardMessageEdgeCases(t *testing.T) {
 				Timestamp:    parseTestDate("2024-01-01"),
 			},
 		}
-		result := bot.buildDashboardMessage(expenses)
+		result := bot.buildDashboardMessage(expenses, nil, models.DefaultUserSettings(0))
 		assert.Contains(t, result, "Total Expenses: ₹100.00")
 		assert.Contains(t, result, "Total Fuel Expenses: ₹100.00")
 		// Should not show fuel efficiency with negative odometer
 		assert.NotContains(t, result, "Average Fuel Efficiency")
 	})
 }
+
+func TestForeignCurrencyRendering(t *testing.T) {
+	bot := createTestBot()
+	settings := models.DefaultUserSettings(0)
+	expenses := []*models.Expense{
+		{CategoryName: "✈️ Flights", TotalPrice: 9000, Currency: "EUR", OriginalAmount: 100, Timestamp: parseTestDate("2026-03-09")},
+		{CategoryName: "🏨 Hotels", TotalPrice: 4050, Currency: "EUR", OriginalAmount: 45, Timestamp: parseTestDate("2026-03-10")},
+		{CategoryName: "⛽ Petrol", TotalPrice: 500, Currency: "INR", OriginalAmount: 500, Timestamp: parseTestDate("2026-03-11")},
+	}
+
+	assert.Equal(t, "09 Mar 2026 - ✈️ Flights: €100.00 (₹9000.00)", formatExpenseLine(expenses[0], settings))
+	assert.Equal(t, "11 Mar 2026 - ⛽ Petrol: ₹500.00", formatExpenseLine(expenses[2], settings))
+
+	report := bot.buildReportMessage(expenses, settings)
+	assert.Contains(t, report, "💰 Total Expenses: ₹13550.00")
+	assert.Contains(t, report, "💱 Paid in Other Currencies:\n• EUR: €145.00")
+}
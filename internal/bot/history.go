@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleUndoCommand handles the /undo command, reverting the user's last change to an expense
+func (b *Bot) handleUndoCommand(ctx context.Context, message *tgbotapi.Message) error {
+	change, err := b.historyService.Undo(ctx, message.From.ID)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	return b.sendMessage(ctx, message.Chat.ID, buildUndoMessage(change, b.getUserSettings(ctx, message.From.ID)))
+}
+
+// handleHistoryCommand handles the /history command. "/history 42" shows the changes to
+// expense 42; without an ID the user picks one of their recent expenses.
+func (b *Bot) handleHistoryCommand(ctx context.Context, message *tgbotapi.Message) error {
+	arg := strings.TrimPrefix(strings.TrimSpace(message.CommandArguments()), "#")
+	if arg != "" {
+		expenseID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return b.sendMessage(ctx, message.Chat.ID, "Usage: /history <expense id>, e.g. /history 42")
+		}
+		return b.sendHistory(ctx, message.Chat.ID, message.From.ID, expenseID)
+	}
+
+	expenses, err := b.expenseService.GetExpensesByTelegramID(ctx, message.From.ID, 10, 0)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	if len(expenses) == 0 {
+		return b.sendMessage(ctx, message.Chat.ID, "No expenses found. Deleted expenses are in /trash.")
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Select an expense to see its history:")
+	msg.ReplyMarkup = GetHistoryExpenseKeyboard(expenses, b.getUserSettings(ctx, message.From.ID))
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleHistoryCallback shows the history of an expense picked from the /history list
+func (b *Bot) handleHistoryCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, idData string) error {
+	expenseID, err := strconv.ParseInt(idData, 10, 64)
+	if err != nil {
+		return b.sendMessage(ctx, callback.Message.Chat.ID, "Invalid selection. Please try again.")
+	}
+	return b.sendHistory(ctx, callback.Message.Chat.ID, callback.From.ID, expenseID)
+}
+
+// sendHistory sends the changes to an expense, oldest first
+func (b *Bot) sendHistory(ctx context.Context, chatID, telegramID, expenseID int64) error {
+	expense, history, err := b.historyService.GetHistory(ctx, telegramID, expenseID)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+	return b.sendMessage(ctx, chatID, buildHistoryMessage(expense, history, b.getUserSettings(ctx, telegramID)))
+}
+
+// handleTrashCommand handles the /trash command and the 🗑️ Trash button, listing deleted
+// expenses to restore
+func (b *Bot) handleTrashCommand(ctx context.Context, message *tgbotapi.Message) error {
+	return b.sendTrash(ctx, message.Chat.ID, message.From.ID)
+}
+
+// sendTrash lists the user's deleted expenses with a button to restore each
+func (b *Bot) sendTrash(ctx context.Context, chatID, telegramID int64) error {
+	expenses, err := b.historyService.GetTrash(ctx, telegramID)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+	if len(expenses) == 0 {
+		return b.sendMessage(ctx, chatID, "🗑️ The trash is empty.")
+	}
+
+	settings := b.getUserSettings(ctx, telegramID)
+	var sb strings.Builder
+	sb.WriteString("🗑️ Trash\n\nRecently deleted expenses. Tap one to restore it.\n\n")
+	for _, expense := range expenses {
+		sb.WriteString(fmt.Sprintf("• %s", formatExpenseLine(expense, settings)))
+		if expense.DeletedAt != nil {
+			sb.WriteString(fmt.Sprintf(" (deleted %s)", settings.FormatShortDate(*expense.DeletedAt)))
+		}
+		sb.WriteString("\n")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ReplyMarkup = GetTrashKeyboard(expenses, settings)
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleTrashCallback handles the 🗑️ Trash button and restoring an expense from the trash
+func (b *Bot) handleTrashCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, action string) error {
+	chatID := callback.Message.Chat.ID
+
+	switch {
+	case action == "list":
+		return b.sendTrash(ctx, chatID, callback.From.ID)
+
+	case strings.HasPrefix(action, "restore_"):
+		expenseID, err := strconv.ParseInt(strings.TrimPrefix(action, "restore_"), 10, 64)
+		if err != nil {
+			return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+		}
+		expense, err := b.historyService.RestoreExpense(ctx, callback.From.ID, expenseID)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		msg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+			fmt.Sprintf("♻️ Expense restored.\n%s", formatExpenseLine(expense, b.getUserSettings(ctx, callback.From.ID))))
+		_, err = b.api.Send(msg)
+		return err
+
+	default:
+		return b.sendMessage(ctx, chatID, "Unknown action. Please try again.")
+	}
+}
+
+// buildUndoMessage describes a change /undo reverted
+func buildUndoMessage(change *models.ExpenseHistory, settings *models.UserSettings) string {
+	switch change.Action {
+	case models.HistoryActionCreate:
+		return fmt.Sprintf("↩️ Undone: removed the expense you added.\n%s\n\nIt is in /trash if you want it back.",
+			formatSnapshotLine(change.After, settings))
+	case models.HistoryActionRestore:
+		return fmt.Sprintf("↩️ Undone: moved the restored expense back to the trash.\n%s",
+			formatSnapshotLine(change.After, settings))
+	case models.HistoryActionUpdate:
+		return fmt.Sprintf("↩️ Undone: your edit was reverted.\n%s\n\n%s",
+			formatSnapshotLine(change.Before, settings), describeChanges(change.After, change.Before, settings))
+	case models.HistoryActionDelete:
+		return fmt.Sprintf("↩️ Undone: the deleted expense is back.\n%s", formatSnapshotLine(change.Before, settings))
+	default:
+		return "↩️ Undone."
+	}
+}
+
+// buildHistoryMessage shows an expense and its changes, oldest first
+func buildHistoryMessage(expense *models.Expense, history []*models.ExpenseHistory, settings *models.UserSettings) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📜 History of expense #%d\n", expense.ID))
+	sb.WriteString(formatExpenseLine(expense, settings))
+	if expense.DeletedAt != nil {
+		sb.WriteString(" 🗑️ deleted")
+	}
+	sb.WriteString("\n\n")
+
+	if len(history) == 0 {
+		sb.WriteString("No changes recorded. Expenses added before history was kept have none.")
+		return sb.String()
+	}
+
+	for _, entry := range history {
+		actor := entry.ActorName
+		if actor == "" {
+			actor = "Someone"
+		}
+		sb.WriteString(fmt.Sprintf("• %s %s · %s %s",
+			settings.FormatShortDate(entry.CreatedAt), entry.CreatedAt.Format("15:04"), actor, describeHistoryEntry(entry, settings)))
+		if entry.UndoOf.Valid {
+			sb.WriteString(" (undo)")
+		} else if entry.UndoneAt != nil {
+			sb.WriteString(" (undone)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// describeHistoryEntry says what a change did, e.g. "added it: Dining ₹450.00"
+func describeHistoryEntry(entry *models.ExpenseHistory, settings *models.UserSettings) string {
+	switch entry.Action {
+	case models.HistoryActionCreate:
+		return "added it: " + formatSnapshotLine(entry.After, settings)
+	case models.HistoryActionImport:
+		return "imported it: " + formatSnapshotLine(entry.After, settings)
+	case models.HistoryActionUpdate:
+		return "changed " + describeChanges(entry.Before, entry.After, settings)
+	case models.HistoryActionDelete:
+		return "deleted it"
+	case models.HistoryActionRestore:
+		return "restored it"
+	default:
+		return strings.ToLower(string(entry.Action))
+	}
+}
+
+// describeChanges lists the fields an edit changed, e.g. "amount ₹450.00 → ₹500.00"
+func describeChanges(before, after *models.ExpenseSnapshot, settings *models.UserSettings) string {
+	if before == nil || after == nil {
+		return "the expense"
+	}
+
+	var changes []string
+	if before.CategoryName != after.CategoryName {
+		changes = append(changes, fmt.Sprintf("category %s → %s", before.CategoryName, after.CategoryName))
+	}
+	if before.TotalPrice != after.TotalPrice || before.OriginalAmount != after.OriginalAmount || before.Currency != after.Currency {
+		changes = append(changes, fmt.Sprintf("amount %s → %s",
+			formatExpenseAmount(snapshotExpense(before), settings), formatExpenseAmount(snapshotExpense(after), settings)))
+	}
+	if !before.Timestamp.Equal(after.Timestamp) {
+		changes = append(changes, fmt.Sprintf("date %s → %s", settings.FormatDate(before.Timestamp), settings.FormatDate(after.Timestamp)))
+	}
+	if before.VehicleID != after.VehicleID || before.VehicleType != after.VehicleType {
+		changes = append(changes, "vehicle")
+	}
+	if before.Odometer != after.Odometer {
+		changes = append(changes, fmt.Sprintf("odometer %.0f → %.0f", before.Odometer, after.Odometer))
+	}
+	if before.PetrolPrice != after.PetrolPrice {
+		changes = append(changes, fmt.Sprintf("price per litre %.2f → %.2f", before.PetrolPrice, after.PetrolPrice))
+	}
+	if before.Notes != after.Notes {
+		changes = append(changes, fmt.Sprintf("notes %q → %q", before.Notes, after.Notes))
+	}
+
+	if len(changes) == 0 {
+		return "nothing"
+	}
+	return strings.Join(changes, ", ")
+}
+
+// formatSnapshotLine formats an expense as recorded in its history like formatExpenseLine
+func formatSnapshotLine(snapshot *models.ExpenseSnapshot, settings *models.UserSettings) string {
+	if snapshot == nil {
+		return ""
+	}
+	return formatExpenseLine(snapshotExpense(snapshot), settings)
+}
+
+// snapshotExpense returns an expense with the fields of a history snapshot
+func snapshotExpense(snapshot *models.ExpenseSnapshot) *models.Expense {
+	expense := &models.Expense{}
+	snapshot.ApplyTo(expense)
+	return expense
+}
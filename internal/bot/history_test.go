@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryCommands(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Food"})
+	mockLogger := logger.NewMockLogger()
+	currencyService := services.NewCurrencyService(storage, mockLogger)
+	categoryService := services.NewCategoryService(storage, mockLogger, nil)
+	ledgerService := services.NewLedgerService(storage, mockLogger, currencyService)
+	expenseService := services.NewExpenseService(storage, mockLogger, nil, currencyService, categoryService, ledgerService)
+
+	var sent []tgbotapi.Chattable
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(tgbotapi.Chattable))
+	}).Return(tgbotapi.Message{}, nil)
+
+	bot := &Bot{
+		api:             mockAPI,
+		db:              storage,
+		logger:          mockLogger,
+		expenseService:  expenseService,
+		userService:     services.NewUserService(storage, mockLogger),
+		settingsService: services.NewSettingsService(storage, mockLogger),
+		historyService:  services.NewHistoryService(storage, mockLogger, ledgerService, categoryService, expenseService),
+		stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
+	}
+
+	user := &tgbotapi.User{ID: 12345, UserName: "tester", FirstName: "Asha"}
+	chat := &tgbotapi.Chat{ID: 12345}
+	_, err := bot.userService.GetOrCreateUser(ctx, user.ID, user.UserName, user.FirstName, "")
+	require.NoError(t, err)
+	command := func(text string) *tgbotapi.Message {
+		return &tgbotapi.Message{
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(strings.Fields(text)[0])}},
+			From:     user,
+			Chat:     chat,
+		}
+	}
+	lastText := func() string {
+		require.NotEmpty(t, sent)
+		switch msg := sent[len(sent)-1].(type) {
+		case tgbotapi.MessageConfig:
+			return msg.Text
+		case tgbotapi.EditMessageTextConfig:
+			return msg.Text
+		}
+		return ""
+	}
+
+	expense := &models.Expense{CategoryName: "Dining", TotalPrice: 450, Notes: "lunch", Timestamp: time.Now()}
+	require.NoError(t, bot.expenseService.CreateExpense(ctx, expense, user.ID))
+	edited, err := storage.GetExpenseByID(ctx, expense.ID)
+	require.NoError(t, err)
+	edited.TotalPrice, edited.OriginalAmount = 500, 500
+	require.NoError(t, bot.expenseService.UpdateExpense(ctx, edited, user.ID))
+
+	t.Run("history lists each change", func(t *testing.T) {
+		require.NoError(t, bot.handleMessage(ctx, command(fmt.Sprintf("/history %d", expense.ID))))
+		text := lastText()
+		assert.Contains(t, text, fmt.Sprintf("History of expense #%d", expense.ID))
+		assert.Contains(t, text, "Asha added it: ")
+		assert.Contains(t, text, "Asha changed amount ₹450.00 → ₹500.00")
+
+		require.NoError(t, bot.handleMessage(ctx, command("/history")))
+		keyboard := sent[len(sent)-1].(tgbotapi.MessageConfig).ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		assert.Equal(t, fmt.Sprintf("history_%d", expense.ID), *keyboard.InlineKeyboard[0][0].CallbackData)
+	})
+
+	t.Run("a deleted expense can be brought back with undo", func(t *testing.T) {
+		state := bot.getState(ctx, chat.ID, user.ID)
+		state.DeleteExpense = edited
+		bot.setState(ctx, chat.ID, user.ID, state)
+		require.NoError(t, bot.handleCallbackQuery(ctx, &tgbotapi.CallbackQuery{
+			From: user, Message: &tgbotapi.Message{MessageID: 3, Chat: chat}, Data: "confirm_delete",
+		}))
+		assert.Contains(t, lastText(), "/undo")
+		_, err := storage.GetExpenseByID(ctx, expense.ID)
+		require.True(t, database.IsNotFound(err))
+
+		require.NoError(t, bot.handleMessage(ctx, command("/undo")))
+		assert.Contains(t, lastText(), "the deleted expense is back")
+		_, err = storage.GetExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, bot.handleMessage(ctx, command("/undo")))
+		assert.Contains(t, lastText(), "your edit was reverted")
+		assert.Contains(t, lastText(), "amount ₹500.00 → ₹450.00")
+	})
+
+	t.Run("the trash restores deleted expenses", func(t *testing.T) {
+		require.NoError(t, bot.expenseService.DeleteExpense(ctx, expense.ID, user.ID))
+
+		require.NoError(t, bot.handleMessage(ctx, command("/trash")))
+		msg := sent[len(sent)-1].(tgbotapi.MessageConfig)
+		assert.Contains(t, msg.Text, "Dining")
+		keyboard := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		restore := *keyboard.InlineKeyboard[0][0].CallbackData
+		assert.Equal(t, fmt.Sprintf("trash_restore_%d", expense.ID), restore)
+
+		require.NoError(t, bot.handleCallbackQuery(ctx, &tgbotapi.CallbackQuery{
+			From: user, Message: &tgbotapi.Message{MessageID: 4, Chat: chat}, Data: restore,
+		}))
+		assert.Contains(t, lastText(), "Expense restored")
+		_, err := storage.GetExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, bot.handleMessage(ctx, command("/trash")))
+		assert.Equal(t, "🗑️ The trash is empty.", lastText())
+	})
+}
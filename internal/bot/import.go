@@ -0,0 +1,237 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxImportFileSize is the largest bank statement accepted by /import
+const maxImportFileSize = 2 << 20
+
+// importPreviewLines is the number of expenses listed in the import preview
+const importPreviewLines = 10
+
+// importProfileName matches bank profile names such as "hdfc" or "amex-gold"
+var importProfileName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// handleImportCommand handles the /import command. The optional argument names the bank
+// profile whose column mapping is used, so each bank's statement is mapped only once.
+func (b *Bot) handleImportCommand(ctx context.Context, message *tgbotapi.Message) error {
+	name := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if name == "" {
+		name = models.DefaultImportProfile
+	}
+	if !importProfileName.MatchString(name) {
+		return b.sendMessage(ctx, message.Chat.ID,
+			"Usage: /import [bank]\nThe bank name may use letters, digits, - and _, e.g. /import hdfc")
+	}
+
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	state := models.NewUserState()
+	state.Step = models.StepImportFile
+	state.ImportProfile = name
+	b.setState(ctx, message.Chat.ID, message.From.ID, state)
+
+	return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("📥 Send your bank statement as a CSV file (profile: %s).\n\n"+
+		"The first time, I'll ask which columns hold the date, amount and description and remember them for next time.\n\n"+
+		"Send /cancel to stop.", name))
+}
+
+// handleImportFile reads the statement sent after /import
+func (b *Bot) handleImportFile(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	if message.Document == nil {
+		return b.sendMessage(ctx, message.Chat.ID, "Please send the bank statement as a CSV file, or /cancel.")
+	}
+	if message.Document.FileSize > maxImportFileSize {
+		return b.sendMessage(ctx, message.Chat.ID, "The file is too large. Please send at most 2 MB of transactions at a time.")
+	}
+
+	data, err := b.downloadFile(ctx, message.Document.FileID, maxImportFileSize)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to download statement", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	preview, err := b.importService.PreviewImport(ctx, message.From.ID, state.ImportProfile, data)
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && appErr.IsNotFoundError() {
+		// First statement for this profile: ask for the column mapping
+		header, err := b.importService.ReadColumns(data)
+		if err != nil {
+			return b.sendImportProblem(ctx, message.Chat.ID, err)
+		}
+		key, err := b.stageUpload(ctx, message.Chat.ID, message.From.ID, stagedStatement, data)
+		if err != nil {
+			b.logger.Error(ctx, "Failed to stage statement", logger.ErrorField(err))
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		state.ImportKey = key
+		state.Step = models.StepImportMapping
+		return b.sendMessage(ctx, message.Chat.ID, buildImportColumnsMessage(header))
+	}
+	if err != nil {
+		return b.sendImportProblem(ctx, message.Chat.ID, err)
+	}
+
+	return b.sendImportPreview(ctx, message, state, preview)
+}
+
+// handleImportMapping saves the column mapping sent as "date amount description" column numbers
+func (b *Bot) handleImportMapping(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	dateColumn, amountColumn, descriptionColumn, err := parseImportMapping(message.Text)
+	if err != nil {
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("⚠️ %v\nReply with three column numbers for date, amount and description, e.g. 1 4 2", err))
+	}
+
+	data, err := b.getUpload(ctx, state.ImportKey)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to read staged statement", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	_, err = b.importService.SaveProfile(ctx, message.From.ID, state.ImportProfile, data, dateColumn, amountColumn, descriptionColumn)
+	if err != nil {
+		return b.sendImportProblem(ctx, message.Chat.ID, err)
+	}
+
+	preview, err := b.importService.PreviewImport(ctx, message.From.ID, state.ImportProfile, data)
+	if err != nil {
+		return b.sendImportProblem(ctx, message.Chat.ID, err)
+	}
+	state.ImportKey = ""
+
+	return b.sendImportPreview(ctx, message, state, preview)
+}
+
+// sendImportPreview summarises a statement and asks for confirmation before anything is saved
+func (b *Bot) sendImportPreview(ctx context.Context, message *tgbotapi.Message, state *models.UserState, preview *models.ImportPreview) error {
+	settings := b.getUserSettings(ctx, message.From.ID)
+	if len(preview.Expenses) == 0 {
+		b.clearState(ctx, message.Chat.ID, message.From.ID)
+		return b.sendMessage(ctx, message.Chat.ID, buildImportPreviewMessage(preview, settings))
+	}
+
+	state.ImportExpenses = preview.Expenses
+	state.Step = models.StepImportConfirm
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildImportPreviewMessage(preview, settings))
+	msg.ReplyMarkup = GetImportKeyboard()
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// sendImportProblem explains validation problems such as unreadable dates; other errors are reported as usual
+func (b *Bot) sendImportProblem(ctx context.Context, chatID int64, err error) error {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && appErr.IsValidationError() {
+		return b.sendMessage(ctx, chatID, fmt.Sprintf("⚠️ %s: %s\nPlease try again, or /cancel.", appErr.Message, appErr.Details))
+	}
+	return b.sendError(ctx, chatID, err)
+}
+
+// handleImportCallback handles callback data with the import_ prefix
+func (b *Bot) handleImportCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, action string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	switch action {
+	case "confirm":
+		if state.Step != models.StepImportConfirm || len(state.ImportExpenses) == 0 {
+			return b.sendMessage(ctx, chatID, "This import has expired. Please use /import again.")
+		}
+
+		count, err := b.importService.ImportExpenses(ctx, callback.From.ID, state.ImportExpenses)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		b.clearState(ctx, chatID, callback.From.ID)
+
+		msg := tgbotapi.NewEditMessageText(chatID, messageID,
+			fmt.Sprintf("✅ Imported %d expenses. Use /list or /edit to review them.", count))
+		_, err = b.api.Send(msg)
+		return err
+
+	case "cancel":
+		b.clearState(ctx, chatID, callback.From.ID)
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, "❌ Import cancelled. Nothing was saved.")
+		_, err := b.api.Send(msg)
+		return err
+
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+}
+
+// parseImportMapping parses one-based "date amount description" column numbers into zero-based indexes
+func parseImportMapping(text string) (int, int, int, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	if len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("please give exactly three column numbers")
+	}
+
+	columns := make([]int, len(fields))
+	for i, field := range fields {
+		column, err := strconv.Atoi(field)
+		if err != nil || column < 1 {
+			return 0, 0, 0, fmt.Errorf("invalid column number %q", field)
+		}
+		columns[i] = column - 1
+	}
+	return columns[0], columns[1], columns[2], nil
+}
+
+// buildImportColumnsMessage lists a statement's columns so the user can map them
+func buildImportColumnsMessage(header []string) string {
+	var sb strings.Builder
+	sb.WriteString("🧾 I found these columns:\n\n")
+	for i, column := range header {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, strings.TrimSpace(column)))
+	}
+	sb.WriteString("\nReply with the column numbers for the date, amount and description, e.g. 1 4 2\n")
+	sb.WriteString("Use the withdrawal or debit column for the amount; rows without one are skipped.")
+	return sb.String()
+}
+
+// buildImportPreviewMessage summarises what an import would add
+func buildImportPreviewMessage(preview *models.ImportPreview, settings *models.UserSettings) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📥 Import Preview (%s)\n\n", preview.Profile.Name))
+	sb.WriteString(fmt.Sprintf("🆕 New expenses: %d\n", len(preview.Expenses)))
+	sb.WriteString(fmt.Sprintf("♻️ Already recorded: %d\n", preview.Duplicates))
+	sb.WriteString(fmt.Sprintf("⏭️ Skipped rows: %d\n", preview.Skipped))
+
+	if len(preview.Expenses) == 0 {
+		sb.WriteString("\nNothing new to import.")
+		return sb.String()
+	}
+
+	sb.WriteString("\n")
+	total := 0.0
+	for i, expense := range preview.Expenses {
+		total += expense.TotalPrice
+		if i < importPreviewLines {
+			sb.WriteString(fmt.Sprintf("• %s %s\n", formatExpenseLine(expense, settings), expense.Notes))
+		}
+	}
+	if extra := len(preview.Expenses) - importPreviewLines; extra > 0 {
+		sb.WriteString(fmt.Sprintf("…and %d more\n", extra))
+	}
+	sb.WriteString(fmt.Sprintf("\n💰 Total: %s\n", settings.FormatAmount(total)))
+	sb.WriteString("\nCategories are suggested from the descriptions; you can change them later with /edit.")
+	return sb.String()
+}
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFlow(t *testing.T) {
+	statement := "Txn Date,Narration,Withdrawal,Deposit\n" +
+		"03/03/2026,SWIGGY DINING,450.00,\n" +
+		"04/03/2026,SALARY,,80000.00\n" +
+		"05/03/2026,NEFT RENT MARCH,\"25,000.00\",\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(statement))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Daily Living"})
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Other", Emoji: "📌", Group: "Other"})
+	mockLogger := logger.NewMockLogger()
+	blobs, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	var sent []string
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		switch msg := args.Get(0).(type) {
+		case tgbotapi.MessageConfig:
+			sent = append(sent, msg.Text)
+		case tgbotapi.EditMessageTextConfig:
+			sent = append(sent, msg.Text)
+		}
+	}).Return(tgbotapi.Message{}, nil)
+	mockAPI.On("GetFileDirectURL", "statement").Return(server.URL, nil)
+
+	bot := &Bot{
+		api:             mockAPI,
+		db:              storage,
+		logger:          mockLogger,
+		userService:     services.NewUserService(storage, mockLogger),
+		settingsService: services.NewSettingsService(storage, mockLogger),
+		importService:   services.NewImportService(storage, mockLogger, nil, services.NewCurrencyService(storage, mockLogger), services.NewCategoryService(storage, mockLogger, nil)),
+		stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
+		blobs:           blobs,
+	}
+
+	user := &tgbotapi.User{ID: 12345, UserName: "tester"}
+	chat := &tgbotapi.Chat{ID: 12345}
+	document := &tgbotapi.Message{From: user, Chat: chat, Document: &tgbotapi.Document{FileID: "statement", FileSize: len(statement)}}
+
+	t.Run("first statement asks for the columns", func(t *testing.T) {
+		require.NoError(t, bot.handleImportCommand(ctx, &tgbotapi.Message{
+			Text:     "/import HDFC",
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 7}},
+			From:     user,
+			Chat:     chat,
+		}))
+		// Handlers change the state in place, saved when the update's state session closes
+		ctx, closeState := bot.openState(ctx, user.ID, user.ID)
+		defer closeState()
+		state := bot.getState(ctx, user.ID, user.ID)
+		require.NotNil(t, state)
+		assert.Equal(t, "hdfc", state.ImportProfile)
+
+		require.NoError(t, bot.handleImportFile(ctx, document, state))
+		assert.Equal(t, models.StepImportMapping, state.Step)
+		assert.Contains(t, sent[len(sent)-1], "1. Txn Date\n2. Narration\n3. Withdrawal\n4. Deposit\n")
+
+		// The statement is staged in the blob store, not kept in the state
+		staged, err := blobs.Get(ctx, state.ImportKey)
+		require.NoError(t, err)
+		assert.Equal(t, statement, string(staged))
+	})
+
+	t.Run("mapping shows a preview", func(t *testing.T) {
+		ctx, closeState := bot.openState(ctx, user.ID, user.ID)
+		state := bot.getState(ctx, user.ID, user.ID)
+		key := state.ImportKey
+		require.NoError(t, bot.handleImportMapping(ctx, &tgbotapi.Message{Text: "1 2", From: user, Chat: chat}, state))
+		assert.Equal(t, models.StepImportMapping, state.Step, "invalid mapping is asked again")
+
+		require.NoError(t, bot.handleImportMapping(ctx, &tgbotapi.Message{Text: "1, 3, 2", From: user, Chat: chat}, state))
+		assert.Equal(t, models.StepImportConfirm, state.Step)
+		assert.Empty(t, state.ImportKey)
+		require.Len(t, state.ImportExpenses, 2)
+		assert.Contains(t, sent[len(sent)-1], "🆕 New expenses: 2\n♻️ Already recorded: 0\n⏭️ Skipped rows: 1\n")
+
+		// The staged statement is deleted once the state no longer needs it
+		closeState()
+		_, err := blobs.Get(ctx, key)
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+
+	t.Run("confirm saves the expenses", func(t *testing.T) {
+		require.NoError(t, bot.handleCallbackQuery(ctx, &tgbotapi.CallbackQuery{
+			From:    user,
+			Message: &tgbotapi.Message{MessageID: 1, Chat: chat},
+			Data:    "import_confirm",
+		}))
+		assert.Nil(t, bot.getState(ctx, user.ID, user.ID))
+		assert.Equal(t, "✅ Imported 2 expenses. Use /list or /edit to review them.", sent[len(sent)-1])
+
+		expenses, err := storage.GetExpensesByTelegramID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, expenses, 2)
+	})
+
+	t.Run("the same statement again is all duplicates", func(t *testing.T) {
+		state := models.NewUserState()
+		state.Step = models.StepImportFile
+		state.ImportProfile = "hdfc"
+		bot.setState(ctx, user.ID, user.ID, state)
+
+		require.NoError(t, bot.handleImportFile(ctx, document, state))
+		assert.Contains(t, sent[len(sent)-1], "♻️ Already recorded: 2\n")
+		assert.Contains(t, sent[len(sent)-1], "Nothing new to import.")
+		assert.Nil(t, bot.getState(ctx, user.ID, user.ID))
+	})
+}
+
+func TestParseImportMapping(t *testing.T) {
+	date, amount, description, err := parseImportMapping("1 4 2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 3, 1}, []int{date, amount, description})
+
+	for _, text := range []string{"", "1 2", "1 2 3 4", "a b c", "0 1 2"} {
+		_, _, _, err := parseImportMapping(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestBuildImportPreviewMessage(t *testing.T) {
+	preview := &models.ImportPreview{
+		Profile: &models.ImportProfile{Name: "hdfc"},
+		Expenses: []*models.Expense{
+			{CategoryName: "Dining", TotalPrice: 450, Notes: "SWIGGY DINING", Timestamp: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+		},
+		Duplicates: 2,
+		Skipped:    1,
+	}
+
+	expected := "📥 Import Preview (hdfc)\n\n" +
+		"🆕 New expenses: 1\n" +
+		"♻️ Already recorded: 2\n" +
+		"⏭️ Skipped rows: 1\n\n" +
+		"• 03 Mar 2026 - Dining: ₹450.00 SWIGGY DINING\n" +
+		"\n💰 Total: ₹450.00\n" +
+		"\nCategories are suggested from the descriptions; you can change them later with /edit."
+	assert.Equal(t, expected, buildImportPreviewMessage(preview, models.DefaultUserSettings(0)))
+}
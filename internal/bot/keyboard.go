@@ -3,6 +3,7 @@ package bot
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -28,32 +29,25 @@ func GetMainMenuKeyboard() tgbotapi.ReplyKeyboardMarkup {
 	return keyboard
 }
 
-// GetCategoryKeyboard returns the category group keyboard
-func GetCategoryKeyboard() tgbotapi.InlineKeyboardMarkup {
-	// This will be replaced with a dynamic version that fetches from DB
-	// For now, return a static keyboard with the main groups
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🚗 Vehicle", "group_Vehicle"),
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Home", "group_Home"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏪 Daily Living", "group_Daily Living"),
-			tgbotapi.NewInlineKeyboardButtonData("🎬 Entertainment", "group_Entertainment"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏥 Health", "group_Health"),
-			tgbotapi.NewInlineKeyboardButtonData("📚 Education", "group_Education"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✈️ Travel", "group_Travel"),
-			tgbotapi.NewInlineKeyboardButtonData("💰 Investments", "group_Investments"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🎁 Gifts", "group_Gifts"),
-			tgbotapi.NewInlineKeyboardButtonData("📌 Other", "group_Other"),
-		),
-	)
+// GetCategoryKeyboard returns the category group keyboard, two groups per row in the given order.
+// Groups without categories to pick are left out.
+func GetCategoryKeyboard(groups []*models.CategoryGroup) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, group := range groups {
+		if len(group.Categories) == 0 {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(group.Emoji+" "+group.Name, "group_"+group.Name))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // GetCategoryGroupKeyboard returns the category group keyboard
@@ -120,6 +114,61 @@ func GetBudgetKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 }
 
+// GetBudgetPeriodKeyboard returns the budget period selection keyboard
+func GetBudgetPeriodKeyboard() tgbotapi.InlineKeyboardMarkup {
+	keyboard := [][]tgbotapi.InlineKeyboardButton{
+		{
+			tgbotapi.NewInlineKeyboardButtonData("📆 Daily", "budget_period_daily"),
+			tgbotapi.NewInlineKeyboardButtonData("🗓️ Weekly", "budget_period_weekly"),
+		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData("📅 Monthly", "budget_period_monthly"),
+			tgbotapi.NewInlineKeyboardButtonData("📊 Yearly", "budget_period_yearly"),
+		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "budget_menu"),
+		},
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// GetBudgetSettingsKeyboard returns a keyboard to manage each active budget
+func GetBudgetSettingsKeyboard(budgets []*models.Budget) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(budgets)+1)
+
+	for _, budget := range budgets {
+		label := periodLabel(budget.Period)
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData("🏷️ "+label+" Limits", fmt.Sprintf("budget_limits_%d", budget.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🛑 Stop "+label, fmt.Sprintf("budget_deactivate_%d", budget.ID)),
+		})
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "budget_menu"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetRecurringKeyboard returns pause/resume and delete buttons for each recurring expense
+func GetRecurringKeyboard(recurring []*models.RecurringExpense) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(recurring)+1)
+
+	for _, r := range recurring {
+		toggle := tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⏸ Pause #%d", r.ID), fmt.Sprintf("recurring_pause_%d", r.ID))
+		if !r.IsActive {
+			toggle = tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("▶️ Resume #%d", r.ID), fmt.Sprintf("recurring_resume_%d", r.ID))
+		}
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			toggle,
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🗑️ Delete #%d", r.ID), fmt.Sprintf("recurring_delete_%d", r.ID)),
+		})
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("➕ Add Recurring", "recurring_add"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
 // GetReminderKeyboard returns the reminder management keyboard
 func GetReminderKeyboard() tgbotapi.InlineKeyboardMarkup {
 	keyboard := [][]tgbotapi.InlineKeyboardButton{
@@ -138,6 +187,50 @@ func GetReminderKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 }
 
+// GetReminderSelectKeyboard returns a keyboard for picking a reminder to edit or delete
+func GetReminderSelectKeyboard(reminders []*models.Reminder, action string) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(reminders)+1)
+
+	for _, r := range reminders {
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%02d:%02d %s", r.Hour, r.Minute, r.Message), fmt.Sprintf("reminder_%s_%d", action, r.ID)),
+		})
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "back_to_main"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetVehicleKeyboard returns a keyboard for picking one of the user's vehicles, with callback data
+// prefix followed by the vehicle ID. Users without vehicles pick a vehicle type instead, with
+// callback data prefix followed by the type.
+func GetVehicleKeyboard(vehicles []*models.Vehicle, prefix string) tgbotapi.InlineKeyboardMarkup {
+	if len(vehicles) == 0 {
+		return tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🚗 Car", prefix+string(models.VehicleTypeCar)),
+				tgbotapi.NewInlineKeyboardButtonData("🏍️ Bike", prefix+string(models.VehicleTypeBike)),
+			),
+		)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, vehicle := range vehicles {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			vehicleEmoji(vehicle.Type)+" "+vehicle.Name, fmt.Sprintf("%s%d", prefix, vehicle.ID)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
 // GetSettingsKeyboard returns the settings keyboard
 func GetSettingsKeyboard() tgbotapi.InlineKeyboardMarkup {
 	keyboard := [][]tgbotapi.InlineKeyboardButton{
@@ -156,8 +249,60 @@ func GetSettingsKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 }
 
+// GetCurrencyKeyboard returns the currency selection keyboard
+func GetCurrencyKeyboard() tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(models.SupportedCurrencies)/2+2)
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, c := range models.SupportedCurrencies {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %s", strings.TrimSpace(c.Symbol), c.Code), "settings_currency_"+c.Code))
+		if len(row) == 2 {
+			buttons = append(buttons, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		buttons = append(buttons, row)
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "back_to_main"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetDateFormatKeyboard returns the date format selection keyboard
+func GetDateFormatKeyboard() tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(models.SupportedDateFormats)+1)
+
+	for i, f := range models.SupportedDateFormats {
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(settingsSampleDate.Format(f.Layout), fmt.Sprintf("settings_date_%d", i)),
+		})
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "back_to_main"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetLanguageKeyboard returns the language selection keyboard
+func GetLanguageKeyboard() tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(models.SupportedLanguages)+1)
+
+	for _, l := range models.SupportedLanguages {
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(l.Name, "settings_language_"+l.Code),
+		})
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "back_to_main"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
 // GetEditExpenseKeyboard returns the edit expense selection keyboard
-func GetEditExpenseKeyboard(expenses []*models.Expense) tgbotapi.InlineKeyboardMarkup {
+func GetEditExpenseKeyboard(expenses []*models.Expense, settings *models.UserSettings) tgbotapi.InlineKeyboardMarkup {
 	maxExpenses := 10
 	expensesToShow := expenses
 	if len(expenses) > maxExpenses {
@@ -166,10 +311,10 @@ func GetEditExpenseKeyboard(expenses []*models.Expense) tgbotapi.InlineKeyboardM
 	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(expensesToShow)+1)
 
 	for _, expense := range expensesToShow {
-		buttonText := fmt.Sprintf("%s - %s: ₹%.2f",
-			expense.Timestamp.Format("02 Jan"),
+		buttonText := fmt.Sprintf("%s - %s: %s",
+			settings.FormatShortDate(expense.Timestamp),
 			expense.CategoryName,
-			expense.TotalPrice)
+			formatExpenseAmount(expense, settings))
 		callbackData := fmt.Sprintf("edit_%d", expense.ID)
 		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
 			tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData),
@@ -182,7 +327,7 @@ func GetEditExpenseKeyboard(expenses []*models.Expense) tgbotapi.InlineKeyboardM
 }
 
 // GetDeleteExpenseKeyboard returns the delete expense selection keyboard
-func GetDeleteExpenseKeyboard(expenses []*models.Expense) tgbotapi.InlineKeyboardMarkup {
+func GetDeleteExpenseKeyboard(expenses []*models.Expense, settings *models.UserSettings) tgbotapi.InlineKeyboardMarkup {
 	maxExpenses := 10
 	expensesToShow := expenses
 	if len(expenses) > maxExpenses {
@@ -191,10 +336,10 @@ func GetDeleteExpenseKeyboard(expenses []*models.Expense) tgbotapi.InlineKeyboar
 	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(expensesToShow)+1)
 
 	for _, expense := range expensesToShow {
-		buttonText := fmt.Sprintf("%s - %s: ₹%.2f",
-			expense.Timestamp.Format("02 Jan"),
+		buttonText := fmt.Sprintf("%s - %s: %s",
+			settings.FormatShortDate(expense.Timestamp),
 			expense.CategoryName,
-			expense.TotalPrice)
+			formatExpenseAmount(expense, settings))
 		callbackData := fmt.Sprintf("delete_%d", expense.ID)
 		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
 			tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData),
@@ -202,10 +347,117 @@ func GetDeleteExpenseKeyboard(expenses []*models.Expense) tgbotapi.InlineKeyboar
 	}
 	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back to Main Menu", "back_to_main"),
+		tgbotapi.NewInlineKeyboardButtonData("🗑️ Trash", "trash_list"),
 	})
 	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
 }
 
+// GetHistoryExpenseKeyboard returns the expense selection keyboard for /history
+func GetHistoryExpenseKeyboard(expenses []*models.Expense, settings *models.UserSettings) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(expenses))
+	for _, expense := range expenses {
+		buttonText := fmt.Sprintf("%s - %s: %s",
+			settings.FormatShortDate(expense.Timestamp),
+			expense.CategoryName,
+			formatExpenseAmount(expense, settings))
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("history_%d", expense.ID)),
+		})
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetTrashKeyboard returns a restore button for each deleted expense in the trash
+func GetTrashKeyboard(expenses []*models.Expense, settings *models.UserSettings) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(expenses))
+	for _, expense := range expenses {
+		buttonText := fmt.Sprintf("♻️ %s - %s: %s",
+			settings.FormatShortDate(expense.Timestamp),
+			expense.CategoryName,
+			formatExpenseAmount(expense, settings))
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("trash_restore_%d", expense.ID)),
+		})
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetQuickAddKeyboard returns the keyboard confirming an expense added in one line
+func GetQuickAddKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Save", "quick_save"),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Edit", "quick_edit"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "quick_cancel"),
+		),
+	)
+}
+
+// GetImportKeyboard returns the keyboard confirming a bank statement import
+func GetImportKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Import", "import_confirm"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "import_cancel"),
+		),
+	)
+}
+
+// GetSplitExpenseKeyboard returns the shared expense selection keyboard for /split
+func GetSplitExpenseKeyboard(expenses []*models.Expense, settings *models.UserSettings) tgbotapi.InlineKeyboardMarkup {
+	maxExpenses := 10
+	expensesToShow := expenses
+	if len(expenses) > maxExpenses {
+		expensesToShow = expenses[:maxExpenses]
+	}
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(expensesToShow)+1)
+
+	for _, expense := range expensesToShow {
+		buttonText := fmt.Sprintf("%s - %s: %s",
+			settings.FormatShortDate(expense.Timestamp),
+			expense.CategoryName,
+			formatExpenseAmount(expense, settings))
+		callbackData := fmt.Sprintf("split_expense_%d", expense.ID)
+		buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData),
+		})
+	}
+	buttons = append(buttons, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "split_cancel"),
+	})
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// GetSplitMethodKeyboard returns the keyboard choosing how to split an expense
+func GetSplitMethodKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚖️ Equally", "split_method_equal"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔢 By Shares", "split_method_shares"),
+			tgbotapi.NewInlineKeyboardButtonData("💵 Exact Amounts", "split_method_exact"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "split_cancel"),
+		),
+	)
+}
+
+// GetSearchPageKeyboard returns the previous and next page buttons for search results
+func GetSearchPageKeyboard(page, pages int) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("◀️ Previous", fmt.Sprintf("search_page_%d", page-1)))
+	}
+	if page < pages {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("Next ▶️", fmt.Sprintf("search_page_%d", page+1)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
 // GetEditFieldKeyboard returns the edit field selection keyboard
 func GetEditFieldKeyboard() tgbotapi.InlineKeyboardMarkup {
 	keyboard := [][]tgbotapi.InlineKeyboardButton{
@@ -221,6 +473,9 @@ func GetEditFieldKeyboard() tgbotapi.InlineKeyboardMarkup {
 			tgbotapi.NewInlineKeyboardButtonData("💰 Total Price", "edit_field_total"),
 			tgbotapi.NewInlineKeyboardButtonData("📝 Notes", "edit_field_notes"),
 		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData("📎 Receipt", "edit_field_receipt"),
+		},
 		{
 			tgbotapi.NewInlineKeyboardButtonData("✅ Save Changes", "edit_save"),
 			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "edit_cancel"),
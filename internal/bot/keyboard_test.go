@@ -36,7 +36,12 @@ func TestGetMainMenuKeyboard(t *testing.T) {
 
 func TestGetCategoryKeyboard(t *testing.T) {
 	t.Run("should create category keyboard", func(t *testing.T) {
-		keyboard := GetCategoryKeyboard()
+		groups := make([]*models.CategoryGroup, 0, len(models.GetCategoryGroups()))
+		for _, group := range models.GetCategoryGroups() {
+			groups = append(groups, &group)
+		}
+
+		keyboard := GetCategoryKeyboard(groups)
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 5) // 5 rows
 
@@ -46,6 +51,22 @@ func TestGetCategoryKeyboard(t *testing.T) {
 		require.NotNil(t, firstButton.CallbackData)
 		require.Equal(t, "group_Vehicle", *firstButton.CallbackData)
 	})
+
+	t.Run("should skip groups without categories", func(t *testing.T) {
+		groups := []*models.CategoryGroup{
+			{Name: "Vehicle", Emoji: "🚗", Categories: []models.CategoryType{models.CategoryPetrol}},
+			{Name: "Kids", Emoji: "🧸"},
+			{Name: "Pets", Emoji: "🐾", Categories: []models.CategoryType{"🐶 Pet Food"}},
+			{Name: "Other", Emoji: "📌", Categories: []models.CategoryType{models.CategoryOther}},
+		}
+
+		keyboard := GetCategoryKeyboard(groups)
+		require.Len(t, keyboard.InlineKeyboard, 2)
+		require.Equal(t, "🐾 Pets", keyboard.InlineKeyboard[0][1].Text)
+		require.Equal(t, "group_Pets", *keyboard.InlineKeyboard[0][1].CallbackData)
+		require.Len(t, keyboard.InlineKeyboard[1], 1)
+		require.Equal(t, "📌 Other", keyboard.InlineKeyboard[1][0].Text)
+	})
 }
 
 func TestGetCategoryGroupKeyboard(t *testing.T) {
@@ -157,6 +178,54 @@ func TestGetBudgetKeyboard(t *testing.T) {
 	})
 }
 
+func TestGetBudgetPeriodKeyboard(t *testing.T) {
+	t.Run("should create budget period keyboard", func(t *testing.T) {
+		keyboard := GetBudgetPeriodKeyboard()
+		require.Len(t, keyboard.InlineKeyboard, 3) // 3 rows
+
+		require.Equal(t, "budget_period_daily", *keyboard.InlineKeyboard[0][0].CallbackData)
+		require.Equal(t, "budget_period_weekly", *keyboard.InlineKeyboard[0][1].CallbackData)
+		require.Equal(t, "budget_period_monthly", *keyboard.InlineKeyboard[1][0].CallbackData)
+		require.Equal(t, "budget_period_yearly", *keyboard.InlineKeyboard[1][1].CallbackData)
+		require.Equal(t, "budget_menu", *keyboard.InlineKeyboard[2][0].CallbackData)
+	})
+}
+
+func TestGetBudgetSettingsKeyboard(t *testing.T) {
+	t.Run("should create a row per budget plus back button", func(t *testing.T) {
+		budgets := []*models.Budget{
+			{ID: 3, Period: models.BudgetPeriodWeekly},
+			{ID: 7, Period: models.BudgetPeriodMonthly},
+		}
+		keyboard := GetBudgetSettingsKeyboard(budgets)
+		require.Len(t, keyboard.InlineKeyboard, 3)
+
+		require.Equal(t, "🏷️ Weekly Limits", keyboard.InlineKeyboard[0][0].Text)
+		require.Equal(t, "budget_limits_3", *keyboard.InlineKeyboard[0][0].CallbackData)
+		require.Equal(t, "🛑 Stop Monthly", keyboard.InlineKeyboard[1][1].Text)
+		require.Equal(t, "budget_deactivate_7", *keyboard.InlineKeyboard[1][1].CallbackData)
+		require.Equal(t, "budget_menu", *keyboard.InlineKeyboard[2][0].CallbackData)
+	})
+}
+
+func TestGetRecurringKeyboard(t *testing.T) {
+	t.Run("should toggle pause and resume by status", func(t *testing.T) {
+		recurring := []*models.RecurringExpense{
+			{ID: 4, IsActive: true},
+			{ID: 9, IsActive: false},
+		}
+		keyboard := GetRecurringKeyboard(recurring)
+		require.Len(t, keyboard.InlineKeyboard, 3)
+
+		require.Equal(t, "⏸ Pause #4", keyboard.InlineKeyboard[0][0].Text)
+		require.Equal(t, "recurring_pause_4", *keyboard.InlineKeyboard[0][0].CallbackData)
+		require.Equal(t, "▶️ Resume #9", keyboard.InlineKeyboard[1][0].Text)
+		require.Equal(t, "recurring_resume_9", *keyboard.InlineKeyboard[1][0].CallbackData)
+		require.Equal(t, "recurring_delete_9", *keyboard.InlineKeyboard[1][1].CallbackData)
+		require.Equal(t, "recurring_add", *keyboard.InlineKeyboard[2][0].CallbackData)
+	})
+}
+
 func TestGetReminderKeyboard(t *testing.T) {
 	t.Run("should create reminder keyboard", func(t *testing.T) {
 		keyboard := GetReminderKeyboard()
@@ -182,6 +251,48 @@ func TestGetReminderKeyboard(t *testing.T) {
 	})
 }
 
+func TestGetReminderSelectKeyboard(t *testing.T) {
+	t.Run("should list reminders for the chosen action", func(t *testing.T) {
+		reminders := []*models.Reminder{
+			{ID: 3, Message: "Log expenses", Hour: 21},
+			{ID: 7, Message: "Petrol check", Hour: 9, Minute: 30},
+		}
+		keyboard := GetReminderSelectKeyboard(reminders, "delete")
+		require.Len(t, keyboard.InlineKeyboard, 3)
+
+		require.Equal(t, "21:00 Log expenses", keyboard.InlineKeyboard[0][0].Text)
+		require.Equal(t, "reminder_delete_3", *keyboard.InlineKeyboard[0][0].CallbackData)
+		require.Equal(t, "09:30 Petrol check", keyboard.InlineKeyboard[1][0].Text)
+		require.Equal(t, "reminder_delete_7", *keyboard.InlineKeyboard[1][0].CallbackData)
+		require.Equal(t, "back_to_main", *keyboard.InlineKeyboard[2][0].CallbackData)
+	})
+}
+
+func TestGetVehicleKeyboard(t *testing.T) {
+	t.Run("should list vehicles two per row", func(t *testing.T) {
+		vehicles := []*models.Vehicle{
+			{ID: 4, Name: "Swift", Type: models.VehicleTypeCar},
+			{ID: 5, Name: "Ather", Type: models.VehicleTypeScooter},
+			{ID: 9, Name: "Pulsar", Type: models.VehicleTypeBike},
+		}
+		keyboard := GetVehicleKeyboard(vehicles, "vehicle_")
+		require.Len(t, keyboard.InlineKeyboard, 2)
+		require.Len(t, keyboard.InlineKeyboard[0], 2)
+
+		require.Equal(t, "🚗 Swift", keyboard.InlineKeyboard[0][0].Text)
+		require.Equal(t, "vehicle_4", *keyboard.InlineKeyboard[0][0].CallbackData)
+		require.Equal(t, "🛵 Ather", keyboard.InlineKeyboard[0][1].Text)
+		require.Equal(t, "edit_vehicle_9", *GetVehicleKeyboard(vehicles, "edit_vehicle_").InlineKeyboard[1][0].CallbackData)
+	})
+
+	t.Run("should offer vehicle types without vehicles", func(t *testing.T) {
+		keyboard := GetVehicleKeyboard(nil, "vehicle_")
+		require.Len(t, keyboard.InlineKeyboard, 1)
+		require.Equal(t, "vehicle_CAR", *keyboard.InlineKeyboard[0][0].CallbackData)
+		require.Equal(t, "vehicle_BIKE", *keyboard.InlineKeyboard[0][1].CallbackData)
+	})
+}
+
 func TestGetSettingsKeyboard(t *testing.T) {
 	t.Run("should create settings keyboard", func(t *testing.T) {
 		keyboard := GetSettingsKeyboard()
@@ -214,7 +325,7 @@ func TestGetEditExpenseKeyboard(t *testing.T) {
 			{ID: 2, CategoryName: "Food", TotalPrice: 50.0, Timestamp: time.Now()},
 		}
 
-		keyboard := GetEditExpenseKeyboard(expenses)
+		keyboard := GetEditExpenseKeyboard(expenses, models.DefaultUserSettings(0))
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 3) // 2 expenses + 1 back button
 
@@ -243,7 +354,7 @@ func TestGetEditExpenseKeyboard(t *testing.T) {
 			}
 		}
 
-		keyboard := GetEditExpenseKeyboard(expenses)
+		keyboard := GetEditExpenseKeyboard(expenses, models.DefaultUserSettings(0))
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 11) // 10 expenses + 1 back button
 
@@ -256,7 +367,7 @@ func TestGetEditExpenseKeyboard(t *testing.T) {
 	t.Run("should create edit expense keyboard with no expenses", func(t *testing.T) {
 		expenses := []*models.Expense{}
 
-		keyboard := GetEditExpenseKeyboard(expenses)
+		keyboard := GetEditExpenseKeyboard(expenses, models.DefaultUserSettings(0))
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 1) // Only back button
 
@@ -275,7 +386,7 @@ func TestGetDeleteExpenseKeyboard(t *testing.T) {
 			{ID: 2, CategoryName: "Food", TotalPrice: 50.0, Timestamp: time.Now()},
 		}
 
-		keyboard := GetDeleteExpenseKeyboard(expenses)
+		keyboard := GetDeleteExpenseKeyboard(expenses, models.DefaultUserSettings(0))
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 3) // 2 expenses + 1 back button
 
@@ -291,6 +402,11 @@ func TestGetDeleteExpenseKeyboard(t *testing.T) {
 		require.Equal(t, "⬅️ Back to Main Menu", backButton.Text)
 		require.NotNil(t, backButton.CallbackData)
 		require.Equal(t, "back_to_main", *backButton.CallbackData)
+
+		// Check trash button
+		trashButton := keyboard.InlineKeyboard[2][1]
+		require.Equal(t, "🗑️ Trash", trashButton.Text)
+		require.Equal(t, "trash_list", *trashButton.CallbackData)
 	})
 
 	t.Run("should create delete expense keyboard with more than 10 expenses", func(t *testing.T) {
@@ -304,7 +420,7 @@ func TestGetDeleteExpenseKeyboard(t *testing.T) {
 			}
 		}
 
-		keyboard := GetDeleteExpenseKeyboard(expenses)
+		keyboard := GetDeleteExpenseKeyboard(expenses, models.DefaultUserSettings(0))
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 11) // 10 expenses + 1 back button
 
@@ -317,7 +433,7 @@ func TestGetDeleteExpenseKeyboard(t *testing.T) {
 	t.Run("should create delete expense keyboard with no expenses", func(t *testing.T) {
 		expenses := []*models.Expense{}
 
-		keyboard := GetDeleteExpenseKeyboard(expenses)
+		keyboard := GetDeleteExpenseKeyboard(expenses, models.DefaultUserSettings(0))
 		require.NotNil(t, keyboard)
 		require.Len(t, keyboard.InlineKeyboard, 1) // Only back button
 
@@ -333,7 +449,7 @@ func TestGetEditFieldKeyboard(t *testing.T) {
 	t.Run("should create edit field keyboard", func(t *testing.T) {
 		keyboard := GetEditFieldKeyboard()
 		require.NotNil(t, keyboard)
-		require.Len(t, keyboard.InlineKeyboard, 4) // 4 rows
+		require.Len(t, keyboard.InlineKeyboard, 5) // 5 rows
 
 		// Check first row
 		categoryButton := keyboard.InlineKeyboard[0][0]
@@ -369,14 +485,38 @@ func TestGetEditFieldKeyboard(t *testing.T) {
 		require.Equal(t, "edit_field_notes", *notesButton.CallbackData)
 
 		// Check fourth row
-		saveButton := keyboard.InlineKeyboard[3][0]
+		receiptButton := keyboard.InlineKeyboard[3][0]
+		require.Equal(t, "📎 Receipt", receiptButton.Text)
+		require.NotNil(t, receiptButton.CallbackData)
+		require.Equal(t, "edit_field_receipt", *receiptButton.CallbackData)
+
+		// Check fifth row
+		saveButton := keyboard.InlineKeyboard[4][0]
 		require.Equal(t, "✅ Save Changes", saveButton.Text)
 		require.NotNil(t, saveButton.CallbackData)
 		require.Equal(t, "edit_save", *saveButton.CallbackData)
 
-		cancelButton := keyboard.InlineKeyboard[3][1]
+		cancelButton := keyboard.InlineKeyboard[4][1]
 		require.Equal(t, "❌ Cancel", cancelButton.Text)
 		require.NotNil(t, cancelButton.CallbackData)
 		require.Equal(t, "edit_cancel", *cancelButton.CallbackData)
 	})
 }
+
+func TestGetSplitKeyboards(t *testing.T) {
+	expenses := []*models.Expense{
+		{ID: 7, CategoryName: "Rent", TotalPrice: 900.0, Timestamp: time.Now()},
+	}
+
+	keyboard := GetSplitExpenseKeyboard(expenses, models.DefaultUserSettings(0))
+	require.Len(t, keyboard.InlineKeyboard, 2) // 1 expense + cancel
+	require.Contains(t, keyboard.InlineKeyboard[0][0].Text, "Rent")
+	require.Equal(t, "split_expense_7", *keyboard.InlineKeyboard[0][0].CallbackData)
+	require.Equal(t, "split_cancel", *keyboard.InlineKeyboard[1][0].CallbackData)
+
+	methods := GetSplitMethodKeyboard()
+	require.Len(t, methods.InlineKeyboard, 3)
+	require.Equal(t, "split_method_equal", *methods.InlineKeyboard[0][0].CallbackData)
+	require.Equal(t, "split_method_shares", *methods.InlineKeyboard[1][0].CallbackData)
+	require.Equal(t, "split_method_exact", *methods.InlineKeyboard[1][1].CallbackData)
+}
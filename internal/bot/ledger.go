@@ -0,0 +1,249 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const ledgerUsage = `Usage:
+/ledger - List your shared ledgers
+/ledger create Home - Create a shared ledger and get its invite code
+/ledger join K7QM2XPA - Join a ledger with its invite code
+/ledger use Home - Add your new expenses to a ledger; /ledger use personal to stop
+/ledger report [Home] - This month's combined spending by member and category
+/ledger members [Home] - List members and their roles
+/ledger role @anna viewer [in Home] - Make a member an owner, editor or viewer (owners only)
+/ledger leave [Home] - Leave a ledger; your expenses stay in it
+/ledger link Home - In a group chat, log everyone's expenses here to a ledger (owners only)
+/ledger unlink - In a group chat, stop logging expenses here to its ledger (owners only)`
+
+// handleLedgerCommand handles the /ledger command: listing and managing shared ledgers
+func (b *Bot) handleLedgerCommand(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		ledgers, err := b.ledgerService.GetLedgers(ctx, message.From.ID)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildLedgersMessage(ledgers))
+	}
+
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	name := strings.Join(args[1:], " ")
+
+	switch strings.ToLower(args[0]) {
+	case "create":
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, ledgerUsage)
+		}
+		ledger, err := b.ledgerService.CreateLedger(ctx, message.From.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		// A ledger created in a group chat is the chat's ledger
+		if isGroupChat(message.Chat) {
+			if _, err := b.ledgerService.LinkChat(ctx, message.From.ID, message.Chat.ID, ledger.Name); err != nil {
+				return b.sendError(ctx, message.Chat.ID, err)
+			}
+			return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Created %s. Expenses anyone logs in this chat now go to it.", ledger.Name))
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Created %s. Others can join with /ledger join %s\n\nSend /ledger use %s to add your expenses to it.", ledger.Name, ledger.InviteCode, ledger.Name))
+
+	case "join":
+		if len(args) != 2 {
+			return b.sendMessage(ctx, message.Chat.ID, ledgerUsage)
+		}
+		ledger, err := b.ledgerService.JoinLedger(ctx, message.From.ID, args[1])
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("👋 You joined %s. Send /ledger use %s to add your expenses to it.", ledger.Name, ledger.Name))
+
+	case "use":
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, ledgerUsage)
+		}
+		ledger, err := b.ledgerService.UseLedger(ctx, message.From.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		if ledger == nil {
+			return b.sendMessage(ctx, message.Chat.ID, "👤 Your new expenses are personal again.")
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("📒 Your new expenses go to %s.", ledger.Name))
+
+	case "link":
+		if !isGroupChat(message.Chat) {
+			return b.sendMessage(ctx, message.Chat.ID, "Add me to a group chat and send /ledger link there.")
+		}
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, ledgerUsage)
+		}
+		ledger, err := b.ledgerService.LinkChat(ctx, message.From.ID, message.Chat.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("🔗 Expenses anyone logs in this chat now go to %s.", ledger.Name))
+
+	case "unlink":
+		if !isGroupChat(message.Chat) {
+			return b.sendMessage(ctx, message.Chat.ID, "Send /ledger unlink in the group chat to unlink.")
+		}
+		ledger, err := b.ledgerService.UnlinkChat(ctx, message.From.ID, message.Chat.ID)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✂️ Expenses logged in this chat no longer go to %s.", ledger.Name))
+
+	case "report":
+		name, err := b.groupLedgerName(ctx, message, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		report, err := b.ledgerService.GetLedgerReport(ctx, message.From.ID, name, time.Time{}, time.Time{})
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildLedgerReportMessage(report, b.getUserSettings(ctx, message.From.ID)))
+
+	case "members":
+		name, err := b.groupLedgerName(ctx, message, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		ledger, members, err := b.ledgerService.GetMembers(ctx, message.From.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildLedgerMembersMessage(ledger, members))
+
+	case "role":
+		roleArgs, ledgerArgs, _ := splitArgsAt(args[1:], "in")
+		if len(roleArgs) < 2 {
+			return b.sendMessage(ctx, message.Chat.ID, ledgerUsage)
+		}
+		memberName := strings.Join(roleArgs[:len(roleArgs)-1], " ")
+		role := models.LedgerRole(roleArgs[len(roleArgs)-1])
+		member, err := b.ledgerService.SetMemberRole(ctx, message.From.ID, strings.Join(ledgerArgs, " "), memberName, role)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ %s is now %s.", member.DisplayName(), formatLedgerRole(member.Role)))
+
+	case "leave":
+		ledger, deleted, err := b.ledgerService.LeaveLedger(ctx, message.From.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		if deleted {
+			return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("🗑️ You were the last member, so %s was deleted. Its expenses are personal again.", ledger.Name))
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("👋 You left %s. Your expenses stay in it.", ledger.Name))
+
+	default:
+		return b.sendMessage(ctx, message.Chat.ID, ledgerUsage)
+	}
+}
+
+// applyActiveLedger puts a new expense in the ledger of the group chat it was logged in or else
+// the user's active ledger, if there is one, and returns it
+func (b *Bot) applyActiveLedger(ctx context.Context, chat *tgbotapi.Chat, telegramID int64, expense *models.Expense) (*models.Ledger, error) {
+	var (
+		ledger *models.Ledger
+		err    error
+	)
+	if isGroupChat(chat) {
+		ledger, err = b.ledgerService.JoinChatLedger(ctx, telegramID, chat.ID)
+	}
+	if err == nil && ledger == nil {
+		ledger, err = b.ledgerService.GetActiveLedger(ctx, telegramID)
+	}
+	if err != nil || ledger == nil {
+		return nil, err
+	}
+	expense.LedgerID = sql.NullInt64{Int64: ledger.ID, Valid: true}
+	return ledger, nil
+}
+
+// expenseAddedMessage confirms a new expense, naming the shared ledger it went to
+func expenseAddedMessage(ledger *models.Ledger) string {
+	if ledger == nil {
+		return "✅ Expense added successfully!"
+	}
+	return fmt.Sprintf("✅ Expense added to %s!", ledger.Name)
+}
+
+// formatLedgerRole returns a role as a lowercase word with an article, e.g. "an editor"
+func formatLedgerRole(role models.LedgerRole) string {
+	switch role {
+	case models.LedgerRoleOwner:
+		return "an owner"
+	case models.LedgerRoleEditor:
+		return "an editor"
+	default:
+		return "a viewer"
+	}
+}
+
+// buildLedgersMessage lists the user's ledgers with their role, marking the active one
+func buildLedgersMessage(ledgers []*models.Ledger) string {
+	if len(ledgers) == 0 {
+		return "📒 You are not in any shared ledger yet.\n\n" + ledgerUsage
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📒 Your Ledgers\n\n")
+	for _, ledger := range ledgers {
+		fmt.Fprintf(&sb, "• %s - %s", ledger.Name, strings.ToLower(string(ledger.Role)))
+		if ledger.Active {
+			sb.WriteString(" ✅ new expenses go here")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nSend /ledger help for more.")
+	return sb.String()
+}
+
+// buildLedgerMembersMessage lists a ledger's members and, for owners, its invite code
+func buildLedgerMembersMessage(ledger *models.Ledger, members []*models.LedgerMember) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "👥 %s Members\n\n", ledger.Name)
+	for _, member := range members {
+		fmt.Fprintf(&sb, "• %s - %s\n", member.DisplayName(), strings.ToLower(string(member.Role)))
+	}
+	if ledger.InviteCode != "" {
+		fmt.Fprintf(&sb, "\nInvite others with /ledger join %s", ledger.InviteCode)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// buildLedgerReportMessage builds the /ledger report message
+func buildLedgerReportMessage(report *models.LedgerReport, settings *models.UserSettings) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📒 %s - %s\n\n", report.Ledger.Name, settings.FormatMonth(report.Start))
+	if report.Count == 0 {
+		sb.WriteString("No expenses in this ledger yet. Members add expenses to it after /ledger use " + report.Ledger.Name)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "Total: %s in %d expenses\n", models.FormatMoney(report.Total, report.Currency), report.Count)
+
+	sb.WriteString("\n👥 By member\n")
+	for _, total := range report.ByMember {
+		fmt.Fprintf(&sb, "• %s: %s (%d)\n", total.Name, models.FormatMoney(total.Amount, report.Currency), total.Count)
+	}
+
+	sb.WriteString("\n🏷️ By category\n")
+	for _, total := range report.ByCategory {
+		fmt.Fprintf(&sb, "• %s: %s (%d)\n", total.Name, models.FormatMoney(total.Amount, report.Currency), total.Count)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
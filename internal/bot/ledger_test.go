@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildLedgersMessage(t *testing.T) {
+	assert.Contains(t, buildLedgersMessage(nil), "not in any shared ledger")
+
+	ledgers := []*models.Ledger{
+		{Name: "Home", Role: models.LedgerRoleOwner, Active: true},
+		{Name: "Goa Trip", Role: models.LedgerRoleViewer},
+	}
+	assert.Equal(t, `📒 Your Ledgers
+
+• Home - owner ✅ new expenses go here
+• Goa Trip - viewer
+
+Send /ledger help for more.`, buildLedgersMessage(ledgers))
+}
+
+func TestBuildLedgerMembersMessage(t *testing.T) {
+	members := []*models.LedgerMember{
+		{FirstName: "Asha", Role: models.LedgerRoleOwner},
+		{Username: "ravi", Role: models.LedgerRoleEditor},
+	}
+
+	assert.Equal(t, `👥 Home Members
+
+• Asha - owner
+• @ravi - editor
+
+Invite others with /ledger join K7QM2XPA`, buildLedgerMembersMessage(&models.Ledger{Name: "Home", InviteCode: "K7QM2XPA"}, members))
+
+	// Non-owners do not get the invite code
+	assert.NotContains(t, buildLedgerMembersMessage(&models.Ledger{Name: "Home"}, members), "/ledger join")
+}
+
+func TestBuildLedgerReportMessage(t *testing.T) {
+	settings := models.DefaultUserSettings(1)
+	report := &models.LedgerReport{
+		Ledger:   &models.Ledger{Name: "Home"},
+		Start:    time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Currency: "INR",
+		Total:    2300,
+		Count:    3,
+		ByMember: []models.LedgerTotal{
+			{Name: "Asha", Amount: 1200, Count: 1},
+			{Name: "Ravi", Amount: 1100, Count: 2},
+		},
+		ByCategory: []models.LedgerTotal{
+			{Name: "🛒 Groceries", Amount: 1500, Count: 2},
+			{Name: "🍽️ Dining", Amount: 800, Count: 1},
+		},
+	}
+
+	assert.Equal(t, `📒 Home - October 2026
+
+Total: ₹2300.00 in 3 expenses
+
+👥 By member
+• Asha: ₹1200.00 (1)
+• Ravi: ₹1100.00 (2)
+
+🏷️ By category
+• 🛒 Groceries: ₹1500.00 (2)
+• 🍽️ Dining: ₹800.00 (1)`, buildLedgerReportMessage(report, settings))
+
+	empty := &models.LedgerReport{Ledger: report.Ledger, Start: report.Start, Currency: "INR"}
+	assert.Contains(t, buildLedgerReportMessage(empty, settings), "No expenses in this ledger yet")
+}
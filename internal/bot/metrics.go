@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// updateBuckets are the latency buckets for handling updates, up to the dispatcher's timeout
+var updateBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+var (
+	updatesTotal = metrics.NewCounter("expense_bot_updates_total",
+		"Updates handled, by kind: the command, callback:<action> for buttons, or message.", "kind")
+	updateDuration = metrics.NewHistogram("expense_bot_update_duration_seconds",
+		"How long handling an update took, by kind.", updateBuckets, "kind")
+	errorsTotal = metrics.NewCounter("expense_bot_errors_total",
+		"Errors reported to users or failing updates, by error type.", "type")
+	telegramFailures = metrics.NewCounter("expense_bot_telegram_send_failures_total",
+		"Requests to the Telegram Bot API that failed, by method: send for messages, or the API method.", "method")
+)
+
+// registerMetrics registers the gauges read from the bot when metrics are scraped
+func (b *Bot) registerMetrics() {
+	metrics.NewGaugeFunc("expense_bot_active_states",
+		"Conversations in progress, such as expenses being added.",
+		func(ctx context.Context) (float64, error) {
+			count, err := b.stateStore.Count(ctx)
+			return float64(count), err
+		})
+	metrics.NewGaugeFunc("expense_bot_update_queue_depth",
+		"Updates received and waiting to be handled.",
+		func(context.Context) (float64, error) {
+			if b.dispatcher == nil {
+				return 0, nil
+			}
+			return float64(b.dispatcher.queueDepth()), nil
+		})
+}
+
+// recordError counts an error by its type; errors that are not application errors count as internal
+func recordError(err error) {
+	errorType := apperrors.ErrorTypeInternal
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		errorType = appErr.Type
+	}
+	errorsTotal.Inc(string(errorType))
+}
+
+// instrumentedAPI counts the requests to the Telegram Bot API that fail
+type instrumentedAPI struct {
+	BotAPIInterface
+}
+
+// Send implements BotAPIInterface
+func (a instrumentedAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg, err := a.BotAPIInterface.Send(c)
+	if err != nil {
+		telegramFailures.Inc("send")
+	}
+	return msg, err
+}
+
+// MakeRequest implements BotAPIInterface
+func (a instrumentedAPI) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	resp, err := a.BotAPIInterface.MakeRequest(endpoint, params)
+	if err != nil {
+		telegramFailures.Inc(endpoint)
+	}
+	return resp, err
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/metrics"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// failingAPI fails every request to Telegram
+type failingAPI struct {
+	BotAPIInterface
+}
+
+func (failingAPI) Send(tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return tgbotapi.Message{}, errors.New("telegram is down")
+}
+
+func (failingAPI) MakeRequest(string, tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	return nil, errors.New("telegram is down")
+}
+
+func TestBot_PrometheusMetrics(t *testing.T) {
+	ctx := context.Background()
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Return(tgbotapi.Message{}, nil)
+	bot := &Bot{
+		api:        instrumentedAPI{mockAPI},
+		db:         database.NewMockStorage(),
+		logger:     logger.NewMockLogger(),
+		stateStore: statestore.NewMemoryStore(statestore.DefaultTTL),
+	}
+	bot.registerMetrics()
+
+	t.Run("counts updates by kind", func(t *testing.T) {
+		before := updatesTotal.Value("/help")
+		bot.processUpdate(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Text:     "/help",
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+			From:     &tgbotapi.User{ID: 12345},
+			Chat:     &tgbotapi.Chat{ID: 12345},
+		}})
+		assert.Equal(t, before+1, updatesTotal.Value("/help"))
+	})
+
+	t.Run("counts errors by type", func(t *testing.T) {
+		validation := errorsTotal.Value(string(apperrors.ErrorTypeValidation))
+		internal := errorsTotal.Value(string(apperrors.ErrorTypeInternal))
+
+		require.NoError(t, bot.sendError(ctx, 12345, apperrors.NewValidationError("Invalid amount", "amount must be positive")))
+		require.NoError(t, bot.sendError(ctx, 12345, errors.New("boom")))
+
+		assert.Equal(t, validation+1, errorsTotal.Value(string(apperrors.ErrorTypeValidation)))
+		assert.Equal(t, internal+1, errorsTotal.Value(string(apperrors.ErrorTypeInternal)))
+	})
+
+	t.Run("counts failed Telegram requests", func(t *testing.T) {
+		sends, webhooks := telegramFailures.Value("send"), telegramFailures.Value("setWebhook")
+		api := instrumentedAPI{failingAPI{}}
+
+		_, err := api.Send(tgbotapi.NewMessage(12345, "hello"))
+		assert.Error(t, err)
+		_, err = api.MakeRequest("setWebhook", nil)
+		assert.Error(t, err)
+
+		assert.Equal(t, sends+1, telegramFailures.Value("send"))
+		assert.Equal(t, webhooks+1, telegramFailures.Value("setWebhook"))
+	})
+
+	t.Run("reports conversations in progress", func(t *testing.T) {
+		require.NoError(t, bot.stateStore.Set(ctx, 1, 1, models.NewUserState()))
+		require.NoError(t, bot.stateStore.Set(ctx, 2, 2, models.NewUserState()))
+
+		var out strings.Builder
+		require.NoError(t, metrics.Default.Write(ctx, &out))
+		// The one with the user who sent /help and the two above
+		assert.Contains(t, out.String(), "# TYPE expense_bot_active_states gauge\nexpense_bot_active_states 3\n")
+		assert.Contains(t, out.String(), "expense_bot_update_queue_depth 0\n")
+		assert.Contains(t, out.String(), `expense_bot_updates_total{kind="/help"}`)
+	})
+}
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/parser"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// quickAddHelp explains the one-line format when a category cannot be recognised
+const quickAddHelp = `🤔 I couldn't tell the category. Try one line like:
+450 dining lunch with team yesterday
+petrol 2000 car odo 45210 @104.5
+45 EUR hotels Paris`
+
+// handleQuickAdd parses free text as a one-line expense and asks the user to confirm it.
+// It returns false when the text does not contain an amount, so other handling can continue.
+func (b *Bot) handleQuickAdd(ctx context.Context, message *tgbotapi.Message, state *models.UserState) (bool, error) {
+	categories, err := b.categoryService.GetCategories(ctx, message.From.ID)
+	if err != nil {
+		return true, b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	expense, err := parser.New(categories).Parse(message.Text)
+	switch {
+	case errors.Is(err, parser.ErrNoAmount):
+		return false, nil
+	case errors.Is(err, parser.ErrNoCategory):
+		return true, b.sendMessage(ctx, message.Chat.ID, quickAddHelp)
+	case err != nil:
+		return true, b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	if expense.CategoryGroup != "Vehicle" {
+		expense.VehicleType.Valid = false
+		expense.Odometer = 0
+		expense.PetrolPrice = 0
+	}
+
+	if err := b.setAmount(ctx, message.From.ID, expense, expense.OriginalAmount, expense.Currency); err != nil {
+		return true, b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	state.TempExpense = expense
+	state.ReceiptKey, state.ReceiptFileName = "", ""
+	state.Step = models.StepQuickAddConfirm
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildQuickAddMessage(expense, b.getUserSettings(ctx, message.From.ID)))
+	msg.ReplyMarkup = GetQuickAddKeyboard()
+	_, err = b.api.Send(msg)
+	return true, err
+}
+
+// handleQuickAddCallback handles callback data with the quick_ prefix
+func (b *Bot) handleQuickAddCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, action string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if state.TempExpense == nil || state.TempExpense.ID != 0 || state.TempExpense.CategoryName == "" {
+		return b.sendMessage(ctx, chatID, "This expense has expired. Please send it again.")
+	}
+
+	switch action {
+	case "save":
+		return b.saveQuickAddExpense(ctx, callback, state)
+
+	case "edit":
+		// Reuse the edit flow; saving an unsaved expense creates it
+		state.Step = models.StepEditExpense
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			fmt.Sprintf("Editing expense:\n%s\n\nSelect what to edit:",
+				formatExpenseLine(state.TempExpense, b.getUserSettings(ctx, callback.From.ID))),
+			GetEditFieldKeyboard())
+		_, err := b.api.Send(msg)
+		return err
+
+	case "cancel":
+		b.clearState(ctx, chatID, callback.From.ID)
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, "❌ Expense discarded.")
+		_, err := b.api.Send(msg)
+		return err
+
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+}
+
+// saveQuickAddExpense creates the expense confirmed from a quick-add message
+func (b *Bot) saveQuickAddExpense(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState) error {
+	chatID := callback.Message.Chat.ID
+
+	if _, err := b.userService.GetOrCreateUser(ctx, callback.From.ID, callback.From.UserName, callback.From.FirstName, callback.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, chatID, err)
+	}
+
+	expense := state.TempExpense
+	ledger, err := b.applyActiveLedger(ctx, callback.Message.Chat, callback.From.ID, expense)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+	if err := b.expenseService.CreateExpense(ctx, expense, callback.From.ID); err != nil {
+		b.logger.Error(ctx, "Failed to create expense", logger.ErrorField(err))
+		return b.sendError(ctx, chatID, err)
+	}
+
+	text := expenseAddedMessage(ledger) + "\n" + formatExpenseLine(expense, b.getUserSettings(ctx, callback.From.ID))
+
+	// A scanned receipt photo is attached to the expense read from it
+	if state.ReceiptKey != "" {
+		if err := b.attachScannedReceipt(ctx, callback.From.ID, expense.ID, state); err != nil {
+			b.logger.Warn(ctx, "Failed to attach scanned receipt", logger.ErrorField(err))
+		} else {
+			text += "\n📎 Receipt attached."
+		}
+	}
+
+	b.startAttachingReceipts(ctx, chatID, callback.From.ID, expense.ID)
+
+	msg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text+"\n\n"+receiptPrompt)
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// attachScannedReceipt attaches the receipt photo staged by a scan to the expense read from it
+func (b *Bot) attachScannedReceipt(ctx context.Context, telegramID, expenseID int64, state *models.UserState) error {
+	data, err := b.getUpload(ctx, state.ReceiptKey)
+	if err != nil {
+		return err
+	}
+	_, err = b.attachmentService.AddAttachment(ctx, telegramID, expenseID, state.ReceiptFileName, "", data)
+	return err
+}
+
+// buildQuickAddMessage shows a parsed expense for confirmation
+func buildQuickAddMessage(expense *models.Expense, settings *models.UserSettings) string {
+	var sb strings.Builder
+	sb.WriteString("⚡ Quick Add\n\n")
+	writeExpenseDetails(&sb, expense, settings)
+	sb.WriteString("\nSave this expense?")
+	return sb.String()
+}
+
+// writeExpenseDetails writes the fields of an expense awaiting confirmation, one per line
+func writeExpenseDetails(sb *strings.Builder, expense *models.Expense, settings *models.UserSettings) {
+	sb.WriteString(fmt.Sprintf("🏷️ Category: %s %s\n", expense.CategoryEmoji, expense.CategoryName))
+	sb.WriteString(fmt.Sprintf("💰 Amount: %s\n", formatExpenseAmount(expense, settings)))
+	sb.WriteString(fmt.Sprintf("📅 Date: %s\n", settings.FormatDate(expense.Timestamp)))
+	if expense.VehicleType.Valid {
+		sb.WriteString(fmt.Sprintf("🚗 Vehicle: %s\n", expense.VehicleType.String))
+	}
+	if expense.Odometer > 0 {
+		sb.WriteString(fmt.Sprintf("🔢 Odometer: %.0f km\n", expense.Odometer))
+	}
+	if expense.PetrolPrice > 0 {
+		sb.WriteString(fmt.Sprintf("⛽ Petrol Price: %s/L\n", settings.FormatAmount(expense.PetrolPrice)))
+	}
+	if expense.Notes != "" {
+		sb.WriteString(fmt.Sprintf("📝 Notes: %s\n", expense.Notes))
+	}
+}
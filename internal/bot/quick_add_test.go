@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickAddFlow(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{ID: 1, Name: "Dining", Emoji: "🍽️", Group: "Daily Living"})
+	mockLogger := logger.NewMockLogger()
+	categoryService := services.NewCategoryService(storage, mockLogger, nil)
+	currencyService := services.NewCurrencyService(storage, mockLogger)
+	ledgerService := services.NewLedgerService(storage, mockLogger, currencyService)
+
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Return(tgbotapi.Message{}, nil)
+
+	bot := &Bot{
+		api:             mockAPI,
+		db:              storage,
+		logger:          mockLogger,
+		expenseService:  services.NewExpenseService(storage, mockLogger, nil, currencyService, categoryService, ledgerService),
+		categoryService: categoryService,
+		userService:     services.NewUserService(storage, mockLogger),
+		settingsService: services.NewSettingsService(storage, mockLogger),
+		currencyService: currencyService,
+		ledgerService:   ledgerService,
+		stateStore:      statestore.NewMemoryStore(statestore.DefaultTTL),
+	}
+
+	user := &tgbotapi.User{ID: 12345, UserName: "tester"}
+	chat := &tgbotapi.Chat{ID: 12345}
+	state := models.NewUserState()
+	bot.setState(ctx, user.ID, user.ID, state)
+
+	t.Run("text without an amount is not handled", func(t *testing.T) {
+		handled, err := bot.handleQuickAdd(ctx, &tgbotapi.Message{Text: "hello", From: user, Chat: chat}, state)
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+
+	t.Run("parsed expense waits for confirmation", func(t *testing.T) {
+		handled, err := bot.handleQuickAdd(ctx, &tgbotapi.Message{Text: "450 dining lunch with team", From: user, Chat: chat}, state)
+		require.NoError(t, err)
+		assert.True(t, handled)
+		assert.Equal(t, models.StepQuickAddConfirm, state.Step)
+		require.NotNil(t, state.TempExpense)
+		assert.Equal(t, 450.0, state.TempExpense.TotalPrice)
+		assert.Equal(t, models.DefaultCurrency, state.TempExpense.Currency)
+		// Handled outside an update's state session, so the changed state is saved here
+		bot.setState(ctx, user.ID, user.ID, state)
+	})
+
+	t.Run("save creates the expense", func(t *testing.T) {
+		callback := &tgbotapi.CallbackQuery{
+			From:    user,
+			Message: &tgbotapi.Message{MessageID: 1, Chat: chat},
+			Data:    "quick_save",
+		}
+		require.NoError(t, bot.handleCallbackQuery(ctx, callback))
+
+		expenses, err := storage.GetExpensesByTelegramID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, expenses, 1)
+		assert.Equal(t, 450.0, expenses[0].TotalPrice)
+		assert.Equal(t, "lunch with team", expenses[0].Notes)
+
+		// Receipts sent next are attached to the new expense
+		state := bot.getState(ctx, user.ID, user.ID)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StepAttachReceipts, state.Step)
+		assert.Equal(t, expenses[0].ID, state.AttachExpenseID)
+	})
+}
+
+func TestBuildQuickAddMessage(t *testing.T) {
+	expense := &models.Expense{
+		CategoryName:  "Petrol",
+		CategoryEmoji: "⛽",
+		TotalPrice:    2000,
+		Odometer:      45210,
+		PetrolPrice:   104.5,
+		Timestamp:     time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+	}
+	expense.VehicleType.String, expense.VehicleType.Valid = "CAR", true
+
+	expected := "⚡ Quick Add\n\n" +
+		"🏷️ Category: ⛽ Petrol\n" +
+		"💰 Amount: ₹2000.00\n" +
+		"📅 Date: 11 Mar 2026\n" +
+		"🚗 Vehicle: CAR\n" +
+		"🔢 Odometer: 45210 km\n" +
+		"⛽ Petrol Price: ₹104.50/L\n" +
+		"\nSave this expense?"
+	assert.Equal(t, expected, buildQuickAddMessage(expense, models.DefaultUserSettings(0)))
+}
+
+func TestGetQuickAddKeyboard(t *testing.T) {
+	keyboard := GetQuickAddKeyboard()
+	require.Len(t, keyboard.InlineKeyboard, 2)
+	assert.Equal(t, "quick_save", *keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, "quick_edit", *keyboard.InlineKeyboard[0][1].CallbackData)
+	assert.Equal(t, "quick_cancel", *keyboard.InlineKeyboard[1][0].CallbackData)
+}
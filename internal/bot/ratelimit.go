@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ratelimit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// heavyCommands are expensive to answer, so they are limited more strictly than other commands.
+// /search and /import only ask for a query or a statement; the answers to those are heavy.
+var heavyCommands = map[string]bool{
+	"export": true,
+}
+
+// heavySteps are the steps of a conversation in which the next message is expensive to handle:
+// a search query, or a statement or its column mapping, which are read and previewed
+var heavySteps = map[models.Step]bool{
+	models.StepSearchExpense: true,
+	models.StepImportFile:    true,
+	models.StepImportMapping: true,
+}
+
+// heavyCallbackPrefixes start the data of buttons that are expensive to handle: search result
+// pages and import confirmations
+var heavyCallbackPrefixes = []string{"search_page_", "import_"}
+
+// messageRateClass returns the class of rate limits a message counts against. Messages that are
+// not commands are classed by the step of the conversation, state, which may be nil.
+func messageRateClass(message *tgbotapi.Message, state *models.UserState) string {
+	switch {
+	case message.IsCommand() && heavyCommands[strings.ToLower(message.Command())]:
+		return ratelimit.ClassHeavy
+	case !message.IsCommand() && state != nil && heavySteps[state.Step]:
+		return ratelimit.ClassHeavy
+	case len(message.Photo) > 0 || message.Document != nil:
+		return ratelimit.ClassUpload
+	}
+	return ratelimit.ClassDefault
+}
+
+// callbackRateClass returns the class of rate limits a button press with data counts against
+func callbackRateClass(data string) string {
+	for _, prefix := range heavyCallbackPrefixes {
+		if strings.HasPrefix(data, prefix) {
+			return ratelimit.ClassHeavy
+		}
+	}
+	return ratelimit.ClassDefault
+}
+
+// checkRateLimit reports whether a request of class from a user may go ahead. The user is told
+// when to try again the first time a request is refused; requests refused after that are
+// dropped without an answer, so flooding the bot does not flood the chat.
+func (b *Bot) checkRateLimit(ctx context.Context, chatID, userID int64, class string) (bool, error) {
+	if b.rateLimiter == nil {
+		return true, nil
+	}
+	notify, err := b.rateLimiter.Allow(userID, class)
+	if err == nil {
+		return true, nil
+	}
+
+	b.incrementMetric(&b.metrics.errorCount)
+	recordError(err)
+	b.logger.Warn(ctx, "Rate limit exceeded", zap.Int64("user_id", userID),
+		logger.String("class", class), logger.ErrorField(err))
+	if !notify {
+		return false, nil
+	}
+
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return false, b.sendError(ctx, chatID, err)
+	}
+	return false, b.sendMessage(ctx, chatID, buildRateLimitMessage(appErr))
+}
+
+// buildRateLimitMessage tells a user who is sending too much when they can go on
+func buildRateLimitMessage(err *apperrors.AppError) string {
+	return fmt.Sprintf("⏳ %s. Please try again in %s.", err.Message, formatRetryAfter(err.RetryAfter))
+}
+
+// formatRetryAfter rounds a wait up to whole seconds, or whole minutes when it is that long
+func formatRetryAfter(d time.Duration) string {
+	if d >= time.Minute {
+		minutes := int((d + time.Minute - 1) / time.Minute)
+		if minutes == 1 {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds <= 1 {
+		return "1 second"
+	}
+	return fmt.Sprintf("%d seconds", seconds)
+}
+
+// evictRateLimits forgets the rate limits of users who have gone quiet
+func (b *Bot) evictRateLimits(ctx context.Context) {
+	if b.rateLimiter == nil {
+		return
+	}
+	if evicted := b.rateLimiter.Evict(); evicted > 0 {
+		b.logger.Debug(ctx, "Evicted idle rate limits", logger.Int("count", evicted))
+	}
+}
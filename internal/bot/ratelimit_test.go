@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ratelimit"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageRateClass(t *testing.T) {
+	command := func(text string) *tgbotapi.Message {
+		return &tgbotapi.Message{Text: text, Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}}}
+	}
+
+	searching := &models.UserState{Step: models.StepSearchExpense}
+	importing := &models.UserState{Step: models.StepImportFile}
+	statement := &tgbotapi.Message{Document: &tgbotapi.Document{FileID: "d"}}
+
+	assert.Equal(t, ratelimit.ClassHeavy, messageRateClass(command("/export"), nil))
+	assert.Equal(t, ratelimit.ClassDefault, messageRateClass(command("/Search"), nil), "/search only asks for a query")
+	assert.Equal(t, ratelimit.ClassDefault, messageRateClass(command("/list"), nil))
+	assert.Equal(t, ratelimit.ClassDefault, messageRateClass(&tgbotapi.Message{Text: "450 lunch"}, nil))
+	assert.Equal(t, ratelimit.ClassUpload, messageRateClass(&tgbotapi.Message{Photo: []tgbotapi.PhotoSize{{FileID: "p"}}}, nil))
+	assert.Equal(t, ratelimit.ClassUpload, messageRateClass(statement, nil))
+
+	// Answers in a search or an import are classed by the step of the conversation
+	assert.Equal(t, ratelimit.ClassHeavy, messageRateClass(&tgbotapi.Message{Text: "petrol last month"}, searching))
+	assert.Equal(t, ratelimit.ClassHeavy, messageRateClass(statement, importing))
+	assert.Equal(t, ratelimit.ClassHeavy, messageRateClass(&tgbotapi.Message{Text: "1 4 2"}, &models.UserState{Step: models.StepImportMapping}))
+	assert.Equal(t, ratelimit.ClassDefault, messageRateClass(command("/cancel"), searching))
+	assert.Equal(t, ratelimit.ClassUpload, messageRateClass(statement, models.NewUserState()))
+}
+
+func TestCallbackRateClass(t *testing.T) {
+	assert.Equal(t, ratelimit.ClassHeavy, callbackRateClass("search_page_2"))
+	assert.Equal(t, ratelimit.ClassHeavy, callbackRateClass("import_confirm"))
+	assert.Equal(t, ratelimit.ClassDefault, callbackRateClass("category_Dining"))
+}
+
+func TestBot_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	var sent []string
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		if msg, ok := args.Get(0).(tgbotapi.MessageConfig); ok {
+			sent = append(sent, msg.Text)
+		}
+	}).Return(tgbotapi.Message{}, nil)
+
+	bot := &Bot{
+		api:        mockAPI,
+		logger:     logger.NewMockLogger(),
+		stateStore: statestore.NewMemoryStore(statestore.DefaultTTL),
+		rateLimiter: ratelimit.New(ratelimit.Config{
+			Limits:      map[string]ratelimit.Limit{ratelimit.ClassDefault: {Requests: 2, Per: time.Hour}},
+			BanAfter:    3,
+			BanWindow:   time.Minute,
+			BanDuration: 15 * time.Minute,
+		}),
+	}
+	user := &tgbotapi.User{ID: 12345}
+	help := func(from *tgbotapi.User) *tgbotapi.Message {
+		return &tgbotapi.Message{
+			Text:     "/help",
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+			From:     from,
+			Chat:     &tgbotapi.Chat{ID: from.ID},
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, bot.handleMessage(ctx, help(user)))
+	}
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[1], "Here are the available commands")
+
+	// The first refused message is answered with when to try again, later ones are dropped
+	require.NoError(t, bot.handleMessage(ctx, help(user)))
+	require.Len(t, sent, 3)
+	assert.Contains(t, sent[2], "Too many requests. Please try again in 30 minutes")
+	require.NoError(t, bot.handleMessage(ctx, help(user)))
+	assert.Len(t, sent, 3)
+
+	// Button presses count too, and keeping on gets the user banned
+	callback := &tgbotapi.CallbackQuery{From: user, Data: "category_Dining", Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: user.ID}}}
+	require.NoError(t, bot.handleCallbackQuery(ctx, callback))
+	require.Len(t, sent, 4)
+	assert.Contains(t, sent[3], "Temporarily banned for sending too many requests. Please try again in 15 minutes")
+
+	// Other users are not affected
+	require.NoError(t, bot.handleMessage(ctx, help(&tgbotapi.User{ID: 67890})))
+	require.Len(t, sent, 5)
+	assert.Contains(t, sent[4], "Here are the available commands")
+}
+
+func TestBot_RateLimitHeavySearch(t *testing.T) {
+	ctx := context.Background()
+	var sent []string
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		if msg, ok := args.Get(0).(tgbotapi.MessageConfig); ok {
+			sent = append(sent, msg.Text)
+		}
+	}).Return(tgbotapi.Message{}, nil)
+
+	// No vector service: a search that got through the limits would fail the test
+	bot := &Bot{
+		api:        mockAPI,
+		logger:     logger.NewMockLogger(),
+		stateStore: statestore.NewMemoryStore(statestore.DefaultTTL),
+		rateLimiter: ratelimit.New(ratelimit.Config{
+			Limits: map[string]ratelimit.Limit{
+				ratelimit.ClassDefault: {Requests: 100, Per: time.Hour},
+				ratelimit.ClassHeavy:   {Requests: 1, Per: time.Hour},
+			},
+			BanAfter:    10,
+			BanWindow:   time.Minute,
+			BanDuration: 15 * time.Minute,
+		}),
+	}
+	user := &tgbotapi.User{ID: 12345}
+	chat := &tgbotapi.Chat{ID: user.ID}
+	_, err := bot.rateLimiter.Allow(user.ID, ratelimit.ClassHeavy)
+	require.NoError(t, err)
+
+	// A search query is refused once the heavy limit is used up
+	bot.setState(ctx, chat.ID, user.ID, &models.UserState{Step: models.StepSearchExpense})
+	require.NoError(t, bot.handleMessage(ctx, &tgbotapi.Message{Text: "petrol last month", From: user, Chat: chat}))
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], "Too many requests")
+
+	// So is paging through results, while other buttons still work
+	state := bot.getState(ctx, chat.ID, user.ID)
+	state.SearchQuery = "petrol"
+	bot.setState(ctx, chat.ID, user.ID, state)
+	require.NoError(t, bot.handleCallbackQuery(ctx, &tgbotapi.CallbackQuery{From: user, Data: "search_page_2", Message: &tgbotapi.Message{Chat: chat}}))
+	assert.Len(t, sent, 1)
+
+	allowed, err := bot.checkRateLimit(ctx, chat.ID, user.ID, callbackRateClass("category_Dining"))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestBuildRateLimitMessage(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{retryAfter: 200 * time.Millisecond, want: "⏳ Too many requests. Please try again in 1 second."},
+		{retryAfter: 4200 * time.Millisecond, want: "⏳ Too many requests. Please try again in 5 seconds."},
+		{retryAfter: time.Minute, want: "⏳ Too many requests. Please try again in 1 minute."},
+		{retryAfter: 9*time.Minute + time.Second, want: "⏳ Too many requests. Please try again in 10 minutes."},
+	}
+	for _, tt := range tests {
+		got := buildRateLimitMessage(apperrors.NewRateLimitErrorWithRetry("Too many requests", tt.retryAfter))
+		assert.Equal(t, tt.want, got)
+	}
+}
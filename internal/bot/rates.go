@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxRatesFileSize is the largest exchange rate CSV accepted by /rates import
+const maxRatesFileSize = 1 << 20
+
+// ratesUsage explains the /rates subcommands
+const ratesUsage = `Usage:
+/rates - Show the latest exchange rates
+/rates set EUR INR 90.5 [YYYY-MM-DD] - 1 EUR = 90.5 INR from that date (default today)
+/rates import - Import rates from a CSV file with columns date,base,quote,rate`
+
+// handleRatesCommand handles the /rates command
+func (b *Bot) handleRatesCommand(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		rates, err := b.currencyService.GetLatestRates(ctx, 20)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildRatesMessage(rates, b.getUserSettings(ctx, message.From.ID)))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "set":
+		base, quote, value, date, err := parseRateArgs(args[1:])
+		if err != nil {
+			return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("%v\n\n%s", err, ratesUsage))
+		}
+		rate, err := b.currencyService.SetRate(ctx, base, quote, value, date)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		settings := b.getUserSettings(ctx, message.From.ID)
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Exchange rate saved: 1 %s = %.4f %s from %s",
+			rate.BaseCurrency, rate.Rate, rate.QuoteCurrency, settings.FormatDate(rate.RateDate)))
+
+	case "import":
+		state := b.getState(ctx, message.Chat.ID, message.From.ID)
+		if state == nil {
+			state = models.NewUserState()
+			b.setState(ctx, message.Chat.ID, message.From.ID, state)
+		}
+		state.Step = models.StepRatesImport
+		return b.sendMessage(ctx, message.Chat.ID, "📎 Send the exchange rates as a CSV file with the columns:\n"+
+			"date,base,quote,rate\n2026-03-01,EUR,INR,90.5\n\nSend /cancel to stop.")
+
+	default:
+		return b.sendMessage(ctx, message.Chat.ID, ratesUsage)
+	}
+}
+
+// handleRatesImport imports the exchange rate CSV file sent after /rates import
+func (b *Bot) handleRatesImport(ctx context.Context, message *tgbotapi.Message) error {
+	if message.Document == nil {
+		return b.sendMessage(ctx, message.Chat.ID, "Please send the exchange rates as a CSV file, or /cancel.")
+	}
+	if message.Document.FileSize > maxRatesFileSize {
+		return b.sendMessage(ctx, message.Chat.ID, "The file is too large. Please send at most 1 MB of rates at a time.")
+	}
+
+	data, err := b.downloadFile(ctx, message.Document.FileID, maxRatesFileSize)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to download rates file", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	count, err := b.currencyService.ImportRatesCSV(ctx, bytes.NewReader(data))
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	b.clearState(ctx, message.Chat.ID, message.From.ID)
+	return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Imported %d exchange rates.", count))
+}
+
+// parseRateArgs parses "BASE QUOTE RATE [YYYY-MM-DD]"
+func parseRateArgs(args []string) (string, string, float64, time.Time, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return "", "", 0, time.Time{}, fmt.Errorf("please give two currency codes and a rate")
+	}
+
+	value, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return "", "", 0, time.Time{}, fmt.Errorf("invalid rate %q", args[2])
+	}
+
+	date := time.Now()
+	if len(args) == 4 {
+		date, err = time.Parse("2006-01-02", args[3])
+		if err != nil {
+			return "", "", 0, time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", args[3])
+		}
+	}
+
+	return strings.ToUpper(args[0]), strings.ToUpper(args[1]), value, date, nil
+}
+
+// buildRatesMessage lists the latest exchange rates
+func buildRatesMessage(rates []*models.ExchangeRate, settings *models.UserSettings) string {
+	if len(rates) == 0 {
+		return "💱 No exchange rates yet.\n\n" + ratesUsage
+	}
+
+	var sb strings.Builder
+	sb.WriteString("💱 Exchange Rates\n\n")
+	for _, rate := range rates {
+		sb.WriteString(fmt.Sprintf("• 1 %s = %.4f %s (%s)\n",
+			rate.BaseCurrency, rate.Rate, rate.QuoteCurrency, settings.FormatDate(rate.RateDate)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(ratesUsage)
+
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateArgs(t *testing.T) {
+	t.Run("with date", func(t *testing.T) {
+		base, quote, rate, date, err := parseRateArgs([]string{"eur", "inr", "90.5", "2026-03-01"})
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", base)
+		assert.Equal(t, "INR", quote)
+		assert.Equal(t, 90.5, rate)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), date)
+	})
+
+	t.Run("defaults to today", func(t *testing.T) {
+		_, _, _, date, err := parseRateArgs([]string{"USD", "INR", "83"})
+		require.NoError(t, err)
+		assert.Equal(t, time.Now().Format("2006-01-02"), date.Format("2006-01-02"))
+	})
+
+	invalid := [][]string{
+		{"USD", "INR"},
+		{"USD", "INR", "abc"},
+		{"USD", "INR", "83", "01/03/2026"},
+	}
+	for _, args := range invalid {
+		_, _, _, _, err := parseRateArgs(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestBuildRatesMessage(t *testing.T) {
+	settings := models.DefaultUserSettings(0)
+
+	assert.Contains(t, buildRatesMessage(nil, settings), "No exchange rates yet.")
+
+	rates := []*models.ExchangeRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "INR", Rate: 90.5, RateDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	assert.Contains(t, buildRatesMessage(rates, settings), "• 1 EUR = 90.5000 INR (01 Mar 2026)")
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// receiptPrompt invites the user to attach receipts to the expense they just added or are editing
+const receiptPrompt = "📎 Send a photo or PDF of the receipt to attach it, or carry on with anything else."
+
+// receiptFile is a photo or document sent to be attached as a receipt
+type receiptFile struct {
+	fileID      string
+	fileName    string
+	contentType string
+	size        int
+}
+
+// getReceiptFile returns the photo or document in a message, or nil if it has neither. Of the
+// sizes Telegram sends a photo in, the largest is used.
+func getReceiptFile(message *tgbotapi.Message) *receiptFile {
+	if len(message.Photo) > 0 {
+		photo := message.Photo[len(message.Photo)-1]
+		return &receiptFile{fileID: photo.FileID, fileName: "receipt.jpg", contentType: "image/jpeg", size: photo.FileSize}
+	}
+	if message.Document != nil {
+		return &receiptFile{
+			fileID:      message.Document.FileID,
+			fileName:    message.Document.FileName,
+			contentType: message.Document.MimeType,
+			size:        message.Document.FileSize,
+		}
+	}
+	return nil
+}
+
+// startAttachingReceipts makes photos and PDFs the user sends next attach to the expense
+func (b *Bot) startAttachingReceipts(ctx context.Context, chatID, userID, expenseID int64) {
+	state := models.NewUserState()
+	state.Step = models.StepAttachReceipts
+	state.AttachExpenseID = expenseID
+	b.setState(ctx, chatID, userID, state)
+}
+
+// handleReceiptUpload attaches the photo or PDF in a message to the expense in state
+func (b *Bot) handleReceiptUpload(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	file := getReceiptFile(message)
+	if file == nil {
+		return b.sendMessage(ctx, message.Chat.ID, receiptPrompt)
+	}
+	if file.size > services.MaxAttachmentSize {
+		return b.sendMessage(ctx, message.Chat.ID,
+			fmt.Sprintf("The file is too large. Receipts can be at most %d MB.", services.MaxAttachmentSize>>20))
+	}
+
+	data, err := b.downloadFile(ctx, file.fileID, services.MaxAttachmentSize)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to download receipt", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	attachment, err := b.attachmentService.AddAttachment(ctx, message.From.ID, state.AttachExpenseID, file.fileName, file.contentType, data)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	// Receipts attached while editing go back to the edit options
+	if state.TempExpense != nil && state.TempExpense.ID == attachment.ExpenseID {
+		state.Step = models.StepEditExpense
+		state.AttachExpenseID = 0
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("📎 Receipt attached.\n%s\n\nSelect what to edit:",
+			formatExpenseLine(state.TempExpense, b.getUserSettings(ctx, message.From.ID))))
+		msg.ReplyMarkup = GetEditFieldKeyboard()
+		_, err = b.api.Send(msg)
+		return err
+	}
+
+	return b.sendMessage(ctx, message.Chat.ID, "📎 Receipt attached. Send another, or carry on with anything else.")
+}
+
+// handleReceiptCallback sends the receipt behind a "📎 receipt" button of a list or search result
+func (b *Bot) handleReceiptCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, idData string) error {
+	chatID := callback.Message.Chat.ID
+
+	attachmentID, err := strconv.ParseInt(idData, 10, 64)
+	if err != nil {
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+
+	attachment, data, err := b.attachmentService.GetAttachment(ctx, callback.From.ID, attachmentID)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+
+	file := tgbotapi.FileBytes{Name: attachment.FileName, Bytes: data}
+	if attachment.IsImage() {
+		_, err = b.api.Send(tgbotapi.NewPhoto(chatID, file))
+	} else {
+		_, err = b.api.Send(tgbotapi.NewDocument(chatID, file))
+	}
+	return err
+}
+
+// receiptButtons returns a "📎 receipt" button for each receipt of the expenses, at most 10, so
+// list and search results can re-send them
+func receiptButtons(expenses []*models.Expense, attachments map[int64][]*models.Attachment, settings *models.UserSettings) [][]tgbotapi.InlineKeyboardButton {
+	const maxButtons = 10
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, expense := range expenses {
+		for i, attachment := range attachments[expense.ID] {
+			if len(rows) == maxButtons {
+				return rows
+			}
+			label := "📎 receipt"
+			if len(attachments[expense.ID]) > 1 {
+				label = fmt.Sprintf("📎 receipt %d", i+1)
+			}
+			text := fmt.Sprintf("%s · %s - %s: %s", label,
+				settings.FormatShortDate(expense.Timestamp), expense.CategoryName, formatExpenseAmount(expense, settings))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("receipt_%d", attachment.ID))))
+		}
+	}
+	return rows
+}
+
+// getReceiptButtons looks up the receipts of expenses shown to a user and returns their buttons.
+// Results are still useful without them, so failures are only logged.
+func (b *Bot) getReceiptButtons(ctx context.Context, expenses []*models.Expense, settings *models.UserSettings) [][]tgbotapi.InlineKeyboardButton {
+	if len(expenses) == 0 {
+		return nil
+	}
+	attachments, err := b.attachmentService.GetAttachments(ctx, expenses)
+	if err != nil {
+		b.logger.Warn(ctx, "Failed to get receipts", logger.ErrorField(err))
+		return nil
+	}
+	return receiptButtons(expenses, attachments, settings)
+}
@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ocr"
+	"github.com/MitulShah1/expense-tracker-bot/internal/parser"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// receiptUnreadable is sent when no total can be read from a receipt photo
+const receiptUnreadable = "🧾 I couldn't read a total from that receipt. Send the expense as one line instead, e.g. 450 dining, or use /add."
+
+// maxScannedItems is how many line items of a scanned receipt are shown for confirmation
+const maxScannedItems = 5
+
+// handleReceiptScan reads a receipt photo into an expense and asks the user to confirm it, like
+// a quick-add. The photo is attached to the expense once it is saved.
+func (b *Bot) handleReceiptScan(ctx context.Context, message *tgbotapi.Message, state *models.UserState, file *receiptFile) error {
+	chatID := message.Chat.ID
+
+	if b.scanner == nil || !strings.HasPrefix(file.contentType, "image/") {
+		return b.sendMessage(ctx, chatID, "To attach a receipt, pick the expense with /edit and tap 📎 Receipt.")
+	}
+	if file.size > services.MaxAttachmentSize {
+		return b.sendMessage(ctx, chatID,
+			fmt.Sprintf("The file is too large. Receipts can be at most %d MB.", services.MaxAttachmentSize>>20))
+	}
+
+	data, err := b.downloadFile(ctx, file.fileID, services.MaxAttachmentSize)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to download receipt", logger.ErrorField(err))
+		return b.sendError(ctx, chatID, err)
+	}
+
+	receipt, err := b.scanner.Scan(ctx, data)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to scan receipt", logger.ErrorField(err))
+		return b.sendMessage(ctx, chatID, receiptUnreadable)
+	}
+	if receipt.Total <= 0 {
+		return b.sendMessage(ctx, chatID, receiptUnreadable)
+	}
+
+	categories, err := b.categoryService.GetCategories(ctx, message.From.ID)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+	category := receiptCategory(parser.New(categories), receipt)
+	if category == nil {
+		return b.sendMessage(ctx, chatID, receiptUnreadable)
+	}
+
+	expense := &models.Expense{
+		CategoryID:    category.ID,
+		CategoryName:  category.Name,
+		CategoryEmoji: category.Emoji,
+		CategoryGroup: category.Group,
+		Notes:         receipt.Merchant,
+		Timestamp:     receiptTimestamp(receipt.Date, time.Now()),
+	}
+	if receipt.Fuel && category.Group == "Vehicle" {
+		expense.PetrolPrice = receipt.PricePerLitre
+	}
+	if err := b.setAmount(ctx, message.From.ID, expense, receipt.Total, receipt.Currency); err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+
+	key, err := b.stageUpload(ctx, chatID, message.From.ID, stagedReceipt, data)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to stage receipt", logger.ErrorField(err))
+		return b.sendError(ctx, chatID, err)
+	}
+
+	state.TempExpense = expense
+	state.ReceiptKey = key
+	state.ReceiptFileName = file.fileName
+	state.Step = models.StepQuickAddConfirm
+
+	msg := tgbotapi.NewMessage(chatID, buildScannedReceiptMessage(receipt, expense, b.getUserSettings(ctx, message.From.ID)))
+	msg.ReplyMarkup = GetQuickAddKeyboard()
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// receiptCategory picks the category of a scanned receipt: Petrol for fuel, otherwise one named
+// by the merchant or the items, falling back to Other
+func receiptCategory(p *parser.Parser, receipt *ocr.Receipt) *models.Category {
+	if receipt.Fuel {
+		if category := p.MatchCategory("petrol"); category != nil {
+			return category
+		}
+	}
+
+	words := []string{receipt.Merchant}
+	for _, item := range receipt.Items {
+		words = append(words, item.Description)
+	}
+	if category := p.MatchCategory(strings.Join(words, " ")); category != nil {
+		return category
+	}
+	return p.MatchCategory("other")
+}
+
+// receiptTimestamp returns the receipt's date at the current time of day, or now when the
+// receipt has no date or one in the future
+func receiptTimestamp(date, now time.Time) time.Time {
+	if date.IsZero() || date.After(now) {
+		return now
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+}
+
+// buildScannedReceiptMessage shows an expense read from a receipt photo for confirmation
+func buildScannedReceiptMessage(receipt *ocr.Receipt, expense *models.Expense, settings *models.UserSettings) string {
+	var sb strings.Builder
+	sb.WriteString("🧾 Scanned Receipt\n\n")
+	writeExpenseDetails(&sb, expense, settings)
+	if receipt.Fuel && receipt.Litres > 0 {
+		sb.WriteString(fmt.Sprintf("🛢️ Litres: %.2f L\n", receipt.Litres))
+	}
+
+	if len(receipt.Items) > 0 {
+		sb.WriteString("\nItems:\n")
+		for i, item := range receipt.Items {
+			if i == maxScannedItems {
+				sb.WriteString(fmt.Sprintf("… and %d more\n", len(receipt.Items)-maxScannedItems))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("• %s: %s\n", item.Description, models.FormatMoney(item.Amount, expense.Currency)))
+		}
+	}
+
+	sb.WriteString("\nSave this expense? Use ✏️ Edit to fix anything read wrong.")
+	return sb.String()
+}
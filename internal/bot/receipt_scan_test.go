@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ocr"
+	"github.com/MitulShah1/expense-tracker-bot/internal/parser"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner returns a fixed receipt for any image
+type fakeScanner struct {
+	receipt *ocr.Receipt
+	err     error
+}
+
+func (s *fakeScanner) Scan(_ context.Context, _ []byte) (*ocr.Receipt, error) {
+	return s.receipt, s.err
+}
+
+func TestReceiptScan(t *testing.T) {
+	photo := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00receipt")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(photo)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	for _, category := range []*models.Category{
+		{Name: "Petrol", Emoji: "⛽", Group: "Vehicle"},
+		{Name: "Coffee/Tea", Emoji: "☕", Group: "Daily Living"},
+		{Name: "Other", Emoji: "📌", Group: "Other"},
+	} {
+		storage.(*database.MockStorage).AddMockCategory(category)
+	}
+	mockLogger := logger.NewMockLogger()
+	categoryService := services.NewCategoryService(storage, mockLogger, nil)
+	currencyService := services.NewCurrencyService(storage, mockLogger)
+	ledgerService := services.NewLedgerService(storage, mockLogger, currencyService)
+	blobs, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	var sent []tgbotapi.Chattable
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(tgbotapi.Chattable))
+	}).Return(tgbotapi.Message{}, nil)
+	mockAPI.On("GetFileDirectURL", "receipt-photo").Return(server.URL, nil)
+
+	scanner := &fakeScanner{}
+	bot := &Bot{
+		api:               mockAPI,
+		db:                storage,
+		logger:            mockLogger,
+		expenseService:    services.NewExpenseService(storage, mockLogger, nil, currencyService, categoryService, ledgerService),
+		categoryService:   categoryService,
+		userService:       services.NewUserService(storage, mockLogger),
+		settingsService:   services.NewSettingsService(storage, mockLogger),
+		currencyService:   currencyService,
+		ledgerService:     ledgerService,
+		attachmentService: services.NewAttachmentService(storage, mockLogger, blobs, ledgerService),
+		scanner:           scanner,
+		blobs:             blobs,
+		stateStore:        statestore.NewMemoryStore(statestore.DefaultTTL),
+	}
+
+	user := &tgbotapi.User{ID: 12345, UserName: "tester"}
+	chat := &tgbotapi.Chat{ID: 12345}
+	_, err = bot.userService.GetOrCreateUser(ctx, user.ID, user.UserName, "", "")
+	require.NoError(t, err)
+	photoMessage := &tgbotapi.Message{From: user, Chat: chat, Photo: []tgbotapi.PhotoSize{{FileID: "receipt-photo", FileSize: len(photo)}}}
+
+	t.Run("a receipt photo is read into an expense to confirm and attached when saved", func(t *testing.T) {
+		sent = nil
+		bot.clearState(ctx, chat.ID, user.ID)
+		scanner.receipt = &ocr.Receipt{
+			Merchant: "Cafe Coffee Day",
+			Date:     time.Now().AddDate(0, 0, -2),
+			Total:    320,
+			Items:    []ocr.LineItem{{Description: "Cappuccino", Amount: 180}, {Description: "Brownie", Amount: 140}},
+		}
+
+		require.NoError(t, bot.handleMessage(ctx, photoMessage))
+		require.Len(t, sent, 1)
+		text := sent[0].(tgbotapi.MessageConfig).Text
+		assert.Contains(t, text, "Scanned Receipt")
+		assert.Contains(t, text, "Coffee/Tea")
+		assert.Contains(t, text, "Cappuccino")
+
+		state := bot.getState(ctx, chat.ID, user.ID)
+		assert.Equal(t, models.StepQuickAddConfirm, state.Step)
+		require.NotNil(t, state.TempExpense)
+		assert.Equal(t, 320.0, state.TempExpense.TotalPrice)
+		staged, err := blobs.Get(ctx, state.ReceiptKey)
+		require.NoError(t, err)
+		assert.Equal(t, photo, staged)
+		assert.Equal(t, "Cafe Coffee Day", state.TempExpense.Notes)
+		assert.Equal(t, time.Now().AddDate(0, 0, -2).YearDay(), state.TempExpense.Timestamp.YearDay())
+
+		sent = nil
+		require.NoError(t, bot.handleCallbackQuery(ctx, &tgbotapi.CallbackQuery{
+			From: user, Message: &tgbotapi.Message{MessageID: 5, Chat: chat}, Data: "quick_save",
+		}))
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].(tgbotapi.EditMessageTextConfig).Text, "Receipt attached")
+
+		expenseID := bot.getState(ctx, chat.ID, user.ID).AttachExpenseID
+		require.NotZero(t, expenseID)
+		attachments, err := storage.GetAttachmentsByExpenseIDs(ctx, []int64{expenseID})
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "image/jpeg", attachments[0].ContentType)
+
+		// The staged photo is deleted once it is attached
+		_, err = blobs.Get(ctx, state.ReceiptKey)
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+
+	t.Run("fuel receipts fill the price per litre", func(t *testing.T) {
+		sent = nil
+		bot.clearState(ctx, chat.ID, user.ID)
+		scanner.receipt = &ocr.Receipt{Merchant: "Shree Sai Fuel Station", Total: 2000, Fuel: true, Litres: 19.12, PricePerLitre: 104.61}
+
+		require.NoError(t, bot.handleMessage(ctx, photoMessage))
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, "19.12 L")
+
+		expense := bot.getState(ctx, chat.ID, user.ID).TempExpense
+		require.NotNil(t, expense)
+		assert.Equal(t, "Petrol", expense.CategoryName)
+		assert.Equal(t, 104.61, expense.PetrolPrice)
+		assert.Equal(t, 2000.0, expense.TotalPrice)
+	})
+
+	t.Run("receipts without a total are not added", func(t *testing.T) {
+		sent = nil
+		bot.clearState(ctx, chat.ID, user.ID)
+		scanner.receipt = &ocr.Receipt{Merchant: "Blurry"}
+
+		require.NoError(t, bot.handleMessage(ctx, photoMessage))
+		require.Len(t, sent, 1)
+		assert.Equal(t, receiptUnreadable, sent[0].(tgbotapi.MessageConfig).Text)
+		assert.Nil(t, bot.getState(ctx, chat.ID, user.ID).TempExpense)
+
+		sent = nil
+		scanner.receipt, scanner.err = nil, errors.New("tesseract failed")
+		require.NoError(t, bot.handleMessage(ctx, photoMessage))
+		require.Len(t, sent, 1)
+		assert.Equal(t, receiptUnreadable, sent[0].(tgbotapi.MessageConfig).Text)
+		scanner.err = nil
+	})
+
+	t.Run("without OCR photos get a hint", func(t *testing.T) {
+		sent = nil
+		bot.clearState(ctx, chat.ID, user.ID)
+		bot.scanner = nil
+		defer func() { bot.scanner = scanner }()
+
+		require.NoError(t, bot.handleMessage(ctx, photoMessage))
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, "/edit")
+	})
+}
+
+func TestReceiptCategory(t *testing.T) {
+	p := parser.New([]*models.Category{
+		{ID: 1, Name: "Petrol", Group: "Vehicle"},
+		{ID: 2, Name: "Grocery", Group: "Daily Living"},
+		{ID: 3, Name: "Other", Group: "Other"},
+	})
+
+	assert.Equal(t, "Petrol", receiptCategory(p, &ocr.Receipt{Merchant: "Shell", Fuel: true}).Name)
+	assert.Equal(t, "Grocery", receiptCategory(p, &ocr.Receipt{Merchant: "Fresh Mart", Items: []ocr.LineItem{{Description: "Vegetables"}}}).Name)
+	assert.Equal(t, "Other", receiptCategory(p, &ocr.Receipt{Merchant: "Acme Ltd"}).Name)
+}
+
+func TestReceiptTimestamp(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 18, 30, 0, 0, time.Local)
+
+	assert.Equal(t, now, receiptTimestamp(time.Time{}, now))
+	assert.Equal(t, now, receiptTimestamp(now.AddDate(0, 0, 1), now), "future dates are misreads")
+	assert.Equal(t, time.Date(2026, time.October, 3, 18, 30, 0, 0, time.Local),
+		receiptTimestamp(time.Date(2026, time.October, 3, 0, 0, 0, 0, time.Local), now))
+}
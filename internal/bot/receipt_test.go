@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/services"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptFlow(t *testing.T) {
+	photo := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00receipt")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(photo)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Daily Living"})
+	mockLogger := logger.NewMockLogger()
+	categoryService := services.NewCategoryService(storage, mockLogger, nil)
+	currencyService := services.NewCurrencyService(storage, mockLogger)
+	ledgerService := services.NewLedgerService(storage, mockLogger, currencyService)
+	blobs, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	var sent []tgbotapi.Chattable
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(tgbotapi.Chattable))
+	}).Return(tgbotapi.Message{}, nil)
+	mockAPI.On("GetFileDirectURL", "photo-large").Return(server.URL, nil)
+
+	bot := &Bot{
+		api:               mockAPI,
+		db:                storage,
+		logger:            mockLogger,
+		expenseService:    services.NewExpenseService(storage, mockLogger, nil, currencyService, categoryService, ledgerService),
+		categoryService:   categoryService,
+		userService:       services.NewUserService(storage, mockLogger),
+		settingsService:   services.NewSettingsService(storage, mockLogger),
+		currencyService:   currencyService,
+		attachmentService: services.NewAttachmentService(storage, mockLogger, blobs, ledgerService),
+		stateStore:        statestore.NewMemoryStore(statestore.DefaultTTL),
+	}
+
+	user := &tgbotapi.User{ID: 12345, UserName: "tester"}
+	chat := &tgbotapi.Chat{ID: 12345}
+	_, err = bot.userService.GetOrCreateUser(ctx, user.ID, user.UserName, "", "")
+	require.NoError(t, err)
+	expense := &models.Expense{CategoryName: "Dining", TotalPrice: 450, Timestamp: time.Now()}
+	require.NoError(t, bot.expenseService.CreateExpense(ctx, expense, user.ID))
+
+	t.Run("a photo sent after adding an expense is attached", func(t *testing.T) {
+		sent = nil
+		bot.startAttachingReceipts(ctx, chat.ID, user.ID, expense.ID)
+		message := &tgbotapi.Message{From: user, Chat: chat, Photo: []tgbotapi.PhotoSize{
+			{FileID: "photo-small", FileSize: 100},
+			{FileID: "photo-large", FileSize: len(photo)},
+		}}
+		require.NoError(t, bot.handleMessage(ctx, message))
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, "Receipt attached")
+		assert.Equal(t, models.StepAttachReceipts, bot.getState(ctx, chat.ID, user.ID).Step, "more receipts can follow")
+	})
+
+	t.Run("text ends attaching receipts", func(t *testing.T) {
+		sent = nil
+		require.NoError(t, bot.handleMessage(ctx, &tgbotapi.Message{From: user, Chat: chat, Text: "hello"}))
+		state := bot.getState(ctx, chat.ID, user.ID)
+		assert.Equal(t, models.StepStart, state.Step)
+		assert.Zero(t, state.AttachExpenseID)
+	})
+
+	t.Run("list results re-send the receipt", func(t *testing.T) {
+		sent = nil
+		require.NoError(t, bot.handleListCommand(ctx, &tgbotapi.Message{From: user, Chat: chat}))
+		require.Len(t, sent, 1)
+		list := sent[0].(tgbotapi.MessageConfig)
+		keyboard, ok := list.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		require.True(t, ok)
+		require.Len(t, keyboard.InlineKeyboard, 1)
+		button := keyboard.InlineKeyboard[0][0]
+		assert.Contains(t, button.Text, "📎 receipt · ")
+		assert.Contains(t, button.Text, "450.00")
+
+		sent = nil
+		callback := &tgbotapi.CallbackQuery{From: user, Message: &tgbotapi.Message{MessageID: 3, Chat: chat}, Data: *button.CallbackData}
+		require.NoError(t, bot.handleCallbackQuery(ctx, callback))
+		require.Len(t, sent, 1)
+		upload, ok := sent[0].(tgbotapi.PhotoConfig)
+		require.True(t, ok)
+		assert.Equal(t, tgbotapi.FileBytes{Name: "receipt.jpg", Bytes: photo}, upload.File)
+	})
+
+	t.Run("other users cannot fetch the receipt", func(t *testing.T) {
+		sent = nil
+		other := &tgbotapi.User{ID: 67890}
+		_, err := bot.userService.GetOrCreateUser(ctx, other.ID, "", "", "")
+		require.NoError(t, err)
+		attachments, err := storage.GetAttachmentsByExpenseIDs(ctx, []int64{expense.ID})
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+
+		require.NoError(t, bot.handleCallbackQuery(ctx, &tgbotapi.CallbackQuery{
+			From: other, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: other.ID}},
+			Data: fmt.Sprintf("receipt_%d", attachments[0].ID),
+		}))
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, "only see your own expenses")
+	})
+}
+
+func TestReceiptButtons(t *testing.T) {
+	settings := models.DefaultUserSettings(0)
+	expenses := []*models.Expense{
+		{ID: 1, CategoryName: "Dining", TotalPrice: 450, Timestamp: time.Now()},
+		{ID: 2, CategoryName: "Petrol", TotalPrice: 2000, Timestamp: time.Now()},
+		{ID: 3, CategoryName: "Rent", TotalPrice: 25000, Timestamp: time.Now()},
+	}
+	attachments := map[int64][]*models.Attachment{
+		1: {{ID: 10, ExpenseID: 1}},
+		3: {{ID: 11, ExpenseID: 3}, {ID: 12, ExpenseID: 3}},
+	}
+
+	rows := receiptButtons(expenses, attachments, settings)
+	require.Len(t, rows, 3)
+	assert.Contains(t, rows[0][0].Text, "📎 receipt · ")
+	assert.Equal(t, "receipt_10", *rows[0][0].CallbackData)
+	assert.Contains(t, rows[1][0].Text, "📎 receipt 1 · ")
+	assert.Contains(t, rows[2][0].Text, "📎 receipt 2 · ")
+	assert.Equal(t, "receipt_12", *rows[2][0].CallbackData)
+
+	assert.Empty(t, receiptButtons(expenses, nil, settings))
+}
+
+func TestGetReceiptFile(t *testing.T) {
+	assert.Nil(t, getReceiptFile(&tgbotapi.Message{Text: "450 dining"}))
+
+	file := getReceiptFile(&tgbotapi.Message{Photo: []tgbotapi.PhotoSize{{FileID: "small"}, {FileID: "large", FileSize: 2048}}})
+	require.NotNil(t, file)
+	assert.Equal(t, "large", file.fileID)
+	assert.Equal(t, "image/jpeg", file.contentType)
+
+	file = getReceiptFile(&tgbotapi.Message{Document: &tgbotapi.Document{FileID: "doc", FileName: "bill.pdf", MimeType: "application/pdf"}})
+	require.NotNil(t, file)
+	assert.Equal(t, "bill.pdf", file.fileName)
+	assert.Equal(t, "application/pdf", file.contentType)
+}
@@ -0,0 +1,237 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recurringInputHelp explains the format accepted when adding a recurring expense
+const recurringInputHelp = `🔁 Send the recurring expense as:
+<amount> <daily|weekly|monthly|yearly> <category> [first due YYYY-MM-DD]; [notes]
+
+Examples:
+25000 monthly Home Loan EMI 2026-11-05; HDFC
+199 monthly Entertainment; Music subscription
+
+Without a date the first expense is added right away.`
+
+// handleRecurringCommand handles the /recurring command.
+// "/recurring add ..." creates a recurring expense directly; otherwise the list is shown.
+func (b *Bot) handleRecurringCommand(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.TrimSpace(message.CommandArguments())
+	if strings.HasPrefix(args, "add") {
+		input := strings.TrimSpace(strings.TrimPrefix(args, "add"))
+		if input == "" {
+			state := b.getState(ctx, message.Chat.ID, message.From.ID)
+			if state == nil {
+				state = models.NewUserState()
+				b.setState(ctx, message.Chat.ID, message.From.ID, state)
+			}
+			state.Step = models.StepRecurringDetails
+			return b.sendMessage(ctx, message.Chat.ID, recurringInputHelp)
+		}
+		return b.createRecurringExpense(ctx, message, input)
+	}
+
+	// Make sure the user exists so first-time users see an empty list
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	recurring, err := b.recurringService.GetRecurringExpenses(ctx, message.From.ID)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.buildRecurringListMessage(recurring, b.getUserSettings(ctx, message.From.ID)))
+	msg.ReplyMarkup = GetRecurringKeyboard(recurring)
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleRecurringCallback handles callback data with the recurring_ prefix
+func (b *Bot) handleRecurringCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, action string) error {
+	chatID := callback.Message.Chat.ID
+
+	if action == "add" {
+		state.Step = models.StepRecurringDetails
+		return b.sendMessage(ctx, chatID, recurringInputHelp)
+	}
+
+	verb, idText, found := strings.Cut(action, "_")
+	if !found {
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+
+	recurringID, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+
+	switch verb {
+	case "pause":
+		err = b.recurringService.SetRecurringExpenseActive(ctx, callback.From.ID, recurringID, false)
+	case "resume":
+		err = b.recurringService.SetRecurringExpenseActive(ctx, callback.From.ID, recurringID, true)
+	case "delete":
+		err = b.recurringService.DeleteRecurringExpense(ctx, callback.From.ID, recurringID)
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+
+	// Refresh the list in place
+	recurring, err := b.recurringService.GetRecurringExpenses(ctx, callback.From.ID)
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+	msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID,
+		b.buildRecurringListMessage(recurring, b.getUserSettings(ctx, callback.From.ID)), GetRecurringKeyboard(recurring))
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleRecurringDetails handles the recurring expense entered after choosing ➕ Add Recurring
+func (b *Bot) handleRecurringDetails(ctx context.Context, message *tgbotapi.Message) error {
+	return b.createRecurringExpense(ctx, message, message.Text)
+}
+
+// createRecurringExpense parses input and creates a recurring expense for the sender
+func (b *Bot) createRecurringExpense(ctx context.Context, message *tgbotapi.Message, input string) error {
+	parsed, err := parseRecurringInput(input, time.Now())
+	if err != nil {
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, recurringInputHelp))
+	}
+
+	// Recurring expenses may be the first thing a user sets up, so make sure the user exists
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	recurring, err := b.recurringService.CreateRecurringExpense(ctx, message.From.ID,
+		parsed.CategoryName, parsed.Amount, parsed.Interval, parsed.FirstDue, parsed.Notes)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	// Reset state
+	b.clearState(ctx, message.Chat.ID, message.From.ID)
+
+	settings := b.getUserSettings(ctx, message.From.ID)
+	return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf(
+		"✅ Recurring expense added: %s %s %s %s\nFirst expense on %s.",
+		recurring.CategoryEmoji, recurring.CategoryName,
+		settings.FormatAmount(recurring.Amount), recurring.Interval,
+		settings.FormatDate(recurring.NextDue)))
+}
+
+// recurringInput holds the fields parsed from a recurring expense message
+type recurringInput struct {
+	Amount       float64
+	Interval     models.RecurringInterval
+	CategoryName string
+	FirstDue     time.Time
+	Notes        string
+}
+
+// parseRecurringInput parses "<amount> <interval> <category> [YYYY-MM-DD]; [notes]"
+func parseRecurringInput(input string, now time.Time) (*recurringInput, error) {
+	head, notes, _ := strings.Cut(input, ";")
+	fields := strings.Fields(head)
+	if len(fields) < 3 {
+		return nil, errors.New("expected an amount, an interval and a category")
+	}
+
+	amount, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || amount <= 0 {
+		return nil, errors.New("amount must be a positive number")
+	}
+
+	interval := models.RecurringInterval(strings.ToLower(fields[1]))
+	switch interval {
+	case models.RecurringDaily, models.RecurringWeekly, models.RecurringMonthly, models.RecurringYearly:
+	default:
+		return nil, fmt.Errorf("unknown interval %q", fields[1])
+	}
+
+	firstDue := now
+	categoryFields := fields[2:]
+	if n := len(categoryFields); n > 1 {
+		if date, err := time.ParseInLocation("2006-01-02", categoryFields[n-1], now.Location()); err == nil {
+			firstDue = date
+			categoryFields = categoryFields[:n-1]
+		}
+	}
+
+	return &recurringInput{
+		Amount:       amount,
+		Interval:     interval,
+		CategoryName: strings.Join(categoryFields, " "),
+		FirstDue:     firstDue,
+		Notes:        strings.TrimSpace(notes),
+	}, nil
+}
+
+// processRecurringExpenses materializes due recurring expenses and notifies their owners
+func (b *Bot) processRecurringExpenses(ctx context.Context, now time.Time) {
+	occurrences, err := b.recurringService.ProcessDueExpenses(ctx, now)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to process recurring expenses", logger.ErrorField(err))
+		return
+	}
+
+	for _, occurrence := range occurrences {
+		settings := b.getUserSettings(ctx, occurrence.Recurring.TelegramID)
+		if !settings.NotificationsEnabled {
+			continue
+		}
+		if err := b.sendMessage(ctx, occurrence.Recurring.TelegramID, buildRecurringNotification(occurrence, settings)); err != nil {
+			b.logger.Warn(ctx, "Failed to notify user about recurring expense", logger.ErrorField(err))
+		}
+	}
+}
+
+// buildRecurringListMessage builds a formatted list of recurring expenses
+func (b *Bot) buildRecurringListMessage(recurring []*models.RecurringExpense, settings *models.UserSettings) string {
+	if len(recurring) == 0 {
+		return "🔁 No recurring expenses yet.\n\nUse ➕ Add Recurring for rent, EMIs and subscriptions."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔁 Recurring Expenses\n\n")
+
+	for _, r := range recurring {
+		status := "🟢 active"
+		if !r.IsActive {
+			status = "⏸ paused"
+		}
+		sb.WriteString(fmt.Sprintf("#%d %s %s: %s %s\n",
+			r.ID, r.CategoryEmoji, r.CategoryName, settings.FormatAmount(r.Amount), r.Interval))
+		sb.WriteString(fmt.Sprintf("   Next: %s %s\n", settings.FormatDate(r.NextDue), status))
+		if r.Notes != "" {
+			sb.WriteString(fmt.Sprintf("   📝 %s\n", r.Notes))
+		}
+	}
+
+	return sb.String()
+}
+
+// buildRecurringNotification builds the message sent when a recurring expense is added
+func buildRecurringNotification(occurrence *models.RecurringOccurrence, settings *models.UserSettings) string {
+	r := occurrence.Recurring
+	return fmt.Sprintf("🔁 Recurring expense added: %s %s %s (%s)\nNext on %s. Use /recurring to pause it.",
+		r.CategoryEmoji, r.CategoryName, settings.FormatAmount(r.Amount),
+		settings.FormatDate(occurrence.DueAt), settings.FormatDate(r.NextDue))
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurringInput(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    *recurringInput
+		expectError bool
+	}{
+		{
+			name:  "multi word category with first due date and notes",
+			input: "25000 monthly Home Loan EMI 2026-11-05; HDFC",
+			expected: &recurringInput{
+				Amount:       25000,
+				Interval:     models.RecurringMonthly,
+				CategoryName: "Home Loan EMI",
+				FirstDue:     time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC),
+				Notes:        "HDFC",
+			},
+		},
+		{
+			name:  "defaults first due to now",
+			input: "199 Monthly Entertainment",
+			expected: &recurringInput{
+				Amount:       199,
+				Interval:     models.RecurringMonthly,
+				CategoryName: "Entertainment",
+				FirstDue:     now,
+			},
+		},
+		{
+			name:  "date-like category is kept when it is the only word",
+			input: "50 daily 2026-11-05",
+			expected: &recurringInput{
+				Amount:       50,
+				Interval:     models.RecurringDaily,
+				CategoryName: "2026-11-05",
+				FirstDue:     now,
+			},
+		},
+		{name: "missing category", input: "25000 monthly", expectError: true},
+		{name: "invalid amount", input: "lots monthly Rent", expectError: true},
+		{name: "unknown interval", input: "25000 fortnightly Rent", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseRecurringInput(tt.input, now)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parsed)
+		})
+	}
+}
+
+func TestBuildRecurringListMessage(t *testing.T) {
+	bot := createTestBot()
+
+	assert.Equal(t, "🔁 No recurring expenses yet.\n\nUse ➕ Add Recurring for rent, EMIs and subscriptions.",
+		bot.buildRecurringListMessage(nil, models.DefaultUserSettings(0)))
+
+	recurring := []*models.RecurringExpense{
+		{ID: 4, CategoryName: "Home Loan EMI", CategoryEmoji: "🏠", Amount: 25000, Interval: models.RecurringMonthly, NextDue: parseTestDate("2026-11-05"), IsActive: true, Notes: "HDFC"},
+		{ID: 9, CategoryName: "Car Loan EMI", CategoryEmoji: "🚘", Amount: 12000, Interval: models.RecurringMonthly, NextDue: parseTestDate("2026-11-10")},
+	}
+
+	expected := "🔁 Recurring Expenses\n\n" +
+		"#4 🏠 Home Loan EMI: ₹25000.00 monthly\n" +
+		"   Next: 05 Nov 2026 🟢 active\n" +
+		"   📝 HDFC\n" +
+		"#9 🚘 Car Loan EMI: ₹12000.00 monthly\n" +
+		"   Next: 10 Nov 2026 ⏸ paused\n"
+	assert.Equal(t, expected, bot.buildRecurringListMessage(recurring, models.DefaultUserSettings(0)))
+}
+
+func TestBuildRecurringNotification(t *testing.T) {
+	occurrence := &models.RecurringOccurrence{
+		Recurring: &models.RecurringExpense{
+			CategoryName:  "Home Loan EMI",
+			CategoryEmoji: "🏠",
+			Amount:        25000,
+			NextDue:       parseTestDate("2026-11-05"),
+		},
+		DueAt: parseTestDate("2026-10-05"),
+	}
+
+	assert.Equal(t, "🔁 Recurring expense added: 🏠 Home Loan EMI ₹25000.00 (05 Oct 2026)\nNext on 05 Nov 2026. Use /recurring to pause it.",
+		buildRecurringNotification(occurrence, models.DefaultUserSettings(0)))
+}
@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// reminderInputHelp explains the format accepted when setting a reminder
+const reminderInputHelp = `⏰ Send the reminder as:
+[daily|<weekday>] HH:MM <message>
+
+Examples:
+21:00 Log today's expenses
+sunday 09:00 Petrol check`
+
+// handleReminderCommand handles the /reminders command
+func (b *Bot) handleReminderCommand(ctx context.Context, message *tgbotapi.Message) error {
+	msg := tgbotapi.NewMessage(message.Chat.ID, "⏰ Reminders:")
+	msg.ReplyMarkup = GetReminderKeyboard()
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// handleReminderCallback handles callback data with the reminder_ prefix
+func (b *Bot) handleReminderCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, action string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	switch {
+	case action == "set":
+		state.ReminderID = 0
+		state.Step = models.StepReminderDetails
+		return b.sendMessage(ctx, chatID, reminderInputHelp)
+
+	case action == "view":
+		reminders, err := b.getRemindersForChat(ctx, callback)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		return b.sendMessage(ctx, chatID, b.buildReminderListMessage(reminders))
+
+	case action == "edit", action == "delete":
+		reminders, err := b.getRemindersForChat(ctx, callback)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		if len(reminders) == 0 {
+			return b.sendMessage(ctx, chatID, "No reminders yet. Use ⏰ Set Reminder to create one.")
+		}
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			fmt.Sprintf("Select a reminder to %s:", action),
+			GetReminderSelectKeyboard(reminders, action))
+		_, err = b.api.Send(msg)
+		return err
+
+	case strings.HasPrefix(action, "edit_"):
+		reminderID, err := strconv.ParseInt(strings.TrimPrefix(action, "edit_"), 10, 64)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		state.ReminderID = reminderID
+		state.Step = models.StepReminderDetails
+		return b.sendMessage(ctx, chatID, reminderInputHelp)
+
+	case strings.HasPrefix(action, "delete_"):
+		reminderID, err := strconv.ParseInt(strings.TrimPrefix(action, "delete_"), 10, 64)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		if err := b.reminderService.DeleteReminder(ctx, callback.From.ID, reminderID); err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, "✅ Reminder deleted.")
+		_, err = b.api.Send(msg)
+		return err
+
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+}
+
+// handleReminderDetails handles the reminder text entered after ⏰ Set Reminder or 🔔 Edit Reminder
+func (b *Bot) handleReminderDetails(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	reminder, err := parseReminderInput(message.Text)
+	if err != nil {
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, reminderInputHelp))
+	}
+
+	// Reminders may be the first thing a user sets up, so make sure the user exists
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	if state.ReminderID != 0 {
+		reminder, err = b.reminderService.UpdateReminder(ctx, message.From.ID, state.ReminderID, reminder, time.Now())
+	} else {
+		err = b.reminderService.CreateReminder(ctx, message.From.ID, reminder, time.Now())
+	}
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	// Reset state
+	b.clearState(ctx, message.Chat.ID, message.From.ID)
+
+	return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf(
+		"✅ Reminder saved: %s\n%s\nNext: %s %s",
+		reminder.Message, formatReminderSchedule(reminder),
+		b.getUserSettings(ctx, message.From.ID).FormatDate(reminder.NextRun), reminder.NextRun.Format("15:04")))
+}
+
+// getRemindersForChat retrieves the reminders of the user behind a callback
+func (b *Bot) getRemindersForChat(ctx context.Context, callback *tgbotapi.CallbackQuery) ([]*models.Reminder, error) {
+	// Make sure the user exists so first-time users see an empty list
+	if _, err := b.userService.GetOrCreateUser(ctx, callback.From.ID, callback.From.UserName, callback.From.FirstName, callback.From.LastName); err != nil {
+		return nil, err
+	}
+	return b.reminderService.GetReminders(ctx, callback.From.ID)
+}
+
+// parseReminderInput parses "[daily|<weekday>] HH:MM <message>"
+func parseReminderInput(input string) (*models.Reminder, error) {
+	fields := strings.Fields(input)
+	reminder := &models.Reminder{Schedule: models.ReminderDaily}
+
+	if len(fields) > 0 && strings.EqualFold(fields[0], "every") {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		if strings.EqualFold(fields[0], "daily") {
+			fields = fields[1:]
+		} else if day, ok := parseWeekday(fields[0]); ok {
+			reminder.Schedule = models.ReminderWeekly
+			reminder.DayOfWeek = sql.NullInt64{Int64: int64(day), Valid: true}
+			fields = fields[1:]
+		}
+	}
+
+	if len(fields) < 2 {
+		return nil, errors.New("expected a time and a message")
+	}
+
+	at, err := time.Parse("15:04", fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q, use HH:MM", fields[0])
+	}
+	reminder.Hour = at.Hour()
+	reminder.Minute = at.Minute()
+	reminder.Message = strings.Join(fields[1:], " ")
+
+	return reminder, nil
+}
+
+// parseWeekday parses a full or three-letter English weekday name
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || s == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// formatReminderSchedule returns a human readable schedule such as "Every Sunday at 09:00"
+func formatReminderSchedule(reminder *models.Reminder) string {
+	at := fmt.Sprintf("%02d:%02d", reminder.Hour, reminder.Minute)
+	if reminder.Schedule == models.ReminderWeekly && reminder.DayOfWeek.Valid {
+		return fmt.Sprintf("Every %s at %s", time.Weekday(reminder.DayOfWeek.Int64), at)
+	}
+	return "Daily at " + at
+}
+
+// buildReminderListMessage builds a formatted list of reminders
+func (b *Bot) buildReminderListMessage(reminders []*models.Reminder) string {
+	if len(reminders) == 0 {
+		return "No reminders yet. Use ⏰ Set Reminder to create one."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⏰ Your Reminders\n\n")
+
+	for _, reminder := range reminders {
+		sb.WriteString(fmt.Sprintf("• %s\n   %s\n", reminder.Message, formatReminderSchedule(reminder)))
+	}
+
+	return sb.String()
+}
+
+// processReminders sends every reminder that is due
+func (b *Bot) processReminders(ctx context.Context, now time.Time) {
+	reminders, err := b.reminderService.ClaimDueReminders(ctx, now)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to process reminders", logger.ErrorField(err))
+		return
+	}
+
+	for _, reminder := range reminders {
+		if err := b.sendMessage(ctx, reminder.TelegramID, "⏰ Reminder: "+reminder.Message); err != nil {
+			b.logger.Warn(ctx, "Failed to send reminder", logger.ErrorField(err),
+				logger.Int("reminder_id", int(reminder.ID)))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReminderInput(t *testing.T) {
+	sunday := sql.NullInt64{Int64: int64(time.Sunday), Valid: true}
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    *models.Reminder
+		expectError bool
+	}{
+		{
+			name:     "daily by default",
+			input:    "21:00 Log today's expenses",
+			expected: &models.Reminder{Message: "Log today's expenses", Schedule: models.ReminderDaily, Hour: 21},
+		},
+		{
+			name:     "explicit daily",
+			input:    "daily 7:30 Check budget",
+			expected: &models.Reminder{Message: "Check budget", Schedule: models.ReminderDaily, Hour: 7, Minute: 30},
+		},
+		{
+			name:     "weekly on a weekday",
+			input:    "every Sunday 09:00 Petrol check",
+			expected: &models.Reminder{Message: "Petrol check", Schedule: models.ReminderWeekly, DayOfWeek: sunday, Hour: 9},
+		},
+		{
+			name:     "short weekday name",
+			input:    "sun 09:00 Petrol check",
+			expected: &models.Reminder{Message: "Petrol check", Schedule: models.ReminderWeekly, DayOfWeek: sunday, Hour: 9},
+		},
+		{
+			name:        "missing message",
+			input:       "21:00",
+			expectError: true,
+		},
+		{
+			name:        "invalid time",
+			input:       "25:00 Log expenses",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseReminderInput(tt.input)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestBuildReminderListMessage(t *testing.T) {
+	bot := createTestBot()
+
+	assert.Equal(t, "No reminders yet. Use ⏰ Set Reminder to create one.", bot.buildReminderListMessage(nil))
+
+	reminders := []*models.Reminder{
+		{Message: "Log today's expenses", Schedule: models.ReminderDaily, Hour: 21},
+		{Message: "Petrol check", Schedule: models.ReminderWeekly, DayOfWeek: sql.NullInt64{Int64: int64(time.Sunday), Valid: true}, Hour: 9, Minute: 5},
+	}
+
+	expected := "⏰ Your Reminders\n\n" +
+		"• Log today's expenses\n   Daily at 21:00\n" +
+		"• Petrol check\n   Every Sunday at 09:05\n"
+	assert.Equal(t, expected, bot.buildReminderListMessage(reminders))
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"time"
+)
+
+// StartScheduler runs background jobs such as recurring expenses and reminders every interval until ctx is done.
+// Jobs also run once immediately so anything that fell due while the bot was down is caught up.
+func (b *Bot) StartScheduler(ctx context.Context, interval time.Duration) {
+	b.logger.Info(ctx, "Starting scheduler...")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		b.runScheduledJobs(ctx, time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				b.runScheduledJobs(ctx, now)
+			}
+		}
+	}()
+}
+
+// runScheduledJobs runs every background job once for the given time
+func (b *Bot) runScheduledJobs(ctx context.Context, now time.Time) {
+	b.processRecurringExpenses(ctx, now)
+	b.processReminders(ctx, now)
+}
+
+// StartEmbeddingWorker embeds queued expenses in the background until ctx is done
+func (b *Bot) StartEmbeddingWorker(ctx context.Context) {
+	if b.embeddingWorker == nil {
+		return
+	}
+	b.embeddingWorker.Start(ctx)
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// settingsSampleDate is shown to preview date formats; day 31 tells DD/MM and MM/DD apart
+var settingsSampleDate = time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// getUserSettings returns the display settings of a user.
+// Rendering must never fail because of settings, so errors fall back to the defaults.
+func (b *Bot) getUserSettings(ctx context.Context, telegramID int64) *models.UserSettings {
+	settings, err := b.settingsService.GetSettings(ctx, telegramID)
+	if err != nil {
+		b.logger.Debug(ctx, "Using default settings", logger.ErrorField(err))
+		return models.DefaultUserSettings(0)
+	}
+	return settings
+}
+
+// handleSettingsCommand handles the /settings command
+func (b *Bot) handleSettingsCommand(ctx context.Context, message *tgbotapi.Message) error {
+	// Make sure the user exists so preferences can be saved
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		b.logger.Error(ctx, "Failed to create user", logger.ErrorField(err))
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildSettingsMessage(b.getUserSettings(ctx, message.From.ID)))
+	msg.ReplyMarkup = GetSettingsKeyboard()
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// handleSettingsCallback handles callback data with the settings_ prefix
+func (b *Bot) handleSettingsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, action string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	var (
+		settings *models.UserSettings
+		err      error
+	)
+
+	switch {
+	case action == "currency":
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, "💱 Choose your currency:", GetCurrencyKeyboard())
+		_, err = b.api.Send(msg)
+		return err
+
+	case action == "date":
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, "📅 Choose your date format:", GetDateFormatKeyboard())
+		_, err = b.api.Send(msg)
+		return err
+
+	case action == "language":
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, "🌍 Choose your language:", GetLanguageKeyboard())
+		_, err = b.api.Send(msg)
+		return err
+
+	case action == "notifications":
+		current := b.getUserSettings(ctx, callback.From.ID)
+		settings, err = b.settingsService.SetNotifications(ctx, callback.From.ID, !current.NotificationsEnabled)
+
+	case strings.HasPrefix(action, "currency_"):
+		settings, err = b.settingsService.SetCurrency(ctx, callback.From.ID, strings.TrimPrefix(action, "currency_"))
+
+	case strings.HasPrefix(action, "date_"):
+		index, convErr := strconv.Atoi(strings.TrimPrefix(action, "date_"))
+		if convErr != nil || index < 0 || index >= len(models.SupportedDateFormats) {
+			return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+		}
+		settings, err = b.settingsService.SetDateFormat(ctx, callback.From.ID, models.SupportedDateFormats[index].Layout)
+
+	case strings.HasPrefix(action, "language_"):
+		settings, err = b.settingsService.SetLanguage(ctx, callback.From.ID, strings.TrimPrefix(action, "language_"))
+
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+
+	if err != nil {
+		return b.sendError(ctx, chatID, err)
+	}
+
+	// Show the updated settings in place
+	msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+		"✅ Settings updated.\n\n"+buildSettingsMessage(settings), GetSettingsKeyboard())
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// buildSettingsMessage builds a summary of the user's current settings
+func buildSettingsMessage(settings *models.UserSettings) string {
+	currency := settings.Currency
+	if c, ok := models.FindCurrency(settings.Currency); ok {
+		currency = fmt.Sprintf("%s (%s)", c.Name, strings.TrimSpace(c.Symbol))
+	}
+
+	language := settings.Language
+	if l, ok := models.FindLanguage(settings.Language); ok {
+		language = l.Name
+	}
+
+	notifications := "🔕 Off"
+	if settings.NotificationsEnabled {
+		notifications = "🔔 On"
+	}
+
+	return fmt.Sprintf("⚙️ Settings\n\n"+
+		"💱 Currency: %s\n"+
+		"📅 Date Format: %s\n"+
+		"🌍 Language: %s\n"+
+		"🔔 Notifications: %s",
+		currency, settings.FormatDate(settingsSampleDate), language, notifications)
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSettingsMessage(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		expected := "⚙️ Settings\n\n" +
+			"💱 Currency: Indian Rupee (₹)\n" +
+			"📅 Date Format: 31 Dec 2026\n" +
+			"🌍 Language: English\n" +
+			"🔔 Notifications: 🔔 On"
+		assert.Equal(t, expected, buildSettingsMessage(models.DefaultUserSettings(1)))
+	})
+
+	t.Run("custom settings", func(t *testing.T) {
+		settings := &models.UserSettings{Currency: "EUR", DateFormat: "02/01/2006", Language: "de"}
+		result := buildSettingsMessage(settings)
+		assert.Contains(t, result, "💱 Currency: Euro (€)")
+		assert.Contains(t, result, "📅 Date Format: 31/12/2026")
+		assert.Contains(t, result, "🌍 Language: Deutsch")
+		assert.Contains(t, result, "🔔 Notifications: 🔕 Off")
+	})
+}
+
+func TestRenderingUsesUserSettings(t *testing.T) {
+	bot := createTestBot()
+	settings := &models.UserSettings{Currency: "USD", DateFormat: "Jan 02, 2006", Language: "en"}
+	expenses := []*models.Expense{
+		{ID: 1, CategoryName: "✈️ Flights", TotalPrice: 420.5, Timestamp: time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)},
+	}
+
+	list := bot.buildExpenseListMessage(expenses, settings)
+	assert.Contains(t, list, "• Mar 09, 2026: $420.50")
+
+	report := bot.buildReportMessage(expenses, settings)
+	assert.Contains(t, report, "💰 Total Expenses: $420.50")
+	assert.Contains(t, report, "• March 2026: $420.50")
+
+	keyboard := GetEditExpenseKeyboard(expenses, settings)
+	assert.Equal(t, "Mar 09 - ✈️ Flights: $420.50", keyboard.InlineKeyboard[0][0].Text)
+
+	settings.Language = "fr"
+	assert.Equal(t, "mars 2026", settings.FormatMonth(expenses[0].Timestamp))
+	assert.Equal(t, "mar 09, 2026", settings.FormatDate(expenses[0].Timestamp))
+}
+
+func TestGetCurrencyKeyboard(t *testing.T) {
+	keyboard := GetCurrencyKeyboard()
+	require.Len(t, keyboard.InlineKeyboard, (len(models.SupportedCurrencies)+1)/2+1)
+
+	assert.Equal(t, "₹ INR", keyboard.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "settings_currency_INR", *keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, "settings_currency_EUR", *keyboard.InlineKeyboard[1][0].CallbackData)
+}
+
+func TestGetDateFormatKeyboard(t *testing.T) {
+	keyboard := GetDateFormatKeyboard()
+	require.Len(t, keyboard.InlineKeyboard, len(models.SupportedDateFormats)+1)
+
+	assert.Equal(t, "31 Dec 2026", keyboard.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "settings_date_0", *keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, "2026-12-31", keyboard.InlineKeyboard[4][0].Text)
+}
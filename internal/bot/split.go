@@ -0,0 +1,268 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const balancesUsage = `Usage:
+/balances [Home] - Who owes whom in a shared ledger, and the fewest payments to settle up
+/balances settle Asha 550 [in Home] - Record that you paid a member back`
+
+// handleSplitCommand handles the /split command: picking a recent shared expense to split
+func (b *Bot) handleSplitCommand(ctx context.Context, message *tgbotapi.Message) error {
+	name, err := b.groupLedgerName(ctx, message, message.CommandArguments())
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	ledger, expenses, err := b.splitService.GetSplittableExpenses(ctx, message.From.ID, name)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	if len(expenses) == 0 {
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf(
+			"No expenses in %s in the last 90 days. Members add expenses to it after /ledger use %s", ledger.Name, ledger.Name))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("➗ Select an expense in %s to split:", ledger.Name))
+	msg.ReplyMarkup = GetSplitExpenseKeyboard(expenses, b.getUserSettings(ctx, message.From.ID))
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleSplitCallback handles the expense and method buttons of the /split flow
+func (b *Bot) handleSplitCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, state *models.UserState, action string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	switch {
+	case strings.HasPrefix(action, "expense_"):
+		expenseID, err := strconv.ParseInt(strings.TrimPrefix(action, "expense_"), 10, 64)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		expense, members, err := b.splitService.GetSplitMembers(ctx, callback.From.ID, expenseID)
+		if err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		state.SplitExpenseID = expense.ID
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			fmt.Sprintf("➗ Split %s %s between %s?",
+				expense.CategoryName, formatExpenseAmount(expense, b.getUserSettings(ctx, callback.From.ID)), formatMemberNames(members)),
+			GetSplitMethodKeyboard())
+		_, err = b.api.Send(msg)
+		return err
+
+	case action == "method_equal":
+		if state.SplitExpenseID == 0 {
+			return b.sendMessage(ctx, chatID, "This split has expired. Please use /split again.")
+		}
+		if err := b.splitExpense(ctx, chatID, callback.From.ID, state.SplitExpenseID, models.SplitMethodEqual, nil); err != nil {
+			return b.sendError(ctx, chatID, err)
+		}
+		b.clearState(ctx, chatID, callback.From.ID)
+		return nil
+
+	case action == "method_shares", action == "method_exact":
+		if state.SplitExpenseID == 0 {
+			return b.sendMessage(ctx, chatID, "This split has expired. Please use /split again.")
+		}
+		prompt := `🔢 Send each member's shares, one per line or separated by commas.
+
+Example:
+Asha 2, Ravi 1`
+		state.SplitMethod = models.SplitMethodShares
+		if action == "method_exact" {
+			prompt = `💵 Send each member's amount, one per line or separated by commas. They must add up to the expense total.
+
+Example:
+Asha 650, Ravi 350`
+			state.SplitMethod = models.SplitMethodExact
+		}
+		state.Step = models.StepSplitDetails
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, prompt)
+		_, err := b.api.Send(msg)
+		return err
+
+	case action == "cancel":
+		b.clearState(ctx, chatID, callback.From.ID)
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, "❌ Split cancelled.")
+		_, err := b.api.Send(msg)
+		return err
+
+	default:
+		return b.sendMessage(ctx, chatID, "Invalid selection. Please try again.")
+	}
+}
+
+// handleSplitDetails handles the shares or exact amounts entered for the split in state
+func (b *Bot) handleSplitDetails(ctx context.Context, message *tgbotapi.Message, state *models.UserState) error {
+	parts, err := parseSplitParts(message.Text)
+	if err != nil {
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("❌ %v. Please try again or send /cancel.", err))
+	}
+	if err := b.splitExpense(ctx, message.Chat.ID, message.From.ID, state.SplitExpenseID, state.SplitMethod, parts); err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	b.clearState(ctx, message.Chat.ID, message.From.ID)
+	return nil
+}
+
+// splitExpense splits an expense and replies with each member's part
+func (b *Bot) splitExpense(ctx context.Context, chatID, telegramID, expenseID int64, method models.SplitMethod, parts []models.SplitPart) error {
+	splits, err := b.splitService.SplitExpense(ctx, telegramID, expenseID, method, parts)
+	if err != nil {
+		return err
+	}
+	expense, members, err := b.splitService.GetSplitMembers(ctx, telegramID, expenseID)
+	if err != nil {
+		return err
+	}
+	return b.sendMessage(ctx, chatID, buildSplitMessage(expense, splits, members, b.getUserSettings(ctx, telegramID)))
+}
+
+// handleBalancesCommand handles the /balances command: balances and settlements in a shared ledger
+func (b *Bot) handleBalancesCommand(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || !strings.EqualFold(args[0], "settle") {
+		if len(args) == 1 && strings.EqualFold(args[0], "help") {
+			return b.sendMessage(ctx, message.Chat.ID, balancesUsage)
+		}
+		name, err := b.groupLedgerName(ctx, message, strings.Join(args, " "))
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		balances, err := b.splitService.GetBalances(ctx, message.From.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildBalancesMessage(balances))
+	}
+
+	settleArgs, ledgerArgs, _ := splitArgsAt(args[1:], "in")
+	if len(settleArgs) < 2 {
+		return b.sendMessage(ctx, message.Chat.ID, balancesUsage)
+	}
+	amount, err := strconv.ParseFloat(settleArgs[len(settleArgs)-1], 64)
+	if err != nil {
+		return b.sendMessage(ctx, message.Chat.ID, balancesUsage)
+	}
+	memberName := strings.Join(settleArgs[:len(settleArgs)-1], " ")
+	ledgerName, err := b.groupLedgerName(ctx, message, strings.Join(ledgerArgs, " "))
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	settlement, member, err := b.splitService.Settle(ctx, message.From.ID, ledgerName, memberName, amount)
+	if err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+	return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Recorded your payment of %s to %s. See what is left with /balances",
+		models.FormatMoney(settlement.Amount, settlement.Currency), member.DisplayName()))
+}
+
+// parseSplitParts parses "<member> <value>" parts separated by commas or new lines
+func parseSplitParts(text string) ([]models.SplitPart, error) {
+	var parts []models.SplitPart
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' || r == ';' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(part, " ")
+		if idx <= 0 {
+			return nil, fmt.Errorf(`expected "<member> <number>", got %q`, part)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(part[idx+1:]), 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("%q is not a positive number", strings.TrimSpace(part[idx+1:]))
+		}
+		parts = append(parts, models.SplitPart{Member: strings.TrimSpace(part[:idx]), Value: value})
+	}
+
+	if len(parts) == 0 {
+		return nil, errors.New("no members given")
+	}
+	return parts, nil
+}
+
+// formatMemberNames joins member names as "Asha, Ravi and Meera"
+func formatMemberNames(members []*models.LedgerMember) string {
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.DisplayName()
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// buildSplitMessage lists each member's part of a split expense
+func buildSplitMessage(expense *models.Expense, splits []*models.ExpenseSplit, members []*models.LedgerMember, settings *models.UserSettings) string {
+	names := make(map[int64]string, len(members))
+	for _, member := range members {
+		names[member.UserID] = member.DisplayName()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "✅ Split %s %s\n\n", expense.CategoryName, settings.FormatAmount(expense.TotalPrice))
+	for _, split := range splits {
+		name, ok := names[split.UserID]
+		if !ok {
+			name = "Former member"
+		}
+		fmt.Fprintf(&sb, "• %s: %s\n", name, settings.FormatAmount(split.Amount))
+	}
+	sb.WriteString("\nSee who owes whom with /balances")
+	return sb.String()
+}
+
+// buildBalancesMessage builds the /balances message with each member's balance and the
+// payments that settle them
+func buildBalancesMessage(balances *models.LedgerBalances) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "⚖️ %s Balances\n\n", balances.Ledger.Name)
+	if len(balances.Transfers) == 0 {
+		sb.WriteString("✅ Everyone is settled up. Split shared expenses with /split")
+		writeUnconvertedWarning(&sb, balances)
+		return sb.String()
+	}
+
+	for _, balance := range balances.Balances {
+		switch {
+		case math.Abs(balance.Amount) < 0.005:
+			fmt.Fprintf(&sb, "• %s is settled up\n", balance.Name)
+		case balance.Amount > 0:
+			fmt.Fprintf(&sb, "• %s is owed %s\n", balance.Name, models.FormatMoney(balance.Amount, balances.Currency))
+		default:
+			fmt.Fprintf(&sb, "• %s owes %s\n", balance.Name, models.FormatMoney(-balance.Amount, balances.Currency))
+		}
+	}
+
+	sb.WriteString("\n💸 To settle up\n")
+	for _, transfer := range balances.Transfers {
+		fmt.Fprintf(&sb, "• %s pays %s %s\n", transfer.From, transfer.To, models.FormatMoney(transfer.Amount, balances.Currency))
+	}
+	sb.WriteString("\nRecord a payment with /balances settle <member> <amount>")
+	writeUnconvertedWarning(&sb, balances)
+	return sb.String()
+}
+
+// writeUnconvertedWarning warns that expenses or settlements without an exchange rate were left
+// out of the balances
+func writeUnconvertedWarning(sb *strings.Builder, balances *models.LedgerBalances) {
+	if balances.Unconverted == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n\n⚠️ %d expense(s) or settlement(s) have no exchange rate to %s and are left out: %s. Add rates with /rates.",
+		balances.Unconverted, balances.Currency, strings.Join(balances.MissingRates, ", "))
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSplitParts(t *testing.T) {
+	parts, err := parseSplitParts("Asha 2, @ravi 1\nMeera Rao 1.5")
+	require.NoError(t, err)
+	assert.Equal(t, []models.SplitPart{
+		{Member: "Asha", Value: 2},
+		{Member: "@ravi", Value: 1},
+		{Member: "Meera Rao", Value: 1.5},
+	}, parts)
+
+	for _, input := range []string{"", "Asha", "Asha two", "Asha -5", " , "} {
+		_, err := parseSplitParts(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestFormatMemberNames(t *testing.T) {
+	members := []*models.LedgerMember{{FirstName: "Asha"}, {Username: "ravi"}, {FirstName: "Meera"}}
+	assert.Equal(t, "Asha, @ravi and Meera", formatMemberNames(members))
+	assert.Equal(t, "Asha", formatMemberNames(members[:1]))
+}
+
+func TestBuildSplitMessage(t *testing.T) {
+	expense := &models.Expense{CategoryName: "Rent", TotalPrice: 900}
+	members := []*models.LedgerMember{{UserID: 1, FirstName: "Asha"}, {UserID: 2, FirstName: "Ravi"}}
+	splits := []*models.ExpenseSplit{
+		{UserID: 1, Amount: 600},
+		{UserID: 2, Amount: 200},
+		{UserID: 3, Amount: 100},
+	}
+
+	assert.Equal(t, `✅ Split Rent ₹900.00
+
+• Asha: ₹600.00
+• Ravi: ₹200.00
+• Former member: ₹100.00
+
+See who owes whom with /balances`, buildSplitMessage(expense, splits, members, models.DefaultUserSettings(1)))
+}
+
+func TestBuildBalancesMessage(t *testing.T) {
+	balances := &models.LedgerBalances{
+		Ledger:   &models.Ledger{Name: "Flat"},
+		Currency: "INR",
+		Balances: []models.MemberBalance{
+			{Name: "Asha", Amount: 600},
+			{Name: "Kiran", Amount: 0},
+			{Name: "Ravi", Amount: -100},
+			{Name: "Meera", Amount: -500},
+		},
+		Transfers: []models.Transfer{
+			{From: "Meera", To: "Asha", Amount: 500},
+			{From: "Ravi", To: "Asha", Amount: 100},
+		},
+	}
+
+	assert.Equal(t, `⚖️ Flat Balances
+
+• Asha is owed ₹600.00
+• Kiran is settled up
+• Ravi owes ₹100.00
+• Meera owes ₹500.00
+
+💸 To settle up
+• Meera pays Asha ₹500.00
+• Ravi pays Asha ₹100.00
+
+Record a payment with /balances settle <member> <amount>`, buildBalancesMessage(balances))
+
+	settled := &models.LedgerBalances{Ledger: balances.Ledger, Currency: "INR"}
+	assert.Contains(t, buildBalancesMessage(settled), "Everyone is settled up")
+
+	settled.Unconverted, settled.MissingRates = 2, []string{"EUR→INR", "USD→INR"}
+	assert.Contains(t, buildBalancesMessage(settled), "⚠️ 2 expense(s) or settlement(s) have no exchange rate to INR and are left out: EUR→INR, USD→INR")
+}
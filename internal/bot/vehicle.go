@@ -0,0 +1,331 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const vehicleUsage = `Usage:
+/vehicle - Fuel efficiency and running costs per vehicle
+/vehicle list - Your vehicles
+/vehicle add car Swift [diesel] [reg MH12AB1234] [odo 45000] - Add a car, bike, scooter or other vehicle; fuel is petrol, diesel, cng or electric
+/vehicle remove Swift - Remove a vehicle, keeping its expenses`
+
+// vehicleTypeWords maps the words accepted by /vehicle add to vehicle types
+var vehicleTypeWords = map[string]models.VehicleType{
+	"car":     models.VehicleTypeCar,
+	"bike":    models.VehicleTypeBike,
+	"scooter": models.VehicleTypeScooter,
+	"other":   models.VehicleTypeOther,
+}
+
+// fuelTypeWords maps the words accepted by /vehicle add to fuel types
+var fuelTypeWords = map[string]models.FuelType{
+	"petrol":   models.FuelTypePetrol,
+	"diesel":   models.FuelTypeDiesel,
+	"cng":      models.FuelTypeCNG,
+	"electric": models.FuelTypeElectric,
+	"ev":       models.FuelTypeElectric,
+}
+
+// handleVehicleCommand handles the /vehicle command: a breakdown per vehicle, or managing vehicles
+func (b *Bot) handleVehicleCommand(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendVehicleReport(ctx, message.Chat.ID, message.From.ID)
+	}
+
+	if _, err := b.userService.GetOrCreateUser(ctx, message.From.ID, message.From.UserName, message.From.FirstName, message.From.LastName); err != nil {
+		return b.sendError(ctx, message.Chat.ID, err)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		vehicles, err := b.vehicleService.GetVehicles(ctx, message.From.ID)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, buildVehicleListMessage(vehicles))
+
+	case "add":
+		vehicle, err := parseVehicleArgs(args[1:])
+		if err != nil {
+			return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("%v\n\n%s", err, vehicleUsage))
+		}
+		if err := b.vehicleService.AddVehicle(ctx, message.From.ID, vehicle); err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("✅ Added %s. Pick it when adding Vehicle expenses.", formatVehicle(vehicle)))
+
+	case "remove":
+		name := strings.Join(args[1:], " ")
+		if name == "" {
+			return b.sendMessage(ctx, message.Chat.ID, vehicleUsage)
+		}
+		vehicle, err := b.vehicleService.RemoveVehicle(ctx, message.From.ID, name)
+		if err != nil {
+			return b.sendError(ctx, message.Chat.ID, err)
+		}
+		return b.sendMessage(ctx, message.Chat.ID, fmt.Sprintf("🗑️ Removed %s. Its expenses are kept.", vehicle.Name))
+
+	default:
+		return b.sendMessage(ctx, message.Chat.ID, vehicleUsage)
+	}
+}
+
+// sendVehicleReport sends the fuel efficiency and running costs of each of the user's vehicles
+func (b *Bot) sendVehicleReport(ctx context.Context, chatID, telegramID int64) error {
+	settings := b.getUserSettings(ctx, telegramID)
+	stats, err := b.vehicleService.GetVehicleStats(ctx, telegramID, settings.Currency)
+	if err != nil {
+		b.logger.Error(ctx, "Failed to get vehicle stats", logger.ErrorField(err))
+		return b.sendError(ctx, chatID, err)
+	}
+	return b.sendMessage(ctx, chatID, buildVehicleMessage(stats, settings))
+}
+
+// getVehicles returns the user's vehicles, or none for a user who has not been created yet
+func (b *Bot) getVehicles(ctx context.Context, telegramID int64) ([]*models.Vehicle, error) {
+	vehicles, err := b.vehicleService.GetVehicles(ctx, telegramID)
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && appErr.IsNotFoundError() {
+		return nil, nil
+	}
+	return vehicles, err
+}
+
+// findVehicle returns the vehicle with the given ID, or nil
+func findVehicle(vehicles []*models.Vehicle, id int64) *models.Vehicle {
+	for _, vehicle := range vehicles {
+		if vehicle.ID == id {
+			return vehicle
+		}
+	}
+	return nil
+}
+
+// selectVehicle applies a choice from GetVehicleKeyboard to the expense and returns the vehicle.
+// The choice is a vehicle ID, or a vehicle type for users without vehicles; the expense service
+// then links the expense to a vehicle of that type, and the returned vehicle has only the type.
+func (b *Bot) selectVehicle(ctx context.Context, telegramID int64, expense *models.Expense, choice string) (*models.Vehicle, error) {
+	id, err := strconv.ParseInt(choice, 10, 64)
+	if err != nil {
+		expense.VehicleType = sql.NullString{String: choice, Valid: true}
+		expense.VehicleID = sql.NullInt64{}
+		return &models.Vehicle{Type: models.VehicleType(choice), FuelType: models.FuelTypePetrol}, nil
+	}
+
+	vehicles, err := b.getVehicles(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	vehicle := findVehicle(vehicles, id)
+	if vehicle == nil {
+		return nil, apperrors.NewNotFoundError("Vehicle not found", fmt.Sprintf("Vehicle with ID %d not found", id))
+	}
+	expense.VehicleID = sql.NullInt64{Int64: vehicle.ID, Valid: true}
+	expense.VehicleType = sql.NullString{String: string(vehicle.Type), Valid: true}
+	return vehicle, nil
+}
+
+// expenseFuelType returns what the expense's vehicle runs on, petrol when it is not known
+func (b *Bot) expenseFuelType(ctx context.Context, telegramID int64, expense *models.Expense) models.FuelType {
+	if !expense.VehicleID.Valid {
+		return models.FuelTypePetrol
+	}
+	vehicles, err := b.getVehicles(ctx, telegramID)
+	if err != nil {
+		b.logger.Warn(ctx, "Failed to get vehicles", logger.ErrorField(err))
+		return models.FuelTypePetrol
+	}
+	if vehicle := findVehicle(vehicles, expense.VehicleID.Int64); vehicle != nil {
+		return vehicle.FuelType
+	}
+	return models.FuelTypePetrol
+}
+
+// parseVehicleArgs parses "TYPE NAME [FUEL] [reg REGISTRATION] [odo READING]"
+func parseVehicleArgs(args []string) (*models.Vehicle, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("please give a vehicle type and a name")
+	}
+
+	vehicleType, ok := vehicleTypeWords[strings.ToLower(args[0])]
+	if !ok {
+		return nil, fmt.Errorf("unknown vehicle type %q, use car, bike, scooter or other", args[0])
+	}
+
+	vehicle := &models.Vehicle{Type: vehicleType, FuelType: models.FuelTypePetrol}
+	var name []string
+	for i := 1; i < len(args); i++ {
+		word := strings.ToLower(args[i])
+		if fuelType, ok := fuelTypeWords[word]; ok && len(name) > 0 {
+			vehicle.FuelType = fuelType
+			continue
+		}
+		if (word == "reg" || word == "odo") && i+1 < len(args) {
+			i++
+			if word == "reg" {
+				vehicle.Registration = strings.ToUpper(args[i])
+				continue
+			}
+			reading, err := strconv.ParseFloat(args[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid odometer reading %q", args[i])
+			}
+			vehicle.InitialOdometer = reading
+			continue
+		}
+		name = append(name, args[i])
+	}
+
+	vehicle.Name = strings.Join(name, " ")
+	if vehicle.Name == "" {
+		return nil, fmt.Errorf("please give the vehicle a name")
+	}
+	return vehicle, nil
+}
+
+// vehicleLabel names a vehicle type for display
+func vehicleLabel(vehicleType string) string {
+	switch models.VehicleType(vehicleType) {
+	case models.VehicleTypeCar:
+		return "🚗 Car"
+	case models.VehicleTypeBike:
+		return "🏍️ Bike"
+	case models.VehicleTypeScooter:
+		return "🛵 Scooter"
+	case models.VehicleTypeOther:
+		return "🚙 Other"
+	default:
+		return vehicleType
+	}
+}
+
+// vehicleEmoji returns the emoji of a vehicle type
+func vehicleEmoji(vehicleType models.VehicleType) string {
+	label := vehicleLabel(string(vehicleType))
+	if emoji, _, found := strings.Cut(label, " "); found {
+		return emoji
+	}
+	return "🚗"
+}
+
+// statsLabel names the vehicle of a stats entry
+func statsLabel(stats *models.VehicleStats) string {
+	return vehicleName(&models.Vehicle{Name: stats.Name, Type: models.VehicleType(stats.VehicleType)})
+}
+
+// vehicleName names a vehicle for display, by its type when it has no name
+func vehicleName(vehicle *models.Vehicle) string {
+	if vehicle.Name == "" {
+		return vehicleLabel(string(vehicle.Type))
+	}
+	return vehicleEmoji(vehicle.Type) + " " + vehicle.Name
+}
+
+// formatVehicle describes a vehicle on one line, e.g. "🚗 Swift (diesel, MH12AB1234)"
+func formatVehicle(vehicle *models.Vehicle) string {
+	details := []string{strings.ToLower(string(vehicle.FuelType))}
+	if vehicle.Registration != "" {
+		details = append(details, vehicle.Registration)
+	}
+	return fmt.Sprintf("%s (%s)", vehicleName(vehicle), strings.Join(details, ", "))
+}
+
+// fuelPricePrompt asks for the price per litre, kg or kWh of what the vehicle runs on
+func fuelPricePrompt(fuelType models.FuelType) string {
+	switch fuelType {
+	case models.FuelTypeElectric:
+		return "🔌 Please enter the charging price per kWh:"
+	case models.FuelTypeCNG:
+		return "⛽ Please enter the CNG price per kg:"
+	case models.FuelTypeDiesel:
+		return "⛽ Please enter the diesel price per liter:"
+	default:
+		return "⛽ Please enter the petrol price per liter:"
+	}
+}
+
+// buildVehicleListMessage lists the user's vehicles
+func buildVehicleListMessage(vehicles []*models.Vehicle) string {
+	if len(vehicles) == 0 {
+		return "🚗 You have no vehicles yet.\n\n" + vehicleUsage
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚗 Your Vehicles\n\n")
+	for _, vehicle := range vehicles {
+		sb.WriteString("• " + formatVehicle(vehicle))
+		if vehicle.InitialOdometer > 0 {
+			sb.WriteString(fmt.Sprintf(", odometer %.0f km when added", vehicle.InitialOdometer))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// buildVehicleMessage builds the /vehicle report
+func buildVehicleMessage(stats []*models.VehicleStats, settings *models.UserSettings) string {
+	if len(stats) == 0 {
+		return "No vehicle expenses found. Add your vehicles with /vehicle add, pick one when adding Vehicle expenses, and enter odometer readings with fuel fills to see fuel efficiency."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚗 Vehicle Analytics\n")
+
+	for _, vehicle := range stats {
+		sb.WriteString(fmt.Sprintf("\n%s\n", statsLabel(vehicle)))
+		if vehicle.Distance > 0 {
+			sb.WriteString(fmt.Sprintf("• Distance: %.0f km (odometer %.0f → %.0f)\n", vehicle.Distance, vehicle.FirstReading, vehicle.LastReading))
+		}
+		if vehicle.Fills > 0 {
+			if vehicle.FuelQuantity > 0 {
+				sb.WriteString(fmt.Sprintf("• Fuel: %.1f %s in %d fills, %s\n", vehicle.FuelQuantity, vehicle.FuelType.Unit(), vehicle.Fills, settings.FormatAmount(vehicle.FuelCost)))
+			} else {
+				sb.WriteString(fmt.Sprintf("• Fuel: %d fills, %s\n", vehicle.Fills, settings.FormatAmount(vehicle.FuelCost)))
+			}
+		}
+		if vehicle.FuelEfficiency > 0 {
+			sb.WriteString(fmt.Sprintf("• Fuel Efficiency: %.1f km/%s\n", vehicle.FuelEfficiency, vehicle.FuelType.Unit()))
+		}
+		if vehicle.MaintenanceCost > 0 {
+			sb.WriteString(fmt.Sprintf("• Service, Repairs & Insurance: %s\n", settings.FormatAmount(vehicle.MaintenanceCost)))
+		}
+		if vehicle.OtherCost > 0 {
+			sb.WriteString(fmt.Sprintf("• Other: %s\n", settings.FormatAmount(vehicle.OtherCost)))
+		}
+		sb.WriteString(fmt.Sprintf("• Total: %s\n", settings.FormatAmount(vehicle.TotalCost())))
+		if vehicle.CostPerKm > 0 {
+			sb.WriteString(fmt.Sprintf("• Cost per km: %s (fuel and maintenance)\n", settings.FormatAmount(vehicle.CostPerKm)))
+		}
+		for _, regression := range vehicle.Regressions {
+			sb.WriteString(fmt.Sprintf("⚠️ Odometer went back from %.0f to %.0f on %s. Check that entry with /edit.\n",
+				regression.Previous, regression.Reading, settings.FormatDate(regression.Timestamp)))
+		}
+	}
+
+	if !hasFuelEfficiency(stats) {
+		sb.WriteString("\nEnter the odometer reading and price per litre or kWh with each fill to see fuel efficiency.")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// hasFuelEfficiency reports whether any vehicle has enough fills to compute km/L or km/kWh
+func hasFuelEfficiency(stats []*models.VehicleStats) bool {
+	for _, vehicle := range stats {
+		if vehicle.FuelEfficiency > 0 {
+			return true
+		}
+	}
+	return false
+}
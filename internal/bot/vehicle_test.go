@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVehicleMessage(t *testing.T) {
+	settings := models.DefaultUserSettings(0)
+
+	assert.Contains(t, buildVehicleMessage(nil, settings), "No vehicle expenses found.")
+
+	stats := []*models.VehicleStats{
+		{
+			VehicleType: "CAR", Distance: 800, FirstReading: 10000, LastReading: 10800,
+			Fills: 4, FuelQuantity: 55, FuelCost: 5500, MaintenanceCost: 3000, OtherCost: 200,
+			FuelEfficiency: 26.666, CostPerKm: 10.6,
+			Regressions: []models.OdometerRegression{
+				{ExpenseID: 6, Timestamp: time.Date(2026, 3, 25, 9, 0, 0, 0, time.UTC), Previous: 10800, Reading: 1090},
+			},
+		},
+		{VehicleType: "BIKE", Fills: 1, FuelCost: 300},
+		{
+			VehicleID: 7, Name: "Nexon EV", VehicleType: "CAR", FuelType: models.FuelTypeElectric,
+			Distance: 300, FirstReading: 5000, LastReading: 5300, Fills: 3, FuelQuantity: 60, FuelCost: 600,
+			FuelEfficiency: 7.5, CostPerKm: 2,
+		},
+	}
+	assert.Equal(t, `🚗 Vehicle Analytics
+
+🚗 Car
+• Distance: 800 km (odometer 10000 → 10800)
+• Fuel: 55.0 L in 4 fills, ₹5500.00
+• Fuel Efficiency: 26.7 km/L
+• Service, Repairs & Insurance: ₹3000.00
+• Other: ₹200.00
+• Total: ₹8700.00
+• Cost per km: ₹10.60 (fuel and maintenance)
+⚠️ Odometer went back from 10800 to 1090 on 25 Mar 2026. Check that entry with /edit.
+
+🏍️ Bike
+• Fuel: 1 fills, ₹300.00
+• Total: ₹300.00
+
+🚗 Nexon EV
+• Distance: 300 km (odometer 5000 → 5300)
+• Fuel: 60.0 kWh in 3 fills, ₹600.00
+• Fuel Efficiency: 7.5 km/kWh
+• Total: ₹600.00
+• Cost per km: ₹2.00 (fuel and maintenance)`, buildVehicleMessage(stats, settings))
+
+	assert.Contains(t, buildVehicleMessage(stats[1:2], settings), "Enter the odometer reading and price per litre")
+}
+
+func TestParseVehicleArgs(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    *models.Vehicle
+		wantErr bool
+	}{
+		{args: "car Swift", want: &models.Vehicle{Name: "Swift", Type: models.VehicleTypeCar, FuelType: models.FuelTypePetrol}},
+		{args: "car Swift Dzire diesel reg mh12ab1234 odo 45000", want: &models.Vehicle{
+			Name: "Swift Dzire", Type: models.VehicleTypeCar, FuelType: models.FuelTypeDiesel, Registration: "MH12AB1234", InitialOdometer: 45000,
+		}},
+		{args: "scooter Ather electric", want: &models.Vehicle{Name: "Ather", Type: models.VehicleTypeScooter, FuelType: models.FuelTypeElectric}},
+		// A fuel word before any name is the name
+		{args: "other EV", want: &models.Vehicle{Name: "EV", Type: models.VehicleTypeOther, FuelType: models.FuelTypePetrol}},
+		{args: "car", wantErr: true},
+		{args: "truck Tata", wantErr: true},
+		{args: "bike Pulsar odo many", wantErr: true},
+		{args: "bike reg MH12", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			vehicle, err := parseVehicleArgs(strings.Fields(tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, vehicle)
+		})
+	}
+}
+
+func TestBuildVehicleListMessage(t *testing.T) {
+	assert.Contains(t, buildVehicleListMessage(nil), "You have no vehicles yet.")
+
+	vehicles := []*models.Vehicle{
+		{ID: 1, Name: "Swift", Type: models.VehicleTypeCar, FuelType: models.FuelTypePetrol, Registration: "MH12AB1234", InitialOdometer: 45000},
+		{ID: 2, Name: "Ather", Type: models.VehicleTypeScooter, FuelType: models.FuelTypeElectric},
+	}
+	assert.Equal(t, "🚗 Your Vehicles\n\n"+
+		"• 🚗 Swift (petrol, MH12AB1234), odometer 45000 km when added\n"+
+		"• 🛵 Ather (electric)", buildVehicleListMessage(vehicles))
+}
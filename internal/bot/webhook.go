@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webhookSecretHeader carries the secret token Telegram was given when the webhook was set
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxWebhookBodySize caps the size of an update posted to the webhook
+const maxWebhookBodySize = 1 << 20
+
+// StartWebhook registers url as the bot's webhook with Telegram, which then posts updates to it
+// with secret in the X-Telegram-Bot-Api-Secret-Token header. The updates are received by the
+// handler from WebhookHandler, which must be served at url. It blocks until ctx is done.
+func (b *Bot) StartWebhook(ctx context.Context, url, secret string) error {
+	b.logger.Info(ctx, "Starting bot with webhook...", logger.String("url", url))
+
+	stopDispatcher := b.startDispatcher(ctx)
+	defer stopDispatcher()
+
+	if _, err := b.api.MakeRequest("setWebhook", tgbotapi.Params{
+		"url":          url,
+		"secret_token": secret,
+	}); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// WebhookHandler returns the HTTP handler Telegram posts updates to in webhook mode. Requests
+// without the secret token are rejected, so only Telegram can send the bot updates. Each update
+// is processed like one received by long polling.
+func (b *Bot) WebhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(secret)) != 1 {
+			b.logger.Warn(r.Context(), "Rejected webhook request with a wrong secret token",
+				logger.String("remote_addr", r.RemoteAddr))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookBodySize)).Decode(&update); err != nil {
+			http.Error(w, "Invalid update", http.StatusBadRequest)
+			return
+		}
+
+		// The update is acknowledged once it is queued, as with long polling; failures to handle
+		// it are logged. Telegram retries updates that could not be queued.
+		if err := b.dispatchUpdate(r.Context(), &update); err != nil {
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
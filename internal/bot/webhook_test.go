@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const helpUpdate = `{
+	"update_id": 1001,
+	"message": {
+		"message_id": 5,
+		"from": {"id": 12345, "is_bot": false, "first_name": "Asha"},
+		"chat": {"id": 12345, "type": "private"},
+		"date": 1760600000,
+		"text": "/help",
+		"entities": [{"type": "bot_command", "offset": 0, "length": 5}]
+	}
+}`
+
+func TestWebhookHandler(t *testing.T) {
+	var sent []tgbotapi.Chattable
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(tgbotapi.Chattable))
+	}).Return(tgbotapi.Message{}, nil)
+
+	bot := &Bot{
+		api:        mockAPI,
+		db:         database.NewMockStorage(),
+		logger:     logger.NewMockLogger(),
+		stateStore: statestore.NewMemoryStore(statestore.DefaultTTL),
+	}
+	server := httptest.NewServer(bot.WebhookHandler("s3cret_token"))
+	defer server.Close()
+
+	post := func(t *testing.T, secret, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set(webhookSecretHeader, secret)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("handles updates posted with the secret token", func(t *testing.T) {
+		sent = nil
+		resp := post(t, "s3cret_token", helpUpdate)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, sent, 1)
+		msg := sent[0].(tgbotapi.MessageConfig)
+		assert.Equal(t, int64(12345), msg.ChatID)
+		assert.Contains(t, msg.Text, "Here are the available commands")
+	})
+
+	t.Run("rejects requests without the secret token", func(t *testing.T) {
+		sent = nil
+		assert.Equal(t, http.StatusUnauthorized, post(t, "", helpUpdate).StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, post(t, "wrong", helpUpdate).StatusCode)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("rejects malformed updates", func(t *testing.T) {
+		sent = nil
+		assert.Equal(t, http.StatusBadRequest, post(t, "s3cret_token", `{"update_id":`).StatusCode)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("only accepts POST", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
+
+func TestStartWebhook(t *testing.T) {
+	mockAPI := &MockBotAPI{}
+	mockAPI.On("MakeRequest", "setWebhook", tgbotapi.Params{
+		"url":          "https://bot.example.com/telegram",
+		"secret_token": "s3cret_token",
+	}).Return(&tgbotapi.APIResponse{Ok: true}, nil)
+	bot := &Bot{api: mockAPI, logger: logger.NewMockLogger()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bot.StartWebhook(ctx, "https://bot.example.com/telegram", "s3cret_token") }()
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("StartWebhook did not return once the context was done")
+	}
+	mockAPI.AssertExpectations(t)
+}
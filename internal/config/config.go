@@ -4,13 +4,26 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ocr"
+	"github.com/MitulShah1/expense-tracker-bot/internal/ratelimit"
+	"github.com/MitulShah1/expense-tracker-bot/internal/statestore"
 	"github.com/joho/godotenv"
 )
 
+// How the bot receives updates from Telegram
+const (
+	UpdateModePolling = "polling" // Long polling, which needs no public endpoint
+	UpdateModeWebhook = "webhook" // Telegram posts updates to WEBHOOK_URL
+)
+
 // Config holds the application configuration
 type Config struct {
 	TelegramToken string
@@ -18,11 +31,53 @@ type Config struct {
 	LogLevel      string
 	IsDevMode     bool
 
+	// Receiving updates: long polling, or a webhook served on the health check server
+	UpdateMode    string
+	WebhookURL    string
+	WebhookSecret string
+
 	// Database Configuration
 	DatabaseURL       string
 	DBMaxOpenConns    int
 	DBMaxIdleConns    int
 	DBConnMaxLifetime time.Duration
+
+	// Background Jobs
+	SchedulerInterval time.Duration
+
+	// Embeddings for semantic search
+	EmbeddingProvider string
+	EmbeddingURL      string
+	EmbeddingAPIKey   string
+	EmbeddingModel    string
+	EmbeddingTimeout  time.Duration
+
+	// Blob store for receipt attachments
+	BlobStore         string
+	BlobDir           string
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3Timeout         time.Duration
+
+	// Receipt OCR, which adds expenses from receipt photos
+	OCRProvider   string
+	TesseractPath string
+	OCRLanguages  string
+	OCRTimeout    time.Duration
+
+	// Conversation state, kept in the database so it survives restarts and is shared by replicas
+	StateStore string
+	StateTTL   time.Duration
+
+	// Per-user rate limits by class of request, and bans for users who keep exceeding them
+	RateLimitDefault     ratelimit.Limit
+	RateLimitHeavy       ratelimit.Limit
+	RateLimitUpload      ratelimit.Limit
+	RateLimitBanAfter    int
+	RateLimitBanDuration time.Duration
 }
 
 // Load loads the configuration from environment variables
@@ -52,15 +107,157 @@ func Load() (*Config, error) {
 		}
 	}
 
+	schedulerInterval := time.Minute // default
+	if val := os.Getenv("SCHEDULER_INTERVAL"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed > 0 {
+			schedulerInterval = parsed
+		}
+	}
+
+	embeddingProvider := embedding.ProviderLocal // default
+	if val := os.Getenv("EMBEDDING_PROVIDER"); val != "" {
+		embeddingProvider = strings.ToLower(val)
+	}
+
+	embeddingURL := "https://api.openai.com/v1/embeddings" // default
+	if val := os.Getenv("EMBEDDING_URL"); val != "" {
+		embeddingURL = val
+	}
+
+	embeddingModel := "text-embedding-3-small" // default
+	if val := os.Getenv("EMBEDDING_MODEL"); val != "" {
+		embeddingModel = val
+	}
+
+	embeddingTimeout := 30 * time.Second // default
+	if val := os.Getenv("EMBEDDING_TIMEOUT"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed > 0 {
+			embeddingTimeout = parsed
+		}
+	}
+
+	blobStore := blobstore.ProviderLocal // default
+	if val := os.Getenv("BLOB_STORE"); val != "" {
+		blobStore = strings.ToLower(val)
+	}
+
+	blobDir := "data/blobs" // default
+	if val := os.Getenv("BLOB_DIR"); val != "" {
+		blobDir = val
+	}
+
+	s3Region := "us-east-1" // default
+	if val := os.Getenv("S3_REGION"); val != "" {
+		s3Region = val
+	}
+
+	s3Timeout := 30 * time.Second // default
+	if val := os.Getenv("S3_TIMEOUT"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed > 0 {
+			s3Timeout = parsed
+		}
+	}
+
+	ocrProvider := ocr.ProviderTesseract // default
+	if val := os.Getenv("OCR_PROVIDER"); val != "" {
+		ocrProvider = strings.ToLower(val)
+	}
+
+	tesseractPath := "tesseract" // default, looked up on PATH
+	if val := os.Getenv("TESSERACT_PATH"); val != "" {
+		tesseractPath = val
+	}
+
+	ocrLanguages := "eng" // default
+	if val := os.Getenv("OCR_LANGUAGES"); val != "" {
+		ocrLanguages = val
+	}
+
+	ocrTimeout := 30 * time.Second // default
+	if val := os.Getenv("OCR_TIMEOUT"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed > 0 {
+			ocrTimeout = parsed
+		}
+	}
+
+	updateMode := UpdateModePolling // default
+	if val := os.Getenv("UPDATE_MODE"); val != "" {
+		updateMode = strings.ToLower(val)
+	}
+
+	stateStore := statestore.ProviderPostgres // default
+	if val := os.Getenv("STATE_STORE"); val != "" {
+		stateStore = strings.ToLower(val)
+	}
+
+	stateTTL := statestore.DefaultTTL // default
+	if val := os.Getenv("STATE_TTL"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed > 0 {
+			stateTTL = parsed
+		}
+	}
+
+	rateLimits := ratelimit.DefaultConfig() // defaults
+	for class, env := range map[string]string{
+		ratelimit.ClassDefault: "RATE_LIMIT_DEFAULT",
+		ratelimit.ClassHeavy:   "RATE_LIMIT_HEAVY",
+		ratelimit.ClassUpload:  "RATE_LIMIT_UPLOAD",
+	} {
+		if val := os.Getenv(env); val != "" {
+			if parsed, err := ratelimit.ParseLimit(val); err == nil {
+				rateLimits.Limits[class] = parsed
+			}
+		}
+	}
+	if val := os.Getenv("RATE_LIMIT_BAN_AFTER"); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil && parsed >= 0 {
+			rateLimits.BanAfter = parsed
+		}
+	}
+	if val := os.Getenv("RATE_LIMIT_BAN_DURATION"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed > 0 {
+			rateLimits.BanDuration = parsed
+		}
+	}
+
 	cnfg := &Config{
 		TelegramToken:     os.Getenv("TELEGRAM_TOKEN"),
 		BotID:             os.Getenv("BOT_ID"),
 		LogLevel:          os.Getenv("LOG_LEVEL"),
 		IsDevMode:         os.Getenv("IS_DEV_MODE") == "true",
+		UpdateMode:        updateMode,
+		WebhookURL:        os.Getenv("WEBHOOK_URL"),
+		WebhookSecret:     os.Getenv("WEBHOOK_SECRET"),
 		DatabaseURL:       os.Getenv("DATABASE_URL"),
 		DBMaxOpenConns:    dbMaxOpenConns,
 		DBMaxIdleConns:    dbMaxIdleConns,
 		DBConnMaxLifetime: dbConnMaxLifetime,
+		SchedulerInterval: schedulerInterval,
+		EmbeddingProvider: embeddingProvider,
+		EmbeddingURL:      embeddingURL,
+		EmbeddingAPIKey:   os.Getenv("EMBEDDING_API_KEY"),
+		EmbeddingModel:    embeddingModel,
+		EmbeddingTimeout:  embeddingTimeout,
+		BlobStore:         blobStore,
+		BlobDir:           blobDir,
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3Region:          s3Region,
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		S3Timeout:         s3Timeout,
+		OCRProvider:       ocrProvider,
+		TesseractPath:     tesseractPath,
+		OCRLanguages:      ocrLanguages,
+		OCRTimeout:        ocrTimeout,
+		StateStore:        stateStore,
+		StateTTL:          stateTTL,
+
+		RateLimitDefault:     rateLimits.Limits[ratelimit.ClassDefault],
+		RateLimitHeavy:       rateLimits.Limits[ratelimit.ClassHeavy],
+		RateLimitUpload:      rateLimits.Limits[ratelimit.ClassUpload],
+		RateLimitBanAfter:    rateLimits.BanAfter,
+		RateLimitBanDuration: rateLimits.BanDuration,
 	}
 
 	if err := cnfg.IsValid(); err != nil {
@@ -78,5 +275,68 @@ func (cfg *Config) IsValid() error {
 	if cfg.DatabaseURL == "" {
 		return errors.New("DATABASE_URL is required")
 	}
+	switch cfg.UpdateMode {
+	case "", UpdateModePolling:
+	case UpdateModeWebhook:
+		if err := validateWebhook(cfg.WebhookURL, cfg.WebhookSecret); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("UPDATE_MODE must be %s or %s", UpdateModePolling, UpdateModeWebhook)
+	}
+	switch cfg.EmbeddingProvider {
+	case "", embedding.ProviderLocal, embedding.ProviderOpenAI:
+	default:
+		return fmt.Errorf("EMBEDDING_PROVIDER must be %s or %s", embedding.ProviderLocal, embedding.ProviderOpenAI)
+	}
+	switch cfg.BlobStore {
+	case "", blobstore.ProviderLocal:
+	case blobstore.ProviderS3:
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when BLOB_STORE is s3")
+		}
+	default:
+		return fmt.Errorf("BLOB_STORE must be %s or %s", blobstore.ProviderLocal, blobstore.ProviderS3)
+	}
+	switch cfg.OCRProvider {
+	case "", ocr.ProviderTesseract, ocr.ProviderNone:
+	default:
+		return fmt.Errorf("OCR_PROVIDER must be %s or %s", ocr.ProviderTesseract, ocr.ProviderNone)
+	}
+	switch cfg.StateStore {
+	case "", statestore.ProviderPostgres, statestore.ProviderMemory:
+	default:
+		return fmt.Errorf("STATE_STORE must be %s or %s", statestore.ProviderPostgres, statestore.ProviderMemory)
+	}
+	return nil
+}
+
+// WebhookPath returns the path of WebhookURL, which the webhook is served at
+func (cfg *Config) WebhookPath() string {
+	u, err := url.Parse(cfg.WebhookURL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// validateWebhook checks the settings webhook mode needs. Telegram only posts to HTTPS URLs, and
+// accepts secret tokens of 1-256 letters, digits, underscores and hyphens.
+func validateWebhook(webhookURL, secret string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("WEBHOOK_URL must be an https URL when UPDATE_MODE is webhook")
+	}
+	if u.Path == "" || u.Path == "/" || u.Path == "/health" || u.Path == "/metrics" {
+		return errors.New("WEBHOOK_URL needs a path of its own, e.g. https://bot.example.com/telegram")
+	}
+	if secret == "" || len(secret) > 256 {
+		return errors.New("WEBHOOK_SECRET of 1-256 characters is required when UPDATE_MODE is webhook")
+	}
+	for _, r := range secret {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return errors.New("WEBHOOK_SECRET may only contain letters, digits, _ and -")
+		}
+	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -85,6 +86,9 @@ func TestLoad_DefaultValues(t *testing.T) {
 	if config.DBConnMaxLifetime != 5*time.Minute {
 		t.Errorf("DBConnMaxLifetime = %v, want %v", config.DBConnMaxLifetime, 5*time.Minute)
 	}
+	if config.SchedulerInterval != time.Minute {
+		t.Errorf("SchedulerInterval = %v, want %v", config.SchedulerInterval, time.Minute)
+	}
 }
 
 func TestLoad_InvalidDBMaxOpenConns(t *testing.T) {
@@ -138,6 +142,258 @@ func TestLoad_InvalidDBConnMaxLifetime(t *testing.T) {
 	}
 }
 
+func TestLoad_SchedulerInterval(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"custom interval", "30s", 30 * time.Second},
+		{"invalid interval", "soon", time.Minute},
+		{"non-positive interval", "0s", time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+			t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+			t.Setenv("SCHEDULER_INTERVAL", tt.value)
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v, want no error", err)
+			}
+
+			if config.SchedulerInterval != tt.want {
+				t.Errorf("SchedulerInterval = %v, want %v", config.SchedulerInterval, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_Embedding(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.EmbeddingProvider != "local" {
+		t.Errorf("EmbeddingProvider = %v, want %v", config.EmbeddingProvider, "local")
+	}
+	if config.EmbeddingModel != "text-embedding-3-small" {
+		t.Errorf("EmbeddingModel = %v, want %v", config.EmbeddingModel, "text-embedding-3-small")
+	}
+	if config.EmbeddingTimeout != 30*time.Second {
+		t.Errorf("EmbeddingTimeout = %v, want %v", config.EmbeddingTimeout, 30*time.Second)
+	}
+
+	t.Setenv("EMBEDDING_PROVIDER", "OpenAI")
+	t.Setenv("EMBEDDING_URL", "http://localhost:11434/v1/embeddings")
+	t.Setenv("EMBEDDING_API_KEY", "sk-test")
+	t.Setenv("EMBEDDING_MODEL", "nomic-embed-text")
+	t.Setenv("EMBEDDING_TIMEOUT", "5s")
+
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.EmbeddingProvider != "openai" {
+		t.Errorf("EmbeddingProvider = %v, want %v", config.EmbeddingProvider, "openai")
+	}
+	if config.EmbeddingURL != "http://localhost:11434/v1/embeddings" {
+		t.Errorf("EmbeddingURL = %v, want %v", config.EmbeddingURL, "http://localhost:11434/v1/embeddings")
+	}
+	if config.EmbeddingAPIKey != "sk-test" {
+		t.Errorf("EmbeddingAPIKey = %v, want %v", config.EmbeddingAPIKey, "sk-test")
+	}
+	if config.EmbeddingModel != "nomic-embed-text" {
+		t.Errorf("EmbeddingModel = %v, want %v", config.EmbeddingModel, "nomic-embed-text")
+	}
+	if config.EmbeddingTimeout != 5*time.Second {
+		t.Errorf("EmbeddingTimeout = %v, want %v", config.EmbeddingTimeout, 5*time.Second)
+	}
+
+	t.Setenv("EMBEDDING_PROVIDER", "cohere")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for unknown embedding provider")
+	}
+}
+
+func TestLoad_BlobStore(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.BlobStore != "local" {
+		t.Errorf("BlobStore = %v, want %v", config.BlobStore, "local")
+	}
+	if config.BlobDir != "data/blobs" {
+		t.Errorf("BlobDir = %v, want %v", config.BlobDir, "data/blobs")
+	}
+
+	t.Setenv("BLOB_STORE", "S3")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for s3 without a bucket and credentials")
+	}
+
+	t.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	t.Setenv("S3_BUCKET", "receipts")
+	t.Setenv("S3_ACCESS_KEY_ID", "minio")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "minio123")
+	t.Setenv("S3_TIMEOUT", "10s")
+
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.BlobStore != "s3" {
+		t.Errorf("BlobStore = %v, want %v", config.BlobStore, "s3")
+	}
+	if config.S3Bucket != "receipts" {
+		t.Errorf("S3Bucket = %v, want %v", config.S3Bucket, "receipts")
+	}
+	if config.S3Region != "us-east-1" {
+		t.Errorf("S3Region = %v, want %v", config.S3Region, "us-east-1")
+	}
+	if config.S3Timeout != 10*time.Second {
+		t.Errorf("S3Timeout = %v, want %v", config.S3Timeout, 10*time.Second)
+	}
+
+	t.Setenv("BLOB_STORE", "gcs")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for unknown blob store")
+	}
+}
+
+func TestLoad_OCR(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.OCRProvider != "tesseract" {
+		t.Errorf("OCRProvider = %v, want %v", config.OCRProvider, "tesseract")
+	}
+	if config.TesseractPath != "tesseract" {
+		t.Errorf("TesseractPath = %v, want %v", config.TesseractPath, "tesseract")
+	}
+	if config.OCRLanguages != "eng" {
+		t.Errorf("OCRLanguages = %v, want %v", config.OCRLanguages, "eng")
+	}
+	if config.OCRTimeout != 30*time.Second {
+		t.Errorf("OCRTimeout = %v, want %v", config.OCRTimeout, 30*time.Second)
+	}
+
+	t.Setenv("OCR_PROVIDER", "None")
+	t.Setenv("OCR_LANGUAGES", "eng+hin")
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.OCRProvider != "none" {
+		t.Errorf("OCRProvider = %v, want %v", config.OCRProvider, "none")
+	}
+	if config.OCRLanguages != "eng+hin" {
+		t.Errorf("OCRLanguages = %v, want %v", config.OCRLanguages, "eng+hin")
+	}
+
+	t.Setenv("OCR_PROVIDER", "cloud")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for unknown OCR provider")
+	}
+}
+
+func TestLoad_StateStore(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.StateStore != "postgres" {
+		t.Errorf("StateStore = %v, want %v", config.StateStore, "postgres")
+	}
+	if config.StateTTL != 30*time.Minute {
+		t.Errorf("StateTTL = %v, want %v", config.StateTTL, 30*time.Minute)
+	}
+
+	t.Setenv("STATE_STORE", "Memory")
+	t.Setenv("STATE_TTL", "1h")
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.StateStore != "memory" {
+		t.Errorf("StateStore = %v, want %v", config.StateStore, "memory")
+	}
+	if config.StateTTL != time.Hour {
+		t.Errorf("StateTTL = %v, want %v", config.StateTTL, time.Hour)
+	}
+
+	t.Setenv("STATE_STORE", "redis")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for unknown state store")
+	}
+}
+
+func TestLoad_Webhook(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.UpdateMode != UpdateModePolling {
+		t.Errorf("UpdateMode = %v, want %v", config.UpdateMode, UpdateModePolling)
+	}
+
+	t.Setenv("UPDATE_MODE", "Webhook")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for webhook mode without a URL and secret")
+	}
+
+	t.Setenv("WEBHOOK_URL", "https://bot.example.com/telegram/updates")
+	t.Setenv("WEBHOOK_SECRET", "s3cret-token_1")
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if config.UpdateMode != UpdateModeWebhook {
+		t.Errorf("UpdateMode = %v, want %v", config.UpdateMode, UpdateModeWebhook)
+	}
+	if config.WebhookPath() != "/telegram/updates" {
+		t.Errorf("WebhookPath() = %v, want %v", config.WebhookPath(), "/telegram/updates")
+	}
+
+	for _, tt := range []struct{ url, secret string }{
+		{"http://bot.example.com/telegram", "s3cret"},
+		{"https://bot.example.com", "s3cret"},
+		{"https://bot.example.com/health", "s3cret"},
+		{"https://bot.example.com/telegram", "not secret!"},
+		{"https://bot.example.com/telegram", strings.Repeat("a", 257)},
+	} {
+		t.Setenv("WEBHOOK_URL", tt.url)
+		t.Setenv("WEBHOOK_SECRET", tt.secret)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() error = nil, want error for WEBHOOK_URL %q and WEBHOOK_SECRET %q", tt.url, tt.secret)
+		}
+	}
+
+	t.Setenv("UPDATE_MODE", "push")
+	if _, err := Load(); err == nil {
+		t.Error("Load() error = nil, want error for unknown update mode")
+	}
+}
+
 func TestLoad_MissingTelegramToken(t *testing.T) {
 	// Set only DATABASE_URL, missing TELEGRAM_TOKEN
 	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
@@ -305,3 +561,50 @@ func TestLoad_IsDevModeVariations(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_RateLimits(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "test_token_123")
+	t.Setenv("DATABASE_URL", "postgres://localhost:5432/testdb")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if got := config.RateLimitDefault.String(); got != "20/10s" {
+		t.Errorf("RateLimitDefault = %v, want %v", got, "20/10s")
+	}
+	if got := config.RateLimitHeavy.String(); got != "3/1m0s" {
+		t.Errorf("RateLimitHeavy = %v, want %v", got, "3/1m0s")
+	}
+	if config.RateLimitBanAfter != 10 {
+		t.Errorf("RateLimitBanAfter = %v, want %v", config.RateLimitBanAfter, 10)
+	}
+	if config.RateLimitBanDuration != 10*time.Minute {
+		t.Errorf("RateLimitBanDuration = %v, want %v", config.RateLimitBanDuration, 10*time.Minute)
+	}
+
+	t.Setenv("RATE_LIMIT_DEFAULT", "5/s")
+	t.Setenv("RATE_LIMIT_HEAVY", "not a limit")
+	t.Setenv("RATE_LIMIT_UPLOAD", "4/1m")
+	t.Setenv("RATE_LIMIT_BAN_AFTER", "0")
+	t.Setenv("RATE_LIMIT_BAN_DURATION", "1h")
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want no error", err)
+	}
+	if got := config.RateLimitDefault.String(); got != "5/1s" {
+		t.Errorf("RateLimitDefault = %v, want %v", got, "5/1s")
+	}
+	if got := config.RateLimitHeavy.String(); got != "3/1m0s" {
+		t.Errorf("RateLimitHeavy = %v, want the default for an invalid limit", got)
+	}
+	if got := config.RateLimitUpload.String(); got != "4/1m0s" {
+		t.Errorf("RateLimitUpload = %v, want %v", got, "4/1m0s")
+	}
+	if config.RateLimitBanAfter != 0 {
+		t.Errorf("RateLimitBanAfter = %v, want %v", config.RateLimitBanAfter, 0)
+	}
+	if config.RateLimitBanDuration != time.Hour {
+		t.Errorf("RateLimitBanDuration = %v, want %v", config.RateLimitBanDuration, time.Hour)
+	}
+}
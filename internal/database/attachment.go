@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/lib/pq"
+)
+
+// AttachmentStorage defines operations for receipt attachments of expenses
+type AttachmentStorage interface {
+	CreateAttachment(ctx context.Context, attachment *models.Attachment) error
+	GetAttachmentByID(ctx context.Context, id int64) (*models.Attachment, error)
+	GetAttachmentsByExpenseIDs(ctx context.Context, expenseIDs []int64) ([]*models.Attachment, error)
+}
+
+// CreateAttachment records a file attached to an expense
+func (c *Client) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	query := `
+		INSERT INTO expense_attachments (expense_id, user_id, storage_key, file_name, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		attachment.ExpenseID, attachment.UserID, attachment.StorageKey,
+		attachment.FileName, attachment.ContentType, attachment.SizeBytes).
+		Scan(&attachment.ID, &attachment.CreatedAt)
+}
+
+// GetAttachmentByID retrieves an attachment by ID
+func (c *Client) GetAttachmentByID(ctx context.Context, id int64) (*models.Attachment, error) {
+	var attachment models.Attachment
+	query := `SELECT * FROM expense_attachments WHERE id = $1`
+
+	if err := c.db.GetContext(ctx, &attachment, query, id); err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// GetAttachmentsByExpenseIDs retrieves the attachments of several expenses, oldest first
+func (c *Client) GetAttachmentsByExpenseIDs(ctx context.Context, expenseIDs []int64) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	if len(expenseIDs) == 0 {
+		return attachments, nil
+	}
+	query := `SELECT * FROM expense_attachments WHERE expense_id = ANY($1) ORDER BY expense_id, created_at, id`
+
+	if err := c.db.SelectContext(ctx, &attachments, query, pq.Array(expenseIDs)); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// BudgetStorage defines operations for budget management
+type BudgetStorage interface {
+	CreateBudget(ctx context.Context, budget *models.Budget) error
+	GetActiveBudgets(ctx context.Context, userID int64) ([]*models.Budget, error)
+	GetBudgetsByUserID(ctx context.Context, userID int64) ([]*models.Budget, error)
+	GetBudgetByID(ctx context.Context, id int64) (*models.Budget, error)
+	SetBudgetLimit(ctx context.Context, limit *models.BudgetLimit) error
+	DeactivateBudget(ctx context.Context, id, userID int64) error
+}
+
+// CreateBudget creates a new active budget, closing any active budget for the same period
+func (c *Client) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	closeQuery := `
+		UPDATE budgets
+		SET is_active = false, end_date = $3, updated_at = now()
+		WHERE user_id = $1 AND period = $2 AND is_active`
+
+	if _, err := tx.ExecContext(ctx, closeQuery, budget.UserID, budget.Period, budget.StartDate); err != nil {
+		return fmt.Errorf("failed to close previous budget: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO budgets (user_id, category_id, amount, period, start_date, is_active)
+		VALUES ($1, $2, $3, $4, $5, true)
+		RETURNING id, is_active, created_at, updated_at`
+
+	if err := tx.QueryRowxContext(ctx, insertQuery,
+		budget.UserID, budget.CategoryID, budget.Amount, budget.Period, budget.StartDate).
+		StructScan(budget); err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetActiveBudgets retrieves the active budgets for a user with their category limits
+func (c *Client) GetActiveBudgets(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	var budgets []*models.Budget
+	query := `
+		SELECT * FROM budgets
+		WHERE user_id = $1 AND is_active
+		ORDER BY CASE period
+			WHEN 'daily' THEN 1 WHEN 'weekly' THEN 2 WHEN 'monthly' THEN 3 ELSE 4 END`
+
+	if err := c.db.SelectContext(ctx, &budgets, query, userID); err != nil {
+		return nil, err
+	}
+
+	if err := c.loadBudgetLimits(ctx, budgets); err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// GetBudgetsByUserID retrieves all budgets for a user, including inactive ones
+func (c *Client) GetBudgetsByUserID(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	var budgets []*models.Budget
+	query := `
+		SELECT * FROM budgets
+		WHERE user_id = $1
+		ORDER BY start_date DESC, id DESC`
+
+	if err := c.db.SelectContext(ctx, &budgets, query, userID); err != nil {
+		return nil, err
+	}
+
+	if err := c.loadBudgetLimits(ctx, budgets); err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// GetBudgetByID retrieves a budget by ID with its category limits
+func (c *Client) GetBudgetByID(ctx context.Context, id int64) (*models.Budget, error) {
+	var budget models.Budget
+	query := `SELECT * FROM budgets WHERE id = $1`
+
+	err := c.db.GetContext(ctx, &budget, query, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	if err := c.loadBudgetLimits(ctx, []*models.Budget{&budget}); err != nil {
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+// SetBudgetLimit creates or updates the limit for a category within a budget
+func (c *Client) SetBudgetLimit(ctx context.Context, limit *models.BudgetLimit) error {
+	query := `
+		INSERT INTO budget_limits (budget_id, category_id, limit_amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (budget_id, category_id) DO UPDATE SET
+			limit_amount = EXCLUDED.limit_amount,
+			updated_at = now()
+		RETURNING id, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query, limit.BudgetID, limit.CategoryID, limit.LimitAmount).
+		StructScan(limit)
+}
+
+// DeactivateBudget marks a budget as inactive and closes it today
+func (c *Client) DeactivateBudget(ctx context.Context, id, userID int64) error {
+	query := `
+		UPDATE budgets
+		SET is_active = false, end_date = CURRENT_DATE, updated_at = now()
+		WHERE id = $1 AND user_id = $2 AND is_active`
+
+	result, err := c.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// loadBudgetLimits fills the Limits field of each budget
+func (c *Client) loadBudgetLimits(ctx context.Context, budgets []*models.Budget) error {
+	query := `
+		SELECT bl.*, c.name as category_name, c.emoji as category_emoji
+		FROM budget_limits bl
+		JOIN categories c ON bl.category_id = c.id
+		WHERE bl.budget_id = $1
+		ORDER BY c.name`
+
+	for _, budget := range budgets {
+		var limits []*models.BudgetLimit
+		if err := c.db.SelectContext(ctx, &limits, query, budget.ID); err != nil {
+			return fmt.Errorf("failed to load budget limits: %w", err)
+		}
+		budget.Limits = limits
+	}
+
+	return nil
+}
@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/lib/pq"
 )
 
 // CategoryStorage defines operations for category management
@@ -11,12 +13,19 @@ type CategoryStorage interface {
 	GetAllCategories(ctx context.Context) ([]*models.Category, error)
 	GetCategoriesByGroup(ctx context.Context, group string) ([]*models.Category, error)
 	GetCategoryByName(ctx context.Context, name string) (*models.Category, error)
+	GetCategoriesByUserID(ctx context.Context, userID int64) ([]*models.Category, error)
+	CreateCategory(ctx context.Context, category *models.Category) error
+	UpdateCategory(ctx context.Context, category *models.Category) error
+	MergeCategory(ctx context.Context, userID, fromID, toID int64) ([]int64, error)
+	GetCategoryGroups(ctx context.Context, userID int64) ([]*models.CategoryGroup, error)
+	CreateCategoryGroup(ctx context.Context, group *models.CategoryGroup) error
+	RenameCategoryGroup(ctx context.Context, group *models.CategoryGroup, oldName string) error
 }
 
-// GetAllCategories retrieves all categories
+// GetAllCategories retrieves all built-in categories
 func (c *Client) GetAllCategories(ctx context.Context) ([]*models.Category, error) {
 	var categories []*models.Category
-	query := `SELECT * FROM categories ORDER BY "group", name`
+	query := `SELECT * FROM categories WHERE user_id IS NULL ORDER BY "group", name`
 
 	err := c.db.SelectContext(ctx, &categories, query)
 	if err != nil {
@@ -26,10 +35,10 @@ func (c *Client) GetAllCategories(ctx context.Context) ([]*models.Category, erro
 	return categories, nil
 }
 
-// GetCategoriesByGroup retrieves categories by group
+// GetCategoriesByGroup retrieves built-in categories by group
 func (c *Client) GetCategoriesByGroup(ctx context.Context, group string) ([]*models.Category, error) {
 	var categories []*models.Category
-	query := `SELECT * FROM categories WHERE "group" = $1 ORDER BY name`
+	query := `SELECT * FROM categories WHERE user_id IS NULL AND "group" = $1 ORDER BY name`
 
 	err := c.db.SelectContext(ctx, &categories, query, group)
 	if err != nil {
@@ -39,10 +48,10 @@ func (c *Client) GetCategoriesByGroup(ctx context.Context, group string) ([]*mod
 	return categories, nil
 }
 
-// GetCategoryByName retrieves a category by name
+// GetCategoryByName retrieves a built-in category by name
 func (c *Client) GetCategoryByName(ctx context.Context, name string) (*models.Category, error) {
 	var category models.Category
-	query := `SELECT * FROM categories WHERE name = $1`
+	query := `SELECT * FROM categories WHERE user_id IS NULL AND name = $1`
 
 	err := c.db.GetContext(ctx, &category, query, name)
 	if err != nil {
@@ -54,3 +63,211 @@ func (c *Client) GetCategoryByName(ctx context.Context, name string) (*models.Ca
 
 	return &category, nil
 }
+
+// GetCategoriesByUserID retrieves a user's custom categories, including archived ones
+func (c *Client) GetCategoriesByUserID(ctx context.Context, userID int64) ([]*models.Category, error) {
+	var categories []*models.Category
+	query := `SELECT * FROM categories WHERE user_id = $1 ORDER BY "group", name`
+
+	if err := c.db.SelectContext(ctx, &categories, query, userID); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// CreateCategory creates a custom category
+func (c *Client) CreateCategory(ctx context.Context, category *models.Category) error {
+	query := `
+		INSERT INTO categories (user_id, name, emoji, "group", archived)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		category.UserID, category.Name, category.Emoji, category.Group, category.Archived).
+		Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+}
+
+// UpdateCategory updates the name, emoji, group and archived flag of a user's custom category
+func (c *Client) UpdateCategory(ctx context.Context, category *models.Category) error {
+	query := `
+		UPDATE categories
+		SET name = $3, emoji = $4, "group" = $5, archived = $6, updated_at = now()
+		WHERE id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query,
+		category.ID, category.UserID, category.Name, category.Emoji, category.Group, category.Archived)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// MergeCategory moves a user's expenses, recurring expenses and budget limits from one category
+// to another in a single transaction and returns the IDs of the moved expenses. Each moved
+// expense is recorded in its history as an update by the user. A limit on both
+// categories in the same budget is combined into one. The source category is deleted when it is
+// the user's own; a built-in category is left for other users.
+func (c *Client) MergeCategory(ctx context.Context, userID, fromID, toID int64) ([]int64, error) {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	combineLimitsQuery := `
+		UPDATE budget_limits t
+		SET limit_amount = t.limit_amount + f.limit_amount, updated_at = now()
+		FROM budget_limits f, budgets b
+		WHERE f.budget_id = t.budget_id AND b.id = t.budget_id AND b.user_id = $1
+			AND f.category_id = $2 AND t.category_id = $3`
+	if _, err := tx.ExecContext(ctx, combineLimitsQuery, userID, fromID, toID); err != nil {
+		return nil, fmt.Errorf("failed to combine budget limits: %w", err)
+	}
+
+	deleteLimitsQuery := `
+		DELETE FROM budget_limits f
+		USING budgets b
+		WHERE b.id = f.budget_id AND b.user_id = $1 AND f.category_id = $2
+			AND EXISTS (SELECT 1 FROM budget_limits t WHERE t.budget_id = f.budget_id AND t.category_id = $3)`
+	if _, err := tx.ExecContext(ctx, deleteLimitsQuery, userID, fromID, toID); err != nil {
+		return nil, fmt.Errorf("failed to delete combined budget limits: %w", err)
+	}
+
+	moveLimitsQuery := `
+		UPDATE budget_limits f
+		SET category_id = $3, updated_at = now()
+		FROM budgets b
+		WHERE b.id = f.budget_id AND b.user_id = $1 AND f.category_id = $2`
+	if _, err := tx.ExecContext(ctx, moveLimitsQuery, userID, fromID, toID); err != nil {
+		return nil, fmt.Errorf("failed to move budget limits: %w", err)
+	}
+
+	moveRecurringQuery := `UPDATE recurring_expenses SET category_id = $3, updated_at = now() WHERE user_id = $1 AND category_id = $2`
+	if _, err := tx.ExecContext(ctx, moveRecurringQuery, userID, fromID, toID); err != nil {
+		return nil, fmt.Errorf("failed to move recurring expenses: %w", err)
+	}
+
+	// Deleted expenses move too, so the source category can be removed
+	var expenses []*models.Expense
+	getExpensesQuery := `
+		SELECT e.*, c.name as category_name, c.emoji as category_emoji, c."group" as category_group
+		FROM expenses e
+		JOIN categories c ON e.category_id = c.id
+		WHERE e.user_id = $1 AND e.category_id = $2
+		ORDER BY e.id
+		FOR UPDATE OF e`
+	if err := tx.SelectContext(ctx, &expenses, getExpensesQuery, userID, fromID); err != nil {
+		return nil, fmt.Errorf("failed to get expenses to move: %w", err)
+	}
+
+	var target models.Category
+	if err := tx.GetContext(ctx, &target, `SELECT * FROM categories WHERE id = $1`, toID); err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	expenseIDs := make([]int64, len(expenses))
+	history := make([]*models.ExpenseHistory, len(expenses))
+	for i, expense := range expenses {
+		expenseIDs[i] = expense.ID
+		history[i] = newMergeHistory(userID, expense, &target)
+	}
+
+	moveExpensesQuery := `UPDATE expenses SET category_id = $2, updated_at = now() WHERE id = ANY($1)`
+	if _, err := tx.ExecContext(ctx, moveExpensesQuery, pq.Array(expenseIDs), toID); err != nil {
+		return nil, fmt.Errorf("failed to move expenses: %w", err)
+	}
+	if err := createExpenseHistory(ctx, tx, history); err != nil {
+		return nil, fmt.Errorf("failed to record expense history: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1 AND user_id = $2`, fromID, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return expenseIDs, nil
+}
+
+// newMergeHistory records an expense moving to the target category of a merge, made by userID
+func newMergeHistory(userID int64, expense *models.Expense, target *models.Category) *models.ExpenseHistory {
+	before := models.NewExpenseSnapshot(expense)
+	after := *before
+	after.CategoryID, after.CategoryName = target.ID, target.Name
+	return &models.ExpenseHistory{
+		ExpenseID: expense.ID,
+		UserID:    userID,
+		Action:    models.HistoryActionUpdate,
+		Before:    before,
+		After:     &after,
+	}
+}
+
+// GetCategoryGroups retrieves the built-in groups followed by the user's own, each in position order
+func (c *Client) GetCategoryGroups(ctx context.Context, userID int64) ([]*models.CategoryGroup, error) {
+	var groups []*models.CategoryGroup
+	query := `
+		SELECT * FROM category_groups
+		WHERE user_id IS NULL OR user_id = $1
+		ORDER BY user_id NULLS FIRST, position, id`
+
+	if err := c.db.SelectContext(ctx, &groups, query, userID); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// CreateCategoryGroup creates a custom group after the user's existing groups
+func (c *Client) CreateCategoryGroup(ctx context.Context, group *models.CategoryGroup) error {
+	query := `
+		INSERT INTO category_groups (user_id, name, emoji, position)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), 0) + 1 FROM category_groups WHERE user_id = $1))
+		RETURNING id, position, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query, group.UserID, group.Name, group.Emoji).
+		Scan(&group.ID, &group.Position, &group.CreatedAt, &group.UpdatedAt)
+}
+
+// RenameCategoryGroup updates the name and emoji of a user's custom group and moves the user's
+// categories in it to the new name, in a single transaction
+func (c *Client) RenameCategoryGroup(ctx context.Context, group *models.CategoryGroup, oldName string) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `UPDATE category_groups SET name = $3, emoji = $4, updated_at = now() WHERE id = $1 AND user_id = $2`
+	result, err := tx.ExecContext(ctx, query, group.ID, group.UserID, group.Name, group.Emoji)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	categoriesQuery := `UPDATE categories SET "group" = $3, updated_at = now() WHERE user_id = $1 AND "group" = $2`
+	if _, err := tx.ExecContext(ctx, categoriesQuery, group.UserID, oldName, group.Name); err != nil {
+		return fmt.Errorf("failed to move categories: %w", err)
+	}
+
+	return tx.Commit()
+}
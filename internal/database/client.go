@@ -3,12 +3,13 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // contextKey is a custom type for context keys in this package
@@ -30,6 +31,19 @@ type Storage interface {
 	CategoryStorage
 	ExpenseStorage
 	VectorSearchStorage
+	BudgetStorage
+	RecurringExpenseStorage
+	ReminderStorage
+	UserSettingsStorage
+	ExchangeRateStorage
+	ImportProfileStorage
+	EmbeddingQueueStorage
+	VehicleStorage
+	LedgerStorage
+	SplitStorage
+	AttachmentStorage
+	ExpenseHistoryStorage
+	UserStateStorage
 
 	// Connection management
 	Close() error
@@ -41,11 +55,12 @@ func NewClient(ctx context.Context, databaseURL string, logger logger.Logger) (S
 	// Create context with request ID
 	reqCtx := context.WithValue(ctx, contextKey("request_id"), "db_init")
 
-	// Connect to database
-	db, err := sqlx.Connect("postgres", databaseURL)
+	// Connect to database, timing every query
+	connector, err := pq.NewConnector(databaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("invalid database URL: %w", err)
 	}
+	db := sqlx.NewDb(sql.OpenDB(instrumentedConnector{Connector: connector}), "postgres")
 
 	// Configure connection pool
 	db.SetMaxOpenConns(25)
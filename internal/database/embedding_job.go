@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/lib/pq"
+)
+
+// EmbeddingQueueStorage defines operations for the background embedding queue and the
+// embedding cache
+type EmbeddingQueueStorage interface {
+	EnqueueEmbeddingJobs(ctx context.Context, expenseIDs []int64) error
+	EnqueueMissingEmbeddings(ctx context.Context, userID int64) (int, error)
+	ClaimEmbeddingJobs(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingJob, error)
+	CompleteEmbeddingJob(ctx context.Context, job *models.EmbeddingJob) error
+	RetryEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, runAt time.Time, lastError string) error
+	FailEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, lastError string) error
+	GetEmbeddingQueueStats(ctx context.Context) (*models.EmbeddingQueueStats, error)
+	GetCachedEmbedding(ctx context.Context, contentHash string) ([]float32, error)
+	SaveCachedEmbedding(ctx context.Context, contentHash string, embedding []float32) error
+}
+
+// EnqueueEmbeddingJobs queues expenses for embedding. Expenses already queued are reset so
+// they are embedded again with their current text, even if they had failed.
+func (c *Client) EnqueueEmbeddingJobs(ctx context.Context, expenseIDs []int64) error {
+	if len(expenseIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO embedding_jobs (expense_id)
+		SELECT unnest($1::integer[])
+		ON CONFLICT (expense_id) DO UPDATE
+		SET attempts = 0,
+		    run_at = now(),
+		    locked_until = NULL,
+		    last_error = NULL,
+		    failed_at = NULL,
+		    enqueued_at = clock_timestamp()`
+
+	_, err := c.db.ExecContext(ctx, query, pq.Array(expenseIDs))
+	return err
+}
+
+// EnqueueMissingEmbeddings queues every expense of a user that has no category embedding,
+// or has notes but no notes embedding. It returns the number of expenses queued.
+func (c *Client) EnqueueMissingEmbeddings(ctx context.Context, userID int64) (int, error) {
+	query := `
+		INSERT INTO embedding_jobs (expense_id)
+		SELECT id FROM expenses
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+			AND (category_embedding IS NULL OR (notes_embedding IS NULL AND COALESCE(notes, '') <> ''))
+		ON CONFLICT (expense_id) DO NOTHING`
+
+	result, err := c.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ClaimEmbeddingJobs locks up to limit due jobs for lease and counts the attempt. Jobs whose
+// lease ran out without being completed, for example after a crash, are claimed again.
+func (c *Client) ClaimEmbeddingJobs(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingJob, error) {
+	var jobs []*models.EmbeddingJob
+
+	query := `
+		UPDATE embedding_jobs j
+		SET attempts = j.attempts + 1,
+		    locked_until = now() + make_interval(secs => $2)
+		FROM (
+			SELECT expense_id FROM embedding_jobs
+			WHERE failed_at IS NULL
+				AND run_at <= now()
+				AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY run_at, expense_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE j.expense_id = due.expense_id
+		RETURNING j.expense_id, j.attempts, j.enqueued_at, COALESCE(j.last_error, '') AS last_error`
+
+	if err := c.db.SelectContext(ctx, &jobs, query, limit, lease.Seconds()); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// CompleteEmbeddingJob removes a finished job. A job queued again since it was claimed is
+// left for the next claim.
+func (c *Client) CompleteEmbeddingJob(ctx context.Context, job *models.EmbeddingJob) error {
+	query := `DELETE FROM embedding_jobs WHERE expense_id = $1 AND enqueued_at = $2`
+
+	_, err := c.db.ExecContext(ctx, query, job.ExpenseID, job.EnqueuedAt)
+	return err
+}
+
+// RetryEmbeddingJob releases a failed job to be claimed again at runAt
+func (c *Client) RetryEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, runAt time.Time, lastError string) error {
+	query := `
+		UPDATE embedding_jobs
+		SET run_at = $3, locked_until = NULL, last_error = $4
+		WHERE expense_id = $1 AND enqueued_at = $2`
+
+	_, err := c.db.ExecContext(ctx, query, job.ExpenseID, job.EnqueuedAt, runAt, lastError)
+	return err
+}
+
+// FailEmbeddingJob marks a job that ran out of attempts so it is no longer claimed
+func (c *Client) FailEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, lastError string) error {
+	query := `
+		UPDATE embedding_jobs
+		SET failed_at = now(), locked_until = NULL, last_error = $3
+		WHERE expense_id = $1 AND enqueued_at = $2`
+
+	_, err := c.db.ExecContext(ctx, query, job.ExpenseID, job.EnqueuedAt, lastError)
+	return err
+}
+
+// GetEmbeddingQueueStats counts pending and failed embedding jobs
+func (c *Client) GetEmbeddingQueueStats(ctx context.Context) (*models.EmbeddingQueueStats, error) {
+	var stats models.EmbeddingQueueStats
+
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE failed_at IS NULL) AS pending,
+			COUNT(*) FILTER (WHERE failed_at IS NOT NULL) AS failed
+		FROM embedding_jobs`
+
+	if err := c.db.GetContext(ctx, &stats, query); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetCachedEmbedding retrieves a cached embedding by content hash
+func (c *Client) GetCachedEmbedding(ctx context.Context, contentHash string) ([]float32, error) {
+	var embedding models.Float32Vector
+	query := `SELECT embedding FROM embedding_cache WHERE content_hash = $1`
+
+	if err := c.db.GetContext(ctx, &embedding, query, contentHash); err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return embedding, nil
+}
+
+// SaveCachedEmbedding stores an embedding by content hash. Concurrent workers embedding the
+// same text both succeed and the first vector is kept.
+func (c *Client) SaveCachedEmbedding(ctx context.Context, contentHash string, embedding []float32) error {
+	query := `
+		INSERT INTO embedding_cache (content_hash, embedding)
+		VALUES ($1, $2::vector)
+		ON CONFLICT (content_hash) DO NOTHING`
+
+	_, err := c.db.ExecContext(ctx, query, contentHash, formatVector(embedding))
+	return err
+}
+
+// formatVector converts a vector to the pgvector text format, e.g. [0.1,0.2]
+func formatVector(vector []float32) string {
+	var b strings.Builder
+	b.Grow(len(vector) * 10)
+	b.WriteByte('[')
+	for i, value := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(value), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// ExchangeRateStorage defines operations for currency exchange rates
+type ExchangeRateStorage interface {
+	UpsertExchangeRates(ctx context.Context, rates []*models.ExchangeRate) error
+	GetExchangeRate(ctx context.Context, base, quote string, on time.Time) (*models.ExchangeRate, error)
+	GetLatestExchangeRates(ctx context.Context, limit int) ([]*models.ExchangeRate, error)
+}
+
+// UpsertExchangeRates creates or replaces rates in a single transaction, so an import either fully applies or not at all
+func (c *Client) UpsertExchangeRates(ctx context.Context, rates []*models.ExchangeRate) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, rate_date, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (base_currency, quote_currency, rate_date) DO UPDATE
+		SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_at = now()
+		RETURNING id, created_at, updated_at`
+
+	for _, rate := range rates {
+		if err := tx.QueryRowxContext(ctx, query,
+			rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.RateDate, rate.Source).
+			Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetExchangeRate retrieves the latest base→quote rate dated on or before on
+func (c *Client) GetExchangeRate(ctx context.Context, base, quote string, on time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	query := `
+		SELECT * FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND rate_date <= $3::date
+		ORDER BY rate_date DESC
+		LIMIT 1`
+
+	err := c.db.GetContext(ctx, &rate, query, base, quote, on)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// GetLatestExchangeRates retrieves the most recent rate of each currency pair
+func (c *Client) GetLatestExchangeRates(ctx context.Context, limit int) ([]*models.ExchangeRate, error) {
+	var rates []*models.ExchangeRate
+	query := `
+		SELECT DISTINCT ON (base_currency, quote_currency) *
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency, rate_date DESC
+		LIMIT $1`
+
+	if err := c.db.SelectContext(ctx, &rates, query, limit); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
@@ -9,27 +10,88 @@ import (
 
 // ExpenseStorage defines operations for expense management
 type ExpenseStorage interface {
-	CreateExpense(ctx context.Context, expense *models.Expense) error
+	CreateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error
+	CreateExpenses(ctx context.Context, expenses []*models.Expense, history ...*models.ExpenseHistory) error
 	GetExpensesByUserID(ctx context.Context, userID int64) ([]*models.Expense, error)
 	GetExpensesByTelegramID(ctx context.Context, telegramID int64) ([]*models.Expense, error)
 	GetExpenseByID(ctx context.Context, id int64) (*models.Expense, error)
-	UpdateExpense(ctx context.Context, expense *models.Expense) error
-	DeleteExpense(ctx context.Context, id, userID int64) error
+	UpdateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error
+	DeleteExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error
 	GetExpenseStats(ctx context.Context, userID int64) (*models.ExpenseStats, error)
 	GetExpensesByDateRange(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*models.Expense, error)
+	StreamExpenses(ctx context.Context, userID int64, startDate, endDate time.Time, fn func(*models.Expense) error) error
+	GetDeletedExpenseByID(ctx context.Context, id int64) (*models.Expense, error)
+	GetDeletedExpenses(ctx context.Context, userID int64, limit int) ([]*models.Expense, error)
+	RestoreExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error
 }
 
-// CreateExpense creates a new expense
-func (c *Client) CreateExpense(ctx context.Context, expense *models.Expense) error {
+// CreateExpense creates a new expense and records it in the history, in a single transaction.
+// The history entries are given the new expense's ID.
+func (c *Client) CreateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
-		INSERT INTO expenses (user_id, category_id, vehicle_type, odometer, petrol_price, total_price, notes, timestamp)
-		VALUES ($1, $2, CASE WHEN $3 = '' THEN NULL ELSE $3 END, $4, $5, $6, $7, $8)
+		INSERT INTO expenses (user_id, category_id, vehicle_type, odometer, petrol_price, total_price, notes, timestamp, currency, original_amount, vehicle_id, ledger_id)
+		VALUES ($1, $2, CASE WHEN $3 = '' THEN NULL ELSE $3 END, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at`
 
-	return c.db.QueryRowxContext(ctx, query,
+	if err := tx.QueryRowxContext(ctx, query,
 		expense.UserID, expense.CategoryID, expense.VehicleType, expense.Odometer,
-		expense.PetrolPrice, expense.TotalPrice, expense.Notes, expense.Timestamp).
-		StructScan(expense)
+		expense.PetrolPrice, expense.TotalPrice, expense.Notes, expense.Timestamp,
+		expense.Currency, expense.OriginalAmount, expense.VehicleID, expense.LedgerID).
+		StructScan(expense); err != nil {
+		return err
+	}
+
+	for _, entry := range history {
+		entry.ExpenseID = expense.ID
+	}
+	if err := createExpenseHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateExpenses creates expenses and records them in the history in a single transaction, so
+// a bulk import either fully applies or not at all. Each history entry is given the ID of the
+// expense at the same position.
+func (c *Client) CreateExpenses(ctx context.Context, expenses []*models.Expense, history ...*models.ExpenseHistory) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO expenses (user_id, category_id, vehicle_type, odometer, petrol_price, total_price, notes, timestamp, currency, original_amount, vehicle_id, ledger_id)
+		VALUES ($1, $2, CASE WHEN $3 = '' THEN NULL ELSE $3 END, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at`
+
+	for _, expense := range expenses {
+		if err := tx.QueryRowxContext(ctx, query,
+			expense.UserID, expense.CategoryID, expense.VehicleType, expense.Odometer,
+			expense.PetrolPrice, expense.TotalPrice, expense.Notes, expense.Timestamp,
+			expense.Currency, expense.OriginalAmount, expense.VehicleID, expense.LedgerID).
+			Scan(&expense.ID, &expense.CreatedAt, &expense.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	for i, entry := range history {
+		if i < len(expenses) {
+			entry.ExpenseID = expenses[i].ID
+		}
+	}
+	if err := createExpenseHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetExpensesByUserID retrieves all expenses for a user
@@ -70,29 +132,107 @@ func (c *Client) GetExpenseByID(ctx context.Context, id int64) (*models.Expense,
 	return &expense, nil
 }
 
-// UpdateExpense updates an existing expense
-func (c *Client) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+// UpdateExpense updates an existing expense and records the change in the history, in a
+// single transaction
+func (c *Client) UpdateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		UPDATE expenses 
 		SET category_id = $1, vehicle_type = CASE WHEN $2 = '' THEN NULL ELSE $2 END, odometer = $3, petrol_price = $4, 
-		    total_price = $5, notes = $6, timestamp = $7, updated_at = now()
+		    total_price = $5, notes = $6, timestamp = $7, currency = $10, original_amount = $11, vehicle_id = $12, updated_at = now()
 		WHERE id = $8 AND user_id = $9 AND deleted_at IS NULL
 		RETURNING updated_at`
 
-	return c.db.QueryRowxContext(ctx, query,
+	if err := tx.QueryRowxContext(ctx, query,
 		expense.CategoryID, expense.VehicleType, expense.Odometer, expense.PetrolPrice,
-		expense.TotalPrice, expense.Notes, expense.Timestamp, expense.ID, expense.UserID).
-		Scan(&expense.UpdatedAt)
+		expense.TotalPrice, expense.Notes, expense.Timestamp, expense.ID, expense.UserID,
+		expense.Currency, expense.OriginalAmount, expense.VehicleID).
+		Scan(&expense.UpdatedAt); err != nil {
+		return err
+	}
+
+	if err := createExpenseHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// DeleteExpense soft deletes an expense
-func (c *Client) DeleteExpense(ctx context.Context, id, userID int64) error {
+// DeleteExpense soft deletes an expense and records the change in the history, in a single transaction
+func (c *Client) DeleteExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error {
 	query := `
 		UPDATE expenses 
 		SET deleted_at = now(), updated_at = now()
 		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
-	result, err := c.db.ExecContext(ctx, query, id, userID)
+	return c.execWithHistory(ctx, query, []any{id, userID}, history)
+}
+
+// GetDeletedExpenseByID retrieves a soft-deleted expense by ID
+func (c *Client) GetDeletedExpenseByID(ctx context.Context, id int64) (*models.Expense, error) {
+	var expense models.Expense
+	query := `
+		SELECT e.*, c.name as category_name, c.emoji as category_emoji, c."group" as category_group
+		FROM expenses e
+		JOIN categories c ON e.category_id = c.id
+		WHERE e.id = $1 AND e.deleted_at IS NOT NULL`
+
+	err := c.db.GetContext(ctx, &expense, query, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &expense, nil
+}
+
+// GetDeletedExpenses retrieves a user's soft-deleted expenses, most recently deleted first
+func (c *Client) GetDeletedExpenses(ctx context.Context, userID int64, limit int) ([]*models.Expense, error) {
+	var expenses []*models.Expense
+	query := `
+		SELECT e.*, c.name as category_name, c.emoji as category_emoji, c."group" as category_group
+		FROM expenses e
+		JOIN categories c ON e.category_id = c.id
+		WHERE e.user_id = $1 AND e.deleted_at IS NOT NULL
+		ORDER BY e.deleted_at DESC, e.id DESC
+		LIMIT $2`
+
+	err := c.db.SelectContext(ctx, &expenses, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+// RestoreExpense undoes the soft delete of an expense and records the change in the history,
+// in a single transaction
+func (c *Client) RestoreExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error {
+	query := `
+		UPDATE expenses 
+		SET deleted_at = NULL, updated_at = now()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+
+	return c.execWithHistory(ctx, query, []any{id, userID}, history)
+}
+
+// execWithHistory runs a statement that must change a row, failing with errNotFound when it
+// changes none, and records history in the same transaction
+func (c *Client) execWithHistory(ctx context.Context, query string, args []any, history []*models.ExpenseHistory) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -106,7 +246,11 @@ func (c *Client) DeleteExpense(ctx context.Context, id, userID int64) error {
 		return errNotFound
 	}
 
-	return nil
+	if err := createExpenseHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetExpenseStats retrieves expense statistics for a user
@@ -151,6 +295,46 @@ func (c *Client) GetExpensesByDateRange(ctx context.Context, userID int64, start
 	return expenses, nil
 }
 
+// StreamExpenses calls fn for each expense of a user from oldest to newest, one row at a time,
+// so long histories are never held in memory. Zero dates leave that end of the range open;
+// endDate is exclusive. Iteration stops at the first error returned by fn.
+func (c *Client) StreamExpenses(ctx context.Context, userID int64, startDate, endDate time.Time, fn func(*models.Expense) error) error {
+	query := `
+		SELECT e.*, c.name as category_name, c.emoji as category_emoji, c."group" as category_group
+		FROM expenses e
+		JOIN categories c ON e.category_id = c.id
+		WHERE e.user_id = $1 AND e.deleted_at IS NULL`
+	args := []any{userID}
+
+	if !startDate.IsZero() {
+		args = append(args, startDate)
+		query += fmt.Sprintf(" AND e.timestamp >= $%d", len(args))
+	}
+	if !endDate.IsZero() {
+		args = append(args, endDate)
+		query += fmt.Sprintf(" AND e.timestamp < $%d", len(args))
+	}
+	query += " ORDER BY e.timestamp, e.id"
+
+	rows, err := c.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var expense models.Expense
+		if err := rows.StructScan(&expense); err != nil {
+			return err
+		}
+		if err := fn(&expense); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // GetExpensesByTelegramID retrieves all expenses for a user by their Telegram ID
 func (c *Client) GetExpensesByTelegramID(ctx context.Context, telegramID int64) ([]*models.Expense, error) {
 	// First get the user by Telegram ID
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// ExpenseHistoryStorage defines operations for the audit history of expense changes. Changes
+// are recorded by the expense operations that make them, in the same transaction.
+type ExpenseHistoryStorage interface {
+	GetExpenseHistory(ctx context.Context, expenseID int64) ([]*models.ExpenseHistory, error)
+	GetLastUndoableChange(ctx context.Context, userID int64) (*models.ExpenseHistory, error)
+}
+
+// createExpenseHistory records changes to expenses within tx. An entry that undoes another
+// change marks it undone, failing with errNotFound if it already was, so a change cannot be
+// undone twice.
+func createExpenseHistory(ctx context.Context, tx *sqlx.Tx, entries []*models.ExpenseHistory) error {
+	query := `
+		INSERT INTO expense_history (expense_id, user_id, action, before_snapshot, after_snapshot, undo_of)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	for _, entry := range entries {
+		if entry.UndoOf.Valid {
+			result, err := tx.ExecContext(ctx,
+				`UPDATE expense_history SET undone_at = now() WHERE id = $1 AND undone_at IS NULL`, entry.UndoOf.Int64)
+			if err != nil {
+				return err
+			}
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if rowsAffected == 0 {
+				return errNotFound
+			}
+		}
+
+		if err := tx.QueryRowxContext(ctx, query,
+			entry.ExpenseID, entry.UserID, entry.Action, entry.Before, entry.After, entry.UndoOf).
+			Scan(&entry.ID, &entry.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetExpenseHistory retrieves the changes to an expense with the names of who made them, oldest first
+func (c *Client) GetExpenseHistory(ctx context.Context, expenseID int64) ([]*models.ExpenseHistory, error) {
+	var history []*models.ExpenseHistory
+	query := `
+		SELECT h.*, COALESCE(NULLIF(u.first_name, ''), u.username, '') AS actor_name
+		FROM expense_history h
+		JOIN users u ON h.user_id = u.id
+		WHERE h.expense_id = $1
+		ORDER BY h.created_at, h.id`
+
+	if err := c.db.SelectContext(ctx, &history, query, expenseID); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetLastUndoableChange retrieves the user's most recent change that /undo can revert: one not
+// already undone, not itself an undo, and not part of a statement import
+func (c *Client) GetLastUndoableChange(ctx context.Context, userID int64) (*models.ExpenseHistory, error) {
+	var entry models.ExpenseHistory
+	query := `
+		SELECT h.*, COALESCE(NULLIF(u.first_name, ''), u.username, '') AS actor_name
+		FROM expense_history h
+		JOIN users u ON h.user_id = u.id
+		WHERE h.user_id = $1 AND h.undone_at IS NULL AND h.undo_of IS NULL AND h.action <> 'IMPORT'
+		ORDER BY h.created_at DESC, h.id DESC
+		LIMIT 1`
+
+	if err := c.db.GetContext(ctx, &entry, query, userID); err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
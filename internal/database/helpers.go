@@ -12,3 +12,8 @@ var errNotFound = errors.New("not found")
 func isNoRows(err error) bool {
 	return errors.Is(err, sql.ErrNoRows)
 }
+
+// IsNotFound reports whether err means the requested row does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, errNotFound) || isNoRows(err)
+}
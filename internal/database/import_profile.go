@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// ImportProfileStorage defines operations for bank statement import profiles
+type ImportProfileStorage interface {
+	GetImportProfile(ctx context.Context, userID int64, name string) (*models.ImportProfile, error)
+	UpsertImportProfile(ctx context.Context, profile *models.ImportProfile) error
+}
+
+// GetImportProfile retrieves a user's import profile by name
+func (c *Client) GetImportProfile(ctx context.Context, userID int64, name string) (*models.ImportProfile, error) {
+	var profile models.ImportProfile
+	query := `SELECT * FROM import_profiles WHERE user_id = $1 AND name = $2`
+
+	err := c.db.GetContext(ctx, &profile, query, userID, name)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// UpsertImportProfile creates or replaces a user's import profile with the same name
+func (c *Client) UpsertImportProfile(ctx context.Context, profile *models.ImportProfile) error {
+	query := `
+		INSERT INTO import_profiles (user_id, name, date_column, amount_column, description_column, date_format)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, name) DO UPDATE
+		SET date_column = EXCLUDED.date_column,
+		    amount_column = EXCLUDED.amount_column,
+		    description_column = EXCLUDED.description_column,
+		    date_format = EXCLUDED.date_format,
+		    updated_at = now()
+		RETURNING id, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		profile.UserID, profile.Name, profile.DateColumn, profile.AmountColumn,
+		profile.DescriptionColumn, profile.DateFormat).
+		Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/metrics"
+)
+
+// queryDuration times the queries run on the database by the kind of statement
+var queryDuration = metrics.NewHistogram("expense_bot_db_query_duration_seconds",
+	"How long database queries took, by statement: select, insert, update, delete or other.",
+	nil, "operation")
+
+// instrumentedConnector opens connections that time every query run on them
+type instrumentedConnector struct {
+	driver.Connector
+}
+
+// Connect implements driver.Connector
+func (c instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn}, nil
+}
+
+// instrumentedConn times queries and passes everything else on to the driver's connection.
+// Queries within transactions run on the connection too, so they are timed as well.
+type instrumentedConn struct {
+	driver.Conn
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer observeQuery(query, time.Now())
+	return queryer.QueryContext(ctx, query, args)
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer observeQuery(query, time.Now())
+	return execer.ExecContext(ctx, query, args)
+}
+
+// PrepareContext implements driver.ConnPrepareContext
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx implements driver.ConnBeginTx
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// Ping implements driver.Pinger
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// observeQuery records how long a query started at started took
+func observeQuery(query string, started time.Time) {
+	queryDuration.Observe(time.Since(started).Seconds(), queryOperation(query))
+}
+
+// queryOperation returns the kind of statement a query is, going by its first keyword
+func queryOperation(query string) string {
+	keyword := strings.TrimSpace(query)
+	if end := strings.IndexFunc(keyword, unicode.IsSpace); end >= 0 {
+		keyword = keyword[:end]
+	}
+	keyword = strings.ToLower(keyword)
+	switch keyword {
+	case "select", "insert", "update", "delete":
+		return keyword
+	}
+	return "other"
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnector opens fakeConns
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+// fakeConn answers every query with no rows
+type fakeConn struct{}
+
+func (*fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (*fakeConn) Close() error                        { return nil }
+func (*fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (*fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+func (*fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return []string{"id"} }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+func TestInstrumentedConn(t *testing.T) {
+	ctx := context.Background()
+	db := sql.OpenDB(instrumentedConnector{Connector: fakeConnector{}})
+	defer db.Close()
+
+	selects, inserts, others := queryDuration.Count("select"), queryDuration.Count("insert"), queryDuration.Count("other")
+
+	rows, err := db.QueryContext(ctx, "\n\t\tSELECT id FROM expenses WHERE user_id = $1", 1)
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "insert into expenses (id) values ($1)", 1)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	_, err = db.ExecContext(ctx, "WITH moved AS (SELECT 1) DELETE FROM expenses")
+	require.NoError(t, err)
+
+	assert.Equal(t, selects+1, queryDuration.Count("select"))
+	assert.Equal(t, inserts+1, queryDuration.Count("insert"), "queries in transactions are timed")
+	assert.Equal(t, others+1, queryDuration.Count("other"))
+}
+
+func TestQueryOperation(t *testing.T) {
+	assert.Equal(t, "select", queryOperation("SELECT * FROM users"))
+	assert.Equal(t, "update", queryOperation("\n\tUPDATE users SET x = 1"))
+	assert.Equal(t, "delete", queryOperation("delete from users"))
+	assert.Equal(t, "other", queryOperation("WITH x AS (SELECT 1) SELECT * FROM x"))
+	assert.Equal(t, "other", queryOperation(""))
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// LedgerStorage defines operations for shared ledgers and their members
+type LedgerStorage interface {
+	CreateLedger(ctx context.Context, ledger *models.Ledger, ownerID int64) error
+	GetLedgersByUserID(ctx context.Context, userID int64) ([]*models.Ledger, error)
+	GetLedgerByInviteCode(ctx context.Context, inviteCode string) (*models.Ledger, error)
+	GetLedgerByChatID(ctx context.Context, chatID int64) (*models.Ledger, error)
+	LinkLedgerChat(ctx context.Context, ledgerID, chatID int64) error
+	UnlinkLedgerChat(ctx context.Context, chatID int64) error
+	DeleteLedger(ctx context.Context, id int64) error
+	GetLedgerMembers(ctx context.Context, ledgerID int64) ([]*models.LedgerMember, error)
+	GetLedgerMember(ctx context.Context, ledgerID, userID int64) (*models.LedgerMember, error)
+	AddLedgerMember(ctx context.Context, member *models.LedgerMember) error
+	UpdateLedgerMemberRole(ctx context.Context, ledgerID, userID int64, role models.LedgerRole) error
+	RemoveLedgerMember(ctx context.Context, ledgerID, userID int64) error
+	SetActiveLedger(ctx context.Context, userID, ledgerID int64) error
+	GetLedgerExpenses(ctx context.Context, ledgerID int64, startDate, endDate time.Time) ([]*models.Expense, error)
+}
+
+// CreateLedger creates a ledger with ownerID as its owner, in a single transaction
+func (c *Client) CreateLedger(ctx context.Context, ledger *models.Ledger, ownerID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO ledgers (name, invite_code, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at`
+	if err := tx.QueryRowxContext(ctx, query, ledger.Name, ledger.InviteCode, ownerID).
+		Scan(&ledger.ID, &ledger.CreatedAt, &ledger.UpdatedAt); err != nil {
+		return err
+	}
+
+	memberQuery := `INSERT INTO ledger_members (ledger_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, memberQuery, ledger.ID, ownerID, models.LedgerRoleOwner); err != nil {
+		return fmt.Errorf("failed to add ledger owner: %w", err)
+	}
+
+	ledger.CreatedBy.Int64, ledger.CreatedBy.Valid = ownerID, true
+	ledger.Role = models.LedgerRoleOwner
+	return tx.Commit()
+}
+
+// GetLedgersByUserID retrieves the ledgers a user is a member of, with their role, oldest first
+func (c *Client) GetLedgersByUserID(ctx context.Context, userID int64) ([]*models.Ledger, error) {
+	var ledgers []*models.Ledger
+	query := `
+		SELECT l.*, m.role, m.active
+		FROM ledgers l
+		JOIN ledger_members m ON m.ledger_id = l.id
+		WHERE m.user_id = $1
+		ORDER BY l.id`
+
+	if err := c.db.SelectContext(ctx, &ledgers, query, userID); err != nil {
+		return nil, err
+	}
+
+	return ledgers, nil
+}
+
+// GetLedgerByInviteCode retrieves a ledger by its invite code
+func (c *Client) GetLedgerByInviteCode(ctx context.Context, inviteCode string) (*models.Ledger, error) {
+	var ledger models.Ledger
+	query := `SELECT * FROM ledgers WHERE invite_code = $1`
+
+	err := c.db.GetContext(ctx, &ledger, query, inviteCode)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &ledger, nil
+}
+
+// GetLedgerByChatID retrieves the ledger linked to a group chat
+func (c *Client) GetLedgerByChatID(ctx context.Context, chatID int64) (*models.Ledger, error) {
+	var ledger models.Ledger
+	query := `SELECT * FROM ledgers WHERE chat_id = $1`
+
+	err := c.db.GetContext(ctx, &ledger, query, chatID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &ledger, nil
+}
+
+// LinkLedgerChat links a ledger to a group chat, replacing the chat's previous ledger, in a
+// single transaction
+func (c *Client) LinkLedgerChat(ctx context.Context, ledgerID, chatID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE ledgers SET chat_id = NULL WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("failed to unlink chat ledger: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE ledgers SET chat_id = $1 WHERE id = $2`, chatID, ledgerID)
+	if err != nil {
+		return fmt.Errorf("failed to link chat ledger: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return tx.Commit()
+}
+
+// UnlinkLedgerChat unlinks the ledger linked to a group chat
+func (c *Client) UnlinkLedgerChat(ctx context.Context, chatID int64) error {
+	result, err := c.db.ExecContext(ctx, `UPDATE ledgers SET chat_id = NULL WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// DeleteLedger deletes a ledger and its memberships. Its expenses become their payers' personal expenses.
+func (c *Client) DeleteLedger(ctx context.Context, id int64) error {
+	result, err := c.db.ExecContext(ctx, `DELETE FROM ledgers WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// GetLedgerMembers retrieves a ledger's members with their names, in the order they joined
+func (c *Client) GetLedgerMembers(ctx context.Context, ledgerID int64) ([]*models.LedgerMember, error) {
+	var members []*models.LedgerMember
+	query := `
+		SELECT m.*, COALESCE(u.username, '') AS username, COALESCE(u.first_name, '') AS first_name
+		FROM ledger_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.ledger_id = $1
+		ORDER BY m.joined_at, m.user_id`
+
+	if err := c.db.SelectContext(ctx, &members, query, ledgerID); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// GetLedgerMember retrieves a user's membership of a ledger
+func (c *Client) GetLedgerMember(ctx context.Context, ledgerID, userID int64) (*models.LedgerMember, error) {
+	var member models.LedgerMember
+	query := `
+		SELECT m.*, COALESCE(u.username, '') AS username, COALESCE(u.first_name, '') AS first_name
+		FROM ledger_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.ledger_id = $1 AND m.user_id = $2`
+
+	err := c.db.GetContext(ctx, &member, query, ledgerID, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// AddLedgerMember adds a user to a ledger
+func (c *Client) AddLedgerMember(ctx context.Context, member *models.LedgerMember) error {
+	query := `
+		INSERT INTO ledger_members (ledger_id, user_id, role)
+		VALUES ($1, $2, $3)
+		RETURNING joined_at`
+
+	return c.db.QueryRowxContext(ctx, query, member.LedgerID, member.UserID, member.Role).
+		Scan(&member.JoinedAt)
+}
+
+// UpdateLedgerMemberRole changes a member's role
+func (c *Client) UpdateLedgerMemberRole(ctx context.Context, ledgerID, userID int64, role models.LedgerRole) error {
+	query := `UPDATE ledger_members SET role = $3 WHERE ledger_id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, ledgerID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// RemoveLedgerMember removes a user from a ledger. Their expenses stay in the ledger.
+func (c *Client) RemoveLedgerMember(ctx context.Context, ledgerID, userID int64) error {
+	query := `DELETE FROM ledger_members WHERE ledger_id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, ledgerID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// SetActiveLedger makes ledgerID the ledger the user's new expenses go to; 0 makes them personal.
+// The old ledger is cleared first so the one-active-ledger index is never violated mid-update.
+func (c *Client) SetActiveLedger(ctx context.Context, userID, ledgerID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE ledger_members SET active = false WHERE user_id = $1 AND active`, userID); err != nil {
+		return fmt.Errorf("failed to clear active ledger: %w", err)
+	}
+
+	if ledgerID != 0 {
+		query := `UPDATE ledger_members SET active = true WHERE user_id = $1 AND ledger_id = $2`
+		if _, err := tx.ExecContext(ctx, query, userID, ledgerID); err != nil {
+			return fmt.Errorf("failed to set active ledger: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLedgerExpenses retrieves the expenses of all members in a ledger within a date range, newest first
+func (c *Client) GetLedgerExpenses(ctx context.Context, ledgerID int64, startDate, endDate time.Time) ([]*models.Expense, error) {
+	var expenses []*models.Expense
+	query := `
+		SELECT e.*, c.name as category_name, c.emoji as category_emoji, c."group" as category_group
+		FROM expenses e
+		JOIN categories c ON e.category_id = c.id
+		WHERE e.ledger_id = $1 AND e.timestamp >= $2 AND e.timestamp < $3 AND e.deleted_at IS NULL
+		ORDER BY e.timestamp DESC`
+
+	if err := c.db.SelectContext(ctx, &expenses, query, ledgerID, startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
@@ -4,6 +4,10 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,20 +17,69 @@ import (
 
 // MockStorage implements Storage interface for testing
 type MockStorage struct {
-	mu         sync.RWMutex
-	users      map[int64]*models.User
-	categories []*models.Category
-	expenses   map[int64]*models.Expense
-	nextID     int64
+	mu          sync.RWMutex
+	users       map[int64]*models.User
+	categories  []*models.Category
+	groups      []*models.CategoryGroup
+	expenses    map[int64]*models.Expense
+	budgets     map[int64]*models.Budget
+	recurring   map[int64]*models.RecurringExpense
+	reminders   map[int64]*models.Reminder
+	settings    map[int64]*models.UserSettings
+	rates       []*models.ExchangeRate
+	profiles    []*models.ImportProfile
+	jobs        map[int64]*mockEmbeddingJob
+	cache       map[string][]float32
+	vehicles    map[int64]*models.Vehicle
+	ledgers     map[int64]*models.Ledger
+	members     []*models.LedgerMember
+	splits      map[int64][]*models.ExpenseSplit
+	settlements []*models.Settlement
+	attachments map[int64]*models.Attachment
+	history     []*models.ExpenseHistory
+	userStates  map[mockUserStateKey]*mockUserState
+	nextID      int64
+}
+
+// mockEmbeddingJob is an embedding job with the scheduling columns of embedding_jobs
+type mockEmbeddingJob struct {
+	models.EmbeddingJob
+	runAt       time.Time
+	lockedUntil time.Time
+	failed      bool
+}
+
+// mockUserStateKey identifies a conversation with a user in a chat
+type mockUserStateKey struct {
+	chatID int64
+	userID int64
+}
+
+// mockUserState is a conversation serialized as JSON like the state column of user_states
+type mockUserState struct {
+	data      []byte
+	expiresAt time.Time
 }
 
 // NewMockStorage creates a new mock storage instance
 func NewMockStorage() Storage {
 	return &MockStorage{
-		users:      make(map[int64]*models.User),
-		categories: make([]*models.Category, 0),
-		expenses:   make(map[int64]*models.Expense),
-		nextID:     1,
+		users:       make(map[int64]*models.User),
+		categories:  make([]*models.Category, 0),
+		groups:      make([]*models.CategoryGroup, 0),
+		expenses:    make(map[int64]*models.Expense),
+		budgets:     make(map[int64]*models.Budget),
+		recurring:   make(map[int64]*models.RecurringExpense),
+		reminders:   make(map[int64]*models.Reminder),
+		settings:    make(map[int64]*models.UserSettings),
+		jobs:        make(map[int64]*mockEmbeddingJob),
+		cache:       make(map[string][]float32),
+		vehicles:    make(map[int64]*models.Vehicle),
+		ledgers:     make(map[int64]*models.Ledger),
+		splits:      make(map[int64][]*models.ExpenseSplit),
+		attachments: make(map[int64]*models.Attachment),
+		userStates:  make(map[mockUserStateKey]*mockUserState),
+		nextID:      1,
 	}
 }
 
@@ -80,55 +133,276 @@ func (m *MockStorage) GetUserByTelegramID(ctx context.Context, telegramID int64)
 
 // Category Operations
 
-// GetAllCategories retrieves all categories from mock storage
+// GetAllCategories retrieves all built-in categories from mock storage
 func (m *MockStorage) GetAllCategories(ctx context.Context) ([]*models.Category, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	result := make([]*models.Category, len(m.categories))
-	copy(result, m.categories)
+	result := make([]*models.Category, 0, len(m.categories))
+	for _, category := range m.categories {
+		if !category.UserID.Valid {
+			result = append(result, category)
+		}
+	}
 	return result, nil
 }
 
-// GetCategoriesByGroup retrieves categories by group from mock storage
+// GetCategoriesByGroup retrieves built-in categories by group from mock storage
 func (m *MockStorage) GetCategoriesByGroup(ctx context.Context, group string) ([]*models.Category, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var result []*models.Category
 	for _, category := range m.categories {
-		if category.Group == group {
+		if !category.UserID.Valid && category.Group == group {
 			result = append(result, category)
 		}
 	}
 	return result, nil
 }
 
-// GetCategoryByName retrieves a category by name from mock storage
+// GetCategoryByName retrieves a built-in category by name from mock storage
 func (m *MockStorage) GetCategoryByName(ctx context.Context, name string) (*models.Category, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for _, category := range m.categories {
-		if category.Name == name {
+		if !category.UserID.Valid && category.Name == name {
 			return category, nil
 		}
 	}
 	return nil, sql.ErrNoRows
 }
 
+// GetCategoriesByUserID retrieves a user's custom categories from mock storage
+func (m *MockStorage) GetCategoriesByUserID(ctx context.Context, userID int64) ([]*models.Category, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Category
+	for _, category := range m.categories {
+		if category.UserID.Valid && category.UserID.Int64 == userID {
+			result = append(result, category)
+		}
+	}
+	return result, nil
+}
+
+// CreateCategory creates a custom category in mock storage
+func (m *MockStorage) CreateCategory(ctx context.Context, category *models.Category) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	category.ID = m.nextID
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+	m.categories = append(m.categories, category)
+	m.nextID++
+	return nil
+}
+
+// UpdateCategory updates a user's custom category in mock storage
+func (m *MockStorage) UpdateCategory(ctx context.Context, category *models.Category) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.categories {
+		if existing.ID == category.ID && existing.UserID.Valid && existing.UserID == category.UserID {
+			existing.Name = category.Name
+			existing.Emoji = category.Emoji
+			existing.Group = category.Group
+			existing.Archived = category.Archived
+			existing.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// MergeCategory moves a user's expenses, recurring expenses and budget limits to another
+// category in mock storage, recording each moved expense in its history, and returns the IDs
+// of the moved expenses
+func (m *MockStorage) MergeCategory(ctx context.Context, userID, fromID, toID int64) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var target *models.Category
+	for _, category := range m.categories {
+		if category.ID == toID {
+			target = category
+		}
+	}
+	if target == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	for _, budget := range m.budgets {
+		if budget.UserID != userID {
+			continue
+		}
+		var from, to *models.BudgetLimit
+		for _, limit := range budget.Limits {
+			switch limit.CategoryID {
+			case fromID:
+				from = limit
+			case toID:
+				to = limit
+			}
+		}
+		switch {
+		case from == nil:
+		case to == nil:
+			from.CategoryID = toID
+			from.CategoryName = target.Name
+			from.CategoryEmoji = target.Emoji
+		default:
+			to.LimitAmount += from.LimitAmount
+			limits := budget.Limits[:0]
+			for _, limit := range budget.Limits {
+				if limit != from {
+					limits = append(limits, limit)
+				}
+			}
+			budget.Limits = limits
+		}
+	}
+
+	for _, recurring := range m.recurring {
+		if recurring.UserID == userID && recurring.CategoryID == fromID {
+			recurring.CategoryID = toID
+		}
+	}
+
+	var expenseIDs []int64
+	var history []*models.ExpenseHistory
+	for _, expense := range m.expenses {
+		if expense.UserID == userID && expense.CategoryID == fromID {
+			history = append(history, newMergeHistory(userID, expense, target))
+			expense.CategoryID = toID
+			expense.CategoryName = target.Name
+			expense.CategoryEmoji = target.Emoji
+			expense.CategoryGroup = target.Group
+			expenseIDs = append(expenseIDs, expense.ID)
+		}
+	}
+	sort.Slice(expenseIDs, func(i, j int) bool { return expenseIDs[i] < expenseIDs[j] })
+	sort.Slice(history, func(i, j int) bool { return history[i].ExpenseID < history[j].ExpenseID })
+	m.createExpenseHistory(history)
+
+	categories := m.categories[:0]
+	for _, category := range m.categories {
+		if category.ID != fromID || !category.UserID.Valid || category.UserID.Int64 != userID {
+			categories = append(categories, category)
+		}
+	}
+	m.categories = categories
+	return expenseIDs, nil
+}
+
+// GetCategoryGroups retrieves the built-in groups followed by the user's own from mock storage
+func (m *MockStorage) GetCategoryGroups(ctx context.Context, userID int64) ([]*models.CategoryGroup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.CategoryGroup
+	for _, group := range m.groups {
+		if !group.UserID.Valid || group.UserID.Int64 == userID {
+			result = append(result, group)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].UserID.Valid != result[j].UserID.Valid {
+			return !result[i].UserID.Valid
+		}
+		return result[i].Position < result[j].Position
+	})
+	return result, nil
+}
+
+// CreateCategoryGroup creates a custom group after the user's existing groups in mock storage
+func (m *MockStorage) CreateCategoryGroup(ctx context.Context, group *models.CategoryGroup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	group.Position = 1
+	for _, existing := range m.groups {
+		if existing.UserID == group.UserID && existing.Position >= group.Position {
+			group.Position = existing.Position + 1
+		}
+	}
+	group.ID = m.nextID
+	group.CreatedAt = time.Now()
+	group.UpdatedAt = time.Now()
+	m.groups = append(m.groups, group)
+	m.nextID++
+	return nil
+}
+
+// RenameCategoryGroup renames a user's custom group and moves its categories in mock storage
+func (m *MockStorage) RenameCategoryGroup(ctx context.Context, group *models.CategoryGroup, oldName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.groups {
+		if existing.ID == group.ID && existing.UserID.Valid && existing.UserID == group.UserID {
+			existing.Name = group.Name
+			existing.Emoji = group.Emoji
+			existing.UpdatedAt = time.Now()
+			for _, category := range m.categories {
+				if category.UserID == group.UserID && category.Group == oldName {
+					category.Group = group.Name
+				}
+			}
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
 // Expense Operations
 
-// CreateExpense creates a new expense in mock storage
-func (m *MockStorage) CreateExpense(ctx context.Context, expense *models.Expense) error {
+// CreateExpense creates a new expense and records it in the history in mock storage
+func (m *MockStorage) CreateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if err := m.checkUndoable(history); err != nil {
+		return err
+	}
 	expense.ID = m.nextID
 	expense.CreatedAt = time.Now()
 	expense.UpdatedAt = time.Now()
 	m.expenses[expense.ID] = expense
 	m.nextID++
+	for _, entry := range history {
+		entry.ExpenseID = expense.ID
+	}
+	m.createExpenseHistory(history)
+	return nil
+}
+
+// CreateExpenses creates expenses and records them in the history in mock storage
+func (m *MockStorage) CreateExpenses(ctx context.Context, expenses []*models.Expense, history ...*models.ExpenseHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkUndoable(history); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, expense := range expenses {
+		expense.ID = m.nextID
+		expense.CreatedAt = now
+		expense.UpdatedAt = now
+		m.expenses[expense.ID] = expense
+		m.nextID++
+	}
+	for i, entry := range history {
+		if i < len(expenses) {
+			entry.ExpenseID = expenses[i].ID
+		}
+	}
+	m.createExpenseHistory(history)
 	return nil
 }
 
@@ -167,40 +441,113 @@ func (m *MockStorage) GetExpenseByID(ctx context.Context, id int64) (*models.Exp
 	defer m.mu.RUnlock()
 
 	if expense, exists := m.expenses[id]; exists && expense.DeletedAt == nil {
-		return expense, nil
+		copied := *expense
+		return &copied, nil
 	}
 	return nil, sql.ErrNoRows
 }
 
-// UpdateExpense updates an existing expense in mock storage
-func (m *MockStorage) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+// UpdateExpense updates an existing expense and records the change in the history in mock storage
+func (m *MockStorage) UpdateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if existingExpense, exists := m.expenses[expense.ID]; exists && existingExpense.DeletedAt == nil {
+		if err := m.checkUndoable(history); err != nil {
+			return err
+		}
 		existingExpense.CategoryID = expense.CategoryID
 		existingExpense.VehicleType = expense.VehicleType
+		existingExpense.VehicleID = expense.VehicleID
 		existingExpense.Odometer = expense.Odometer
 		existingExpense.PetrolPrice = expense.PetrolPrice
 		existingExpense.TotalPrice = expense.TotalPrice
 		existingExpense.Notes = expense.Notes
 		existingExpense.Timestamp = expense.Timestamp
+		existingExpense.Currency = expense.Currency
+		existingExpense.OriginalAmount = expense.OriginalAmount
 		existingExpense.UpdatedAt = time.Now()
+		for _, category := range m.categories {
+			if category.ID == expense.CategoryID {
+				existingExpense.CategoryName = category.Name
+				existingExpense.CategoryEmoji = category.Emoji
+				existingExpense.CategoryGroup = category.Group
+				break
+			}
+		}
 		*expense = *existingExpense
+		m.createExpenseHistory(history)
 		return nil
 	}
 	return sql.ErrNoRows
 }
 
-// DeleteExpense soft deletes an expense in mock storage
-func (m *MockStorage) DeleteExpense(ctx context.Context, id, userID int64) error {
+// DeleteExpense soft deletes an expense and records the change in the history in mock storage
+func (m *MockStorage) DeleteExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if expense, exists := m.expenses[id]; exists && expense.UserID == userID && expense.DeletedAt == nil {
+		if err := m.checkUndoable(history); err != nil {
+			return err
+		}
 		now := time.Now()
 		expense.DeletedAt = &now
 		expense.UpdatedAt = now
+		m.createExpenseHistory(history)
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// GetDeletedExpenseByID retrieves a soft-deleted expense by ID from mock storage
+func (m *MockStorage) GetDeletedExpenseByID(ctx context.Context, id int64) (*models.Expense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if expense, exists := m.expenses[id]; exists && expense.DeletedAt != nil {
+		copied := *expense
+		return &copied, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// GetDeletedExpenses retrieves a user's soft-deleted expenses from mock storage, most recently deleted first
+func (m *MockStorage) GetDeletedExpenses(ctx context.Context, userID int64, limit int) ([]*models.Expense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*models.Expense, 0)
+	for _, expense := range m.expenses {
+		if expense.UserID == userID && expense.DeletedAt != nil {
+			copied := *expense
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].DeletedAt.Equal(*result[j].DeletedAt) {
+			return result[i].DeletedAt.After(*result[j].DeletedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// RestoreExpense undoes the soft delete of an expense and records the change in the history in mock storage
+func (m *MockStorage) RestoreExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if expense, exists := m.expenses[id]; exists && expense.UserID == userID && expense.DeletedAt != nil {
+		if err := m.checkUndoable(history); err != nil {
+			return err
+		}
+		expense.DeletedAt = nil
+		expense.UpdatedAt = time.Now()
+		m.createExpenseHistory(history)
 		return nil
 	}
 	return sql.ErrNoRows
@@ -276,6 +623,40 @@ func (m *MockStorage) GetExpensesByDateRange(ctx context.Context, userID int64,
 	return result, nil
 }
 
+// StreamExpenses calls fn for each expense of a user in mock storage, oldest first
+func (m *MockStorage) StreamExpenses(ctx context.Context, userID int64, startDate, endDate time.Time, fn func(*models.Expense) error) error {
+	m.mu.RLock()
+	var result []*models.Expense
+	for _, expense := range m.expenses {
+		if expense.UserID != userID || expense.DeletedAt != nil {
+			continue
+		}
+		if !startDate.IsZero() && expense.Timestamp.Before(startDate) {
+			continue
+		}
+		if !endDate.IsZero() && !expense.Timestamp.Before(endDate) {
+			continue
+		}
+		copied := *expense
+		result = append(result, &copied)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+
+	for _, expense := range result {
+		if err := fn(expense); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // VectorSearchStorage Operations
 
 // SearchExpensesBySimilarity searches for expenses using semantic similarity in mock storage
@@ -353,7 +734,1302 @@ func (m *MockStorage) GetExpenseEmbedding(ctx context.Context, expenseID int64)
 	return nil, sql.ErrNoRows
 }
 
-// Helper methods for testing
+// SearchExpenses filters expenses in mock storage and matches the text as a substring of the
+// notes or category name. There is no ranking: matches are listed newest or largest first.
+func (m *MockStorage) SearchExpenses(ctx context.Context, search *models.ExpenseSearch) ([]*models.Expense, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	groups := make(map[int64]string)
+	for _, category := range m.categories {
+		groups[category.ID] = category.Group
+	}
+	inCategories := func(expense *models.Expense) bool {
+		if len(search.Categories) == 0 && len(search.Groups) == 0 {
+			return true
+		}
+		for _, category := range search.Categories {
+			if category.ID == expense.CategoryID {
+				return true
+			}
+		}
+		for _, group := range search.Groups {
+			if group == groups[expense.CategoryID] || group == expense.CategoryGroup {
+				return true
+			}
+		}
+		return false
+	}
+	words := strings.Fields(strings.ToLower(search.Text))
+	matchesText := func(expense *models.Expense) bool {
+		if len(words) == 0 {
+			return true
+		}
+		haystack := strings.ToLower(expense.Notes + " " + expense.CategoryName)
+		for _, word := range words {
+			if strings.Contains(haystack, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var result []*models.Expense
+	for _, expense := range m.expenses {
+		if expense.UserID != search.UserID || expense.DeletedAt != nil ||
+			(search.From != nil && expense.Timestamp.Before(*search.From)) ||
+			(search.To != nil && !expense.Timestamp.Before(*search.To)) ||
+			(search.MinAmount != nil && expense.TotalPrice < *search.MinAmount) ||
+			(search.MaxAmount != nil && expense.TotalPrice > *search.MaxAmount) ||
+			(search.VehicleType != "" && expense.VehicleType.String != search.VehicleType) ||
+			!inCategories(expense) || !matchesText(expense) {
+			continue
+		}
+		result = append(result, expense)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if search.ByAmount && result[i].TotalPrice != result[j].TotalPrice {
+			return result[i].TotalPrice > result[j].TotalPrice
+		}
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.After(result[j].Timestamp)
+		}
+		return result[i].ID > result[j].ID
+	})
+
+	total := len(result)
+	if search.Offset >= total {
+		return nil, total, nil
+	}
+	result = result[search.Offset:]
+	if search.Limit > 0 && len(result) > search.Limit {
+		result = result[:search.Limit]
+	}
+	return result, total, nil
+}
+
+// Budget Operations
+
+// CreateBudget creates a new active budget in mock storage, closing any active budget for the same period
+func (m *MockStorage) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.budgets {
+		if existing.UserID == budget.UserID && existing.Period == budget.Period && existing.IsActive {
+			endDate := budget.StartDate
+			existing.IsActive = false
+			existing.EndDate = &endDate
+		}
+	}
+
+	budget.ID = m.nextID
+	budget.IsActive = true
+	budget.CreatedAt = time.Now()
+	budget.UpdatedAt = time.Now()
+	m.budgets[budget.ID] = budget
+	m.nextID++
+	return nil
+}
+
+// GetActiveBudgets retrieves the active budgets for a user from mock storage
+func (m *MockStorage) GetActiveBudgets(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Budget
+	for _, budget := range m.budgets {
+		if budget.UserID == userID && budget.IsActive {
+			result = append(result, budget)
+		}
+	}
+	return result, nil
+}
+
+// GetBudgetsByUserID retrieves all budgets for a user from mock storage
+func (m *MockStorage) GetBudgetsByUserID(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Budget
+	for _, budget := range m.budgets {
+		if budget.UserID == userID {
+			result = append(result, budget)
+		}
+	}
+	return result, nil
+}
+
+// GetBudgetByID retrieves a budget by ID from mock storage
+func (m *MockStorage) GetBudgetByID(ctx context.Context, id int64) (*models.Budget, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if budget, exists := m.budgets[id]; exists {
+		return budget, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// SetBudgetLimit creates or updates a category limit in mock storage
+func (m *MockStorage) SetBudgetLimit(ctx context.Context, limit *models.BudgetLimit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	budget, exists := m.budgets[limit.BudgetID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	for _, existing := range budget.Limits {
+		if existing.CategoryID == limit.CategoryID {
+			existing.LimitAmount = limit.LimitAmount
+			existing.UpdatedAt = time.Now()
+			*limit = *existing
+			return nil
+		}
+	}
+
+	for _, category := range m.categories {
+		if category.ID == limit.CategoryID {
+			limit.CategoryName = category.Name
+			limit.CategoryEmoji = category.Emoji
+		}
+	}
+	limit.ID = m.nextID
+	limit.CreatedAt = time.Now()
+	limit.UpdatedAt = time.Now()
+	budget.Limits = append(budget.Limits, limit)
+	m.nextID++
+	return nil
+}
+
+// DeactivateBudget marks a budget as inactive in mock storage
+func (m *MockStorage) DeactivateBudget(ctx context.Context, id, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if budget, exists := m.budgets[id]; exists && budget.UserID == userID && budget.IsActive {
+		now := time.Now()
+		budget.IsActive = false
+		budget.EndDate = &now
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// Recurring Expense Operations
+
+// CreateRecurringExpense creates a new active recurring expense in mock storage
+func (m *MockStorage) CreateRecurringExpense(ctx context.Context, recurring *models.RecurringExpense) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recurring.ID = m.nextID
+	recurring.IsActive = true
+	recurring.CreatedAt = time.Now()
+	recurring.UpdatedAt = time.Now()
+	m.recurring[recurring.ID] = recurring
+	m.nextID++
+	return nil
+}
+
+// GetRecurringExpensesByUserID retrieves all recurring expenses for a user from mock storage
+func (m *MockStorage) GetRecurringExpensesByUserID(ctx context.Context, userID int64) ([]*models.RecurringExpense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.RecurringExpense
+	for _, recurring := range m.recurring {
+		if recurring.UserID == userID {
+			result = append(result, recurring)
+		}
+	}
+	return result, nil
+}
+
+// GetRecurringExpenseByID retrieves a recurring expense by ID from mock storage
+func (m *MockStorage) GetRecurringExpenseByID(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if recurring, exists := m.recurring[id]; exists {
+		return recurring, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// GetDueRecurringExpenses retrieves active recurring expenses due at or before now from mock storage
+func (m *MockStorage) GetDueRecurringExpenses(ctx context.Context, now time.Time, limit int) ([]*models.RecurringExpense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.RecurringExpense
+	for _, recurring := range m.recurring {
+		if recurring.IsActive && !recurring.NextDue.After(now) && len(result) < limit {
+			result = append(result, recurring)
+		}
+	}
+	return result, nil
+}
+
+// AdvanceRecurringExpense moves next_due forward if it still equals currentDue in mock storage
+func (m *MockStorage) AdvanceRecurringExpense(ctx context.Context, id int64, currentDue, nextDue time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recurring, exists := m.recurring[id]
+	if !exists || !recurring.NextDue.Equal(currentDue) {
+		return false, nil
+	}
+	recurring.NextDue = nextDue
+	recurring.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// SetRecurringExpenseActive pauses or resumes a recurring expense in mock storage
+func (m *MockStorage) SetRecurringExpenseActive(ctx context.Context, id, userID int64, active bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if recurring, exists := m.recurring[id]; exists && recurring.UserID == userID {
+		recurring.IsActive = active
+		recurring.UpdatedAt = time.Now()
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// DeleteRecurringExpense deletes a recurring expense from mock storage
+func (m *MockStorage) DeleteRecurringExpense(ctx context.Context, id, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if recurring, exists := m.recurring[id]; exists && recurring.UserID == userID {
+		delete(m.recurring, id)
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// Reminder Operations
+
+// CreateReminder creates a new active reminder in mock storage
+func (m *MockStorage) CreateReminder(ctx context.Context, reminder *models.Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reminder.ID = m.nextID
+	reminder.IsActive = true
+	reminder.CreatedAt = time.Now()
+	reminder.UpdatedAt = time.Now()
+	m.reminders[reminder.ID] = reminder
+	m.nextID++
+	return nil
+}
+
+// GetRemindersByUserID retrieves all reminders for a user from mock storage
+func (m *MockStorage) GetRemindersByUserID(ctx context.Context, userID int64) ([]*models.Reminder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Reminder
+	for _, reminder := range m.reminders {
+		if reminder.UserID == userID {
+			result = append(result, reminder)
+		}
+	}
+	return result, nil
+}
+
+// GetReminderByID retrieves a reminder by ID from mock storage
+func (m *MockStorage) GetReminderByID(ctx context.Context, id int64) (*models.Reminder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if reminder, exists := m.reminders[id]; exists {
+		return reminder, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// UpdateReminder updates a reminder in mock storage
+func (m *MockStorage) UpdateReminder(ctx context.Context, reminder *models.Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, exists := m.reminders[reminder.ID]; exists && existing.UserID == reminder.UserID {
+		reminder.UpdatedAt = time.Now()
+		m.reminders[reminder.ID] = reminder
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// DeleteReminder deletes a reminder from mock storage
+func (m *MockStorage) DeleteReminder(ctx context.Context, id, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if reminder, exists := m.reminders[id]; exists && reminder.UserID == userID {
+		delete(m.reminders, id)
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// GetDueReminders retrieves active reminders due at or before now from mock storage
+func (m *MockStorage) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Reminder
+	for _, reminder := range m.reminders {
+		if reminder.IsActive && !reminder.NextRun.After(now) && len(result) < limit {
+			result = append(result, reminder)
+		}
+	}
+	return result, nil
+}
+
+// ClaimReminder moves next_run forward if it still equals currentRun in mock storage
+func (m *MockStorage) ClaimReminder(ctx context.Context, id int64, currentRun, nextRun time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reminder, exists := m.reminders[id]
+	if !exists || !reminder.IsActive || !reminder.NextRun.Equal(currentRun) {
+		return false, nil
+	}
+	now := time.Now()
+	reminder.NextRun = nextRun
+	reminder.LastSentAt = &now
+	reminder.UpdatedAt = now
+	return true, nil
+}
+
+// User Settings Operations
+
+// GetUserSettings retrieves the settings of a user from mock storage
+func (m *MockStorage) GetUserSettings(ctx context.Context, userID int64) (*models.UserSettings, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if settings, exists := m.settings[userID]; exists {
+		return settings, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// UpsertUserSettings creates or replaces the settings of a user in mock storage
+func (m *MockStorage) UpsertUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if existing, exists := m.settings[settings.UserID]; exists {
+		settings.CreatedAt = existing.CreatedAt
+	} else {
+		settings.CreatedAt = now
+	}
+	settings.UpdatedAt = now
+	m.settings[settings.UserID] = settings
+	return nil
+}
+
+// Exchange Rate Operations
+
+// UpsertExchangeRates creates or replaces rates in mock storage
+func (m *MockStorage) UpsertExchangeRates(ctx context.Context, rates []*models.ExchangeRate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, rate := range rates {
+		rate.UpdatedAt = now
+		replaced := false
+		for i, existing := range m.rates {
+			if existing.BaseCurrency == rate.BaseCurrency && existing.QuoteCurrency == rate.QuoteCurrency &&
+				existing.RateDate.Equal(rate.RateDate) {
+				rate.ID = existing.ID
+				rate.CreatedAt = existing.CreatedAt
+				m.rates[i] = rate
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rate.ID = m.nextID
+			rate.CreatedAt = now
+			m.nextID++
+			m.rates = append(m.rates, rate)
+		}
+	}
+	return nil
+}
+
+// GetExchangeRate retrieves the latest base→quote rate dated on or before on from mock storage
+func (m *MockStorage) GetExchangeRate(ctx context.Context, base, quote string, on time.Time) (*models.ExchangeRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var latest *models.ExchangeRate
+	for _, rate := range m.rates {
+		if rate.BaseCurrency != base || rate.QuoteCurrency != quote || rate.RateDate.After(on) {
+			continue
+		}
+		if latest == nil || rate.RateDate.After(latest.RateDate) {
+			latest = rate
+		}
+	}
+	if latest == nil {
+		return nil, sql.ErrNoRows
+	}
+	return latest, nil
+}
+
+// GetLatestExchangeRates retrieves the most recent rate of each currency pair from mock storage
+func (m *MockStorage) GetLatestExchangeRates(ctx context.Context, limit int) ([]*models.ExchangeRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latest := make(map[string]*models.ExchangeRate)
+	for _, rate := range m.rates {
+		key := rate.BaseCurrency + rate.QuoteCurrency
+		if existing, ok := latest[key]; !ok || rate.RateDate.After(existing.RateDate) {
+			latest[key] = rate
+		}
+	}
+
+	rates := make([]*models.ExchangeRate, 0, len(latest))
+	for _, rate := range latest {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].BaseCurrency+rates[i].QuoteCurrency < rates[j].BaseCurrency+rates[j].QuoteCurrency
+	})
+	if len(rates) > limit {
+		rates = rates[:limit]
+	}
+	return rates, nil
+}
+
+// Import Profile Operations
+
+// GetImportProfile retrieves a user's import profile by name from mock storage
+func (m *MockStorage) GetImportProfile(ctx context.Context, userID int64, name string) (*models.ImportProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, profile := range m.profiles {
+		if profile.UserID == userID && profile.Name == name {
+			return profile, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// UpsertImportProfile creates or replaces a user's import profile in mock storage
+func (m *MockStorage) UpsertImportProfile(ctx context.Context, profile *models.ImportProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	profile.UpdatedAt = now
+	for i, existing := range m.profiles {
+		if existing.UserID == profile.UserID && existing.Name == profile.Name {
+			profile.ID = existing.ID
+			profile.CreatedAt = existing.CreatedAt
+			m.profiles[i] = profile
+			return nil
+		}
+	}
+
+	profile.ID = m.nextID
+	profile.CreatedAt = now
+	m.nextID++
+	m.profiles = append(m.profiles, profile)
+	return nil
+}
+
+// Embedding Queue Operations
+
+// EnqueueEmbeddingJobs queues expenses for embedding in mock storage
+func (m *MockStorage) EnqueueEmbeddingJobs(ctx context.Context, expenseIDs []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range expenseIDs {
+		m.jobs[id] = &mockEmbeddingJob{
+			EmbeddingJob: models.EmbeddingJob{ExpenseID: id, EnqueuedAt: now},
+			runAt:        now,
+		}
+	}
+	return nil
+}
+
+// EnqueueMissingEmbeddings queues every expense of a user that is not queued yet in mock storage,
+// since mock storage keeps no embeddings
+func (m *MockStorage) EnqueueMissingEmbeddings(ctx context.Context, userID int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	queued := 0
+	for _, expense := range m.expenses {
+		if _, exists := m.jobs[expense.ID]; exists || expense.UserID != userID || expense.DeletedAt != nil {
+			continue
+		}
+		m.jobs[expense.ID] = &mockEmbeddingJob{
+			EmbeddingJob: models.EmbeddingJob{ExpenseID: expense.ID, EnqueuedAt: now},
+			runAt:        now,
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// ClaimEmbeddingJobs locks up to limit due jobs in mock storage
+func (m *MockStorage) ClaimEmbeddingJobs(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var due []*mockEmbeddingJob
+	for _, job := range m.jobs {
+		if !job.failed && !job.runAt.After(now) && job.lockedUntil.Before(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].runAt.Equal(due[j].runAt) {
+			return due[i].runAt.Before(due[j].runAt)
+		}
+		return due[i].ExpenseID < due[j].ExpenseID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	result := make([]*models.EmbeddingJob, 0, len(due))
+	for _, job := range due {
+		job.Attempts++
+		job.lockedUntil = now.Add(lease)
+		claimed := job.EmbeddingJob
+		result = append(result, &claimed)
+	}
+	return result, nil
+}
+
+// queuedJob returns the job in mock storage if it has not been queued again since it was claimed
+func (m *MockStorage) queuedJob(job *models.EmbeddingJob) *mockEmbeddingJob {
+	existing, exists := m.jobs[job.ExpenseID]
+	if !exists || !existing.EnqueuedAt.Equal(job.EnqueuedAt) {
+		return nil
+	}
+	return existing
+}
+
+// CompleteEmbeddingJob removes a finished job from mock storage
+func (m *MockStorage) CompleteEmbeddingJob(ctx context.Context, job *models.EmbeddingJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.queuedJob(job) != nil {
+		delete(m.jobs, job.ExpenseID)
+	}
+	return nil
+}
+
+// RetryEmbeddingJob releases a failed job in mock storage to be claimed again at runAt
+func (m *MockStorage) RetryEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, runAt time.Time, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing := m.queuedJob(job); existing != nil {
+		existing.runAt = runAt
+		existing.lockedUntil = time.Time{}
+		existing.LastError = lastError
+	}
+	return nil
+}
+
+// FailEmbeddingJob marks a job in mock storage that ran out of attempts
+func (m *MockStorage) FailEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing := m.queuedJob(job); existing != nil {
+		existing.failed = true
+		existing.lockedUntil = time.Time{}
+		existing.LastError = lastError
+	}
+	return nil
+}
+
+// GetEmbeddingQueueStats counts pending and failed embedding jobs in mock storage
+func (m *MockStorage) GetEmbeddingQueueStats(ctx context.Context) (*models.EmbeddingQueueStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &models.EmbeddingQueueStats{}
+	for _, job := range m.jobs {
+		if job.failed {
+			stats.Failed++
+		} else {
+			stats.Pending++
+		}
+	}
+	return stats, nil
+}
+
+// GetCachedEmbedding retrieves a cached embedding by content hash from mock storage
+func (m *MockStorage) GetCachedEmbedding(ctx context.Context, contentHash string) ([]float32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if embedding, exists := m.cache[contentHash]; exists {
+		return embedding, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// SaveCachedEmbedding stores an embedding by content hash in mock storage, keeping the first one
+func (m *MockStorage) SaveCachedEmbedding(ctx context.Context, contentHash string, embedding []float32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.cache[contentHash]; !exists {
+		m.cache[contentHash] = embedding
+	}
+	return nil
+}
+
+// Helper methods for testing
+
+// Vehicle Operations
+
+// CreateVehicle creates a new vehicle in mock storage
+func (m *MockStorage) CreateVehicle(ctx context.Context, vehicle *models.Vehicle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vehicle.ID = m.nextID
+	vehicle.CreatedAt = time.Now()
+	vehicle.UpdatedAt = time.Now()
+	m.vehicles[vehicle.ID] = vehicle
+	m.nextID++
+	return nil
+}
+
+// GetVehiclesByUserID retrieves a user's vehicles in the order they were added from mock storage
+func (m *MockStorage) GetVehiclesByUserID(ctx context.Context, userID int64) ([]*models.Vehicle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Vehicle
+	for _, vehicle := range m.vehicles {
+		if vehicle.UserID == userID {
+			result = append(result, vehicle)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// GetVehicleByID retrieves a vehicle by ID from mock storage
+func (m *MockStorage) GetVehicleByID(ctx context.Context, id int64) (*models.Vehicle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if vehicle, exists := m.vehicles[id]; exists {
+		return vehicle, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// DeleteVehicle deletes a vehicle from mock storage and unlinks its expenses
+func (m *MockStorage) DeleteVehicle(ctx context.Context, id, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vehicle, exists := m.vehicles[id]
+	if !exists || vehicle.UserID != userID {
+		return sql.ErrNoRows
+	}
+	delete(m.vehicles, id)
+	for _, expense := range m.expenses {
+		if expense.VehicleID.Valid && expense.VehicleID.Int64 == id {
+			expense.VehicleID = sql.NullInt64{}
+		}
+	}
+	return nil
+}
+
+// Ledger Operations
+
+// CreateLedger creates a ledger with ownerID as its owner in mock storage
+func (m *MockStorage) CreateLedger(ctx context.Context, ledger *models.Ledger, ownerID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.ledgers {
+		if existing.InviteCode == ledger.InviteCode {
+			return fmt.Errorf("duplicate invite code %s", ledger.InviteCode)
+		}
+	}
+
+	ledger.ID = m.nextID
+	ledger.CreatedBy = sql.NullInt64{Int64: ownerID, Valid: true}
+	ledger.CreatedAt = time.Now()
+	ledger.UpdatedAt = time.Now()
+	ledger.Role = models.LedgerRoleOwner
+	stored := *ledger
+	stored.Role, stored.Active = "", false
+	m.ledgers[ledger.ID] = &stored
+	m.members = append(m.members, &models.LedgerMember{
+		LedgerID: ledger.ID, UserID: ownerID, Role: models.LedgerRoleOwner, JoinedAt: time.Now(),
+	})
+	m.nextID++
+	return nil
+}
+
+// GetLedgersByUserID retrieves the ledgers a user is a member of from mock storage
+func (m *MockStorage) GetLedgersByUserID(ctx context.Context, userID int64) ([]*models.Ledger, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Ledger
+	for _, member := range m.members {
+		if member.UserID != userID {
+			continue
+		}
+		ledger := *m.ledgers[member.LedgerID]
+		ledger.Role, ledger.Active = member.Role, member.Active
+		result = append(result, &ledger)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// GetLedgerByInviteCode retrieves a ledger by its invite code from mock storage
+func (m *MockStorage) GetLedgerByInviteCode(ctx context.Context, inviteCode string) (*models.Ledger, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, ledger := range m.ledgers {
+		if ledger.InviteCode == inviteCode {
+			copied := *ledger
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// GetLedgerByChatID retrieves the ledger linked to a group chat from mock storage
+func (m *MockStorage) GetLedgerByChatID(ctx context.Context, chatID int64) (*models.Ledger, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, ledger := range m.ledgers {
+		if ledger.ChatID.Valid && ledger.ChatID.Int64 == chatID {
+			copied := *ledger
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// LinkLedgerChat links a ledger to a group chat, replacing the chat's previous ledger, in mock storage
+func (m *MockStorage) LinkLedgerChat(ctx context.Context, ledgerID, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ledger, exists := m.ledgers[ledgerID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	for _, other := range m.ledgers {
+		if other.ChatID.Valid && other.ChatID.Int64 == chatID {
+			other.ChatID = sql.NullInt64{}
+		}
+	}
+	ledger.ChatID = sql.NullInt64{Int64: chatID, Valid: true}
+	return nil
+}
+
+// UnlinkLedgerChat unlinks the ledger linked to a group chat in mock storage
+func (m *MockStorage) UnlinkLedgerChat(ctx context.Context, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ledger := range m.ledgers {
+		if ledger.ChatID.Valid && ledger.ChatID.Int64 == chatID {
+			ledger.ChatID = sql.NullInt64{}
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// DeleteLedger deletes a ledger and its memberships from mock storage
+func (m *MockStorage) DeleteLedger(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.ledgers[id]; !exists {
+		return sql.ErrNoRows
+	}
+	delete(m.ledgers, id)
+	members := m.members[:0]
+	for _, member := range m.members {
+		if member.LedgerID != id {
+			members = append(members, member)
+		}
+	}
+	m.members = members
+	for _, expense := range m.expenses {
+		if expense.LedgerID.Valid && expense.LedgerID.Int64 == id {
+			expense.LedgerID = sql.NullInt64{}
+		}
+	}
+	return nil
+}
+
+// GetLedgerMembers retrieves a ledger's members in the order they joined from mock storage
+func (m *MockStorage) GetLedgerMembers(ctx context.Context, ledgerID int64) ([]*models.LedgerMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.LedgerMember
+	for _, member := range m.members {
+		if member.LedgerID == ledgerID {
+			result = append(result, m.ledgerMemberWithName(member))
+		}
+	}
+	return result, nil
+}
+
+// GetLedgerMember retrieves a user's membership of a ledger from mock storage
+func (m *MockStorage) GetLedgerMember(ctx context.Context, ledgerID, userID int64) (*models.LedgerMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, member := range m.members {
+		if member.LedgerID == ledgerID && member.UserID == userID {
+			return m.ledgerMemberWithName(member), nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// ledgerMemberWithName returns a copy of member with the user's names filled in. The caller holds the lock.
+func (m *MockStorage) ledgerMemberWithName(member *models.LedgerMember) *models.LedgerMember {
+	copied := *member
+	for _, user := range m.users {
+		if user.ID == member.UserID {
+			copied.Username, copied.FirstName = user.Username, user.FirstName
+			break
+		}
+	}
+	return &copied
+}
+
+// AddLedgerMember adds a user to a ledger in mock storage
+func (m *MockStorage) AddLedgerMember(ctx context.Context, member *models.LedgerMember) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.members {
+		if existing.LedgerID == member.LedgerID && existing.UserID == member.UserID {
+			return fmt.Errorf("user %d is already a member of ledger %d", member.UserID, member.LedgerID)
+		}
+	}
+	member.JoinedAt = time.Now()
+	stored := *member
+	m.members = append(m.members, &stored)
+	return nil
+}
+
+// UpdateLedgerMemberRole changes a member's role in mock storage
+func (m *MockStorage) UpdateLedgerMemberRole(ctx context.Context, ledgerID, userID int64, role models.LedgerRole) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, member := range m.members {
+		if member.LedgerID == ledgerID && member.UserID == userID {
+			member.Role = role
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// RemoveLedgerMember removes a user from a ledger in mock storage
+func (m *MockStorage) RemoveLedgerMember(ctx context.Context, ledgerID, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, member := range m.members {
+		if member.LedgerID == ledgerID && member.UserID == userID {
+			m.members = append(m.members[:i], m.members[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// SetActiveLedger makes ledgerID the user's active ledger in mock storage; 0 makes none active
+func (m *MockStorage) SetActiveLedger(ctx context.Context, userID, ledgerID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, member := range m.members {
+		if member.UserID == userID {
+			member.Active = member.LedgerID == ledgerID
+		}
+	}
+	return nil
+}
+
+// GetLedgerExpenses retrieves a ledger's expenses with their categories within a date range from mock storage, newest first
+func (m *MockStorage) GetLedgerExpenses(ctx context.Context, ledgerID int64, startDate, endDate time.Time) ([]*models.Expense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Expense
+	for _, expense := range m.expenses {
+		if expense.LedgerID.Valid && expense.LedgerID.Int64 == ledgerID && expense.DeletedAt == nil &&
+			!expense.Timestamp.Before(startDate) && expense.Timestamp.Before(endDate) {
+			copied := *expense
+			for _, category := range m.categories {
+				if category.ID == expense.CategoryID {
+					copied.CategoryName, copied.CategoryEmoji, copied.CategoryGroup = category.Name, category.Emoji, category.Group
+				}
+			}
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp.After(result[j].Timestamp) })
+	return result, nil
+}
+
+// Split Operations
+
+// SetExpenseSplits replaces the splits of an expense in mock storage
+func (m *MockStorage) SetExpenseSplits(ctx context.Context, expenseID int64, splits []*models.ExpenseSplit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := make([]*models.ExpenseSplit, 0, len(splits))
+	for _, split := range splits {
+		split.ExpenseID = expenseID
+		split.CreatedAt = time.Now()
+		copied := *split
+		stored = append(stored, &copied)
+	}
+	m.splits[expenseID] = stored
+	return nil
+}
+
+// GetExpenseSplits retrieves the splits of an expense from mock storage
+func (m *MockStorage) GetExpenseSplits(ctx context.Context, expenseID int64) ([]*models.ExpenseSplit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*models.ExpenseSplit, 0, len(m.splits[expenseID]))
+	for _, split := range m.splits[expenseID] {
+		copied := *split
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// GetLedgerSplits retrieves the splits of a ledger's expenses that have not been deleted from mock storage
+func (m *MockStorage) GetLedgerSplits(ctx context.Context, ledgerID int64) ([]*models.ExpenseSplit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.ExpenseSplit
+	for expenseID, splits := range m.splits {
+		expense, exists := m.expenses[expenseID]
+		if !exists || expense.DeletedAt != nil || !expense.LedgerID.Valid || expense.LedgerID.Int64 != ledgerID {
+			continue
+		}
+		for _, split := range splits {
+			copied := *split
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ExpenseID == result[j].ExpenseID {
+			return result[i].UserID < result[j].UserID
+		}
+		return result[i].ExpenseID < result[j].ExpenseID
+	})
+	return result, nil
+}
+
+// GetLedgerSplitExpenses retrieves the split expenses of a ledger from mock storage, oldest first
+func (m *MockStorage) GetLedgerSplitExpenses(ctx context.Context, ledgerID int64) ([]*models.Expense, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Expense
+	for expenseID := range m.splits {
+		expense, exists := m.expenses[expenseID]
+		if !exists || expense.DeletedAt != nil || !expense.LedgerID.Valid || expense.LedgerID.Int64 != ledgerID {
+			continue
+		}
+		copied := *expense
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// CreateSettlement records a payment between two ledger members in mock storage
+func (m *MockStorage) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settlement.ID = m.nextID
+	settlement.CreatedAt = time.Now()
+	copied := *settlement
+	m.settlements = append(m.settlements, &copied)
+	m.nextID++
+	return nil
+}
+
+// GetSettlementsByLedgerID retrieves the settlements in a ledger from mock storage, oldest first
+func (m *MockStorage) GetSettlementsByLedgerID(ctx context.Context, ledgerID int64) ([]*models.Settlement, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.Settlement
+	for _, settlement := range m.settlements {
+		if settlement.LedgerID == ledgerID {
+			copied := *settlement
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// Attachment Operations
+
+// CreateAttachment records a file attached to an expense in mock storage
+func (m *MockStorage) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attachment.ID = m.nextID
+	attachment.CreatedAt = time.Now()
+	copied := *attachment
+	m.attachments[attachment.ID] = &copied
+	m.nextID++
+	return nil
+}
+
+// GetAttachmentByID retrieves an attachment by ID from mock storage
+func (m *MockStorage) GetAttachmentByID(ctx context.Context, id int64) (*models.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	attachment, exists := m.attachments[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	copied := *attachment
+	return &copied, nil
+}
+
+// GetAttachmentsByExpenseIDs retrieves the attachments of several expenses from mock storage, oldest first
+func (m *MockStorage) GetAttachmentsByExpenseIDs(ctx context.Context, expenseIDs []int64) ([]*models.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[int64]bool, len(expenseIDs))
+	for _, id := range expenseIDs {
+		wanted[id] = true
+	}
+
+	result := make([]*models.Attachment, 0)
+	for _, attachment := range m.attachments {
+		if wanted[attachment.ExpenseID] {
+			copied := *attachment
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ExpenseID != result[j].ExpenseID {
+			return result[i].ExpenseID < result[j].ExpenseID
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// Expense History Operations
+
+// checkUndoable fails with sql.ErrNoRows if an entry undoes a change that was already undone.
+// The caller holds the lock.
+func (m *MockStorage) checkUndoable(entries []*models.ExpenseHistory) error {
+	for _, entry := range entries {
+		if !entry.UndoOf.Valid {
+			continue
+		}
+		undoable := false
+		for _, change := range m.history {
+			if change.ID == entry.UndoOf.Int64 && change.UndoneAt == nil {
+				undoable = true
+				break
+			}
+		}
+		if !undoable {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// createExpenseHistory records changes to expenses in mock storage, marking the changes they
+// undo undone. The caller holds the lock and has checked the entries with checkUndoable.
+func (m *MockStorage) createExpenseHistory(entries []*models.ExpenseHistory) {
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.UndoOf.Valid {
+			for _, change := range m.history {
+				if change.ID == entry.UndoOf.Int64 {
+					change.UndoneAt = &now
+				}
+			}
+		}
+		entry.ID = m.nextID
+		entry.CreatedAt = now
+		copied := *entry
+		m.history = append(m.history, &copied)
+		m.nextID++
+	}
+}
+
+// expenseHistoryWithName returns a copy of entry with the actor's name filled in. The caller holds the lock.
+func (m *MockStorage) expenseHistoryWithName(entry *models.ExpenseHistory) *models.ExpenseHistory {
+	copied := *entry
+	for _, user := range m.users {
+		if user.ID == entry.UserID {
+			copied.ActorName = user.FirstName
+			if copied.ActorName == "" {
+				copied.ActorName = user.Username
+			}
+			break
+		}
+	}
+	return &copied
+}
+
+// GetExpenseHistory retrieves the changes to an expense from mock storage, oldest first
+func (m *MockStorage) GetExpenseHistory(ctx context.Context, expenseID int64) ([]*models.ExpenseHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*models.ExpenseHistory, 0)
+	for _, entry := range m.history {
+		if entry.ExpenseID == expenseID {
+			result = append(result, m.expenseHistoryWithName(entry))
+		}
+	}
+	return result, nil
+}
+
+// GetLastUndoableChange retrieves the user's most recent change that /undo can revert from mock storage
+func (m *MockStorage) GetLastUndoableChange(ctx context.Context, userID int64) (*models.ExpenseHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.history) - 1; i >= 0; i-- {
+		entry := m.history[i]
+		if entry.UserID == userID && entry.UndoneAt == nil && !entry.UndoOf.Valid && entry.Action != models.HistoryActionImport {
+			return m.expenseHistoryWithName(entry), nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// User State Operations
+
+// GetUserState retrieves an unexpired conversation from mock storage
+func (m *MockStorage) GetUserState(ctx context.Context, chatID, userID int64) (*models.UserState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	saved, ok := m.userStates[mockUserStateKey{chatID: chatID, userID: userID}]
+	if !ok || !saved.expiresAt.After(time.Now()) {
+		return nil, sql.ErrNoRows
+	}
+
+	var state models.UserState
+	if err := json.Unmarshal(saved.data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveUserState creates or replaces a conversation in mock storage
+func (m *MockStorage) SaveUserState(ctx context.Context, chatID, userID int64, state *models.UserState, expiresAt time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.userStates[mockUserStateKey{chatID: chatID, userID: userID}] = &mockUserState{data: data, expiresAt: expiresAt}
+	return nil
+}
+
+// DeleteUserState removes a conversation from mock storage
+func (m *MockStorage) DeleteUserState(ctx context.Context, chatID, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.userStates, mockUserStateKey{chatID: chatID, userID: userID})
+	return nil
+}
+
+// DeleteExpiredUserStates purges expired conversations from mock storage
+func (m *MockStorage) DeleteExpiredUserStates(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := 0
+	now := time.Now()
+	for key, saved := range m.userStates {
+		if !saved.expiresAt.After(now) {
+			delete(m.userStates, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// CountUserStates counts the unexpired conversations in mock storage
+func (m *MockStorage) CountUserStates(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	now := time.Now()
+	for _, saved := range m.userStates {
+		if saved.expiresAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
 
 // AddMockCategory adds a category to mock storage for testing
 func (m *MockStorage) AddMockCategory(category *models.Category) {
@@ -374,6 +2050,23 @@ func (m *MockStorage) ClearMockData() {
 
 	m.users = make(map[int64]*models.User)
 	m.categories = make([]*models.Category, 0)
+	m.groups = make([]*models.CategoryGroup, 0)
 	m.expenses = make(map[int64]*models.Expense)
+	m.budgets = make(map[int64]*models.Budget)
+	m.recurring = make(map[int64]*models.RecurringExpense)
+	m.reminders = make(map[int64]*models.Reminder)
+	m.settings = make(map[int64]*models.UserSettings)
+	m.rates = nil
+	m.profiles = nil
+	m.jobs = make(map[int64]*mockEmbeddingJob)
+	m.cache = make(map[string][]float32)
+	m.vehicles = make(map[int64]*models.Vehicle)
+	m.ledgers = make(map[int64]*models.Ledger)
+	m.members = nil
+	m.splits = make(map[int64][]*models.ExpenseSplit)
+	m.settlements = nil
+	m.attachments = make(map[int64]*models.Attachment)
+	m.history = nil
+	m.userStates = make(map[mockUserStateKey]*mockUserState)
 	m.nextID = 1
 }
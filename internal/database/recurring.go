@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// RecurringExpenseStorage defines operations for recurring expense management
+type RecurringExpenseStorage interface {
+	CreateRecurringExpense(ctx context.Context, recurring *models.RecurringExpense) error
+	GetRecurringExpensesByUserID(ctx context.Context, userID int64) ([]*models.RecurringExpense, error)
+	GetRecurringExpenseByID(ctx context.Context, id int64) (*models.RecurringExpense, error)
+	GetDueRecurringExpenses(ctx context.Context, now time.Time, limit int) ([]*models.RecurringExpense, error)
+	AdvanceRecurringExpense(ctx context.Context, id int64, currentDue, nextDue time.Time) (bool, error)
+	SetRecurringExpenseActive(ctx context.Context, id, userID int64, active bool) error
+	DeleteRecurringExpense(ctx context.Context, id, userID int64) error
+}
+
+// recurringExpenseColumns selects a recurring expense with its category and owner details
+const recurringExpenseColumns = `
+	r.id, r.user_id, r.category_id, r.amount, r.interval, r.next_due,
+	COALESCE(r.anchor_day, EXTRACT(DAY FROM r.next_due)::int) as anchor_day,
+	COALESCE(r.notes, '') as notes, COALESCE(r.is_active, true) as is_active,
+	r.created_at, r.updated_at,
+	c.name as category_name, c.emoji as category_emoji, u.telegram_id`
+
+// CreateRecurringExpense creates a new active recurring expense
+func (c *Client) CreateRecurringExpense(ctx context.Context, recurring *models.RecurringExpense) error {
+	query := `
+		INSERT INTO recurring_expenses (user_id, category_id, amount, interval, next_due, anchor_day, notes, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, true)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		recurring.UserID, recurring.CategoryID, recurring.Amount, recurring.Interval,
+		recurring.NextDue, recurring.AnchorDay, recurring.Notes).
+		StructScan(recurring)
+}
+
+// GetRecurringExpensesByUserID retrieves all recurring expenses for a user
+func (c *Client) GetRecurringExpensesByUserID(ctx context.Context, userID int64) ([]*models.RecurringExpense, error) {
+	var recurring []*models.RecurringExpense
+	query := `
+		SELECT ` + recurringExpenseColumns + `
+		FROM recurring_expenses r
+		JOIN categories c ON r.category_id = c.id
+		JOIN users u ON r.user_id = u.id
+		WHERE r.user_id = $1
+		ORDER BY r.next_due, r.id`
+
+	if err := c.db.SelectContext(ctx, &recurring, query, userID); err != nil {
+		return nil, err
+	}
+
+	return recurring, nil
+}
+
+// GetRecurringExpenseByID retrieves a recurring expense by ID
+func (c *Client) GetRecurringExpenseByID(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+	query := `
+		SELECT ` + recurringExpenseColumns + `
+		FROM recurring_expenses r
+		JOIN categories c ON r.category_id = c.id
+		JOIN users u ON r.user_id = u.id
+		WHERE r.id = $1`
+
+	err := c.db.GetContext(ctx, &recurring, query, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &recurring, nil
+}
+
+// GetDueRecurringExpenses retrieves active recurring expenses due at or before now, oldest first
+func (c *Client) GetDueRecurringExpenses(ctx context.Context, now time.Time, limit int) ([]*models.RecurringExpense, error) {
+	var recurring []*models.RecurringExpense
+	query := `
+		SELECT ` + recurringExpenseColumns + `
+		FROM recurring_expenses r
+		JOIN categories c ON r.category_id = c.id
+		JOIN users u ON r.user_id = u.id
+		WHERE r.is_active AND r.next_due <= $1
+		ORDER BY r.next_due, r.id
+		LIMIT $2`
+
+	if err := c.db.SelectContext(ctx, &recurring, query, now, limit); err != nil {
+		return nil, err
+	}
+
+	return recurring, nil
+}
+
+// AdvanceRecurringExpense moves next_due from currentDue to nextDue.
+// It reports false when next_due no longer equals currentDue, which means
+// another scheduler run has already claimed this occurrence.
+func (c *Client) AdvanceRecurringExpense(ctx context.Context, id int64, currentDue, nextDue time.Time) (bool, error) {
+	query := `
+		UPDATE recurring_expenses
+		SET next_due = $3, updated_at = now()
+		WHERE id = $1 AND next_due = $2`
+
+	result, err := c.db.ExecContext(ctx, query, id, currentDue, nextDue)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// SetRecurringExpenseActive pauses or resumes a recurring expense
+func (c *Client) SetRecurringExpenseActive(ctx context.Context, id, userID int64, active bool) error {
+	query := `
+		UPDATE recurring_expenses
+		SET is_active = $3, updated_at = now()
+		WHERE id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, id, userID, active)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// DeleteRecurringExpense deletes a recurring expense. Expenses it already created are kept.
+func (c *Client) DeleteRecurringExpense(ctx context.Context, id, userID int64) error {
+	query := `DELETE FROM recurring_expenses WHERE id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// ReminderStorage defines operations for reminder management
+type ReminderStorage interface {
+	CreateReminder(ctx context.Context, reminder *models.Reminder) error
+	GetRemindersByUserID(ctx context.Context, userID int64) ([]*models.Reminder, error)
+	GetReminderByID(ctx context.Context, id int64) (*models.Reminder, error)
+	UpdateReminder(ctx context.Context, reminder *models.Reminder) error
+	DeleteReminder(ctx context.Context, id, userID int64) error
+	GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error)
+	ClaimReminder(ctx context.Context, id int64, currentRun, nextRun time.Time) (bool, error)
+}
+
+// CreateReminder creates a new active reminder
+func (c *Client) CreateReminder(ctx context.Context, reminder *models.Reminder) error {
+	query := `
+		INSERT INTO reminders (user_id, message, schedule, day_of_week, hour, minute, next_run, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, true)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		reminder.UserID, reminder.Message, reminder.Schedule, reminder.DayOfWeek,
+		reminder.Hour, reminder.Minute, reminder.NextRun).
+		StructScan(reminder)
+}
+
+// GetRemindersByUserID retrieves all reminders for a user
+func (c *Client) GetRemindersByUserID(ctx context.Context, userID int64) ([]*models.Reminder, error) {
+	var reminders []*models.Reminder
+	query := `
+		SELECT r.*, u.telegram_id
+		FROM reminders r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.user_id = $1
+		ORDER BY r.next_run, r.id`
+
+	if err := c.db.SelectContext(ctx, &reminders, query, userID); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// GetReminderByID retrieves a reminder by ID
+func (c *Client) GetReminderByID(ctx context.Context, id int64) (*models.Reminder, error) {
+	var reminder models.Reminder
+	query := `
+		SELECT r.*, u.telegram_id
+		FROM reminders r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.id = $1`
+
+	err := c.db.GetContext(ctx, &reminder, query, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &reminder, nil
+}
+
+// UpdateReminder updates the message and schedule of a reminder
+func (c *Client) UpdateReminder(ctx context.Context, reminder *models.Reminder) error {
+	query := `
+		UPDATE reminders
+		SET message = $1, schedule = $2, day_of_week = $3, hour = $4, minute = $5,
+		    next_run = $6, is_active = $7, updated_at = now()
+		WHERE id = $8 AND user_id = $9
+		RETURNING updated_at`
+
+	err := c.db.QueryRowxContext(ctx, query,
+		reminder.Message, reminder.Schedule, reminder.DayOfWeek, reminder.Hour, reminder.Minute,
+		reminder.NextRun, reminder.IsActive, reminder.ID, reminder.UserID).
+		Scan(&reminder.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteReminder deletes a reminder
+func (c *Client) DeleteReminder(ctx context.Context, id, userID int64) error {
+	query := `DELETE FROM reminders WHERE id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// GetDueReminders retrieves active reminders due at or before now, oldest first
+func (c *Client) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error) {
+	var reminders []*models.Reminder
+	query := `
+		SELECT r.*, u.telegram_id
+		FROM reminders r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.is_active AND r.next_run <= $1
+		ORDER BY r.next_run, r.id
+		LIMIT $2`
+
+	if err := c.db.SelectContext(ctx, &reminders, query, now, limit); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// ClaimReminder moves next_run from currentRun to nextRun and records the send time.
+// It reports false when next_run no longer equals currentRun, which means the
+// occurrence was already claimed, e.g. by a run before the bot restarted.
+func (c *Client) ClaimReminder(ctx context.Context, id int64, currentRun, nextRun time.Time) (bool, error) {
+	query := `
+		UPDATE reminders
+		SET next_run = $3, last_sent_at = now(), updated_at = now()
+		WHERE id = $1 AND next_run = $2 AND is_active`
+
+	result, err := c.db.ExecContext(ctx, query, id, currentRun, nextRun)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// SplitStorage defines operations for expense splits and settlements in shared ledgers
+type SplitStorage interface {
+	SetExpenseSplits(ctx context.Context, expenseID int64, splits []*models.ExpenseSplit) error
+	GetExpenseSplits(ctx context.Context, expenseID int64) ([]*models.ExpenseSplit, error)
+	GetLedgerSplits(ctx context.Context, ledgerID int64) ([]*models.ExpenseSplit, error)
+	GetLedgerSplitExpenses(ctx context.Context, ledgerID int64) ([]*models.Expense, error)
+	CreateSettlement(ctx context.Context, settlement *models.Settlement) error
+	GetSettlementsByLedgerID(ctx context.Context, ledgerID int64) ([]*models.Settlement, error)
+}
+
+// SetExpenseSplits replaces the splits of an expense in a single transaction
+func (c *Client) SetExpenseSplits(ctx context.Context, expenseID int64, splits []*models.ExpenseSplit) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM expense_splits WHERE expense_id = $1`, expenseID); err != nil {
+		return fmt.Errorf("failed to clear expense splits: %w", err)
+	}
+
+	query := `
+		INSERT INTO expense_splits (expense_id, user_id, method, shares, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`
+	for _, split := range splits {
+		split.ExpenseID = expenseID
+		if err := tx.QueryRowxContext(ctx, query, expenseID, split.UserID, split.Method, split.Shares, split.Amount).
+			Scan(&split.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert expense split: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetExpenseSplits retrieves the splits of an expense
+func (c *Client) GetExpenseSplits(ctx context.Context, expenseID int64) ([]*models.ExpenseSplit, error) {
+	var splits []*models.ExpenseSplit
+	query := `SELECT * FROM expense_splits WHERE expense_id = $1 ORDER BY user_id`
+
+	if err := c.db.SelectContext(ctx, &splits, query, expenseID); err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+// GetLedgerSplits retrieves the splits of all expenses in a ledger that have not been deleted
+func (c *Client) GetLedgerSplits(ctx context.Context, ledgerID int64) ([]*models.ExpenseSplit, error) {
+	var splits []*models.ExpenseSplit
+	query := `
+		SELECT s.*
+		FROM expense_splits s
+		JOIN expenses e ON e.id = s.expense_id
+		WHERE e.ledger_id = $1 AND e.deleted_at IS NULL
+		ORDER BY s.expense_id, s.user_id`
+
+	if err := c.db.SelectContext(ctx, &splits, query, ledgerID); err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+// GetLedgerSplitExpenses retrieves the expenses in a ledger that have been split, oldest first
+func (c *Client) GetLedgerSplitExpenses(ctx context.Context, ledgerID int64) ([]*models.Expense, error) {
+	var expenses []*models.Expense
+	query := `
+		SELECT e.*, c.name as category_name, c.emoji as category_emoji, c."group" as category_group
+		FROM expenses e
+		JOIN categories c ON e.category_id = c.id
+		WHERE e.ledger_id = $1 AND e.deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = e.id)
+		ORDER BY e.timestamp, e.id`
+
+	if err := c.db.SelectContext(ctx, &expenses, query, ledgerID); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+// CreateSettlement records a payment between two ledger members
+func (c *Client) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	query := `
+		INSERT INTO settlements (ledger_id, from_user_id, to_user_id, amount, currency, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		settlement.LedgerID, settlement.FromUserID, settlement.ToUserID,
+		settlement.Amount, settlement.Currency, settlement.Note).
+		Scan(&settlement.ID, &settlement.CreatedAt)
+}
+
+// GetSettlementsByLedgerID retrieves the settlements in a ledger, oldest first
+func (c *Client) GetSettlementsByLedgerID(ctx context.Context, ledgerID int64) ([]*models.Settlement, error) {
+	var settlements []*models.Settlement
+	query := `SELECT * FROM settlements WHERE ledger_id = $1 ORDER BY created_at, id`
+
+	if err := c.db.SelectContext(ctx, &settlements, query, ledgerID); err != nil {
+		return nil, err
+	}
+
+	return settlements, nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// UserSettingsStorage defines operations for per-user preferences
+type UserSettingsStorage interface {
+	GetUserSettings(ctx context.Context, userID int64) (*models.UserSettings, error)
+	UpsertUserSettings(ctx context.Context, settings *models.UserSettings) error
+}
+
+// GetUserSettings retrieves the settings of a user
+func (c *Client) GetUserSettings(ctx context.Context, userID int64) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	query := `SELECT * FROM user_settings WHERE user_id = $1`
+
+	err := c.db.GetContext(ctx, &settings, query, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// UpsertUserSettings creates or replaces the settings of a user
+func (c *Client) UpsertUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	query := `
+		INSERT INTO user_settings (user_id, currency, date_format, language, notifications_enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET currency = EXCLUDED.currency,
+		    date_format = EXCLUDED.date_format,
+		    language = EXCLUDED.language,
+		    notifications_enabled = EXCLUDED.notifications_enabled,
+		    updated_at = now()
+		RETURNING created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		settings.UserID, settings.Currency, settings.DateFormat, settings.Language, settings.NotificationsEnabled).
+		Scan(&settings.CreatedAt, &settings.UpdatedAt)
+}
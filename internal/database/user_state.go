@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// UserStateStorage defines operations for the conversation state of users in chats
+type UserStateStorage interface {
+	GetUserState(ctx context.Context, chatID, userID int64) (*models.UserState, error)
+	SaveUserState(ctx context.Context, chatID, userID int64, state *models.UserState, expiresAt time.Time) error
+	DeleteUserState(ctx context.Context, chatID, userID int64) error
+	DeleteExpiredUserStates(ctx context.Context) (int, error)
+	CountUserStates(ctx context.Context) (int, error)
+}
+
+// GetUserState retrieves the conversation with a user in a chat. It fails with not found if
+// there is none or it has expired.
+func (c *Client) GetUserState(ctx context.Context, chatID, userID int64) (*models.UserState, error) {
+	var data []byte
+	query := `SELECT state FROM user_states WHERE chat_id = $1 AND user_id = $2 AND expires_at > now()`
+
+	if err := c.db.GetContext(ctx, &data, query, chatID, userID); err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	var state models.UserState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// SaveUserState creates or replaces the conversation with a user in a chat, which expires at expiresAt
+func (c *Client) SaveUserState(ctx context.Context, chatID, userID int64, state *models.UserState, expiresAt time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_states (chat_id, user_id, state, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET
+			state = EXCLUDED.state,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = now()`
+
+	_, err = c.db.ExecContext(ctx, query, chatID, userID, data, expiresAt)
+	return err
+}
+
+// DeleteUserState ends the conversation with a user in a chat; deleting none is not an error
+func (c *Client) DeleteUserState(ctx context.Context, chatID, userID int64) error {
+	query := `DELETE FROM user_states WHERE chat_id = $1 AND user_id = $2`
+
+	_, err := c.db.ExecContext(ctx, query, chatID, userID)
+	return err
+}
+
+// DeleteExpiredUserStates purges expired conversations. It returns the number purged.
+func (c *Client) DeleteExpiredUserStates(ctx context.Context) (int, error) {
+	query := `DELETE FROM user_states WHERE expires_at <= now()`
+
+	result, err := c.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// CountUserStates counts the conversations that have not expired
+func (c *Client) CountUserStates(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM user_states WHERE expires_at > now()`
+
+	if err := c.db.GetContext(ctx, &count, query); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
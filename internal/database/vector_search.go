@@ -4,17 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/lib/pq"
 )
 
+// rrfK damps the weight of top ranks in reciprocal rank fusion, the usual value from the RRF paper
+const rrfK = 60
+
+// searchCandidates is how many of the best full-text, semantic and amount matches each ranking contributes
+const searchCandidates = 200
+
 // VectorSearchStorage defines operations for vector-based search
 type VectorSearchStorage interface {
 	SearchExpensesBySimilarity(ctx context.Context, userID int64, queryEmbedding []float32, similarityThreshold float32, limit int) ([]*models.Expense, error)
 	FindSimilarExpenses(ctx context.Context, expenseID int64, similarityThreshold float32, limit int) ([]*models.Expense, error)
 	UpdateExpenseEmbedding(ctx context.Context, expenseID int64, notesEmbedding, categoryEmbedding []float32) error
 	GetExpenseEmbedding(ctx context.Context, expenseID int64) (*models.ExpenseEmbedding, error)
+	SearchExpenses(ctx context.Context, search *models.ExpenseSearch) ([]*models.Expense, int, error)
 }
 
 // ExpenseEmbedding represents the vector embeddings for an expense
@@ -35,16 +44,12 @@ func (c *Client) SearchExpensesBySimilarity(ctx context.Context, userID int64, q
 	}
 
 	// Convert []float32 to string representation for PostgreSQL vector
-	embeddingStr := fmt.Sprintf("[%f", queryEmbedding[0])
-	for i := 1; i < len(queryEmbedding); i++ {
-		embeddingStr += fmt.Sprintf(",%f", queryEmbedding[i])
-	}
-	embeddingStr += "]"
+	embeddingStr := formatVector(queryEmbedding)
 
 	query := `
 		SELECT 
-			e.id, e.user_id, e.category_id, e.vehicle_type, e.odometer, 
-			e.petrol_price, e.total_price, e.notes, e.timestamp, 
+			e.id, e.user_id, e.category_id, e.vehicle_type, e.vehicle_id, e.ledger_id, e.odometer, 
+			e.petrol_price, e.total_price, e.currency, e.original_amount, e.notes, e.timestamp, 
 			e.created_at, e.updated_at, e.deleted_at,
 			c.name as category_name, c.emoji as category_emoji, c."group" as category_group,
 			1 - (e.notes_embedding <=> $2::vector) as similarity
@@ -71,8 +76,8 @@ func (c *Client) FindSimilarExpenses(ctx context.Context, expenseID int64, simil
 
 	query := `
 		SELECT 
-			e.id, e.user_id, e.category_id, e.vehicle_type, e.odometer, 
-			e.petrol_price, e.total_price, e.notes, e.timestamp, 
+			e.id, e.user_id, e.category_id, e.vehicle_type, e.vehicle_id, e.ledger_id, e.odometer, 
+			e.petrol_price, e.total_price, e.currency, e.original_amount, e.notes, e.timestamp, 
 			e.created_at, e.updated_at, e.deleted_at,
 			c.name as category_name, c.emoji as category_emoji, c."group" as category_group,
 			1 - (e.notes_embedding <=> target.notes_embedding) as similarity
@@ -105,21 +110,13 @@ func (c *Client) UpdateExpenseEmbedding(ctx context.Context, expenseID int64, no
 	var notesEmbeddingStr, categoryEmbeddingStr any
 
 	if len(notesEmbedding) > 0 {
-		notesEmbeddingStr = fmt.Sprintf("[%f", notesEmbedding[0])
-		for i := 1; i < len(notesEmbedding); i++ {
-			notesEmbeddingStr = fmt.Sprintf("%s,%f", notesEmbeddingStr, notesEmbedding[i])
-		}
-		notesEmbeddingStr = fmt.Sprintf("%s]", notesEmbeddingStr)
+		notesEmbeddingStr = formatVector(notesEmbedding)
 	} else {
 		notesEmbeddingStr = nil
 	}
 
 	if len(categoryEmbedding) > 0 {
-		categoryEmbeddingStr = fmt.Sprintf("[%f", categoryEmbedding[0])
-		for i := 1; i < len(categoryEmbedding); i++ {
-			categoryEmbeddingStr = fmt.Sprintf("%s,%f", categoryEmbeddingStr, categoryEmbedding[i])
-		}
-		categoryEmbeddingStr = fmt.Sprintf("%s]", categoryEmbeddingStr)
+		categoryEmbeddingStr = formatVector(categoryEmbedding)
 	} else {
 		categoryEmbeddingStr = nil
 	}
@@ -165,3 +162,146 @@ func (c *Client) GetExpenseEmbedding(ctx context.Context, expenseID int64) (*mod
 
 	return &embedding, nil
 }
+
+// SearchExpenses returns one page of a user's expenses matching the search filters, and the number
+// of matches across all pages. With text, expenses must match it by full-text search on the notes
+// or by embedding similarity, and the rankings are combined by reciprocal rank fusion: each
+// expense scores the sum of 1/(60+rank) over the rankings it appears in. "Expensive" queries add
+// a ranking by amount. Without text, filtered expenses are listed newest or largest first.
+func (c *Client) SearchExpenses(ctx context.Context, search *models.ExpenseSearch) ([]*models.Expense, int, error) {
+	args := []any{search.UserID}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	filters := []string{"e.user_id = $1", "e.deleted_at IS NULL"}
+	if search.From != nil {
+		filters = append(filters, "e.timestamp >= "+arg(*search.From))
+	}
+	if search.To != nil {
+		filters = append(filters, "e.timestamp < "+arg(*search.To))
+	}
+	if search.MinAmount != nil {
+		filters = append(filters, "e.total_price >= "+arg(*search.MinAmount))
+	}
+	if search.MaxAmount != nil {
+		filters = append(filters, "e.total_price <= "+arg(*search.MaxAmount))
+	}
+	if search.VehicleType != "" {
+		filters = append(filters, "e.vehicle_type = "+arg(search.VehicleType))
+	}
+	var categoryFilters []string
+	if len(search.Categories) > 0 {
+		ids := make([]int64, len(search.Categories))
+		for i, category := range search.Categories {
+			ids[i] = category.ID
+		}
+		categoryFilters = append(categoryFilters, "e.category_id = ANY("+arg(pq.Array(ids))+")")
+	}
+	if len(search.Groups) > 0 {
+		categoryFilters = append(categoryFilters, `c."group" = ANY(`+arg(pq.Array(search.Groups))+")")
+	}
+	if len(categoryFilters) > 0 {
+		filters = append(filters, "("+strings.Join(categoryFilters, " OR ")+")")
+	}
+
+	// Rankings are computed over the filtered expenses only
+	ctes := []string{`filtered AS (
+			SELECT e.id, e.total_price, e.timestamp, e.notes, e.notes_embedding, e.category_embedding
+			FROM expenses e
+			JOIN categories c ON e.category_id = c.id
+			WHERE ` + strings.Join(filters, " AND ") + `)`}
+	var joins, scores, matches []string
+	rank := func(name, query string) {
+		ctes = append(ctes, name+" AS ("+query+")")
+		joins = append(joins, "LEFT JOIN "+name+" ON "+name+".id = f.id")
+		scores = append(scores, fmt.Sprintf("COALESCE(1.0 / (%d + %s.rank), 0)", rrfK, name))
+	}
+	similarity := "0"
+
+	text := strings.TrimSpace(search.Text)
+	if text != "" {
+		// Any of the words may match; ts_rank_cd favours notes that contain more of them close together
+		rank("text_hits", fmt.Sprintf(`
+			SELECT id, ROW_NUMBER() OVER (ORDER BY ts_rank_cd(to_tsvector('english', COALESCE(notes, '')), terms.q) DESC, timestamp DESC) AS rank
+			FROM filtered, (SELECT replace(plainto_tsquery('english', %s)::text, '&', '|')::tsquery AS q) terms
+			WHERE to_tsvector('english', COALESCE(notes, '')) @@ terms.q
+			ORDER BY rank
+			LIMIT %d`, arg(text), searchCandidates))
+		matches = append(matches, "text_hits.id IS NOT NULL")
+
+		if len(search.Embedding) > 0 {
+			// The better of the notes and category similarity, so "fuel" finds Petrol expenses without notes
+			rank("vector_hits", fmt.Sprintf(`
+				SELECT id, similarity, ROW_NUMBER() OVER (ORDER BY similarity DESC, timestamp DESC) AS rank
+				FROM (
+					SELECT id, timestamp, GREATEST(1 - (notes_embedding <=> %[1]s::vector), 1 - (category_embedding <=> %[1]s::vector)) AS similarity
+					FROM filtered
+				) s
+				WHERE similarity >= %[2]s
+				ORDER BY rank
+				LIMIT %[3]d`, arg(formatVector(search.Embedding)), arg(search.MinSimilarity), searchCandidates))
+			matches = append(matches, "vector_hits.id IS NOT NULL")
+			similarity = "COALESCE(vector_hits.similarity, 0)"
+		}
+	}
+	if search.ByAmount && text != "" {
+		rank("amount_hits", fmt.Sprintf(`
+			SELECT id, ROW_NUMBER() OVER (ORDER BY total_price DESC, timestamp DESC) AS rank
+			FROM filtered
+			ORDER BY rank
+			LIMIT %d`, searchCandidates))
+	}
+
+	score := "0"
+	if len(scores) > 0 {
+		score = strings.Join(scores, " + ")
+	}
+	where := ""
+	if len(matches) > 0 {
+		where = "WHERE " + strings.Join(matches, " OR ")
+	}
+	order := "e.timestamp DESC, e.id DESC"
+	if search.ByAmount {
+		order = "e.total_price DESC, " + order
+	}
+	if text != "" {
+		order = "score DESC, " + order
+	}
+
+	query := `
+		WITH ` + strings.Join(ctes, ",\n\t\t") + `
+		SELECT
+			e.id, e.user_id, e.category_id, e.vehicle_type, e.vehicle_id, e.ledger_id, e.odometer,
+			e.petrol_price, e.total_price, e.currency, e.original_amount, e.notes, e.timestamp,
+			e.created_at, e.updated_at, e.deleted_at,
+			c.name as category_name, c.emoji as category_emoji, c."group" as category_group,
+			` + similarity + ` as similarity,
+			` + score + ` as score,
+			COUNT(*) OVER () as total
+		FROM filtered f
+		JOIN expenses e ON e.id = f.id
+		JOIN categories c ON e.category_id = c.id
+		` + strings.Join(joins, "\n\t\t") + `
+		` + where + `
+		ORDER BY ` + order + `
+		LIMIT ` + arg(search.Limit) + ` OFFSET ` + arg(search.Offset)
+
+	var rows []struct {
+		models.Expense
+		Score float64 `db:"score"`
+		Total int     `db:"total"`
+	}
+	if err := c.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to search expenses: %w", err)
+	}
+
+	expenses := make([]*models.Expense, len(rows))
+	total := 0
+	for i := range rows {
+		expenses[i] = &rows[i].Expense
+		total = rows[i].Total
+	}
+	return expenses, total, nil
+}
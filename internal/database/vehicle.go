@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// VehicleStorage defines operations for a user's named vehicles
+type VehicleStorage interface {
+	CreateVehicle(ctx context.Context, vehicle *models.Vehicle) error
+	GetVehiclesByUserID(ctx context.Context, userID int64) ([]*models.Vehicle, error)
+	GetVehicleByID(ctx context.Context, id int64) (*models.Vehicle, error)
+	DeleteVehicle(ctx context.Context, id, userID int64) error
+}
+
+// CreateVehicle creates a new vehicle
+func (c *Client) CreateVehicle(ctx context.Context, vehicle *models.Vehicle) error {
+	query := `
+		INSERT INTO vehicles (user_id, name, vehicle_type, fuel_type, registration, initial_odometer)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return c.db.QueryRowxContext(ctx, query,
+		vehicle.UserID, vehicle.Name, vehicle.Type, vehicle.FuelType,
+		vehicle.Registration, vehicle.InitialOdometer).
+		Scan(&vehicle.ID, &vehicle.CreatedAt, &vehicle.UpdatedAt)
+}
+
+// GetVehiclesByUserID retrieves a user's vehicles in the order they were added
+func (c *Client) GetVehiclesByUserID(ctx context.Context, userID int64) ([]*models.Vehicle, error) {
+	var vehicles []*models.Vehicle
+	query := `SELECT * FROM vehicles WHERE user_id = $1 ORDER BY id`
+
+	if err := c.db.SelectContext(ctx, &vehicles, query, userID); err != nil {
+		return nil, err
+	}
+
+	return vehicles, nil
+}
+
+// GetVehicleByID retrieves a vehicle by ID
+func (c *Client) GetVehicleByID(ctx context.Context, id int64) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	query := `SELECT * FROM vehicles WHERE id = $1`
+
+	err := c.db.GetContext(ctx, &vehicle, query, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	return &vehicle, nil
+}
+
+// DeleteVehicle deletes a vehicle. Its expenses are kept and unlinked from it.
+func (c *Client) DeleteVehicle(ctx context.Context, id, userID int64) error {
+	query := `DELETE FROM vehicles WHERE id = $1 AND user_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Package embedding turns text into vectors for semantic search over expenses.
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dimension is the size of the vector(1536) columns that store expense embeddings
+const Dimension = 1536
+
+// Supported embedding providers
+const (
+	ProviderLocal  = "local"
+	ProviderOpenAI = "openai"
+)
+
+// ErrEmptyText is returned when there is nothing to embed
+var ErrEmptyText = errors.New("empty text provided for embedding")
+
+// Embedder turns text into a vector of Dimension floats. Vectors stored in the database and
+// query vectors must come from the same embedder, otherwise similarities are meaningless.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+	// Name identifies the embedder and model, so cached vectors are never reused across them
+	Name() string
+}
+
+// Config selects and configures an embedder
+type Config struct {
+	Provider string        // local or openai
+	URL      string        // Embeddings endpoint of an OpenAI-compatible API
+	APIKey   string        // Bearer token for the API, optional for self-hosted servers
+	Model    string        // Model name sent to the API
+	Timeout  time.Duration // Request timeout for the API
+}
+
+// New creates the embedder selected by the config. Every embedder returned checks that
+// vectors have exactly Dimension values.
+func New(cfg Config) (Embedder, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderLocal:
+		return NewLocalEmbedder(), nil
+	case ProviderOpenAI:
+		if cfg.URL == "" || cfg.Model == "" {
+			return nil, errors.New("the openai embedding provider needs a URL and a model")
+		}
+		return NewHTTPEmbedder(cfg.URL, cfg.APIKey, cfg.Model, cfg.Timeout), nil
+	}
+	return nil, fmt.Errorf("unknown embedding provider %q", cfg.Provider)
+}
+
+// ValidateDimension checks that a vector fits the embedding columns
+func ValidateDimension(vector []float32) error {
+	if len(vector) != Dimension {
+		return fmt.Errorf("embedding has %d dimensions, the database expects %d", len(vector), Dimension)
+	}
+	return nil
+}
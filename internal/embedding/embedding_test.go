@@ -0,0 +1,134 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cosine returns the cosine similarity of two normalised vectors
+func cosine(a, b []float32) float64 {
+	dot := 0.0
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestNew(t *testing.T) {
+	embedder, err := New(Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &LocalEmbedder{}, embedder)
+	assert.Equal(t, "local-v1", embedder.Name())
+
+	embedder, err = New(Config{Provider: "OpenAI", URL: "http://localhost/v1/embeddings", Model: "text-embedding-3-small"})
+	require.NoError(t, err)
+	assert.IsType(t, &HTTPEmbedder{}, embedder)
+	assert.Equal(t, "http:text-embedding-3-small", embedder.Name())
+
+	_, err = New(Config{Provider: "openai"})
+	assert.Error(t, err, "URL and model are required")
+
+	_, err = New(Config{Provider: "cohere"})
+	assert.Error(t, err)
+}
+
+func TestLocalEmbedder(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewLocalEmbedder()
+
+	petrol, err := embedder.Embed(ctx, "Petrol for the car")
+	require.NoError(t, err)
+	require.NoError(t, ValidateDimension(petrol))
+
+	norm := 0.0
+	for _, value := range petrol {
+		norm += float64(value) * float64(value)
+	}
+	assert.InDelta(t, 1, math.Sqrt(norm), 1e-6, "vectors are normalised")
+
+	again, err := embedder.Embed(ctx, "petrol FOR the car!")
+	require.NoError(t, err)
+	assert.Equal(t, petrol, again, "case, punctuation and stop words do not matter")
+
+	typo, err := embedder.Embed(ctx, "petrl car")
+	require.NoError(t, err)
+	unrelated, err := embedder.Embed(ctx, "movie tickets")
+	require.NoError(t, err)
+	assert.Greater(t, cosine(petrol, typo), 0.5)
+	assert.Less(t, cosine(petrol, unrelated), 0.2)
+	assert.Greater(t, cosine(petrol, typo), cosine(petrol, unrelated))
+
+	onlyStopWords, err := embedder.Embed(ctx, "for the")
+	require.NoError(t, err)
+	assert.Len(t, onlyStopWords, Dimension)
+
+	_, err = embedder.Embed(ctx, " !? ")
+	assert.ErrorIs(t, err, ErrEmptyText)
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	vector := make([]float32, Dimension)
+	vector[0] = 1
+
+	var received embeddingRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		switch received.Input {
+		case "short":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"embedding": []float32{1, 2, 3}}}})
+		case "fail":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached"}}`))
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"embedding": vector}}})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	embedder := NewHTTPEmbedder(server.URL, "secret", "text-embedding-3-small", time.Second)
+
+	t.Run("returns the embedding", func(t *testing.T) {
+		got, err := embedder.Embed(ctx, "  petrol  ")
+		require.NoError(t, err)
+		assert.Equal(t, vector, got)
+		assert.Equal(t, "Bearer secret", authorization)
+		assert.Equal(t, embeddingRequest{Model: "text-embedding-3-small", Input: "petrol", Dimensions: Dimension}, received)
+	})
+
+	t.Run("rejects vectors that do not fit the database", func(t *testing.T) {
+		_, err := embedder.Embed(ctx, "short")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 dimensions")
+	})
+
+	t.Run("reports API errors", func(t *testing.T) {
+		_, err := embedder.Embed(ctx, "fail")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 429: Rate limit reached")
+	})
+
+	t.Run("skips empty text without a request", func(t *testing.T) {
+		received = embeddingRequest{}
+		_, err := embedder.Embed(ctx, " ")
+		assert.ErrorIs(t, err, ErrEmptyText)
+		assert.Empty(t, received.Input)
+	})
+
+	t.Run("no API key for self-hosted servers", func(t *testing.T) {
+		_, err := NewHTTPEmbedder(server.URL, "", "nomic-embed-text", 0).Embed(ctx, "petrol")
+		require.NoError(t, err)
+		assert.Empty(t, authorization)
+	})
+}
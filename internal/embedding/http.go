@@ -0,0 +1,118 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout is used when the config sets no request timeout
+const defaultHTTPTimeout = 30 * time.Second
+
+// maxErrorBody is the most of an error response kept in the returned error
+const maxErrorBody = 1 << 10
+
+// HTTPEmbedder calls an OpenAI-compatible embeddings endpoint, such as OpenAI itself,
+// Azure OpenAI, Ollama or a self-hosted text-embeddings server
+type HTTPEmbedder struct {
+	client *http.Client
+	url    string
+	apiKey string
+	model  string
+}
+
+// embeddingRequest is the body of an embeddings request. Dimensions asks models that
+// support shortening, such as text-embedding-3-*, for vectors that fit the database.
+type embeddingRequest struct {
+	Model      string `json:"model"`
+	Input      string `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+// embeddingResponse is the body of a successful embeddings response
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// errorResponse is the body of a failed request
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewHTTPEmbedder creates an embedder for the embeddings endpoint at url
+func NewHTTPEmbedder(url, apiKey, model string, timeout time.Duration) *HTTPEmbedder {
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &HTTPEmbedder{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+	}
+}
+
+// Name identifies the embeddings model
+func (e *HTTPEmbedder) Name() string {
+	return "http:" + e.model
+}
+
+// Embed requests the embedding of text and checks that it fits the database
+func (e *HTTPEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+
+	body, err := json.Marshal(embeddingRequest{Model: e.model, Input: text, Dimensions: Dimension})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		var apiErr errorResponse
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("embedding request failed: status %d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("embedding request failed: status %d", resp.StatusCode)
+	}
+
+	var result embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid embedding response: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("invalid embedding response: no embeddings returned")
+	}
+
+	vector := result.Data[0].Embedding
+	if err := ValidateDimension(vector); err != nil {
+		return nil, fmt.Errorf("model %s: %w", e.model, err)
+	}
+	return vector, nil
+}
@@ -0,0 +1,116 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Feature weights of the local embedder. Whole words carry most of the meaning; character
+// trigrams let typos and word forms ("groceries", "grocery") land close together.
+const (
+	wordWeight    = 1.0
+	trigramWeight = 0.5
+)
+
+// stopWords carry no meaning in expense notes and get an inverse document frequency of zero
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "at": true, "for": true, "from": true, "in": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "with": true, "my": true,
+	"expense": true, "expenses": true, "spent": true, "paid": true,
+}
+
+// LocalEmbedder is a deterministic embedder that needs no network. It hashes TF-IDF weighted
+// words and character trigrams into Dimension buckets and normalises the result, so texts
+// sharing words or spellings have a high cosine similarity. It does not understand synonyms.
+type LocalEmbedder struct{}
+
+// NewLocalEmbedder creates a local embedder
+func NewLocalEmbedder() *LocalEmbedder {
+	return &LocalEmbedder{}
+}
+
+// localEmbedderName changes whenever the features change, invalidating cached vectors
+const localEmbedderName = "local-v1"
+
+// Name identifies the local embedder
+func (e *LocalEmbedder) Name() string {
+	return localEmbedderName
+}
+
+// Embed returns the normalised feature vector of text
+func (e *LocalEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	words := tokenize(text)
+	if len(words) == 0 {
+		return nil, ErrEmptyText
+	}
+
+	// Texts made only of stop words still need a vector
+	content := words[:0:0]
+	for _, word := range words {
+		if !stopWords[word] {
+			content = append(content, word)
+		}
+	}
+	if len(content) == 0 {
+		content = words
+	}
+
+	counts := make(map[string]int)
+	for _, word := range content {
+		counts["w:"+word]++
+		padded := []rune("#" + word + "#")
+		for i := 0; i+3 <= len(padded); i++ {
+			counts["c:"+string(padded[i:i+3])]++
+		}
+	}
+
+	vector := make([]float64, Dimension)
+	for feature, count := range counts {
+		// Sublinear term frequency, so repeating a word does not dominate
+		weight := (1 + math.Log(float64(count))) * trigramWeight
+		if strings.HasPrefix(feature, "w:") {
+			weight = (1 + math.Log(float64(count))) * wordWeight
+		}
+		index, sign := bucket(feature)
+		vector[index] += sign * weight
+	}
+
+	norm := 0.0
+	for _, value := range vector {
+		norm += value * value
+	}
+	norm = math.Sqrt(norm)
+	if norm == 0 {
+		return nil, ErrEmptyText
+	}
+
+	embedding := make([]float32, Dimension)
+	for i, value := range vector {
+		embedding[i] = float32(value / norm)
+	}
+	return embedding, nil
+}
+
+// tokenize lowercases text and splits it into words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// bucket hashes a feature to a vector index and a sign. The sign keeps collisions from
+// always adding up, which preserves similarities better than positive-only hashing.
+func bucket(feature string) (int, float64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	sign := 1.0
+	if sum>>63 == 1 {
+		sign = -1
+	}
+	return int(sum % Dimension), sign
+}
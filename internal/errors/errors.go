@@ -4,6 +4,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // ErrorType represents the type of error
@@ -34,7 +35,9 @@ type AppError struct {
 	Details   string    `json:"details,omitempty"`
 	RequestID string    `json:"requestId,omitempty"`
 	UserID    int64     `json:"userId,omitempty"`
-	Err       error     `json:"-"`
+	// RetryAfter is how long to wait before trying again, set on rate limit errors
+	RetryAfter time.Duration `json:"retryAfter,omitempty"`
+	Err        error         `json:"-"`
 }
 
 // NewValidationError creates a new validation error
@@ -100,6 +103,13 @@ func NewRateLimitError(message string) *AppError {
 	}
 }
 
+// NewRateLimitErrorWithRetry creates a new rate limit error telling when to try again
+func NewRateLimitErrorWithRetry(message string, retryAfter time.Duration) *AppError {
+	err := NewRateLimitError(message)
+	err.RetryAfter = retryAfter
+	return err
+}
+
 // NewUnauthorizedError creates a new unauthorized error
 func NewUnauthorizedError(message string) *AppError {
 	return &AppError{
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -116,9 +117,19 @@ func TestNewRateLimitError(t *testing.T) {
 	require.Equal(t, "Rate limit exceeded", err.Details)
 	require.Empty(t, err.RequestID)
 	require.Zero(t, err.UserID)
+	require.Zero(t, err.RetryAfter)
 	require.Nil(t, err.Err)
 }
 
+func TestNewRateLimitErrorWithRetry(t *testing.T) {
+	err := NewRateLimitErrorWithRetry("Too many requests", 30*time.Second)
+
+	require.Equal(t, ErrorTypeRateLimit, err.Type)
+	require.Equal(t, http.StatusTooManyRequests, err.Code)
+	require.Equal(t, 30*time.Second, err.RetryAfter)
+	require.True(t, err.IsRateLimitError())
+}
+
 func TestNewUnauthorizedError(t *testing.T) {
 	message := "Access denied"
 
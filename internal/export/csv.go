@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// CSVWriter writes expenses as comma-separated values
+type CSVWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+// NewCSVWriter creates a CSV writer and writes the header row
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	writer := &CSVWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(Columns)),
+	}
+	if err := writer.w.Write(Columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes one expense as a row
+func (c *CSVWriter) Write(expense *models.Expense) error {
+	for i, value := range row(expense) {
+		c.record[i] = value.text
+		if !value.numeric {
+			c.record[i] = escapeFormula(value.text)
+		}
+	}
+	return c.w.Write(c.record)
+}
+
+// escapeFormula stops spreadsheet apps from running text such as notes as a formula
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Close flushes buffered rows
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
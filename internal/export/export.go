@@ -0,0 +1,116 @@
+// Package export writes expenses to downloadable files such as CSV and XLSX.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported export formats
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat returns the format with the given name, ignoring case
+func ParseFormat(name string) (Format, bool) {
+	switch Format(strings.ToLower(name)) {
+	case FormatCSV:
+		return FormatCSV, true
+	case FormatXLSX:
+		return FormatXLSX, true
+	}
+	return "", false
+}
+
+// ContentType returns the MIME type of files in the format
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// timestampLayout is used for every date column so files sort and import cleanly
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Columns are the header row of every export
+var Columns = []string{
+	"Date", "Category", "Group", "Amount", "Currency", "Original Amount",
+	"Vehicle Type", "Odometer", "Petrol Price", "Notes", "Created At", "Updated At",
+}
+
+// Writer writes expenses as rows of an export file. Close must be called to finish the file.
+type Writer interface {
+	Write(expense *models.Expense) error
+	Close() error
+}
+
+// NewWriter creates a writer for the format that writes to w
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatXLSX:
+		return NewXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// cell is one value of an exported row
+type cell struct {
+	text    string
+	number  float64
+	numeric bool
+}
+
+// textCell creates a text cell
+func textCell(s string) cell {
+	return cell{text: s}
+}
+
+// numberCell creates a numeric cell; zero optional values are left empty
+func numberCell(f float64, optional bool) cell {
+	if optional && f == 0 {
+		return cell{}
+	}
+	return cell{text: fmt.Sprintf("%.2f", f), number: f, numeric: true}
+}
+
+// row converts an expense into cells in the order of Columns
+func row(expense *models.Expense) []cell {
+	vehicleType := ""
+	if expense.VehicleType.Valid {
+		vehicleType = expense.VehicleType.String
+	}
+
+	return []cell{
+		textCell(formatTime(expense.Timestamp)),
+		textCell(expense.CategoryName),
+		textCell(expense.CategoryGroup),
+		numberCell(expense.TotalPrice, false),
+		textCell(expense.Currency),
+		numberCell(expense.OriginalAmount, false),
+		textCell(vehicleType),
+		numberCell(expense.Odometer, true),
+		numberCell(expense.PetrolPrice, true),
+		textCell(expense.Notes),
+		textCell(formatTime(expense.CreatedAt)),
+		textCell(formatTime(expense.UpdatedAt)),
+	}
+}
+
+// formatTime formats a timestamp, leaving unset times empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(timestampLayout)
+}
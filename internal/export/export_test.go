@@ -0,0 +1,102 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExpenses() []*models.Expense {
+	created := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	return []*models.Expense{
+		{
+			CategoryName: "Petrol", CategoryGroup: "Vehicle", TotalPrice: 2000, Currency: "INR", OriginalAmount: 2000,
+			VehicleType: sql.NullString{String: "CAR", Valid: true}, Odometer: 45210, PetrolPrice: 104.5,
+			Notes: "full tank", Timestamp: created, CreatedAt: created, UpdatedAt: created,
+		},
+		{
+			CategoryName: "Hotels", CategoryGroup: "Travel", TotalPrice: 4050, Currency: "EUR", OriginalAmount: 45,
+			Notes: "=1+1 & <b>", Timestamp: created.AddDate(0, 0, 1),
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, ok := ParseFormat("XLSX")
+	assert.True(t, ok)
+	assert.Equal(t, FormatXLSX, format)
+
+	_, ok = ParseFormat("pdf")
+	assert.False(t, ok)
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	require.NoError(t, err)
+	for _, expense := range testExpenses() {
+		require.NoError(t, w.Write(expense))
+	}
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, Columns, records[0])
+	assert.Equal(t, []string{
+		"2026-03-09 10:00:00", "Petrol", "Vehicle", "2000.00", "INR", "2000.00",
+		"CAR", "45210.00", "104.50", "full tank", "2026-03-09 10:00:00", "2026-03-09 10:00:00",
+	}, records[1])
+	assert.Equal(t, "'=1+1 & <b>", records[2][9], "formulas are escaped")
+	assert.Equal(t, "", records[2][7], "missing odometer is empty")
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf)
+	require.NoError(t, err)
+	for _, expense := range testExpenses() {
+		require.NoError(t, w.Write(expense))
+	}
+	require.NoError(t, w.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	names := make(map[string]*zip.File)
+	for _, f := range reader.File {
+		names[f.Name] = f
+	}
+	for _, part := range xlsxStaticParts {
+		assert.Contains(t, names, part.name)
+	}
+	require.Contains(t, names, "xl/worksheets/sheet1.xml")
+
+	f, err := names["xl/worksheets/sheet1.xml"].Open()
+	require.NoError(t, err)
+	sheet, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	content := string(sheet)
+	assert.Contains(t, content, `<c r="A1" t="inlineStr"><is><t xml:space="preserve">Date</t></is></c>`)
+	assert.Contains(t, content, `<c r="D2"><v>2000</v></c>`)
+	assert.Contains(t, content, `<c r="J3" t="inlineStr"><is><t xml:space="preserve">=1+1 &amp; &lt;b&gt;</t></is></c>`)
+	assert.Contains(t, content, `<row r="3">`)
+	assert.NotContains(t, content, `r="H3"`, "missing odometer is left out")
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "L", columnName(11))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/database"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/metrics"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
 )
 
 // HealthChecker provides health check functionality
@@ -18,6 +20,8 @@ type HealthChecker struct {
 	database database.Storage
 	logger   logger.Logger
 	server   *http.Server
+	handlers map[string]http.Handler // Further endpoints served alongside the health checks
+	started  time.Time
 }
 
 // HealthStatus represents the health status of the application
@@ -26,19 +30,63 @@ type HealthStatus struct {
 	Timestamp time.Time `json:"timestamp"`
 	Database  string    `json:"database"`
 	Uptime    string    `json:"uptime"`
+	// EmbeddingQueue is the backlog of expenses waiting to be embedded for search
+	EmbeddingQueue *models.EmbeddingQueueStats `json:"embedding_queue,omitempty"`
 }
 
 // NewHealthChecker creates a new health checker
 func NewHealthChecker(db database.Storage, log logger.Logger) *HealthChecker {
-	return &HealthChecker{
+	h := &HealthChecker{
 		database: db,
 		logger:   log,
+		started:  time.Now(),
 	}
+	h.registerMetrics()
+	return h
+}
+
+// registerMetrics registers the gauges read by the health checker when metrics are scraped
+func (h *HealthChecker) registerMetrics() {
+	metrics.NewGaugeFunc("expense_bot_uptime_seconds", "Seconds since the application started.",
+		func(context.Context) (float64, error) {
+			return time.Since(h.started).Seconds(), nil
+		})
+	if h.database == nil {
+		return
+	}
+	metrics.NewGaugeFunc("expense_bot_embedding_jobs_pending", "Expenses waiting to be embedded for search.",
+		func(ctx context.Context) (float64, error) {
+			stats, err := h.database.GetEmbeddingQueueStats(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return float64(stats.Pending), nil
+		})
+	metrics.NewGaugeFunc("expense_bot_embedding_jobs_failed", "Expenses that could not be embedded after every retry.",
+		func(ctx context.Context) (float64, error) {
+			stats, err := h.database.GetEmbeddingQueueStats(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return float64(stats.Failed), nil
+		})
+}
+
+// Handle serves handler at pattern on the health check server, such as the Telegram webhook.
+// It must be called before Start.
+func (h *HealthChecker) Handle(pattern string, handler http.Handler) {
+	if h.handlers == nil {
+		h.handlers = make(map[string]http.Handler)
+	}
+	h.handlers[pattern] = handler
 }
 
 // Start starts the health check HTTP server
 func (h *HealthChecker) Start(ctx context.Context, port string) error {
 	mux := http.NewServeMux()
+	for pattern, handler := range h.handlers {
+		mux.Handle(pattern, handler)
+	}
 
 	// Health check endpoint
 	mux.HandleFunc("/health", h.healthHandler)
@@ -46,7 +94,7 @@ func (h *HealthChecker) Start(ctx context.Context, port string) error {
 	// Root endpoint
 	mux.HandleFunc("/", h.rootHandler)
 
-	// Metrics endpoint (placeholder for future metrics)
+	// Metrics endpoint, scraped by Prometheus
 	mux.HandleFunc("/metrics", h.metricsHandler)
 
 	h.server = &http.Server{
@@ -95,6 +143,8 @@ func (h *HealthChecker) healthHandler(w http.ResponseWriter, r *http.Request) {
 			status.Status = "unhealthy"
 			status.Database = "disconnected"
 			w.WriteHeader(http.StatusServiceUnavailable)
+		} else if stats, err := h.database.GetEmbeddingQueueStats(ctx); err == nil {
+			status.EmbeddingQueue = stats
 		}
 	} else {
 		status.Status = "unhealthy"
@@ -119,12 +169,13 @@ func (h *HealthChecker) rootHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Metrics: /metrics\n")
 }
 
-// metricsHandler handles metrics requests (placeholder)
+// metricsHandler serves the metrics in the Prometheus text format. Metrics that cannot be
+// collected, such as those read from an unreachable database, are left out and logged.
 func (h *HealthChecker) metricsHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/plain")
-	fmt.Fprintf(w, "# Expense Tracker Bot Metrics\n")
-	fmt.Fprintf(w, "# This is a placeholder for future metrics\n")
-	fmt.Fprintf(w, "app_uptime_seconds %d\n", time.Now().Unix())
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := metrics.Default.Write(r.Context(), w); err != nil && h.logger != nil {
+		h.logger.Warn(r.Context(), "Failed to collect some metrics", logger.ErrorField(err))
+	}
 }
 
 // checkDatabase performs a simple database health check
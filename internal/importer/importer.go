@@ -0,0 +1,298 @@
+// Package importer reads bank statement CSV files into transactions that can become expenses.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// MaxRows is the largest number of transactions read from one statement
+const MaxRows = 5000
+
+// minHeaderFields is the number of non-empty fields a row needs to be taken as the header
+// when no column names are known yet. Account details above the transactions have fewer.
+const minHeaderFields = 3
+
+// ErrNoHeader is returned when a statement has no row that looks like a header
+var ErrNoHeader = errors.New("no header row found")
+
+// dateLayouts are tried in order when detecting a statement's date format. Day-first layouts
+// come before month-first ones, so dates such as 03/04/2026 are read as 3 April.
+var dateLayouts = []string{
+	"2006-1-2",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2/1/2006",
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+	"2-1-2006",
+	"2.1.2006",
+	"2/1/06",
+	"2-1-06",
+	"2 Jan 2006",
+	"2-Jan-2006",
+	"2 Jan 06",
+	"2-Jan-06",
+	"Jan 2, 2006",
+	"1/2/2006",
+	"1-2-2006",
+	"2006/1/2",
+}
+
+// File is a parsed statement
+type File struct {
+	Header  []string
+	Records [][]string // Rows after the header
+}
+
+// Transaction is one statement row converted using an import profile
+type Transaction struct {
+	Date        time.Time
+	Amount      float64
+	Description string
+}
+
+// Read parses a CSV statement. The delimiter may be a comma, semicolon or tab. Banks often put
+// account details above the transactions, so the header is the first row containing all the
+// required column names, or when none are given, the first row with at least three values.
+func Read(data []byte, required ...string) (*File, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	file := &File{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if file.Header == nil {
+			if isHeader(record, required) {
+				file.Header = record
+			}
+			continue
+		}
+		if len(file.Records) == MaxRows {
+			return nil, fmt.Errorf("too many rows, at most %d transactions can be imported at a time", MaxRows)
+		}
+		file.Records = append(file.Records, record)
+	}
+
+	if file.Header == nil {
+		if len(required) > 0 {
+			return nil, fmt.Errorf("%w with the columns %s", ErrNoHeader, strings.Join(required, ", "))
+		}
+		return nil, ErrNoHeader
+	}
+	return file, nil
+}
+
+// detectDelimiter picks the most common of comma, semicolon and tab in the first lines
+func detectDelimiter(data []byte) rune {
+	sample := data
+	for i, n := 0, 0; i < len(data); i++ {
+		if data[i] == '\n' {
+			if n++; n == 20 {
+				sample = data[:i]
+				break
+			}
+		}
+	}
+
+	delimiter, best := ',', bytes.Count(sample, []byte{','})
+	for _, candidate := range []rune{';', '\t'} {
+		if count := bytes.Count(sample, []byte{byte(candidate)}); count > best {
+			delimiter, best = candidate, count
+		}
+	}
+	return delimiter
+}
+
+// isHeader reports whether a record is the header row
+func isHeader(record, required []string) bool {
+	if len(required) == 0 {
+		values := 0
+		for _, field := range record {
+			if strings.TrimSpace(field) != "" {
+				values++
+			}
+		}
+		return values >= minHeaderFields
+	}
+
+	for _, name := range required {
+		if columnIndex(record, name) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Column returns the index of the named column, ignoring case and surrounding spaces, or -1
+func (f *File) Column(name string) int {
+	return columnIndex(f.Header, name)
+}
+
+// columnIndex returns the index of the named field in a record, or -1
+func columnIndex(record []string, name string) int {
+	for i, field := range record {
+		if strings.EqualFold(strings.TrimSpace(field), strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewProfile creates a profile from zero-based column indexes and detects the date format
+// from the values of the date column
+func NewProfile(file *File, name string, dateColumn, amountColumn, descriptionColumn int) (*models.ImportProfile, error) {
+	columns := []int{dateColumn, amountColumn, descriptionColumn}
+	for i, column := range columns {
+		if column < 0 || column >= len(file.Header) {
+			return nil, fmt.Errorf("column %d does not exist, choose from 1 to %d", column+1, len(file.Header))
+		}
+		for _, other := range columns[:i] {
+			if column == other {
+				return nil, fmt.Errorf("column %d is used twice", column+1)
+			}
+		}
+	}
+
+	var dates []string
+	for _, record := range file.Records {
+		if dateColumn < len(record) {
+			dates = append(dates, record[dateColumn])
+		}
+	}
+	layout, ok := DetectDateFormat(dates)
+	if !ok {
+		return nil, fmt.Errorf("could not read the dates in column %q", file.Header[dateColumn])
+	}
+
+	return &models.ImportProfile{
+		Name:              name,
+		DateColumn:        strings.TrimSpace(file.Header[dateColumn]),
+		AmountColumn:      strings.TrimSpace(file.Header[amountColumn]),
+		DescriptionColumn: strings.TrimSpace(file.Header[descriptionColumn]),
+		DateFormat:        layout,
+	}, nil
+}
+
+// DetectDateFormat returns the first layout that parses every non-empty value. Rows such as
+// opening balances or totals have empty dates, so at least one value must parse.
+func DetectDateFormat(values []string) (string, bool) {
+	for _, layout := range dateLayouts {
+		parsed := 0
+		for _, value := range values {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if _, err := time.ParseInLocation(layout, value, time.Local); err != nil {
+				parsed = -1
+				break
+			}
+			parsed++
+		}
+		if parsed > 0 {
+			return layout, true
+		}
+	}
+	return "", false
+}
+
+// Transactions converts the rows using a profile. Rows without a valid date or amount, such as
+// balances and totals, and rows marked as credits are skipped and counted.
+func (f *File) Transactions(profile *models.ImportProfile) ([]Transaction, int, error) {
+	dateColumn, amountColumn, descriptionColumn := f.Column(profile.DateColumn), f.Column(profile.AmountColumn), f.Column(profile.DescriptionColumn)
+	if dateColumn < 0 || amountColumn < 0 || descriptionColumn < 0 {
+		return nil, 0, fmt.Errorf("the statement does not have the columns %s, %s and %s",
+			profile.DateColumn, profile.AmountColumn, profile.DescriptionColumn)
+	}
+
+	var transactions []Transaction
+	skipped := 0
+	for _, record := range f.Records {
+		if isBlank(record) {
+			continue
+		}
+		if dateColumn >= len(record) || amountColumn >= len(record) || descriptionColumn >= len(record) {
+			skipped++
+			continue
+		}
+
+		date, err := time.ParseInLocation(profile.DateFormat, strings.TrimSpace(record[dateColumn]), time.Local)
+		if err != nil {
+			skipped++
+			continue
+		}
+		amount, credit, err := ParseAmount(record[amountColumn])
+		if err != nil || credit || amount == 0 {
+			skipped++
+			continue
+		}
+
+		transactions = append(transactions, Transaction{
+			Date:        date,
+			Amount:      amount,
+			Description: strings.Join(strings.Fields(record[descriptionColumn]), " "),
+		})
+	}
+	return transactions, skipped, nil
+}
+
+// isBlank reports whether every field of a record is empty
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseAmount parses a statement amount such as "1,234.50", "₹ 450", "-450.00", "(450.00)" or
+// "450.00 Dr" and returns its absolute value. credit is true for amounts marked "Cr".
+func ParseAmount(s string) (amount float64, credit bool, err error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.HasSuffix(value, "cr"):
+		credit = true
+		value = strings.TrimSuffix(value, "cr")
+	case strings.HasSuffix(value, "dr"):
+		value = strings.TrimSuffix(value, "dr")
+	}
+
+	var digits strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' {
+			digits.WriteRune(r)
+		}
+	}
+	if digits.Len() == 0 {
+		return 0, false, fmt.Errorf("invalid amount %q", s)
+	}
+
+	amount, err = strconv.ParseFloat(digits.String(), 64)
+	if err != nil || math.IsInf(amount, 0) {
+		return 0, false, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, credit, nil
+}
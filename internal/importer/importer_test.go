@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStatement = "\xef\xbb\xbfAccount Statement,XXXX1234\n" +
+	"Period,01/03/2026 - 31/03/2026\n" +
+	"\n" +
+	"Txn Date,Narration,Withdrawal Amt,Deposit Amt,Balance\n" +
+	"01/03/2026,Opening Balance,,,\"50,000.00\"\n" +
+	"03/03/2026,UPI/SWIGGY/Dinner,450.00,,\"49,550.00\"\n" +
+	"15/03/2026,NEFT SALARY,,\"80,000.00\",\"129,550.00\"\n" +
+	"20/03/2026,  POS HP FUEL   STATION ,\"2,000.50\",,\"127,549.50\"\n" +
+	",,,,\n"
+
+func TestRead(t *testing.T) {
+	file, err := Read([]byte(testStatement))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Txn Date", "Narration", "Withdrawal Amt", "Deposit Amt", "Balance"}, file.Header)
+	assert.Len(t, file.Records, 5)
+	assert.Equal(t, 1, file.Column(" narration "))
+	assert.Equal(t, -1, file.Column("Amount"))
+
+	t.Run("required columns", func(t *testing.T) {
+		file, err := Read([]byte(testStatement), "Txn Date", "Withdrawal Amt")
+		require.NoError(t, err)
+		assert.Equal(t, "Txn Date", file.Header[0])
+
+		_, err = Read([]byte(testStatement), "Value Date")
+		assert.ErrorIs(t, err, ErrNoHeader)
+	})
+
+	t.Run("semicolon delimiter", func(t *testing.T) {
+		file, err := Read([]byte("Date;Description;Amount\n2026-03-01;Coffee;3,50\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Date", "Description", "Amount"}, file.Header)
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		_, err := Read([]byte("a,b\n1,2\n"))
+		assert.ErrorIs(t, err, ErrNoHeader)
+	})
+}
+
+func TestNewProfileAndTransactions(t *testing.T) {
+	file, err := Read([]byte(testStatement))
+	require.NoError(t, err)
+
+	profile, err := NewProfile(file, "hdfc", 0, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &models.ImportProfile{
+		Name: "hdfc", DateColumn: "Txn Date", AmountColumn: "Withdrawal Amt",
+		DescriptionColumn: "Narration", DateFormat: "2/1/2006",
+	}, profile)
+
+	transactions, skipped, err := file.Transactions(profile)
+	require.NoError(t, err)
+	assert.Equal(t, 2, skipped, "opening balance and salary have no withdrawal")
+	assert.Equal(t, []Transaction{
+		{Date: time.Date(2026, 3, 3, 0, 0, 0, 0, time.Local), Amount: 450, Description: "UPI/SWIGGY/Dinner"},
+		{Date: time.Date(2026, 3, 20, 0, 0, 0, 0, time.Local), Amount: 2000.5, Description: "POS HP FUEL STATION"},
+	}, transactions)
+
+	_, err = NewProfile(file, "hdfc", 0, 0, 1)
+	assert.Error(t, err, "same column twice")
+	_, err = NewProfile(file, "hdfc", 0, 2, 9)
+	assert.Error(t, err, "missing column")
+	_, err = NewProfile(file, "hdfc", 1, 2, 0)
+	assert.Error(t, err, "narration is not a date")
+
+	_, _, err = file.Transactions(&models.ImportProfile{DateColumn: "Date", AmountColumn: "Amount", DescriptionColumn: "Narration"})
+	assert.Error(t, err)
+}
+
+func TestDetectDateFormat(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected string
+	}{
+		{values: []string{"2026-03-09", "2026-03-10"}, expected: "2006-1-2"},
+		{values: []string{"09/03/2026", "", "25/03/2026"}, expected: "2/1/2006"},
+		{values: []string{"03/25/2026", "3/9/2026"}, expected: "1/2/2006"},
+		{values: []string{"09-Mar-2026"}, expected: "2-Jan-2006"},
+		{values: []string{"09/03/26"}, expected: "2/1/06"},
+		{values: []string{"09/03/2026 14:30"}, expected: "2/1/2006 15:04"},
+	}
+
+	for _, tt := range tests {
+		layout, ok := DetectDateFormat(tt.values)
+		assert.True(t, ok, tt.values)
+		assert.Equal(t, tt.expected, layout, tt.values)
+	}
+
+	_, ok := DetectDateFormat([]string{"", "yesterday"})
+	assert.False(t, ok)
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedAmount float64
+		expectedCredit bool
+		expectError    bool
+	}{
+		{input: "1,234.50", expectedAmount: 1234.5},
+		{input: "₹ 450", expectedAmount: 450},
+		{input: "-450.00", expectedAmount: 450},
+		{input: "(99.99)", expectedAmount: 99.99},
+		{input: "450.00 Dr", expectedAmount: 450},
+		{input: "80,000.00 CR", expectedAmount: 80000, expectedCredit: true},
+		{input: "", expectError: true},
+		{input: "n/a", expectError: true},
+		{input: "1.2.3", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, credit, err := ParseAmount(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAmount, amount)
+			assert.Equal(t, tt.expectedCredit, credit)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Package metrics collects counters, gauges and histograms and exposes them in the Prometheus
+// text exposition format, so they can be scraped from the /metrics endpoint.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Types of metrics, as written in the # TYPE line
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// ContentType is the content type of the Prometheus text format written by Registry.Write
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// maxSeries caps the label combinations kept per metric, as some label values come from users,
+// such as the commands they send; further combinations are counted with every label "other"
+const maxSeries = 100
+
+// Desc describes a metric
+type Desc struct {
+	Name string
+	Help string
+	Type string
+}
+
+// Label is a label of a sample
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one value of a metric. Suffix is appended to the metric name, as histograms do
+// for _bucket, _sum and _count.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Collector is a metric that can be exposed
+type Collector interface {
+	Describe() Desc
+	// Collect returns the current samples of the metric
+	Collect(ctx context.Context) ([]Sample, error)
+}
+
+// Registry holds the metrics exposed together. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Default is the registry the metrics created by this package are registered with
+var Default = NewRegistry()
+
+// Register adds a collector. A collector registered under a name already taken replaces the
+// previous one, so components that are created again, as in tests, take over their metrics.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors[c.Describe().Name] = c
+}
+
+// Write writes every metric in the Prometheus text format, sorted by name. Metrics that fail
+// to collect are left out and their errors returned once the others are written.
+func (r *Registry) Write(ctx context.Context, w io.Writer) error {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Describe().Name < collectors[j].Describe().Name
+	})
+
+	bw := bufio.NewWriter(w)
+	var errs []error
+	for _, c := range collectors {
+		desc := c.Describe()
+		samples, err := c.Collect(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect %s: %w", desc.Name, err))
+			continue
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n", desc.Name, escapeHelp(desc.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", desc.Name, desc.Type)
+		for _, s := range samples {
+			bw.WriteString(desc.Name)
+			bw.WriteString(s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatValue(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// writeLabels writes labels as {name="value",...}, nothing when there are none
+func writeLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(l.Name)
+		w.WriteString(`="`)
+		w.WriteString(escapeLabelValue(l.Value))
+		w.WriteByte('"')
+	}
+	w.WriteByte('}')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes backslashes and line feeds in help text
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+// escapeLabelValue escapes backslashes, line feeds and double quotes in label values
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// formatValue formats a sample value, with infinities and NaN spelled as Prometheus expects
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// series keeps the label combinations of a metric, capped at maxSeries
+type series struct {
+	names []string
+	keys  map[string][]Label
+}
+
+func newSeries(names []string) series {
+	return series{names: names, keys: make(map[string][]Label)}
+}
+
+// key returns the key of the combination of label values, adding it when it is new. Missing
+// values are empty and extra ones ignored.
+func (s *series) key(values []string) string {
+	labels := s.labels(values)
+	key := seriesKey(labels)
+	if _, ok := s.keys[key]; ok {
+		return key
+	}
+	if len(s.keys) >= maxSeries {
+		for i := range labels {
+			labels[i].Value = "other"
+		}
+		key = seriesKey(labels)
+	}
+	s.keys[key] = labels
+	return key
+}
+
+// lookup returns the key of the combination of label values without adding it
+func (s *series) lookup(values []string) string {
+	return seriesKey(s.labels(values))
+}
+
+// labels pairs the label names with values
+func (s *series) labels(values []string) []Label {
+	labels := make([]Label, len(s.names))
+	for i, name := range s.names {
+		labels[i].Name = name
+		if i < len(values) {
+			labels[i].Value = values[i]
+		}
+	}
+	return labels
+}
+
+// sorted returns the keys in order, so samples are written in the same order every time
+func (s *series) sorted() []string {
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// seriesKey joins label values with a byte that cannot appear in valid UTF-8
+func seriesKey(labels []Label) string {
+	values := make([]string, len(labels))
+	for i, l := range labels {
+		values[i] = l.Value
+	}
+	return strings.Join(values, "\xff")
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write collects the registry the way a scrape would
+func write(t *testing.T, r *Registry) (string, error) {
+	t.Helper()
+	var b strings.Builder
+	err := r.Write(context.Background(), &b)
+	return b.String(), err
+}
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+
+	updates := NewCounter("test_updates_total", "Updates handled.", "kind")
+	updates.Inc("/add")
+	updates.Inc("/add")
+	updates.Add(0.5, `say "hi"\`+"\n")
+	r.Register(updates)
+
+	r.Register(NewGaugeFunc("test_queue_depth", "Updates waiting\nto be handled.", func(context.Context) (float64, error) {
+		return 3, nil
+	}))
+
+	duration := NewHistogram("test_duration_seconds", "How long it took.", []float64{0.1, 1}, "kind")
+	duration.Observe(0.05, "/add")
+	duration.Observe(0.1, "/add")
+	duration.Observe(5, "/add")
+	r.Register(duration)
+
+	out, err := write(t, r)
+	require.NoError(t, err)
+	assert.Equal(t, `# HELP test_duration_seconds How long it took.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{kind="/add",le="0.1"} 2
+test_duration_seconds_bucket{kind="/add",le="1"} 2
+test_duration_seconds_bucket{kind="/add",le="+Inf"} 3
+test_duration_seconds_sum{kind="/add"} 5.15
+test_duration_seconds_count{kind="/add"} 3
+# HELP test_queue_depth Updates waiting\nto be handled.
+# TYPE test_queue_depth gauge
+test_queue_depth 3
+# HELP test_updates_total Updates handled.
+# TYPE test_updates_total counter
+test_updates_total{kind="/add"} 2
+test_updates_total{kind="say \"hi\"\\\n"} 0.5
+`, out)
+
+	assert.Equal(t, 2.0, updates.Value("/add"))
+	assert.Zero(t, updates.Value("/list"))
+	assert.Equal(t, uint64(3), duration.Count("/add"))
+	assert.Zero(t, duration.Count("/list"))
+}
+
+func TestRegistry_WriteSkipsFailedCollectors(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeFunc("test_broken", "Fails.", func(context.Context) (float64, error) {
+		return 0, errors.New("database is down")
+	}))
+	r.Register(NewGaugeFunc("test_working", "Works.", func(context.Context) (float64, error) {
+		return 1, nil
+	}))
+
+	out, err := write(t, r)
+	assert.ErrorContains(t, err, "failed to collect test_broken: database is down")
+	assert.Equal(t, "# HELP test_working Works.\n# TYPE test_working gauge\ntest_working 1\n", out)
+}
+
+func TestRegistry_RegisterReplaces(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeFunc("test_gauge", "First.", func(context.Context) (float64, error) { return 1, nil }))
+	r.Register(NewGaugeFunc("test_gauge", "Second.", func(context.Context) (float64, error) { return 2, nil }))
+
+	out, err := write(t, r)
+	require.NoError(t, err)
+	assert.Equal(t, "# HELP test_gauge Second.\n# TYPE test_gauge gauge\ntest_gauge 2\n", out)
+}
+
+func TestCounter_CapsSeries(t *testing.T) {
+	c := NewCounter("test_capped_total", "Capped.", "kind")
+	for i := 0; i < maxSeries+10; i++ {
+		c.Inc(fmt.Sprintf("/cmd%d", i))
+	}
+	c.Inc("/cmd0")
+
+	samples, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, samples, maxSeries+1)
+	assert.Equal(t, 10.0, c.Value("other"))
+	assert.Equal(t, 2.0, c.Value("/cmd0"), "known series keep counting")
+
+	c.Add(-1, "/cmd0")
+	assert.Equal(t, 2.0, c.Value("/cmd0"), "counters never go down")
+}
@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets, in seconds, for calls that usually take milliseconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Counter is a value that only goes up, kept per combination of its labels
+type Counter struct {
+	desc Desc
+
+	mu     sync.Mutex
+	series series
+	values map[string]float64
+}
+
+// NewCounter creates a counter and registers it with the Default registry
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		desc:   Desc{Name: name, Help: help, Type: TypeCounter},
+		series: newSeries(labels),
+		values: make(map[string]float64),
+	}
+	Default.Register(c)
+	return c
+}
+
+// Inc adds one to the counter of the label values, given in the order of the label names
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the counter of the label values
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[c.series.key(values)] += v
+}
+
+// Value returns the counter of the label values
+func (c *Counter) Value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[c.series.lookup(values)]
+}
+
+// Describe implements Collector
+func (c *Counter) Describe() Desc {
+	return c.desc
+}
+
+// Collect implements Collector
+func (c *Counter) Collect(context.Context) ([]Sample, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	samples := make([]Sample, 0, len(c.values))
+	for _, key := range c.series.sorted() {
+		samples = append(samples, Sample{Labels: c.series.keys[key], Value: c.values[key]})
+	}
+	return samples, nil
+}
+
+// GaugeFunc is a value read when the metrics are collected, such as the size of a queue
+type GaugeFunc struct {
+	desc Desc
+	read func(ctx context.Context) (float64, error)
+}
+
+// NewGaugeFunc creates a gauge read by read and registers it with the Default registry
+func NewGaugeFunc(name, help string, read func(ctx context.Context) (float64, error)) *GaugeFunc {
+	g := &GaugeFunc{desc: Desc{Name: name, Help: help, Type: TypeGauge}, read: read}
+	Default.Register(g)
+	return g
+}
+
+// Describe implements Collector
+func (g *GaugeFunc) Describe() Desc {
+	return g.desc
+}
+
+// Collect implements Collector
+func (g *GaugeFunc) Collect(ctx context.Context) ([]Sample, error) {
+	v, err := g.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []Sample{{Value: v}}, nil
+}
+
+// Histogram counts observations, such as how long requests took, in buckets per combination
+// of its labels
+type Histogram struct {
+	desc    Desc
+	buckets []float64 // Upper bounds, ascending
+
+	mu     sync.Mutex
+	series series
+	values map[string]*histogramValues
+}
+
+// histogramValues are the observations of one label combination
+type histogramValues struct {
+	counts []uint64 // Observations per bucket, not cumulative; the last is above every bound
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates a histogram with buckets, DefaultBuckets when nil, and registers it
+// with the Default registry
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &Histogram{
+		desc:    Desc{Name: name, Help: help, Type: TypeHistogram},
+		buckets: buckets,
+		series:  newSeries(labels),
+		values:  make(map[string]*histogramValues),
+	}
+	Default.Register(h)
+	return h
+}
+
+// Observe records v in the histogram of the label values
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := h.series.key(values)
+	hv := h.values[key]
+	if hv == nil {
+		hv = &histogramValues{counts: make([]uint64, len(h.buckets)+1)}
+		h.values[key] = hv
+	}
+	i := 0
+	for i < len(h.buckets) && v > h.buckets[i] {
+		i++
+	}
+	hv.counts[i]++
+	hv.sum += v
+	hv.count++
+}
+
+// Count returns how many observations the histogram of the label values has
+func (h *Histogram) Count(values ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hv := h.values[h.series.lookup(values)]; hv != nil {
+		return hv.count
+	}
+	return 0
+}
+
+// Describe implements Collector
+func (h *Histogram) Describe() Desc {
+	return h.desc
+}
+
+// Collect implements Collector
+func (h *Histogram) Collect(context.Context) ([]Sample, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := make([]Sample, 0, len(h.values)*(len(h.buckets)+3))
+	for _, key := range h.series.sorted() {
+		hv := h.values[key]
+		if hv == nil {
+			continue
+		}
+		labels := h.series.keys[key]
+		var cumulative uint64
+		for i, count := range hv.counts {
+			cumulative += count
+			le := math.Inf(1)
+			if i < len(h.buckets) {
+				le = h.buckets[i]
+			}
+			bucketLabels := append(append(make([]Label, 0, len(labels)+1), labels...), Label{Name: "le", Value: formatValue(le)})
+			samples = append(samples, Sample{Suffix: "_bucket", Labels: bucketLabels, Value: float64(cumulative)})
+		}
+		samples = append(samples,
+			Sample{Suffix: "_sum", Labels: labels, Value: hv.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(hv.count)})
+	}
+	return samples, nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Attachment is a receipt photo or PDF attached to an expense. The file is kept in the blob
+// store under StorageKey.
+type Attachment struct {
+	ID          int64     `db:"id"           json:"id"`
+	ExpenseID   int64     `db:"expense_id"   json:"expenseId"`
+	UserID      int64     `db:"user_id"      json:"userId"`
+	StorageKey  string    `db:"storage_key"  json:"storageKey"`
+	FileName    string    `db:"file_name"    json:"fileName"`
+	ContentType string    `db:"content_type" json:"contentType"`
+	SizeBytes   int64     `db:"size_bytes"   json:"sizeBytes"`
+	CreatedAt   time.Time `db:"created_at"   json:"createdAt"`
+}
+
+// IsImage reports whether the attachment is a photo rather than a document such as a PDF
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
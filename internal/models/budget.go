@@ -0,0 +1,117 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BudgetPeriod represents the length of a budget cycle
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   BudgetPeriod = "daily"
+	BudgetPeriodWeekly  BudgetPeriod = "weekly"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+	BudgetPeriodYearly  BudgetPeriod = "yearly"
+)
+
+// BudgetPeriods returns all supported budget periods in display order
+func BudgetPeriods() []BudgetPeriod {
+	return []BudgetPeriod{
+		BudgetPeriodDaily,
+		BudgetPeriodWeekly,
+		BudgetPeriodMonthly,
+		BudgetPeriodYearly,
+	}
+}
+
+// Range returns the start and end of the period that contains t.
+// Weeks start on Monday. The end is the last second of the period.
+func (p BudgetPeriod) Range(t time.Time) (time.Time, time.Time) {
+	var start, next time.Time
+	switch p {
+	case BudgetPeriodDaily:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		next = start.AddDate(0, 0, 1)
+	case BudgetPeriodWeekly:
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		start = time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+		next = start.AddDate(0, 0, 7)
+	case BudgetPeriodYearly:
+		start = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+		next = start.AddDate(1, 0, 0)
+	default:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		next = start.AddDate(0, 1, 0)
+	}
+	return start, next.Add(-time.Second)
+}
+
+// Budget represents a spending budget for a period
+type Budget struct {
+	ID         int64         `db:"id"          json:"id"`
+	UserID     int64         `db:"user_id"     json:"userId"`
+	CategoryID sql.NullInt64 `db:"category_id" json:"categoryId"` // NULL for an overall budget
+	Amount     float64       `db:"amount"      json:"amount"`
+	Period     BudgetPeriod  `db:"period"      json:"period"`
+	StartDate  time.Time     `db:"start_date"  json:"startDate"`
+	EndDate    *time.Time    `db:"end_date"    json:"endDate,omitempty"`
+	IsActive   bool          `db:"is_active"   json:"isActive"`
+	CreatedAt  time.Time     `db:"created_at"  json:"createdAt"`
+	UpdatedAt  time.Time     `db:"updated_at"  json:"updatedAt"`
+
+	// Category limits loaded from budget_limits
+	Limits []*BudgetLimit `db:"-" json:"limits,omitempty"`
+}
+
+// BudgetLimit represents a per-category limit within a budget
+type BudgetLimit struct {
+	ID          int64     `db:"id"           json:"id"`
+	BudgetID    int64     `db:"budget_id"    json:"budgetId"`
+	CategoryID  int64     `db:"category_id"  json:"categoryId"`
+	LimitAmount float64   `db:"limit_amount" json:"limitAmount"`
+	CreatedAt   time.Time `db:"created_at"   json:"createdAt"`
+	UpdatedAt   time.Time `db:"updated_at"   json:"updatedAt"`
+
+	// Joined fields from categories table
+	CategoryName  string `db:"category_name"  json:"categoryName"`
+	CategoryEmoji string `db:"category_emoji" json:"categoryEmoji"`
+}
+
+// BudgetProgress represents spending against a budget for its current period
+type BudgetProgress struct {
+	Budget      *Budget
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Spent       float64
+	Categories  []*CategoryProgress
+}
+
+// CategoryProgress represents spending against a single category limit
+type CategoryProgress struct {
+	CategoryName  string
+	CategoryEmoji string
+	Limit         float64
+	Spent         float64
+}
+
+// Remaining returns the amount left in the budget (negative when overspent)
+func (p *BudgetProgress) Remaining() float64 {
+	return p.Budget.Amount - p.Spent
+}
+
+// Percent returns the share of the budget that has been spent
+func (p *BudgetProgress) Percent() float64 {
+	if p.Budget.Amount <= 0 {
+		return 0
+	}
+	return p.Spent / p.Budget.Amount * 100
+}
+
+// Percent returns the share of the category limit that has been spent
+func (c *CategoryProgress) Percent() float64 {
+	if c.Limit <= 0 {
+		return 0
+	}
+	return c.Spent / c.Limit * 100
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// EmbeddingJob is a queued request to embed an expense's notes and category in the background
+type EmbeddingJob struct {
+	ExpenseID  int64     `db:"expense_id"  json:"expenseId"`
+	Attempts   int       `db:"attempts"    json:"attempts"` // Including the attempt in progress
+	EnqueuedAt time.Time `db:"enqueued_at" json:"enqueuedAt"`
+	LastError  string    `db:"last_error"  json:"lastError"`
+}
+
+// EmbeddingQueueStats is the size of the embedding backlog
+type EmbeddingQueueStats struct {
+	Pending int64 `db:"pending" json:"pending"` // Jobs waiting for a worker or a retry
+	Failed  int64 `db:"failed"  json:"failed"`  // Jobs that ran out of attempts
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ExchangeRate represents a daily conversion rate where 1 BaseCurrency = Rate QuoteCurrency
+type ExchangeRate struct {
+	ID            int64     `db:"id"             json:"id"`
+	BaseCurrency  string    `db:"base_currency"  json:"baseCurrency"`
+	QuoteCurrency string    `db:"quote_currency" json:"quoteCurrency"`
+	Rate          float64   `db:"rate"           json:"rate"`
+	RateDate      time.Time `db:"rate_date"      json:"rateDate"`
+	Source        string    `db:"source"         json:"source"` // manual or csv
+	CreatedAt     time.Time `db:"created_at"     json:"createdAt"`
+	UpdatedAt     time.Time `db:"updated_at"     json:"updatedAt"`
+}
+
+// Exchange rate sources
+const (
+	ExchangeRateSourceManual = "manual"
+	ExchangeRateSourceCSV    = "csv"
+)
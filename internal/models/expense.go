@@ -35,6 +35,17 @@ const (
 	StepConfirmDelete
 	StepSearchExpense
 	StepNone
+	StepBudgetAmount
+	StepBudgetLimits
+	StepRecurringDetails
+	StepReminderDetails
+	StepRatesImport
+	StepQuickAddConfirm
+	StepImportFile
+	StepImportMapping
+	StepImportConfirm
+	StepSplitDetails
+	StepAttachReceipts
 )
 
 // User represents a Telegram user
@@ -48,30 +59,42 @@ type User struct {
 	UpdatedAt  time.Time `db:"updated_at"  json:"updatedAt"`
 }
 
-// Category represents an expense category
+// Category represents an expense category. Built-in categories have no UserID; custom
+// categories belong to one user.
 type Category struct {
-	ID        int64     `db:"id"         json:"id"`
-	Name      string    `db:"name"       json:"name"`
-	Emoji     string    `db:"emoji"      json:"emoji"`
-	Group     string    `db:"group"      json:"group"`
-	CreatedAt time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+	ID        int64         `db:"id"         json:"id"`
+	UserID    sql.NullInt64 `db:"user_id"    json:"userId"`
+	Name      string        `db:"name"       json:"name"`
+	Emoji     string        `db:"emoji"      json:"emoji"`
+	Group     string        `db:"group"      json:"group"`
+	Archived  bool          `db:"archived"   json:"archived"`
+	CreatedAt time.Time     `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time     `db:"updated_at" json:"updatedAt"`
+}
+
+// IsCustom reports whether the category was added by a user
+func (c *Category) IsCustom() bool {
+	return c.UserID.Valid
 }
 
 // Expense represents an expense record
 type Expense struct {
-	ID          int64          `db:"id"           json:"id"`
-	UserID      int64          `db:"user_id"      json:"userId"`
-	CategoryID  int64          `db:"category_id"  json:"categoryId"`
-	VehicleType sql.NullString `db:"vehicle_type" json:"vehicleType"` // CAR/BIKE or NULL
-	Odometer    float64        `db:"odometer"     json:"odometer"`    // Optional
-	PetrolPrice float64        `db:"petrol_price" json:"petrolPrice"` // Optional
-	TotalPrice  float64        `db:"total_price"  json:"totalPrice"`
-	Notes       string         `db:"notes"        json:"notes,omitempty"`
-	Timestamp   time.Time      `db:"timestamp"    json:"timestamp"`
-	CreatedAt   time.Time      `db:"created_at"   json:"createdAt"`
-	UpdatedAt   time.Time      `db:"updated_at"   json:"updatedAt"`
-	DeletedAt   *time.Time     `db:"deleted_at"   json:"deletedAt,omitempty"`
+	ID             int64          `db:"id"              json:"id"`
+	UserID         int64          `db:"user_id"         json:"userId"`
+	CategoryID     int64          `db:"category_id"     json:"categoryId"`
+	VehicleType    sql.NullString `db:"vehicle_type"    json:"vehicleType"`    // Type of the vehicle, or NULL
+	VehicleID      sql.NullInt64  `db:"vehicle_id"      json:"vehicleId"`      // Vehicle the expense is for, or NULL
+	LedgerID       sql.NullInt64  `db:"ledger_id"       json:"ledgerId"`       // Shared ledger the expense is in, or NULL for a personal expense
+	Odometer       float64        `db:"odometer"        json:"odometer"`       // Optional
+	PetrolPrice    float64        `db:"petrol_price"    json:"petrolPrice"`    // Price per litre, or per kWh for electric vehicles
+	TotalPrice     float64        `db:"total_price"     json:"totalPrice"`     // In the user's home currency when recorded
+	Currency       string         `db:"currency"        json:"currency"`       // ISO code of the amount as paid
+	OriginalAmount float64        `db:"original_amount" json:"originalAmount"` // Amount as paid, in Currency
+	Notes          string         `db:"notes"           json:"notes,omitempty"`
+	Timestamp      time.Time      `db:"timestamp"       json:"timestamp"`
+	CreatedAt      time.Time      `db:"created_at"      json:"createdAt"`
+	UpdatedAt      time.Time      `db:"updated_at"      json:"updatedAt"`
+	DeletedAt      *time.Time     `db:"deleted_at"      json:"deletedAt,omitempty"`
 
 	// Vector embeddings
 	NotesEmbedding    Float32Vector `db:"notes_embedding"    json:"notesEmbedding,omitempty"`
@@ -85,6 +108,12 @@ type Expense struct {
 	CategoryGroup string `db:"category_group" json:"categoryGroup"`
 }
 
+// IsFuel reports whether the expense is a fuel fill or an EV charge. Older expenses may carry
+// the emoji in the category name.
+func (e *Expense) IsFuel() bool {
+	return e.CategoryName == "Petrol" || e.CategoryName == "EV Charging" || e.CategoryName == string(CategoryPetrol)
+}
+
 // ExpenseStats represents expense statistics for a user
 type ExpenseStats struct {
 	TotalExpenses    int64     `db:"total_expenses"     json:"totalExpenses"`
@@ -94,6 +123,8 @@ type ExpenseStats struct {
 	MaxExpense       float64   `db:"max_expense"        json:"maxExpense"`
 	FirstExpenseDate time.Time `db:"first_expense_date" json:"firstExpenseDate"`
 	LastExpenseDate  time.Time `db:"last_expense_date"  json:"lastExpenseDate"`
+	Currency         string    `db:"-"                  json:"currency"`    // Currency the amounts are in
+	Unconverted      int       `db:"-"                  json:"unconverted"` // Expenses with no exchange rate to Currency, counted at the amount recorded at entry
 }
 
 // ExpenseEmbedding represents the vector embeddings for an expense
@@ -108,8 +139,10 @@ type ExpenseEmbedding struct {
 type VehicleType string
 
 const (
-	VehicleTypeCar  VehicleType = "CAR"
-	VehicleTypeBike VehicleType = "BIKE"
+	VehicleTypeCar     VehicleType = "CAR"
+	VehicleTypeBike    VehicleType = "BIKE"
+	VehicleTypeScooter VehicleType = "SCOOTER"
+	VehicleTypeOther   VehicleType = "OTHER"
 )
 
 // CategoryType represents the type of expense
@@ -181,11 +214,21 @@ const (
 	CategoryOther CategoryType = "📌 Other"
 )
 
-// CategoryGroup represents a group of related categories
+// CategoryGroup represents a group of related categories. Built-in groups have no UserID.
 type CategoryGroup struct {
-	Name       string
-	Emoji      string
-	Categories []CategoryType
+	ID         int64          `db:"id"         json:"id"`
+	UserID     sql.NullInt64  `db:"user_id"    json:"userId"`
+	Name       string         `db:"name"       json:"name"`
+	Emoji      string         `db:"emoji"      json:"emoji"`
+	Position   int            `db:"position"   json:"position"`
+	CreatedAt  time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updatedAt"`
+	Categories []CategoryType `db:"-"          json:"-"`
+}
+
+// IsCustom reports whether the group was added by a user
+func (g *CategoryGroup) IsCustom() bool {
+	return g.UserID.Valid
 }
 
 // GetCategoryGroups returns all category groups
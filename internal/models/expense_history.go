@@ -0,0 +1,105 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// HistoryAction is a kind of change recorded in an expense's history
+type HistoryAction string
+
+// History actions
+const (
+	HistoryActionCreate  HistoryAction = "CREATE"
+	HistoryActionUpdate  HistoryAction = "UPDATE"
+	HistoryActionDelete  HistoryAction = "DELETE"
+	HistoryActionRestore HistoryAction = "RESTORE"
+	HistoryActionImport  HistoryAction = "IMPORT" // Added by a bank statement import, which /undo leaves alone
+)
+
+// ExpenseHistory is one change to an expense, with the expense as it was before and after
+type ExpenseHistory struct {
+	ID        int64            `db:"id"              json:"id"`
+	ExpenseID int64            `db:"expense_id"      json:"expenseId"`
+	UserID    int64            `db:"user_id"         json:"userId"` // User who made the change
+	Action    HistoryAction    `db:"action"          json:"action"`
+	Before    *ExpenseSnapshot `db:"before_snapshot" json:"before"`   // nil for creates
+	After     *ExpenseSnapshot `db:"after_snapshot"  json:"after"`    // nil for deletes
+	UndoOf    sql.NullInt64    `db:"undo_of"         json:"undoOf"`   // Change this one reverted with /undo
+	UndoneAt  *time.Time       `db:"undone_at"       json:"undoneAt"` // When this change was reverted
+	CreatedAt time.Time        `db:"created_at"      json:"createdAt"`
+
+	// Joined from users
+	ActorName string `db:"actor_name" json:"actorName"`
+}
+
+// ExpenseSnapshot is the state of an expense recorded in its history
+type ExpenseSnapshot struct {
+	CategoryID     int64     `json:"categoryId"`
+	CategoryName   string    `json:"categoryName"`
+	VehicleType    string    `json:"vehicleType,omitempty"`
+	VehicleID      int64     `json:"vehicleId,omitempty"`
+	Odometer       float64   `json:"odometer,omitempty"`
+	PetrolPrice    float64   `json:"petrolPrice,omitempty"`
+	TotalPrice     float64   `json:"totalPrice"`
+	Currency       string    `json:"currency"`
+	OriginalAmount float64   `json:"originalAmount"`
+	Notes          string    `json:"notes,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// NewExpenseSnapshot records the fields of an expense that can change
+func NewExpenseSnapshot(e *Expense) *ExpenseSnapshot {
+	return &ExpenseSnapshot{
+		CategoryID:     e.CategoryID,
+		CategoryName:   e.CategoryName,
+		VehicleType:    e.VehicleType.String,
+		VehicleID:      e.VehicleID.Int64,
+		Odometer:       e.Odometer,
+		PetrolPrice:    e.PetrolPrice,
+		TotalPrice:     e.TotalPrice,
+		Currency:       e.Currency,
+		OriginalAmount: e.OriginalAmount,
+		Notes:          e.Notes,
+		Timestamp:      e.Timestamp,
+	}
+}
+
+// ApplyTo sets the fields of an expense back to the snapshot. The ledger is left alone, as
+// expenses do not move between ledgers once added.
+func (s *ExpenseSnapshot) ApplyTo(e *Expense) {
+	e.CategoryID = s.CategoryID
+	e.CategoryName = s.CategoryName
+	e.VehicleType = sql.NullString{String: s.VehicleType, Valid: s.VehicleType != ""}
+	e.VehicleID = sql.NullInt64{Int64: s.VehicleID, Valid: s.VehicleID != 0}
+	e.Odometer = s.Odometer
+	e.PetrolPrice = s.PetrolPrice
+	e.TotalPrice = s.TotalPrice
+	e.Currency = s.Currency
+	e.OriginalAmount = s.OriginalAmount
+	e.Notes = s.Notes
+	e.Timestamp = s.Timestamp
+}
+
+// Scan implements the sql.Scanner interface for ExpenseSnapshot
+func (s *ExpenseSnapshot) Scan(src any) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, s)
+	case string:
+		return json.Unmarshal([]byte(data), s)
+	default:
+		return fmt.Errorf("cannot scan %T into ExpenseSnapshot", src)
+	}
+}
+
+// Value implements the driver.Valuer interface for ExpenseSnapshot
+func (s *ExpenseSnapshot) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// DefaultImportProfile is the profile used when /import is given no bank name
+const DefaultImportProfile = "default"
+
+// ImportProfile remembers how a bank's CSV statement maps to expense fields.
+// Columns are stored by header name so reordered or extra columns do not matter.
+type ImportProfile struct {
+	ID                int64     `db:"id"                 json:"id"`
+	UserID            int64     `db:"user_id"            json:"userId"`
+	Name              string    `db:"name"               json:"name"`
+	DateColumn        string    `db:"date_column"        json:"dateColumn"`
+	AmountColumn      string    `db:"amount_column"      json:"amountColumn"`
+	DescriptionColumn string    `db:"description_column" json:"descriptionColumn"`
+	DateFormat        string    `db:"date_format"        json:"dateFormat"` // Go time layout
+	CreatedAt         time.Time `db:"created_at"         json:"createdAt"`
+	UpdatedAt         time.Time `db:"updated_at"         json:"updatedAt"`
+}
+
+// ImportPreview is the result of reading a statement before anything is saved
+type ImportPreview struct {
+	Profile    *ImportProfile
+	Expenses   []*Expense // New expenses with suggested categories
+	Duplicates int        // Rows matching an existing expense by date, amount and notes
+	Skipped    int        // Rows without a valid date or amount, or marked as credits
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LedgerRole is what a member may do in a shared ledger
+type LedgerRole string
+
+const (
+	LedgerRoleOwner  LedgerRole = "OWNER"
+	LedgerRoleEditor LedgerRole = "EDITOR"
+	LedgerRoleViewer LedgerRole = "VIEWER"
+)
+
+// CanEdit reports whether the role may add, edit and delete expenses in the ledger
+func (r LedgerRole) CanEdit() bool {
+	return r == LedgerRoleOwner || r == LedgerRoleEditor
+}
+
+// Ledger is a set of expenses shared by several users. Expenses stay attributed to the member
+// who paid.
+type Ledger struct {
+	ID         int64         `db:"id"          json:"id"`
+	Name       string        `db:"name"        json:"name"`
+	InviteCode string        `db:"invite_code" json:"inviteCode"`
+	ChatID     sql.NullInt64 `db:"chat_id"     json:"chatId"` // Group chat whose expenses go to this ledger
+	CreatedBy  sql.NullInt64 `db:"created_by"  json:"createdBy"`
+	CreatedAt  time.Time     `db:"created_at"  json:"createdAt"`
+	UpdatedAt  time.Time     `db:"updated_at"  json:"updatedAt"`
+
+	// The requesting user's membership, when loaded for a user
+	Role   LedgerRole `db:"role"   json:"role,omitempty"`
+	Active bool       `db:"active" json:"active,omitempty"`
+}
+
+// LedgerMember is a user's membership of a ledger
+type LedgerMember struct {
+	LedgerID  int64      `db:"ledger_id"  json:"ledgerId"`
+	UserID    int64      `db:"user_id"    json:"userId"`
+	Role      LedgerRole `db:"role"       json:"role"`
+	Active    bool       `db:"active"     json:"active"`
+	JoinedAt  time.Time  `db:"joined_at"  json:"joinedAt"`
+	Username  string     `db:"username"   json:"username,omitempty"`
+	FirstName string     `db:"first_name" json:"firstName,omitempty"`
+}
+
+// DisplayName returns the member's first name, else their @username
+func (m *LedgerMember) DisplayName() string {
+	switch {
+	case m.FirstName != "":
+		return m.FirstName
+	case m.Username != "":
+		return "@" + m.Username
+	default:
+		return "Member"
+	}
+}
+
+// LedgerTotal is the spending of one member or category in a ledger report
+type LedgerTotal struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+	Count  int     `json:"count"`
+}
+
+// LedgerReport is the combined spending of a ledger's members over a period, in Currency
+type LedgerReport struct {
+	Ledger     *Ledger       `json:"ledger"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	Currency   string        `json:"currency"`
+	Total      float64       `json:"total"`
+	Count      int           `json:"count"`
+	ByMember   []LedgerTotal `json:"byMember"`
+	ByCategory []LedgerTotal `json:"byCategory"`
+}
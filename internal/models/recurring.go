@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// RecurringInterval represents how often a recurring expense repeats
+type RecurringInterval string
+
+const (
+	RecurringDaily   RecurringInterval = "daily"
+	RecurringWeekly  RecurringInterval = "weekly"
+	RecurringMonthly RecurringInterval = "monthly"
+	RecurringYearly  RecurringInterval = "yearly"
+)
+
+// Next returns the due date that follows t.
+// Monthly and yearly intervals fall on anchorDay, clamped to the last day of
+// shorter months, so an EMI due on the 31st is due on 28 Feb and then 31 Mar.
+// An anchorDay of zero uses the day of t.
+func (i RecurringInterval) Next(t time.Time, anchorDay int) time.Time {
+	if anchorDay <= 0 {
+		anchorDay = t.Day()
+	}
+
+	switch i {
+	case RecurringDaily:
+		return t.AddDate(0, 0, 1)
+	case RecurringWeekly:
+		return t.AddDate(0, 0, 7)
+	case RecurringYearly:
+		return addMonthsClamped(t, 12, anchorDay)
+	default:
+		return addMonthsClamped(t, 1, anchorDay)
+	}
+}
+
+// addMonthsClamped moves t forward by months onto day, without overflowing into the following month
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	firstOfTarget := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfTarget.AddDate(0, 0, day-1)
+}
+
+// RecurringExpense represents an expense that is added automatically on a schedule
+type RecurringExpense struct {
+	ID         int64             `db:"id"          json:"id"`
+	UserID     int64             `db:"user_id"     json:"userId"`
+	CategoryID int64             `db:"category_id" json:"categoryId"`
+	Amount     float64           `db:"amount"      json:"amount"`
+	Interval   RecurringInterval `db:"interval"    json:"interval"`
+	NextDue    time.Time         `db:"next_due"    json:"nextDue"`
+	AnchorDay  int               `db:"anchor_day"  json:"anchorDay"` // Day of month for monthly/yearly intervals
+	Notes      string            `db:"notes"       json:"notes,omitempty"`
+	IsActive   bool              `db:"is_active"   json:"isActive"`
+	CreatedAt  time.Time         `db:"created_at"  json:"createdAt"`
+	UpdatedAt  time.Time         `db:"updated_at"  json:"updatedAt"`
+
+	// Joined fields
+	CategoryName  string `db:"category_name"  json:"categoryName,omitempty"`
+	CategoryEmoji string `db:"category_emoji" json:"categoryEmoji,omitempty"`
+	TelegramID    int64  `db:"telegram_id"    json:"telegramId,omitempty"`
+}
+
+// RecurringOccurrence records an expense materialized from a recurring expense
+type RecurringOccurrence struct {
+	Recurring *RecurringExpense
+	DueAt     time.Time
+}
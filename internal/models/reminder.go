@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ReminderSchedule represents how often a reminder is sent
+type ReminderSchedule string
+
+const (
+	ReminderDaily  ReminderSchedule = "daily"
+	ReminderWeekly ReminderSchedule = "weekly"
+)
+
+// Reminder represents a message pushed to a user at a chosen time
+type Reminder struct {
+	ID         int64            `db:"id"           json:"id"`
+	UserID     int64            `db:"user_id"      json:"userId"`
+	Message    string           `db:"message"      json:"message"`
+	Schedule   ReminderSchedule `db:"schedule"     json:"schedule"`
+	DayOfWeek  sql.NullInt64    `db:"day_of_week"  json:"dayOfWeek"` // Weekly reminders only, 0 = Sunday
+	Hour       int              `db:"hour"         json:"hour"`
+	Minute     int              `db:"minute"       json:"minute"`
+	NextRun    time.Time        `db:"next_run"     json:"nextRun"`
+	LastSentAt *time.Time       `db:"last_sent_at" json:"lastSentAt,omitempty"`
+	IsActive   bool             `db:"is_active"    json:"isActive"`
+	CreatedAt  time.Time        `db:"created_at"   json:"createdAt"`
+	UpdatedAt  time.Time        `db:"updated_at"   json:"updatedAt"`
+
+	// Joined fields
+	TelegramID int64 `db:"telegram_id" json:"telegramId,omitempty"`
+}
+
+// NextRunAfter returns the first time strictly after t at which the reminder is due, in t's location
+func (r *Reminder) NextRunAfter(t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), r.Hour, r.Minute, 0, 0, t.Location())
+
+	if r.Schedule == ReminderWeekly && r.DayOfWeek.Valid {
+		days := (int(r.DayOfWeek.Int64) - int(next.Weekday()) + 7) % 7
+		next = next.AddDate(0, 0, days)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
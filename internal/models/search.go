@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SearchQuery is a /search query split into filters and the free text left to rank
+type SearchQuery struct {
+	Text        string      // Words left after the filters, ranked by full-text and semantic similarity
+	From        *time.Time  // Earliest expense time, inclusive
+	To          *time.Time  // Latest expense time, exclusive
+	MinAmount   *float64    // Smallest amount in the home currency, inclusive
+	MaxAmount   *float64    // Largest amount in the home currency, inclusive
+	Categories  []*Category // Expenses in any of these categories or groups match
+	Groups      []string
+	VehicleType string // CAR, BIKE, SCOOTER or OTHER, empty for any
+	ByAmount    bool   // Rank larger amounts first, e.g. "expensive"
+}
+
+// HasFilters reports whether the query restricts expenses by anything other than its text
+func (q *SearchQuery) HasFilters() bool {
+	return q.From != nil || q.To != nil || q.MinAmount != nil || q.MaxAmount != nil ||
+		len(q.Categories) > 0 || len(q.Groups) > 0 || q.VehicleType != ""
+}
+
+// ExpenseSearch is a search query run for one user and one page of results
+type ExpenseSearch struct {
+	SearchQuery
+	UserID        int64
+	Embedding     []float32 // Embedding of Text; without it the text is ranked by full-text search only
+	MinSimilarity float64   // Smallest cosine similarity for a semantic match
+	Limit         int
+	Offset        int
+}
+
+// SearchResult is one page of search results
+type SearchResult struct {
+	Query    *SearchQuery
+	Expenses []*Expense
+	Total    int // Matches across all pages
+	Page     int // 1-based
+	PageSize int
+}
+
+// Pages returns the number of pages of results
+func (r *SearchResult) Pages() int {
+	if r.PageSize <= 0 || r.Total == 0 {
+		return 1
+	}
+	return (r.Total + r.PageSize - 1) / r.PageSize
+}
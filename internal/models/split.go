@@ -0,0 +1,73 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SplitMethod is how an expense is divided between its participants
+type SplitMethod string
+
+const (
+	SplitMethodEqual  SplitMethod = "EQUAL"
+	SplitMethodShares SplitMethod = "SHARES"
+	SplitMethodExact  SplitMethod = "EXACT"
+)
+
+// ExpenseSplit is the part of a shared expense one participant owes, in the expense's TotalPrice.
+// The payer's own part is a split too, so the splits of an expense add up to its total.
+type ExpenseSplit struct {
+	ExpenseID int64       `db:"expense_id" json:"expenseId"`
+	UserID    int64       `db:"user_id"    json:"userId"`
+	Method    SplitMethod `db:"method"     json:"method"`
+	Shares    float64     `db:"shares"     json:"shares"`
+	Amount    float64     `db:"amount"     json:"amount"`
+	CreatedAt time.Time   `db:"created_at" json:"createdAt"`
+}
+
+// Settlement is a payment from one ledger member to another towards what they owe
+type Settlement struct {
+	ID         int64          `db:"id"           json:"id"`
+	LedgerID   int64          `db:"ledger_id"    json:"ledgerId"`
+	FromUserID int64          `db:"from_user_id" json:"fromUserId"`
+	ToUserID   int64          `db:"to_user_id"   json:"toUserId"`
+	Amount     float64        `db:"amount"       json:"amount"`
+	Currency   string         `db:"currency"     json:"currency"`
+	Note       sql.NullString `db:"note"         json:"note"`
+	CreatedAt  time.Time      `db:"created_at"   json:"createdAt"`
+}
+
+// MemberBalance is what a ledger member is owed overall; negative when they owe others
+type MemberBalance struct {
+	UserID int64   `json:"userId"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// Transfer is a payment that settles part of the balances in a ledger
+type Transfer struct {
+	FromUserID int64   `json:"fromUserId"`
+	From       string  `json:"from"`
+	ToUserID   int64   `json:"toUserId"`
+	To         string  `json:"to"`
+	Amount     float64 `json:"amount"`
+}
+
+// LedgerBalances are the balances of a ledger's members in Currency, and the fewest transfers
+// that settle them. Expenses and settlements that cannot be converted to Currency are left out;
+// Unconverted counts them and MissingRates lists the exchange rates needed, such as "USD→INR".
+type LedgerBalances struct {
+	Ledger       *Ledger         `json:"ledger"`
+	Currency     string          `json:"currency"`
+	Balances     []MemberBalance `json:"balances"`
+	Transfers    []Transfer      `json:"transfers"`
+	Unconverted  int             `json:"unconverted,omitempty"`
+	MissingRates []string        `json:"missingRates,omitempty"`
+}
+
+// SplitPart is one participant of a split as entered: a member's @username or first name and
+// their shares or exact amount. Equal splits ignore Value.
+type SplitPart struct {
+	Member string  `json:"member"`
+	Value  float64 `json:"value"`
+}
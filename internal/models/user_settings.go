@@ -0,0 +1,189 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Default user preferences, used until a user changes them with /settings
+const (
+	DefaultCurrency   = "INR"
+	DefaultDateFormat = "02 Jan 2006"
+	DefaultLanguage   = "en"
+)
+
+// UserSettings represents the display preferences of a user
+type UserSettings struct {
+	UserID               int64     `db:"user_id"               json:"userId"`
+	Currency             string    `db:"currency"              json:"currency"`
+	DateFormat           string    `db:"date_format"           json:"dateFormat"`
+	Language             string    `db:"language"              json:"language"`
+	NotificationsEnabled bool      `db:"notifications_enabled" json:"notificationsEnabled"`
+	CreatedAt            time.Time `db:"created_at"            json:"createdAt"`
+	UpdatedAt            time.Time `db:"updated_at"            json:"updatedAt"`
+}
+
+// DefaultUserSettings returns the settings used for a user who has not changed any
+func DefaultUserSettings(userID int64) *UserSettings {
+	return &UserSettings{
+		UserID:               userID,
+		Currency:             DefaultCurrency,
+		DateFormat:           DefaultDateFormat,
+		Language:             DefaultLanguage,
+		NotificationsEnabled: true,
+	}
+}
+
+// Currency describes a currency that can be chosen as the display currency
+type Currency struct {
+	Code   string
+	Symbol string
+	Name   string
+}
+
+// SupportedCurrencies lists the currencies offered in /settings
+var SupportedCurrencies = []Currency{
+	{Code: "INR", Symbol: "₹", Name: "Indian Rupee"},
+	{Code: "USD", Symbol: "$", Name: "US Dollar"},
+	{Code: "EUR", Symbol: "€", Name: "Euro"},
+	{Code: "GBP", Symbol: "£", Name: "British Pound"},
+	{Code: "JPY", Symbol: "¥", Name: "Japanese Yen"},
+	{Code: "AED", Symbol: "AED ", Name: "UAE Dirham"},
+}
+
+// FindCurrency returns the supported currency with the given ISO code
+func FindCurrency(code string) (Currency, bool) {
+	for _, c := range SupportedCurrencies {
+		if strings.EqualFold(c.Code, code) {
+			return c, true
+		}
+	}
+	return Currency{}, false
+}
+
+// DateFormat describes a date layout that can be chosen in /settings
+type DateFormat struct {
+	Layout string // Full date, e.g. "02 Jan 2006"
+	Short  string // Date without the year, used in compact lists
+}
+
+// SupportedDateFormats lists the date layouts offered in /settings
+var SupportedDateFormats = []DateFormat{
+	{Layout: "02 Jan 2006", Short: "02 Jan"},
+	{Layout: "Jan 02, 2006", Short: "Jan 02"},
+	{Layout: "02/01/2006", Short: "02/01"},
+	{Layout: "01/02/2006", Short: "01/02"},
+	{Layout: "2006-01-02", Short: "01-02"},
+}
+
+// FindDateFormat returns the supported date format with the given layout
+func FindDateFormat(layout string) (DateFormat, bool) {
+	for _, f := range SupportedDateFormats {
+		if f.Layout == layout {
+			return f, true
+		}
+	}
+	return DateFormat{}, false
+}
+
+// Language describes a language that can be chosen in /settings
+type Language struct {
+	Code   string
+	Name   string
+	Months [12]string // Month names used when rendering dates
+}
+
+// SupportedLanguages lists the languages offered in /settings
+var SupportedLanguages = []Language{
+	{Code: "en", Name: "English"},
+	{Code: "de", Name: "Deutsch", Months: [12]string{
+		"Januar", "Februar", "März", "April", "Mai", "Juni",
+		"Juli", "August", "September", "Oktober", "November", "Dezember",
+	}},
+	{Code: "fr", Name: "Français", Months: [12]string{
+		"janvier", "février", "mars", "avril", "mai", "juin",
+		"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+	}},
+	{Code: "es", Name: "Español", Months: [12]string{
+		"enero", "febrero", "marzo", "abril", "mayo", "junio",
+		"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+	}},
+}
+
+// FindLanguage returns the supported language with the given code
+func FindLanguage(code string) (Language, bool) {
+	for _, l := range SupportedLanguages {
+		if strings.EqualFold(l.Code, code) {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// CurrencySymbol returns the symbol of a currency, or the code for currencies without one
+func CurrencySymbol(code string) string {
+	if c, ok := FindCurrency(code); ok {
+		return c.Symbol
+	}
+	return code + " "
+}
+
+// FormatMoney formats an amount in the given currency, e.g. "€12.50"
+func FormatMoney(amount float64, code string) string {
+	return fmt.Sprintf("%s%.2f", CurrencySymbol(code), amount)
+}
+
+// CurrencySymbol returns the symbol of the user's currency
+func (s *UserSettings) CurrencySymbol() string {
+	return CurrencySymbol(s.Currency)
+}
+
+// FormatAmount formats an amount in the user's currency
+func (s *UserSettings) FormatAmount(amount float64) string {
+	return FormatMoney(amount, s.Currency)
+}
+
+// FormatDate formats a date with the user's date format and language
+func (s *UserSettings) FormatDate(t time.Time) string {
+	return s.localizeMonth(t, t.Format(s.dateFormat().Layout))
+}
+
+// FormatShortDate formats a date without the year, for compact lists and buttons
+func (s *UserSettings) FormatShortDate(t time.Time) string {
+	return s.localizeMonth(t, t.Format(s.dateFormat().Short))
+}
+
+// FormatMonth formats the month and year of a date, e.g. "January 2006"
+func (s *UserSettings) FormatMonth(t time.Time) string {
+	return s.localizeMonth(t, t.Format("January 2006"))
+}
+
+// dateFormat returns the user's date format, falling back to the default layout
+func (s *UserSettings) dateFormat() DateFormat {
+	if f, ok := FindDateFormat(s.DateFormat); ok {
+		return f
+	}
+	return SupportedDateFormats[0]
+}
+
+// localizeMonth replaces the English month name of t in formatted with the user's language.
+// The full name is replaced before the three-letter abbreviation so "January" never becomes "Januaruary".
+func (s *UserSettings) localizeMonth(t time.Time, formatted string) string {
+	lang, ok := FindLanguage(s.Language)
+	if !ok || lang.Months[0] == "" {
+		return formatted
+	}
+
+	english := t.Month().String()
+	local := lang.Months[t.Month()-1]
+	if strings.Contains(formatted, english) {
+		return strings.Replace(formatted, english, local, 1)
+	}
+
+	short := []rune(local)
+	if len(short) > 3 {
+		short = short[:3]
+	}
+	return strings.Replace(formatted, english[:3], string(short), 1)
+}
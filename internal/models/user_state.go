@@ -2,23 +2,37 @@ package models
 
 import "time"
 
-// UserState represents the current state of a user in the conversation
+// UserState represents the current state of a user in the conversation. Between updates it is
+// kept in a state store, serialized as JSON when the store is persistent.
 type UserState struct {
-	Step             Step
-	VehicleType      string
-	Category         string
-	Odometer         float64
-	PetrolPrice      float64
-	TotalPrice       float64
-	Notes            string
-	EditMode         bool
-	EditID           string
-	DeleteExpense    *Expense   // Store the expense being deleted
-	TempExpense      *Expense   // Temporary expense for adding/editing
-	ExpenseSelection []*Expense // List of expenses shown for edit/delete
-	LastActivity     time.Time  // Last activity timestamp
-	CreatedAt        time.Time  // When the state was created
-	UpdatedAt        time.Time  // When the state was last updated
+	Step             Step         `json:"step"`
+	VehicleType      string       `json:"vehicleType"`
+	Category         string       `json:"category"`
+	Odometer         float64      `json:"odometer"`
+	PetrolPrice      float64      `json:"petrolPrice"`
+	TotalPrice       float64      `json:"totalPrice"`
+	Notes            string       `json:"notes"`
+	EditMode         bool         `json:"editMode"`
+	EditID           string       `json:"editId"`
+	DeleteExpense    *Expense     `json:"deleteExpense"`    // Store the expense being deleted
+	TempExpense      *Expense     `json:"tempExpense"`      // Temporary expense for adding/editing
+	ExpenseSelection []*Expense   `json:"expenseSelection"` // List of expenses shown for edit/delete
+	BudgetPeriod     BudgetPeriod `json:"budgetPeriod"`     // Period chosen while setting a budget
+	BudgetID         int64        `json:"budgetId"`         // Budget whose category limits are being edited
+	ReminderID       int64        `json:"reminderId"`       // Reminder being edited, 0 when setting a new one
+	ImportProfile    string       `json:"importProfile"`    // Bank profile name used by /import
+	ImportKey        string       `json:"importKey"`        // Blob key of the statement staged while its columns are being mapped
+	ImportExpenses   []*Expense   `json:"importExpenses"`   // Expenses previewed by /import awaiting confirmation
+	SearchQuery      string       `json:"searchQuery"`      // Query whose results /search is paging through
+	FuelType         FuelType     `json:"fuelType"`         // Fuel of the vehicle picked for the expense being added
+	SplitExpenseID   int64        `json:"splitExpenseId"`   // Shared expense being split with /split
+	SplitMethod      SplitMethod  `json:"splitMethod"`      // Shares or exact amounts being entered for the split
+	AttachExpenseID  int64        `json:"attachExpenseId"`  // Expense that receipt photos and PDFs sent now are attached to
+	ReceiptKey       string       `json:"receiptKey"`       // Blob key of the scanned receipt photo, attached to TempExpense when it is saved
+	ReceiptFileName  string       `json:"receiptFileName"`  // File name of the scanned receipt photo
+	LastActivity     time.Time    `json:"lastActivity"`     // Last activity timestamp
+	CreatedAt        time.Time    `json:"createdAt"`        // When the state was created
+	UpdatedAt        time.Time    `json:"updatedAt"`        // When the state was last updated
 }
 
 // NewUserState creates a new user state
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// FuelType is what a vehicle runs on
+type FuelType string
+
+const (
+	FuelTypePetrol   FuelType = "PETROL"
+	FuelTypeDiesel   FuelType = "DIESEL"
+	FuelTypeCNG      FuelType = "CNG"
+	FuelTypeElectric FuelType = "ELECTRIC"
+)
+
+// Unit returns the unit fuel is bought in: kWh for electric vehicles, kg for CNG, otherwise litres
+func (f FuelType) Unit() string {
+	switch f {
+	case FuelTypeElectric:
+		return "kWh"
+	case FuelTypeCNG:
+		return "kg"
+	default:
+		return "L"
+	}
+}
+
+// Vehicle is a named vehicle owned by a user
+type Vehicle struct {
+	ID              int64       `db:"id"               json:"id"`
+	UserID          int64       `db:"user_id"          json:"userId"`
+	Name            string      `db:"name"             json:"name"`
+	Type            VehicleType `db:"vehicle_type"     json:"vehicleType"`
+	FuelType        FuelType    `db:"fuel_type"        json:"fuelType"`
+	Registration    string      `db:"registration"     json:"registration,omitempty"`
+	InitialOdometer float64     `db:"initial_odometer" json:"initialOdometer"` // Reading when the vehicle was added
+	CreatedAt       time.Time   `db:"created_at"       json:"createdAt"`
+	UpdatedAt       time.Time   `db:"updated_at"       json:"updatedAt"`
+}
+
+// VehicleStats summarises the fuel efficiency and running costs of one vehicle
+type VehicleStats struct {
+	VehicleID       int64 // 0 for expenses not linked to a vehicle
+	Name            string
+	VehicleType     string
+	FuelType        FuelType
+	Distance        float64 // km between consecutive odometer readings, skipping regressions
+	FirstReading    float64 // First odometer reading in km, 0 without readings
+	LastReading     float64 // Highest odometer reading in km
+	Fills           int
+	FuelQuantity    float64 // Litres, kg or kWh bought, from the amount paid and the unit price
+	FuelCost        float64
+	MaintenanceCost float64 // Service, repairs and insurance
+	OtherCost       float64 // Other vehicle expenses such as parking, tolls and loan EMIs
+	FuelEfficiency  float64 // km per FuelType.Unit between fills with odometer readings and prices, 0 if unknown
+	CostPerKm       float64 // Fuel and maintenance per km, 0 without distance
+	Regressions     []OdometerRegression
+}
+
+// TotalCost returns everything spent on the vehicle
+func (s *VehicleStats) TotalCost() float64 {
+	return s.FuelCost + s.MaintenanceCost + s.OtherCost
+}
+
+// OdometerRegression is an odometer reading lower than an earlier one, usually a typo
+type OdometerRegression struct {
+	ExpenseID int64
+	Timestamp time.Time
+	Previous  float64
+	Reading   float64
+}
@@ -0,0 +1,63 @@
+// Package ocr reads receipt photos so expenses can be added from them.
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported OCR providers
+const (
+	ProviderTesseract = "tesseract"
+	ProviderNone      = "none"
+)
+
+// ErrUnavailable is returned by New when receipt OCR is turned off or its engine is not installed
+var ErrUnavailable = errors.New("receipt OCR is not available")
+
+// Receipt is what could be read from a receipt. Fields that could not be read are left zero.
+type Receipt struct {
+	Merchant      string
+	Date          time.Time
+	Total         float64
+	Currency      string // ISO code, only set when the receipt names an unambiguous currency
+	Items         []LineItem
+	Fuel          bool    // A fuel pump receipt
+	Litres        float64 // Fuel dispensed
+	PricePerLitre float64
+	Text          string // The recognised text the fields were parsed from
+}
+
+// LineItem is a priced line of a receipt
+type LineItem struct {
+	Description string
+	Amount      float64
+}
+
+// OCR reads a receipt from an image
+type OCR interface {
+	Scan(ctx context.Context, image []byte) (*Receipt, error)
+}
+
+// Config selects and configures an OCR engine
+type Config struct {
+	Provider  string        // tesseract or none
+	Path      string        // Path or name of the tesseract binary
+	Languages string        // Tesseract languages, e.g. "eng" or "eng+hin"
+	Timeout   time.Duration // Time allowed to read one image
+}
+
+// New creates the OCR engine selected by the config. It returns ErrUnavailable when OCR is
+// turned off or the engine is not installed, so callers can run without it.
+func New(cfg Config) (OCR, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderTesseract:
+		return NewTesseract(cfg.Path, cfg.Languages, cfg.Timeout)
+	case ProviderNone:
+		return nil, fmt.Errorf("%w: turned off", ErrUnavailable)
+	}
+	return nil, fmt.Errorf("unknown OCR provider %q", cfg.Provider)
+}
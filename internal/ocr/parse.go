@@ -0,0 +1,347 @@
+package ocr
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// merchantLines is how many lines from the top of a receipt are searched for the merchant name
+const merchantLines = 6
+
+var (
+	// amountPattern matches amounts such as 450, 450.00, 1,234.50, 1,23,456.00 and 12,50
+	amountPattern = regexp.MustCompile(`(\d{1,3}(?:,\d{2,3})+(?:\.\d{1,2})?|\d+(?:[.,]\d{1,2})?)\b`)
+	// decimalPattern matches amounts written with cents, which is how prices appear on receipts
+	decimalPattern = regexp.MustCompile(`[.,]\d{1,2}$`)
+	// amountLine matches a line holding nothing but an amount
+	amountLine = regexp.MustCompile(`(?i)^(?:₹|€|£|\$|rs\.?)?\s*[\d.,]+$`)
+
+	// totalKeywords name the line holding the total, most specific first
+	totalKeywords = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(grand\s*total|net\s*total|total\s*amount|amount\s*due|balance\s*due|amount\s*payable|total\s*payable|total\s*due|net\s*amount|net\s*payable)\b`),
+		regexp.MustCompile(`(?i)\btotal\b`),
+		regexp.MustCompile(`(?i)\b(amount|amt)\b`),
+	}
+	// notTotal matches lines that mention a total or an amount but do not hold the receipt total
+	notTotal = regexp.MustCompile(`(?i)\bsub[\s-]*total|\btotal\s*(qty|quantity|items?|tax|gst|vat|discount|savings?|saved|weight)\b|\b(taxable|tax|gst|vat|cgst|sgst|igst|discount|saved|savings)\s*(amount|amt|value)\b|\b(amount|amt)\s*(saved|tendered|received|returned)\b`)
+	// paymentLine matches lines about how the total was paid, whose amounts can exceed the total
+	paymentLine = regexp.MustCompile(`(?i)\b(cash|change|tender(ed)?|received|returned)\b`)
+
+	// itemLine matches a line ending in a price, optionally followed by a one-letter tax code
+	itemLine = regexp.MustCompile(`^(.*\S)\s+(?:₹|€|£|\$|rs\.?\s*)?(\d{1,3}(?:,\d{3})+\.\d{2}|\d+[.,]\d{2})(?:\s*[A-Z*])?$`)
+	// leadingQuantity matches a quantity printed before an item's name, e.g. "2" or "2x"
+	leadingQuantity = regexp.MustCompile(`(?i)^\d+x?$`)
+	// notItem matches priced lines that are totals, taxes, payments or receipt details
+	notItem = regexp.MustCompile(`(?i)\b(total|sub[\s-]*total|tax|taxable|gst|cgst|sgst|igst|vat|cash|change|card|visa|mastercard|upi|paid|tender(ed)?|balance|discount|round(ing)?|saved|savings|amount|amt|due|payable|rate|price|qty|quantity|volume|vol|date|time|bill|invoice|tel|phone|gstin|cashier|table|receipt|order|txn|ref)\b`)
+
+	// notMerchant matches header lines that are not the merchant's name
+	notMerchant = regexp.MustCompile(`(?i)\b(tax\s*invoice|invoice|receipt|bill|cash\s*memo|welcome|original|duplicate|copy|gstin|gst|tin|vat|tel|ph|phone|mobile|email|www|http|date|time|customer)\b`)
+
+	// currencyPattern matches currencies a receipt can name unambiguously. The dollar and yen
+	// signs are left out as several currencies use them.
+	currencyPattern = regexp.MustCompile(`(?i)(₹|€|£|\brs\b\.?|\binr\b|\beur\b|\bgbp\b|\busd\b|\baed\b)`)
+	currencyCodes   = map[string]string{
+		"₹": "INR", "rs": "INR", "rs.": "INR", "inr": "INR",
+		"€": "EUR", "eur": "EUR",
+		"£": "GBP", "gbp": "GBP",
+		"usd": "USD",
+		"aed": "AED",
+	}
+
+	isoDate     = regexp.MustCompile(`\b(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})\b`)
+	numericDate = regexp.MustCompile(`\b(\d{1,2})[-/.](\d{1,2})[-/.](\d{4}|\d{2})\b`)
+	dayMonth    = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?[\s\-/.]*(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?[\s\-/.,']*(\d{4}|\d{2})\b`)
+	monthDay    = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	monthNames  = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+	fuelWords = regexp.MustCompile(`(?i)\b(petrol|diesel|fuel|unleaded|gasoline|nozzle|cng|hsd|filling\s*station)\b`)
+	// fuelRate matches the price per litre, e.g. "Rate/Ltr: 104.61", "Price (Rs/L) 104.61" or "@ 104.61/L"
+	fuelRate    = regexp.MustCompile(`(?i)\b(?:rate|price|unit\s*price)\b(?:\s*/\s*(?:l|lt|ltr|litre|liter))?\s*(?:\([^)]*\))?\s*[:=\-]?\s*(?:₹|rs\.?|\$|€|£)?\s*(\d+(?:[.,]\d+)?)`)
+	fuelRatePer = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*/\s*(?:l|lt|ltr|litre|liter)\b`)
+	// fuelVolume matches the litres dispensed, e.g. "Volume(L): 19.12", "Qty 19.12" or "19.12 L"
+	fuelVolume      = regexp.MustCompile(`(?i)\b(?:volume|vol|qty|quantity|litres?|liters?|ltrs?)\b\s*(?:\((?:l|lt|ltr|ltrs|litres?|liters?)\))?\s*[:=\-]?\s*(\d+(?:[.,]\d+)?)`)
+	fuelVolumeUnits = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:l|lt|ltr|ltrs|litres?|liters?)\b`)
+)
+
+// ParseReceipt reads the merchant, date, total, line items and, for fuel pump receipts, the
+// litres and price per litre from recognised receipt text
+func ParseReceipt(text string) *Receipt {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	receipt := &Receipt{
+		Merchant: findMerchant(lines),
+		Date:     findDate(lines),
+		Currency: findCurrency(text),
+		Text:     text,
+	}
+	total, totalLine := findTotal(lines)
+	receipt.Total = total
+	receipt.Items = findItems(lines, totalLine)
+
+	if fuelWords.MatchString(text) {
+		receipt.Fuel = true
+		receipt.Litres, receipt.PricePerLitre = findFuel(lines)
+		switch {
+		// Without a labelled total the largest price may be the rate, so litres times rate is better
+		case totalLine < 0 && receipt.Litres > 0 && receipt.PricePerLitre > 0:
+			receipt.Total = round2(receipt.Litres * receipt.PricePerLitre)
+		case receipt.Total > 0 && receipt.Litres == 0 && receipt.PricePerLitre > 0:
+			receipt.Litres = round2(receipt.Total / receipt.PricePerLitre)
+		case receipt.Total > 0 && receipt.Litres > 0 && receipt.PricePerLitre == 0:
+			receipt.PricePerLitre = round2(receipt.Total / receipt.Litres)
+		}
+	}
+
+	return receipt
+}
+
+// findMerchant returns the first line near the top that reads like a name rather than an
+// address, a phone number or a heading such as "TAX INVOICE"
+func findMerchant(lines []string) string {
+	for i, line := range lines {
+		if i == merchantLines {
+			break
+		}
+		if notMerchant.MatchString(line) {
+			continue
+		}
+		name := strings.TrimFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&' && r != '\''
+		})
+		letters, others := 0, 0
+		for _, r := range name {
+			switch {
+			case unicode.IsLetter(r):
+				letters++
+			case !unicode.IsSpace(r):
+				others++
+			}
+		}
+		if letters >= 3 && letters > others {
+			return name
+		}
+	}
+	return ""
+}
+
+// findDate returns the first date on the receipt, or the zero time when there is none.
+// Numeric dates are read day first like bank statements, unless only month first makes sense.
+func findDate(lines []string) time.Time {
+	for _, line := range lines {
+		if m := isoDate.FindStringSubmatch(line); m != nil {
+			if date, ok := makeDate(m[1], m[2], m[3]); ok {
+				return date
+			}
+		}
+		if m := dayMonth.FindStringSubmatch(line); m != nil {
+			if date, ok := makeDate(m[3], monthNumber(m[2]), m[1]); ok {
+				return date
+			}
+		}
+		if m := monthDay.FindStringSubmatch(line); m != nil {
+			if date, ok := makeDate(m[3], monthNumber(m[1]), m[2]); ok {
+				return date
+			}
+		}
+		if m := numericDate.FindStringSubmatch(line); m != nil {
+			if date, ok := makeDate(m[3], m[2], m[1]); ok {
+				return date
+			}
+			if date, ok := makeDate(m[3], m[1], m[2]); ok {
+				return date
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// monthNumber returns the number of a three-letter month name as a string
+func monthNumber(name string) string {
+	name = strings.ToLower(name)
+	for i, month := range monthNames {
+		if month == name {
+			return strconv.Itoa(i + 1)
+		}
+	}
+	return "0"
+}
+
+// makeDate builds a date from its parts, reading two-digit years as this century
+func makeDate(year, month, day string) (time.Time, bool) {
+	y, errY := strconv.Atoi(year)
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}, false
+	}
+	if y < 100 {
+		y += 2000
+	}
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.Local)
+	if date.Year() != y || int(date.Month()) != m || date.Day() != d {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// findCurrency returns the first currency the receipt names unambiguously
+func findCurrency(text string) string {
+	match := currencyPattern.FindString(text)
+	return currencyCodes[strings.ToLower(match)]
+}
+
+// findTotal returns the total and the index of its line. Lines named by more specific keywords
+// win, then larger amounts. Without any keyword the largest price on the receipt is taken, and
+// the line index is -1.
+func findTotal(lines []string) (float64, int) {
+	total, index, best := 0.0, -1, len(totalKeywords)
+	for i, line := range lines {
+		if notTotal.MatchString(line) {
+			continue
+		}
+		for priority, keyword := range totalKeywords {
+			if priority > best {
+				break
+			}
+			loc := keyword.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			amount, ok := lastAmount(line[loc[1]:])
+			// The amount can be printed on the line below the keyword
+			if !ok && i+1 < len(lines) && amountLine.MatchString(lines[i+1]) {
+				amount, ok = lastAmount(lines[i+1])
+			}
+			if ok && amount > 0 && (priority < best || amount > total) {
+				total, index, best = amount, i, priority
+			}
+			break
+		}
+	}
+	if index >= 0 {
+		return total, index
+	}
+
+	for _, line := range lines {
+		if paymentLine.MatchString(line) {
+			continue
+		}
+		for _, match := range amountPattern.FindAllString(line, -1) {
+			if amount, ok := parseAmount(match); ok && decimalPattern.MatchString(match) && amount > total {
+				total = amount
+			}
+		}
+	}
+	return total, -1
+}
+
+// findItems returns the priced lines above the total, or of the whole receipt when the total
+// line is unknown
+func findItems(lines []string, totalLine int) []LineItem {
+	if totalLine >= 0 {
+		lines = lines[:totalLine]
+	}
+
+	var items []LineItem
+	for _, line := range lines {
+		m := itemLine.FindStringSubmatch(line)
+		if m == nil || notItem.MatchString(m[1]) {
+			continue
+		}
+		amount, ok := parseAmount(m[2])
+		if !ok || amount <= 0 {
+			continue
+		}
+		if description := itemDescription(m[1]); description != "" {
+			items = append(items, LineItem{Description: description, Amount: amount})
+		}
+	}
+	return items
+}
+
+// itemDescription drops the quantity and unit price that often surround an item's name, as in
+// "2 Caesar Salad" or "Milk 1L 2 x 30.00", and returns "" when no name is left
+func itemDescription(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) > 1 && leadingQuantity.MatchString(fields[0]) {
+		fields = fields[1:]
+	}
+	for len(fields) > 0 {
+		last := strings.ToLower(fields[len(fields)-1])
+		if last != "x" && last != "@" && strings.IndexFunc(last, unicode.IsLetter) >= 0 {
+			break
+		}
+		fields = fields[:len(fields)-1]
+	}
+
+	description := strings.Join(fields, " ")
+	letters := 0
+	for _, r := range description {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < 2 {
+		return ""
+	}
+	return description
+}
+
+// findFuel returns the litres dispensed and the price per litre of a fuel pump receipt
+func findFuel(lines []string) (litres, price float64) {
+	for _, line := range lines {
+		if price == 0 {
+			if m := fuelRate.FindStringSubmatch(line); m != nil {
+				price, _ = parseAmount(m[1])
+			} else if m := fuelRatePer.FindStringSubmatch(line); m != nil {
+				price, _ = parseAmount(m[1])
+			}
+		}
+		// "Rate/Ltr" names a unit, not the litres dispensed
+		line = fuelRatePer.ReplaceAllString(fuelRate.ReplaceAllString(line, ""), "")
+		if litres == 0 {
+			if m := fuelVolume.FindStringSubmatch(line); m != nil {
+				litres, _ = parseAmount(m[1])
+			} else if m := fuelVolumeUnits.FindStringSubmatch(line); m != nil {
+				litres, _ = parseAmount(m[1])
+			}
+		}
+	}
+	return litres, price
+}
+
+// lastAmount returns the last amount in text
+func lastAmount(text string) (float64, bool) {
+	matches := amountPattern.FindAllString(text, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	return parseAmount(matches[len(matches)-1])
+}
+
+// parseAmount parses an amount with thousands separators. A single comma followed by one or
+// two digits is a decimal comma, as in 12,50.
+func parseAmount(s string) (float64, bool) {
+	if strings.Count(s, ",") == 1 && !strings.Contains(s, ".") && len(s)-strings.Index(s, ",") <= 3 {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil || math.IsInf(amount, 0) {
+		return 0, false
+	}
+	return amount, true
+}
+
+// round2 rounds to two decimal places
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
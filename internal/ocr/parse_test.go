@@ -0,0 +1,162 @@
+package ocr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReceipt(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		merchant string
+		date     time.Time
+		total    float64
+		currency string
+		items    []LineItem
+		fuel     bool
+		litres   float64
+		price    float64
+	}{
+		{
+			fixture:  "grocery.txt",
+			merchant: "Reliance Fresh",
+			date:     time.Date(2026, time.October, 3, 0, 0, 0, 0, time.Local),
+			total:    354,
+			currency: "INR",
+			items: []LineItem{
+				{Description: "Amul Milk 1L", Amount: 60},
+				{Description: "Brown Bread", Amount: 45},
+				{Description: "Bananas 1kg", Amount: 64.5},
+				{Description: "Toor Dal 1kg", Amount: 168},
+			},
+		},
+		{
+			fixture:  "restaurant.txt",
+			merchant: "THE CORNER BISTRO",
+			date:     time.Date(2026, time.December, 24, 0, 0, 0, 0, time.Local),
+			total:    64.26,
+			items: []LineItem{
+				{Description: "Caesar Salad", Amount: 18},
+				{Description: "Ribeye Steak", Amount: 34.5},
+				{Description: "Iced Tea", Amount: 7},
+			},
+		},
+		{
+			fixture:  "cafe.txt",
+			merchant: "Café Lumière",
+			date:     time.Date(2026, time.September, 15, 0, 0, 0, 0, time.Local),
+			total:    12,
+			currency: "EUR",
+			items: []LineItem{
+				{Description: "Croissant", Amount: 2.4},
+				{Description: "Cafe creme", Amount: 4.5},
+				{Description: "Jus d'orange", Amount: 5.1},
+			},
+		},
+		{
+			fixture:  "fuel.txt",
+			merchant: "SHREE SAI FUEL STATION",
+			date:     time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local),
+			total:    2000,
+			currency: "INR",
+			fuel:     true,
+			litres:   19.12,
+			price:    104.61,
+		},
+		{
+			fixture:  "unlabelled.txt",
+			merchant: "FRESH MART",
+			date:     time.Date(2026, time.September, 30, 0, 0, 0, 0, time.Local),
+			total:    9.65,
+			items: []LineItem{
+				{Description: "Apples", Amount: 3.2},
+				{Description: "Oranges", Amount: 4.1},
+				{Description: "Yogurt", Amount: 2.35},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			text, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+
+			receipt := ParseReceipt(string(text))
+			assert.Equal(t, tt.merchant, receipt.Merchant)
+			assert.True(t, tt.date.Equal(receipt.Date), "date %s", receipt.Date)
+			assert.InDelta(t, tt.total, receipt.Total, 0.001)
+			assert.Equal(t, tt.currency, receipt.Currency)
+			assert.Equal(t, tt.items, receipt.Items)
+			assert.Equal(t, tt.fuel, receipt.Fuel)
+			assert.InDelta(t, tt.litres, receipt.Litres, 0.001)
+			assert.InDelta(t, tt.price, receipt.PricePerLitre, 0.001)
+			assert.Equal(t, string(text), receipt.Text)
+		})
+	}
+}
+
+func TestParseReceipt_FuelFillsMissingFields(t *testing.T) {
+	// Pumps that print only the litres and the rate still give a total
+	receipt := ParseReceipt("INDIAN OIL\nDIESEL\nRate (Rs/L): 92.50\nQty: 20.00 L\n")
+	assert.True(t, receipt.Fuel)
+	assert.InDelta(t, 1850, receipt.Total, 0.001)
+
+	// and those that print only the amount and the rate still give the litres
+	receipt = ParseReceipt("Shell Unleaded\nPrice/L 1.80\nTOTAL 45.00\n")
+	assert.InDelta(t, 25, receipt.Litres, 0.001)
+	assert.InDelta(t, 1.8, receipt.PricePerLitre, 0.001)
+}
+
+func TestParseReceipt_Empty(t *testing.T) {
+	receipt := ParseReceipt("")
+	assert.Empty(t, receipt.Merchant)
+	assert.True(t, receipt.Date.IsZero())
+	assert.Zero(t, receipt.Total)
+	assert.Empty(t, receipt.Items)
+	assert.False(t, receipt.Fuel)
+}
+
+func TestFindDate(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Time
+	}{
+		{"Date: 03/04/2026", time.Date(2026, time.April, 3, 0, 0, 0, 0, time.Local)},
+		{"12/31/26 10:02", time.Date(2026, time.December, 31, 0, 0, 0, 0, time.Local)},
+		{"2026.10.05", time.Date(2026, time.October, 5, 0, 0, 0, 0, time.Local)},
+		{"5th Oct 2026", time.Date(2026, time.October, 5, 0, 0, 0, 0, time.Local)},
+		{"Oct 5, 2026", time.Date(2026, time.October, 5, 0, 0, 0, 0, time.Local)},
+		{"05-OCT-26", time.Date(2026, time.October, 5, 0, 0, 0, 0, time.Local)},
+		{"31/02/2026", time.Time{}},
+		{"Table 12", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(findDate([]string{tt.line})), "got %s", findDate([]string{tt.line}))
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"450", 450},
+		{"450.00", 450},
+		{"1,234.50", 1234.5},
+		{"1,23,456.00", 123456},
+		{"12,50", 12.5},
+		{"1,234", 1234},
+	}
+	for _, tt := range tests {
+		got, ok := parseAmount(tt.in)
+		assert.True(t, ok, tt.in)
+		assert.InDelta(t, tt.want, got, 0.001, tt.in)
+	}
+}
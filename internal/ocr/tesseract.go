@@ -0,0 +1,68 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxStderr bounds how much of tesseract's error output is kept in errors
+const maxStderr = 512
+
+// Tesseract reads receipts by running a local tesseract binary
+type Tesseract struct {
+	path      string
+	languages string
+	timeout   time.Duration
+}
+
+// NewTesseract finds the tesseract binary at path, or on PATH when path is a bare name, and
+// returns ErrUnavailable when it is not installed
+func NewTesseract(path, languages string, timeout time.Duration) (*Tesseract, error) {
+	if path == "" {
+		path = "tesseract"
+	}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if languages == "" {
+		languages = "eng"
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Tesseract{path: resolved, languages: languages, timeout: timeout}, nil
+}
+
+// Scan recognises the text of a receipt image and parses it
+func (t *Tesseract) Scan(ctx context.Context, image []byte) (*Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	// Page segmentation mode 4 reads a single column of text of varying sizes, which is how
+	// receipts are laid out
+	cmd := exec.CommandContext(ctx, t.path, "stdin", "stdout", "-l", t.languages, "--psm", "4")
+	cmd.Stdin = bytes.NewReader(image)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Stop waiting for output soon after the deadline kills the process
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("tesseract did not finish in %s: %w", t.timeout, ctx.Err())
+		}
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > maxStderr {
+			message = message[:maxStderr]
+		}
+		return nil, fmt.Errorf("tesseract failed: %w: %s", err, message)
+	}
+
+	return ParseReceipt(stdout.String()), nil
+}
@@ -0,0 +1,89 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTesseract writes a shell script that stands in for tesseract, recording its arguments and
+// standard input next to it
+func fakeTesseract(t *testing.T, body string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tesseract is a shell script")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tesseract")
+	script := "#!/bin/sh\necho \"$@\" > " + dir + "/args\ncat > " + dir + "/stdin\n" + body + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path, dir
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Provider: ProviderNone})
+	assert.True(t, errors.Is(err, ErrUnavailable))
+
+	_, err = New(Config{Provider: ProviderTesseract, Path: filepath.Join(t.TempDir(), "missing")})
+	assert.True(t, errors.Is(err, ErrUnavailable))
+
+	_, err = New(Config{Provider: "cloud"})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrUnavailable))
+
+	path, _ := fakeTesseract(t, "")
+	engine, err := New(Config{Provider: ProviderTesseract, Path: path})
+	require.NoError(t, err)
+	assert.IsType(t, &Tesseract{}, engine)
+}
+
+func TestTesseract_Scan(t *testing.T) {
+	fixture, err := filepath.Abs(filepath.Join("testdata", "fuel.txt"))
+	require.NoError(t, err)
+
+	t.Run("parses the recognised text", func(t *testing.T) {
+		path, dir := fakeTesseract(t, "cat "+fixture)
+		engine, err := NewTesseract(path, "eng+hin", time.Minute)
+		require.NoError(t, err)
+
+		receipt, err := engine.Scan(context.Background(), []byte("image bytes"))
+		require.NoError(t, err)
+		assert.Equal(t, "SHREE SAI FUEL STATION", receipt.Merchant)
+		assert.InDelta(t, 2000, receipt.Total, 0.001)
+
+		args, err := os.ReadFile(filepath.Join(dir, "args"))
+		require.NoError(t, err)
+		assert.Equal(t, "stdin stdout -l eng+hin --psm 4", strings.TrimSpace(string(args)))
+		stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+		require.NoError(t, err)
+		assert.Equal(t, "image bytes", string(stdin))
+	})
+
+	t.Run("reports tesseract errors", func(t *testing.T) {
+		path, _ := fakeTesseract(t, "echo 'Error in pixReadMem: Unknown format' >&2\nexit 1")
+		engine, err := NewTesseract(path, "", 0)
+		require.NoError(t, err)
+
+		_, err = engine.Scan(context.Background(), []byte("not an image"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Unknown format")
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		path, _ := fakeTesseract(t, "sleep 5")
+		engine, err := NewTesseract(path, "", 50*time.Millisecond)
+		require.NoError(t, err)
+
+		_, err = engine.Scan(context.Background(), []byte("image"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "did not finish")
+	})
+}
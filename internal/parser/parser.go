@@ -0,0 +1,457 @@
+// Package parser turns one-line free text such as "450 dining lunch with team yesterday"
+// into an expense.
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// Errors returned by Parse when the text cannot become an expense
+var (
+	ErrNoAmount   = errors.New("no amount found")
+	ErrNoCategory = errors.New("no category found")
+)
+
+// categoryAliases maps common words to seeded category names
+var categoryAliases = map[string]string{
+	"fuel":       "Petrol",
+	"diesel":     "Petrol",
+	"cng":        "Petrol",
+	"food":       "Dining",
+	"restaurant": "Dining",
+	"coffee":     "Coffee/Tea",
+	"tea":        "Coffee/Tea",
+	"taxi":       "Transportation",
+	"cab":        "Transportation",
+	"uber":       "Transportation",
+	"auto":       "Transportation",
+	"metro":      "Transportation",
+	"groceries":  "Grocery",
+	"vegetables": "Grocery",
+	"flight":     "Flights",
+	"hotel":      "Hotels",
+	"train":      "Trains",
+	"bus":        "Buses",
+	"movie":      "Movies",
+	"medicine":   "Medicines",
+	"gym":        "Fitness",
+	"wifi":       "Internet",
+	"broadband":  "Internet",
+	"phone":      "Mobile",
+	"recharge":   "Mobile",
+	"rent":       "Home Loan EMI",
+}
+
+// vehicleWords maps words to vehicle types
+var vehicleWords = map[string]string{
+	"car":     "CAR",
+	"bike":    "BIKE",
+	"scooter": "SCOOTER",
+}
+
+// odometerWords introduce an odometer reading, e.g. "odo 45210"
+var odometerWords = map[string]bool{"odo": true, "odometer": true}
+
+// Parser parses one-line expense text against a set of categories
+type Parser struct {
+	categories []*models.Category
+	now        func() time.Time
+}
+
+// New creates a parser that matches categories from the given list
+func New(categories []*models.Category) *Parser {
+	return &Parser{
+		categories: categories,
+		now:        time.Now,
+	}
+}
+
+// Parse turns text into an expense. The amount is stored in OriginalAmount and TotalPrice with
+// Currency left empty unless the text names one. The category fields are those of the matched category.
+func (p *Parser) Parse(text string) (*models.Expense, error) {
+	tokens := strings.Fields(text)
+	used := make([]bool, len(tokens))
+	expense := &models.Expense{Timestamp: p.now()}
+
+	p.parseVehicleFields(tokens, used, expense)
+	p.parseDate(tokens, used, expense)
+
+	category := p.matchMultiWordCategory(tokens, used)
+
+	if !p.parseAmount(tokens, used, expense) {
+		return nil, ErrNoAmount
+	}
+
+	for i, token := range tokens {
+		if used[i] {
+			continue
+		}
+		if vehicle, ok := vehicleWords[strings.ToLower(token)]; ok && !expense.VehicleType.Valid {
+			expense.VehicleType.String, expense.VehicleType.Valid = vehicle, true
+			used[i] = true
+		}
+	}
+
+	if category == nil {
+		category = p.matchCategory(tokens, used)
+	}
+	if category == nil {
+		return nil, ErrNoCategory
+	}
+	expense.CategoryID = category.ID
+	expense.CategoryName = category.Name
+	expense.CategoryEmoji = category.Emoji
+	expense.CategoryGroup = category.Group
+
+	var notes []string
+	for i, token := range tokens {
+		if !used[i] {
+			notes = append(notes, token)
+		}
+	}
+	expense.Notes = strings.Join(notes, " ")
+
+	return expense, nil
+}
+
+// MatchCategory returns the category named by free text such as a bank statement description,
+// or nil when no word matches. Punctuation separates words, so "UPI/SWIGGY-DINING" finds Dining.
+func (p *Parser) MatchCategory(text string) *models.Category {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	used := make([]bool, len(tokens))
+
+	if category := p.matchMultiWordCategory(tokens, used); category != nil {
+		return category
+	}
+	return p.matchCategory(tokens, used)
+}
+
+// ParseAmount parses an amount with an optional currency, e.g. "450", "45 EUR", "EUR 45",
+// "€45" or "45€". The currency is empty when none was given.
+func ParseAmount(text string) (float64, string, error) {
+	fields := strings.Fields(text)
+	switch len(fields) {
+	case 1:
+		amount, currency, ok := parseAmountToken(fields[0])
+		if !ok {
+			return 0, "", fmt.Errorf("invalid amount %q", text)
+		}
+		return amount, currency, nil
+	case 2:
+		if amount, err := strconv.ParseFloat(fields[0], 64); err == nil && isCurrencyCode(fields[1]) {
+			return amount, strings.ToUpper(fields[1]), nil
+		}
+		if amount, err := strconv.ParseFloat(fields[1], 64); err == nil && isCurrencyCode(fields[0]) {
+			return amount, strings.ToUpper(fields[0]), nil
+		}
+	}
+	return 0, "", fmt.Errorf("invalid amount %q", text)
+}
+
+// parseAmountToken parses a single token such as "450", "€45" or "45€"
+func parseAmountToken(token string) (float64, string, bool) {
+	currency := ""
+	for _, c := range models.SupportedCurrencies {
+		symbol := strings.TrimSpace(c.Symbol)
+		if symbol == c.Code {
+			continue
+		}
+		if strings.HasPrefix(token, symbol) {
+			currency, token = c.Code, strings.TrimPrefix(token, symbol)
+			break
+		}
+		if strings.HasSuffix(token, symbol) {
+			currency, token = c.Code, strings.TrimSuffix(token, symbol)
+			break
+		}
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(token, ",", ""), 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return amount, currency, true
+}
+
+// isCurrencyCode reports whether s looks like an ISO 4217 currency code
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isKnownCurrency reports whether s is a supported currency or an upper-case code, so words
+// such as "tea" or "car" are not read as currencies in free text
+func isKnownCurrency(s string) bool {
+	if _, ok := models.FindCurrency(s); ok {
+		return true
+	}
+	_, isVehicle := vehicleWords[strings.ToLower(s)]
+	return !isVehicle && isCurrencyCode(s) && strings.ToUpper(s) == s
+}
+
+// parseVehicleFields reads "odo 45210" as the odometer and "@104.5" as the fuel price per litre
+func (p *Parser) parseVehicleFields(tokens []string, used []bool, expense *models.Expense) {
+	for i := 0; i < len(tokens); i++ {
+		token := strings.ToLower(tokens[i])
+
+		if odometerWords[token] && i+1 < len(tokens) {
+			if value, err := strconv.ParseFloat(tokens[i+1], 64); err == nil {
+				expense.Odometer = value
+				used[i], used[i+1] = true, true
+				i++
+			}
+			continue
+		}
+
+		if strings.HasPrefix(token, "@") {
+			priceText := strings.TrimPrefix(token, "@")
+			consumed := 1
+			if priceText == "" && i+1 < len(tokens) {
+				priceText = tokens[i+1]
+				consumed = 2
+			}
+			if value, err := strconv.ParseFloat(priceText, 64); err == nil {
+				expense.PetrolPrice = value
+				for j := 0; j < consumed; j++ {
+					used[i+j] = true
+				}
+				i += consumed - 1
+			}
+		}
+	}
+}
+
+// parseDate reads "today", "yesterday", "N days ago", weekday names and YYYY-MM-DD dates.
+// Weekdays mean the most recent such day, today included.
+func (p *Parser) parseDate(tokens []string, used []bool, expense *models.Expense) {
+	now := p.now()
+
+	for i := 0; i < len(tokens); i++ {
+		if used[i] {
+			continue
+		}
+		token := strings.ToLower(tokens[i])
+
+		switch token {
+		case "today":
+			used[i] = true
+			return
+		case "yesterday":
+			expense.Timestamp = now.AddDate(0, 0, -1)
+			used[i] = true
+			return
+		}
+
+		if i+2 < len(tokens) && strings.EqualFold(tokens[i+2], "ago") {
+			unit := strings.ToLower(tokens[i+1])
+			if days, err := strconv.Atoi(token); err == nil && days >= 0 && (unit == "days" || unit == "day") {
+				expense.Timestamp = now.AddDate(0, 0, -days)
+				used[i], used[i+1], used[i+2] = true, true, true
+				return
+			}
+		}
+
+		if weekday, ok := parseWeekday(token); ok {
+			diff := (int(now.Weekday()) - int(weekday) + 7) % 7
+			expense.Timestamp = now.AddDate(0, 0, -diff)
+			used[i] = true
+			if i > 0 && !used[i-1] && strings.EqualFold(tokens[i-1], "last") {
+				used[i-1] = true
+			}
+			return
+		}
+
+		if date, err := time.ParseInLocation("2006-01-02", token, now.Location()); err == nil {
+			expense.Timestamp = time.Date(date.Year(), date.Month(), date.Day(),
+				now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+			used[i] = true
+			if i > 0 && !used[i-1] && strings.EqualFold(tokens[i-1], "on") {
+				used[i-1] = true
+			}
+			return
+		}
+	}
+}
+
+// parseWeekday parses full or three-letter weekday names
+func parseWeekday(token string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if token == name || token == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseAmount reads the first number not used by another field, with an optional currency
+// symbol or a currency code next to it
+func (p *Parser) parseAmount(tokens []string, used []bool, expense *models.Expense) bool {
+	for i, token := range tokens {
+		if used[i] {
+			continue
+		}
+		amount, currency, ok := parseAmountToken(token)
+		if !ok {
+			continue
+		}
+		used[i] = true
+
+		if currency == "" {
+			switch {
+			case i+1 < len(tokens) && !used[i+1] && isKnownCurrency(tokens[i+1]):
+				currency = strings.ToUpper(tokens[i+1])
+				used[i+1] = true
+			case i > 0 && !used[i-1] && isKnownCurrency(tokens[i-1]):
+				currency = strings.ToUpper(tokens[i-1])
+				used[i-1] = true
+			}
+		}
+
+		expense.TotalPrice = amount
+		expense.OriginalAmount = amount
+		expense.Currency = currency
+		return true
+	}
+	return false
+}
+
+// matchMultiWordCategory matches categories whose name has several words, such as
+// "home loan emi", before single words like "car" are read as other fields
+func (p *Parser) matchMultiWordCategory(tokens []string, used []bool) *models.Category {
+	for _, category := range p.categories {
+		words := strings.Fields(strings.ToLower(category.Name))
+		if len(words) < 2 {
+			continue
+		}
+		for i := 0; i+len(words) <= len(tokens); i++ {
+			matched := true
+			for j, word := range words {
+				if used[i+j] || strings.ToLower(tokens[i+j]) != word {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				for j := range words {
+					used[i+j] = true
+				}
+				return category
+			}
+		}
+	}
+	return nil
+}
+
+// matchCategory matches the first word that names a category. Exact names and aliases
+// are preferred over fuzzy matches so "dining" never becomes something closer by typo.
+func (p *Parser) matchCategory(tokens []string, used []bool) *models.Category {
+	for i, token := range tokens {
+		if used[i] {
+			continue
+		}
+		if category := p.exactCategory(strings.ToLower(token)); category != nil {
+			used[i] = true
+			return category
+		}
+	}
+
+	for i, token := range tokens {
+		if used[i] {
+			continue
+		}
+		if category := p.fuzzyCategory(strings.ToLower(token)); category != nil {
+			used[i] = true
+			return category
+		}
+	}
+	return nil
+}
+
+// exactCategory matches a word against category names, their parts ("coffee/tea") and aliases
+func (p *Parser) exactCategory(word string) *models.Category {
+	for _, category := range p.categories {
+		name := strings.ToLower(category.Name)
+		if word == name {
+			return category
+		}
+		for _, part := range strings.Split(name, "/") {
+			if word == part {
+				return category
+			}
+		}
+	}
+
+	if alias, ok := categoryAliases[word]; ok {
+		return p.categoryByName(alias)
+	}
+	return nil
+}
+
+// fuzzyCategory matches words of at least four letters by prefix ("groc"), singular form
+// ("movie") or a single typo ("grocey")
+func (p *Parser) fuzzyCategory(word string) *models.Category {
+	if len(word) < 4 {
+		return nil
+	}
+
+	for _, category := range p.categories {
+		name := strings.ToLower(category.Name)
+		if strings.Contains(name, " ") {
+			continue
+		}
+		if strings.HasPrefix(name, word) || strings.TrimSuffix(name, "s") == word || levenshtein(word, name) <= 1 {
+			return category
+		}
+	}
+	return nil
+}
+
+// categoryByName returns the category with the given name, ignoring case
+func (p *Parser) categoryByName(name string) *models.Category {
+	for _, category := range p.categories {
+		if strings.EqualFold(category.Name, name) {
+			return category
+		}
+	}
+	return nil
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
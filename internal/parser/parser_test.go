@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNow is Wednesday 11 March 2026
+var testNow = time.Date(2026, time.March, 11, 18, 30, 0, 0, time.UTC)
+
+func newTestParser() *Parser {
+	p := New([]*models.Category{
+		{ID: 1, Name: "Petrol", Emoji: "⛽", Group: "Vehicle"},
+		{ID: 2, Name: "Car Loan EMI", Emoji: "🚘", Group: "Vehicle"},
+		{ID: 3, Name: "Dining", Emoji: "🍽️", Group: "Daily Living"},
+		{ID: 4, Name: "Grocery", Emoji: "🛒", Group: "Daily Living"},
+		{ID: 5, Name: "Coffee/Tea", Emoji: "☕", Group: "Daily Living"},
+		{ID: 6, Name: "Movies", Emoji: "🎬", Group: "Entertainment"},
+		{ID: 7, Name: "Flights", Emoji: "✈️", Group: "Travel"},
+	})
+	p.now = func() time.Time { return testNow }
+	return p
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected models.Expense
+	}{
+		{
+			name:  "amount category notes and date",
+			input: "450 dining lunch with team yesterday",
+			expected: models.Expense{
+				CategoryName: "Dining", TotalPrice: 450, OriginalAmount: 450,
+				Notes: "lunch with team", Timestamp: testNow.AddDate(0, 0, -1),
+			},
+		},
+		{
+			name:  "vehicle fields",
+			input: "petrol 2000 car odo 45210 @104.5",
+			expected: models.Expense{
+				CategoryName: "Petrol", TotalPrice: 2000, OriginalAmount: 2000,
+				Odometer: 45210, PetrolPrice: 104.5, Timestamp: testNow,
+			},
+		},
+		{
+			name:  "multi word category wins over vehicle word",
+			input: "car loan emi 15000",
+			expected: models.Expense{
+				CategoryName: "Car Loan EMI", TotalPrice: 15000, OriginalAmount: 15000, Timestamp: testNow,
+			},
+		},
+		{
+			name:  "currency code and alias",
+			input: "coffee 4.5 EUR airport",
+			expected: models.Expense{
+				CategoryName: "Coffee/Tea", TotalPrice: 4.5, OriginalAmount: 4.5, Currency: "EUR",
+				Notes: "airport", Timestamp: testNow,
+			},
+		},
+		{
+			name:  "currency symbol and weekday",
+			input: "flights $320 last monday",
+			expected: models.Expense{
+				CategoryName: "Flights", TotalPrice: 320, OriginalAmount: 320, Currency: "USD",
+				Timestamp: testNow.AddDate(0, 0, -2),
+			},
+		},
+		{
+			name:  "typo and days ago",
+			input: "grocey 1,250 3 days ago",
+			expected: models.Expense{
+				CategoryName: "Grocery", TotalPrice: 1250, OriginalAmount: 1250, Timestamp: testNow.AddDate(0, 0, -3),
+			},
+		},
+		{
+			name:  "singular name and iso date",
+			input: "movie 600 on 2026-02-14 with family",
+			expected: models.Expense{
+				CategoryName: "Movies", TotalPrice: 600, OriginalAmount: 600, Notes: "with family",
+				Timestamp: time.Date(2026, 2, 14, 18, 30, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense, err := newTestParser().Parse(tt.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected.CategoryName, expense.CategoryName)
+			assert.Equal(t, tt.expected.TotalPrice, expense.TotalPrice)
+			assert.Equal(t, tt.expected.OriginalAmount, expense.OriginalAmount)
+			assert.Equal(t, tt.expected.Currency, expense.Currency)
+			assert.Equal(t, tt.expected.Odometer, expense.Odometer)
+			assert.Equal(t, tt.expected.PetrolPrice, expense.PetrolPrice)
+			assert.Equal(t, tt.expected.Notes, expense.Notes)
+			assert.Equal(t, tt.expected.Timestamp, expense.Timestamp)
+		})
+	}
+
+	t.Run("vehicle type", func(t *testing.T) {
+		expense, err := newTestParser().Parse("petrol 500 bike")
+		require.NoError(t, err)
+		assert.True(t, expense.VehicleType.Valid)
+		assert.Equal(t, "BIKE", expense.VehicleType.String)
+
+		expense, err = newTestParser().Parse("petrol 300 scooter")
+		require.NoError(t, err)
+		assert.Equal(t, "SCOOTER", expense.VehicleType.String)
+	})
+}
+
+func TestParser_ParseErrors(t *testing.T) {
+	_, err := newTestParser().Parse("dining with team")
+	assert.ErrorIs(t, err, ErrNoAmount)
+
+	_, err = newTestParser().Parse("450 something random")
+	assert.ErrorIs(t, err, ErrNoCategory)
+
+	_, err = newTestParser().Parse("")
+	assert.ErrorIs(t, err, ErrNoAmount)
+}
+
+func TestParser_MatchCategory(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "UPI/SWIGGY-DINING/9876", expected: "Dining"},
+		{input: "POS HP FUEL STATION", expected: "Petrol"},
+		{input: "NACH CAR LOAN EMI MAR", expected: "Car Loan EMI"},
+		{input: "PVR MOVIE TICKETS", expected: "Movies"},
+		{input: "NEFT TRANSFER 12345", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			category := newTestParser().MatchCategory(tt.input)
+			if tt.expected == "" {
+				assert.Nil(t, category)
+				return
+			}
+			require.NotNil(t, category)
+			assert.Equal(t, tt.expected, category.Name)
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedAmount   float64
+		expectedCurrency string
+		expectError      bool
+	}{
+		{input: "450", expectedAmount: 450},
+		{input: " 45.5 EUR ", expectedAmount: 45.5, expectedCurrency: "EUR"},
+		{input: "usd 20", expectedAmount: 20, expectedCurrency: "USD"},
+		{input: "€45", expectedAmount: 45, expectedCurrency: "EUR"},
+		{input: "12.5£", expectedAmount: 12.5, expectedCurrency: "GBP"},
+		{input: "THB 1200", expectedAmount: 1200, expectedCurrency: "THB"},
+		{input: "abc", expectError: true},
+		{input: "45 EURO", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, currency, err := ParseAmount(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAmount, amount)
+			assert.Equal(t, tt.expectedCurrency, currency)
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("petrol", "petrol"))
+	assert.Equal(t, 1, levenshtein("grocey", "grocery"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}
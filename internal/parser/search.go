@@ -0,0 +1,350 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// searchFillerWords describe the request rather than the expenses, e.g. "show me all ... expenses"
+var searchFillerWords = map[string]bool{
+	"a": true, "all": true, "an": true, "and": true, "any": true, "did": true, "during": true,
+	"expense": true, "expenses": true, "find": true, "for": true, "from": true, "i": true,
+	"in": true, "list": true, "me": true, "my": true, "of": true, "on": true, "or": true,
+	"related": true, "search": true, "show": true, "spend": true, "spent": true, "the": true,
+	"to": true, "what": true, "with": true,
+}
+
+// expensiveWords rank larger amounts first
+var expensiveWords = map[string]bool{
+	"expensive": true, "costly": true, "pricey": true, "priciest": true,
+	"biggest": true, "largest": true, "highest": true,
+}
+
+// Amount comparisons, e.g. "over 1000", "less than 500" or "at least 2k"
+var (
+	minAmountWords = map[string]bool{"over": true, "above": true, "more": true, "greater": true, "exceeding": true, ">": true, ">=": true}
+	maxAmountWords = map[string]bool{"under": true, "below": true, "less": true, "<": true, "<=": true}
+)
+
+// monthNames maps full and short month names to months
+var monthNames = func() map[string]time.Month {
+	names := map[string]time.Month{"sept": time.September}
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		names[name] = m
+		names[name[:3]] = m
+	}
+	return names
+}()
+
+// ParseSearch splits a search query such as "fuel over 1000 last month" into filters and the
+// free text left to rank. It understands:
+//   - dates: today, yesterday, this/last week, month or year, last N days, weeks or months,
+//     month names with an optional year, "in 2025", YYYY-MM-DD and "since" any of those
+//   - amounts: over, above, under, below, more/less than, at least, at most, between A and B, >1000, 2k
+//   - categories and category groups by name or alias, e.g. "fuel" or "vehicle"
+//   - car, bike or scooter for the vehicle type, and "expensive" to rank larger amounts first
+func (p *Parser) ParseSearch(text string) *models.SearchQuery {
+	tokens := strings.Fields(text)
+	for i, token := range tokens {
+		tokens[i] = strings.Trim(token, ",.?!;:\"'")
+	}
+	used := make([]bool, len(tokens))
+	for i, token := range tokens {
+		used[i] = token == ""
+	}
+	query := &models.SearchQuery{}
+
+	p.parseSearchDates(tokens, used, query)
+	parseSearchAmounts(tokens, used, query)
+
+	// Multi-word categories first so "car loan emi" is not read as the car
+	for category := p.matchMultiWordCategory(tokens, used); category != nil; category = p.matchMultiWordCategory(tokens, used) {
+		query.Categories = append(query.Categories, category)
+	}
+
+	for i, token := range tokens {
+		if used[i] {
+			continue
+		}
+		word := strings.ToLower(token)
+		if vehicle, ok := vehicleWords[word]; ok && query.VehicleType == "" {
+			query.VehicleType = vehicle
+			used[i] = true
+		} else if expensiveWords[word] {
+			query.ByAmount = true
+			used[i] = true
+		} else if searchFillerWords[word] {
+			used[i] = true
+		}
+	}
+
+	query.Groups = p.matchGroups(tokens, used)
+	for category := p.matchCategory(tokens, used); category != nil; category = p.matchCategory(tokens, used) {
+		query.Categories = appendCategory(query.Categories, category)
+	}
+
+	var words []string
+	for i, token := range tokens {
+		if !used[i] {
+			words = append(words, token)
+		}
+	}
+	query.Text = strings.Join(words, " ")
+
+	return query
+}
+
+// appendCategory adds a category unless it is already in the list
+func appendCategory(categories []*models.Category, category *models.Category) []*models.Category {
+	for _, existing := range categories {
+		if existing.ID == category.ID {
+			return categories
+		}
+	}
+	return append(categories, category)
+}
+
+// matchGroups matches category group names such as "vehicle" or "daily living"
+func (p *Parser) matchGroups(tokens []string, used []bool) []string {
+	var groups []string
+	seen := make(map[string]bool)
+	for _, category := range p.categories {
+		if seen[category.Group] {
+			continue
+		}
+		seen[category.Group] = true
+
+		words := strings.Fields(strings.ToLower(category.Group))
+		for i := 0; i+len(words) <= len(tokens); i++ {
+			matched := len(words) > 0
+			for j, word := range words {
+				if used[i+j] || strings.ToLower(tokens[i+j]) != word {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				for j := range words {
+					used[i+j] = true
+				}
+				groups = append(groups, category.Group)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// parseSearchDates reads the first date range in the query
+func (p *Parser) parseSearchDates(tokens []string, used []bool, query *models.SearchQuery) {
+	now := p.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	word := func(i int) string {
+		if i < 0 || i >= len(tokens) || used[i] {
+			return ""
+		}
+		return strings.ToLower(tokens[i])
+	}
+	setRange := func(from, to time.Time, first, last int) {
+		// "since" keeps everything after the start
+		if word(first-1) == "since" {
+			first--
+			query.From = &from
+		} else {
+			query.From, query.To = &from, &to
+		}
+		for i := first; i <= last; i++ {
+			used[i] = true
+		}
+	}
+
+	for i := range tokens {
+		switch word(i) {
+		case "today":
+			setRange(today, today.AddDate(0, 0, 1), i, i)
+			return
+		case "yesterday":
+			setRange(today.AddDate(0, 0, -1), today, i, i)
+			return
+		case "this", "last", "past":
+			if from, to, ok := calendarPeriod(today, word(i), word(i+1)); ok {
+				setRange(from, to, i, i+1)
+				return
+			}
+			if word(i) == "this" {
+				continue
+			}
+			if n, err := strconv.Atoi(word(i + 1)); err == nil && n > 0 {
+				if from, ok := rollingPeriod(today, n, word(i+2)); ok {
+					// Rolling periods end now
+					query.From = &from
+					used[i], used[i+1], used[i+2] = true, true, true
+					return
+				}
+			}
+		}
+
+		if month, ok := monthNames[word(i)]; ok {
+			year := today.Year()
+			last := i
+			if y, err := strconv.Atoi(word(i + 1)); err == nil && y >= 1900 && y <= 9999 {
+				year, last = y, i+1
+			} else if month > today.Month() {
+				// A month without a year means the most recent one
+				year--
+			}
+			from := time.Date(year, month, 1, 0, 0, 0, 0, today.Location())
+			setRange(from, from.AddDate(0, 1, 0), i, last)
+			return
+		}
+
+		if prev := word(i - 1); prev == "in" || prev == "during" || prev == "since" {
+			if y, err := strconv.Atoi(word(i)); err == nil && y >= 1900 && y <= 9999 {
+				from := time.Date(y, time.January, 1, 0, 0, 0, 0, today.Location())
+				setRange(from, from.AddDate(1, 0, 0), i, i)
+				return
+			}
+		}
+
+		if date, err := time.ParseInLocation("2006-01-02", word(i), today.Location()); err == nil {
+			setRange(date, date.AddDate(0, 0, 1), i, i)
+			return
+		}
+	}
+}
+
+// calendarPeriod returns the current or previous calendar week, month or year. Weeks start on Monday.
+// "past" means the rolling period ending today instead, e.g. "past month".
+func calendarPeriod(today time.Time, which, unit string) (time.Time, time.Time, bool) {
+	if which == "past" {
+		from, ok := rollingPeriod(today, 1, unit)
+		return from, today.AddDate(0, 0, 1), ok
+	}
+
+	var start time.Time
+	var next func(time.Time, int) time.Time
+	switch unit {
+	case "week":
+		start = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		next = func(t time.Time, n int) time.Time { return t.AddDate(0, 0, 7*n) }
+	case "month":
+		start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		next = func(t time.Time, n int) time.Time { return t.AddDate(0, n, 0) }
+	case "year":
+		start = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+		next = func(t time.Time, n int) time.Time { return t.AddDate(n, 0, 0) }
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	if which == "last" {
+		return next(start, -1), start, true
+	}
+	return start, next(start, 1), true
+}
+
+// rollingPeriod returns the start of the last n days, weeks, months or years including today
+func rollingPeriod(today time.Time, n int, unit string) (time.Time, bool) {
+	switch strings.TrimSuffix(unit, "s") {
+	case "day":
+		return today.AddDate(0, 0, 1-n), true
+	case "week":
+		return today.AddDate(0, 0, 1-7*n), true
+	case "month":
+		return today.AddDate(0, -n, 1), true
+	case "year":
+		return today.AddDate(-n, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+// parseSearchAmounts reads amount bounds such as "over 1000", "between 100 and 500" or "<2k"
+func parseSearchAmounts(tokens []string, used []bool, query *models.SearchQuery) {
+	amountAt := func(i int) (float64, bool) {
+		if i >= len(tokens) || used[i] {
+			return 0, false
+		}
+		return searchAmount(tokens[i])
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if used[i] {
+			continue
+		}
+		word := strings.ToLower(tokens[i])
+
+		// Operators written together with the amount, e.g. ">1000" or "<=2k"
+		for _, op := range []string{">=", "<=", ">", "<"} {
+			if amount, ok := searchAmount(strings.TrimPrefix(word, op)); ok && strings.HasPrefix(word, op) && len(word) > len(op) {
+				setAmountBound(query, op, amount)
+				used[i] = true
+				break
+			}
+		}
+		if used[i] {
+			continue
+		}
+
+		if word == "between" {
+			low, okLow := amountAt(i + 1)
+			high, okHigh := amountAt(i + 3)
+			if okLow && okHigh && i+2 < len(tokens) && strings.EqualFold(tokens[i+2], "and") {
+				low, high = min(low, high), max(low, high)
+				query.MinAmount, query.MaxAmount = &low, &high
+				used[i], used[i+1], used[i+2], used[i+3] = true, true, true, true
+				i += 3
+			}
+			continue
+		}
+
+		op, next := word, i+1
+		if word == "at" && i+1 < len(tokens) {
+			switch strings.ToLower(tokens[i+1]) {
+			case "least":
+				op, next = ">=", i+2
+			case "most":
+				op, next = "<=", i+2
+			}
+		}
+		if !minAmountWords[op] && !maxAmountWords[op] {
+			continue
+		}
+		if next < len(tokens) && strings.EqualFold(tokens[next], "than") {
+			next++
+		}
+		if amount, ok := amountAt(next); ok {
+			setAmountBound(query, op, amount)
+			for j := i; j <= next; j++ {
+				used[j] = true
+			}
+			i = next
+		}
+	}
+}
+
+// setAmountBound stores an amount as the lower or upper bound for the comparison word
+func setAmountBound(query *models.SearchQuery, op string, amount float64) {
+	if minAmountWords[op] {
+		query.MinAmount = &amount
+	} else {
+		query.MaxAmount = &amount
+	}
+}
+
+// searchAmount parses an amount in a search query, allowing a "k" suffix for thousands
+func searchAmount(token string) (float64, bool) {
+	multiplier := 1.0
+	if trimmed := strings.TrimSuffix(strings.ToLower(token), "k"); trimmed != strings.ToLower(token) {
+		token, multiplier = trimmed, 1000
+	}
+	amount, _, ok := parseAmountToken(token)
+	if !ok || amount < 0 {
+		return 0, false
+	}
+	return amount * multiplier, true
+}
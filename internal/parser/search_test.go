@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func amount(value float64) *float64 {
+	return &value
+}
+
+func categoryNames(categories []*models.Category) []string {
+	var names []string
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	return names
+}
+
+func TestParser_ParseSearch(t *testing.T) {
+	tests := []struct {
+		input      string
+		text       string
+		from, to   *time.Time
+		min, max   *float64
+		categories []string
+		groups     []string
+		vehicle    string
+		byAmount   bool
+	}{
+		// Examples from the /search instructions
+		{input: "Find all fuel expenses from last month", categories: []string{"Petrol"}, from: date(2026, 2, 1), to: date(2026, 3, 1)},
+		{input: "Show me expensive car repairs", text: "repairs", vehicle: "CAR", byAmount: true},
+		{input: "Find expenses related to maintenance", text: "maintenance"},
+
+		{input: "dining over 500 this week", categories: []string{"Dining"}, min: amount(500), from: date(2026, 3, 9), to: date(2026, 3, 16)},
+		{input: "coffee less than 200 yesterday", categories: []string{"Coffee/Tea"}, max: amount(200), from: date(2026, 3, 10), to: date(2026, 3, 11)},
+		{input: "between 1k and 2,500 in february", min: amount(1000), max: amount(2500), from: date(2026, 2, 1), to: date(2026, 3, 1)},
+		{input: "at least 100 at most 300 today", min: amount(100), max: amount(300), from: date(2026, 3, 11), to: date(2026, 3, 12)},
+		{input: ">2000 flights in december", categories: []string{"Flights"}, min: amount(2000), from: date(2025, 12, 1), to: date(2026, 1, 1)},
+		{input: "petrol bike last 30 days", categories: []string{"Petrol"}, vehicle: "BIKE", from: date(2026, 2, 10)},
+		{input: "vehicle expenses since jan 2025", groups: []string{"Vehicle"}, from: date(2025, 1, 1)},
+		{input: "daily living in 2025", groups: []string{"Daily Living"}, from: date(2025, 1, 1), to: date(2026, 1, 1)},
+		{input: "car loan emi last year", categories: []string{"Car Loan EMI"}, from: date(2025, 1, 1), to: date(2026, 1, 1)},
+		{input: "movies and grocery on 2026-02-14", categories: []string{"Movies", "Grocery"}, from: date(2026, 2, 14), to: date(2026, 2, 15)},
+		{input: "birthday gift for mom", text: "birthday gift mom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			query := newTestParser().ParseSearch(tt.input)
+
+			assert.Equal(t, tt.text, query.Text)
+			assert.Equal(t, tt.from, query.From)
+			assert.Equal(t, tt.to, query.To)
+			assert.Equal(t, tt.min, query.MinAmount)
+			assert.Equal(t, tt.max, query.MaxAmount)
+			assert.Equal(t, tt.categories, categoryNames(query.Categories))
+			assert.Equal(t, tt.groups, query.Groups)
+			assert.Equal(t, tt.vehicle, query.VehicleType)
+			assert.Equal(t, tt.byAmount, query.ByAmount)
+		})
+	}
+
+	t.Run("only text has no filters", func(t *testing.T) {
+		assert.False(t, newTestParser().ParseSearch("weekend trip").HasFilters())
+		assert.True(t, newTestParser().ParseSearch("trip last month").HasFilters())
+	})
+}
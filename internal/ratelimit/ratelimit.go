@@ -0,0 +1,206 @@
+// Package ratelimit protects the bot from users sending more than it should handle. Every user
+// gets a token bucket per class of request, so heavy commands can be limited more strictly than
+// everyday ones, and users who keep hitting the limits are banned for a while.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"golang.org/x/time/rate"
+)
+
+// Classes of requests with their own limits
+const (
+	ClassDefault = "default" // Messages, button presses and light commands
+	ClassHeavy   = "heavy"   // Requests that are expensive to answer, such as /export, search queries and statements to import
+	ClassUpload  = "upload"  // Photos and documents, which are downloaded and possibly read by OCR
+)
+
+// Limit lets a user make Requests requests per Per, all at once or spread out
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses a limit written as "<requests>/<duration>", such as "20/10s" or "3/m"
+func ParseLimit(s string) (Limit, error) {
+	requests, per, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want <requests>/<duration>", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid number of requests in rate limit %q", s)
+	}
+	// A unit alone, as in "3/m", is one of it
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid duration in rate limit %q", s)
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// String formats the limit the way ParseLimit reads it
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// rate is how fast the bucket refills
+func (l Limit) rate() rate.Limit {
+	return rate.Limit(float64(l.Requests) / l.Per.Seconds())
+}
+
+// Config sets the limits and how violations are punished
+type Config struct {
+	Limits      map[string]Limit // By class; requests of unknown classes count as ClassDefault
+	BanAfter    int              // Refused requests within BanWindow that get a user banned, 0 never bans
+	BanWindow   time.Duration
+	BanDuration time.Duration
+	IdleTimeout time.Duration // Users who have been quiet this long are forgotten, freeing their buckets
+}
+
+// DefaultConfig returns the limits used unless configured otherwise
+func DefaultConfig() Config {
+	return Config{
+		Limits: map[string]Limit{
+			ClassDefault: {Requests: 20, Per: 10 * time.Second},
+			ClassHeavy:   {Requests: 3, Per: time.Minute},
+			ClassUpload:  {Requests: 10, Per: 30 * time.Second},
+		},
+		BanAfter:    10,
+		BanWindow:   time.Minute,
+		BanDuration: 10 * time.Minute,
+		IdleTimeout: 30 * time.Minute,
+	}
+}
+
+// Limiter keeps the buckets and bans of every user. It is safe for concurrent use.
+type Limiter struct {
+	config Config
+	now    func() time.Time
+
+	mu    sync.Mutex
+	users map[int64]*user
+}
+
+// user is what the limiter knows about one user
+type user struct {
+	buckets     map[string]*rate.Limiter
+	lastSeen    time.Time
+	violations  int       // Refused requests since windowStart
+	windowStart time.Time // When violations started being counted
+	bannedUntil time.Time
+	limited     bool // Refused since the last allowed request, so the user has been told already
+}
+
+// New creates a limiter. Missing limits are taken from DefaultConfig.
+func New(config Config) *Limiter {
+	defaults := DefaultConfig()
+	limits := make(map[string]Limit, len(defaults.Limits))
+	for class, limit := range defaults.Limits {
+		limits[class] = limit
+	}
+	for class, limit := range config.Limits {
+		if limit.Requests > 0 && limit.Per > 0 {
+			limits[class] = limit
+		}
+	}
+	config.Limits = limits
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaults.IdleTimeout
+	}
+	return &Limiter{config: config, now: time.Now, users: make(map[int64]*user)}
+}
+
+// Allow checks a request of class from a user against the limits. It returns nil when the
+// request may go ahead, or a RateLimitError whose RetryAfter says how long to wait. Only the
+// first refusal in a row, and the one that gets the user banned, need to be reported to the
+// user, which notify tells; answering every refused request would let spam through to Telegram.
+func (l *Limiter) Allow(userID int64, class string) (notify bool, err error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.users[userID]
+	if u == nil {
+		u = &user{buckets: make(map[string]*rate.Limiter)}
+		l.users[userID] = u
+	}
+	u.lastSeen = now
+
+	if now.Before(u.bannedUntil) {
+		return u.refuse(), errors.NewRateLimitErrorWithRetry("Temporarily banned for sending too many requests", u.bannedUntil.Sub(now))
+	}
+
+	limit, ok := l.config.Limits[class]
+	if !ok {
+		class, limit = ClassDefault, l.config.Limits[ClassDefault]
+	}
+	bucket := u.buckets[class]
+	if bucket == nil {
+		bucket = rate.NewLimiter(limit.rate(), limit.Requests)
+		u.buckets[class] = bucket
+	}
+
+	reservation := bucket.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		u.limited = false
+		return false, nil
+	}
+	reservation.CancelAt(now)
+
+	// Count the violation, banning users who keep going
+	if now.Sub(u.windowStart) > l.config.BanWindow {
+		u.windowStart, u.violations = now, 0
+	}
+	u.violations++
+	if l.config.BanAfter > 0 && u.violations >= l.config.BanAfter {
+		u.bannedUntil = now.Add(l.config.BanDuration)
+		u.violations = 0
+		u.limited = true
+		return true, errors.NewRateLimitErrorWithRetry("Temporarily banned for sending too many requests", l.config.BanDuration)
+	}
+	return u.refuse(), errors.NewRateLimitErrorWithRetry("Too many requests", delay)
+}
+
+// refuse marks the user as limited and reports whether they still need to be told
+func (u *user) refuse() bool {
+	notify := !u.limited
+	u.limited = true
+	return notify
+}
+
+// Evict forgets users who have been quiet for longer than the idle timeout and are not banned,
+// and returns how many there were
+func (l *Limiter) Evict() int {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	evicted := 0
+	for id, u := range l.users {
+		if now.Sub(u.lastSeen) > l.config.IdleTimeout && !now.Before(u.bannedUntil) {
+			delete(l.users, id)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Users returns how many users the limiter keeps buckets for
+func (l *Limiter) Users() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.users)
+}
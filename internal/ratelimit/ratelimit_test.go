@@ -0,0 +1,185 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLimiter creates a limiter on a clock the test moves by hand
+func newTestLimiter(config Config) (*Limiter, *time.Time) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := New(config)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "20/10s", want: Limit{Requests: 20, Per: 10 * time.Second}},
+		{in: " 3/m ", want: Limit{Requests: 3, Per: time.Minute}},
+		{in: "100/1h30m", want: Limit{Requests: 100, Per: 90 * time.Minute}},
+		{in: "20", wantErr: true},
+		{in: "0/s", wantErr: true},
+		{in: "x/s", wantErr: true},
+		{in: "5/", wantErr: true},
+		{in: "5/-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			again, err := ParseLimit(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l, now := newTestLimiter(Config{Limits: map[string]Limit{
+		ClassDefault: {Requests: 2, Per: 10 * time.Second},
+		ClassHeavy:   {Requests: 1, Per: time.Minute},
+	}})
+
+	for i := 0; i < 2; i++ {
+		_, err := l.Allow(1, ClassDefault)
+		require.NoError(t, err)
+	}
+
+	notify, err := l.Allow(1, ClassDefault)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.True(t, appErr.IsRateLimitError())
+	assert.Equal(t, 5*time.Second, appErr.RetryAfter)
+	assert.True(t, notify, "the first refusal is reported")
+
+	notify, err = l.Allow(1, ClassDefault)
+	assert.Error(t, err)
+	assert.False(t, notify, "later refusals are not")
+
+	// Users and classes have their own buckets
+	_, err = l.Allow(2, ClassDefault)
+	assert.NoError(t, err)
+	_, err = l.Allow(1, ClassHeavy)
+	assert.NoError(t, err)
+	_, err = l.Allow(1, ClassHeavy)
+	assert.Error(t, err)
+
+	// Unknown classes share the default bucket
+	_, err = l.Allow(1, "unknown")
+	assert.Error(t, err)
+
+	// Buckets refill over time
+	*now = now.Add(5 * time.Second)
+	notify, err = l.Allow(1, ClassDefault)
+	assert.NoError(t, err)
+	assert.False(t, notify)
+	_, err = l.Allow(1, ClassDefault)
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 5*time.Second, appErr.RetryAfter)
+}
+
+func TestLimiter_Ban(t *testing.T) {
+	l, now := newTestLimiter(Config{
+		Limits:      map[string]Limit{ClassDefault: {Requests: 1, Per: time.Second}},
+		BanAfter:    3,
+		BanWindow:   time.Minute,
+		BanDuration: 10 * time.Minute,
+	})
+
+	_, err := l.Allow(1, ClassDefault)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = l.Allow(1, ClassDefault)
+		require.Error(t, err)
+	}
+
+	notify, err := l.Allow(1, ClassDefault)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.True(t, notify, "the ban is reported")
+	assert.Equal(t, 10*time.Minute, appErr.RetryAfter)
+
+	// The ban outlasts the bucket refilling
+	*now = now.Add(time.Minute)
+	notify, err = l.Allow(1, ClassDefault)
+	require.ErrorAs(t, err, &appErr)
+	assert.False(t, notify)
+	assert.Equal(t, 9*time.Minute, appErr.RetryAfter)
+
+	*now = now.Add(9 * time.Minute)
+	_, err = l.Allow(1, ClassDefault)
+	assert.NoError(t, err)
+}
+
+func TestLimiter_BanWindow(t *testing.T) {
+	l, now := newTestLimiter(Config{
+		Limits:      map[string]Limit{ClassDefault: {Requests: 1, Per: time.Minute}},
+		BanAfter:    3,
+		BanWindow:   time.Minute,
+		BanDuration: time.Hour,
+	})
+
+	// Violations spread out further than the window never add up to a ban
+	for i := 0; i < 5; i++ {
+		_, err := l.Allow(1, ClassDefault)
+		require.NoError(t, err)
+		_, err = l.Allow(1, ClassDefault)
+		require.Error(t, err)
+		*now = now.Add(61 * time.Second)
+	}
+	_, err := l.Allow(1, ClassDefault)
+	assert.NoError(t, err)
+}
+
+func TestLimiter_Evict(t *testing.T) {
+	l, now := newTestLimiter(Config{
+		Limits:      map[string]Limit{ClassDefault: {Requests: 1, Per: time.Second}},
+		BanAfter:    1,
+		BanWindow:   time.Minute,
+		BanDuration: time.Hour,
+		IdleTimeout: time.Minute,
+	})
+
+	_, err := l.Allow(1, ClassDefault)
+	require.NoError(t, err)
+	_, err = l.Allow(2, ClassDefault)
+	require.NoError(t, err)
+	_, err = l.Allow(2, ClassDefault)
+	require.Error(t, err, "user 2 is banned")
+	assert.Equal(t, 2, l.Users())
+
+	*now = now.Add(30 * time.Second)
+	assert.Zero(t, l.Evict())
+
+	// Idle users are forgotten, but bans are kept until they are over
+	*now = now.Add(time.Minute)
+	assert.Equal(t, 1, l.Evict())
+	assert.Equal(t, 1, l.Users())
+	_, err = l.Allow(2, ClassDefault)
+	assert.Error(t, err)
+}
+
+func TestNew_FillsInDefaults(t *testing.T) {
+	l := New(Config{Limits: map[string]Limit{ClassHeavy: {Requests: 1, Per: time.Hour}, ClassUpload: {}}})
+
+	defaults := DefaultConfig()
+	assert.Equal(t, Limit{Requests: 1, Per: time.Hour}, l.config.Limits[ClassHeavy])
+	assert.Equal(t, defaults.Limits[ClassDefault], l.config.Limits[ClassDefault])
+	assert.Equal(t, defaults.Limits[ClassUpload], l.config.Limits[ClassUpload], "invalid limits are ignored")
+	assert.Equal(t, defaults.IdleTimeout, l.config.IdleTimeout)
+}
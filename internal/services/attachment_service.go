@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// MaxAttachmentSize is the largest receipt file that can be attached to an expense
+const MaxAttachmentSize = 10 << 20
+
+// attachmentTypes maps the receipt file types that can be attached to the extension they are stored with
+var attachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/heic":      ".heic",
+	"application/pdf": ".pdf",
+}
+
+// AttachmentService attaches receipt photos and PDFs to expenses, keeping the files in a blob store
+type AttachmentService struct {
+	db            database.Storage
+	logger        logger.Logger
+	validator     *validation.Validator
+	blobs         blobstore.Store
+	ledgerService *LedgerService
+}
+
+// NewAttachmentService creates a new attachment service. Access to shared ledger expenses is
+// checked with s.ledgerService.
+func NewAttachmentService(db database.Storage, logger logger.Logger, blobs blobstore.Store, ledgerService *LedgerService) *AttachmentService {
+	return &AttachmentService{
+		db:            db,
+		logger:        logger,
+		validator:     validation.NewValidator(),
+		blobs:         blobs,
+		ledgerService: ledgerService,
+	}
+}
+
+// AddAttachment attaches a receipt file to an expense the user may edit. An empty content type
+// is detected from the data.
+func (s *AttachmentService) AddAttachment(ctx context.Context, telegramID, expenseID int64, fileName, contentType string, data []byte) (*models.Attachment, error) {
+	if len(data) == 0 {
+		return nil, errors.NewValidationError("Empty file", "The receipt file is empty")
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, errors.NewValidationError("File too large",
+			fmt.Sprintf("Receipts can be at most %d MB", MaxAttachmentSize>>20))
+	}
+	contentType = attachmentContentType(contentType, data)
+	ext, ok := attachmentTypes[contentType]
+	if !ok {
+		return nil, errors.NewValidationError("Unsupported file type", "Receipts must be a photo or a PDF")
+	}
+
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.getExpense(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ledgerService.checkExpenseAccess(ctx, user.ID, expense); err != nil {
+		return nil, err
+	}
+
+	name, err := newAttachmentName()
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to name receipt", err)
+	}
+	if fileName = path.Base(strings.TrimSpace(fileName)); fileName == "." || fileName == "/" {
+		fileName = "receipt" + ext
+	}
+	attachment := &models.Attachment{
+		ExpenseID:   expense.ID,
+		UserID:      user.ID,
+		StorageKey:  fmt.Sprintf("receipts/%d/%d/%s%s", expense.UserID, expense.ID, name, ext),
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+	}
+
+	if err := s.blobs.Put(ctx, attachment.StorageKey, data, contentType); err != nil {
+		s.logger.Error(ctx, "Failed to store receipt", logger.ErrorField(err))
+		return nil, errors.NewInternalError("Failed to store receipt", err)
+	}
+	if err := s.db.CreateAttachment(ctx, attachment); err != nil {
+		s.logger.Error(ctx, "Failed to create attachment", logger.ErrorField(err))
+		if delErr := s.blobs.Delete(ctx, attachment.StorageKey); delErr != nil {
+			s.logger.Error(ctx, "Failed to remove unrecorded receipt", logger.ErrorField(delErr))
+		}
+		return nil, errors.NewDatabaseError("Failed to save receipt", err)
+	}
+
+	return attachment, nil
+}
+
+// GetAttachment returns an attachment and its file, if the user may see its expense
+func (s *AttachmentService) GetAttachment(ctx context.Context, telegramID, attachmentID int64) (*models.Attachment, []byte, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.db.GetAttachmentByID(ctx, attachmentID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, nil, errors.NewNotFoundError("Receipt not found", fmt.Sprintf("Receipt with ID %d not found", attachmentID))
+		}
+		s.logger.Error(ctx, "Failed to get attachment", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to get receipt", err)
+	}
+	expense, err := s.getExpense(ctx, attachment.ExpenseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.ledgerService.checkExpenseVisible(ctx, user.ID, expense); err != nil {
+		return nil, nil, err
+	}
+
+	data, err := s.blobs.Get(ctx, attachment.StorageKey)
+	if stderrors.Is(err, blobstore.ErrNotFound) {
+		return nil, nil, errors.NewNotFoundError("Receipt file missing", "The receipt file is no longer in storage")
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to read receipt", logger.ErrorField(err))
+		return nil, nil, errors.NewInternalError("Failed to read receipt", err)
+	}
+
+	return attachment, data, nil
+}
+
+// GetAttachments returns the attachments of the given expenses by expense ID. The caller has
+// already checked the user may see them, as for expenses listed or found by a search.
+func (s *AttachmentService) GetAttachments(ctx context.Context, expenses []*models.Expense) (map[int64][]*models.Attachment, error) {
+	ids := make([]int64, len(expenses))
+	for i, expense := range expenses {
+		ids[i] = expense.ID
+	}
+
+	attachments, err := s.db.GetAttachmentsByExpenseIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get attachments", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get receipts", err)
+	}
+
+	byExpense := make(map[int64][]*models.Attachment)
+	for _, attachment := range attachments {
+		byExpense[attachment.ExpenseID] = append(byExpense[attachment.ExpenseID], attachment)
+	}
+	return byExpense, nil
+}
+
+func (s *AttachmentService) getExpense(ctx context.Context, expenseID int64) (*models.Expense, error) {
+	if err := s.validator.ValidateExpenseID(expenseID); err != nil {
+		return nil, err
+	}
+
+	expense, err := s.db.GetExpenseByID(ctx, expenseID)
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get expense by ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get expense", err)
+	}
+	if expense == nil {
+		return nil, errors.NewNotFoundError("Expense not found", fmt.Sprintf("Expense with ID %d not found", expenseID))
+	}
+	return expense, nil
+}
+
+// attachmentContentType returns the media type of a receipt, detecting it from the data when
+// Telegram gives none or a generic one
+func attachmentContentType(contentType string, data []byte) string {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = strings.Split(http.DetectContentType(data), ";")[0]
+	}
+	if contentType == "image/jpg" {
+		contentType = "image/jpeg"
+	}
+	return contentType
+}
+
+// newAttachmentName returns a random name for a stored receipt, so keys cannot be guessed
+func newAttachmentName() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
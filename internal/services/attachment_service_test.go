@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/blobstore"
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdf := []byte("%PDF-1.7\n")
+
+	assert.Equal(t, "image/jpeg", attachmentContentType("image/jpeg", nil))
+	assert.Equal(t, "image/jpeg", attachmentContentType("IMAGE/JPG", nil))
+	assert.Equal(t, "application/pdf", attachmentContentType("application/pdf; charset=binary", nil))
+	assert.Equal(t, "image/png", attachmentContentType("", png))
+	assert.Equal(t, "application/pdf", attachmentContentType("application/octet-stream", pdf))
+}
+
+func TestAttachmentService_AddAndGet(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Food"})
+	for _, user := range []*models.User{
+		{TelegramID: 111, FirstName: "Asha"},
+		{TelegramID: 222, FirstName: "Ravi"},
+	} {
+		require.NoError(t, storage.CreateUser(ctx, user))
+	}
+
+	blobs, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	mockLogger := logger.NewMockLogger()
+	expenseService := newTestExpenseService(storage, mockLogger, nil)
+	service := NewAttachmentService(storage, mockLogger, blobs, NewLedgerService(storage, mockLogger, NewCurrencyService(storage, mockLogger)))
+
+	expense := &models.Expense{CategoryName: "Dining", TotalPrice: 450, Timestamp: time.Now()}
+	require.NoError(t, expenseService.CreateExpense(ctx, expense, 111))
+	require.NotZero(t, expense.ID)
+
+	pdf := []byte("%PDF-1.7\n%receipt")
+	attachment, err := service.AddAttachment(ctx, 111, expense.ID, "dinner.pdf", "", pdf)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", attachment.ContentType)
+	assert.Equal(t, "dinner.pdf", attachment.FileName)
+	assert.Equal(t, int64(len(pdf)), attachment.SizeBytes)
+	assert.False(t, attachment.IsImage())
+
+	got, data, err := service.GetAttachment(ctx, 111, attachment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.StorageKey, got.StorageKey)
+	assert.Equal(t, pdf, data)
+
+	byExpense, err := service.GetAttachments(ctx, []*models.Expense{expense})
+	require.NoError(t, err)
+	require.Len(t, byExpense[expense.ID], 1)
+	assert.Equal(t, attachment.ID, byExpense[expense.ID][0].ID)
+
+	// Other users cannot attach to or see someone else's personal expense
+	_, err = service.AddAttachment(ctx, 222, expense.ID, "x.pdf", "application/pdf", pdf)
+	assertAppErrorType(t, err, errors.ErrorTypeUnauthorized)
+	_, _, err = service.GetAttachment(ctx, 222, attachment.ID)
+	assertAppErrorType(t, err, errors.ErrorTypeUnauthorized)
+
+	// Only photos and PDFs up to the size limit are accepted
+	_, err = service.AddAttachment(ctx, 111, expense.ID, "notes.txt", "text/plain", []byte("hello"))
+	assertAppErrorType(t, err, errors.ErrorTypeValidation)
+	_, err = service.AddAttachment(ctx, 111, expense.ID, "huge.pdf", "application/pdf", make([]byte, MaxAttachmentSize+1))
+	assertAppErrorType(t, err, errors.ErrorTypeValidation)
+
+	_, _, err = service.GetAttachment(ctx, 111, 9999)
+	assertAppErrorType(t, err, errors.ErrorTypeNotFound)
+}
+
+func assertAppErrorType(t *testing.T, err error, want errors.ErrorType) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok, "want an AppError, got %v", err)
+	assert.Equal(t, want, appErr.Type)
+}
@@ -0,0 +1,292 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// BudgetService provides budget-related business logic
+type BudgetService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	categoryService *CategoryService
+}
+
+// NewBudgetService creates a new budget service that finds category limits' categories with
+// categoryService
+func NewBudgetService(db database.Storage, logger logger.Logger, categoryService *CategoryService) *BudgetService {
+	return &BudgetService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		categoryService: categoryService,
+	}
+}
+
+// SetBudget creates a new budget for the given period, replacing the active one
+func (s *BudgetService) SetBudget(ctx context.Context, telegramID int64, period models.BudgetPeriod, amount float64) (*models.Budget, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateBudgetPeriod(string(period)); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAmount(amount, "budget amount"); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := period.Range(time.Now())
+	budget := &models.Budget{
+		UserID:    user.ID,
+		Amount:    amount,
+		Period:    period,
+		StartDate: start,
+	}
+
+	if err := s.db.CreateBudget(ctx, budget); err != nil {
+		s.logger.Error(ctx, "Failed to create budget", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to create budget", err)
+	}
+
+	s.logger.Info(ctx, "Budget created successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("budget_id", int(budget.ID)),
+		logger.String("period", string(period)),
+		logger.Float64("amount", amount))
+
+	return budget, nil
+}
+
+// SetCategoryLimit sets the spending limit for a category within a budget
+func (s *BudgetService) SetCategoryLimit(ctx context.Context, telegramID, budgetID int64, categoryName string, amount float64) (*models.BudgetLimit, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateCategoryName(categoryName); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAmount(amount, "category limit"); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := s.getOwnedBudget(ctx, user, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !budget.IsActive {
+		return nil, errors.NewValidationError("Budget is not active", "Limits can only be set on an active budget")
+	}
+
+	category, err := s.categoryService.FindCategory(ctx, user.ID, categoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := &models.BudgetLimit{
+		BudgetID:      budget.ID,
+		CategoryID:    category.ID,
+		LimitAmount:   amount,
+		CategoryName:  category.Name,
+		CategoryEmoji: category.Emoji,
+	}
+
+	if err := s.db.SetBudgetLimit(ctx, limit); err != nil {
+		s.logger.Error(ctx, "Failed to set budget limit", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to set budget limit", err)
+	}
+
+	return limit, nil
+}
+
+// GetActiveBudgets retrieves the active budgets for a user
+func (s *BudgetService) GetActiveBudgets(ctx context.Context, telegramID int64) ([]*models.Budget, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.db.GetActiveBudgets(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get active budgets", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get budgets", err)
+	}
+
+	sortBudgetsByPeriod(budgets)
+	return budgets, nil
+}
+
+// GetBudgetHistory retrieves all budgets for a user, newest first
+func (s *BudgetService) GetBudgetHistory(ctx context.Context, telegramID int64) ([]*models.Budget, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.db.GetBudgetsByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get budget history", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get budget history", err)
+	}
+
+	sort.SliceStable(budgets, func(i, j int) bool {
+		if budgets[i].StartDate.Equal(budgets[j].StartDate) {
+			return budgets[i].ID > budgets[j].ID
+		}
+		return budgets[i].StartDate.After(budgets[j].StartDate)
+	})
+
+	return budgets, nil
+}
+
+// GetBudgetProgress calculates spending against each active budget for the period containing now
+func (s *BudgetService) GetBudgetProgress(ctx context.Context, telegramID int64, now time.Time) ([]*models.BudgetProgress, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.db.GetActiveBudgets(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get active budgets", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get budgets", err)
+	}
+	sortBudgetsByPeriod(budgets)
+
+	progress := make([]*models.BudgetProgress, 0, len(budgets))
+	for _, budget := range budgets {
+		start, end := budget.Period.Range(now)
+
+		expenses, err := s.db.GetExpensesByDateRange(ctx, user.ID, start, end)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to get expenses for budget", logger.ErrorField(err))
+			return nil, errors.NewDatabaseError("Failed to get expenses", err)
+		}
+
+		progress = append(progress, calculateBudgetProgress(budget, expenses, start, end))
+	}
+
+	return progress, nil
+}
+
+// DeactivateBudget stops tracking a budget while keeping it in the history
+func (s *BudgetService) DeactivateBudget(ctx context.Context, telegramID, budgetID int64) error {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getOwnedBudget(ctx, user, budgetID); err != nil {
+		return err
+	}
+
+	if err := s.db.DeactivateBudget(ctx, budgetID, user.ID); err != nil {
+		s.logger.Error(ctx, "Failed to deactivate budget", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to deactivate budget", err)
+	}
+
+	s.logger.Info(ctx, "Budget deactivated",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("budget_id", int(budgetID)))
+
+	return nil
+}
+
+// getUser retrieves a user by Telegram ID, returning a not found error if missing
+func (s *BudgetService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	return user, nil
+}
+
+// getOwnedBudget retrieves a budget and checks that it belongs to the user
+func (s *BudgetService) getOwnedBudget(ctx context.Context, user *models.User, budgetID int64) (*models.Budget, error) {
+	budget, err := s.db.GetBudgetByID(ctx, budgetID)
+	if database.IsNotFound(err) {
+		return nil, errors.NewNotFoundError("Budget not found", fmt.Sprintf("Budget with ID %d not found", budgetID))
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get budget by ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get budget", err)
+	}
+
+	if budget.UserID != user.ID {
+		return nil, errors.NewUnauthorizedError("You can only manage your own budgets")
+	}
+
+	return budget, nil
+}
+
+// calculateBudgetProgress sums expenses against a budget and its category limits
+func calculateBudgetProgress(budget *models.Budget, expenses []*models.Expense, start, end time.Time) *models.BudgetProgress {
+	progress := &models.BudgetProgress{
+		Budget:      budget,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	spentByCategory := make(map[int64]float64)
+	for _, expense := range expenses {
+		if budget.CategoryID.Valid && expense.CategoryID != budget.CategoryID.Int64 {
+			continue
+		}
+		progress.Spent += expense.TotalPrice
+		spentByCategory[expense.CategoryID] += expense.TotalPrice
+	}
+
+	for _, limit := range budget.Limits {
+		progress.Categories = append(progress.Categories, &models.CategoryProgress{
+			CategoryName:  limit.CategoryName,
+			CategoryEmoji: limit.CategoryEmoji,
+			Limit:         limit.LimitAmount,
+			Spent:         spentByCategory[limit.CategoryID],
+		})
+	}
+
+	return progress
+}
+
+// sortBudgetsByPeriod orders budgets from the shortest to the longest period
+func sortBudgetsByPeriod(budgets []*models.Budget) {
+	order := make(map[models.BudgetPeriod]int)
+	for i, period := range models.BudgetPeriods() {
+		order[period] = i
+	}
+	sort.SliceStable(budgets, func(i, j int) bool {
+		return order[budgets[i].Period] < order[budgets[j].Period]
+	})
+}
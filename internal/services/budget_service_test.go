@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBudgetService(mockDB *MockStorage) *BudgetService {
+	log := logger.NewMockLogger()
+	return NewBudgetService(mockDB, log, NewCategoryService(mockDB, log, nil))
+}
+
+func TestBudgetService_SetBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		telegramID  int64
+		period      models.BudgetPeriod
+		amount      float64
+		setupMock   func(*MockStorage)
+		expectError bool
+		errorType   errors.ErrorType
+	}{
+		{
+			name:       "successful monthly budget",
+			telegramID: 12345,
+			period:     models.BudgetPeriodMonthly,
+			amount:     50000,
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("CreateBudget", mock.Anything, mock.MatchedBy(func(b *models.Budget) bool {
+					return b.UserID == 1 && b.Period == models.BudgetPeriodMonthly && b.Amount == 50000 && b.StartDate.Day() == 1
+				})).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name:        "invalid period",
+			telegramID:  12345,
+			period:      "quarterly",
+			amount:      50000,
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+		{
+			name:        "invalid amount",
+			telegramID:  12345,
+			period:      models.BudgetPeriodWeekly,
+			amount:      0,
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockStorage{}
+			tt.setupMock(mockDB)
+
+			service := newTestBudgetService(mockDB)
+
+			budget, err := service.SetBudget(context.Background(), tt.telegramID, tt.period, tt.amount)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				if appErr, ok := err.(*errors.AppError); ok {
+					assert.Equal(t, tt.errorType, appErr.Type)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, budget)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBudgetService_SetCategoryLimit(t *testing.T) {
+	categories := []*models.Category{
+		{ID: 10, Name: "Dining", Emoji: "🍽️", Group: "Daily Living"},
+		{ID: 11, Name: "Petrol", Emoji: "⛽", Group: "Vehicle"},
+	}
+
+	t.Run("matches category case-insensitively", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetBudgetByID", mock.Anything, int64(5)).Return(&models.Budget{ID: 5, UserID: 1, IsActive: true}, nil)
+		mockDB.On("GetAllCategories", mock.Anything).Return(categories, nil)
+		mockDB.On("SetBudgetLimit", mock.Anything, mock.MatchedBy(func(l *models.BudgetLimit) bool {
+			return l.BudgetID == 5 && l.CategoryID == 10 && l.LimitAmount == 3000
+		})).Return(nil)
+
+		service := newTestBudgetService(mockDB)
+		limit, err := service.SetCategoryLimit(context.Background(), 12345, 5, "dining", 3000)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Dining", limit.CategoryName)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("rejects budgets owned by another user", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetBudgetByID", mock.Anything, int64(5)).Return(&models.Budget{ID: 5, UserID: 2, IsActive: true}, nil)
+
+		service := newTestBudgetService(mockDB)
+		_, err := service.SetCategoryLimit(context.Background(), 12345, 5, "Dining", 3000)
+
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorTypeUnauthorized, appErr.Type)
+	})
+
+	t.Run("unknown budget", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetBudgetByID", mock.Anything, int64(6)).Return(nil, sql.ErrNoRows)
+
+		service := newTestBudgetService(mockDB)
+		_, err := service.SetCategoryLimit(context.Background(), 12345, 6, "Dining", 3000)
+
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetBudgetByID", mock.Anything, int64(5)).Return(&models.Budget{ID: 5, UserID: 1, IsActive: true}, nil)
+		mockDB.On("GetAllCategories", mock.Anything).Return(categories, nil)
+		mockDB.On("GetCategoriesByUserID", mock.Anything, int64(1)).Return(nil, nil)
+
+		service := newTestBudgetService(mockDB)
+		_, err := service.SetCategoryLimit(context.Background(), 12345, 5, "Pets", 3000)
+
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+	})
+}
+
+func TestBudgetService_GetBudgetProgress(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	monthly := &models.Budget{
+		ID:       5,
+		UserID:   1,
+		Amount:   10000,
+		Period:   models.BudgetPeriodMonthly,
+		IsActive: true,
+		Limits: []*models.BudgetLimit{
+			{BudgetID: 5, CategoryID: 10, LimitAmount: 3000, CategoryName: "Dining", CategoryEmoji: "🍽️"},
+		},
+	}
+	expenses := []*models.Expense{
+		{ID: 1, CategoryID: 10, TotalPrice: 1200},
+		{ID: 2, CategoryID: 10, TotalPrice: 300},
+		{ID: 3, CategoryID: 11, TotalPrice: 2500},
+	}
+
+	mockDB := &MockStorage{}
+	mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+	mockDB.On("GetActiveBudgets", mock.Anything, int64(1)).Return([]*models.Budget{monthly}, nil)
+	mockDB.On("GetExpensesByDateRange", mock.Anything, int64(1),
+		time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC)).Return(expenses, nil)
+
+	service := newTestBudgetService(mockDB)
+	progress, err := service.GetBudgetProgress(context.Background(), 12345, now)
+
+	require.NoError(t, err)
+	require.Len(t, progress, 1)
+	assert.InDelta(t, 4000, progress[0].Spent, 0.001)
+	assert.InDelta(t, 6000, progress[0].Remaining(), 0.001)
+	assert.InDelta(t, 40, progress[0].Percent(), 0.001)
+	require.Len(t, progress[0].Categories, 1)
+	assert.InDelta(t, 1500, progress[0].Categories[0].Spent, 0.001)
+	assert.InDelta(t, 50, progress[0].Categories[0].Percent(), 0.001)
+	mockDB.AssertExpectations(t)
+}
+
+func TestBudgetPeriod_Range(t *testing.T) {
+	// Friday 16 Oct 2026
+	ref := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period        models.BudgetPeriod
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{models.BudgetPeriodDaily, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 16, 23, 59, 59, 0, time.UTC)},
+		{models.BudgetPeriodWeekly, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 18, 23, 59, 59, 0, time.UTC)},
+		{models.BudgetPeriodMonthly, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC)},
+		{models.BudgetPeriodYearly, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			start, end := tt.period.Range(ref)
+			assert.Equal(t, tt.expectedStart, start)
+			assert.Equal(t, tt.expectedEnd, end)
+		})
+	}
+}
@@ -3,7 +3,9 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/database"
 	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
@@ -12,23 +14,35 @@ import (
 	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
 )
 
-// CategoryService provides category-related business logic
+const (
+	// defaultCategoryGroup is the group of custom categories added without one
+	defaultCategoryGroup = "Other"
+
+	// defaultGroupEmoji is the emoji of custom groups added without one
+	defaultGroupEmoji = "📁"
+)
+
+// CategoryService provides category-related business logic. Each user sees the built-in
+// categories merged with their own custom ones.
 type CategoryService struct {
-	db        database.Storage
-	logger    logger.Logger
-	validator *validation.Validator
+	db            database.Storage
+	logger        logger.Logger
+	validator     *validation.Validator
+	vectorService VectorServiceInterface
 }
 
-// NewCategoryService creates a new category service
-func NewCategoryService(db database.Storage, logger logger.Logger) *CategoryService {
+// NewCategoryService creates a new category service. Expenses whose category is renamed or merged
+// are queued for embedding with vectorService; when it is nil they are not.
+func NewCategoryService(db database.Storage, logger logger.Logger, vectorService VectorServiceInterface) *CategoryService {
 	return &CategoryService{
-		db:        db,
-		logger:    logger,
-		validator: validation.NewValidator(),
+		db:            db,
+		logger:        logger,
+		validator:     validation.NewValidator(),
+		vectorService: vectorService,
 	}
 }
 
-// GetAllCategories retrieves all categories
+// GetAllCategories retrieves all built-in categories
 func (s *CategoryService) GetAllCategories(ctx context.Context) ([]*models.Category, error) {
 	// Get categories from database
 	categories, err := s.db.GetAllCategories(ctx)
@@ -40,8 +54,77 @@ func (s *CategoryService) GetAllCategories(ctx context.Context) ([]*models.Categ
 	return categories, nil
 }
 
-// GetCategoryByName retrieves a category by name
-func (s *CategoryService) GetCategoryByName(ctx context.Context, categoryName string) (*models.Category, error) {
+// GetUserCategories retrieves the built-in categories followed by the user's active custom ones
+func (s *CategoryService) GetUserCategories(ctx context.Context, userID int64) ([]*models.Category, error) {
+	categories, err := s.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	custom, err := s.getCustomCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range custom {
+		if !category.Archived {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+// GetCategories retrieves the categories the user with the given Telegram ID can pick from. A user
+// who has not been registered yet only has the built-in categories.
+func (s *CategoryService) GetCategories(ctx context.Context, telegramID int64) ([]*models.Category, error) {
+	user, err := s.findUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return s.GetAllCategories(ctx)
+	}
+	return s.GetUserCategories(ctx, user.ID)
+}
+
+// GetArchivedCategories retrieves the user's archived custom categories
+func (s *CategoryService) GetArchivedCategories(ctx context.Context, telegramID int64) ([]*models.Category, error) {
+	user, err := s.findUser(ctx, telegramID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	custom, err := s.getCustomCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []*models.Category
+	for _, category := range custom {
+		if category.Archived {
+			archived = append(archived, category)
+		}
+	}
+	return archived, nil
+}
+
+// GetCategoryByName retrieves a category the user can pick by its exact name
+func (s *CategoryService) GetCategoryByName(ctx context.Context, telegramID int64, categoryName string) (*models.Category, error) {
+	user, err := s.findUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	return s.GetUserCategoryByName(ctx, userID, categoryName)
+}
+
+// GetUserCategoryByName retrieves a built-in category or one of the user's active custom
+// categories by its exact name
+func (s *CategoryService) GetUserCategoryByName(ctx context.Context, userID int64, categoryName string) (*models.Category, error) {
 	// Validate input
 	if err := s.validator.ValidateCategoryName(categoryName); err != nil {
 		return nil, err
@@ -49,20 +132,56 @@ func (s *CategoryService) GetCategoryByName(ctx context.Context, categoryName st
 
 	// Get category from database
 	category, err := s.db.GetCategoryByName(ctx, categoryName)
-	if err != nil {
+	if err != nil && !database.IsNotFound(err) {
 		s.logger.Error(ctx, "Failed to get category by name", logger.ErrorField(err))
 		return nil, errors.NewDatabaseError("Failed to get category", err)
 	}
+	if err == nil && category != nil {
+		return category, nil
+	}
 
-	if category == nil {
-		return nil, errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", categoryName))
+	if userID != 0 {
+		custom, err := s.getCustomCategories(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, category := range custom {
+			if !category.Archived && category.Name == categoryName {
+				return category, nil
+			}
+		}
 	}
 
-	return category, nil
+	return nil, errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", categoryName))
 }
 
-// GetCategoriesByGroup retrieves categories by group
-func (s *CategoryService) GetCategoriesByGroup(ctx context.Context, groupName string) ([]*models.Category, error) {
+// FindCategory resolves a category the user can pick by name, ignoring case and an optional emoji
+// prefix
+func (s *CategoryService) FindCategory(ctx context.Context, userID int64, name string) (*models.Category, error) {
+	categories, err := s.db.GetAllCategories(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get categories", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get categories", err)
+	}
+
+	name = strings.TrimSpace(name)
+	if category := matchCategory(categories, name); category != nil {
+		return category, nil
+	}
+
+	custom, err := s.getCustomCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if category := matchCategory(activeCategories(custom), name); category != nil {
+		return category, nil
+	}
+
+	return nil, errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", name))
+}
+
+// GetCategoriesByGroup retrieves the categories the user can pick in a group
+func (s *CategoryService) GetCategoriesByGroup(ctx context.Context, telegramID int64, groupName string) ([]*models.Category, error) {
 	// Validate input
 	if groupName == "" {
 		return nil, errors.NewValidationError("Group name is required", "Group name cannot be empty")
@@ -72,44 +191,512 @@ func (s *CategoryService) GetCategoriesByGroup(ctx context.Context, groupName st
 		return nil, errors.NewValidationError("Group name too long", "Group name must be 100 characters or less")
 	}
 
-	// Get categories from database
-	categories, err := s.db.GetCategoriesByGroup(ctx, groupName)
+	categories, err := s.GetCategories(ctx, telegramID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to get categories by group", logger.ErrorField(err))
-		return nil, errors.NewDatabaseError("Failed to get categories", err)
+		return nil, err
 	}
 
-	return categories, nil
+	var result []*models.Category
+	for _, category := range categories {
+		if category.Group == groupName {
+			result = append(result, category)
+		}
+	}
+	return result, nil
 }
 
-// GetCategoryGroups retrieves all category groups
-func (s *CategoryService) GetCategoryGroups(ctx context.Context) ([]*models.CategoryGroup, error) {
-	// Get all categories first
-	categories, err := s.db.GetAllCategories(ctx)
+// GetCategoryGroups retrieves the built-in groups followed by the user's own, in keyboard order,
+// with the labels of the categories the user can pick in each. The user's groups are included
+// even while they have no categories.
+func (s *CategoryService) GetCategoryGroups(ctx context.Context, telegramID int64) ([]*models.CategoryGroup, error) {
+	user, err := s.findUser(ctx, telegramID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to get all categories for groups", logger.ErrorField(err))
-		return nil, errors.NewDatabaseError("Failed to get categories", err)
+		return nil, err
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	categories, err := s.GetUserCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getGroups(ctx, userID, categories)
+}
+
+// AddCategory adds a custom category for the user. The group defaults to Other and the emoji to
+// the group's. Names must not clash with a built-in category or another of the user's, ignoring case.
+func (s *CategoryService) AddCategory(ctx context.Context, telegramID int64, name, emoji, groupName string) (*models.Category, error) {
+	name = strings.TrimSpace(name)
+	if err := s.validator.ValidateCustomCategoryName(name); err != nil {
+		return nil, err
+	}
+	if emoji != "" {
+		if err := s.validator.ValidateEmoji(emoji); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.getAllUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCategoryNameFree(categories, name, 0); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(groupName) == "" {
+		groupName = defaultCategoryGroup
+	}
+	groups, err := s.getGroups(ctx, user.ID, categories)
+	if err != nil {
+		return nil, err
+	}
+	group := findGroupByName(groups, groupName)
+	if group == nil {
+		return nil, errors.NewNotFoundError("Group not found", fmt.Sprintf("There is no category group named %s", strings.TrimSpace(groupName)))
+	}
+	if emoji == "" {
+		emoji = group.Emoji
+	}
+
+	category := &models.Category{
+		UserID: sql.NullInt64{Int64: user.ID, Valid: true},
+		Name:   name,
+		Emoji:  emoji,
+		Group:  group.Name,
+	}
+	if err := s.db.CreateCategory(ctx, category); err != nil {
+		s.logger.Error(ctx, "Failed to create category", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to create category", err)
+	}
+
+	s.logger.Info(ctx, "Category added",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("category_id", int(category.ID)))
+	return category, nil
+}
+
+// AddGroup adds a custom category group for the user, shown after the built-in groups once it
+// has a category
+func (s *CategoryService) AddGroup(ctx context.Context, telegramID int64, name, emoji string) (*models.CategoryGroup, error) {
+	name = strings.TrimSpace(name)
+	if err := s.validator.ValidateCustomCategoryName(name); err != nil {
+		return nil, err
+	}
+	if emoji == "" {
+		emoji = defaultGroupEmoji
+	}
+	if err := s.validator.ValidateEmoji(emoji); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.getAllUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.getGroups(ctx, user.ID, categories)
+	if err != nil {
+		return nil, err
+	}
+	if findGroupByName(groups, name) != nil {
+		return nil, errors.NewValidationError("Group already exists", fmt.Sprintf("There is already a category group named %s", name))
+	}
+
+	group := &models.CategoryGroup{
+		UserID: sql.NullInt64{Int64: user.ID, Valid: true},
+		Name:   name,
+		Emoji:  emoji,
+	}
+	if err := s.db.CreateCategoryGroup(ctx, group); err != nil {
+		s.logger.Error(ctx, "Failed to create category group", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to create category group", err)
+	}
+
+	s.logger.Info(ctx, "Category group added",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("group_id", int(group.ID)))
+	return group, nil
+}
+
+// RenameCategory renames one of the user's custom categories and optionally changes its emoji.
+// Built-in categories cannot be renamed.
+func (s *CategoryService) RenameCategory(ctx context.Context, telegramID int64, oldName, newName, emoji string) (*models.Category, error) {
+	newName = strings.TrimSpace(newName)
+	if err := s.validator.ValidateCustomCategoryName(newName); err != nil {
+		return nil, err
+	}
+	if emoji != "" {
+		if err := s.validator.ValidateEmoji(emoji); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.getAllUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	category, err := findCustomCategory(categories, oldName, "renamed")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCategoryNameFree(categories, newName, category.ID); err != nil {
+		return nil, err
+	}
+
+	updated := *category
+	updated.Name = newName
+	if emoji != "" {
+		updated.Emoji = emoji
+	}
+	if err := s.db.UpdateCategory(ctx, &updated); err != nil {
+		s.logger.Error(ctx, "Failed to update category", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to update category", err)
+	}
+
+	s.queueCategoryEmbeddings(ctx, user.ID, func(expense *models.Expense) bool {
+		return expense.CategoryID == category.ID
+	})
+	return &updated, nil
+}
+
+// RenameGroup renames one of the user's custom groups and optionally changes its emoji. The
+// user's categories in the group move with it. Built-in groups cannot be renamed.
+func (s *CategoryService) RenameGroup(ctx context.Context, telegramID int64, oldName, newName, emoji string) (*models.CategoryGroup, error) {
+	newName = strings.TrimSpace(newName)
+	if err := s.validator.ValidateCustomCategoryName(newName); err != nil {
+		return nil, err
+	}
+	if emoji != "" {
+		if err := s.validator.ValidateEmoji(emoji); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.getAllUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.getGroups(ctx, user.ID, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	group := findGroupByName(groups, oldName)
+	switch {
+	case group == nil:
+		return nil, errors.NewNotFoundError("Group not found", fmt.Sprintf("There is no category group named %s", strings.TrimSpace(oldName)))
+	case !group.IsCustom():
+		return nil, errors.NewValidationError("Built-in group", fmt.Sprintf("%s is a built-in group and cannot be renamed", group.Name))
+	}
+	if existing := findGroupByName(groups, newName); existing != nil && existing.ID != group.ID {
+		return nil, errors.NewValidationError("Group already exists", fmt.Sprintf("There is already a category group named %s", newName))
+	}
+
+	updated := *group
+	updated.Name = newName
+	updated.Categories = nil
+	if emoji != "" {
+		updated.Emoji = emoji
+	}
+	if err := s.db.RenameCategoryGroup(ctx, &updated, group.Name); err != nil {
+		s.logger.Error(ctx, "Failed to rename category group", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to rename category group", err)
+	}
+
+	moved := make(map[int64]bool)
+	for _, category := range categories {
+		if category.IsCustom() && category.Group == group.Name {
+			moved[category.ID] = true
+		}
+	}
+	s.queueCategoryEmbeddings(ctx, user.ID, func(expense *models.Expense) bool {
+		return moved[expense.CategoryID]
+	})
+	return &updated, nil
+}
+
+// SetCategoryArchived archives or restores one of the user's custom categories. Archived
+// categories are hidden from keyboards and quick add; their expenses are kept.
+func (s *CategoryService) SetCategoryArchived(ctx context.Context, telegramID int64, name string, archived bool) (*models.Category, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.getAllUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	category, err := findCustomCategory(categories, name, "archived")
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *category
+	updated.Archived = archived
+	if err := s.db.UpdateCategory(ctx, &updated); err != nil {
+		s.logger.Error(ctx, "Failed to update category", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to update category", err)
+	}
+	return &updated, nil
+}
+
+// MergeCategories moves the user's expenses, recurring expenses and budget limits from one
+// category to another and returns both categories with the number of expenses moved. A custom
+// source category is deleted; a built-in one stays for other users.
+func (s *CategoryService) MergeCategories(ctx context.Context, telegramID int64, fromName, toName string) (from, to *models.Category, moved int, err error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	categories, err := s.getAllUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	from = matchCategory(categories, strings.TrimSpace(fromName))
+	if from == nil {
+		return nil, nil, 0, errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", strings.TrimSpace(fromName)))
+	}
+	to = matchCategory(activeCategories(categories), strings.TrimSpace(toName))
+	if to == nil {
+		return nil, nil, 0, errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", strings.TrimSpace(toName)))
+	}
+	if from.ID == to.ID {
+		return nil, nil, 0, errors.NewValidationError("Same category", "Choose two different categories to merge")
+	}
+
+	expenseIDs, err := s.db.MergeCategory(ctx, user.ID, from.ID, to.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to merge categories", logger.ErrorField(err))
+		return nil, nil, 0, errors.NewDatabaseError("Failed to merge categories", err)
+	}
+
+	s.logger.Info(ctx, "Categories merged",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("from_category_id", int(from.ID)),
+		logger.Int("to_category_id", int(to.ID)),
+		logger.Int("expenses", len(expenseIDs)))
+
+	if s.vectorService != nil && len(expenseIDs) > 0 {
+		if err := s.vectorService.EnqueueEmbeddings(ctx, expenseIDs...); err != nil {
+			s.logger.Error(ctx, "Failed to queue embeddings for merged expenses", logger.ErrorField(err))
+		}
+	}
+	return from, to, len(expenseIDs), nil
+}
+
+// getGroups returns the built-in groups, then any group that only appears on a category, then the
+// user's own groups, with the labels of the active categories in each
+func (s *CategoryService) getGroups(ctx context.Context, userID int64, categories []*models.Category) ([]*models.CategoryGroup, error) {
+	stored, err := s.db.GetCategoryGroups(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get category groups", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get category groups", err)
+	}
+
+	var builtIn, custom, unlisted []*models.CategoryGroup
+	byName := make(map[string]*models.CategoryGroup, len(stored))
+	for _, group := range stored {
+		group := *group
+		group.Categories = nil
+		if group.IsCustom() {
+			custom = append(custom, &group)
+		} else {
+			builtIn = append(builtIn, &group)
+		}
+		byName[group.Name] = &group
 	}
 
-	// Group categories by their group
-	groupMap := make(map[string]*models.CategoryGroup)
 	for _, category := range categories {
-		if _, exists := groupMap[category.Group]; !exists {
-			groupMap[category.Group] = &models.CategoryGroup{
+		group, exists := byName[category.Group]
+		if !exists {
+			group = &models.CategoryGroup{
 				Name:  category.Group,
 				Emoji: getGroupEmoji(category.Group),
 			}
+			unlisted = append(unlisted, group)
+			byName[category.Group] = group
+		}
+		if !category.Archived {
+			group.Categories = append(group.Categories, models.CategoryType(category.Emoji+" "+category.Name))
+		}
+	}
+
+	groups := append(builtIn, unlisted...)
+	return append(groups, custom...), nil
+}
+
+// getAllUserCategories returns the built-in categories followed by all of the user's custom ones,
+// archived included
+func (s *CategoryService) getAllUserCategories(ctx context.Context, userID int64) ([]*models.Category, error) {
+	categories, err := s.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	custom, err := s.getCustomCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(categories, custom...), nil
+}
+
+// getCustomCategories returns the user's custom categories, archived included
+func (s *CategoryService) getCustomCategories(ctx context.Context, userID int64) ([]*models.Category, error) {
+	if userID == 0 {
+		return nil, nil
+	}
+
+	custom, err := s.db.GetCategoriesByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get custom categories", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get categories", err)
+	}
+	return custom, nil
+}
+
+// queueCategoryEmbeddings queues the user's expenses that match for embedding, after a change to
+// the category name or group that their embeddings include. Failures are logged.
+func (s *CategoryService) queueCategoryEmbeddings(ctx context.Context, userID int64, match func(*models.Expense) bool) {
+	if s.vectorService == nil {
+		return
+	}
+
+	expenses, err := s.db.GetExpensesByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get expenses to embed", logger.ErrorField(err))
+		return
+	}
+
+	var expenseIDs []int64
+	for _, expense := range expenses {
+		if match(expense) {
+			expenseIDs = append(expenseIDs, expense.ID)
+		}
+	}
+	if len(expenseIDs) == 0 {
+		return
+	}
+	if err := s.vectorService.EnqueueEmbeddings(ctx, expenseIDs...); err != nil {
+		s.logger.Error(ctx, "Failed to queue expense embeddings", logger.ErrorField(err))
+	}
+}
+
+// findUser looks up the user with the given Telegram ID, returning nil when they are not registered
+func (s *CategoryService) findUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, nil
 		}
-		// Add category to group (you might want to add a Categories field to CategoryGroup)
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+	return user, nil
+}
+
+// getUser looks up the user with the given Telegram ID
+func (s *CategoryService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	user, err := s.findUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
 	}
+	return user, nil
+}
 
-	// Convert map to slice
-	groups := make([]*models.CategoryGroup, 0, len(groupMap))
-	for _, group := range groupMap {
-		groups = append(groups, group)
+// matchCategory returns the category with the given name, ignoring case and an optional emoji
+// prefix, or nil
+func matchCategory(categories []*models.Category, name string) *models.Category {
+	for _, category := range categories {
+		if strings.EqualFold(category.Name, name) || strings.EqualFold(category.Emoji+" "+category.Name, name) {
+			return category
+		}
 	}
+	return nil
+}
 
-	return groups, nil
+// activeCategories returns the categories that are not archived
+func activeCategories(categories []*models.Category) []*models.Category {
+	active := make([]*models.Category, 0, len(categories))
+	for _, category := range categories {
+		if !category.Archived {
+			active = append(active, category)
+		}
+	}
+	return active
+}
+
+// findCustomCategory returns the user's custom category with the given name, or an error saying
+// that it does not exist or is built-in and so cannot be changed as described by action
+func findCustomCategory(categories []*models.Category, name, action string) (*models.Category, error) {
+	name = strings.TrimSpace(name)
+	category := matchCategory(categories, name)
+	switch {
+	case category == nil:
+		return nil, errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", name))
+	case !category.IsCustom():
+		return nil, errors.NewValidationError("Built-in category",
+			fmt.Sprintf("%s is a built-in category and cannot be %s; merge it into one of your own instead", category.Name, action))
+	}
+	return category, nil
+}
+
+// checkCategoryNameFree returns a validation error when a category other than the one with exceptID
+// already has the name, ignoring case
+func checkCategoryNameFree(categories []*models.Category, name string, exceptID int64) error {
+	for _, category := range categories {
+		if category.ID == exceptID || !strings.EqualFold(category.Name, name) {
+			continue
+		}
+		if category.Archived {
+			return errors.NewValidationError("Category already exists", fmt.Sprintf("You have an archived category named %s; restore it instead", category.Name))
+		}
+		return errors.NewValidationError("Category already exists", fmt.Sprintf("There is already a category named %s", category.Name))
+	}
+	return nil
+}
+
+// findGroupByName returns the group with the given name, ignoring case, or nil
+func findGroupByName(groups []*models.CategoryGroup, name string) *models.CategoryGroup {
+	name = strings.TrimSpace(name)
+	for _, group := range groups {
+		if strings.EqualFold(group.Name, name) {
+			return group
+		}
+	}
+	return nil
 }
 
 // getGroupEmoji returns the emoji for a category group
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryService_CustomCategories(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	mockStorage := storage.(*database.MockStorage)
+	mockStorage.AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Daily Living"})
+	mockStorage.AddMockCategory(&models.Category{Name: "Other", Emoji: "📌", Group: "Other"})
+	user := &models.User{TelegramID: 12345}
+	require.NoError(t, storage.CreateUser(ctx, user))
+	require.NoError(t, storage.CreateUser(ctx, &models.User{TelegramID: 67890}))
+
+	service := NewCategoryService(storage, logger.NewMockLogger(), nil)
+	errorType := func(err error) errors.ErrorType {
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok, "expected an AppError, got %v", err)
+		return appErr.Type
+	}
+
+	// Unregistered users see the built-in categories
+	categories, err := service.GetCategories(ctx, 11111)
+	require.NoError(t, err)
+	assert.Len(t, categories, 2)
+
+	petFood, err := service.AddCategory(ctx, 12345, "Pet Food", "🐶", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Other", petFood.Group)
+	assert.True(t, petFood.IsCustom())
+
+	_, err = service.AddCategory(ctx, 12345, "pet food", "", "")
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+	_, err = service.AddCategory(ctx, 12345, "dining", "", "")
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+	_, err = service.AddCategory(ctx, 12345, "Vet", "", "Pets")
+	assert.Equal(t, errors.ErrorTypeNotFound, errorType(err))
+
+	_, err = service.AddGroup(ctx, 12345, "Pets", "🐾")
+	require.NoError(t, err)
+	_, err = service.AddGroup(ctx, 12345, "other", "")
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+	vet, err := service.AddCategory(ctx, 12345, "Vet", "", "pets")
+	require.NoError(t, err)
+	assert.Equal(t, "Pets", vet.Group)
+	assert.Equal(t, "🐾", vet.Emoji)
+
+	groups, err := service.GetCategoryGroups(ctx, 12345)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Equal(t, []models.CategoryType{"📌 Other", "🐶 Pet Food"}, groups[1].Categories)
+	assert.Equal(t, "Pets", groups[2].Name)
+	assert.Equal(t, []models.CategoryType{"🐾 Vet"}, groups[2].Categories)
+
+	// Custom categories are only visible to their owner
+	found, err := service.FindCategory(ctx, user.ID, "🐶 pet food")
+	require.NoError(t, err)
+	assert.Equal(t, petFood.ID, found.ID)
+	_, err = service.GetCategoryByName(ctx, 12345, "Vet")
+	assert.NoError(t, err)
+	_, err = service.GetCategoryByName(ctx, 67890, "Vet")
+	assert.Equal(t, errors.ErrorTypeNotFound, errorType(err))
+
+	_, err = service.RenameCategory(ctx, 12345, "Dining", "Eating Out", "")
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+	renamed, err := service.RenameCategory(ctx, 12345, "pet food", "Pet Supplies", "🦴")
+	require.NoError(t, err)
+	assert.Equal(t, "🦴", renamed.Emoji)
+	assert.Equal(t, "Pet Supplies", renamed.Name)
+
+	group, err := service.RenameGroup(ctx, 12345, "Pets", "Animals", "")
+	require.NoError(t, err)
+	assert.Equal(t, "🐾", group.Emoji)
+	vet, err = service.GetCategoryByName(ctx, 12345, "Vet")
+	require.NoError(t, err)
+	assert.Equal(t, "Animals", vet.Group)
+
+	// Archived categories are hidden but can be restored
+	_, err = service.SetCategoryArchived(ctx, 12345, "Vet", true)
+	require.NoError(t, err)
+	_, err = service.FindCategory(ctx, user.ID, "Vet")
+	assert.Equal(t, errors.ErrorTypeNotFound, errorType(err))
+	archived, err := service.GetArchivedCategories(ctx, 12345)
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, "Vet", archived[0].Name)
+	_, err = service.SetCategoryArchived(ctx, 12345, "Vet", false)
+	require.NoError(t, err)
+	categories, err = service.GetCategories(ctx, 12345)
+	require.NoError(t, err)
+	assert.Len(t, categories, 4)
+}
+
+func TestCategoryService_MergeCategories(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Other", Emoji: "📌", Group: "Other"})
+	user := &models.User{TelegramID: 12345}
+	require.NoError(t, storage.CreateUser(ctx, user))
+
+	service := NewCategoryService(storage, logger.NewMockLogger(), nil)
+	petFood, err := service.AddCategory(ctx, 12345, "Pet Food", "🐶", "")
+	require.NoError(t, err)
+	other, err := service.FindCategory(ctx, user.ID, "Other")
+	require.NoError(t, err)
+
+	moved := &models.Expense{UserID: user.ID, CategoryID: petFood.ID, CategoryName: petFood.Name, TotalPrice: 800}
+	require.NoError(t, storage.CreateExpense(ctx, moved))
+	require.NoError(t, storage.CreateExpense(ctx, &models.Expense{UserID: user.ID, CategoryID: other.ID, TotalPrice: 100}))
+
+	_, _, _, err = service.MergeCategories(ctx, 12345, "Pet Food", "pet food")
+	require.Error(t, err)
+
+	from, to, count, err := service.MergeCategories(ctx, 12345, "pet food", "other")
+	require.NoError(t, err)
+	assert.Equal(t, petFood.ID, from.ID)
+	assert.Equal(t, other.ID, to.ID)
+	assert.Equal(t, 1, count)
+
+	expense, err := storage.GetExpenseByID(ctx, moved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, expense.CategoryID)
+
+	// The custom source category is removed
+	_, err = service.FindCategory(ctx, user.ID, "Pet Food")
+	assert.Error(t, err)
+
+	// The move is recorded, and undoing it waits for the category to be added again
+	history, err := storage.GetExpenseHistory(ctx, moved.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.HistoryActionUpdate, history[0].Action)
+	assert.Equal(t, "Pet Food", history[0].Before.CategoryName)
+	assert.Equal(t, other.ID, history[0].After.CategoryID)
+
+	historyService := newTestHistoryService(storage, logger.NewMockLogger())
+	_, err = historyService.Undo(ctx, 12345)
+	assertAppErrorType(t, err, errors.ErrorTypeNotFound)
+	petFood, err = service.AddCategory(ctx, 12345, "Pet Food", "🐶", "")
+	require.NoError(t, err)
+	_, err = historyService.Undo(ctx, 12345)
+	require.NoError(t, err)
+	expense, err = storage.GetExpenseByID(ctx, moved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, petFood.ID, expense.CategoryID)
+}
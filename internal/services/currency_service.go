@@ -0,0 +1,282 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// maxRateImportRows caps the number of rows accepted from one CSV import
+const maxRateImportRows = 10000
+
+// CurrencyService provides exchange rate and currency conversion business logic
+type CurrencyService struct {
+	db        database.Storage
+	logger    logger.Logger
+	validator *validation.Validator
+}
+
+// NewCurrencyService creates a new currency service
+func NewCurrencyService(db database.Storage, logger logger.Logger) *CurrencyService {
+	return &CurrencyService{
+		db:        db,
+		logger:    logger,
+		validator: validation.NewValidator(),
+	}
+}
+
+// SetRate stores the rate of 1 base in quote on the given date
+func (s *CurrencyService) SetRate(ctx context.Context, base, quote string, rate float64, date time.Time) (*models.ExchangeRate, error) {
+	exchangeRate, err := s.newRate(base, quote, rate, date, models.ExchangeRateSourceManual)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.UpsertExchangeRates(ctx, []*models.ExchangeRate{exchangeRate}); err != nil {
+		s.logger.Error(ctx, "Failed to save exchange rate", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to save exchange rate", err)
+	}
+
+	return exchangeRate, nil
+}
+
+// ImportRatesCSV imports rates from CSV rows of "date,base,quote,rate" with dates as YYYY-MM-DD.
+// A header row is skipped. Nothing is saved if any row is invalid.
+func (s *CurrencyService) ImportRatesCSV(ctx context.Context, r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var rates []*models.ExchangeRate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.NewValidationError("Invalid CSV file", err.Error())
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return 0, errors.NewValidationError("Invalid CSV file", fmt.Sprintf("line %d: date must be YYYY-MM-DD", line))
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if err != nil {
+			return 0, errors.NewValidationError("Invalid CSV file", fmt.Sprintf("line %d: rate must be a number", line))
+		}
+
+		rate, err := s.newRate(record[1], record[2], value, date, models.ExchangeRateSourceCSV)
+		if err != nil {
+			return 0, errors.NewValidationError("Invalid CSV file", fmt.Sprintf("line %d: %v", line, err))
+		}
+
+		rates = append(rates, rate)
+		if len(rates) > maxRateImportRows {
+			return 0, errors.NewValidationError("CSV file too large", fmt.Sprintf("At most %d rates can be imported at once", maxRateImportRows))
+		}
+	}
+
+	if len(rates) == 0 {
+		return 0, errors.NewValidationError("Empty CSV file", "No exchange rates found in the file")
+	}
+
+	if err := s.db.UpsertExchangeRates(ctx, rates); err != nil {
+		s.logger.Error(ctx, "Failed to import exchange rates", logger.ErrorField(err))
+		return 0, errors.NewDatabaseError("Failed to import exchange rates", err)
+	}
+
+	s.logger.Info(ctx, "Exchange rates imported", logger.Int("count", len(rates)))
+
+	return len(rates), nil
+}
+
+// GetLatestRates retrieves the most recent rate of each currency pair
+func (s *CurrencyService) GetLatestRates(ctx context.Context, limit int) ([]*models.ExchangeRate, error) {
+	rates, err := s.db.GetLatestExchangeRates(ctx, limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get exchange rates", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get exchange rates", err)
+	}
+
+	return rates, nil
+}
+
+// Convert converts amount from one currency to another at the latest rate on or before on.
+// Rates stored in the opposite direction are inverted.
+func (s *CurrencyService) Convert(ctx context.Context, amount float64, from, to string, on time.Time) (float64, error) {
+	rate, err := s.rate(ctx, from, to, on)
+	if err != nil {
+		return 0, err
+	}
+
+	return amount * rate, nil
+}
+
+// ApplyHomeCurrency fills in the currency fields of an expense for the user.
+// Expenses without a currency are in the user's home currency. For foreign expenses
+// TotalPrice becomes OriginalAmount converted to the home currency at the expense date.
+func (s *CurrencyService) ApplyHomeCurrency(ctx context.Context, userID int64, expense *models.Expense) error {
+	home, err := s.homeCurrency(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if expense.Currency == "" {
+		expense.Currency = home
+	}
+	expense.Currency = strings.ToUpper(expense.Currency)
+	if err := s.validator.ValidateCurrencyCode(expense.Currency); err != nil {
+		return err
+	}
+
+	if expense.OriginalAmount == 0 {
+		expense.OriginalAmount = expense.TotalPrice
+	}
+
+	if expense.Currency == home {
+		expense.TotalPrice = expense.OriginalAmount
+		return nil
+	}
+
+	on := expense.Timestamp
+	if on.IsZero() {
+		on = time.Now()
+	}
+
+	converted, err := s.Convert(ctx, expense.OriginalAmount, expense.Currency, home, on)
+	if err != nil {
+		return err
+	}
+	expense.TotalPrice = converted
+
+	return nil
+}
+
+// ConvertExpenses sets TotalPrice of each expense to its original amount converted to
+// the given currency at the expense date's rate. Expenses without a usable rate keep
+// their stored amount; the number of such expenses is returned.
+func (s *CurrencyService) ConvertExpenses(ctx context.Context, expenses []*models.Expense, to string) int {
+	rates := make(map[string]float64)
+	unconverted := 0
+
+	for _, expense := range expenses {
+		if expense.Currency == "" || expense.Currency == to {
+			if expense.Currency == to && expense.OriginalAmount > 0 {
+				expense.TotalPrice = expense.OriginalAmount
+			}
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s:%s", expense.Currency, to, expense.Timestamp.Format("2006-01-02"))
+		rate, ok := rates[key]
+		if !ok {
+			var err error
+			rate, err = s.rate(ctx, expense.Currency, to, expense.Timestamp)
+			if err != nil {
+				s.logger.Debug(ctx, "No exchange rate for expense", logger.ErrorField(err),
+					logger.Int("expense_id", int(expense.ID)))
+				rate = 0
+			}
+			rates[key] = rate
+		}
+
+		if rate == 0 {
+			unconverted++
+			continue
+		}
+		expense.TotalPrice = expense.OriginalAmount * rate
+	}
+
+	return unconverted
+}
+
+// HomeCurrency returns the home currency of a user
+func (s *CurrencyService) HomeCurrency(ctx context.Context, userID int64) (string, error) {
+	return s.homeCurrency(ctx, userID)
+}
+
+// rate returns how many to units one from unit was worth on the given date
+func (s *CurrencyService) rate(ctx context.Context, from, to string, on time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	rate, err := s.db.GetExchangeRate(ctx, from, to, on)
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get exchange rate", logger.ErrorField(err))
+		return 0, errors.NewDatabaseError("Failed to get exchange rate", err)
+	}
+
+	inverse, err := s.db.GetExchangeRate(ctx, to, from, on)
+	if err == nil {
+		return 1 / inverse.Rate, nil
+	}
+	if !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get exchange rate", logger.ErrorField(err))
+		return 0, errors.NewDatabaseError("Failed to get exchange rate", err)
+	}
+
+	return 0, errors.NewNotFoundError("Exchange rate not found",
+		fmt.Sprintf("No %s→%s rate on or before %s. Add one with /rates set %s %s <rate>", from, to, on.Format("2006-01-02"), from, to))
+}
+
+// homeCurrency returns the currency from the user's settings, or the default currency
+func (s *CurrencyService) homeCurrency(ctx context.Context, userID int64) (string, error) {
+	settings, err := s.db.GetUserSettings(ctx, userID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return models.DefaultCurrency, nil
+		}
+		s.logger.Error(ctx, "Failed to get user settings", logger.ErrorField(err))
+		return "", errors.NewDatabaseError("Failed to get settings", err)
+	}
+
+	return settings.Currency, nil
+}
+
+// newRate validates and builds an exchange rate
+func (s *CurrencyService) newRate(base, quote string, rate float64, date time.Time, source string) (*models.ExchangeRate, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+
+	if err := s.validator.ValidateCurrencyCode(base); err != nil {
+		return nil, err
+	}
+	if err := s.validator.ValidateCurrencyCode(quote); err != nil {
+		return nil, err
+	}
+	if base == quote {
+		return nil, errors.NewValidationError("Invalid currency pair", "Base and quote currency must differ")
+	}
+	if err := s.validator.ValidateAmount(rate, "rate"); err != nil {
+		return nil, err
+	}
+
+	return &models.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate,
+		RateDate:      time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		Source:        source,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyService_Convert(t *testing.T) {
+	on := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		from, to    string
+		setupMock   func(*MockStorage)
+		expected    float64
+		expectError bool
+	}{
+		{
+			name:      "same currency",
+			from:      "INR",
+			to:        "INR",
+			setupMock: func(mockDB *MockStorage) {},
+			expected:  100,
+		},
+		{
+			name: "direct rate",
+			from: "EUR",
+			to:   "INR",
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetExchangeRate", mock.Anything, "EUR", "INR", on).Return(&models.ExchangeRate{Rate: 90}, nil)
+			},
+			expected: 9000,
+		},
+		{
+			name: "inverse rate",
+			from: "INR",
+			to:   "EUR",
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetExchangeRate", mock.Anything, "INR", "EUR", on).Return(nil, sql.ErrNoRows)
+				mockDB.On("GetExchangeRate", mock.Anything, "EUR", "INR", on).Return(&models.ExchangeRate{Rate: 80}, nil)
+			},
+			expected: 1.25,
+		},
+		{
+			name: "no rate",
+			from: "USD",
+			to:   "INR",
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetExchangeRate", mock.Anything, mock.Anything, mock.Anything, on).Return(nil, sql.ErrNoRows)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockStorage{}
+			tt.setupMock(mockDB)
+
+			service := NewCurrencyService(mockDB, logger.NewMockLogger())
+			result, err := service.Convert(context.Background(), 100, tt.from, tt.to, on)
+
+			if tt.expectError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok)
+				assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, result, 0.0001)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCurrencyService_ImportRatesCSV(t *testing.T) {
+	t.Run("imports rows and skips the header", func(t *testing.T) {
+		input := "date,base,quote,rate\n2026-03-01,eur,INR,90.5\n2026-03-01,USD,INR,83.2\n"
+
+		mockDB := &MockStorage{}
+		mockDB.On("UpsertExchangeRates", mock.Anything, mock.MatchedBy(func(rates []*models.ExchangeRate) bool {
+			return len(rates) == 2 &&
+				rates[0].BaseCurrency == "EUR" && rates[0].QuoteCurrency == "INR" && rates[0].Rate == 90.5 &&
+				rates[0].Source == models.ExchangeRateSourceCSV &&
+				rates[0].RateDate.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		})).Return(nil)
+
+		service := NewCurrencyService(mockDB, logger.NewMockLogger())
+		count, err := service.ImportRatesCSV(context.Background(), strings.NewReader(input))
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		mockDB.AssertExpectations(t)
+	})
+
+	invalid := map[string]string{
+		"bad date":      "03/01/2026,EUR,INR,90.5\n",
+		"bad rate":      "2026-03-01,EUR,INR,abc\n",
+		"negative rate": "2026-03-01,EUR,INR,-1\n",
+		"same currency": "2026-03-01,EUR,EUR,1\n",
+		"wrong columns": "2026-03-01,EUR,INR\n",
+		"empty":         "date,base,quote,rate\n",
+	}
+	for name, input := range invalid {
+		t.Run(name, func(t *testing.T) {
+			mockDB := &MockStorage{}
+
+			service := NewCurrencyService(mockDB, logger.NewMockLogger())
+			_, err := service.ImportRatesCSV(context.Background(), strings.NewReader(input))
+
+			require.Error(t, err)
+			mockDB.AssertNotCalled(t, "UpsertExchangeRates", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCurrencyService_ApplyHomeCurrency(t *testing.T) {
+	on := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+
+	t.Run("defaults to the home currency", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+
+		expense := &models.Expense{TotalPrice: 250, Timestamp: on}
+		err := NewCurrencyService(mockDB, logger.NewMockLogger()).ApplyHomeCurrency(context.Background(), 1, expense)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DefaultCurrency, expense.Currency)
+		assert.Equal(t, 250.0, expense.OriginalAmount)
+		assert.Equal(t, 250.0, expense.TotalPrice)
+	})
+
+	t.Run("converts foreign expenses", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(&models.UserSettings{Currency: "INR"}, nil)
+		mockDB.On("GetExchangeRate", mock.Anything, "EUR", "INR", on).Return(&models.ExchangeRate{Rate: 90}, nil)
+
+		expense := &models.Expense{Currency: "eur", OriginalAmount: 45, Timestamp: on}
+		err := NewCurrencyService(mockDB, logger.NewMockLogger()).ApplyHomeCurrency(context.Background(), 1, expense)
+
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", expense.Currency)
+		assert.Equal(t, 45.0, expense.OriginalAmount)
+		assert.InDelta(t, 4050.0, expense.TotalPrice, 0.0001)
+	})
+}
+
+func TestCurrencyService_ConvertExpenses(t *testing.T) {
+	day := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	mockDB := &MockStorage{}
+	mockDB.On("GetExchangeRate", mock.Anything, "EUR", "USD", day).Return(&models.ExchangeRate{Rate: 1.1}, nil).Once()
+	mockDB.On("GetExchangeRate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows)
+
+	expenses := []*models.Expense{
+		{ID: 1, Currency: "EUR", OriginalAmount: 10, TotalPrice: 900, Timestamp: day},
+		{ID: 2, Currency: "EUR", OriginalAmount: 20, TotalPrice: 1800, Timestamp: day},
+		{ID: 3, Currency: "USD", OriginalAmount: 5, TotalPrice: 415, Timestamp: day},
+		{ID: 4, Currency: "JPY", OriginalAmount: 1000, TotalPrice: 560, Timestamp: day},
+	}
+
+	unconverted := NewCurrencyService(mockDB, logger.NewMockLogger()).ConvertExpenses(context.Background(), expenses, "USD")
+
+	assert.Equal(t, 1, unconverted)
+	assert.InDelta(t, 11.0, expenses[0].TotalPrice, 0.0001)
+	assert.InDelta(t, 22.0, expenses[1].TotalPrice, 0.0001)
+	assert.Equal(t, 5.0, expenses[2].TotalPrice)
+	assert.Equal(t, 560.0, expenses[3].TotalPrice)
+}
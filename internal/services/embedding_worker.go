@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// EmbeddingWorkerConfig tunes the background embedding workers
+type EmbeddingWorkerConfig struct {
+	Workers      int           // Expenses embedded concurrently
+	BatchSize    int           // Jobs claimed per poll
+	PollInterval time.Duration // Wait between polls while the queue is empty
+	Lease        time.Duration // How long a claimed job is hidden from other workers
+	MaxAttempts  int           // Attempts before a job is marked failed
+	RetryBackoff time.Duration // Delay before the first retry, doubled after every failure
+	MaxBackoff   time.Duration // Upper bound of the retry delay
+}
+
+// DefaultEmbeddingWorkerConfig returns the worker settings used by the bot
+func DefaultEmbeddingWorkerConfig() EmbeddingWorkerConfig {
+	return EmbeddingWorkerConfig{
+		Workers:      2,
+		BatchSize:    20,
+		PollInterval: 5 * time.Second,
+		Lease:        5 * time.Minute,
+		MaxAttempts:  8,
+		RetryBackoff: 30 * time.Second,
+		MaxBackoff:   time.Hour,
+	}
+}
+
+// EmbeddingWorker embeds queued expenses in the background. Jobs live in the database, so
+// several bot instances can share the queue and nothing is lost on restart.
+type EmbeddingWorker struct {
+	db            database.Storage
+	logger        logger.Logger
+	vectorService *VectorService
+	config        EmbeddingWorkerConfig
+}
+
+// NewEmbeddingWorker creates a worker pool that embeds expenses with vectorService
+func NewEmbeddingWorker(db database.Storage, logger logger.Logger, vectorService *VectorService, config EmbeddingWorkerConfig) *EmbeddingWorker {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.BatchSize < config.Workers {
+		config.BatchSize = config.Workers
+	}
+	return &EmbeddingWorker{
+		db:            db,
+		logger:        logger,
+		vectorService: vectorService,
+		config:        config,
+	}
+}
+
+// Start processes the queue until ctx is done. It polls every PollInterval and right after
+// expenses are queued, and keeps claiming batches without waiting while they come back full.
+func (w *EmbeddingWorker) Start(ctx context.Context) {
+	w.logger.Info(ctx, "Starting embedding workers...", logger.Int("workers", w.config.Workers))
+
+	go func() {
+		ticker := time.NewTicker(w.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				processed, err := w.ProcessBatch(ctx)
+				if err != nil {
+					w.logger.Error(ctx, "Failed to claim embedding jobs", logger.ErrorField(err))
+				}
+				if err != nil || processed < w.config.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-w.vectorService.Queued():
+			}
+		}
+	}()
+}
+
+// ProcessBatch claims one batch of due jobs, embeds them on the worker pool and returns how
+// many were claimed
+func (w *EmbeddingWorker) ProcessBatch(ctx context.Context) (int, error) {
+	jobs, err := w.db.ClaimEmbeddingJobs(ctx, w.config.BatchSize, w.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+
+	queue := make(chan *models.EmbeddingJob)
+	var wg sync.WaitGroup
+	for i := 0; i < min(w.config.Workers, len(jobs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				w.process(ctx, job)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	return len(jobs), nil
+}
+
+// process embeds one expense and completes, retries or fails its job
+func (w *EmbeddingWorker) process(ctx context.Context, job *models.EmbeddingJob) {
+	err := w.vectorService.UpdateExpenseEmbeddings(ctx, job.ExpenseID)
+	if err == nil || expenseGone(err) {
+		// Deleted expenses have nothing left to embed
+		if err := w.db.CompleteEmbeddingJob(ctx, job); err != nil {
+			w.logger.Error(ctx, "Failed to complete embedding job", logger.ErrorField(err),
+				logger.Int("expense_id", int(job.ExpenseID)))
+		}
+		return
+	}
+
+	if job.Attempts >= w.config.MaxAttempts {
+		w.logger.Error(ctx, "Embedding job failed permanently", logger.ErrorField(err),
+			logger.Int("expense_id", int(job.ExpenseID)),
+			logger.Int("attempts", job.Attempts))
+		if err := w.db.FailEmbeddingJob(ctx, job, err.Error()); err != nil {
+			w.logger.Error(ctx, "Failed to mark embedding job as failed", logger.ErrorField(err),
+				logger.Int("expense_id", int(job.ExpenseID)))
+		}
+		return
+	}
+
+	delay := w.backoff(job.Attempts)
+	w.logger.Warn(ctx, "Embedding job failed, will retry", logger.ErrorField(err),
+		logger.Int("expense_id", int(job.ExpenseID)),
+		logger.Int("attempts", job.Attempts),
+		logger.String("retry_in", delay.String()))
+	if err := w.db.RetryEmbeddingJob(ctx, job, time.Now().Add(delay), err.Error()); err != nil {
+		w.logger.Error(ctx, "Failed to reschedule embedding job", logger.ErrorField(err),
+			logger.Int("expense_id", int(job.ExpenseID)))
+	}
+}
+
+// expenseGone reports whether embedding failed because the expense no longer exists
+func expenseGone(err error) bool {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) && appErr.IsNotFoundError() {
+		return true
+	}
+	return database.IsNotFound(err)
+}
+
+// backoff returns the delay before retrying a job that failed its given attempt
+func (w *EmbeddingWorker) backoff(attempts int) time.Duration {
+	delay := w.config.RetryBackoff
+	for i := 1; i < attempts && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, w.config.MaxBackoff)
+}
+
+// Backlog returns the number of pending and failed embedding jobs
+func (w *EmbeddingWorker) Backlog(ctx context.Context) (*models.EmbeddingQueueStats, error) {
+	return w.db.GetEmbeddingQueueStats(ctx)
+}
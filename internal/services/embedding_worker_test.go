@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEmbedder counts the texts it embeds and fails while err is set
+type countingEmbedder struct {
+	mu    sync.Mutex
+	texts []string
+	err   error
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.texts = append(e.texts, text)
+	return embedding.NewLocalEmbedder().Embed(ctx, text)
+}
+
+func (e *countingEmbedder) Name() string {
+	return "counting"
+}
+
+func (e *countingEmbedder) embedded() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.texts...)
+}
+
+func newTestEmbeddingWorker(t *testing.T, config EmbeddingWorkerConfig) (*EmbeddingWorker, *countingEmbedder, database.Storage, []int64) {
+	t.Helper()
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Dining", Group: "Daily Living"})
+
+	var ids []int64
+	for _, notes := range []string{"Lunch at office", "lunch  at office", "Dinner"} {
+		expense := &models.Expense{UserID: 1, CategoryID: 1, CategoryName: "Dining", CategoryGroup: "Daily Living", TotalPrice: 100, Notes: notes}
+		require.NoError(t, storage.CreateExpense(ctx, expense))
+		ids = append(ids, expense.ID)
+	}
+
+	embedder := &countingEmbedder{}
+	log := logger.NewMockLogger()
+	worker := NewEmbeddingWorker(storage, log, NewVectorService(storage, log, embedder), config)
+	return worker, embedder, storage, ids
+}
+
+func TestEmbeddingWorker_ProcessBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("embeds queued expenses once per distinct text", func(t *testing.T) {
+		worker, embedder, storage, ids := newTestEmbeddingWorker(t, DefaultEmbeddingWorkerConfig())
+		require.NoError(t, worker.vectorService.EnqueueEmbeddings(ctx, ids...))
+
+		backlog, err := worker.Backlog(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), backlog.Pending)
+
+		processed, err := worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, processed)
+
+		backlog, err = worker.Backlog(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &models.EmbeddingQueueStats{}, backlog)
+
+		// Notes differing only in spacing share a cache entry, as do the category texts
+		texts := embedder.embedded()
+		assert.Len(t, texts, 4)
+		assert.ElementsMatch(t, []string{"Lunch at office", "lunch at office", "Dinner", "Dining Daily Living"}, texts)
+
+		// Queueing the same expenses again is served from the cache
+		require.NoError(t, storage.EnqueueEmbeddingJobs(ctx, ids))
+		_, err = worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+		assert.Len(t, embedder.embedded(), 4)
+	})
+
+	t.Run("failed jobs are retried later and then marked failed", func(t *testing.T) {
+		config := DefaultEmbeddingWorkerConfig()
+		config.MaxAttempts = 2
+		config.RetryBackoff = 20 * time.Millisecond
+		worker, embedder, _, ids := newTestEmbeddingWorker(t, config)
+		embedder.err = stderrors.New("embedding service unavailable")
+		require.NoError(t, worker.vectorService.EnqueueEmbeddings(ctx, ids[0]))
+
+		processed, err := worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		processed, err = worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, processed, "the retry waits for the backoff")
+
+		time.Sleep(30 * time.Millisecond)
+		processed, err = worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		backlog, err := worker.Backlog(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &models.EmbeddingQueueStats{Failed: 1}, backlog)
+
+		// Editing the expense queues it again with fresh attempts
+		embedder.err = nil
+		require.NoError(t, worker.vectorService.EnqueueEmbeddings(ctx, ids[0]))
+		processed, err = worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		backlog, err = worker.Backlog(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &models.EmbeddingQueueStats{}, backlog)
+	})
+
+	t.Run("deleted expenses are dropped from the queue", func(t *testing.T) {
+		worker, embedder, storage, ids := newTestEmbeddingWorker(t, DefaultEmbeddingWorkerConfig())
+		require.NoError(t, worker.vectorService.EnqueueEmbeddings(ctx, ids[2]))
+		require.NoError(t, storage.DeleteExpense(ctx, ids[2], 1))
+
+		_, err := worker.ProcessBatch(ctx)
+		require.NoError(t, err)
+
+		backlog, err := worker.Backlog(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &models.EmbeddingQueueStats{}, backlog)
+		assert.Empty(t, embedder.embedded())
+	})
+}
+
+func TestEmbeddingWorker_Start(t *testing.T) {
+	config := DefaultEmbeddingWorkerConfig()
+	config.PollInterval = time.Hour
+	worker, embedder, _, ids := newTestEmbeddingWorker(t, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+
+	// Queueing wakes the workers without waiting for the next poll
+	require.NoError(t, worker.vectorService.EnqueueEmbeddings(ctx, ids...))
+	require.Eventually(t, func() bool {
+		backlog, err := worker.Backlog(ctx)
+		return err == nil && backlog.Pending == 0 && len(embedder.embedded()) == 4
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestEmbeddingWorker_Backoff(t *testing.T) {
+	worker := NewEmbeddingWorker(nil, nil, nil, EmbeddingWorkerConfig{RetryBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute})
+
+	assert.Equal(t, 30*time.Second, worker.backoff(1))
+	assert.Equal(t, time.Minute, worker.backoff(2))
+	assert.Equal(t, 4*time.Minute, worker.backoff(4))
+	assert.Equal(t, 5*time.Minute, worker.backoff(5))
+	assert.Equal(t, 5*time.Minute, worker.backoff(50))
+}
@@ -16,17 +16,28 @@ import (
 
 // ExpenseService provides expense-related business logic
 type ExpenseService struct {
-	db        database.Storage
-	logger    logger.Logger
-	validator *validation.Validator
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	vectorService   VectorServiceInterface
+	currencyService *CurrencyService
+	categoryService *CategoryService
+	ledgerService   *LedgerService
 }
 
-// NewExpenseService creates a new expense service
-func NewExpenseService(db database.Storage, logger logger.Logger) *ExpenseService {
+// NewExpenseService creates a new expense service. Amounts are recorded and reported in the home
+// currency from currencyService, categories are looked up with categoryService, and access to
+// shared ledger expenses is checked with ledgerService. New and edited expenses are queued for
+// embedding with vectorService; when it is nil they are not embedded.
+func NewExpenseService(db database.Storage, logger logger.Logger, vectorService VectorServiceInterface, currencyService *CurrencyService, categoryService *CategoryService, ledgerService *LedgerService) *ExpenseService {
 	return &ExpenseService{
-		db:        db,
-		logger:    logger,
-		validator: validation.NewValidator(),
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		vectorService:   vectorService,
+		currencyService: currencyService,
+		categoryService: categoryService,
+		ledgerService:   ledgerService,
 	}
 }
 
@@ -73,58 +84,72 @@ func (s *ExpenseService) CreateExpense(ctx context.Context, expense *models.Expe
 		return errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
 	}
 
-	// Get category by name
-	category, err := s.db.GetCategoryByName(ctx, expense.CategoryName)
-	if err != nil {
-		s.logger.Error(ctx, "Failed to get category by name", logger.ErrorField(err))
-		return errors.NewDatabaseError("Failed to get category", err)
+	// Only owners and editors can add expenses to a shared ledger
+	if expense.LedgerID.Valid {
+		if err := s.ledgerService.checkCanEdit(ctx, expense.LedgerID.Int64, user.ID); err != nil {
+			return err
+		}
 	}
 
-	if category == nil {
-		return errors.NewNotFoundError("Category not found", fmt.Sprintf("Category '%s' not found", expense.CategoryName))
+	// Get category by name, built-in or one of the user's own
+	category, err := s.categoryService.GetUserCategoryByName(ctx, user.ID, expense.CategoryName)
+	if err != nil {
+		return err
 	}
 
-	// Set the expense details
-	var vehicleType sql.NullString
-	if category.Group == "Vehicle" && expense.VehicleType.Valid {
-		if err := s.validator.ValidateVehicleType(expense.VehicleType.String); err != nil {
+	// Link vehicle expenses to one of the user's vehicles
+	if category.Group == "Vehicle" {
+		if err := s.resolveVehicle(ctx, user.ID, expense); err != nil {
 			return err
 		}
-		vehicleType = expense.VehicleType
 	} else {
-		vehicleType = sql.NullString{Valid: false}
+		expense.VehicleType = sql.NullString{Valid: false}
+		expense.VehicleID = sql.NullInt64{Valid: false}
+	}
+
+	// Record the amount in the user's home currency as well as the currency paid in
+	if err := s.currencyService.ApplyHomeCurrency(ctx, user.ID, expense); err != nil {
+		return err
 	}
 
 	// Create expense record
 	expenseRecord := &models.Expense{
-		UserID:      user.ID,
-		CategoryID:  category.ID,
-		VehicleType: vehicleType,
-		Odometer:    expense.Odometer,
-		PetrolPrice: expense.PetrolPrice,
-		TotalPrice:  expense.TotalPrice,
-		Notes:       expense.Notes,
-		Timestamp:   expense.Timestamp,
-	}
-
-	// Save expense to database
-	if err := s.db.CreateExpense(ctx, expenseRecord); err != nil {
+		UserID:         user.ID,
+		CategoryID:     category.ID,
+		VehicleType:    expense.VehicleType,
+		VehicleID:      expense.VehicleID,
+		LedgerID:       expense.LedgerID,
+		Odometer:       expense.Odometer,
+		PetrolPrice:    expense.PetrolPrice,
+		TotalPrice:     expense.TotalPrice,
+		Notes:          expense.Notes,
+		Timestamp:      expense.Timestamp,
+		Currency:       expense.Currency,
+		OriginalAmount: expense.OriginalAmount,
+		CategoryName:   category.Name,
+	}
+
+	// Save expense to database, recorded in its history
+	if err := s.db.CreateExpense(ctx, expenseRecord, &models.ExpenseHistory{
+		UserID: user.ID,
+		Action: models.HistoryActionCreate,
+		After:  models.NewExpenseSnapshot(expenseRecord),
+	}); err != nil {
 		s.logger.Error(ctx, "Failed to create expense", logger.ErrorField(err))
 		return errors.NewDatabaseError("Failed to create expense", err)
 	}
+	expense.ID = expenseRecord.ID
+	expense.UserID = user.ID
+	expense.CategoryID = category.ID
 
 	s.logger.Info(ctx, "Expense created successfully",
 		logger.Int("user_id", int(user.ID)),
 		logger.Int("expense_id", int(expenseRecord.ID)),
 		logger.Float64("total_price", expense.TotalPrice))
 
-	// Generate embeddings for the new expense
-	vectorService := NewVectorService(s.db, s.logger)
-	if err := vectorService.UpdateExpenseEmbeddings(ctx, expenseRecord.ID); err != nil {
-		s.logger.Error(ctx, "Failed to generate embeddings for new expense", logger.ErrorField(err))
-		// Don't fail the expense creation if embedding generation fails
-		// The expense is still created successfully
-	}
+	// Queue embeddings for the new expense; they are generated in the background
+	// so creating an expense does not wait for the embedding backend
+	s.queueEmbeddings(ctx, expenseRecord.ID)
 
 	return nil
 }
@@ -234,13 +259,34 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, expense *models.Expe
 		return errors.NewNotFoundError("Expense not found", fmt.Sprintf("Expense with ID %d not found", expense.ID))
 	}
 
-	// Check ownership
-	if existingExpense.UserID != user.ID {
-		return errors.NewUnauthorizedError("You can only edit your own expenses")
+	// Check ownership; editors of a shared ledger can edit each other's expenses in it
+	if err := s.ledgerService.checkExpenseAccess(ctx, user.ID, existingExpense); err != nil {
+		return err
+	}
+
+	// The expense stays attributed to the member who paid. Its vehicle is kept as it is; a newly
+	// picked one must be the editing user's own.
+	expense.UserID = existingExpense.UserID
+	if expense.VehicleID.Valid && expense.VehicleID == existingExpense.VehicleID {
+		expense.VehicleType = existingExpense.VehicleType
+	} else if expense.VehicleID.Valid || expense.VehicleType.Valid {
+		if err := s.resolveVehicle(ctx, user.ID, expense); err != nil {
+			return err
+		}
+	}
+
+	if err := s.currencyService.ApplyHomeCurrency(ctx, expense.UserID, expense); err != nil {
+		return err
 	}
 
-	// Update expense in database
-	if err := s.db.UpdateExpense(ctx, expense); err != nil {
+	// Update expense in database, recorded in its history
+	if err := s.db.UpdateExpense(ctx, expense, &models.ExpenseHistory{
+		ExpenseID: expense.ID,
+		UserID:    user.ID,
+		Action:    models.HistoryActionUpdate,
+		Before:    models.NewExpenseSnapshot(existingExpense),
+		After:     models.NewExpenseSnapshot(expense),
+	}); err != nil {
 		s.logger.Error(ctx, "Failed to update expense", logger.ErrorField(err))
 		return errors.NewDatabaseError("Failed to update expense", err)
 	}
@@ -250,9 +296,84 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, expense *models.Expe
 		logger.Int("expense_id", int(expense.ID)),
 		logger.Float64("total_price", expense.TotalPrice))
 
+	// Notes or category may have changed
+	s.queueEmbeddings(ctx, expense.ID)
+
 	return nil
 }
 
+// defaultVehicleNames name the vehicle created for a user who picks a vehicle type without
+// having added a vehicle of that type
+var defaultVehicleNames = map[models.VehicleType]string{
+	models.VehicleTypeCar:     "Car",
+	models.VehicleTypeBike:    "Bike",
+	models.VehicleTypeScooter: "Scooter",
+	models.VehicleTypeOther:   "Other",
+}
+
+// resolveVehicle links a vehicle expense to one of the user's vehicles and sets its vehicle type
+// to that vehicle's. An expense with only a vehicle type, e.g. from quick add, gets the first
+// vehicle of that type; if the user has none, a vehicle named after the type is created.
+// Another user's vehicle is reported as not found.
+func (s *ExpenseService) resolveVehicle(ctx context.Context, userID int64, expense *models.Expense) error {
+	if expense.VehicleID.Valid {
+		vehicle, err := s.db.GetVehicleByID(ctx, expense.VehicleID.Int64)
+		if err != nil && !database.IsNotFound(err) {
+			s.logger.Error(ctx, "Failed to get vehicle", logger.ErrorField(err))
+			return errors.NewDatabaseError("Failed to get vehicle", err)
+		}
+		if vehicle == nil || vehicle.UserID != userID {
+			return errors.NewNotFoundError("Vehicle not found", fmt.Sprintf("Vehicle with ID %d not found", expense.VehicleID.Int64))
+		}
+		expense.VehicleType = sql.NullString{String: string(vehicle.Type), Valid: true}
+		return nil
+	}
+
+	if !expense.VehicleType.Valid {
+		return nil
+	}
+	if err := s.validator.ValidateVehicleType(expense.VehicleType.String); err != nil {
+		return err
+	}
+
+	vehicles, err := s.db.GetVehiclesByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get vehicles", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to get vehicles", err)
+	}
+	vehicleType := models.VehicleType(expense.VehicleType.String)
+	for _, vehicle := range vehicles {
+		if vehicle.Type == vehicleType {
+			expense.VehicleID = sql.NullInt64{Int64: vehicle.ID, Valid: true}
+			return nil
+		}
+	}
+
+	vehicle := &models.Vehicle{
+		UserID:   userID,
+		Name:     defaultVehicleNames[vehicleType],
+		Type:     vehicleType,
+		FuelType: models.FuelTypePetrol,
+	}
+	if err := s.db.CreateVehicle(ctx, vehicle); err != nil {
+		s.logger.Error(ctx, "Failed to create vehicle", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to create vehicle", err)
+	}
+	expense.VehicleID = sql.NullInt64{Int64: vehicle.ID, Valid: true}
+	return nil
+}
+
+// queueEmbeddings queues expenses for embedding. Failures are logged and never fail the
+// expense change; the expenses can be queued again with BatchUpdateEmbeddings.
+func (s *ExpenseService) queueEmbeddings(ctx context.Context, expenseIDs ...int64) {
+	if s.vectorService == nil {
+		return
+	}
+	if err := s.vectorService.EnqueueEmbeddings(ctx, expenseIDs...); err != nil {
+		s.logger.Error(ctx, "Failed to queue expense embeddings", logger.ErrorField(err))
+	}
+}
+
 // DeleteExpense deletes an expense
 func (s *ExpenseService) DeleteExpense(ctx context.Context, expenseID, telegramID int64) error {
 	// Validate input
@@ -286,13 +407,18 @@ func (s *ExpenseService) DeleteExpense(ctx context.Context, expenseID, telegramI
 		return errors.NewNotFoundError("Expense not found", fmt.Sprintf("Expense with ID %d not found", expenseID))
 	}
 
-	// Check ownership
-	if existingExpense.UserID != user.ID {
-		return errors.NewUnauthorizedError("You can only delete your own expenses")
+	// Check ownership; editors of a shared ledger can delete each other's expenses in it
+	if err := s.ledgerService.checkExpenseAccess(ctx, user.ID, existingExpense); err != nil {
+		return err
 	}
 
-	// Delete expense from database
-	if err := s.db.DeleteExpense(ctx, expenseID, user.ID); err != nil {
+	// Delete expense from database, recorded in its history
+	if err := s.db.DeleteExpense(ctx, expenseID, existingExpense.UserID, &models.ExpenseHistory{
+		ExpenseID: expenseID,
+		UserID:    user.ID,
+		Action:    models.HistoryActionDelete,
+		Before:    models.NewExpenseSnapshot(existingExpense),
+	}); err != nil {
 		s.logger.Error(ctx, "Failed to delete expense", logger.ErrorField(err))
 		return errors.NewDatabaseError("Failed to delete expense", err)
 	}
@@ -347,9 +473,22 @@ func (s *ExpenseService) GetExpenseStats(ctx context.Context, telegramID int64,
 		expenses = filteredExpenses
 	}
 
+	// Convert to the user's current home currency at each expense date's rate
+	homeCurrency, err := s.currencyService.HomeCurrency(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	unconverted := s.currencyService.ConvertExpenses(ctx, expenses, homeCurrency)
+	if unconverted > 0 {
+		s.logger.Warn(ctx, "Expenses without an exchange rate counted at the amount recorded at entry",
+			logger.Int("count", unconverted), logger.String("currency", homeCurrency))
+	}
+
 	// Calculate statistics
 	stats := &models.ExpenseStats{
 		TotalExpenses: int64(len(expenses)),
+		Currency:      homeCurrency,
+		Unconverted:   unconverted,
 	}
 
 	if len(expenses) == 0 {
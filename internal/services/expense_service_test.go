@@ -3,15 +3,19 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
 	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockStorage is a mock implementation of database.Storage
@@ -56,8 +60,8 @@ func (m *MockStorage) GetCategoryByName(ctx context.Context, name string) (*mode
 	return args.Get(0).(*models.Category), args.Error(1)
 }
 
-func (m *MockStorage) CreateExpense(ctx context.Context, expense *models.Expense) error {
-	args := m.Called(ctx, expense)
+func (m *MockStorage) CreateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expense, history)
 	return args.Error(0)
 }
 
@@ -77,13 +81,13 @@ func (m *MockStorage) GetExpenseByID(ctx context.Context, expenseID int64) (*mod
 	return args.Get(0).(*models.Expense), args.Error(1)
 }
 
-func (m *MockStorage) UpdateExpense(ctx context.Context, expense *models.Expense) error {
-	args := m.Called(ctx, expense)
+func (m *MockStorage) UpdateExpense(ctx context.Context, expense *models.Expense, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expense, history)
 	return args.Error(0)
 }
 
-func (m *MockStorage) DeleteExpense(ctx context.Context, expenseID, userID int64) error {
-	args := m.Called(ctx, expenseID, userID)
+func (m *MockStorage) DeleteExpense(ctx context.Context, expenseID, userID int64, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expenseID, userID, history)
 	return args.Error(0)
 }
 
@@ -154,6 +158,554 @@ func (m *MockStorage) GetExpenseEmbedding(ctx context.Context, expenseID int64)
 	return args.Get(0).(*models.ExpenseEmbedding), args.Error(1)
 }
 
+func (m *MockStorage) SearchExpenses(ctx context.Context, search *models.ExpenseSearch) ([]*models.Expense, int, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.Expense), args.Int(1), args.Error(2)
+}
+
+// BudgetStorage stubs
+func (m *MockStorage) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	args := m.Called(ctx, budget)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetActiveBudgets(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Budget), args.Error(1)
+}
+
+func (m *MockStorage) GetBudgetsByUserID(ctx context.Context, userID int64) ([]*models.Budget, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Budget), args.Error(1)
+}
+
+func (m *MockStorage) GetBudgetByID(ctx context.Context, id int64) (*models.Budget, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockStorage) SetBudgetLimit(ctx context.Context, limit *models.BudgetLimit) error {
+	args := m.Called(ctx, limit)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeactivateBudget(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+// RecurringExpenseStorage stubs
+func (m *MockStorage) CreateRecurringExpense(ctx context.Context, recurring *models.RecurringExpense) error {
+	args := m.Called(ctx, recurring)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetRecurringExpensesByUserID(ctx context.Context, userID int64) ([]*models.RecurringExpense, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RecurringExpense), args.Error(1)
+}
+
+func (m *MockStorage) GetRecurringExpenseByID(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecurringExpense), args.Error(1)
+}
+
+func (m *MockStorage) GetDueRecurringExpenses(ctx context.Context, now time.Time, limit int) ([]*models.RecurringExpense, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RecurringExpense), args.Error(1)
+}
+
+func (m *MockStorage) AdvanceRecurringExpense(ctx context.Context, id int64, currentDue, nextDue time.Time) (bool, error) {
+	args := m.Called(ctx, id, currentDue, nextDue)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) SetRecurringExpenseActive(ctx context.Context, id, userID int64, active bool) error {
+	args := m.Called(ctx, id, userID, active)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteRecurringExpense(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+// ReminderStorage stubs
+func (m *MockStorage) CreateReminder(ctx context.Context, reminder *models.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetRemindersByUserID(ctx context.Context, userID int64) ([]*models.Reminder, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) GetReminderByID(ctx context.Context, id int64) (*models.Reminder, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) UpdateReminder(ctx context.Context, reminder *models.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteReminder(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) ClaimReminder(ctx context.Context, id int64, currentRun, nextRun time.Time) (bool, error) {
+	args := m.Called(ctx, id, currentRun, nextRun)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) GetUserSettings(ctx context.Context, userID int64) (*models.UserSettings, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSettings), args.Error(1)
+}
+
+func (m *MockStorage) UpsertUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UpsertExchangeRates(ctx context.Context, rates []*models.ExchangeRate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetExchangeRate(ctx context.Context, base, quote string, on time.Time) (*models.ExchangeRate, error) {
+	args := m.Called(ctx, base, quote, on)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockStorage) GetLatestExchangeRates(ctx context.Context, limit int) ([]*models.ExchangeRate, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockStorage) StreamExpenses(ctx context.Context, userID int64, startDate, endDate time.Time, fn func(*models.Expense) error) error {
+	args := m.Called(ctx, userID, startDate, endDate, fn)
+	if expenses, ok := args.Get(0).([]*models.Expense); ok {
+		for _, expense := range expenses {
+			if err := fn(expense); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStorage) CreateExpenses(ctx context.Context, expenses []*models.Expense, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, expenses, history)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetImportProfile(ctx context.Context, userID int64, name string) (*models.ImportProfile, error) {
+	args := m.Called(ctx, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportProfile), args.Error(1)
+}
+
+func (m *MockStorage) UpsertImportProfile(ctx context.Context, profile *models.ImportProfile) error {
+	args := m.Called(ctx, profile)
+	return args.Error(0)
+}
+
+func (m *MockStorage) EnqueueEmbeddingJobs(ctx context.Context, expenseIDs []int64) error {
+	args := m.Called(ctx, expenseIDs)
+	return args.Error(0)
+}
+
+func (m *MockStorage) EnqueueMissingEmbeddings(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStorage) ClaimEmbeddingJobs(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingJob, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.EmbeddingJob), args.Error(1)
+}
+
+func (m *MockStorage) CompleteEmbeddingJob(ctx context.Context, job *models.EmbeddingJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RetryEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, runAt time.Time, lastError string) error {
+	args := m.Called(ctx, job, runAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockStorage) FailEmbeddingJob(ctx context.Context, job *models.EmbeddingJob, lastError string) error {
+	args := m.Called(ctx, job, lastError)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetEmbeddingQueueStats(ctx context.Context) (*models.EmbeddingQueueStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmbeddingQueueStats), args.Error(1)
+}
+
+func (m *MockStorage) GetCachedEmbedding(ctx context.Context, contentHash string) ([]float32, error) {
+	args := m.Called(ctx, contentHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]float32), args.Error(1)
+}
+
+func (m *MockStorage) SaveCachedEmbedding(ctx context.Context, contentHash string, embedding []float32) error {
+	args := m.Called(ctx, contentHash, embedding)
+	return args.Error(0)
+}
+
+func (m *MockStorage) CreateVehicle(ctx context.Context, vehicle *models.Vehicle) error {
+	args := m.Called(ctx, vehicle)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetVehiclesByUserID(ctx context.Context, userID int64) ([]*models.Vehicle, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Vehicle), args.Error(1)
+}
+
+func (m *MockStorage) GetVehicleByID(ctx context.Context, id int64) (*models.Vehicle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Vehicle), args.Error(1)
+}
+
+func (m *MockStorage) DeleteVehicle(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetCategoriesByUserID(ctx context.Context, userID int64) ([]*models.Category, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Category), args.Error(1)
+}
+
+func (m *MockStorage) CreateCategory(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UpdateCategory(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockStorage) MergeCategory(ctx context.Context, userID, fromID, toID int64) ([]int64, error) {
+	args := m.Called(ctx, userID, fromID, toID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockStorage) GetCategoryGroups(ctx context.Context, userID int64) ([]*models.CategoryGroup, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CategoryGroup), args.Error(1)
+}
+
+func (m *MockStorage) CreateCategoryGroup(ctx context.Context, group *models.CategoryGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RenameCategoryGroup(ctx context.Context, group *models.CategoryGroup, oldName string) error {
+	args := m.Called(ctx, group, oldName)
+	return args.Error(0)
+}
+
+func (m *MockStorage) CreateLedger(ctx context.Context, ledger *models.Ledger, ownerID int64) error {
+	args := m.Called(ctx, ledger, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetLedgersByUserID(ctx context.Context, userID int64) ([]*models.Ledger, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Ledger), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerByInviteCode(ctx context.Context, inviteCode string) (*models.Ledger, error) {
+	args := m.Called(ctx, inviteCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Ledger), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerByChatID(ctx context.Context, chatID int64) (*models.Ledger, error) {
+	args := m.Called(ctx, chatID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Ledger), args.Error(1)
+}
+
+func (m *MockStorage) LinkLedgerChat(ctx context.Context, ledgerID, chatID int64) error {
+	args := m.Called(ctx, ledgerID, chatID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UnlinkLedgerChat(ctx context.Context, chatID int64) error {
+	args := m.Called(ctx, chatID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteLedger(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetLedgerMembers(ctx context.Context, ledgerID int64) ([]*models.LedgerMember, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LedgerMember), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerMember(ctx context.Context, ledgerID, userID int64) (*models.LedgerMember, error) {
+	args := m.Called(ctx, ledgerID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerMember), args.Error(1)
+}
+
+func (m *MockStorage) AddLedgerMember(ctx context.Context, member *models.LedgerMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UpdateLedgerMemberRole(ctx context.Context, ledgerID, userID int64, role models.LedgerRole) error {
+	args := m.Called(ctx, ledgerID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RemoveLedgerMember(ctx context.Context, ledgerID, userID int64) error {
+	args := m.Called(ctx, ledgerID, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) SetActiveLedger(ctx context.Context, userID, ledgerID int64) error {
+	args := m.Called(ctx, userID, ledgerID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetLedgerExpenses(ctx context.Context, ledgerID int64, startDate, endDate time.Time) ([]*models.Expense, error) {
+	args := m.Called(ctx, ledgerID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) SetExpenseSplits(ctx context.Context, expenseID int64, splits []*models.ExpenseSplit) error {
+	args := m.Called(ctx, expenseID, splits)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetExpenseSplits(ctx context.Context, expenseID int64) ([]*models.ExpenseSplit, error) {
+	args := m.Called(ctx, expenseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExpenseSplit), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerSplits(ctx context.Context, ledgerID int64) ([]*models.ExpenseSplit, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExpenseSplit), args.Error(1)
+}
+
+func (m *MockStorage) GetLedgerSplitExpenses(ctx context.Context, ledgerID int64) ([]*models.Expense, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) CreateSettlement(ctx context.Context, settlement *models.Settlement) error {
+	args := m.Called(ctx, settlement)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetSettlementsByLedgerID(ctx context.Context, ledgerID int64) ([]*models.Settlement, error) {
+	args := m.Called(ctx, ledgerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Settlement), args.Error(1)
+}
+
+func (m *MockStorage) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetAttachmentByID(ctx context.Context, id int64) (*models.Attachment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockStorage) GetAttachmentsByExpenseIDs(ctx context.Context, expenseIDs []int64) ([]*models.Attachment, error) {
+	args := m.Called(ctx, expenseIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+func (m *MockStorage) GetDeletedExpenseByID(ctx context.Context, id int64) (*models.Expense, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) GetDeletedExpenses(ctx context.Context, userID int64, limit int) ([]*models.Expense, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Expense), args.Error(1)
+}
+
+func (m *MockStorage) RestoreExpense(ctx context.Context, id, userID int64, history ...*models.ExpenseHistory) error {
+	args := m.Called(ctx, id, userID, history)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetExpenseHistory(ctx context.Context, expenseID int64) ([]*models.ExpenseHistory, error) {
+	args := m.Called(ctx, expenseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExpenseHistory), args.Error(1)
+}
+
+func (m *MockStorage) GetLastUndoableChange(ctx context.Context, userID int64) (*models.ExpenseHistory, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExpenseHistory), args.Error(1)
+}
+
+func (m *MockStorage) GetUserState(ctx context.Context, chatID, userID int64) (*models.UserState, error) {
+	args := m.Called(ctx, chatID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserState), args.Error(1)
+}
+
+func (m *MockStorage) SaveUserState(ctx context.Context, chatID, userID int64, state *models.UserState, expiresAt time.Time) error {
+	args := m.Called(ctx, chatID, userID, state, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteUserState(ctx context.Context, chatID, userID int64) error {
+	args := m.Called(ctx, chatID, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteExpiredUserStates(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStorage) CountUserStates(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// newTestExpenseService creates an expense service with its collaborators on the same storage
+func newTestExpenseService(db database.Storage, log logger.Logger, vectorService VectorServiceInterface) *ExpenseService {
+	currencyService := NewCurrencyService(db, log)
+	return NewExpenseService(db, log, vectorService, currencyService, NewCategoryService(db, log, nil), NewLedgerService(db, log, currencyService))
+}
+
 func TestExpenseService_CreateExpense(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -175,18 +727,94 @@ func TestExpenseService_CreateExpense(t *testing.T) {
 			setupMock: func(mockDB *MockStorage) {
 				user := &models.User{ID: 1, TelegramID: 12345}
 				category := &models.Category{ID: 1, Name: "⛽ Petrol", Group: "Vehicle"}
-				expenseRecord := &models.Expense{ID: 1, UserID: 1, TotalPrice: 100.0, CategoryName: "⛽ Petrol", Notes: "Test expense"}
 
 				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
 				mockDB.On("GetCategoryByName", mock.Anything, "⛽ Petrol").Return(category, nil)
-				mockDB.On("CreateExpense", mock.Anything, mock.AnythingOfType("*models.Expense")).Return(nil)
-				// General mock for all GetExpenseByID calls
-				mockDB.On("GetExpenseByID", mock.Anything, mock.AnythingOfType("int64")).Return(expenseRecord, nil)
-				// Mock UpdateExpenseEmbedding
-				mockDB.On("UpdateExpenseEmbedding", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("[]float32"), mock.AnythingOfType("[]float32")).Return(nil)
+				mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+				mockDB.On("CreateExpense", mock.Anything, mock.AnythingOfType("*models.Expense"), mock.MatchedBy(func(entries []*models.ExpenseHistory) bool {
+					return len(entries) == 1 && entries[0].Action == models.HistoryActionCreate && entries[0].UserID == 1 &&
+						entries[0].Before == nil && entries[0].After.TotalPrice == 100.0
+				})).Return(nil)
+				// Embeddings are queued, not generated inline
+				mockDB.On("EnqueueEmbeddingJobs", mock.Anything, mock.AnythingOfType("[]int64")).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name: "vehicle type without vehicles creates a default vehicle",
+			expense: &models.Expense{
+				TotalPrice:   100.0,
+				CategoryName: "⛽ Petrol",
+				VehicleType:  sql.NullString{String: "BIKE", Valid: true},
+				Timestamp:    time.Now(),
+			},
+			telegramID: 12345,
+			setupMock: func(mockDB *MockStorage) {
+				user := &models.User{ID: 1, TelegramID: 12345}
+				category := &models.Category{ID: 1, Name: "⛽ Petrol", Group: "Vehicle"}
+
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
+				mockDB.On("GetCategoryByName", mock.Anything, "⛽ Petrol").Return(category, nil)
+				mockDB.On("GetVehiclesByUserID", mock.Anything, int64(1)).Return([]*models.Vehicle{}, nil)
+				mockDB.On("CreateVehicle", mock.Anything, mock.MatchedBy(func(v *models.Vehicle) bool {
+					return v.Name == "Bike" && v.Type == models.VehicleTypeBike && v.UserID == 1
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.Vehicle).ID = 7
+				}).Return(nil)
+				mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+				mockDB.On("CreateExpense", mock.Anything, mock.MatchedBy(func(e *models.Expense) bool {
+					return e.VehicleID.Int64 == 7 && e.VehicleType.String == "BIKE"
+				}), mock.Anything).Return(nil)
+				mockDB.On("EnqueueEmbeddingJobs", mock.Anything, mock.AnythingOfType("[]int64")).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name: "vehicle takes its type from the vehicle",
+			expense: &models.Expense{
+				TotalPrice:   100.0,
+				CategoryName: "⛽ Petrol",
+				VehicleType:  sql.NullString{String: "CAR", Valid: true},
+				VehicleID:    sql.NullInt64{Int64: 8, Valid: true},
+				Timestamp:    time.Now(),
+			},
+			telegramID: 12345,
+			setupMock: func(mockDB *MockStorage) {
+				user := &models.User{ID: 1, TelegramID: 12345}
+				category := &models.Category{ID: 1, Name: "⛽ Petrol", Group: "Vehicle"}
+				scooter := &models.Vehicle{ID: 8, UserID: 1, Name: "Ather", Type: models.VehicleTypeScooter}
+
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
+				mockDB.On("GetCategoryByName", mock.Anything, "⛽ Petrol").Return(category, nil)
+				mockDB.On("GetVehicleByID", mock.Anything, int64(8)).Return(scooter, nil)
+				mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+				mockDB.On("CreateExpense", mock.Anything, mock.MatchedBy(func(e *models.Expense) bool {
+					return e.VehicleID.Int64 == 8 && e.VehicleType.String == "SCOOTER"
+				}), mock.Anything).Return(nil)
+				mockDB.On("EnqueueEmbeddingJobs", mock.Anything, mock.AnythingOfType("[]int64")).Return(nil)
 			},
 			expectError: false,
 		},
+		{
+			name: "another user's vehicle",
+			expense: &models.Expense{
+				TotalPrice:   100.0,
+				CategoryName: "⛽ Petrol",
+				VehicleID:    sql.NullInt64{Int64: 9, Valid: true},
+				Timestamp:    time.Now(),
+			},
+			telegramID: 12345,
+			setupMock: func(mockDB *MockStorage) {
+				user := &models.User{ID: 1, TelegramID: 12345}
+				category := &models.Category{ID: 1, Name: "⛽ Petrol", Group: "Vehicle"}
+
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
+				mockDB.On("GetCategoryByName", mock.Anything, "⛽ Petrol").Return(category, nil)
+				mockDB.On("GetVehicleByID", mock.Anything, int64(9)).Return(&models.Vehicle{ID: 9, UserID: 2, Type: models.VehicleTypeCar}, nil)
+			},
+			expectError: true,
+			errorType:   errors.ErrorTypeNotFound,
+		},
 		{
 			name: "invalid telegram ID",
 			expense: &models.Expense{
@@ -241,6 +869,7 @@ func TestExpenseService_CreateExpense(t *testing.T) {
 				user := &models.User{ID: 1, TelegramID: 12345}
 				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
 				mockDB.On("GetCategoryByName", mock.Anything, "Invalid Category").Return(nil, nil)
+				mockDB.On("GetCategoriesByUserID", mock.Anything, int64(1)).Return(nil, nil)
 			},
 			expectError: true,
 			errorType:   errors.ErrorTypeNotFound,
@@ -255,7 +884,7 @@ func TestExpenseService_CreateExpense(t *testing.T) {
 
 			logger := logger.NewMockLogger()
 
-			service := NewExpenseService(mockDB, logger)
+			service := newTestExpenseService(mockDB, logger, NewVectorService(mockDB, logger, embedding.NewLocalEmbedder()))
 
 			// Execute
 			err := service.CreateExpense(context.Background(), tt.expense, tt.telegramID)
@@ -329,7 +958,7 @@ func TestExpenseService_GetExpensesByTelegramID(t *testing.T) {
 
 			logger := logger.NewMockLogger()
 
-			service := NewExpenseService(mockDB, logger)
+			service := newTestExpenseService(mockDB, logger, NewVectorService(mockDB, logger, embedding.NewLocalEmbedder()))
 
 			// Execute
 			expenses, err := service.GetExpensesByTelegramID(context.Background(), tt.telegramID, tt.limit, tt.offset)
@@ -350,6 +979,36 @@ func TestExpenseService_GetExpensesByTelegramID(t *testing.T) {
 	}
 }
 
+func TestExpenseService_GetExpenseStats(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	user := &models.User{TelegramID: 111, FirstName: "Asha"}
+	require.NoError(t, storage.CreateUser(ctx, user))
+	day := time.Now().Add(-time.Hour)
+	for _, expense := range []*models.Expense{
+		{UserID: user.ID, CategoryName: "Dining", Currency: "INR", OriginalAmount: 100, TotalPrice: 100, Timestamp: day},
+		{UserID: user.ID, CategoryName: "Dining", Currency: "USD", OriginalAmount: 10, TotalPrice: 830, Timestamp: day},
+	} {
+		require.NoError(t, storage.CreateExpense(ctx, expense))
+	}
+	currencyService := NewCurrencyService(storage, logger.NewMockLogger())
+	service := newTestExpenseService(storage, logger.NewMockLogger(), nil)
+
+	// Without a USD rate the expense counts at the amount recorded at entry, and is reported
+	stats, err := service.GetExpenseStats(ctx, 111, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "INR", stats.Currency)
+	assert.Equal(t, 1, stats.Unconverted)
+	assert.Equal(t, 930.0, stats.TotalSpent)
+
+	_, err = currencyService.SetRate(ctx, "USD", "INR", 80, day.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	stats, err = service.GetExpenseStats(ctx, 111, nil, nil)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Unconverted)
+	assert.Equal(t, 900.0, stats.TotalSpent)
+}
+
 func TestExpenseService_UpdateExpense(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -374,7 +1033,12 @@ func TestExpenseService_UpdateExpense(t *testing.T) {
 
 				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
 				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(existingExpense, nil)
-				mockDB.On("UpdateExpense", mock.Anything, mock.AnythingOfType("*models.Expense")).Return(nil)
+				mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+				mockDB.On("UpdateExpense", mock.Anything, mock.AnythingOfType("*models.Expense"), mock.MatchedBy(func(entries []*models.ExpenseHistory) bool {
+					return len(entries) == 1 && entries[0].Action == models.HistoryActionUpdate &&
+						entries[0].Before.TotalPrice == 100.0 && entries[0].After.TotalPrice == 150.0
+				})).Return(nil)
+				mockDB.On("EnqueueEmbeddingJobs", mock.Anything, []int64{1}).Return(nil)
 			},
 			expectError: false,
 		},
@@ -407,7 +1071,7 @@ func TestExpenseService_UpdateExpense(t *testing.T) {
 
 			logger := logger.NewMockLogger()
 
-			service := NewExpenseService(mockDB, logger)
+			service := newTestExpenseService(mockDB, logger, NewVectorService(mockDB, logger, embedding.NewLocalEmbedder()))
 
 			// Execute
 			err := service.UpdateExpense(context.Background(), tt.expense, tt.telegramID)
@@ -0,0 +1,101 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/export"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// ExportService provides expense export business logic
+type ExportService struct {
+	db        database.Storage
+	logger    logger.Logger
+	validator *validation.Validator
+}
+
+// NewExportService creates a new export service
+func NewExportService(db database.Storage, logger logger.Logger) *ExportService {
+	return &ExportService{
+		db:        db,
+		logger:    logger,
+		validator: validation.NewValidator(),
+	}
+}
+
+// ExportExpenses writes a user's expenses between startDate (inclusive) and endDate (exclusive)
+// to w in the given format and returns the number of exported expenses. Zero dates leave the
+// range open. Expenses are streamed from storage, so large histories are never held in memory.
+func (s *ExportService) ExportExpenses(ctx context.Context, telegramID int64, startDate, endDate time.Time, format export.Format, w io.Writer) (int, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return 0, err
+	}
+
+	if !startDate.IsZero() && !endDate.IsZero() && !startDate.Before(endDate) {
+		return 0, errors.NewValidationError("Invalid date range", "Start date must be before end date")
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return 0, err
+	}
+
+	writer, err := export.NewWriter(format, w)
+	if err != nil {
+		return 0, errors.NewValidationError("Invalid export format", err.Error())
+	}
+
+	count := 0
+	var writeErr error
+	err = s.db.StreamExpenses(ctx, user.ID, startDate, endDate, func(expense *models.Expense) error {
+		if writeErr = writer.Write(expense); writeErr != nil {
+			return writeErr
+		}
+		count++
+		return nil
+	})
+	if writeErr != nil {
+		s.logger.Error(ctx, "Failed to write export", logger.ErrorField(writeErr))
+		return count, errors.NewInternalError("Failed to write export", writeErr)
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to export expenses", logger.ErrorField(err))
+		return count, errors.NewDatabaseError("Failed to export expenses", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		s.logger.Error(ctx, "Failed to finish export", logger.ErrorField(err))
+		return count, errors.NewInternalError("Failed to finish export", err)
+	}
+
+	s.logger.Info(ctx, "Expenses exported successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("count", count),
+		logger.String("format", string(format)))
+
+	return count, nil
+}
+
+// getUser retrieves a user by Telegram ID
+func (s *ExportService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	return user, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/export"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportService_ExportExpenses(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	expenses := []*models.Expense{
+		{CategoryName: "Petrol", CategoryGroup: "Vehicle", TotalPrice: 2000, Currency: "INR", OriginalAmount: 2000, Timestamp: start},
+		{CategoryName: "Groceries", CategoryGroup: "Daily Living", TotalPrice: 450, Currency: "INR", OriginalAmount: 450, Timestamp: start.AddDate(0, 0, 3)},
+	}
+
+	tests := []struct {
+		name          string
+		startDate     time.Time
+		endDate       time.Time
+		format        export.Format
+		setupMock     func(*MockStorage)
+		expectedCount int
+		expectError   bool
+		errorType     errors.ErrorType
+	}{
+		{
+			name:      "exports expenses in range",
+			startDate: start,
+			endDate:   end,
+			format:    export.FormatCSV,
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("StreamExpenses", mock.Anything, int64(1), start, end, mock.Anything).Return(expenses, nil)
+			},
+			expectedCount: 2,
+		},
+		{
+			name:        "start date after end date",
+			startDate:   end,
+			endDate:     start,
+			format:      export.FormatCSV,
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+		{
+			name:   "unsupported format",
+			format: export.Format("pdf"),
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+			},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+		{
+			name:   "storage error",
+			format: export.FormatCSV,
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("StreamExpenses", mock.Anything, int64(1), time.Time{}, time.Time{}, mock.Anything).Return(nil, fmt.Errorf("connection lost"))
+			},
+			expectError: true,
+			errorType:   errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockStorage)
+			tt.setupMock(mockDB)
+			service := NewExportService(mockDB, logger.NewMockLogger())
+
+			var buf bytes.Buffer
+			count, err := service.ExportExpenses(context.Background(), 12345, tt.startDate, tt.endDate, tt.format, &buf)
+
+			if tt.expectError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok)
+				assert.Equal(t, tt.errorType, appErr.Type)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, count)
+
+				records, err := csv.NewReader(&buf).ReadAll()
+				require.NoError(t, err)
+				assert.Len(t, records, tt.expectedCount+1)
+				assert.Equal(t, "Groceries", records[2][1])
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// MaxTrashExpenses is how many deleted expenses the trash lists
+const MaxTrashExpenses = 10
+
+// HistoryService keeps the audit history of expense changes, and undoes them and restores
+// deleted expenses from the trash
+type HistoryService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	ledgerService   *LedgerService
+	categoryService *CategoryService
+	expenseService  *ExpenseService
+}
+
+// NewHistoryService creates a new history service. Access to shared ledger expenses is checked
+// with ledgerService, categories are looked up with categoryService, and expenses changed back
+// by an undo are queued for embedding with expenseService.
+func NewHistoryService(db database.Storage, logger logger.Logger, ledgerService *LedgerService, categoryService *CategoryService, expenseService *ExpenseService) *HistoryService {
+	return &HistoryService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		ledgerService:   ledgerService,
+		categoryService: categoryService,
+		expenseService:  expenseService,
+	}
+}
+
+// GetHistory returns an expense and its changes, oldest first, if the user may see it. Deleted
+// expenses keep their history.
+func (s *HistoryService) GetHistory(ctx context.Context, telegramID, expenseID int64) (*models.Expense, []*models.ExpenseHistory, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.validator.ValidateExpenseID(expenseID); err != nil {
+		return nil, nil, err
+	}
+
+	expense, err := s.db.GetExpenseByID(ctx, expenseID)
+	if database.IsNotFound(err) {
+		expense, err = s.db.GetDeletedExpenseByID(ctx, expenseID)
+	}
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get expense by ID", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to get expense", err)
+	}
+	if expense == nil {
+		return nil, nil, errors.NewNotFoundError("Expense not found", fmt.Sprintf("Expense with ID %d not found", expenseID))
+	}
+	if err := s.ledgerService.checkExpenseVisible(ctx, user.ID, expense); err != nil {
+		return nil, nil, err
+	}
+
+	history, err := s.db.GetExpenseHistory(ctx, expense.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get expense history", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to get expense history", err)
+	}
+
+	return expense, history, nil
+}
+
+// Undo reverts the user's most recent change to an expense: an added or restored expense is
+// deleted, an edit is changed back and a deleted expense is restored. Imported expenses are
+// left alone. It returns the change that was undone.
+func (s *HistoryService) Undo(ctx context.Context, telegramID int64) (*models.ExpenseHistory, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := s.db.GetLastUndoableChange(ctx, user.ID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("Nothing to undo", "You have no changes to undo")
+		}
+		s.logger.Error(ctx, "Failed to get last change", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get last change", err)
+	}
+
+	switch change.Action {
+	case models.HistoryActionCreate, models.HistoryActionRestore:
+		err = s.undoCreate(ctx, user.ID, change)
+	case models.HistoryActionUpdate:
+		err = s.undoUpdate(ctx, user.ID, change)
+	case models.HistoryActionDelete:
+		err = s.undoDelete(ctx, user.ID, change)
+	default:
+		return nil, errors.NewValidationError("Cannot undo", fmt.Sprintf("%s changes cannot be undone", change.Action))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Expense change undone",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("expense_id", int(change.ExpenseID)),
+		logger.String("action", string(change.Action)))
+
+	return change, nil
+}
+
+// newUndoEntry starts the history entry of undoing change. Recording it marks change undone, in
+// the same transaction as the expense is changed back.
+func newUndoEntry(userID int64, change *models.ExpenseHistory, action models.HistoryAction) *models.ExpenseHistory {
+	entry := &models.ExpenseHistory{ExpenseID: change.ExpenseID, UserID: userID, Action: action}
+	entry.UndoOf.Int64, entry.UndoOf.Valid = change.ID, true
+	return entry
+}
+
+// undoCreate deletes an expense the user added or restored
+func (s *HistoryService) undoCreate(ctx context.Context, userID int64, change *models.ExpenseHistory) error {
+	expense, err := s.getLiveExpense(ctx, change.ExpenseID)
+	if err != nil {
+		return err
+	}
+	if err := s.ledgerService.checkExpenseAccess(ctx, userID, expense); err != nil {
+		return err
+	}
+
+	entry := newUndoEntry(userID, change, models.HistoryActionDelete)
+	entry.Before = models.NewExpenseSnapshot(expense)
+	if err := s.db.DeleteExpense(ctx, expense.ID, expense.UserID, entry); err != nil {
+		return s.undoFailed(ctx, "Failed to delete expense", err)
+	}
+
+	return nil
+}
+
+// undoUpdate changes an edited expense back to how it was before the edit
+func (s *HistoryService) undoUpdate(ctx context.Context, userID int64, change *models.ExpenseHistory) error {
+	if change.Before == nil {
+		return errors.NewInternalError("Failed to undo edit", fmt.Errorf("change %d has no snapshot", change.ID))
+	}
+	expense, err := s.getLiveExpense(ctx, change.ExpenseID)
+	if err != nil {
+		return err
+	}
+	if err := s.ledgerService.checkExpenseAccess(ctx, userID, expense); err != nil {
+		return err
+	}
+
+	before := *change.Before
+	if before.CategoryID != expense.CategoryID {
+		if err := s.findSnapshotCategory(ctx, expense.UserID, &before); err != nil {
+			return err
+		}
+	}
+
+	entry := newUndoEntry(userID, change, models.HistoryActionUpdate)
+	entry.Before = models.NewExpenseSnapshot(expense)
+	before.ApplyTo(expense)
+	entry.After = models.NewExpenseSnapshot(expense)
+	if err := s.db.UpdateExpense(ctx, expense, entry); err != nil {
+		return s.undoFailed(ctx, "Failed to update expense", err)
+	}
+
+	// Notes or category may have changed back
+	s.expenseService.queueEmbeddings(ctx, expense.ID)
+
+	return nil
+}
+
+// findSnapshotCategory checks the category of a snapshot still exists for the expense's owner.
+// A category merged away since is replaced by one of the same name if it was added again.
+func (s *HistoryService) findSnapshotCategory(ctx context.Context, ownerID int64, snapshot *models.ExpenseSnapshot) error {
+	categories, err := s.categoryService.getAllUserCategories(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if category.ID == snapshot.CategoryID {
+			return nil
+		}
+	}
+	if category := matchCategory(categories, snapshot.CategoryName); category != nil {
+		snapshot.CategoryID, snapshot.CategoryName = category.ID, category.Name
+		return nil
+	}
+	return errors.NewNotFoundError("Category deleted",
+		fmt.Sprintf("The category %s has been merged away since; add it again with /categories add %s to undo this change",
+			snapshot.CategoryName, snapshot.CategoryName))
+}
+
+// undoDelete restores an expense the user deleted
+func (s *HistoryService) undoDelete(ctx context.Context, userID int64, change *models.ExpenseHistory) error {
+	_, err := s.restore(ctx, userID, change.ExpenseID, newUndoEntry(userID, change, models.HistoryActionRestore))
+	return err
+}
+
+// undoFailed reports an undo that the database refused. Not found means the change was undone,
+// or the expense changed, at the same time.
+func (s *HistoryService) undoFailed(ctx context.Context, message string, err error) error {
+	if database.IsNotFound(err) {
+		return errors.NewNotFoundError("Nothing to undo", "The change was undone or the expense changed at the same time")
+	}
+	s.logger.Error(ctx, message, logger.ErrorField(err))
+	return errors.NewDatabaseError(message, err)
+}
+
+// GetTrash returns the user's deleted expenses, most recently deleted first
+func (s *HistoryService) GetTrash(ctx context.Context, telegramID int64) ([]*models.Expense, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := s.db.GetDeletedExpenses(ctx, user.ID, MaxTrashExpenses)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get deleted expenses", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get deleted expenses", err)
+	}
+
+	return expenses, nil
+}
+
+// RestoreExpense brings a deleted expense back from the trash. The restore is recorded, so
+// /undo deletes it again.
+func (s *HistoryService) RestoreExpense(ctx context.Context, telegramID, expenseID int64) (*models.Expense, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validator.ValidateExpenseID(expenseID); err != nil {
+		return nil, err
+	}
+
+	expense, err := s.restore(ctx, user.ID, expenseID, &models.ExpenseHistory{
+		ExpenseID: expenseID,
+		UserID:    user.ID,
+		Action:    models.HistoryActionRestore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Expense restored successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("expense_id", int(expense.ID)))
+
+	return expense, nil
+}
+
+// restore undoes the soft delete of an expense the user may edit, recording entry with the
+// restored expense in the same transaction
+func (s *HistoryService) restore(ctx context.Context, userID, expenseID int64, entry *models.ExpenseHistory) (*models.Expense, error) {
+	expense, err := s.db.GetDeletedExpenseByID(ctx, expenseID)
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get deleted expense", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get deleted expense", err)
+	}
+	if expense == nil {
+		return nil, errors.NewNotFoundError("Expense not in trash", fmt.Sprintf("Expense with ID %d is not in the trash", expenseID))
+	}
+	if err := s.ledgerService.checkExpenseAccess(ctx, userID, expense); err != nil {
+		return nil, err
+	}
+
+	expense.DeletedAt = nil
+	entry.After = models.NewExpenseSnapshot(expense)
+	if err := s.db.RestoreExpense(ctx, expense.ID, expense.UserID, entry); err != nil {
+		if entry.UndoOf.Valid {
+			return nil, s.undoFailed(ctx, "Failed to restore expense", err)
+		}
+		s.logger.Error(ctx, "Failed to restore expense", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to restore expense", err)
+	}
+
+	return expense, nil
+}
+
+// getLiveExpense returns an expense that has not been deleted. A change to an expense someone
+// else has deleted since cannot be undone until it is restored.
+func (s *HistoryService) getLiveExpense(ctx context.Context, expenseID int64) (*models.Expense, error) {
+	expense, err := s.db.GetExpenseByID(ctx, expenseID)
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get expense by ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get expense", err)
+	}
+	if expense == nil {
+		return nil, errors.NewNotFoundError("Expense deleted",
+			fmt.Sprintf("Expense with ID %d has been deleted since; restore it from the trash first", expenseID))
+	}
+	return expense, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHistoryService creates a history service with its collaborators on the same storage
+func newTestHistoryService(db database.Storage, log logger.Logger) *HistoryService {
+	currencyService := NewCurrencyService(db, log)
+	categoryService := NewCategoryService(db, log, nil)
+	ledgerService := NewLedgerService(db, log, currencyService)
+	expenseService := NewExpenseService(db, log, nil, currencyService, categoryService, ledgerService)
+	return NewHistoryService(db, log, ledgerService, categoryService, expenseService)
+}
+
+func TestHistoryService(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Food"})
+	for _, user := range []*models.User{
+		{TelegramID: 111, FirstName: "Asha"},
+		{TelegramID: 222, FirstName: "Ravi"},
+	} {
+		require.NoError(t, storage.CreateUser(ctx, user))
+	}
+
+	mockLogger := logger.NewMockLogger()
+	expenseService := newTestExpenseService(storage, mockLogger, nil)
+	service := newTestHistoryService(storage, mockLogger)
+
+	addExpense := func(t *testing.T, amount float64) *models.Expense {
+		t.Helper()
+		expense := &models.Expense{CategoryName: "Dining", TotalPrice: amount, Notes: "lunch", Timestamp: time.Now()}
+		require.NoError(t, expenseService.CreateExpense(ctx, expense, 111))
+		return expense
+	}
+	editAmount := func(t *testing.T, expenseID int64, amount float64) {
+		t.Helper()
+		expense, err := storage.GetExpenseByID(ctx, expenseID)
+		require.NoError(t, err)
+		expense.TotalPrice, expense.OriginalAmount = amount, amount
+		require.NoError(t, expenseService.UpdateExpense(ctx, expense, 111))
+	}
+	undoAll := func() {
+		for {
+			if _, err := service.Undo(ctx, 111); err != nil {
+				return
+			}
+		}
+	}
+
+	t.Run("records every change with who made it", func(t *testing.T) {
+		expense := addExpense(t, 450)
+		editAmount(t, expense.ID, 500)
+		require.NoError(t, expenseService.DeleteExpense(ctx, expense.ID, 111))
+
+		got, history, err := service.GetHistory(ctx, 111, expense.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, got.DeletedAt, "deleted expenses keep their history")
+		require.Len(t, history, 3)
+
+		assert.Equal(t, models.HistoryActionCreate, history[0].Action)
+		assert.Nil(t, history[0].Before)
+		assert.Equal(t, 450.0, history[0].After.TotalPrice)
+		assert.Equal(t, "Dining", history[0].After.CategoryName)
+		assert.Equal(t, "Asha", history[0].ActorName)
+
+		assert.Equal(t, models.HistoryActionUpdate, history[1].Action)
+		assert.Equal(t, 450.0, history[1].Before.TotalPrice)
+		assert.Equal(t, 500.0, history[1].After.TotalPrice)
+
+		assert.Equal(t, models.HistoryActionDelete, history[2].Action)
+		assert.Equal(t, 500.0, history[2].Before.TotalPrice)
+		assert.Nil(t, history[2].After)
+
+		_, _, err = service.GetHistory(ctx, 222, expense.ID)
+		assertAppErrorType(t, err, errors.ErrorTypeUnauthorized)
+		undoAll()
+	})
+
+	t.Run("undo walks back changes most recent first", func(t *testing.T) {
+		expense := addExpense(t, 450)
+		editAmount(t, expense.ID, 500)
+		require.NoError(t, expenseService.DeleteExpense(ctx, expense.ID, 111))
+
+		undone, err := service.Undo(ctx, 111)
+		require.NoError(t, err)
+		assert.Equal(t, models.HistoryActionDelete, undone.Action)
+		restored, err := storage.GetExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 500.0, restored.TotalPrice)
+
+		undone, err = service.Undo(ctx, 111)
+		require.NoError(t, err)
+		assert.Equal(t, models.HistoryActionUpdate, undone.Action)
+		restored, err = storage.GetExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 450.0, restored.TotalPrice)
+
+		undone, err = service.Undo(ctx, 111)
+		require.NoError(t, err)
+		assert.Equal(t, models.HistoryActionCreate, undone.Action)
+		_, err = storage.GetExpenseByID(ctx, expense.ID)
+		assert.True(t, database.IsNotFound(err))
+
+		_, err = service.Undo(ctx, 111)
+		assertAppErrorType(t, err, errors.ErrorTypeNotFound)
+
+		// Undoing is itself recorded, pointing back at the change it reverted
+		_, history, err := service.GetHistory(ctx, 111, expense.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 6)
+		for i, entry := range history[:3] {
+			assert.NotNil(t, entry.UndoneAt)
+			assert.Equal(t, entry.ID, history[5-i].UndoOf.Int64)
+		}
+	})
+
+	t.Run("a change is undone only once", func(t *testing.T) {
+		expense := addExpense(t, 300)
+		change, err := storage.GetLastUndoableChange(ctx, expense.UserID)
+		require.NoError(t, err)
+
+		undoEntry := func(action models.HistoryAction) *models.ExpenseHistory {
+			entry := &models.ExpenseHistory{ExpenseID: expense.ID, UserID: expense.UserID, Action: action}
+			entry.UndoOf.Int64, entry.UndoOf.Valid = change.ID, true
+			return entry
+		}
+
+		// Another undo of the same change got in first; this one leaves the expense alone
+		require.NoError(t, storage.UpdateExpense(ctx, expense, undoEntry(models.HistoryActionUpdate)))
+		assert.True(t, database.IsNotFound(storage.DeleteExpense(ctx, expense.ID, expense.UserID, undoEntry(models.HistoryActionDelete))))
+		_, err = storage.GetExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+
+		_, history, err := service.GetHistory(ctx, 111, expense.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+		require.NoError(t, expenseService.DeleteExpense(ctx, expense.ID, 111))
+		undoAll()
+	})
+
+	t.Run("the trash restores deleted expenses", func(t *testing.T) {
+		expense := addExpense(t, 120)
+		require.NoError(t, expenseService.DeleteExpense(ctx, expense.ID, 111))
+
+		trash, err := service.GetTrash(ctx, 111)
+		require.NoError(t, err)
+		require.NotEmpty(t, trash)
+		assert.Equal(t, expense.ID, trash[0].ID)
+
+		_, err = service.RestoreExpense(ctx, 222, expense.ID)
+		assertAppErrorType(t, err, errors.ErrorTypeUnauthorized)
+
+		restored, err := service.RestoreExpense(ctx, 111, expense.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		_, err = storage.GetExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+
+		_, err = service.RestoreExpense(ctx, 111, expense.ID)
+		assertAppErrorType(t, err, errors.ErrorTypeNotFound)
+
+		// Undoing the restore puts it back in the trash
+		undone, err := service.Undo(ctx, 111)
+		require.NoError(t, err)
+		assert.Equal(t, models.HistoryActionRestore, undone.Action)
+		_, err = storage.GetDeletedExpenseByID(ctx, expense.ID)
+		require.NoError(t, err)
+		undoAll()
+	})
+
+	t.Run("imports are not undone", func(t *testing.T) {
+		user, err := storage.GetUserByTelegramID(ctx, 111)
+		require.NoError(t, err)
+		category, err := storage.GetCategoryByName(ctx, "Dining")
+		require.NoError(t, err)
+		imported := []*models.Expense{{UserID: user.ID, CategoryID: category.ID, TotalPrice: 75, Timestamp: time.Now()}}
+		_, err = NewImportService(storage, mockLogger, nil, NewCurrencyService(storage, mockLogger), NewCategoryService(storage, mockLogger, nil)).ImportExpenses(ctx, 111, imported)
+		require.NoError(t, err)
+
+		_, err = service.Undo(ctx, 111)
+		assertAppErrorType(t, err, errors.ErrorTypeNotFound)
+		_, err = storage.GetExpenseByID(ctx, imported[0].ID)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,341 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/importer"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/parser"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// importSimilarityThreshold is the minimum similarity of a past expense whose category is
+// suggested for a statement row that names no category
+const importSimilarityThreshold = 0.8
+
+// maxImportNotes is the longest statement description kept as expense notes
+const maxImportNotes = 500
+
+// fallbackCategoryName is suggested when nothing better matches
+const fallbackCategoryName = "Other"
+
+// ImportService provides bank statement import business logic
+type ImportService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	vectorService   VectorServiceInterface
+	currencyService *CurrencyService
+	categoryService *CategoryService
+}
+
+// NewImportService creates a new import service. Imported amounts are recorded in the home
+// currency from currencyService, and categories are suggested from those of categoryService.
+// vectorService may be nil, in which case categories are only suggested from the words of each
+// description and imported expenses are not embedded.
+func NewImportService(db database.Storage, logger logger.Logger, vectorService VectorServiceInterface, currencyService *CurrencyService, categoryService *CategoryService) *ImportService {
+	return &ImportService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		vectorService:   vectorService,
+		currencyService: currencyService,
+		categoryService: categoryService,
+	}
+}
+
+// ReadColumns returns the header of a statement so its columns can be mapped
+func (s *ImportService) ReadColumns(data []byte) ([]string, error) {
+	file, err := importer.Read(data)
+	if err != nil {
+		return nil, errors.NewValidationError("Invalid statement", err.Error())
+	}
+	return file.Header, nil
+}
+
+// SaveProfile maps a statement's zero-based columns to date, amount and description, detects its
+// date format and saves the mapping under the profile name for later imports
+func (s *ImportService) SaveProfile(ctx context.Context, telegramID int64, name string, data []byte, dateColumn, amountColumn, descriptionColumn int) (*models.ImportProfile, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := importer.Read(data)
+	if err != nil {
+		return nil, errors.NewValidationError("Invalid statement", err.Error())
+	}
+
+	profile, err := importer.NewProfile(file, name, dateColumn, amountColumn, descriptionColumn)
+	if err != nil {
+		return nil, errors.NewValidationError("Invalid column mapping", err.Error())
+	}
+	profile.UserID = user.ID
+
+	if err := s.db.UpsertImportProfile(ctx, profile); err != nil {
+		s.logger.Error(ctx, "Failed to save import profile", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to save import profile", err)
+	}
+
+	s.logger.Info(ctx, "Import profile saved successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.String("profile", name))
+
+	return profile, nil
+}
+
+// PreviewImport reads a statement with the named profile and returns the expenses it would create,
+// with suggested categories and without rows already recorded. Nothing is saved. A NotFound error
+// means the profile does not exist yet and the columns must be mapped with SaveProfile first.
+func (s *ImportService) PreviewImport(ctx context.Context, telegramID int64, name string, data []byte) (*models.ImportPreview, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.db.GetImportProfile(ctx, user.ID, name)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("Import profile not found", fmt.Sprintf("Import profile %q not found", name))
+		}
+		s.logger.Error(ctx, "Failed to get import profile", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get import profile", err)
+	}
+
+	file, err := importer.Read(data, profile.DateColumn, profile.AmountColumn, profile.DescriptionColumn)
+	if err != nil {
+		return nil, errors.NewValidationError("Invalid statement", err.Error())
+	}
+	transactions, skipped, err := file.Transactions(profile)
+	if err != nil {
+		return nil, errors.NewValidationError("Invalid statement", err.Error())
+	}
+
+	preview := &models.ImportPreview{Profile: profile, Skipped: skipped}
+	if len(transactions) == 0 {
+		return preview, nil
+	}
+
+	transactions, preview.Duplicates, err = s.removeDuplicates(ctx, user.ID, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := s.currencyService.HomeCurrency(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggest, err := s.categorySuggester(ctx, telegramID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		notes := transaction.Description
+		if len(notes) > maxImportNotes {
+			notes = strings.ToValidUTF8(notes[:maxImportNotes], "")
+		}
+
+		category := suggest(notes)
+		preview.Expenses = append(preview.Expenses, &models.Expense{
+			UserID:         user.ID,
+			CategoryID:     category.ID,
+			CategoryName:   category.Name,
+			CategoryEmoji:  category.Emoji,
+			CategoryGroup:  category.Group,
+			TotalPrice:     transaction.Amount,
+			OriginalAmount: transaction.Amount,
+			Currency:       currency,
+			Notes:          notes,
+			Timestamp:      transaction.Date,
+		})
+	}
+
+	return preview, nil
+}
+
+// ImportExpenses saves previewed expenses in a single transaction and returns how many were saved
+func (s *ImportService) ImportExpenses(ctx context.Context, telegramID int64, expenses []*models.Expense) (int, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return 0, err
+	}
+
+	if len(expenses) == 0 {
+		return 0, errors.NewValidationError("Nothing to import", "The statement has no new expenses")
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, expense := range expenses {
+		if expense.UserID != user.ID {
+			return 0, errors.NewUnauthorizedError("You can only import your own expenses")
+		}
+		if err := s.validator.ValidateAmount(expense.TotalPrice, "amount"); err != nil {
+			return 0, err
+		}
+	}
+
+	history := make([]*models.ExpenseHistory, len(expenses))
+	for i, expense := range expenses {
+		history[i] = &models.ExpenseHistory{
+			UserID: user.ID,
+			Action: models.HistoryActionImport,
+			After:  models.NewExpenseSnapshot(expense),
+		}
+	}
+	if err := s.db.CreateExpenses(ctx, expenses, history...); err != nil {
+		s.logger.Error(ctx, "Failed to import expenses", logger.ErrorField(err))
+		return 0, errors.NewDatabaseError("Failed to import expenses", err)
+	}
+
+	s.logger.Info(ctx, "Expenses imported successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("count", len(expenses)))
+
+	// Queue embeddings so imported expenses show up in /search
+	if s.vectorService != nil {
+		ids := make([]int64, len(expenses))
+		for i, expense := range expenses {
+			ids[i] = expense.ID
+		}
+		if err := s.vectorService.EnqueueEmbeddings(ctx, ids...); err != nil {
+			s.logger.Error(ctx, "Failed to queue embeddings for imported expenses", logger.ErrorField(err))
+		}
+	}
+
+	return len(expenses), nil
+}
+
+// removeDuplicates drops transactions already recorded with the same day, amount and notes.
+// Each existing expense matches at most one row, so two identical coffees on a statement stay
+// two expenses when only one of them was logged by hand.
+func (s *ImportService) removeDuplicates(ctx context.Context, userID int64, transactions []importer.Transaction) ([]importer.Transaction, int, error) {
+	start, end := transactions[0].Date, transactions[0].Date
+	for _, transaction := range transactions[1:] {
+		if transaction.Date.Before(start) {
+			start = transaction.Date
+		}
+		if transaction.Date.After(end) {
+			end = transaction.Date
+		}
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	end = time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, end.Location())
+
+	existing := make(map[string]int)
+	err := s.db.StreamExpenses(ctx, userID, start, end, func(expense *models.Expense) error {
+		existing[duplicateKey(expense.Timestamp, expense.TotalPrice, expense.Notes)]++
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get existing expenses", logger.ErrorField(err))
+		return nil, 0, errors.NewDatabaseError("Failed to check for duplicates", err)
+	}
+
+	unique := transactions[:0]
+	duplicates := 0
+	for _, transaction := range transactions {
+		key := duplicateKey(transaction.Date, transaction.Amount, transaction.Description)
+		if existing[key] > 0 {
+			existing[key]--
+			duplicates++
+			continue
+		}
+		unique = append(unique, transaction)
+	}
+	return unique, duplicates, nil
+}
+
+// duplicateKey identifies an expense by local day, amount in cents and normalized notes
+func duplicateKey(date time.Time, amount float64, notes string) string {
+	return fmt.Sprintf("%s|%.2f|%s", date.In(time.Local).Format("2006-01-02"), amount,
+		strings.ToLower(strings.Join(strings.Fields(notes), " ")))
+}
+
+// categorySuggester returns a function that suggests a category for a description: a category
+// named in the description, else the category of the most similar past expense, else Other
+func (s *ImportService) categorySuggester(ctx context.Context, telegramID, userID int64) (func(string) *models.Category, error) {
+	categories, err := s.categoryService.GetUserCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, errors.NewNotFoundError("No categories", "No categories are available for imported expenses")
+	}
+
+	fallback := categories[0]
+	for _, category := range categories {
+		if strings.EqualFold(category.Name, fallbackCategoryName) {
+			fallback = category
+			break
+		}
+	}
+
+	p := parser.New(categories)
+	cache := make(map[string]*models.Category)
+
+	return func(description string) *models.Category {
+		key := strings.ToLower(description)
+		if category, ok := cache[key]; ok {
+			return category
+		}
+
+		category := p.MatchCategory(description)
+		if category == nil && description != "" && s.vectorService != nil {
+			similar, err := s.vectorService.SearchExpensesByQuery(ctx, telegramID, description, importSimilarityThreshold, 1)
+			if err != nil {
+				s.logger.Warn(ctx, "Failed to find similar expenses for import", logger.ErrorField(err))
+			} else if len(similar) > 0 {
+				category = &models.Category{
+					ID:    similar[0].CategoryID,
+					Name:  similar[0].CategoryName,
+					Emoji: similar[0].CategoryEmoji,
+					Group: similar[0].CategoryGroup,
+				}
+			}
+		}
+		if category == nil {
+			category = fallback
+		}
+
+		cache[key] = category
+		return category
+	}, nil
+}
+
+// getUser retrieves a user by Telegram ID
+func (s *ImportService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	return user, nil
+}
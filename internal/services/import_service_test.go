@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testBankStatement = "Date,Description,Debit\n" +
+	"2026-03-01,SWIGGY DINING,450.00\n" +
+	"2026-03-02,AMAZON PAY,999.00\n" +
+	"2026-03-02,NEFT XYZ,100.00\n" +
+	"2026-03-05,Petrol pump,\"2,000.00\"\n" +
+	"2026-03-05,Refund,\n"
+
+func testImportProfile() *models.ImportProfile {
+	return &models.ImportProfile{
+		ID: 7, UserID: 1, Name: "hdfc",
+		DateColumn: "Date", AmountColumn: "Debit", DescriptionColumn: "Description", DateFormat: "2006-1-2",
+	}
+}
+
+func newTestImportService(mockDB *MockStorage, vectorService VectorServiceInterface) *ImportService {
+	log := logger.NewMockLogger()
+	return NewImportService(mockDB, log, vectorService, NewCurrencyService(mockDB, log), NewCategoryService(mockDB, log, nil))
+}
+
+func TestImportService_SaveProfile(t *testing.T) {
+	mockDB := new(MockStorage)
+	mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+	mockDB.On("UpsertImportProfile", mock.Anything, mock.MatchedBy(func(profile *models.ImportProfile) bool {
+		return profile.UserID == 1 && profile.Name == "hdfc" && profile.DateColumn == "Date" &&
+			profile.AmountColumn == "Debit" && profile.DescriptionColumn == "Description" && profile.DateFormat == "2006-1-2"
+	})).Return(nil)
+
+	service := newTestImportService(mockDB, nil)
+	profile, err := service.SaveProfile(context.Background(), 12345, "hdfc", []byte(testBankStatement), 0, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Debit", profile.AmountColumn)
+
+	_, err = service.SaveProfile(context.Background(), 12345, "hdfc", []byte(testBankStatement), 1, 2, 0)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeValidation, err.(*errors.AppError).Type)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestImportService_PreviewImport(t *testing.T) {
+	categories := []*models.Category{
+		{ID: 1, Name: "Petrol", Emoji: "⛽", Group: "Vehicle"},
+		{ID: 2, Name: "Dining", Emoji: "🍽️", Group: "Daily Living"},
+		{ID: 3, Name: "Shopping", Emoji: "🛍️", Group: "Shopping"},
+		{ID: 4, Name: "Other", Emoji: "📌", Group: "Other"},
+	}
+	existing := []*models.Expense{
+		{UserID: 1, TotalPrice: 2000, Notes: "petrol  PUMP", Timestamp: time.Date(2026, 3, 5, 18, 0, 0, 0, time.Local)},
+	}
+
+	mockDB := new(MockStorage)
+	mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+	mockDB.On("GetImportProfile", mock.Anything, int64(1), "hdfc").Return(testImportProfile(), nil)
+	mockDB.On("StreamExpenses", mock.Anything, int64(1),
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 6, 0, 0, 0, 0, time.Local), mock.Anything).
+		Return(existing, nil)
+	mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+	mockDB.On("GetAllCategories", mock.Anything).Return(categories, nil)
+	mockDB.On("GetCategoriesByUserID", mock.Anything, int64(1)).Return(nil, nil)
+
+	mockVector := new(MockVectorService)
+	mockVector.On("SearchExpensesByQuery", mock.Anything, int64(12345), "AMAZON PAY", float32(importSimilarityThreshold), 1).
+		Return([]*models.Expense{{CategoryID: 3, CategoryName: "Shopping", CategoryEmoji: "🛍️", CategoryGroup: "Shopping"}}, nil)
+	mockVector.On("SearchExpensesByQuery", mock.Anything, int64(12345), "NEFT XYZ", float32(importSimilarityThreshold), 1).
+		Return([]*models.Expense{}, nil)
+
+	service := newTestImportService(mockDB, mockVector)
+	preview, err := service.PreviewImport(context.Background(), 12345, "hdfc", []byte(testBankStatement))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, preview.Duplicates, "petrol pump was already logged")
+	assert.Equal(t, 1, preview.Skipped, "refund has no debit")
+	require.Len(t, preview.Expenses, 3)
+
+	assert.Equal(t, "Dining", preview.Expenses[0].CategoryName, "named in the description")
+	assert.Equal(t, "Shopping", preview.Expenses[1].CategoryName, "similar past expense")
+	assert.Equal(t, "Other", preview.Expenses[2].CategoryName, "fallback")
+
+	first := preview.Expenses[0]
+	assert.Equal(t, int64(1), first.UserID)
+	assert.Equal(t, 450.0, first.TotalPrice)
+	assert.Equal(t, 450.0, first.OriginalAmount)
+	assert.Equal(t, "INR", first.Currency)
+	assert.Equal(t, "SWIGGY DINING", first.Notes)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), first.Timestamp)
+
+	mockDB.AssertExpectations(t)
+	mockVector.AssertExpectations(t)
+}
+
+func TestImportService_PreviewImportWithoutProfile(t *testing.T) {
+	mockDB := new(MockStorage)
+	mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+	mockDB.On("GetImportProfile", mock.Anything, int64(1), "default").Return(nil, sql.ErrNoRows)
+
+	service := newTestImportService(mockDB, nil)
+	_, err := service.PreviewImport(context.Background(), 12345, "default", []byte(testBankStatement))
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeNotFound, err.(*errors.AppError).Type)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestImportService_ImportExpenses(t *testing.T) {
+	tests := []struct {
+		name        string
+		expenses    []*models.Expense
+		setupMock   func(*MockStorage)
+		expectError bool
+		errorType   errors.ErrorType
+	}{
+		{
+			name: "saves all expenses at once",
+			expenses: []*models.Expense{
+				{UserID: 1, CategoryID: 2, TotalPrice: 450},
+				{UserID: 1, CategoryID: 4, TotalPrice: 100},
+			},
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("CreateExpenses", mock.Anything, mock.AnythingOfType("[]*models.Expense"), mock.MatchedBy(func(entries []*models.ExpenseHistory) bool {
+					return len(entries) == 2 && entries[0].Action == models.HistoryActionImport && entries[1].After.TotalPrice == 100
+				})).Return(nil)
+			},
+		},
+		{
+			name:        "nothing to import",
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+		{
+			name:     "expense of another user",
+			expenses: []*models.Expense{{UserID: 2, CategoryID: 2, TotalPrice: 450}},
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+			},
+			expectError: true,
+			errorType:   errors.ErrorTypeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockStorage)
+			tt.setupMock(mockDB)
+			service := newTestImportService(mockDB, nil)
+
+			count, err := service.ImportExpenses(context.Background(), 12345, tt.expenses)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Equal(t, tt.errorType, err.(*errors.AppError).Type)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, len(tt.expenses), count)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
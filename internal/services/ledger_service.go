@@ -0,0 +1,683 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// inviteCodeAlphabet leaves out characters that are easily confused, like 0 and O or 1 and I
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// inviteCodeLength is the length of ledger invite codes
+const inviteCodeLength = 8
+
+// LedgerService manages shared ledgers: expenses several users record together, each attributed
+// to the member who paid. Owners manage members, editors add and change expenses, and viewers
+// only see the combined report.
+type LedgerService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	currencyService *CurrencyService
+}
+
+// NewLedgerService creates a new ledger service that reports totals converted by currencyService
+func NewLedgerService(db database.Storage, logger logger.Logger, currencyService *CurrencyService) *LedgerService {
+	return &LedgerService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		currencyService: currencyService,
+	}
+}
+
+// CreateLedger creates a ledger owned by the user, with a new invite code
+func (s *LedgerService) CreateLedger(ctx context.Context, telegramID int64, name string) (*models.Ledger, error) {
+	name = strings.TrimSpace(name)
+	if err := s.validator.ValidateLedgerName(name); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledgers, err := s.getLedgers(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ledger := range ledgers {
+		if strings.EqualFold(ledger.Name, name) {
+			return nil, errors.NewValidationError("Ledger already exists", fmt.Sprintf("You are already in a ledger named %s", ledger.Name))
+		}
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to create invite code", err)
+	}
+
+	ledger := &models.Ledger{Name: name, InviteCode: code}
+	if err := s.db.CreateLedger(ctx, ledger, user.ID); err != nil {
+		s.logger.Error(ctx, "Failed to create ledger", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to create ledger", err)
+	}
+
+	s.logger.Info(ctx, "Ledger created",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("ledger_id", int(ledger.ID)))
+
+	return ledger, nil
+}
+
+// JoinLedger adds the user to the ledger with the invite code as an editor
+func (s *LedgerService) JoinLedger(ctx context.Context, telegramID int64, inviteCode string) (*models.Ledger, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+	ledger, err := s.db.GetLedgerByInviteCode(ctx, inviteCode)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("Invite code not found", fmt.Sprintf("No ledger has the invite code %s", inviteCode))
+		}
+		s.logger.Error(ctx, "Failed to get ledger by invite code", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledger", err)
+	}
+
+	existing, err := s.getMember(ctx, ledger.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.NewValidationError("Already a member", fmt.Sprintf("You are already a member of %s", ledger.Name))
+	}
+
+	member := &models.LedgerMember{LedgerID: ledger.ID, UserID: user.ID, Role: models.LedgerRoleEditor}
+	if err := s.db.AddLedgerMember(ctx, member); err != nil {
+		s.logger.Error(ctx, "Failed to add ledger member", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to join ledger", err)
+	}
+
+	s.logger.Info(ctx, "User joined ledger",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("ledger_id", int(ledger.ID)))
+
+	ledger.Role = member.Role
+	return ledger, nil
+}
+
+// GetLedgers returns the ledgers the user is a member of, with their role in each. Users who
+// have not used the bot yet have none.
+func (s *LedgerService) GetLedgers(ctx context.Context, telegramID int64) ([]*models.Ledger, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+	return s.getLedgers(ctx, user.ID)
+}
+
+// GetActiveLedger returns the ledger the user's new expenses go to, or nil when they are personal
+func (s *LedgerService) GetActiveLedger(ctx context.Context, telegramID int64) (*models.Ledger, error) {
+	ledgers, err := s.GetLedgers(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ledger := range ledgers {
+		if ledger.Active {
+			return ledger, nil
+		}
+	}
+	return nil, nil
+}
+
+// UseLedger makes the named ledger the one the user's new expenses go to. An empty name or
+// "personal" makes them personal again and returns nil.
+func (s *LedgerService) UseLedger(ctx context.Context, telegramID int64, name string) (*models.Ledger, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ledger *models.Ledger
+	if name = strings.TrimSpace(name); name != "" && !strings.EqualFold(name, "personal") {
+		ledgers, err := s.getLedgers(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ledger, err = findLedger(ledgers, name); err != nil {
+			return nil, err
+		}
+		if !ledger.Role.CanEdit() {
+			return nil, errors.NewUnauthorizedError(fmt.Sprintf("You are a viewer in %s and cannot add expenses to it", ledger.Name))
+		}
+	}
+
+	var ledgerID int64
+	if ledger != nil {
+		ledgerID = ledger.ID
+	}
+	if err := s.db.SetActiveLedger(ctx, user.ID, ledgerID); err != nil {
+		s.logger.Error(ctx, "Failed to set active ledger", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to switch ledger", err)
+	}
+
+	if ledger != nil {
+		ledger.Active = true
+	}
+	return ledger, nil
+}
+
+// GetMembers returns the named ledger and its members. An empty name means the active ledger,
+// or the user's only one.
+func (s *LedgerService) GetMembers(ctx context.Context, telegramID int64, name string) (*models.Ledger, []*models.LedgerMember, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ledger, err := s.getUserLedger(ctx, user.ID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+
+	// Only owners can hand out the invite code
+	if ledger.Role != models.LedgerRoleOwner {
+		ledger.InviteCode = ""
+	}
+	return ledger, members, nil
+}
+
+// SetMemberRole changes the role of a member of the named ledger, found by @username or first
+// name. Only owners can change roles, and a ledger always keeps at least one owner.
+func (s *LedgerService) SetMemberRole(ctx context.Context, telegramID int64, ledgerName, memberName string, role models.LedgerRole) (*models.LedgerMember, error) {
+	role = models.LedgerRole(strings.ToUpper(string(role)))
+	if err := s.validator.ValidateLedgerRole(string(role)); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.getUserLedger(ctx, user.ID, ledgerName)
+	if err != nil {
+		return nil, err
+	}
+	if ledger.Role != models.LedgerRoleOwner {
+		return nil, errors.NewUnauthorizedError(fmt.Sprintf("Only owners of %s can change roles", ledger.Name))
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+
+	member := findLedgerMember(members, memberName)
+	if member == nil {
+		return nil, errors.NewNotFoundError("Member not found", fmt.Sprintf("%s has no member named %s", ledger.Name, memberName))
+	}
+	if member.Role == models.LedgerRoleOwner && role != models.LedgerRoleOwner && countOwners(members) == 1 {
+		return nil, errors.NewValidationError("Last owner", fmt.Sprintf("%s needs at least one owner; make someone else an owner first", ledger.Name))
+	}
+
+	if err := s.db.UpdateLedgerMemberRole(ctx, ledger.ID, member.UserID, role); err != nil {
+		s.logger.Error(ctx, "Failed to update ledger member role", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to change role", err)
+	}
+
+	// A viewer cannot keep adding expenses to the ledger
+	if !role.CanEdit() && member.Active {
+		if err := s.db.SetActiveLedger(ctx, member.UserID, 0); err != nil {
+			s.logger.Error(ctx, "Failed to clear active ledger", logger.ErrorField(err))
+		}
+		member.Active = false
+	}
+
+	member.Role = role
+	return member, nil
+}
+
+// LeaveLedger removes the user from the named ledger. Their expenses stay in the ledger. The last
+// member to leave deletes the ledger, which makes its expenses personal again; deleted reports
+// whether that happened.
+func (s *LedgerService) LeaveLedger(ctx context.Context, telegramID int64, name string) (ledger *models.Ledger, deleted bool, err error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ledger, err = s.getUserLedger(ctx, user.ID, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, false, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+
+	if len(members) == 1 {
+		if err := s.db.DeleteLedger(ctx, ledger.ID); err != nil {
+			s.logger.Error(ctx, "Failed to delete ledger", logger.ErrorField(err))
+			return nil, false, errors.NewDatabaseError("Failed to delete ledger", err)
+		}
+		return ledger, true, nil
+	}
+
+	if ledger.Role == models.LedgerRoleOwner && countOwners(members) == 1 {
+		return nil, false, errors.NewValidationError("Last owner", fmt.Sprintf("You are the only owner of %s; make someone else an owner before leaving", ledger.Name))
+	}
+
+	if err := s.db.RemoveLedgerMember(ctx, ledger.ID, user.ID); err != nil {
+		s.logger.Error(ctx, "Failed to remove ledger member", logger.ErrorField(err))
+		return nil, false, errors.NewDatabaseError("Failed to leave ledger", err)
+	}
+	return ledger, false, nil
+}
+
+// GetChatLedger returns the ledger linked to a group chat, or nil when it has none
+func (s *LedgerService) GetChatLedger(ctx context.Context, chatID int64) (*models.Ledger, error) {
+	ledger, err := s.db.GetLedgerByChatID(ctx, chatID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, nil
+		}
+		s.logger.Error(ctx, "Failed to get chat ledger", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get chat ledger", err)
+	}
+	return ledger, nil
+}
+
+// JoinChatLedger returns the ledger linked to a group chat with the user's role in it, adding the
+// user as an editor the first time they use it from the chat. It returns nil when the chat has
+// no ledger.
+func (s *LedgerService) JoinChatLedger(ctx context.Context, telegramID, chatID int64) (*models.Ledger, error) {
+	ledger, err := s.GetChatLedger(ctx, chatID)
+	if err != nil || ledger == nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.getMember(ctx, ledger.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		member = &models.LedgerMember{LedgerID: ledger.ID, UserID: user.ID, Role: models.LedgerRoleEditor}
+		if err := s.db.AddLedgerMember(ctx, member); err != nil {
+			s.logger.Error(ctx, "Failed to add ledger member", logger.ErrorField(err))
+			return nil, errors.NewDatabaseError("Failed to join ledger", err)
+		}
+
+		s.logger.Info(ctx, "User joined chat ledger",
+			logger.Int("user_id", int(user.ID)),
+			logger.Int("ledger_id", int(ledger.ID)))
+	}
+
+	ledger.Role = member.Role
+	ledger.Active = member.Active
+	return ledger, nil
+}
+
+// LinkChat links the named ledger to a group chat, so expenses logged in the chat go to it. Only
+// owners can link a ledger, and only owners of the chat's current ledger can replace it.
+func (s *LedgerService) LinkChat(ctx context.Context, telegramID, chatID int64, name string) (*models.Ledger, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.getUserLedger(ctx, user.ID, name)
+	if err != nil {
+		return nil, err
+	}
+	if ledger.Role != models.LedgerRoleOwner {
+		return nil, errors.NewUnauthorizedError(fmt.Sprintf("Only owners of %s can link it to a chat", ledger.Name))
+	}
+
+	current, err := s.GetChatLedger(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.ID != ledger.ID {
+		member, err := s.getMember(ctx, current.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil || member.Role != models.LedgerRoleOwner {
+			return nil, errors.NewUnauthorizedError(fmt.Sprintf("This chat uses %s; only its owners can replace it", current.Name))
+		}
+	}
+
+	if err := s.db.LinkLedgerChat(ctx, ledger.ID, chatID); err != nil {
+		s.logger.Error(ctx, "Failed to link chat ledger", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to link ledger", err)
+	}
+
+	ledger.ChatID.Int64, ledger.ChatID.Valid = chatID, true
+	return ledger, nil
+}
+
+// UnlinkChat unlinks a group chat's ledger, so expenses logged in the chat are no longer shared.
+// Only owners of the ledger can unlink it.
+func (s *LedgerService) UnlinkChat(ctx context.Context, telegramID, chatID int64) (*models.Ledger, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.GetChatLedger(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if ledger == nil {
+		return nil, errors.NewNotFoundError("No chat ledger", "This chat is not linked to a ledger")
+	}
+
+	member, err := s.getMember(ctx, ledger.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.Role != models.LedgerRoleOwner {
+		return nil, errors.NewUnauthorizedError(fmt.Sprintf("Only owners of %s can unlink it", ledger.Name))
+	}
+
+	if err := s.db.UnlinkLedgerChat(ctx, chatID); err != nil {
+		s.logger.Error(ctx, "Failed to unlink chat ledger", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to unlink ledger", err)
+	}
+
+	ledger.ChatID = sql.NullInt64{}
+	return ledger, nil
+}
+
+// GetLedgerReport returns the combined spending of the named ledger's members between start and
+// end, in the user's home currency. A zero start means the current month.
+func (s *LedgerService) GetLedgerReport(ctx context.Context, telegramID int64, name string, start, end time.Time) (*models.LedgerReport, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.getUserLedger(ctx, user.ID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if start.IsZero() {
+		now := time.Now()
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		end = start.AddDate(0, 1, 0)
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+
+	expenses, err := s.db.GetLedgerExpenses(ctx, ledger.ID, start, end)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger expenses", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledger expenses", err)
+	}
+
+	currency, err := s.currencyService.HomeCurrency(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.currencyService.ConvertExpenses(ctx, expenses, currency)
+
+	names := make(map[int64]string, len(members))
+	for _, member := range members {
+		names[member.UserID] = member.DisplayName()
+	}
+
+	report := &models.LedgerReport{Ledger: ledger, Start: start, End: end, Currency: currency, Count: len(expenses)}
+	byMember := make(map[string]*models.LedgerTotal)
+	byCategory := make(map[string]*models.LedgerTotal)
+	for _, expense := range expenses {
+		report.Total += expense.TotalPrice
+
+		payer, ok := names[expense.UserID]
+		if !ok {
+			payer = "Former member"
+		}
+		addLedgerTotal(byMember, payer, expense.TotalPrice)
+		addLedgerTotal(byCategory, strings.TrimSpace(expense.CategoryEmoji+" "+expense.CategoryName), expense.TotalPrice)
+	}
+	report.ByMember = sortedLedgerTotals(byMember)
+	report.ByCategory = sortedLedgerTotals(byCategory)
+
+	return report, nil
+}
+
+// CheckExpenseAccess returns an Unauthorized error unless the user may edit and delete the
+// expense: their own personal expenses, and any expense in a ledger where they are an owner or
+// editor.
+func (s *LedgerService) CheckExpenseAccess(ctx context.Context, telegramID int64, expense *models.Expense) error {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	return s.checkExpenseAccess(ctx, user.ID, expense)
+}
+
+// checkExpenseAccess is CheckExpenseAccess for a user ID
+func (s *LedgerService) checkExpenseAccess(ctx context.Context, userID int64, expense *models.Expense) error {
+	if !expense.LedgerID.Valid {
+		if expense.UserID != userID {
+			return errors.NewUnauthorizedError("You can only modify your own expenses")
+		}
+		return nil
+	}
+	return s.checkCanEdit(ctx, expense.LedgerID.Int64, userID)
+}
+
+// checkExpenseVisible returns an Unauthorized error unless the user may see the expense: their
+// own personal expenses, and any expense in a ledger they are a member of
+func (s *LedgerService) checkExpenseVisible(ctx context.Context, userID int64, expense *models.Expense) error {
+	if !expense.LedgerID.Valid {
+		if expense.UserID != userID {
+			return errors.NewUnauthorizedError("You can only see your own expenses")
+		}
+		return nil
+	}
+	member, err := s.getMember(ctx, expense.LedgerID.Int64, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return errors.NewUnauthorizedError("You are not a member of this expense's ledger")
+	}
+	return nil
+}
+
+// checkCanEdit returns an Unauthorized error unless the user is an owner or editor of the ledger
+func (s *LedgerService) checkCanEdit(ctx context.Context, ledgerID, userID int64) error {
+	member, err := s.getMember(ctx, ledgerID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return errors.NewUnauthorizedError("You are not a member of this expense's ledger")
+	}
+	if !member.Role.CanEdit() {
+		return errors.NewUnauthorizedError("Viewers cannot add or change expenses in a shared ledger")
+	}
+	return nil
+}
+
+// getMember returns the user's membership of a ledger, or nil if they are not a member
+func (s *LedgerService) getMember(ctx context.Context, ledgerID, userID int64) (*models.LedgerMember, error) {
+	member, err := s.db.GetLedgerMember(ctx, ledgerID, userID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, nil
+		}
+		s.logger.Error(ctx, "Failed to get ledger member", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledger member", err)
+	}
+	return member, nil
+}
+
+// getUserLedger returns the user's ledger with the given name. An empty name means the active
+// ledger, or the user's only one.
+func (s *LedgerService) getUserLedger(ctx context.Context, userID int64, name string) (*models.Ledger, error) {
+	ledgers, err := s.getLedgers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return findLedger(ledgers, name)
+}
+
+func (s *LedgerService) getLedgers(ctx context.Context, userID int64) ([]*models.Ledger, error) {
+	ledgers, err := s.db.GetLedgersByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledgers", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledgers", err)
+	}
+	return ledgers, nil
+}
+
+func (s *LedgerService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+	return user, nil
+}
+
+// findLedger returns the ledger with the given name, ignoring case. An empty name means the
+// active ledger, or the only one.
+func findLedger(ledgers []*models.Ledger, name string) (*models.Ledger, error) {
+	if len(ledgers) == 0 {
+		return nil, errors.NewNotFoundError("No ledgers", "You are not in any shared ledger yet")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		for _, ledger := range ledgers {
+			if ledger.Active {
+				return ledger, nil
+			}
+		}
+		if len(ledgers) == 1 {
+			return ledgers[0], nil
+		}
+		return nil, errors.NewValidationError("Ledger name required", "You are in several ledgers; say which one")
+	}
+
+	for _, ledger := range ledgers {
+		if strings.EqualFold(ledger.Name, name) {
+			return ledger, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("Ledger not found", fmt.Sprintf("You are not in a ledger named %s", name))
+}
+
+// findLedgerMember returns the member with the given @username or first name, ignoring case, or nil
+func findLedgerMember(members []*models.LedgerMember, name string) *models.LedgerMember {
+	name = strings.TrimSpace(name)
+	if username, ok := strings.CutPrefix(name, "@"); ok {
+		for _, member := range members {
+			if strings.EqualFold(member.Username, username) {
+				return member
+			}
+		}
+		return nil
+	}
+	for _, member := range members {
+		if strings.EqualFold(member.FirstName, name) || strings.EqualFold(member.Username, name) {
+			return member
+		}
+	}
+	return nil
+}
+
+func countOwners(members []*models.LedgerMember) int {
+	owners := 0
+	for _, member := range members {
+		if member.Role == models.LedgerRoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+func addLedgerTotal(totals map[string]*models.LedgerTotal, name string, amount float64) {
+	total, ok := totals[name]
+	if !ok {
+		total = &models.LedgerTotal{Name: name}
+		totals[name] = total
+	}
+	total.Amount += amount
+	total.Count++
+}
+
+// sortedLedgerTotals returns the totals from largest to smallest amount
+func sortedLedgerTotals(totals map[string]*models.LedgerTotal) []models.LedgerTotal {
+	result := make([]models.LedgerTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Amount == result[j].Amount {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Amount > result[j].Amount
+	})
+	return result
+}
+
+// newInviteCode returns a random ledger invite code
+func newInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(buf), nil
+}
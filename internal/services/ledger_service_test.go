@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerService_Members(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	require.NoError(t, storage.CreateUser(ctx, &models.User{TelegramID: 111, FirstName: "Asha", Username: "asha"}))
+	require.NoError(t, storage.CreateUser(ctx, &models.User{TelegramID: 222, FirstName: "Ravi", Username: "ravi"}))
+
+	service := NewLedgerService(storage, logger.NewMockLogger(), NewCurrencyService(storage, logger.NewMockLogger()))
+	errorType := func(err error) errors.ErrorType {
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok, "expected an AppError, got %v", err)
+		return appErr.Type
+	}
+
+	ledger, err := service.CreateLedger(ctx, 111, "Home")
+	require.NoError(t, err)
+	assert.Len(t, ledger.InviteCode, inviteCodeLength)
+	assert.Equal(t, models.LedgerRoleOwner, ledger.Role)
+	_, err = service.CreateLedger(ctx, 111, "home")
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+
+	_, err = service.JoinLedger(ctx, 222, "NOPE2345")
+	assert.Equal(t, errors.ErrorTypeNotFound, errorType(err))
+	joined, err := service.JoinLedger(ctx, 222, " "+ledger.InviteCode+" ")
+	require.NoError(t, err)
+	assert.Equal(t, models.LedgerRoleEditor, joined.Role)
+	_, err = service.JoinLedger(ctx, 222, ledger.InviteCode)
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+
+	// Only owners see the invite code and change roles
+	_, members, err := service.GetMembers(ctx, 111, "")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "Ravi", members[1].DisplayName())
+	shown, _, err := service.GetMembers(ctx, 222, "home")
+	require.NoError(t, err)
+	assert.Empty(t, shown.InviteCode)
+	_, err = service.SetMemberRole(ctx, 222, "", "@asha", models.LedgerRoleViewer)
+	assert.Equal(t, errors.ErrorTypeUnauthorized, errorType(err))
+	_, err = service.SetMemberRole(ctx, 111, "", "asha", models.LedgerRoleEditor)
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+
+	// Viewers cannot add expenses, so they lose their active ledger
+	_, err = service.UseLedger(ctx, 222, "Home")
+	require.NoError(t, err)
+	member, err := service.SetMemberRole(ctx, 111, "Home", "@ravi", "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, models.LedgerRoleViewer, member.Role)
+	active, err := service.GetActiveLedger(ctx, 222)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+	_, err = service.UseLedger(ctx, 222, "Home")
+	assert.Equal(t, errors.ErrorTypeUnauthorized, errorType(err))
+
+	// The only owner cannot leave while others remain; the last member deletes the ledger
+	_, _, err = service.LeaveLedger(ctx, 111, "Home")
+	assert.Equal(t, errors.ErrorTypeValidation, errorType(err))
+	_, deleted, err := service.LeaveLedger(ctx, 222, "Home")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	_, deleted, err = service.LeaveLedger(ctx, 111, "Home")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	ledgers, err := service.GetLedgers(ctx, 111)
+	require.NoError(t, err)
+	assert.Empty(t, ledgers)
+}
+
+func TestLedgerService_ExpensesAndReport(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	mockStorage := storage.(*database.MockStorage)
+	mockStorage.AddMockCategory(&models.Category{Name: "Groceries", Emoji: "🛒", Group: "Daily Living"})
+	mockStorage.AddMockCategory(&models.Category{Name: "Dining", Emoji: "🍽️", Group: "Daily Living"})
+	mockStorage.AddMockCategory(&models.Category{Name: "Petrol", Emoji: "⛽", Group: "Vehicle"})
+	asha := &models.User{TelegramID: 111, FirstName: "Asha"}
+	ravi := &models.User{TelegramID: 222, FirstName: "Ravi"}
+	guest := &models.User{TelegramID: 333, FirstName: "Guest"}
+	for _, user := range []*models.User{asha, ravi, guest} {
+		require.NoError(t, storage.CreateUser(ctx, user))
+	}
+
+	mockLogger := logger.NewMockLogger()
+	currencyService := NewCurrencyService(storage, mockLogger)
+	service := NewLedgerService(storage, mockLogger, currencyService)
+	expenseService := newTestExpenseService(storage, mockLogger, nil)
+
+	ledger, err := service.CreateLedger(ctx, 111, "Home")
+	require.NoError(t, err)
+	_, err = service.JoinLedger(ctx, 222, ledger.InviteCode)
+	require.NoError(t, err)
+	_, err = service.JoinLedger(ctx, 333, ledger.InviteCode)
+	require.NoError(t, err)
+	_, err = service.SetMemberRole(ctx, 111, "Home", "Guest", models.LedgerRoleViewer)
+	require.NoError(t, err)
+
+	shared := func(amount float64, category string) *models.Expense {
+		expense := &models.Expense{CategoryName: category, TotalPrice: amount, Timestamp: time.Now()}
+		expense.LedgerID.Int64, expense.LedgerID.Valid = ledger.ID, true
+		return expense
+	}
+	require.NoError(t, expenseService.CreateExpense(ctx, shared(1200, "Groceries"), 111))
+	require.NoError(t, expenseService.CreateExpense(ctx, shared(800, "Dining"), 222))
+	require.NoError(t, expenseService.CreateExpense(ctx, shared(300, "Groceries"), 222))
+	require.NoError(t, expenseService.CreateExpense(ctx, &models.Expense{CategoryName: "Dining", TotalPrice: 500, Timestamp: time.Now()}, 111))
+
+	err = expenseService.CreateExpense(ctx, shared(100, "Dining"), 333)
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorTypeUnauthorized, appErr.Type)
+
+	report, err := service.GetLedgerReport(ctx, 333, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Count)
+	assert.Equal(t, 2300.0, report.Total)
+	assert.Equal(t, []models.LedgerTotal{
+		{Name: "Asha", Amount: 1200, Count: 1},
+		{Name: "Ravi", Amount: 1100, Count: 2},
+	}, report.ByMember)
+	require.Len(t, report.ByCategory, 2)
+	assert.Equal(t, models.LedgerTotal{Name: "🛒 Groceries", Amount: 1500, Count: 2}, report.ByCategory[0])
+
+	// Editors can change each other's shared expenses, viewers cannot, and personal expenses stay private
+	expenses, err := storage.GetLedgerExpenses(ctx, ledger.ID, report.Start, report.End)
+	require.NoError(t, err)
+	var ravisDinner *models.Expense
+	for _, expense := range expenses {
+		if expense.UserID == ravi.ID && expense.TotalPrice == 800 {
+			ravisDinner = expense
+		}
+	}
+	require.NotNil(t, ravisDinner)
+	assert.NoError(t, service.CheckExpenseAccess(ctx, 111, ravisDinner))
+	assert.Error(t, service.CheckExpenseAccess(ctx, 333, ravisDinner))
+	assert.Error(t, service.CheckExpenseAccess(ctx, 222, &models.Expense{UserID: asha.ID}))
+
+	ravisDinner.TotalPrice = 850
+	require.NoError(t, expenseService.UpdateExpense(ctx, ravisDinner, 111))
+	updated, err := storage.GetExpenseByID(ctx, ravisDinner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ravi.ID, updated.UserID)
+	assert.Error(t, expenseService.DeleteExpense(ctx, ravisDinner.ID, 333))
+	assert.NoError(t, expenseService.DeleteExpense(ctx, ravisDinner.ID, 111))
+
+	// An editor keeps the payer's vehicle on the expense, and can only pick one of their own
+	ashasCar := &models.Vehicle{UserID: asha.ID, Name: "Swift", Type: models.VehicleTypeCar, FuelType: models.FuelTypePetrol}
+	require.NoError(t, storage.CreateVehicle(ctx, ashasCar))
+	ravisBike := &models.Vehicle{UserID: ravi.ID, Name: "Pulsar", Type: models.VehicleTypeBike, FuelType: models.FuelTypePetrol}
+	require.NoError(t, storage.CreateVehicle(ctx, ravisBike))
+	ravisFuel := shared(400, "Petrol")
+	ravisFuel.VehicleID = sql.NullInt64{Int64: ravisBike.ID, Valid: true}
+	require.NoError(t, expenseService.CreateExpense(ctx, ravisFuel, 222))
+
+	ravisFuel.TotalPrice = 450
+	require.NoError(t, expenseService.UpdateExpense(ctx, ravisFuel, 111))
+	updated, err = storage.GetExpenseByID(ctx, ravisFuel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ravisBike.ID, updated.VehicleID.Int64)
+
+	ravisFuel.VehicleID = sql.NullInt64{Int64: ashasCar.ID, Valid: true}
+	require.NoError(t, expenseService.UpdateExpense(ctx, ravisFuel, 111))
+	updated, err = storage.GetExpenseByID(ctx, ravisFuel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ashasCar.ID, updated.VehicleID.Int64)
+	assert.Equal(t, string(models.VehicleTypeCar), updated.VehicleType.String)
+	assert.Equal(t, ravi.ID, updated.UserID)
+
+	ravisFuel.VehicleID = sql.NullInt64{Int64: ravisBike.ID, Valid: true}
+	err = expenseService.UpdateExpense(ctx, ravisFuel, 111)
+	appErr, ok = err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+}
+
+func TestLedgerService_ChatLedger(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	require.NoError(t, storage.CreateUser(ctx, &models.User{TelegramID: 111, FirstName: "Asha"}))
+	require.NoError(t, storage.CreateUser(ctx, &models.User{TelegramID: 222, FirstName: "Ravi"}))
+
+	service := NewLedgerService(storage, logger.NewMockLogger(), NewCurrencyService(storage, logger.NewMockLogger()))
+	const chatID = int64(-1001234567890)
+
+	// Chats without a ledger keep expenses personal
+	ledger, err := service.JoinChatLedger(ctx, 222, chatID)
+	require.NoError(t, err)
+	assert.Nil(t, ledger)
+
+	home, err := service.CreateLedger(ctx, 111, "Home")
+	require.NoError(t, err)
+	linked, err := service.LinkChat(ctx, 111, chatID, "Home")
+	require.NoError(t, err)
+	assert.Equal(t, chatID, linked.ChatID.Int64)
+
+	// Members of the chat join as editors the first time they use it
+	ledger, err = service.JoinChatLedger(ctx, 222, chatID)
+	require.NoError(t, err)
+	require.NotNil(t, ledger)
+	assert.Equal(t, home.ID, ledger.ID)
+	assert.Equal(t, models.LedgerRoleEditor, ledger.Role)
+	ledger, err = service.JoinChatLedger(ctx, 222, chatID)
+	require.NoError(t, err)
+	assert.Equal(t, models.LedgerRoleEditor, ledger.Role)
+
+	// Only owners link and unlink
+	_, err = service.CreateLedger(ctx, 222, "Trip")
+	require.NoError(t, err)
+	_, err = service.LinkChat(ctx, 222, chatID, "Trip")
+	assert.Error(t, err)
+	_, err = service.UnlinkChat(ctx, 222, chatID)
+	assert.Error(t, err)
+
+	unlinked, err := service.UnlinkChat(ctx, 111, chatID)
+	require.NoError(t, err)
+	assert.Equal(t, "Home", unlinked.Name)
+	ledger, err = service.GetChatLedger(ctx, chatID)
+	require.NoError(t, err)
+	assert.Nil(t, ledger)
+	_, err = service.UnlinkChat(ctx, 111, chatID)
+	assert.Error(t, err)
+}
+
+func TestFindLedger(t *testing.T) {
+	home := &models.Ledger{ID: 1, Name: "Home"}
+	trip := &models.Ledger{ID: 2, Name: "Goa Trip", Active: true}
+
+	found, err := findLedger([]*models.Ledger{home}, "")
+	require.NoError(t, err)
+	assert.Equal(t, home, found)
+
+	found, err = findLedger([]*models.Ledger{home, trip}, "")
+	require.NoError(t, err)
+	assert.Equal(t, trip, found)
+
+	found, err = findLedger([]*models.Ledger{home, trip}, "goa trip")
+	require.NoError(t, err)
+	assert.Equal(t, trip, found)
+
+	_, err = findLedger([]*models.Ledger{home, {ID: 3, Name: "Office"}}, "")
+	assert.Error(t, err)
+	_, err = findLedger(nil, "Home")
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+const (
+	// recurringBatchSize is the number of due recurring expenses processed per run
+	recurringBatchSize = 100
+	// maxRecurringCatchUp caps how many missed occurrences are created for one
+	// recurring expense in a single run, e.g. after the bot was down for a while
+	maxRecurringCatchUp = 31
+)
+
+// RecurringExpenseService provides recurring expense business logic
+type RecurringExpenseService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	expenseService  *ExpenseService
+	categoryService *CategoryService
+}
+
+// NewRecurringExpenseService creates a new recurring expense service that records occurrences
+// with expenseService and finds categories with categoryService
+func NewRecurringExpenseService(db database.Storage, logger logger.Logger, expenseService *ExpenseService, categoryService *CategoryService) *RecurringExpenseService {
+	return &RecurringExpenseService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		expenseService:  expenseService,
+		categoryService: categoryService,
+	}
+}
+
+// CreateRecurringExpense creates a recurring expense whose first occurrence is due at firstDue
+func (s *RecurringExpenseService) CreateRecurringExpense(ctx context.Context, telegramID int64, categoryName string, amount float64, interval models.RecurringInterval, firstDue time.Time, notes string) (*models.RecurringExpense, error) {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAmount(amount, "amount"); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateRecurringInterval(string(interval)); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateNotes(notes); err != nil {
+		return nil, err
+	}
+
+	if firstDue.IsZero() {
+		return nil, errors.NewValidationError("Invalid first due date", "First due date is required")
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryService.FindCategory(ctx, user.ID, categoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring := &models.RecurringExpense{
+		UserID:        user.ID,
+		CategoryID:    category.ID,
+		Amount:        amount,
+		Interval:      interval,
+		NextDue:       firstDue,
+		AnchorDay:     firstDue.Day(),
+		Notes:         notes,
+		CategoryName:  category.Name,
+		CategoryEmoji: category.Emoji,
+		TelegramID:    telegramID,
+	}
+
+	if err := s.db.CreateRecurringExpense(ctx, recurring); err != nil {
+		s.logger.Error(ctx, "Failed to create recurring expense", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to create recurring expense", err)
+	}
+
+	s.logger.Info(ctx, "Recurring expense created successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("recurring_id", int(recurring.ID)),
+		logger.String("interval", string(interval)),
+		logger.Float64("amount", amount))
+
+	return recurring, nil
+}
+
+// GetRecurringExpenses retrieves all recurring expenses for a user
+func (s *RecurringExpenseService) GetRecurringExpenses(ctx context.Context, telegramID int64) ([]*models.RecurringExpense, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring, err := s.db.GetRecurringExpensesByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get recurring expenses", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get recurring expenses", err)
+	}
+
+	return recurring, nil
+}
+
+// SetRecurringExpenseActive pauses or resumes a recurring expense owned by the user
+func (s *RecurringExpenseService) SetRecurringExpenseActive(ctx context.Context, telegramID, recurringID int64, active bool) error {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	recurring, err := s.getOwnedRecurringExpense(ctx, user, recurringID)
+	if err != nil {
+		return err
+	}
+
+	// Occurrences missed while paused are skipped rather than back-filled on resume
+	if active && !recurring.IsActive {
+		nextDue := skipMissedOccurrences(recurring, time.Now())
+		if !nextDue.Equal(recurring.NextDue) {
+			if _, err := s.db.AdvanceRecurringExpense(ctx, recurringID, recurring.NextDue, nextDue); err != nil {
+				s.logger.Error(ctx, "Failed to advance recurring expense", logger.ErrorField(err))
+				return errors.NewDatabaseError("Failed to update recurring expense", err)
+			}
+		}
+	}
+
+	if err := s.db.SetRecurringExpenseActive(ctx, recurringID, user.ID, active); err != nil {
+		s.logger.Error(ctx, "Failed to update recurring expense", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to update recurring expense", err)
+	}
+
+	s.logger.Info(ctx, "Recurring expense updated",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("recurring_id", int(recurringID)),
+		logger.Bool("active", active))
+
+	return nil
+}
+
+// DeleteRecurringExpense deletes a recurring expense owned by the user.
+// Expenses it has already created are kept.
+func (s *RecurringExpenseService) DeleteRecurringExpense(ctx context.Context, telegramID, recurringID int64) error {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getOwnedRecurringExpense(ctx, user, recurringID); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteRecurringExpense(ctx, recurringID, user.ID); err != nil {
+		s.logger.Error(ctx, "Failed to delete recurring expense", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to delete recurring expense", err)
+	}
+
+	s.logger.Info(ctx, "Recurring expense deleted",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("recurring_id", int(recurringID)))
+
+	return nil
+}
+
+// ProcessDueExpenses creates an expense for every occurrence due at or before now
+// and advances next_due past it. Each occurrence is claimed by moving next_due
+// before the expense is created, so concurrent runs never create it twice.
+// If the expense cannot be created the claim is released so a later run retries it.
+func (s *RecurringExpenseService) ProcessDueExpenses(ctx context.Context, now time.Time) ([]*models.RecurringOccurrence, error) {
+	due, err := s.db.GetDueRecurringExpenses(ctx, now, recurringBatchSize)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get due recurring expenses", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get due recurring expenses", err)
+	}
+
+	var occurrences []*models.RecurringOccurrence
+	for _, recurring := range due {
+		for i := 0; i < maxRecurringCatchUp && !recurring.NextDue.After(now); i++ {
+			dueAt := recurring.NextDue
+			nextDue := recurring.Interval.Next(dueAt, recurring.AnchorDay)
+
+			claimed, err := s.db.AdvanceRecurringExpense(ctx, recurring.ID, dueAt, nextDue)
+			if err != nil {
+				s.logger.Error(ctx, "Failed to advance recurring expense", logger.ErrorField(err),
+					logger.Int("recurring_id", int(recurring.ID)))
+				break
+			}
+			if !claimed {
+				break
+			}
+
+			expense := &models.Expense{
+				CategoryName: recurring.CategoryName,
+				TotalPrice:   recurring.Amount,
+				Notes:        recurring.Notes,
+				Timestamp:    dueAt,
+			}
+			if err := s.expenseService.CreateExpense(ctx, expense, recurring.TelegramID); err != nil {
+				s.logger.Error(ctx, "Failed to create expense from recurring expense", logger.ErrorField(err),
+					logger.Int("recurring_id", int(recurring.ID)))
+				if _, err := s.db.AdvanceRecurringExpense(ctx, recurring.ID, nextDue, dueAt); err != nil {
+					s.logger.Error(ctx, "Failed to release recurring expense", logger.ErrorField(err),
+						logger.Int("recurring_id", int(recurring.ID)))
+				}
+				break
+			}
+
+			recurring.NextDue = nextDue
+			occurrences = append(occurrences, &models.RecurringOccurrence{Recurring: recurring, DueAt: dueAt})
+		}
+	}
+
+	if len(occurrences) > 0 {
+		s.logger.Info(ctx, "Recurring expenses processed", logger.Int("created", len(occurrences)))
+	}
+
+	return occurrences, nil
+}
+
+// skipMissedOccurrences returns the first due date of a recurring expense that is not before the start of today
+func skipMissedOccurrences(recurring *models.RecurringExpense, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	nextDue := recurring.NextDue
+	for nextDue.Before(today) {
+		nextDue = recurring.Interval.Next(nextDue, recurring.AnchorDay)
+	}
+	return nextDue
+}
+
+// getUser retrieves a user by Telegram ID, returning a not found error if missing
+func (s *RecurringExpenseService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	return user, nil
+}
+
+// getOwnedRecurringExpense retrieves a recurring expense and checks that it belongs to the user
+func (s *RecurringExpenseService) getOwnedRecurringExpense(ctx context.Context, user *models.User, recurringID int64) (*models.RecurringExpense, error) {
+	recurring, err := s.db.GetRecurringExpenseByID(ctx, recurringID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get recurring expense by ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get recurring expense", err)
+	}
+
+	if recurring == nil {
+		return nil, errors.NewNotFoundError("Recurring expense not found", fmt.Sprintf("Recurring expense with ID %d not found", recurringID))
+	}
+
+	if recurring.UserID != user.ID {
+		return nil, errors.NewUnauthorizedError("You can only manage your own recurring expenses")
+	}
+
+	return recurring, nil
+}
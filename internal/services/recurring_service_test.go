@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRecurringService(mockDB *MockStorage) *RecurringExpenseService {
+	log := logger.NewMockLogger()
+	categoryService := NewCategoryService(mockDB, log, nil)
+	expenseService := newTestExpenseService(mockDB, log, NewVectorService(mockDB, log, embedding.NewLocalEmbedder()))
+	return NewRecurringExpenseService(mockDB, log, expenseService, categoryService)
+}
+
+func TestRecurringExpenseService_CreateRecurringExpense(t *testing.T) {
+	firstDue := time.Date(2026, 11, 5, 9, 0, 0, 0, time.UTC)
+	categories := []*models.Category{
+		{ID: 20, Name: "Home Loan EMI", Emoji: "🏠", Group: "Home"},
+	}
+
+	tests := []struct {
+		name        string
+		interval    models.RecurringInterval
+		amount      float64
+		category    string
+		setupMock   func(*MockStorage)
+		expectError bool
+		errorType   errors.ErrorType
+	}{
+		{
+			name:     "successful monthly EMI",
+			interval: models.RecurringMonthly,
+			amount:   25000,
+			category: "🏠 Home Loan EMI",
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("GetAllCategories", mock.Anything).Return(categories, nil)
+				mockDB.On("CreateRecurringExpense", mock.Anything, mock.MatchedBy(func(r *models.RecurringExpense) bool {
+					return r.UserID == 1 && r.CategoryID == 20 && r.Amount == 25000 && r.NextDue.Equal(firstDue)
+				})).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name:        "invalid interval",
+			interval:    "fortnightly",
+			amount:      25000,
+			category:    "Home Loan EMI",
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+		{
+			name:     "unknown category",
+			interval: models.RecurringMonthly,
+			amount:   25000,
+			category: "Boat Loan",
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("GetAllCategories", mock.Anything).Return(categories, nil)
+				mockDB.On("GetCategoriesByUserID", mock.Anything, int64(1)).Return(nil, nil)
+			},
+			expectError: true,
+			errorType:   errors.ErrorTypeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockStorage{}
+			tt.setupMock(mockDB)
+
+			service := newTestRecurringService(mockDB)
+			recurring, err := service.CreateRecurringExpense(context.Background(), 12345, tt.category, tt.amount, tt.interval, firstDue, "")
+
+			if tt.expectError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok)
+				assert.Equal(t, tt.errorType, appErr.Type)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "Home Loan EMI", recurring.CategoryName)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRecurringExpenseService_ProcessDueExpenses(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	aug31 := time.Date(2026, 8, 31, 9, 0, 0, 0, time.UTC)
+	sep30 := time.Date(2026, 9, 30, 9, 0, 0, 0, time.UTC)
+	oct31 := time.Date(2026, 10, 31, 9, 0, 0, 0, time.UTC)
+
+	newRecurring := func() *models.RecurringExpense {
+		return &models.RecurringExpense{
+			ID:           7,
+			UserID:       1,
+			CategoryID:   20,
+			Amount:       25000,
+			Interval:     models.RecurringMonthly,
+			NextDue:      aug31,
+			AnchorDay:    31,
+			IsActive:     true,
+			CategoryName: "Home Loan EMI",
+			TelegramID:   12345,
+		}
+	}
+
+	setupExpenseCreation := func(mockDB *MockStorage) {
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+		mockDB.On("GetCategoryByName", mock.Anything, "Home Loan EMI").Return(&models.Category{ID: 20, Name: "Home Loan EMI", Group: "Home"}, nil)
+		mockDB.On("GetUserSettings", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows)
+		mockDB.On("EnqueueEmbeddingJobs", mock.Anything, mock.AnythingOfType("[]int64")).Return(nil)
+	}
+
+	t.Run("catches up missed occurrences", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		setupExpenseCreation(mockDB)
+		mockDB.On("GetDueRecurringExpenses", mock.Anything, now, recurringBatchSize).Return([]*models.RecurringExpense{newRecurring()}, nil)
+		mockDB.On("AdvanceRecurringExpense", mock.Anything, int64(7), aug31, sep30).Return(true, nil).Once()
+		mockDB.On("AdvanceRecurringExpense", mock.Anything, int64(7), sep30, oct31).Return(true, nil).Once()
+		mockDB.On("CreateExpense", mock.Anything, mock.MatchedBy(func(e *models.Expense) bool {
+			return e.Timestamp.Equal(aug31) && e.TotalPrice == 25000
+		}), mock.Anything).Return(nil).Once()
+		mockDB.On("CreateExpense", mock.Anything, mock.MatchedBy(func(e *models.Expense) bool {
+			return e.Timestamp.Equal(sep30) && e.TotalPrice == 25000
+		}), mock.Anything).Return(nil).Once()
+
+		service := newTestRecurringService(mockDB)
+		occurrences, err := service.ProcessDueExpenses(context.Background(), now)
+
+		require.NoError(t, err)
+		require.Len(t, occurrences, 2)
+		assert.Equal(t, aug31, occurrences[0].DueAt)
+		assert.Equal(t, sep30, occurrences[1].DueAt)
+		assert.Equal(t, oct31, occurrences[1].Recurring.NextDue)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("skips occurrences claimed by another run", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetDueRecurringExpenses", mock.Anything, now, recurringBatchSize).Return([]*models.RecurringExpense{newRecurring()}, nil)
+		mockDB.On("AdvanceRecurringExpense", mock.Anything, int64(7), aug31, sep30).Return(false, nil)
+
+		service := newTestRecurringService(mockDB)
+		occurrences, err := service.ProcessDueExpenses(context.Background(), now)
+
+		require.NoError(t, err)
+		assert.Empty(t, occurrences)
+		mockDB.AssertNotCalled(t, "CreateExpense", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("releases the claim when the expense cannot be created", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+		mockDB.On("GetCategoryByName", mock.Anything, "Home Loan EMI").Return(&models.Category{ID: 20, Name: "Home Loan EMI", Group: "Home"}, nil)
+		mockDB.On("GetUserSettings", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows)
+		mockDB.On("GetDueRecurringExpenses", mock.Anything, now, recurringBatchSize).Return([]*models.RecurringExpense{newRecurring()}, nil)
+		mockDB.On("AdvanceRecurringExpense", mock.Anything, int64(7), aug31, sep30).Return(true, nil).Once()
+		mockDB.On("CreateExpense", mock.Anything, mock.AnythingOfType("*models.Expense"), mock.Anything).Return(sql.ErrConnDone)
+		mockDB.On("AdvanceRecurringExpense", mock.Anything, int64(7), sep30, aug31).Return(true, nil).Once()
+
+		service := newTestRecurringService(mockDB)
+		occurrences, err := service.ProcessDueExpenses(context.Background(), now)
+
+		require.NoError(t, err)
+		assert.Empty(t, occurrences)
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestRecurringExpenseService_SetRecurringExpenseActive(t *testing.T) {
+	t.Run("rejects recurring expenses owned by another user", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetRecurringExpenseByID", mock.Anything, int64(7)).Return(&models.RecurringExpense{ID: 7, UserID: 2}, nil)
+
+		service := newTestRecurringService(mockDB)
+		err := service.SetRecurringExpenseActive(context.Background(), 12345, 7, false)
+
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorTypeUnauthorized, appErr.Type)
+		mockDB.AssertNotCalled(t, "SetRecurringExpenseActive", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("pauses an owned recurring expense", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetRecurringExpenseByID", mock.Anything, int64(7)).Return(&models.RecurringExpense{ID: 7, UserID: 1, IsActive: true}, nil)
+		mockDB.On("SetRecurringExpenseActive", mock.Anything, int64(7), int64(1), false).Return(nil)
+
+		service := newTestRecurringService(mockDB)
+		require.NoError(t, service.SetRecurringExpenseActive(context.Background(), 12345, 7, false))
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestSkipMissedOccurrences(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	recurring := &models.RecurringExpense{
+		Interval:  models.RecurringMonthly,
+		NextDue:   time.Date(2026, 7, 5, 9, 0, 0, 0, time.UTC),
+		AnchorDay: 5,
+	}
+	assert.Equal(t, time.Date(2026, 11, 5, 9, 0, 0, 0, time.UTC), skipMissedOccurrences(recurring, now))
+
+	recurring.Interval = models.RecurringDaily
+	assert.Equal(t, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), skipMissedOccurrences(recurring, now))
+}
+
+func TestRecurringInterval_Next(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval models.RecurringInterval
+		from     time.Time
+		anchor   int
+		expected time.Time
+	}{
+		{"daily", models.RecurringDaily, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), 0, time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)},
+		{"weekly", models.RecurringWeekly, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), 0, time.Date(2026, 10, 23, 9, 0, 0, 0, time.UTC)},
+		{"monthly", models.RecurringMonthly, time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC), 5, time.Date(2026, 11, 5, 9, 0, 0, 0, time.UTC)},
+		{"monthly clamps to month end", models.RecurringMonthly, time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), 31, time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC)},
+		{"monthly returns to anchor day", models.RecurringMonthly, time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC), 31, time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{"monthly across year end", models.RecurringMonthly, time.Date(2026, 12, 15, 9, 0, 0, 0, time.UTC), 0, time.Date(2027, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{"yearly clamps leap day", models.RecurringYearly, time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC), 29, time.Date(2029, 2, 28, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.interval.Next(tt.from, tt.anchor))
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// reminderBatchSize is the number of due reminders processed per run
+const reminderBatchSize = 100
+
+// ReminderService provides reminder-related business logic
+type ReminderService struct {
+	db        database.Storage
+	logger    logger.Logger
+	validator *validation.Validator
+}
+
+// NewReminderService creates a new reminder service
+func NewReminderService(db database.Storage, logger logger.Logger) *ReminderService {
+	return &ReminderService{
+		db:        db,
+		logger:    logger,
+		validator: validation.NewValidator(),
+	}
+}
+
+// CreateReminder creates a reminder whose first run is the next matching time after now.
+// Reminder times are interpreted in the location of now, i.e. the bot's local time zone.
+func (s *ReminderService) CreateReminder(ctx context.Context, telegramID int64, reminder *models.Reminder, now time.Time) error {
+	// Validate input
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return err
+	}
+
+	if err := s.validateReminder(reminder); err != nil {
+		return err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	reminder.UserID = user.ID
+	reminder.TelegramID = telegramID
+	reminder.NextRun = reminder.NextRunAfter(now)
+
+	if err := s.db.CreateReminder(ctx, reminder); err != nil {
+		s.logger.Error(ctx, "Failed to create reminder", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to create reminder", err)
+	}
+
+	s.logger.Info(ctx, "Reminder created successfully",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("reminder_id", int(reminder.ID)),
+		logger.String("schedule", string(reminder.Schedule)))
+
+	return nil
+}
+
+// GetReminders retrieves all reminders for a user
+func (s *ReminderService) GetReminders(ctx context.Context, telegramID int64) ([]*models.Reminder, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := s.db.GetRemindersByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get reminders", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get reminders", err)
+	}
+
+	return reminders, nil
+}
+
+// UpdateReminder replaces the message and schedule of a reminder owned by the user
+func (s *ReminderService) UpdateReminder(ctx context.Context, telegramID, reminderID int64, updated *models.Reminder, now time.Time) (*models.Reminder, error) {
+	if err := s.validateReminder(updated); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder, err := s.getOwnedReminder(ctx, user, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder.Message = updated.Message
+	reminder.Schedule = updated.Schedule
+	reminder.DayOfWeek = updated.DayOfWeek
+	reminder.Hour = updated.Hour
+	reminder.Minute = updated.Minute
+	reminder.NextRun = reminder.NextRunAfter(now)
+	reminder.IsActive = true
+
+	if err := s.db.UpdateReminder(ctx, reminder); err != nil {
+		s.logger.Error(ctx, "Failed to update reminder", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to update reminder", err)
+	}
+
+	s.logger.Info(ctx, "Reminder updated",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("reminder_id", int(reminderID)))
+
+	return reminder, nil
+}
+
+// DeleteReminder deletes a reminder owned by the user
+func (s *ReminderService) DeleteReminder(ctx context.Context, telegramID, reminderID int64) error {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getOwnedReminder(ctx, user, reminderID); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteReminder(ctx, reminderID, user.ID); err != nil {
+		s.logger.Error(ctx, "Failed to delete reminder", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to delete reminder", err)
+	}
+
+	s.logger.Info(ctx, "Reminder deleted",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("reminder_id", int(reminderID)))
+
+	return nil
+}
+
+// ClaimDueReminders returns the reminders that should be sent now.
+// Each reminder is claimed by moving next_run past now before it is returned, so
+// a reminder is sent at most once per occurrence even if the bot restarts while
+// sending. Occurrences missed while the bot was down are collapsed into one send.
+func (s *ReminderService) ClaimDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error) {
+	due, err := s.db.GetDueReminders(ctx, now, reminderBatchSize)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get due reminders", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get due reminders", err)
+	}
+
+	claimed := make([]*models.Reminder, 0, len(due))
+	for _, reminder := range due {
+		nextRun := reminder.NextRunAfter(now)
+
+		ok, err := s.db.ClaimReminder(ctx, reminder.ID, reminder.NextRun, nextRun)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to claim reminder", logger.ErrorField(err),
+				logger.Int("reminder_id", int(reminder.ID)))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		reminder.NextRun = nextRun
+		claimed = append(claimed, reminder)
+	}
+
+	return claimed, nil
+}
+
+// validateReminder validates the user-editable fields of a reminder
+func (s *ReminderService) validateReminder(reminder *models.Reminder) error {
+	if err := s.validator.ValidateReminderMessage(reminder.Message); err != nil {
+		return err
+	}
+
+	dayOfWeek := -1
+	if reminder.DayOfWeek.Valid {
+		dayOfWeek = int(reminder.DayOfWeek.Int64)
+	}
+	if err := s.validator.ValidateReminderSchedule(string(reminder.Schedule), dayOfWeek); err != nil {
+		return err
+	}
+
+	return s.validator.ValidateTimeOfDay(reminder.Hour, reminder.Minute)
+}
+
+// getUser retrieves a user by Telegram ID, returning a not found error if missing
+func (s *ReminderService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	return user, nil
+}
+
+// getOwnedReminder retrieves a reminder and checks that it belongs to the user
+func (s *ReminderService) getOwnedReminder(ctx context.Context, user *models.User, reminderID int64) (*models.Reminder, error) {
+	reminder, err := s.db.GetReminderByID(ctx, reminderID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get reminder by ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get reminder", err)
+	}
+
+	if reminder == nil {
+		return nil, errors.NewNotFoundError("Reminder not found", fmt.Sprintf("Reminder with ID %d not found", reminderID))
+	}
+
+	if reminder.UserID != user.ID {
+		return nil, errors.NewUnauthorizedError("You can only manage your own reminders")
+	}
+
+	return reminder, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderService_CreateReminder(t *testing.T) {
+	// Friday 16 Oct 2026
+	now := time.Date(2026, 10, 16, 22, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		reminder        *models.Reminder
+		setupMock       func(*MockStorage)
+		expectError     bool
+		errorType       errors.ErrorType
+		expectedNextRun time.Time
+	}{
+		{
+			name:     "daily reminder already passed today runs tomorrow",
+			reminder: &models.Reminder{Message: "Log today's expenses", Schedule: models.ReminderDaily, Hour: 21},
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("CreateReminder", mock.Anything, mock.AnythingOfType("*models.Reminder")).Return(nil)
+			},
+			expectedNextRun: time.Date(2026, 10, 17, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly reminder runs on the chosen day",
+			reminder: &models.Reminder{
+				Message:   "Petrol check",
+				Schedule:  models.ReminderWeekly,
+				DayOfWeek: sql.NullInt64{Int64: int64(time.Sunday), Valid: true},
+				Hour:      9,
+			},
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("CreateReminder", mock.Anything, mock.AnythingOfType("*models.Reminder")).Return(nil)
+			},
+			expectedNextRun: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "weekly reminder without a day",
+			reminder:    &models.Reminder{Message: "Petrol check", Schedule: models.ReminderWeekly, Hour: 9},
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+		{
+			name:        "empty message",
+			reminder:    &models.Reminder{Schedule: models.ReminderDaily, Hour: 9},
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+			errorType:   errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockStorage{}
+			tt.setupMock(mockDB)
+
+			service := NewReminderService(mockDB, logger.NewMockLogger())
+			err := service.CreateReminder(context.Background(), 12345, tt.reminder, now)
+
+			if tt.expectError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok)
+				assert.Equal(t, tt.errorType, appErr.Type)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), tt.reminder.UserID)
+				assert.Equal(t, tt.expectedNextRun, tt.reminder.NextRun)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReminderService_ClaimDueReminders(t *testing.T) {
+	now := time.Date(2026, 10, 16, 21, 0, 30, 0, time.UTC)
+	dueAt := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2026, 10, 17, 21, 0, 0, 0, time.UTC)
+
+	mine := &models.Reminder{ID: 1, Message: "Log today's expenses", Schedule: models.ReminderDaily, Hour: 21, NextRun: dueAt, IsActive: true}
+	taken := &models.Reminder{ID: 2, Message: "Already sent", Schedule: models.ReminderDaily, Hour: 21, NextRun: dueAt, IsActive: true}
+
+	mockDB := &MockStorage{}
+	mockDB.On("GetDueReminders", mock.Anything, now, reminderBatchSize).Return([]*models.Reminder{mine, taken}, nil)
+	mockDB.On("ClaimReminder", mock.Anything, int64(1), dueAt, tomorrow).Return(true, nil)
+	// Claimed by a run before a restart
+	mockDB.On("ClaimReminder", mock.Anything, int64(2), dueAt, tomorrow).Return(false, nil)
+
+	service := NewReminderService(mockDB, logger.NewMockLogger())
+	claimed, err := service.ClaimDueReminders(context.Background(), now)
+
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, int64(1), claimed[0].ID)
+	assert.Equal(t, tomorrow, claimed[0].NextRun)
+	mockDB.AssertExpectations(t)
+}
+
+func TestReminderService_UpdateReminder(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	updated := &models.Reminder{Message: "Log expenses", Schedule: models.ReminderDaily, Hour: 20, Minute: 30}
+
+	t.Run("rejects reminders owned by another user", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetReminderByID", mock.Anything, int64(5)).Return(&models.Reminder{ID: 5, UserID: 2}, nil)
+
+		service := NewReminderService(mockDB, logger.NewMockLogger())
+		_, err := service.UpdateReminder(context.Background(), 12345, 5, updated, now)
+
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorTypeUnauthorized, appErr.Type)
+	})
+
+	t.Run("reschedules an owned reminder", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1}, nil)
+		mockDB.On("GetReminderByID", mock.Anything, int64(5)).Return(&models.Reminder{ID: 5, UserID: 1, Message: "Old", Schedule: models.ReminderDaily, Hour: 9}, nil)
+		mockDB.On("UpdateReminder", mock.Anything, mock.MatchedBy(func(r *models.Reminder) bool {
+			return r.ID == 5 && r.Message == "Log expenses" && r.NextRun.Equal(time.Date(2026, 10, 16, 20, 30, 0, 0, time.UTC))
+		})).Return(nil)
+
+		service := NewReminderService(mockDB, logger.NewMockLogger())
+		reminder, err := service.UpdateReminder(context.Background(), 12345, 5, updated, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 20, reminder.Hour)
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestReminder_NextRunAfter(t *testing.T) {
+	// Friday 16 Oct 2026
+	ref := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	weekly := func(day time.Weekday, hour int) *models.Reminder {
+		return &models.Reminder{Schedule: models.ReminderWeekly, DayOfWeek: sql.NullInt64{Int64: int64(day), Valid: true}, Hour: hour}
+	}
+
+	tests := []struct {
+		name     string
+		reminder *models.Reminder
+		expected time.Time
+	}{
+		{"daily later today", &models.Reminder{Schedule: models.ReminderDaily, Hour: 22, Minute: 30}, time.Date(2026, 10, 16, 22, 30, 0, 0, time.UTC)},
+		{"daily at exactly now moves to tomorrow", &models.Reminder{Schedule: models.ReminderDaily, Hour: 21}, time.Date(2026, 10, 17, 21, 0, 0, 0, time.UTC)},
+		{"weekly later this week", weekly(time.Sunday, 9), time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		{"weekly today but later", weekly(time.Friday, 23), time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)},
+		{"weekly today already passed", weekly(time.Friday, 8), time.Date(2026, 10, 23, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.reminder.NextRunAfter(ref))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package services provides business logic services for the expense tracker bot.
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// SettingsService provides per-user preference business logic
+type SettingsService struct {
+	db        database.Storage
+	logger    logger.Logger
+	validator *validation.Validator
+}
+
+// NewSettingsService creates a new settings service
+func NewSettingsService(db database.Storage, logger logger.Logger) *SettingsService {
+	return &SettingsService{
+		db:        db,
+		logger:    logger,
+		validator: validation.NewValidator(),
+	}
+}
+
+// GetSettings retrieves the settings of a user, or the defaults if none were saved
+func (s *SettingsService) GetSettings(ctx context.Context, telegramID int64) (*models.UserSettings, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getSettings(ctx, user)
+}
+
+// SetCurrency changes the currency amounts are shown in
+func (s *SettingsService) SetCurrency(ctx context.Context, telegramID int64, code string) (*models.UserSettings, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if err := s.validator.ValidateCurrencyCode(code); err != nil {
+		return nil, err
+	}
+
+	if _, ok := models.FindCurrency(code); !ok {
+		return nil, errors.NewValidationError("Unsupported currency", fmt.Sprintf("Currency %s is not supported", code))
+	}
+
+	return s.update(ctx, telegramID, func(settings *models.UserSettings) {
+		settings.Currency = code
+	})
+}
+
+// SetDateFormat changes the layout dates are shown in
+func (s *SettingsService) SetDateFormat(ctx context.Context, telegramID int64, layout string) (*models.UserSettings, error) {
+	if _, ok := models.FindDateFormat(layout); !ok {
+		return nil, errors.NewValidationError("Unsupported date format", fmt.Sprintf("Date format %q is not supported", layout))
+	}
+
+	return s.update(ctx, telegramID, func(settings *models.UserSettings) {
+		settings.DateFormat = layout
+	})
+}
+
+// SetLanguage changes the language dates are shown in
+func (s *SettingsService) SetLanguage(ctx context.Context, telegramID int64, code string) (*models.UserSettings, error) {
+	language, ok := models.FindLanguage(code)
+	if !ok {
+		return nil, errors.NewValidationError("Unsupported language", fmt.Sprintf("Language %q is not supported", code))
+	}
+
+	return s.update(ctx, telegramID, func(settings *models.UserSettings) {
+		settings.Language = language.Code
+	})
+}
+
+// SetNotifications turns automatic messages such as recurring expense notices on or off
+func (s *SettingsService) SetNotifications(ctx context.Context, telegramID int64, enabled bool) (*models.UserSettings, error) {
+	return s.update(ctx, telegramID, func(settings *models.UserSettings) {
+		settings.NotificationsEnabled = enabled
+	})
+}
+
+// update applies change to the current settings of a user and saves them
+func (s *SettingsService) update(ctx context.Context, telegramID int64, change func(*models.UserSettings)) (*models.UserSettings, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.getSettings(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	change(settings)
+
+	if err := s.db.UpsertUserSettings(ctx, settings); err != nil {
+		s.logger.Error(ctx, "Failed to save user settings", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to save settings", err)
+	}
+
+	s.logger.Info(ctx, "User settings updated",
+		logger.Int("user_id", int(user.ID)),
+		logger.String("currency", settings.Currency),
+		logger.String("language", settings.Language))
+
+	return settings, nil
+}
+
+// getSettings retrieves the saved settings of a user, falling back to the defaults
+func (s *SettingsService) getSettings(ctx context.Context, user *models.User) (*models.UserSettings, error) {
+	settings, err := s.db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return models.DefaultUserSettings(user.ID), nil
+		}
+		s.logger.Error(ctx, "Failed to get user settings", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get settings", err)
+	}
+
+	return settings, nil
+}
+
+// getUser retrieves a user by Telegram ID, returning a not found error if missing
+func (s *SettingsService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+		}
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	return user, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsService_GetSettings(t *testing.T) {
+	t.Run("returns defaults when nothing is saved", func(t *testing.T) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+		mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+
+		service := NewSettingsService(mockDB, logger.NewMockLogger())
+		settings, err := service.GetSettings(context.Background(), 12345)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DefaultUserSettings(1), settings)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("returns saved settings", func(t *testing.T) {
+		saved := &models.UserSettings{UserID: 1, Currency: "EUR", DateFormat: "2006-01-02", Language: "de"}
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+		mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(saved, nil)
+
+		service := NewSettingsService(mockDB, logger.NewMockLogger())
+		settings, err := service.GetSettings(context.Background(), 12345)
+
+		require.NoError(t, err)
+		assert.Equal(t, saved, settings)
+	})
+}
+
+func TestSettingsService_SetCurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		setupMock   func(*MockStorage)
+		expectError bool
+		expected    string
+	}{
+		{
+			name: "valid currency is normalised and saved",
+			code: " usd ",
+			setupMock: func(mockDB *MockStorage) {
+				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+				mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(nil, sql.ErrNoRows)
+				mockDB.On("UpsertUserSettings", mock.Anything, mock.MatchedBy(func(s *models.UserSettings) bool {
+					return s.UserID == 1 && s.Currency == "USD" && s.DateFormat == models.DefaultDateFormat
+				})).Return(nil)
+			},
+			expected: "USD",
+		},
+		{
+			name:        "unsupported currency",
+			code:        "XYZ",
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+		},
+		{
+			name:        "invalid code",
+			code:        "euro",
+			setupMock:   func(mockDB *MockStorage) {},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockStorage{}
+			tt.setupMock(mockDB)
+
+			service := NewSettingsService(mockDB, logger.NewMockLogger())
+			settings, err := service.SetCurrency(context.Background(), 12345, tt.code)
+
+			if tt.expectError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok)
+				assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, settings.Currency)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSettingsService_SetDateFormat(t *testing.T) {
+	mockDB := &MockStorage{}
+	service := NewSettingsService(mockDB, logger.NewMockLogger())
+
+	_, err := service.SetDateFormat(context.Background(), 12345, "Mon Jan 2")
+	require.Error(t, err)
+
+	mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 1, TelegramID: 12345}, nil)
+	mockDB.On("GetUserSettings", mock.Anything, int64(1)).Return(&models.UserSettings{UserID: 1, Currency: "EUR", DateFormat: models.DefaultDateFormat}, nil)
+	mockDB.On("UpsertUserSettings", mock.Anything, mock.AnythingOfType("*models.UserSettings")).Return(nil)
+
+	settings, err := service.SetDateFormat(context.Background(), 12345, "2006-01-02")
+	require.NoError(t, err)
+	assert.Equal(t, "2006-01-02", settings.DateFormat)
+	assert.Equal(t, "EUR", settings.Currency)
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// splitExpenseLimit is the number of recent ledger expenses offered for splitting
+const splitExpenseLimit = 10
+
+// SplitService splits shared ledger expenses between members, works out who owes whom and
+// records settlements
+type SplitService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	currencyService *CurrencyService
+	ledgerService   *LedgerService
+}
+
+// NewSplitService creates a new split service that converts balances with currencyService and
+// finds ledgers and checks members' access with ledgerService
+func NewSplitService(db database.Storage, logger logger.Logger, currencyService *CurrencyService, ledgerService *LedgerService) *SplitService {
+	return &SplitService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		currencyService: currencyService,
+		ledgerService:   ledgerService,
+	}
+}
+
+// GetSplittableExpenses returns the latest expenses of the named ledger from the last 90 days,
+// newest first. An empty name means the active ledger, or the user's only one.
+func (s *SplitService) GetSplittableExpenses(ctx context.Context, telegramID int64, ledgerName string) (*models.Ledger, []*models.Expense, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ledger, err := s.ledgerService.getUserLedger(ctx, user.ID, ledgerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	end := time.Now().Add(time.Minute)
+	expenses, err := s.db.GetLedgerExpenses(ctx, ledger.ID, end.AddDate(0, 0, -90), end)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger expenses", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to get ledger expenses", err)
+	}
+	if len(expenses) > splitExpenseLimit {
+		expenses = expenses[:splitExpenseLimit]
+	}
+
+	return ledger, expenses, nil
+}
+
+// GetSplitMembers returns a shared expense the user may split and the members of its ledger
+func (s *SplitService) GetSplitMembers(ctx context.Context, telegramID, expenseID int64) (*models.Expense, []*models.LedgerMember, error) {
+	_, expense, members, err := s.getSplitExpense(ctx, telegramID, expenseID)
+	return expense, members, err
+}
+
+// SplitExpense divides a shared expense between ledger members, replacing any earlier split.
+// Equal splits with no parts include every member; shares must be positive and exact amounts
+// must add up to the expense total.
+func (s *SplitService) SplitExpense(ctx context.Context, telegramID, expenseID int64, method models.SplitMethod, parts []models.SplitPart) ([]*models.ExpenseSplit, error) {
+	_, expense, members, err := s.getSplitExpense(ctx, telegramID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := make([]*models.LedgerMember, 0, len(parts))
+	values := make([]float64, 0, len(parts))
+	if method == models.SplitMethodEqual && len(parts) == 0 {
+		for _, member := range members {
+			participants = append(participants, member)
+			values = append(values, 1)
+		}
+	}
+	seen := make(map[int64]bool)
+	for _, part := range parts {
+		member := findLedgerMember(members, part.Member)
+		if member == nil {
+			return nil, errors.NewNotFoundError("Member not found", fmt.Sprintf("The ledger has no member named %s", part.Member))
+		}
+		if seen[member.UserID] {
+			return nil, errors.NewValidationError("Duplicate participant", fmt.Sprintf("%s is listed twice", member.DisplayName()))
+		}
+		seen[member.UserID] = true
+		participants = append(participants, member)
+		if method == models.SplitMethodEqual {
+			values = append(values, 1)
+		} else {
+			values = append(values, part.Value)
+		}
+	}
+
+	amounts, err := computeSplitAmounts(method, expense.TotalPrice, values)
+	if err != nil {
+		return nil, err
+	}
+
+	splits := make([]*models.ExpenseSplit, len(participants))
+	for i, member := range participants {
+		split := &models.ExpenseSplit{UserID: member.UserID, Method: method, Shares: 1, Amount: amounts[i]}
+		if method == models.SplitMethodShares {
+			split.Shares = values[i]
+		}
+		splits[i] = split
+	}
+
+	if err := s.db.SetExpenseSplits(ctx, expense.ID, splits); err != nil {
+		s.logger.Error(ctx, "Failed to save expense splits", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to split expense", err)
+	}
+
+	s.logger.Info(ctx, "Expense split",
+		logger.Int("expense_id", int(expense.ID)),
+		logger.Int("participants", len(splits)))
+
+	return splits, nil
+}
+
+// GetBalances returns what each member of the named ledger is owed or owes, in the user's home
+// currency, and the fewest transfers that settle everything
+func (s *SplitService) GetBalances(ctx context.Context, telegramID int64, ledgerName string) (*models.LedgerBalances, error) {
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.ledgerService.getUserLedger(ctx, user.ID, ledgerName)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+
+	expenses, err := s.db.GetLedgerSplitExpenses(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get split expenses", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get split expenses", err)
+	}
+
+	splits, err := s.db.GetLedgerSplits(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get expense splits", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get expense splits", err)
+	}
+
+	settlements, err := s.db.GetSettlementsByLedgerID(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get settlements", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get settlements", err)
+	}
+
+	currency, err := s.currencyService.HomeCurrency(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Amounts are converted to the user's currency. Expenses and settlements without an exchange
+	// rate are left out, and reported, rather than counted in the wrong currency.
+	result := &models.LedgerBalances{Ledger: ledger, Currency: currency}
+	missingRates := make(map[string]bool)
+	convert := func(amount float64, from string, on time.Time) (float64, bool, error) {
+		if from == "" || from == currency {
+			return amount, true, nil
+		}
+		converted, err := s.currencyService.Convert(ctx, amount, from, currency, on)
+		var appErr *errors.AppError
+		if stderrors.As(err, &appErr) && appErr.IsNotFoundError() {
+			result.Unconverted++
+			missingRates[from+"→"+currency] = true
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		return converted, true, nil
+	}
+
+	// Each participant owes their split's proportion of the expense, so an expense edited after
+	// it was split still balances
+	totals := make(map[int64]*models.Expense, len(expenses))
+	amounts := make(map[int64]float64, len(expenses))
+	for _, expense := range expenses {
+		amount := expense.TotalPrice
+		if expense.Currency != "" && expense.OriginalAmount > 0 {
+			amount = expense.OriginalAmount
+		}
+		converted, ok, err := convert(amount, expense.Currency, expense.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			totals[expense.ID] = expense
+			amounts[expense.ID] = converted
+		}
+	}
+	splitSums := make(map[int64]float64, len(expenses))
+	for _, split := range splits {
+		splitSums[split.ExpenseID] += split.Amount
+	}
+
+	net := make(map[int64]float64)
+	for _, split := range splits {
+		expense, ok := totals[split.ExpenseID]
+		if !ok || splitSums[split.ExpenseID] <= 0 {
+			continue
+		}
+		amount := amounts[expense.ID] * split.Amount / splitSums[split.ExpenseID]
+		net[expense.UserID] += amount
+		net[split.UserID] -= amount
+	}
+
+	for _, settlement := range settlements {
+		amount, ok, err := convert(settlement.Amount, settlement.Currency, settlement.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		net[settlement.FromUserID] += amount
+		net[settlement.ToUserID] -= amount
+	}
+	for pair := range missingRates {
+		result.MissingRates = append(result.MissingRates, pair)
+	}
+	sort.Strings(result.MissingRates)
+
+	balances := make([]models.MemberBalance, 0, len(members))
+	for _, member := range members {
+		balances = append(balances, models.MemberBalance{UserID: member.UserID, Name: member.DisplayName(), Amount: roundCents(net[member.UserID])})
+		delete(net, member.UserID)
+	}
+	for userID, amount := range net {
+		if math.Abs(amount) >= 0.005 {
+			balances = append(balances, models.MemberBalance{UserID: userID, Name: "Former member", Amount: roundCents(amount)})
+		}
+	}
+	sort.SliceStable(balances, func(i, j int) bool { return balances[i].Amount > balances[j].Amount })
+
+	result.Balances = balances
+	result.Transfers = simplifyDebts(balances)
+	return result, nil
+}
+
+// Settle records that the user paid a member of the named ledger amount in the user's home
+// currency, and returns the member
+func (s *SplitService) Settle(ctx context.Context, telegramID int64, ledgerName, memberName string, amount float64) (*models.Settlement, *models.LedgerMember, error) {
+	if err := s.validator.ValidateAmount(amount, "amount"); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ledger, err := s.ledgerService.getUserLedger(ctx, user.ID, ledgerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+	member := findLedgerMember(members, memberName)
+	if member == nil {
+		return nil, nil, errors.NewNotFoundError("Member not found", fmt.Sprintf("%s has no member named %s", ledger.Name, memberName))
+	}
+	if member.UserID == user.ID {
+		return nil, nil, errors.NewValidationError("Invalid settlement", "You cannot settle up with yourself")
+	}
+
+	currency, err := s.currencyService.HomeCurrency(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settlement := &models.Settlement{
+		LedgerID:   ledger.ID,
+		FromUserID: user.ID,
+		ToUserID:   member.UserID,
+		Amount:     amount,
+		Currency:   currency,
+	}
+	if err := s.db.CreateSettlement(ctx, settlement); err != nil {
+		s.logger.Error(ctx, "Failed to create settlement", logger.ErrorField(err))
+		return nil, nil, errors.NewDatabaseError("Failed to record settlement", err)
+	}
+
+	s.logger.Info(ctx, "Settlement recorded",
+		logger.Int("ledger_id", int(ledger.ID)),
+		logger.Int("settlement_id", int(settlement.ID)))
+
+	return settlement, member, nil
+}
+
+// getSplitExpense returns the user, a shared expense they may change and its ledger's members
+func (s *SplitService) getSplitExpense(ctx context.Context, telegramID, expenseID int64) (*models.User, *models.Expense, []*models.LedgerMember, error) {
+	if err := s.validator.ValidateExpenseID(expenseID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	user, err := s.ledgerService.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	expense, err := s.db.GetExpenseByID(ctx, expenseID)
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to get expense", logger.ErrorField(err))
+		return nil, nil, nil, errors.NewDatabaseError("Failed to get expense", err)
+	}
+	if expense == nil {
+		return nil, nil, nil, errors.NewNotFoundError("Expense not found", fmt.Sprintf("Expense with ID %d not found", expenseID))
+	}
+	if !expense.LedgerID.Valid {
+		return nil, nil, nil, errors.NewValidationError("Not a shared expense", "Only expenses in a shared ledger can be split")
+	}
+	if err := s.ledgerService.checkExpenseAccess(ctx, user.ID, expense); err != nil {
+		return nil, nil, nil, err
+	}
+
+	members, err := s.db.GetLedgerMembers(ctx, expense.LedgerID.Int64)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get ledger members", logger.ErrorField(err))
+		return nil, nil, nil, errors.NewDatabaseError("Failed to get ledger members", err)
+	}
+
+	return user, expense, members, nil
+}
+
+// computeSplitAmounts divides total by the given values: equally or in proportion to shares,
+// rounded to cents with any rounding difference on the first participant, or as exact amounts
+// that must add up to total
+func computeSplitAmounts(method models.SplitMethod, total float64, values []float64) ([]float64, error) {
+	if len(values) == 0 {
+		return nil, errors.NewValidationError("No participants", "Name at least one member to split with")
+	}
+
+	amounts := make([]float64, len(values))
+	switch method {
+	case models.SplitMethodEqual, models.SplitMethodShares:
+		var sum float64
+		for _, value := range values {
+			if value <= 0 {
+				return nil, errors.NewValidationError("Invalid shares", "Shares must be greater than zero")
+			}
+			sum += value
+		}
+		var allocated float64
+		for i, value := range values {
+			amounts[i] = roundCents(total * value / sum)
+			allocated += amounts[i]
+		}
+		amounts[0] = roundCents(amounts[0] + total - allocated)
+
+	case models.SplitMethodExact:
+		var sum float64
+		for i, value := range values {
+			if value < 0 {
+				return nil, errors.NewValidationError("Invalid amount", "Amounts cannot be negative")
+			}
+			amounts[i] = roundCents(value)
+			sum += amounts[i]
+		}
+		if math.Abs(sum-total) >= 0.01 {
+			return nil, errors.NewValidationError("Amounts do not add up",
+				fmt.Sprintf("The amounts add up to %.2f but the expense is %.2f", sum, total))
+		}
+
+	default:
+		return nil, errors.NewValidationError("Invalid split method", "Split equally, by shares or by exact amounts")
+	}
+
+	return amounts, nil
+}
+
+// simplifyDebts returns the transfers that settle the balances, matching the largest debtor
+// with the largest creditor each time so there are at most one fewer transfers than members
+func simplifyDebts(balances []models.MemberBalance) []models.Transfer {
+	var creditors, debtors []models.MemberBalance
+	for _, balance := range balances {
+		switch {
+		case balance.Amount >= 0.01:
+			creditors = append(creditors, balance)
+		case balance.Amount <= -0.01:
+			debtors = append(debtors, models.MemberBalance{UserID: balance.UserID, Name: balance.Name, Amount: -balance.Amount})
+		}
+	}
+	byAmount := func(list []models.MemberBalance) func(i, j int) bool {
+		return func(i, j int) bool {
+			if list[i].Amount == list[j].Amount {
+				return list[i].UserID < list[j].UserID
+			}
+			return list[i].Amount > list[j].Amount
+		}
+	}
+	sort.Slice(creditors, byAmount(creditors))
+	sort.Slice(debtors, byAmount(debtors))
+
+	var transfers []models.Transfer
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := roundCents(math.Min(debtors[i].Amount, creditors[j].Amount))
+		if amount >= 0.01 {
+			transfers = append(transfers, models.Transfer{
+				FromUserID: debtors[i].UserID,
+				From:       debtors[i].Name,
+				ToUserID:   creditors[j].UserID,
+				To:         creditors[j].Name,
+				Amount:     amount,
+			})
+		}
+		debtors[i].Amount -= amount
+		creditors[j].Amount -= amount
+		if debtors[i].Amount < 0.01 {
+			i++
+		}
+		if creditors[j].Amount < 0.01 {
+			j++
+		}
+	}
+	return transfers
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSplitAmounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  models.SplitMethod
+		total   float64
+		values  []float64
+		want    []float64
+		wantErr bool
+	}{
+		{name: "equal", method: models.SplitMethodEqual, total: 900, values: []float64{1, 1, 1}, want: []float64{300, 300, 300}},
+		{name: "equal with remainder", method: models.SplitMethodEqual, total: 100, values: []float64{1, 1, 1}, want: []float64{33.34, 33.33, 33.33}},
+		{name: "shares", method: models.SplitMethodShares, total: 1200, values: []float64{2, 1}, want: []float64{800, 400}},
+		{name: "zero share", method: models.SplitMethodShares, total: 1200, values: []float64{2, 0}, wantErr: true},
+		{name: "exact", method: models.SplitMethodExact, total: 1000, values: []float64{650, 350}, want: []float64{650, 350}},
+		{name: "exact not adding up", method: models.SplitMethodExact, total: 1000, values: []float64{650, 300}, wantErr: true},
+		{name: "no participants", method: models.SplitMethodEqual, total: 1000, wantErr: true},
+		{name: "unknown method", method: "HALF", total: 1000, values: []float64{1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := computeSplitAmounts(tt.method, tt.total, tt.values)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSimplifyDebts(t *testing.T) {
+	balances := []models.MemberBalance{
+		{UserID: 1, Name: "Asha", Amount: 700},
+		{UserID: 2, Name: "Ravi", Amount: 100},
+		{UserID: 3, Name: "Meera", Amount: -300},
+		{UserID: 4, Name: "Dev", Amount: -500},
+		{UserID: 5, Name: "Kiran", Amount: 0},
+	}
+
+	assert.Equal(t, []models.Transfer{
+		{FromUserID: 4, From: "Dev", ToUserID: 1, To: "Asha", Amount: 500},
+		{FromUserID: 3, From: "Meera", ToUserID: 1, To: "Asha", Amount: 200},
+		{FromUserID: 3, From: "Meera", ToUserID: 2, To: "Ravi", Amount: 100},
+	}, simplifyDebts(balances))
+
+	assert.Empty(t, simplifyDebts([]models.MemberBalance{{UserID: 1, Amount: 0.004}, {UserID: 2, Amount: -0.004}}))
+}
+
+func TestSplitService_SplitAndSettle(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	storage.(*database.MockStorage).AddMockCategory(&models.Category{Name: "Rent", Emoji: "🏠", Group: "Home"})
+	for _, user := range []*models.User{
+		{TelegramID: 111, FirstName: "Asha"},
+		{TelegramID: 222, FirstName: "Ravi", Username: "ravi"},
+		{TelegramID: 333, FirstName: "Meera"},
+	} {
+		require.NoError(t, storage.CreateUser(ctx, user))
+	}
+
+	mockLogger := logger.NewMockLogger()
+	currencyService := NewCurrencyService(storage, mockLogger)
+	ledgerService := NewLedgerService(storage, mockLogger, currencyService)
+	expenseService := newTestExpenseService(storage, mockLogger, nil)
+	service := NewSplitService(storage, mockLogger, currencyService, ledgerService)
+
+	ledger, err := ledgerService.CreateLedger(ctx, 111, "Flat")
+	require.NoError(t, err)
+	for _, telegramID := range []int64{222, 333} {
+		_, err = ledgerService.JoinLedger(ctx, telegramID, ledger.InviteCode)
+		require.NoError(t, err)
+	}
+
+	addShared := func(telegramID int64, amount float64) *models.Expense {
+		expense := &models.Expense{CategoryName: "Rent", TotalPrice: amount, Timestamp: time.Now()}
+		expense.LedgerID.Int64, expense.LedgerID.Valid = ledger.ID, true
+		require.NoError(t, expenseService.CreateExpense(ctx, expense, telegramID))
+		_, expenses, err := service.GetSplittableExpenses(ctx, telegramID, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, expenses)
+		return expenses[0]
+	}
+
+	// Asha pays 900 rent split equally; Ravi pays 600 for groceries split 2:1 with Meera
+	rent := addShared(111, 900)
+	splits, err := service.SplitExpense(ctx, 111, rent.ID, models.SplitMethodEqual, nil)
+	require.NoError(t, err)
+	assert.Len(t, splits, 3)
+
+	groceries := addShared(222, 600)
+	_, err = service.SplitExpense(ctx, 222, groceries.ID, models.SplitMethodShares, []models.SplitPart{{Member: "@ravi", Value: 2}, {Member: "meera", Value: 1}})
+	require.NoError(t, err)
+
+	_, err = service.SplitExpense(ctx, 222, groceries.ID, models.SplitMethodExact, []models.SplitPart{{Member: "Ravi", Value: 100}, {Member: "Nobody", Value: 500}})
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+
+	balances, err := service.GetBalances(ctx, 333, "Flat")
+	require.NoError(t, err)
+	assert.Equal(t, []models.MemberBalance{
+		{UserID: balances.Balances[0].UserID, Name: "Asha", Amount: 600},
+		{UserID: balances.Balances[1].UserID, Name: "Ravi", Amount: -100},
+		{UserID: balances.Balances[2].UserID, Name: "Meera", Amount: -500},
+	}, balances.Balances)
+	require.Len(t, balances.Transfers, 2)
+	assert.Equal(t, models.Transfer{FromUserID: balances.Transfers[0].FromUserID, From: "Meera", ToUserID: balances.Transfers[0].ToUserID, To: "Asha", Amount: 500}, balances.Transfers[0])
+
+	// Settling up moves the balances back towards zero
+	_, _, err = service.Settle(ctx, 333, "", "Meera", 500)
+	assert.Error(t, err)
+	_, member, err := service.Settle(ctx, 333, "", "Asha", 500)
+	require.NoError(t, err)
+	assert.Equal(t, "Asha", member.FirstName)
+
+	balances, err = service.GetBalances(ctx, 111, "")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, balances.Balances[0].Amount)
+	assert.Equal(t, []models.Transfer{{FromUserID: balances.Transfers[0].FromUserID, From: "Ravi", ToUserID: balances.Transfers[0].ToUserID, To: "Asha", Amount: 100}}, balances.Transfers)
+
+	// A settlement in a currency without an exchange rate is left out and reported
+	ravi, asha := balances.Transfers[0].FromUserID, balances.Transfers[0].ToUserID
+	require.NoError(t, storage.CreateSettlement(ctx, &models.Settlement{LedgerID: ledger.ID, FromUserID: ravi, ToUserID: asha, Amount: 1, Currency: "USD"}))
+	balances, err = service.GetBalances(ctx, 111, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, balances.Unconverted)
+	assert.Equal(t, []string{"USD→INR"}, balances.MissingRates)
+	assert.Equal(t, 100.0, balances.Balances[0].Amount)
+
+	_, err = currencyService.SetRate(ctx, "USD", "INR", 100, time.Now().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	balances, err = service.GetBalances(ctx, 111, "")
+	require.NoError(t, err)
+	assert.Zero(t, balances.Unconverted)
+	assert.Equal(t, 0.0, balances.Balances[0].Amount)
+	assert.Empty(t, balances.Transfers)
+
+	// Personal expenses cannot be split
+	personal := &models.Expense{CategoryName: "Rent", TotalPrice: 50, Timestamp: time.Now()}
+	require.NoError(t, expenseService.CreateExpense(ctx, personal, 111))
+	expenses, err := storage.GetExpensesByDateRange(ctx, rent.UserID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	for _, expense := range expenses {
+		if !expense.LedgerID.Valid {
+			_, err = service.SplitExpense(ctx, 111, expense.ID, models.SplitMethodEqual, nil)
+			assert.Error(t, err)
+		}
+	}
+}
@@ -53,9 +53,9 @@ func (s *UserService) GetOrCreateUser(ctx context.Context, telegramID int64, use
 		}
 	}
 
-	// Try to get existing user
+	// Try to get existing user; a missing user is created below
 	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
-	if err != nil {
+	if err != nil && !database.IsNotFound(err) {
 		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
 		return nil, errors.NewDatabaseError("Failed to get user", err)
 	}
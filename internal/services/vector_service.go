@@ -2,14 +2,20 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	stderrors "errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
 	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/metrics"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/parser"
 )
 
 // VectorServiceInterface defines the interface for vector service operations
@@ -17,23 +23,49 @@ type VectorServiceInterface interface {
 	SearchExpensesByQuery(ctx context.Context, telegramID int64, query string, similarityThreshold float32, limit int) ([]*models.Expense, error)
 	FindSimilarExpenses(ctx context.Context, expenseID int64, similarityThreshold float32, limit int) ([]*models.Expense, error)
 	UpdateExpenseEmbeddings(ctx context.Context, expenseID int64) error
+	EnqueueEmbeddings(ctx context.Context, expenseIDs ...int64) error
 	BatchUpdateEmbeddings(ctx context.Context, telegramID int64) error
+	SearchExpenses(ctx context.Context, telegramID int64, text string, page int) (*models.SearchResult, error)
 }
 
+const (
+	// SearchPageSize is the number of expenses on a page of search results
+	SearchPageSize = 10
+
+	// searchMinSimilarity is the smallest embedding similarity that counts as a semantic match
+	searchMinSimilarity = 0.2
+)
+
+// embeddingDuration times generating embeddings, which calls out to the API for remote embedders
+var embeddingDuration = metrics.NewHistogram("expense_bot_embedding_duration_seconds",
+	"How long generating an embedding took, by embedder.", nil, "embedder")
+
 // VectorService provides vector-based search and embedding functionality
 type VectorService struct {
-	db     database.Storage
-	logger logger.Logger
+	db              database.Storage
+	logger          logger.Logger
+	embedder        embedding.Embedder
+	categoryService *CategoryService
+	queued          chan struct{}
 }
 
-// NewVectorService creates a new vector service
-func NewVectorService(db database.Storage, logger logger.Logger) *VectorService {
+// NewVectorService creates a new vector service that embeds text with the given embedder
+func NewVectorService(db database.Storage, logger logger.Logger, embedder embedding.Embedder) *VectorService {
 	return &VectorService{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		embedder: embedder,
+		queued:   make(chan struct{}, 1),
 	}
 }
 
+// SetCategoryService sets the category service whose categories search filters are parsed with.
+// It must be set before SearchExpenses is used; it is not a constructor argument because the
+// category service queues embeddings with this service.
+func (s *VectorService) SetCategoryService(categoryService *CategoryService) {
+	s.categoryService = categoryService
+}
+
 // SearchExpensesByQuery performs semantic search on expenses using natural language query
 func (s *VectorService) SearchExpensesByQuery(ctx context.Context, telegramID int64, query string, similarityThreshold float32, limit int) ([]*models.Expense, error) {
 	// Get user by Telegram ID
@@ -64,6 +96,65 @@ func (s *VectorService) SearchExpensesByQuery(ctx context.Context, telegramID in
 	return expenses, nil
 }
 
+// SearchExpenses runs a /search query and returns the given 1-based page of results. Dates,
+// amounts, categories and vehicle words become filters; the remaining text is ranked by full-text
+// search and embedding similarity. If the text cannot be embedded, full-text ranking is used alone.
+func (s *VectorService) SearchExpenses(ctx context.Context, telegramID int64, text string, page int) (*models.SearchResult, error) {
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+
+	categories, err := s.categoryService.GetUserCategories(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := parser.New(categories).ParseSearch(text)
+	if page < 1 {
+		page = 1
+	}
+	search := &models.ExpenseSearch{
+		SearchQuery:   *query,
+		UserID:        user.ID,
+		MinSimilarity: searchMinSimilarity,
+		Limit:         SearchPageSize,
+		Offset:        (page - 1) * SearchPageSize,
+	}
+
+	if query.Text != "" {
+		// Cached so that paging through results embeds the query once
+		vector, err := s.cachedEmbedding(ctx, query.Text)
+		if err != nil && !stderrors.Is(err, embedding.ErrEmptyText) {
+			s.logger.Warn(ctx, "Failed to embed search query, using full-text search only", logger.ErrorField(err))
+		}
+		search.Embedding = vector
+	}
+
+	expenses, total, err := s.db.SearchExpenses(ctx, search)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to search expenses", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to search expenses", err)
+	}
+
+	// Expenses deleted since the search was run can leave a page empty
+	if len(expenses) == 0 && page > 1 {
+		return s.SearchExpenses(ctx, telegramID, text, 1)
+	}
+
+	return &models.SearchResult{
+		Query:    query,
+		Expenses: expenses,
+		Total:    total,
+		Page:     page,
+		PageSize: SearchPageSize,
+	}, nil
+}
+
 // FindSimilarExpenses finds expenses similar to a given expense
 func (s *VectorService) FindSimilarExpenses(ctx context.Context, expenseID int64, similarityThreshold float32, limit int) ([]*models.Expense, error) {
 	expenses, err := s.db.FindSimilarExpenses(ctx, expenseID, similarityThreshold, limit)
@@ -92,8 +183,12 @@ func (s *VectorService) UpdateExpenseEmbeddings(ctx context.Context, expenseID i
 	var notesEmbedding, categoryEmbedding []float32
 
 	// Generate notes embedding only if notes exist
-	if expense.Notes != "" {
-		notesEmbedding, err = s.generateEmbedding(ctx, expense.Notes)
+	if strings.TrimSpace(expense.Notes) != "" {
+		notesEmbedding, err = s.cachedEmbedding(ctx, expense.Notes)
+		if stderrors.Is(err, embedding.ErrEmptyText) {
+			// Notes such as "-" have nothing to embed
+			notesEmbedding, err = nil, nil
+		}
 		if err != nil {
 			s.logger.Error(ctx, "Failed to generate notes embedding", logger.ErrorField(err))
 			return errors.NewInternalError("Failed to generate notes embedding", err)
@@ -108,7 +203,7 @@ func (s *VectorService) UpdateExpenseEmbeddings(ctx context.Context, expenseID i
 
 	// Generate category embedding
 	categoryText := fmt.Sprintf("%s %s", expense.CategoryName, expense.CategoryGroup)
-	categoryEmbedding, err = s.generateEmbedding(ctx, categoryText)
+	categoryEmbedding, err = s.cachedEmbedding(ctx, categoryText)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to generate category embedding", logger.ErrorField(err))
 		return errors.NewInternalError("Failed to generate category embedding", err)
@@ -135,7 +230,22 @@ func (s *VectorService) UpdateExpenseEmbeddings(ctx context.Context, expenseID i
 	return nil
 }
 
-// BatchUpdateEmbeddings updates embeddings for all expenses without embeddings
+// EnqueueEmbeddings queues expenses to be embedded in the background by the embedding workers
+func (s *VectorService) EnqueueEmbeddings(ctx context.Context, expenseIDs ...int64) error {
+	if len(expenseIDs) == 0 {
+		return nil
+	}
+
+	if err := s.db.EnqueueEmbeddingJobs(ctx, expenseIDs); err != nil {
+		s.logger.Error(ctx, "Failed to queue expense embeddings", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to queue expense embeddings", err)
+	}
+
+	s.notifyQueued()
+	return nil
+}
+
+// BatchUpdateEmbeddings queues every expense of a user that is missing embeddings
 func (s *VectorService) BatchUpdateEmbeddings(ctx context.Context, telegramID int64) error {
 	// Get user by Telegram ID
 	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
@@ -148,82 +258,92 @@ func (s *VectorService) BatchUpdateEmbeddings(ctx context.Context, telegramID in
 		return errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
 	}
 
-	// Get all expenses for the user
-	expenses, err := s.db.GetExpensesByUserID(ctx, user.ID)
+	queued, err := s.db.EnqueueMissingEmbeddings(ctx, user.ID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to get expenses for batch embedding update", logger.ErrorField(err))
-		return errors.NewDatabaseError("Failed to get expenses", err)
+		s.logger.Error(ctx, "Failed to queue expenses for batch embedding update", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to queue expense embeddings", err)
 	}
 
-	updatedCount := 0
-	for _, expense := range expenses {
-		// Check if expense already has embeddings
-		embedding, err := s.db.GetExpenseEmbedding(ctx, expense.ID)
-		if err != nil {
-			// Check if it's a not found error (no embedding exists yet)
-			if appErr, ok := err.(*errors.AppError); ok && appErr.IsNotFoundError() {
-				// This is expected - no embedding exists yet
-			} else {
-				s.logger.Error(ctx, "Failed to check expense embedding", logger.ErrorField(err))
-				continue
-			}
-		}
+	if queued > 0 {
+		s.notifyQueued()
+	}
 
-		// Skip if embedding already exists
-		if embedding != nil && len(embedding.NotesEmbedding) > 0 {
-			continue
-		}
+	s.logger.Info(ctx, "Batch embedding update queued",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("queued_count", queued))
 
-		// Update embeddings for this expense
-		if err := s.UpdateExpenseEmbeddings(ctx, expense.ID); err != nil {
-			s.logger.Error(ctx, "Failed to update embeddings for expense",
-				logger.Int("expense_id", int(expense.ID)),
-				logger.ErrorField(err))
-			continue
-		}
+	return nil
+}
+
+// Queued is signalled after expenses are queued, so idle workers can start without waiting for their next poll
+func (s *VectorService) Queued() <-chan struct{} {
+	return s.queued
+}
 
-		updatedCount++
+// notifyQueued signals Queued without blocking; one pending signal is enough to wake the workers
+func (s *VectorService) notifyQueued() {
+	select {
+	case s.queued <- struct{}{}:
+	default:
 	}
+}
 
-	s.logger.Info(ctx, "Batch embedding update completed",
-		logger.Int("total_expenses", len(expenses)),
-		logger.Int("updated_count", updatedCount))
+// cachedEmbedding returns the embedding of text from the embedding cache, embedding and caching it
+// on a miss. Texts are cached per embedder, so identical notes and category names are embedded once.
+// Cache errors are logged and never stop the embedding itself.
+func (s *VectorService) cachedEmbedding(ctx context.Context, text string) ([]float32, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil, embedding.ErrEmptyText
+	}
 
-	return nil
+	hash := sha256.Sum256([]byte(s.embedder.Name() + "\x00" + text))
+	contentHash := hex.EncodeToString(hash[:])
+
+	cached, err := s.db.GetCachedEmbedding(ctx, contentHash)
+	if err == nil && embedding.ValidateDimension(cached) == nil {
+		return cached, nil
+	}
+	if err != nil && !database.IsNotFound(err) {
+		s.logger.Warn(ctx, "Failed to read embedding cache", logger.ErrorField(err))
+	}
+
+	vector, err := s.generateEmbedding(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SaveCachedEmbedding(ctx, contentHash, vector); err != nil {
+		s.logger.Warn(ctx, "Failed to save embedding to cache", logger.ErrorField(err))
+	}
+
+	return vector, nil
 }
 
-// generateEmbedding generates a vector embedding for the given text
-// This is a placeholder implementation - in a real application, you would:
-// 1. Use an embedding service (OpenAI, Cohere, etc.)
-// 2. Cache embeddings to avoid repeated API calls
-// 3. Handle rate limiting and errors
+// generateEmbedding generates a vector embedding for the given text and checks that
+// it fits the vector(1536) columns
 func (s *VectorService) generateEmbedding(ctx context.Context, text string) ([]float32, error) {
 	// Normalize text
 	text = strings.TrimSpace(text)
 	if text == "" {
-		return nil, stderrors.New("empty text provided for embedding")
+		return nil, embedding.ErrEmptyText
 	}
 
-	// This is a simplified placeholder implementation
-	// In production, you would call an embedding API like:
-	// - OpenAI's text-embedding-ada-002
-	// - Cohere's embed-multilingual-v3.0
-	// - Hugging Face's sentence-transformers
-
-	// For now, we'll create a simple hash-based embedding
-	// This is NOT suitable for production - just for demonstration
-	embedding := make([]float32, 1536)
-	hash := 0
-	for _, char := range text {
-		hash = (hash*31 + int(char)) % 1536
-		embedding[hash] = float32(char) / 255.0
+	started := time.Now()
+	vector, err := s.embedder.Embed(ctx, text)
+	embeddingDuration.Observe(time.Since(started).Seconds(), s.embedder.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := embedding.ValidateDimension(vector); err != nil {
+		return nil, err
 	}
 
 	s.logger.Debug(ctx, "Generated embedding for text",
 		logger.String("text", text[:myMin(len(text), 50)]),
-		logger.Int("embedding_length", len(embedding)))
+		logger.Int("embedding_length", len(vector)))
 
-	return embedding, nil
+	return vector, nil
 }
 
 // myMin returns the minimum of two integers
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
+	"github.com/MitulShah1/expense-tracker-bot/internal/embedding"
 	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
 	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
 	"github.com/MitulShah1/expense-tracker-bot/internal/models"
@@ -37,6 +39,19 @@ func (m *MockVectorService) UpdateExpenseEmbeddings(ctx context.Context, expense
 	return args.Error(0)
 }
 
+func (m *MockVectorService) EnqueueEmbeddings(ctx context.Context, expenseIDs ...int64) error {
+	args := m.Called(ctx, expenseIDs)
+	return args.Error(0)
+}
+
+func (m *MockVectorService) SearchExpenses(ctx context.Context, telegramID int64, text string, page int) (*models.SearchResult, error) {
+	args := m.Called(ctx, telegramID, text, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SearchResult), args.Error(1)
+}
+
 func (m *MockVectorService) BatchUpdateEmbeddings(ctx context.Context, telegramID int64) error {
 	args := m.Called(ctx, telegramID)
 	return args.Error(0)
@@ -121,7 +136,7 @@ func TestVectorService_SearchExpensesByQuery(t *testing.T) {
 			tt.setupMock(mockDB)
 
 			logger := logger.NewMockLogger()
-			service := NewVectorService(mockDB, logger)
+			service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 			// Execute
 			expenses, err := service.SearchExpensesByQuery(context.Background(), tt.telegramID, tt.query, tt.similarityThreshold, tt.limit)
@@ -188,7 +203,7 @@ func TestVectorService_FindSimilarExpenses(t *testing.T) {
 			tt.setupMock(mockDB)
 
 			logger := logger.NewMockLogger()
-			service := NewVectorService(mockDB, logger)
+			service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 			// Execute
 			expenses, err := service.FindSimilarExpenses(context.Background(), tt.expenseID, tt.similarityThreshold, tt.limit)
@@ -209,6 +224,79 @@ func TestVectorService_FindSimilarExpenses(t *testing.T) {
 	}
 }
 
+func TestVectorService_SearchExpenses(t *testing.T) {
+	ctx := context.Background()
+	categories := []*models.Category{{ID: 1, Name: "Petrol", Group: "Vehicle"}, {ID: 2, Name: "Dining", Group: "Daily Living"}}
+	expenses := []*models.Expense{{ID: 1, CategoryName: "Petrol", TotalPrice: 1500, Notes: "highway trip"}}
+
+	setup := func(embedder embedding.Embedder) (*VectorService, *MockStorage) {
+		mockDB := &MockStorage{}
+		mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(&models.User{ID: 7, TelegramID: 12345}, nil)
+		mockDB.On("GetAllCategories", mock.Anything).Return(categories, nil)
+		mockDB.On("GetCategoriesByUserID", mock.Anything, int64(7)).Return(nil, nil)
+		mockDB.On("GetCachedEmbedding", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows).Maybe()
+		mockDB.On("SaveCachedEmbedding", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		log := logger.NewMockLogger()
+		service := NewVectorService(mockDB, log, embedder)
+		service.SetCategoryService(NewCategoryService(mockDB, log, service))
+		return service, mockDB
+	}
+
+	t.Run("filters and embedded text", func(t *testing.T) {
+		service, mockDB := setup(embedding.NewLocalEmbedder())
+		mockDB.On("SearchExpenses", mock.Anything, mock.MatchedBy(func(search *models.ExpenseSearch) bool {
+			return search.UserID == 7 && search.Text == "highway" && *search.MinAmount == 1000 &&
+				len(search.Categories) == 1 && search.Categories[0].Name == "Petrol" &&
+				len(search.Embedding) == embedding.Dimension && search.Limit == SearchPageSize && search.Offset == SearchPageSize
+		})).Return(expenses, 11, nil)
+
+		result, err := service.SearchExpenses(ctx, 12345, "petrol highway over 1000", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, expenses, result.Expenses)
+		assert.Equal(t, 2, result.Page)
+		assert.Equal(t, 2, result.Pages())
+		assert.Equal(t, "highway", result.Query.Text)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("falls back to full-text search when the text cannot be embedded", func(t *testing.T) {
+		service, mockDB := setup(&countingEmbedder{err: assert.AnError})
+		mockDB.On("SearchExpenses", mock.Anything, mock.MatchedBy(func(search *models.ExpenseSearch) bool {
+			return search.Text == "highway" && search.Embedding == nil
+		})).Return(expenses, 1, nil)
+
+		result, err := service.SearchExpenses(ctx, 12345, "highway", 1)
+		assert.NoError(t, err)
+		assert.Len(t, result.Expenses, 1)
+	})
+
+	t.Run("a page past the end shows the first page", func(t *testing.T) {
+		service, mockDB := setup(embedding.NewLocalEmbedder())
+		mockDB.On("SearchExpenses", mock.Anything, mock.MatchedBy(func(search *models.ExpenseSearch) bool {
+			return search.Offset > 0
+		})).Return([]*models.Expense{}, 0, nil)
+		mockDB.On("SearchExpenses", mock.Anything, mock.MatchedBy(func(search *models.ExpenseSearch) bool {
+			return search.Offset == 0 && search.Embedding == nil && len(search.Groups) == 1
+		})).Return(expenses, 1, nil)
+
+		result, err := service.SearchExpenses(ctx, 12345, "vehicle", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Page)
+		assert.Len(t, result.Expenses, 1)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		service, mockDB := setup(embedding.NewLocalEmbedder())
+		mockDB.On("SearchExpenses", mock.Anything, mock.Anything).Return(nil, 0, assert.AnError)
+
+		_, err := service.SearchExpenses(ctx, 12345, "dining", 1)
+		var appErr *errors.AppError
+		if assert.ErrorAs(t, err, &appErr) {
+			assert.Equal(t, errors.ErrorTypeDatabase, appErr.Type)
+		}
+	})
+}
+
 func TestVectorService_UpdateExpenseEmbeddings(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -228,6 +316,8 @@ func TestVectorService_UpdateExpenseEmbeddings(t *testing.T) {
 					CategoryGroup: "Vehicle",
 				}
 				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(expense, nil)
+				mockDB.On("GetCachedEmbedding", mock.Anything, mock.AnythingOfType("string")).Return(nil, sql.ErrNoRows)
+				mockDB.On("SaveCachedEmbedding", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("[]float32")).Return(nil)
 				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(1), mock.AnythingOfType("[]float32"), mock.AnythingOfType("[]float32")).Return(nil)
 			},
 			expectError: false,
@@ -252,11 +342,48 @@ func TestVectorService_UpdateExpenseEmbeddings(t *testing.T) {
 					CategoryGroup: "Vehicle",
 				}
 				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(expense, nil)
+				mockDB.On("GetCachedEmbedding", mock.Anything, mock.AnythingOfType("string")).Return(nil, sql.ErrNoRows)
+				mockDB.On("SaveCachedEmbedding", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("[]float32")).Return(nil)
 				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(1), mock.AnythingOfType("[]float32"), mock.AnythingOfType("[]float32")).Return(assert.AnError)
 			},
 			expectError: true,
 			errorType:   errors.ErrorTypeDatabase,
 		},
+		{
+			name:      "cached embeddings are reused",
+			expenseID: 1,
+			setupMock: func(mockDB *MockStorage) {
+				expense := &models.Expense{
+					ID:            1,
+					Notes:         "  Petrol   expense ",
+					CategoryName:  "⛽ Petrol",
+					CategoryGroup: "Vehicle",
+				}
+				cached := make([]float32, embedding.Dimension)
+				cached[0] = 1
+				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(expense, nil)
+				mockDB.On("GetCachedEmbedding", mock.Anything, mock.AnythingOfType("string")).Return(cached, nil)
+				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(1), cached, cached).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name:      "notes without words are skipped",
+			expenseID: 1,
+			setupMock: func(mockDB *MockStorage) {
+				expense := &models.Expense{
+					ID:            1,
+					Notes:         "-",
+					CategoryName:  "⛽ Petrol",
+					CategoryGroup: "Vehicle",
+				}
+				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(expense, nil)
+				mockDB.On("GetCachedEmbedding", mock.Anything, mock.AnythingOfType("string")).Return(nil, sql.ErrNoRows)
+				mockDB.On("SaveCachedEmbedding", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("[]float32")).Return(nil)
+				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(1), []float32(nil), mock.AnythingOfType("[]float32")).Return(nil)
+			},
+			expectError: false,
+		},
 		{
 			name:      "expense with empty notes",
 			expenseID: 1,
@@ -268,6 +395,8 @@ func TestVectorService_UpdateExpenseEmbeddings(t *testing.T) {
 					CategoryGroup: "Vehicle",
 				}
 				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(expense, nil)
+				mockDB.On("GetCachedEmbedding", mock.Anything, mock.AnythingOfType("string")).Return(nil, sql.ErrNoRows)
+				mockDB.On("SaveCachedEmbedding", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("[]float32")).Return(nil)
 				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(1), mock.AnythingOfType("[]float32"), mock.AnythingOfType("[]float32")).Return(nil)
 			},
 			expectError: false,
@@ -281,7 +410,7 @@ func TestVectorService_UpdateExpenseEmbeddings(t *testing.T) {
 			tt.setupMock(mockDB)
 
 			logger := logger.NewMockLogger()
-			service := NewVectorService(mockDB, logger)
+			service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 			// Execute
 			err := service.UpdateExpenseEmbeddings(context.Background(), tt.expenseID)
@@ -314,19 +443,9 @@ func TestVectorService_BatchUpdateEmbeddings(t *testing.T) {
 			telegramID: 12345,
 			setupMock: func(mockDB *MockStorage) {
 				user := &models.User{ID: 1, TelegramID: 12345}
-				expenses := []*models.Expense{
-					{ID: 1, Notes: "Petrol", CategoryName: "⛽ Petrol", CategoryGroup: "Vehicle"},
-					{ID: 2, Notes: "Service", CategoryName: "🔧 Service", CategoryGroup: "Vehicle"},
-				}
 
 				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
-				mockDB.On("GetExpensesByUserID", mock.Anything, int64(1)).Return(expenses, nil)
-				mockDB.On("GetExpenseEmbedding", mock.Anything, int64(1)).Return(nil, &errors.AppError{Type: errors.ErrorTypeNotFound})
-				mockDB.On("GetExpenseEmbedding", mock.Anything, int64(2)).Return(nil, &errors.AppError{Type: errors.ErrorTypeNotFound})
-				mockDB.On("GetExpenseByID", mock.Anything, int64(1)).Return(expenses[0], nil)
-				mockDB.On("GetExpenseByID", mock.Anything, int64(2)).Return(expenses[1], nil)
-				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(1), mock.AnythingOfType("[]float32"), mock.AnythingOfType("[]float32")).Return(nil)
-				mockDB.On("UpdateExpenseEmbedding", mock.Anything, int64(2), mock.AnythingOfType("[]float32"), mock.AnythingOfType("[]float32")).Return(nil)
+				mockDB.On("EnqueueMissingEmbeddings", mock.Anything, int64(1)).Return(2, nil)
 			},
 			expectError: false,
 		},
@@ -340,12 +459,12 @@ func TestVectorService_BatchUpdateEmbeddings(t *testing.T) {
 			errorType:   errors.ErrorTypeNotFound,
 		},
 		{
-			name:       "database error getting expenses",
+			name:       "database error queueing expenses",
 			telegramID: 12345,
 			setupMock: func(mockDB *MockStorage) {
 				user := &models.User{ID: 1, TelegramID: 12345}
 				mockDB.On("GetUserByTelegramID", mock.Anything, int64(12345)).Return(user, nil)
-				mockDB.On("GetExpensesByUserID", mock.Anything, int64(1)).Return(nil, assert.AnError)
+				mockDB.On("EnqueueMissingEmbeddings", mock.Anything, int64(1)).Return(0, assert.AnError)
 			},
 			expectError: true,
 			errorType:   errors.ErrorTypeDatabase,
@@ -359,7 +478,7 @@ func TestVectorService_BatchUpdateEmbeddings(t *testing.T) {
 			tt.setupMock(mockDB)
 
 			logger := logger.NewMockLogger()
-			service := NewVectorService(mockDB, logger)
+			service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 			// Execute
 			err := service.BatchUpdateEmbeddings(context.Background(), tt.telegramID)
@@ -417,7 +536,7 @@ func TestVectorService_GenerateEmbedding(t *testing.T) {
 			// Setup
 			mockDB := &MockStorage{}
 			logger := logger.NewMockLogger()
-			service := NewVectorService(mockDB, logger)
+			service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 			// Execute
 			embedding, err := service.generateEmbedding(context.Background(), tt.text)
@@ -448,22 +567,24 @@ func TestVectorService_GenerateEmbedding_Consistency(t *testing.T) {
 	// Test that the same text generates the same embedding
 	mockDB := &MockStorage{}
 	logger := logger.NewMockLogger()
-	service := NewVectorService(mockDB, logger)
+	service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 	text := "petrol"
+	observed := embeddingDuration.Count(service.embedder.Name())
 	embedding1, err1 := service.generateEmbedding(context.Background(), text)
 	embedding2, err2 := service.generateEmbedding(context.Background(), text)
 
 	assert.NoError(t, err1)
 	assert.NoError(t, err2)
 	assert.Equal(t, embedding1, embedding2, "Same text should generate same embedding")
+	assert.Equal(t, observed+2, embeddingDuration.Count(service.embedder.Name()), "Each embedding should be timed")
 }
 
 func TestVectorService_GenerateEmbedding_DifferentTexts(t *testing.T) {
 	// Test that different texts generate different embeddings
 	mockDB := &MockStorage{}
 	logger := logger.NewMockLogger()
-	service := NewVectorService(mockDB, logger)
+	service := NewVectorService(mockDB, logger, embedding.NewLocalEmbedder())
 
 	text1 := "petrol"
 	text2 := "diesel"
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/MitulShah1/expense-tracker-bot/internal/validation"
+)
+
+// maintenanceCategories count towards the cost per km together with fuel
+var maintenanceCategories = map[string]bool{
+	"Service":   true,
+	"Repairs":   true,
+	"Insurance": true,
+}
+
+// VehicleService manages a user's vehicles and computes fuel efficiency and running costs per vehicle
+type VehicleService struct {
+	db              database.Storage
+	logger          logger.Logger
+	validator       *validation.Validator
+	currencyService *CurrencyService
+}
+
+// NewVehicleService creates a new vehicle service that reports costs converted by currencyService
+func NewVehicleService(db database.Storage, logger logger.Logger, currencyService *CurrencyService) *VehicleService {
+	return &VehicleService{
+		db:              db,
+		logger:          logger,
+		validator:       validation.NewValidator(),
+		currencyService: currencyService,
+	}
+}
+
+// AddVehicle adds a vehicle for the user. Names must be unique per user, ignoring case; the fuel
+// type defaults to petrol.
+func (s *VehicleService) AddVehicle(ctx context.Context, telegramID int64, vehicle *models.Vehicle) error {
+	vehicle.Name = strings.TrimSpace(vehicle.Name)
+	if vehicle.FuelType == "" {
+		vehicle.FuelType = models.FuelTypePetrol
+	}
+	if err := s.validator.ValidateVehicleName(vehicle.Name); err != nil {
+		return err
+	}
+	if err := s.validator.ValidateVehicleType(string(vehicle.Type)); err != nil {
+		return err
+	}
+	if err := s.validator.ValidateFuelType(string(vehicle.FuelType)); err != nil {
+		return err
+	}
+	if err := s.validator.ValidateRegistration(vehicle.Registration); err != nil {
+		return err
+	}
+	if err := s.validator.ValidateOdometer(vehicle.InitialOdometer); err != nil {
+		return err
+	}
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	vehicles, err := s.db.GetVehiclesByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get vehicles", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to get vehicles", err)
+	}
+	if findVehicleByName(vehicles, vehicle.Name) != nil {
+		return errors.NewValidationError("Vehicle already exists", fmt.Sprintf("You already have a vehicle named %s", vehicle.Name))
+	}
+
+	vehicle.UserID = user.ID
+	if err := s.db.CreateVehicle(ctx, vehicle); err != nil {
+		s.logger.Error(ctx, "Failed to create vehicle", logger.ErrorField(err))
+		return errors.NewDatabaseError("Failed to create vehicle", err)
+	}
+
+	s.logger.Info(ctx, "Vehicle added",
+		logger.Int("user_id", int(user.ID)),
+		logger.Int("vehicle_id", int(vehicle.ID)))
+	return nil
+}
+
+// GetVehicles returns the user's vehicles in the order they were added
+func (s *VehicleService) GetVehicles(ctx context.Context, telegramID int64) ([]*models.Vehicle, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles, err := s.db.GetVehiclesByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get vehicles", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get vehicles", err)
+	}
+	return vehicles, nil
+}
+
+// RemoveVehicle removes the user's vehicle with the given name, ignoring case. Its expenses are
+// kept and reported by vehicle type.
+func (s *VehicleService) RemoveVehicle(ctx context.Context, telegramID int64, name string) (*models.Vehicle, error) {
+	vehicles, err := s.GetVehicles(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle := findVehicleByName(vehicles, name)
+	if vehicle == nil {
+		return nil, errors.NewNotFoundError("Vehicle not found", fmt.Sprintf("You have no vehicle named %s", strings.TrimSpace(name)))
+	}
+
+	if err := s.db.DeleteVehicle(ctx, vehicle.ID, vehicle.UserID); err != nil {
+		s.logger.Error(ctx, "Failed to delete vehicle", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to delete vehicle", err)
+	}
+	return vehicle, nil
+}
+
+// GetVehicleStats returns the stats of each vehicle the user has expenses for, in the order the
+// vehicles were added, with costs in the given currency. Expenses without a vehicle are not counted.
+func (s *VehicleService) GetVehicleStats(ctx context.Context, telegramID int64, currency string) ([]*models.VehicleStats, error) {
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles, err := s.db.GetVehiclesByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get vehicles for vehicle stats", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get vehicles", err)
+	}
+
+	expenses, err := s.db.GetExpensesByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get expenses for vehicle stats", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get expenses", err)
+	}
+
+	if s.currencyService != nil && currency != "" {
+		s.currencyService.ConvertExpenses(ctx, expenses, currency)
+	}
+
+	return ComputeVehicleStats(vehicles, expenses), nil
+}
+
+// getUser looks up the user with the given Telegram ID
+func (s *VehicleService) getUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	if err := s.validator.ValidateTelegramID(telegramID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get user by Telegram ID", logger.ErrorField(err))
+		return nil, errors.NewDatabaseError("Failed to get user", err)
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("User not found", fmt.Sprintf("User with Telegram ID %d not found", telegramID))
+	}
+	return user, nil
+}
+
+// findVehicleByName returns the vehicle with the given name, ignoring case, or nil
+func findVehicleByName(vehicles []*models.Vehicle, name string) *models.Vehicle {
+	name = strings.TrimSpace(name)
+	for _, vehicle := range vehicles {
+		if strings.EqualFold(vehicle.Name, name) {
+			return vehicle
+		}
+	}
+	return nil
+}
+
+// vehicleTypeOrder orders expenses that are not linked to a vehicle after the named vehicles
+var vehicleTypeOrder = map[string]int{
+	string(models.VehicleTypeCar):     1,
+	string(models.VehicleTypeBike):    2,
+	string(models.VehicleTypeScooter): 3,
+	string(models.VehicleTypeOther):   4,
+}
+
+// ComputeVehicleStats computes the stats of each vehicle with expenses, in the order of vehicles.
+// Expenses not linked to one of the vehicles, e.g. because it was removed, are grouped by vehicle
+// type after them, cars first.
+func ComputeVehicleStats(vehicles []*models.Vehicle, expenses []*models.Expense) []*models.VehicleStats {
+	known := make(map[int64]bool, len(vehicles))
+	for _, vehicle := range vehicles {
+		known[vehicle.ID] = true
+	}
+
+	byVehicle := make(map[int64][]*models.Expense)
+	byType := make(map[string][]*models.Expense)
+	for _, expense := range expenses {
+		switch {
+		case expense.VehicleID.Valid && known[expense.VehicleID.Int64]:
+			byVehicle[expense.VehicleID.Int64] = append(byVehicle[expense.VehicleID.Int64], expense)
+		case expense.VehicleType.Valid && expense.VehicleType.String != "":
+			byType[expense.VehicleType.String] = append(byType[expense.VehicleType.String], expense)
+		}
+	}
+
+	stats := make([]*models.VehicleStats, 0, len(byVehicle)+len(byType))
+	for _, vehicle := range vehicles {
+		if len(byVehicle[vehicle.ID]) > 0 {
+			stats = append(stats, computeVehicle(vehicle, byVehicle[vehicle.ID]))
+		}
+	}
+
+	vehicleTypes := make([]string, 0, len(byType))
+	for vehicleType := range byType {
+		vehicleTypes = append(vehicleTypes, vehicleType)
+	}
+	sort.Slice(vehicleTypes, func(i, j int) bool {
+		oi, oj := vehicleTypeOrder[vehicleTypes[i]], vehicleTypeOrder[vehicleTypes[j]]
+		if oi == 0 {
+			oi = len(vehicleTypeOrder) + 1
+		}
+		if oj == 0 {
+			oj = len(vehicleTypeOrder) + 1
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return vehicleTypes[i] < vehicleTypes[j]
+	})
+	for _, vehicleType := range vehicleTypes {
+		legacy := &models.Vehicle{Type: models.VehicleType(vehicleType), FuelType: models.FuelTypePetrol}
+		stats = append(stats, computeVehicle(legacy, byType[vehicleType]))
+	}
+	return stats
+}
+
+// computeVehicle computes the stats of one vehicle. Distance is the sum of the increases between
+// consecutive odometer readings, starting from the vehicle's initial odometer; a reading lower than
+// an earlier one is flagged and skipped rather than counted as negative distance. Fuel efficiency
+// assumes each fill or charge replaces what was used since the previous one, so the first fill's
+// quantity is not counted.
+func computeVehicle(vehicle *models.Vehicle, expenses []*models.Expense) *models.VehicleStats {
+	sorted := append([]*models.Expense(nil), expenses...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	stats := &models.VehicleStats{
+		VehicleID:    vehicle.ID,
+		Name:         vehicle.Name,
+		VehicleType:  string(vehicle.Type),
+		FuelType:     vehicle.FuelType,
+		FirstReading: vehicle.InitialOdometer,
+		LastReading:  vehicle.InitialOdometer,
+	}
+	var lastFill, efficiencyDistance, efficiencyQuantity float64
+
+	for _, expense := range sorted {
+		fuel := expense.IsFuel()
+		quantity := 0.0
+		switch {
+		case fuel:
+			stats.Fills++
+			stats.FuelCost += expense.TotalPrice
+			if expense.PetrolPrice > 0 {
+				quantity = paidAmount(expense) / expense.PetrolPrice
+				stats.FuelQuantity += quantity
+			}
+		case maintenanceCategories[expense.CategoryName]:
+			stats.MaintenanceCost += expense.TotalPrice
+		default:
+			stats.OtherCost += expense.TotalPrice
+		}
+
+		reading := expense.Odometer
+		if reading <= 0 {
+			continue
+		}
+		if stats.LastReading == 0 {
+			stats.FirstReading = reading
+		} else if reading < stats.LastReading {
+			stats.Regressions = append(stats.Regressions, models.OdometerRegression{
+				ExpenseID: expense.ID,
+				Timestamp: expense.Timestamp,
+				Previous:  stats.LastReading,
+				Reading:   reading,
+			})
+			continue
+		} else {
+			stats.Distance += reading - stats.LastReading
+		}
+		stats.LastReading = reading
+
+		if fuel {
+			if lastFill > 0 && quantity > 0 {
+				efficiencyDistance += reading - lastFill
+				efficiencyQuantity += quantity
+			}
+			lastFill = reading
+		}
+	}
+
+	if efficiencyQuantity > 0 {
+		stats.FuelEfficiency = efficiencyDistance / efficiencyQuantity
+	}
+	if stats.Distance > 0 {
+		stats.CostPerKm = (stats.FuelCost + stats.MaintenanceCost) / stats.Distance
+	}
+	return stats
+}
+
+// paidAmount returns the amount as paid at the pump or charger, in the currency of the unit price
+func paidAmount(expense *models.Expense) float64 {
+	if expense.OriginalAmount > 0 {
+		return expense.OriginalAmount
+	}
+	return expense.TotalPrice
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
+	"github.com/MitulShah1/expense-tracker-bot/internal/logger"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vehicleExpense(id int64, vehicle, category string, day int, amount, odometer, price float64) *models.Expense {
+	return &models.Expense{
+		ID:           id,
+		VehicleType:  sql.NullString{String: vehicle, Valid: vehicle != ""},
+		CategoryName: category,
+		TotalPrice:   amount,
+		Odometer:     odometer,
+		PetrolPrice:  price,
+		Timestamp:    time.Date(2026, time.March, day, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestComputeVehicleStats(t *testing.T) {
+	expenses := []*models.Expense{
+		// Out of order on purpose: stats follow the timestamps
+		vehicleExpense(3, "CAR", "Petrol", 20, 2000, 10800, 100),
+		vehicleExpense(1, "CAR", "Petrol", 1, 2000, 10000, 100),
+		vehicleExpense(2, "CAR", "Petrol", 10, 1000, 10400, 100),
+		vehicleExpense(4, "CAR", "Service", 21, 3000, 0, 0),
+		vehicleExpense(5, "CAR", "Toll", 22, 200, 0, 0),
+		vehicleExpense(6, "CAR", "Petrol", 25, 500, 1090, 100), // typo for 10900
+		vehicleExpense(7, "BIKE", "Petrol", 2, 300, 5000, 100),
+		vehicleExpense(8, "BIKE", "Petrol", 12, 200, 5100, 100),
+		vehicleExpense(9, "", "Dining", 3, 450, 0, 0),
+	}
+
+	stats := ComputeVehicleStats(nil, expenses)
+	require.Len(t, stats, 2)
+
+	car := stats[0]
+	assert.Equal(t, "CAR", car.VehicleType)
+	assert.Equal(t, 4, car.Fills)
+	assert.Equal(t, 800.0, car.Distance)
+	assert.Equal(t, 10000.0, car.FirstReading)
+	assert.Equal(t, 10800.0, car.LastReading)
+	assert.InDelta(t, 55.0, car.FuelQuantity, 1e-9)
+	assert.Equal(t, 5500.0, car.FuelCost)
+	assert.Equal(t, 3000.0, car.MaintenanceCost)
+	assert.Equal(t, 200.0, car.OtherCost)
+	assert.Equal(t, 8700.0, car.TotalCost())
+	// 800 km on the 30 L bought after the first fill
+	assert.InDelta(t, 800.0/30, car.FuelEfficiency, 1e-9)
+	assert.InDelta(t, 8500.0/800, car.CostPerKm, 1e-9)
+	require.Len(t, car.Regressions, 1)
+	assert.Equal(t, models.OdometerRegression{ExpenseID: 6, Timestamp: expenses[5].Timestamp, Previous: 10800, Reading: 1090}, car.Regressions[0])
+
+	bike := stats[1]
+	assert.Equal(t, "BIKE", bike.VehicleType)
+	assert.Equal(t, 100.0, bike.Distance)
+	assert.InDelta(t, 50.0, bike.FuelEfficiency, 1e-9)
+	assert.Empty(t, bike.Regressions)
+
+	t.Run("foreign currency fills use the amount paid", func(t *testing.T) {
+		fill := vehicleExpense(1, "CAR", "Petrol", 1, 9000, 0, 1.8)
+		fill.Currency, fill.OriginalAmount = "EUR", 90
+		stats := ComputeVehicleStats(nil, []*models.Expense{fill})
+		assert.InDelta(t, 50.0, stats[0].FuelQuantity, 1e-9)
+	})
+
+	t.Run("no readings means no efficiency or cost per km", func(t *testing.T) {
+		stats := ComputeVehicleStats(nil, []*models.Expense{vehicleExpense(1, "BIKE", "Insurance", 1, 1500, 0, 0)})
+		require.Len(t, stats, 1)
+		assert.Zero(t, stats[0].Distance)
+		assert.Zero(t, stats[0].FuelEfficiency)
+		assert.Zero(t, stats[0].CostPerKm)
+		assert.Equal(t, 1500.0, stats[0].MaintenanceCost)
+	})
+
+	t.Run("named vehicles come first and start at their initial odometer", func(t *testing.T) {
+		vehicles := []*models.Vehicle{
+			{ID: 10, Name: "Swift", Type: models.VehicleTypeCar, FuelType: models.FuelTypePetrol},
+			{ID: 11, Name: "Nexon EV", Type: models.VehicleTypeCar, FuelType: models.FuelTypeElectric, InitialOdometer: 2000},
+			{ID: 12, Name: "Unused", Type: models.VehicleTypeBike},
+		}
+		linked := func(expense *models.Expense, vehicleID int64) *models.Expense {
+			expense.VehicleID = sql.NullInt64{Int64: vehicleID, Valid: true}
+			return expense
+		}
+		stats := ComputeVehicleStats(vehicles, []*models.Expense{
+			linked(vehicleExpense(1, "CAR", "EV Charging", 1, 300, 2100, 10), 11),
+			linked(vehicleExpense(2, "CAR", "EV Charging", 5, 200, 2250, 10), 11),
+			linked(vehicleExpense(3, "CAR", "Petrol", 2, 1000, 100, 100), 10),
+			// The vehicle was removed: reported by type
+			linked(vehicleExpense(4, "CAR", "Toll", 3, 50, 0, 0), 99),
+		})
+		require.Len(t, stats, 3)
+
+		assert.Equal(t, "Swift", stats[0].Name)
+		assert.Equal(t, int64(10), stats[0].VehicleID)
+		assert.Equal(t, 10.0, stats[0].FuelQuantity)
+
+		ev := stats[1]
+		assert.Equal(t, "Nexon EV", ev.Name)
+		assert.Equal(t, models.FuelTypeElectric, ev.FuelType)
+		assert.Equal(t, 2000.0, ev.FirstReading)
+		assert.Equal(t, 250.0, ev.Distance)
+		assert.Equal(t, 2, ev.Fills)
+		assert.InDelta(t, 50.0, ev.FuelQuantity, 1e-9)
+		// 150 km on the 20 kWh charged after the first charge
+		assert.InDelta(t, 7.5, ev.FuelEfficiency, 1e-9)
+
+		assert.Empty(t, stats[2].Name)
+		assert.Equal(t, "CAR", stats[2].VehicleType)
+		assert.Equal(t, 50.0, stats[2].OtherCost)
+	})
+}
+
+func TestVehicleService_GetVehicleStats(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	user := &models.User{TelegramID: 12345}
+	require.NoError(t, storage.CreateUser(ctx, user))
+	for _, expense := range []*models.Expense{
+		vehicleExpense(0, "BIKE", "Petrol", 1, 300, 5000, 100),
+		vehicleExpense(0, "BIKE", "Petrol", 5, 300, 5150, 100),
+	} {
+		expense.UserID = user.ID
+		require.NoError(t, storage.CreateExpense(ctx, expense))
+	}
+
+	log := logger.NewMockLogger()
+	service := NewVehicleService(storage, log, NewCurrencyService(storage, log))
+	stats, err := service.GetVehicleStats(ctx, 12345, "INR")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, 150.0, stats[0].Distance)
+	assert.InDelta(t, 50.0, stats[0].FuelEfficiency, 1e-9)
+
+	_, err = service.GetVehicleStats(ctx, 999, "INR")
+	assert.Error(t, err)
+}
+
+func TestVehicleService_AddAndRemoveVehicle(t *testing.T) {
+	ctx := context.Background()
+	storage := database.NewMockStorage()
+	require.NoError(t, storage.CreateUser(ctx, &models.User{TelegramID: 12345}))
+	service := NewVehicleService(storage, logger.NewMockLogger(), nil)
+
+	swift := &models.Vehicle{Name: " Swift ", Type: models.VehicleTypeCar}
+	require.NoError(t, service.AddVehicle(ctx, 12345, swift))
+	assert.Equal(t, "Swift", swift.Name)
+	assert.Equal(t, models.FuelTypePetrol, swift.FuelType)
+	require.NoError(t, service.AddVehicle(ctx, 12345, &models.Vehicle{Name: "Ather", Type: models.VehicleTypeScooter, FuelType: models.FuelTypeElectric}))
+
+	for _, invalid := range []*models.Vehicle{
+		{Name: "SWIFT", Type: models.VehicleTypeCar}, // Names are unique ignoring case
+		{Name: "Truck", Type: "TRUCK"},
+		{Name: "Van", Type: models.VehicleTypeOther, FuelType: "HYDROGEN"},
+		{Name: " ", Type: models.VehicleTypeCar},
+	} {
+		err := service.AddVehicle(ctx, 12345, invalid)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok, "expected AppError for %q, got %v", invalid.Name, err)
+		assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+	}
+
+	vehicles, err := service.GetVehicles(ctx, 12345)
+	require.NoError(t, err)
+	require.Len(t, vehicles, 2)
+	assert.Equal(t, "Swift", vehicles[0].Name)
+	assert.Equal(t, "Ather", vehicles[1].Name)
+
+	removed, err := service.RemoveVehicle(ctx, 12345, "ather")
+	require.NoError(t, err)
+	assert.Equal(t, "Ather", removed.Name)
+
+	_, err = service.RemoveVehicle(ctx, 12345, "ather")
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+}
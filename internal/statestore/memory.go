@@ -0,0 +1,108 @@
+package statestore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// stateKey identifies a conversation with one user in one chat
+type stateKey struct {
+	chatID int64
+	userID int64
+}
+
+// storedState is a conversation serialized as JSON, as PostgresStore keeps it
+type storedState struct {
+	data         []byte
+	lastActivity time.Time
+}
+
+// MemoryStore keeps conversations in process memory. They are lost on restart and not shared
+// between replicas, so it suits a single instance and tests.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	states map[stateKey]storedState
+	ttl    time.Duration
+}
+
+// NewMemoryStore creates an empty store whose conversations expire after ttl without activity
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		states: make(map[stateKey]storedState),
+		ttl:    ttl,
+	}
+}
+
+// Get loads the conversation. Like PostgresStore, each call returns a new copy; changes to it
+// are only kept once it is Set again.
+func (s *MemoryStore) Get(ctx context.Context, chatID, userID int64) (*models.UserState, error) {
+	s.mu.RLock()
+	stored, ok := s.states[stateKey{chatID: chatID, userID: userID}]
+	s.mu.RUnlock()
+	if !ok || expired(stored.lastActivity, s.ttl) {
+		return nil, nil
+	}
+
+	var state models.UserState
+	if err := json.Unmarshal(stored.data, &state); err != nil {
+		return nil, fmt.Errorf("failed to load conversation state: %w", err)
+	}
+	return &state, nil
+}
+
+// Set saves a copy of the conversation, which expires once it has been idle for the TTL
+func (s *MemoryStore) Set(ctx context.Context, chatID, userID int64, state *models.UserState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to save conversation state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[stateKey{chatID: chatID, userID: userID}] = storedState{data: data, lastActivity: state.LastActivity}
+	return nil
+}
+
+// Delete removes the conversation
+func (s *MemoryStore) Delete(ctx context.Context, chatID, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, stateKey{chatID: chatID, userID: userID})
+	return nil
+}
+
+// DeleteExpired removes conversations idle for longer than the TTL
+func (s *MemoryStore) DeleteExpired(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for key, stored := range s.states {
+		if expired(stored.lastActivity, s.ttl) {
+			delete(s.states, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Count counts the conversations idle for no longer than the TTL
+func (s *MemoryStore) Count(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, stored := range s.states {
+		if !expired(stored.lastActivity, s.ttl) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,69 @@
+package statestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// PostgresStore keeps conversations serialized as JSON in the user_states table, so they
+// survive restarts and every replica sees the same conversation
+type PostgresStore struct {
+	db  database.UserStateStorage
+	ttl time.Duration
+}
+
+// NewPostgresStore creates a store whose conversations expire after ttl without activity
+func NewPostgresStore(db database.UserStateStorage, ttl time.Duration) *PostgresStore {
+	return &PostgresStore{db: db, ttl: ttl}
+}
+
+// Get loads the conversation. Each call returns a new copy; changes to it are only kept once
+// it is Set again.
+func (s *PostgresStore) Get(ctx context.Context, chatID, userID int64) (*models.UserState, error) {
+	state, err := s.db.GetUserState(ctx, chatID, userID)
+	if database.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation state: %w", err)
+	}
+	return state, nil
+}
+
+// Set saves the conversation, which expires once it has been idle for the TTL
+func (s *PostgresStore) Set(ctx context.Context, chatID, userID int64, state *models.UserState) error {
+	if err := s.db.SaveUserState(ctx, chatID, userID, state, state.LastActivity.Add(s.ttl)); err != nil {
+		return fmt.Errorf("failed to save conversation state: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the conversation
+func (s *PostgresStore) Delete(ctx context.Context, chatID, userID int64) error {
+	if err := s.db.DeleteUserState(ctx, chatID, userID); err != nil {
+		return fmt.Errorf("failed to delete conversation state: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired purges expired conversations from the table
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int, error) {
+	purged, err := s.db.DeleteExpiredUserStates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired conversation states: %w", err)
+	}
+	return purged, nil
+}
+
+// Count counts the conversations in the table that have not expired
+func (s *PostgresStore) Count(ctx context.Context) (int, error) {
+	count, err := s.db.CountUserStates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count conversation states: %w", err)
+	}
+	return count, nil
+}
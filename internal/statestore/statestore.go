@@ -0,0 +1,69 @@
+// Package statestore keeps the conversation state of users between updates, in memory or in
+// PostgreSQL so conversations survive restarts and are shared by every replica of the bot.
+package statestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+)
+
+// Supported state store providers
+const (
+	ProviderMemory   = "memory"
+	ProviderPostgres = "postgres"
+)
+
+// DefaultTTL is how long a conversation lasts without activity
+const DefaultTTL = 30 * time.Minute
+
+// Store keeps the conversation with a user in a chat. A conversation expires once it has been
+// idle, going by its LastActivity, for longer than the store's TTL.
+type Store interface {
+	// Get returns a copy of the conversation, or nil when there is none or it has expired.
+	// Changes to the copy are only kept once it is Set again.
+	Get(ctx context.Context, chatID, userID int64) (*models.UserState, error)
+	// Set creates or replaces the conversation with a copy of state
+	Set(ctx context.Context, chatID, userID int64, state *models.UserState) error
+	// Delete ends the conversation; deleting a missing one is not an error
+	Delete(ctx context.Context, chatID, userID int64) error
+	// DeleteExpired purges expired conversations and returns how many there were
+	DeleteExpired(ctx context.Context) (int, error)
+	// Count returns how many conversations have not expired
+	Count(ctx context.Context) (int, error)
+}
+
+// Config selects and configures a state store
+type Config struct {
+	Provider string        // memory or postgres
+	TTL      time.Duration // How long a conversation lasts without activity, DefaultTTL when zero
+}
+
+// New creates the state store selected by the config. The postgres store keeps conversations in db.
+func New(cfg Config, db database.UserStateStorage) (Store, error) {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderMemory:
+		return NewMemoryStore(ttl), nil
+	case "", ProviderPostgres:
+		if db == nil {
+			return nil, errors.New("the postgres state store needs a database")
+		}
+		return NewPostgresStore(db, ttl), nil
+	}
+	return nil, fmt.Errorf("unknown state store provider %q", cfg.Provider)
+}
+
+// expired reports whether a conversation last active at lastActivity has been idle for longer than ttl
+func expired(lastActivity time.Time, ttl time.Duration) bool {
+	return time.Since(lastActivity) > ttl
+}
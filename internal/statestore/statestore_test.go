@@ -0,0 +1,112 @@
+package statestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MitulShah1/expense-tracker-bot/internal/database"
+	"github.com/MitulShah1/expense-tracker-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	store, err := New(Config{Provider: "Memory"}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+
+	store, err = New(Config{}, database.NewMockStorage())
+	require.NoError(t, err)
+	assert.IsType(t, &PostgresStore{}, store)
+	assert.Equal(t, DefaultTTL, store.(*PostgresStore).ttl)
+
+	_, err = New(Config{Provider: ProviderPostgres}, nil)
+	assert.Error(t, err, "a database is required")
+
+	_, err = New(Config{Provider: "redis"}, nil)
+	assert.Error(t, err)
+}
+
+func TestStores(t *testing.T) {
+	for name, newStore := range map[string]func(ttl time.Duration) Store{
+		"memory":   func(ttl time.Duration) Store { return NewMemoryStore(ttl) },
+		"postgres": func(ttl time.Duration) Store { return NewPostgresStore(database.NewMockStorage(), ttl) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(time.Hour)
+
+			state, err := store.Get(ctx, -100, 42)
+			require.NoError(t, err)
+			assert.Nil(t, state)
+
+			saved := models.NewUserState()
+			saved.Step = models.StepNotes
+			saved.TempExpense = &models.Expense{CategoryName: "Dining", TotalPrice: 450, Currency: "INR", Timestamp: time.Now().Truncate(time.Second)}
+			saved.ExpenseSelection = []*models.Expense{{ID: 7, CategoryName: "Fuel", TotalPrice: 1200}}
+			saved.ReceiptKey = "staged/-100/42/receipt"
+			require.NoError(t, store.Set(ctx, -100, 42, saved))
+
+			state, err = store.Get(ctx, -100, 42)
+			require.NoError(t, err)
+			require.NotNil(t, state)
+			assert.Equal(t, models.StepNotes, state.Step)
+			assert.Equal(t, "Dining", state.TempExpense.CategoryName)
+			assert.Equal(t, 450.0, state.TempExpense.TotalPrice)
+			assert.True(t, saved.TempExpense.Timestamp.Equal(state.TempExpense.Timestamp))
+			require.Len(t, state.ExpenseSelection, 1)
+			assert.Equal(t, int64(7), state.ExpenseSelection[0].ID)
+			assert.Equal(t, "staged/-100/42/receipt", state.ReceiptKey)
+
+			// Changes are only kept once the conversation is set again
+			saved.Step = models.StepCategory
+			state.TempExpense.TotalPrice = 500
+			state.ExpenseSelection[0].ID = 8
+			unsaved, err := store.Get(ctx, -100, 42)
+			require.NoError(t, err)
+			assert.Equal(t, models.StepNotes, unsaved.Step)
+			assert.Equal(t, 450.0, unsaved.TempExpense.TotalPrice)
+			assert.Equal(t, int64(7), unsaved.ExpenseSelection[0].ID)
+
+			require.NoError(t, store.Set(ctx, -100, 42, state))
+			state.TempExpense.TotalPrice = 600
+			state, err = store.Get(ctx, -100, 42)
+			require.NoError(t, err)
+			assert.Equal(t, 500.0, state.TempExpense.TotalPrice)
+
+			// Conversations are per user and chat
+			state, err = store.Get(ctx, 42, 42)
+			require.NoError(t, err)
+			assert.Nil(t, state)
+
+			require.NoError(t, store.Delete(ctx, -100, 42))
+			state, err = store.Get(ctx, -100, 42)
+			require.NoError(t, err)
+			assert.Nil(t, state)
+			require.NoError(t, store.Delete(ctx, -100, 42))
+		})
+
+		t.Run(name+" expires idle conversations", func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(time.Minute)
+
+			idle := models.NewUserState()
+			idle.LastActivity = time.Now().Add(-2 * time.Minute)
+			require.NoError(t, store.Set(ctx, 1, 1, idle))
+			require.NoError(t, store.Set(ctx, 2, 2, models.NewUserState()))
+
+			state, err := store.Get(ctx, 1, 1)
+			require.NoError(t, err)
+			assert.Nil(t, state)
+
+			purged, err := store.DeleteExpired(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, purged)
+
+			state, err = store.Get(ctx, 2, 2)
+			require.NoError(t, err)
+			assert.NotNil(t, state)
+		})
+	}
+}
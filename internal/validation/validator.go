@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/MitulShah1/expense-tracker-bot/internal/errors"
 )
@@ -151,9 +152,32 @@ func (v *Validator) ValidateCategoryName(categoryName string) error {
 	return nil
 }
 
+// ValidateCustomCategoryName validates the name of a user's own category or group. Names are kept
+// short because they are sent back in keyboard callback data, which Telegram limits to 64 bytes.
+func (v *Validator) ValidateCustomCategoryName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.NewValidationError("Name is required", "Category and group names cannot be empty")
+	}
+
+	if len(name) > 32 {
+		return errors.NewValidationError("Name too long", "Category and group names must be 32 characters or less")
+	}
+
+	return nil
+}
+
+// ValidateEmoji validates the emoji of a category or group
+func (v *Validator) ValidateEmoji(emoji string) error {
+	if emoji == "" || len(emoji) > 16 || strings.IndexFunc(emoji, unicode.IsLetter) >= 0 || strings.IndexFunc(emoji, unicode.IsSpace) >= 0 {
+		return errors.NewValidationError("Invalid emoji", "Use a single emoji, like 🐶")
+	}
+
+	return nil
+}
+
 // ValidateVehicleType validates a vehicle type
 func (v *Validator) ValidateVehicleType(vehicleType string) error {
-	validTypes := []string{"CAR", "BIKE"}
+	validTypes := []string{"CAR", "BIKE", "SCOOTER", "OTHER"}
 
 	for _, validType := range validTypes {
 		if vehicleType == validType {
@@ -164,6 +188,144 @@ func (v *Validator) ValidateVehicleType(vehicleType string) error {
 	return errors.NewValidationError("Invalid vehicle type", "Vehicle type must be one of: "+strings.Join(validTypes, ", "))
 }
 
+// ValidateFuelType validates a vehicle fuel type
+func (v *Validator) ValidateFuelType(fuelType string) error {
+	validTypes := []string{"PETROL", "DIESEL", "CNG", "ELECTRIC"}
+
+	for _, validType := range validTypes {
+		if fuelType == validType {
+			return nil
+		}
+	}
+
+	return errors.NewValidationError("Invalid fuel type", "Fuel type must be one of: "+strings.Join(validTypes, ", "))
+}
+
+// ValidateVehicleName validates a vehicle name
+func (v *Validator) ValidateVehicleName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.NewValidationError("Vehicle name is required", "Vehicle name cannot be empty")
+	}
+
+	if len(name) > 50 {
+		return errors.NewValidationError("Vehicle name too long", "Vehicle name must be 50 characters or less")
+	}
+
+	return nil
+}
+
+// ValidateRegistration validates a vehicle registration number
+func (v *Validator) ValidateRegistration(registration string) error {
+	if len(registration) > 20 {
+		return errors.NewValidationError("Registration too long", "Registration must be 20 characters or less")
+	}
+
+	return nil
+}
+
+// ValidateLedgerName validates a shared ledger name
+func (v *Validator) ValidateLedgerName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.NewValidationError("Ledger name is required", "Ledger name cannot be empty")
+	}
+
+	if len(name) > 50 {
+		return errors.NewValidationError("Ledger name too long", "Ledger name must be 50 characters or less")
+	}
+
+	return nil
+}
+
+// ValidateLedgerRole validates a ledger member role
+func (v *Validator) ValidateLedgerRole(role string) error {
+	validRoles := []string{"OWNER", "EDITOR", "VIEWER"}
+
+	for _, validRole := range validRoles {
+		if role == validRole {
+			return nil
+		}
+	}
+
+	return errors.NewValidationError("Invalid role", "Role must be one of: "+strings.Join(validRoles, ", "))
+}
+
+// ValidateBudgetPeriod validates a budget period
+func (v *Validator) ValidateBudgetPeriod(period string) error {
+	validPeriods := []string{"daily", "weekly", "monthly", "yearly"}
+
+	for _, validPeriod := range validPeriods {
+		if period == validPeriod {
+			return nil
+		}
+	}
+
+	return errors.NewValidationError("Invalid budget period", "Budget period must be one of: "+strings.Join(validPeriods, ", "))
+}
+
+// ValidateRecurringInterval validates a recurring expense interval
+func (v *Validator) ValidateRecurringInterval(interval string) error {
+	validIntervals := []string{"daily", "weekly", "monthly", "yearly"}
+
+	for _, validInterval := range validIntervals {
+		if interval == validInterval {
+			return nil
+		}
+	}
+
+	return errors.NewValidationError("Invalid recurring interval", "Interval must be one of: "+strings.Join(validIntervals, ", "))
+}
+
+// ValidateReminderSchedule validates a reminder schedule and, for weekly reminders, its day of week
+func (v *Validator) ValidateReminderSchedule(schedule string, dayOfWeek int) error {
+	switch schedule {
+	case "daily":
+		return nil
+	case "weekly":
+		if dayOfWeek < 0 || dayOfWeek > 6 {
+			return errors.NewValidationError("Invalid day of week", "Day of week must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		return nil
+	default:
+		return errors.NewValidationError("Invalid reminder schedule", "Reminder schedule must be one of: daily, weekly")
+	}
+}
+
+// ValidateTimeOfDay validates an hour and minute
+func (v *Validator) ValidateTimeOfDay(hour, minute int) error {
+	if hour < 0 || hour > 23 {
+		return errors.NewValidationError("Invalid hour", "Hour must be between 0 and 23")
+	}
+
+	if minute < 0 || minute > 59 {
+		return errors.NewValidationError("Invalid minute", "Minute must be between 0 and 59")
+	}
+
+	return nil
+}
+
+// ValidateReminderMessage validates a reminder message
+func (v *Validator) ValidateReminderMessage(message string) error {
+	if strings.TrimSpace(message) == "" {
+		return errors.NewValidationError("Reminder message is required", "Reminder message cannot be empty")
+	}
+
+	if len(message) > 500 {
+		return errors.NewValidationError("Reminder message too long", "Reminder message must be 500 characters or less")
+	}
+
+	return nil
+}
+
+// ValidateCurrencyCode validates an ISO 4217 currency code such as INR or EUR
+func (v *Validator) ValidateCurrencyCode(code string) error {
+	currencyRegex := regexp.MustCompile(`^[A-Z]{3}$`)
+	if !currencyRegex.MatchString(code) {
+		return errors.NewValidationError("Invalid currency code", "Currency must be a three-letter ISO code such as INR, USD or EUR")
+	}
+
+	return nil
+}
+
 // ValidateDate validates a date
 func (v *Validator) ValidateDate(date time.Time, fieldName string) error {
 	if date.IsZero() {
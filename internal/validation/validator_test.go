@@ -321,6 +321,57 @@ func TestValidateCategoryName(t *testing.T) {
 	}
 }
 
+func TestValidateCustomCategoryName(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name     string
+		category string
+		wantErr  bool
+	}{
+		{"valid", "Pet Food", false},
+		{"32 bytes", strings.Repeat("a", 32), false},
+		{"empty", "", true},
+		{"blank", "   ", true},
+		{"too long", strings.Repeat("a", 33), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateCustomCategoryName(tt.category)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCustomCategoryName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEmoji(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		emoji   string
+		wantErr bool
+	}{
+		{"emoji", "🐶", false},
+		{"with variation selector", "✈️", false},
+		{"empty", "", true},
+		{"word", "dog", true},
+		{"two emojis with space", "🐶 🐱", true},
+		{"too long", strings.Repeat("🐶", 5), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateEmoji(tt.emoji)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEmoji() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateVehicleType(t *testing.T) {
 	validator := NewValidator()
 
@@ -331,6 +382,8 @@ func TestValidateVehicleType(t *testing.T) {
 	}{
 		{"valid car type", "CAR", false},
 		{"valid bike type", "BIKE", false},
+		{"valid scooter type", "SCOOTER", false},
+		{"valid other type", "OTHER", false},
 		{"invalid type", "TRUCK", true},
 		{"empty type", "", true},
 		{"lowercase car", "car", true},
@@ -353,6 +406,244 @@ func TestValidateVehicleType(t *testing.T) {
 	}
 }
 
+func TestValidateFuelType(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name     string
+		fuelType string
+		wantErr  bool
+	}{
+		{"petrol", "PETROL", false},
+		{"diesel", "DIESEL", false},
+		{"cng", "CNG", false},
+		{"electric", "ELECTRIC", false},
+		{"empty", "", true},
+		{"lowercase", "electric", true},
+		{"unknown", "HYDROGEN", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateFuelType(tt.fuelType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFuelType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLedgerName(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name       string
+		ledgerName string
+		wantErr    bool
+	}{
+		{"valid", "Household", false},
+		{"with spaces", "Goa Trip 2026", false},
+		{"empty", "", true},
+		{"whitespace", "   ", true},
+		{"too long", strings.Repeat("a", 51), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateLedgerName(tt.ledgerName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLedgerName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLedgerRole(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		role    string
+		wantErr bool
+	}{
+		{"owner", "OWNER", false},
+		{"editor", "EDITOR", false},
+		{"viewer", "VIEWER", false},
+		{"lowercase", "viewer", true},
+		{"unknown", "ADMIN", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateLedgerRole(tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLedgerRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBudgetPeriod(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		period  string
+		wantErr bool
+	}{
+		{"daily", "daily", false},
+		{"weekly", "weekly", false},
+		{"monthly", "monthly", false},
+		{"yearly", "yearly", false},
+		{"empty period", "", true},
+		{"uppercase", "MONTHLY", true},
+		{"unknown period", "quarterly", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateBudgetPeriod(tt.period)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBudgetPeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if appErr, ok := err.(*errors.AppError); ok && !appErr.IsValidationError() {
+					t.Errorf("ValidateBudgetPeriod() should return validation error, got %T", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRecurringInterval(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name     string
+		interval string
+		wantErr  bool
+	}{
+		{"daily", "daily", false},
+		{"weekly", "weekly", false},
+		{"monthly", "monthly", false},
+		{"yearly", "yearly", false},
+		{"empty interval", "", true},
+		{"unknown interval", "fortnightly", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateRecurringInterval(tt.interval)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRecurringInterval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReminderSchedule(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name      string
+		schedule  string
+		dayOfWeek int
+		wantErr   bool
+	}{
+		{"daily", "daily", -1, false},
+		{"weekly sunday", "weekly", 0, false},
+		{"weekly saturday", "weekly", 6, false},
+		{"weekly without day", "weekly", -1, true},
+		{"weekly invalid day", "weekly", 7, true},
+		{"unknown schedule", "hourly", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateReminderSchedule(tt.schedule, tt.dayOfWeek)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReminderSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTimeOfDay(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		hour    int
+		minute  int
+		wantErr bool
+	}{
+		{"midnight", 0, 0, false},
+		{"evening", 21, 30, false},
+		{"last minute", 23, 59, false},
+		{"hour too large", 24, 0, true},
+		{"negative minute", 12, -1, true},
+		{"minute too large", 12, 60, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateTimeOfDay(tt.hour, tt.minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTimeOfDay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReminderMessage(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		message string
+		wantErr bool
+	}{
+		{"valid message", "Log today's expenses", false},
+		{"empty message", "", true},
+		{"whitespace message", "   ", true},
+		{"message too long", strings.Repeat("a", 501), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateReminderMessage(tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReminderMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCurrencyCode(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{"valid code", "EUR", false},
+		{"lowercase code", "eur", true},
+		{"too short", "EU", true},
+		{"symbol", "€", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateCurrencyCode(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCurrencyCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateDate(t *testing.T) {
 	validator := NewValidator()
 	now := time.Now()
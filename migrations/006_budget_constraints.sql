@@ -0,0 +1,11 @@
+-- Migration: 006_budget_constraints.sql
+-- Description: Enforce one active budget per period and one limit per category
+-- Created: 2026-10-16
+
+-- Only one active budget per user and period; older budgets are kept as history
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_period_active
+    ON budgets(user_id, period) WHERE is_active;
+
+-- A budget has at most one limit per category so limits can be upserted
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budget_limits_budget_category
+    ON budget_limits(budget_id, category_id);
@@ -0,0 +1,15 @@
+-- Migration: 007_recurring_anchor_day.sql
+-- Description: Remember the day of month recurring expenses fall on
+-- Created: 2026-10-16
+
+-- Monthly and yearly occurrences clamp to shorter months (31st -> 28 Feb);
+-- anchor_day lets the next occurrence return to the original day
+ALTER TABLE recurring_expenses ADD COLUMN IF NOT EXISTS anchor_day SMALLINT
+    CHECK (anchor_day BETWEEN 1 AND 31);
+
+UPDATE recurring_expenses SET anchor_day = EXTRACT(DAY FROM next_due)
+WHERE anchor_day IS NULL;
+
+-- The scheduler only looks at active rows that are due
+CREATE INDEX IF NOT EXISTS idx_recurring_expenses_active_next_due
+    ON recurring_expenses(next_due) WHERE is_active;
@@ -0,0 +1,25 @@
+-- Migration: 008_add_reminders.sql
+-- Description: Add reminders pushed to users at a chosen time of day
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS reminders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    schedule TEXT NOT NULL CHECK (schedule IN ('daily', 'weekly')),
+    day_of_week SMALLINT CHECK (day_of_week BETWEEN 0 AND 6), -- 0 = Sunday, weekly reminders only
+    hour SMALLINT NOT NULL CHECK (hour BETWEEN 0 AND 23),
+    minute SMALLINT NOT NULL CHECK (minute BETWEEN 0 AND 59),
+    next_run TIMESTAMPTZ NOT NULL,
+    last_sent_at TIMESTAMPTZ,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (schedule <> 'weekly' OR day_of_week IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_user_id ON reminders(user_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_active_next_run ON reminders(next_run) WHERE is_active;
+
+CREATE TRIGGER update_reminders_updated_at BEFORE UPDATE ON reminders
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,16 @@
+-- Migration: 009_add_user_settings.sql
+-- Description: Add per-user display preferences (currency, date format, language, notifications)
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL DEFAULT 'INR',
+    date_format TEXT NOT NULL DEFAULT '02 Jan 2006', -- Go time layout
+    language TEXT NOT NULL DEFAULT 'en',
+    notifications_enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TRIGGER update_user_settings_updated_at BEFORE UPDATE ON user_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,31 @@
+-- Migration: 010_multi_currency.sql
+-- Description: Store the currency and original amount of each expense, and add exchange rates
+-- Created: 2026-10-16
+
+-- total_price stays in the user's home currency at the time the expense was recorded,
+-- so budgets and views keep working; currency/original_amount record what was paid
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'INR';
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS original_amount FLOAT;
+
+UPDATE expenses SET original_amount = total_price WHERE original_amount IS NULL;
+
+ALTER TABLE expenses ALTER COLUMN original_amount SET NOT NULL;
+
+-- 1 base_currency = rate quote_currency on rate_date
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+    rate_date DATE NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual', -- manual or csv
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (base_currency, quote_currency, rate_date),
+    CHECK (base_currency <> quote_currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_date ON exchange_rates(base_currency, quote_currency, rate_date DESC);
+
+CREATE TRIGGER update_exchange_rates_updated_at BEFORE UPDATE ON exchange_rates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,19 @@
+-- Migration: 011_import_profiles.sql
+-- Description: Add per-user bank statement column mappings for CSV import
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    date_column TEXT NOT NULL,
+    amount_column TEXT NOT NULL,
+    description_column TEXT NOT NULL,
+    date_format TEXT NOT NULL, -- Go time layout detected from the first import
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER update_import_profiles_updated_at BEFORE UPDATE ON import_profiles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,30 @@
+-- Migration: 012_embedding_jobs.sql
+-- Description: Add a queue for background expense embedding and a cache of embeddings by content
+-- Created: 2026-10-16
+
+-- One pending job per expense. Enqueuing an expense that is already queued resets it,
+-- so an edit made while a worker is embedding the old text is embedded again.
+CREATE TABLE IF NOT EXISTS embedding_jobs (
+    expense_id INTEGER PRIMARY KEY REFERENCES expenses(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- Next attempt, pushed back after each failure
+    locked_until TIMESTAMPTZ, -- Set while a worker holds the job
+    last_error TEXT,
+    failed_at TIMESTAMPTZ, -- Set when the job ran out of attempts
+    enqueued_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_jobs_run_at ON embedding_jobs(run_at) WHERE failed_at IS NULL;
+
+CREATE TRIGGER update_embedding_jobs_updated_at BEFORE UPDATE ON embedding_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Embeddings keyed by a hash of the embedder and the normalised text, so identical notes
+-- and category names are embedded once
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    content_hash TEXT PRIMARY KEY,
+    embedding vector(1536) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
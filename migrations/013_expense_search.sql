@@ -0,0 +1,8 @@
+-- Migration: 013_expense_search.sql
+-- Description: Add a full-text index on expense notes for hybrid search
+-- Created: 2026-10-16
+
+-- An expression index rather than a stored tsvector column, so expense queries that select
+-- e.* are unchanged. Search queries must use the same expression for the index to apply.
+CREATE INDEX IF NOT EXISTS idx_expenses_notes_fts ON expenses
+    USING GIN (to_tsvector('english', COALESCE(notes, '')));
@@ -0,0 +1,50 @@
+-- Migration: 014_vehicles.sql
+-- Description: Add named vehicles per user, link vehicle expenses to them and add EV charging
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS vehicles (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    vehicle_type TEXT NOT NULL CHECK (vehicle_type IN ('CAR', 'BIKE', 'SCOOTER', 'OTHER')),
+    fuel_type TEXT NOT NULL DEFAULT 'PETROL' CHECK (fuel_type IN ('PETROL', 'DIESEL', 'CNG', 'ELECTRIC')),
+    registration VARCHAR(20) NOT NULL DEFAULT '',
+    initial_odometer FLOAT NOT NULL DEFAULT 0 CHECK (initial_odometer >= 0), -- Reading when the vehicle was added
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Names are unique per user regardless of case, so "swift" finds "Swift"
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicles_user_name ON vehicles(user_id, lower(name));
+
+CREATE TRIGGER update_vehicles_updated_at BEFORE UPDATE ON vehicles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- vehicle_type stays on expenses as the type of the linked vehicle, for filters and for
+-- expenses whose vehicle was removed
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_vehicle_type_check;
+ALTER TABLE expenses ADD CONSTRAINT expenses_vehicle_type_check
+    CHECK (vehicle_type IN ('CAR', 'BIKE', 'SCOOTER', 'OTHER'));
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS vehicle_id INTEGER REFERENCES vehicles(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_vehicle_id ON expenses(vehicle_id) WHERE vehicle_id IS NOT NULL;
+
+-- Existing expenses: one vehicle per user and vehicle type used, named "Car" or "Bike"
+INSERT INTO vehicles (user_id, name, vehicle_type)
+SELECT DISTINCT user_id, CASE vehicle_type WHEN 'CAR' THEN 'Car' ELSE 'Bike' END, vehicle_type
+FROM expenses
+WHERE vehicle_type IS NOT NULL AND user_id IS NOT NULL
+ON CONFLICT (user_id, lower(name)) DO NOTHING;
+
+UPDATE expenses e
+SET vehicle_id = v.id
+FROM vehicles v
+WHERE e.vehicle_id IS NULL
+    AND e.vehicle_type IS NOT NULL
+    AND v.user_id = e.user_id
+    AND v.vehicle_type = e.vehicle_type
+    AND lower(v.name) = CASE e.vehicle_type WHEN 'CAR' THEN 'car' ELSE 'bike' END;
+
+-- Charging is recorded like a fuel fill, in kWh at a price per kWh
+INSERT INTO categories (name, emoji, "group") VALUES ('EV Charging', '🔌', 'Vehicle')
+ON CONFLICT (name) DO NOTHING;
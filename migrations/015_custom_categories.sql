@@ -0,0 +1,43 @@
+-- Migration: 015_custom_categories.sql
+-- Description: Add per-user custom categories and category groups alongside the built-in ones
+-- Created: 2026-10-16
+
+-- Category groups in keyboard order; user_id is NULL for the built-in groups
+CREATE TABLE IF NOT EXISTS category_groups (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    emoji TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Names are unique per user regardless of case; built-in groups count as user 0
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_groups_user_name ON category_groups(COALESCE(user_id, 0), lower(name));
+
+CREATE TRIGGER update_category_groups_updated_at BEFORE UPDATE ON category_groups
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO category_groups (name, emoji, position) VALUES
+('Vehicle', '🚗', 1),
+('Home', '🏠', 2),
+('Daily Living', '🏪', 3),
+('Entertainment', '🎬', 4),
+('Health', '🏥', 5),
+('Education', '📚', 6),
+('Travel', '✈️', 7),
+('Investments', '💰', 8),
+('Gifts', '🎁', 9),
+('Other', '📌', 10)
+ON CONFLICT DO NOTHING;
+
+-- Custom categories belong to a user; archived ones are hidden from keyboards and parsing but keep
+-- their expenses
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT false;
+
+-- Two users may each have a "Pets" category, so names are unique per user instead of globally
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_name ON categories(COALESCE(user_id, 0), lower(name));
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories(user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,34 @@
+-- Migration: 016_shared_ledgers.sql
+-- Description: Add shared ledgers that several users join with an invite code, with member roles
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS ledgers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    invite_code VARCHAR(16) NOT NULL UNIQUE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TRIGGER update_ledgers_updated_at BEFORE UPDATE ON ledgers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Owners manage members, editors add and change expenses, viewers only see reports.
+-- active marks the ledger a member's new expenses go to; at most one per user.
+CREATE TABLE IF NOT EXISTS ledger_members (
+    ledger_id INTEGER NOT NULL REFERENCES ledgers(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('OWNER', 'EDITOR', 'VIEWER')),
+    active BOOLEAN NOT NULL DEFAULT false,
+    joined_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (ledger_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_members_user_id ON ledger_members(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_members_active ON ledger_members(user_id) WHERE active;
+
+-- Expenses stay attributed to the member who paid; ledger_id adds them to a shared ledger.
+-- Removing a ledger turns its expenses back into their payers' personal expenses.
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS ledger_id INTEGER REFERENCES ledgers(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_ledger_id ON expenses(ledger_id, timestamp) WHERE ledger_id IS NOT NULL;
@@ -0,0 +1,32 @@
+-- Migration: 017_expense_splits.sql
+-- Description: Add expense splits between ledger members and settlements between them
+-- Created: 2026-10-16
+
+-- The share of a shared expense each participant owes, in the expense's total_price.
+-- method records how the split was entered: equally, by shares or by exact amounts.
+CREATE TABLE IF NOT EXISTS expense_splits (
+    expense_id INTEGER NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method TEXT NOT NULL CHECK (method IN ('EQUAL', 'SHARES', 'EXACT')),
+    shares FLOAT NOT NULL DEFAULT 1 CHECK (shares > 0),
+    amount FLOAT NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (expense_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_splits_user_id ON expense_splits(user_id);
+
+-- A payment from one ledger member to another that settles what they owe
+CREATE TABLE IF NOT EXISTS settlements (
+    id SERIAL PRIMARY KEY,
+    ledger_id INTEGER NOT NULL REFERENCES ledgers(id) ON DELETE CASCADE,
+    from_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount FLOAT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'INR',
+    note TEXT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (from_user_id <> to_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_ledger_id ON settlements(ledger_id, created_at);
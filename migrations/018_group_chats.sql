@@ -0,0 +1,7 @@
+-- Migration: 018_group_chats.sql
+-- Description: Link shared ledgers to Telegram group chats
+-- Created: 2026-10-16
+
+-- Expenses logged in a group chat go to the ledger linked to it; a chat has at most one.
+-- Group chat IDs are negative and can exceed 32 bits, hence BIGINT.
+ALTER TABLE ledgers ADD COLUMN IF NOT EXISTS chat_id BIGINT UNIQUE;
@@ -0,0 +1,17 @@
+-- Migration: 019_expense_attachments.sql
+-- Description: Add receipt photos and PDFs attached to expenses
+-- Created: 2026-10-16
+
+-- The file itself lives in the blob store under storage_key; this row records what it is.
+CREATE TABLE IF NOT EXISTS expense_attachments (
+    id SERIAL PRIMARY KEY,
+    expense_id INTEGER NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL CHECK (size_bytes > 0),
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_attachments_expense_id ON expense_attachments(expense_id);
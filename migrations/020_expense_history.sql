@@ -0,0 +1,23 @@
+-- Migration: 020_expense_history.sql
+-- Description: Add the audit history of expense changes behind /history, /undo and the trash
+-- Created: 2026-10-16
+
+-- Every change to an expense with the expense as it was before and after, and the user who
+-- made it. undo_of points at the change an /undo reverted; undone_at marks changes reverted.
+CREATE TABLE IF NOT EXISTS expense_history (
+    id SERIAL PRIMARY KEY,
+    expense_id INTEGER NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('CREATE', 'UPDATE', 'DELETE', 'RESTORE', 'IMPORT')),
+    before_snapshot JSONB,
+    after_snapshot JSONB,
+    undo_of INTEGER REFERENCES expense_history(id) ON DELETE SET NULL,
+    undone_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_history_expense_id ON expense_history(expense_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_expense_history_user_id ON expense_history(user_id, created_at DESC);
+
+-- The trash lists a user's deleted expenses, most recently deleted first
+CREATE INDEX IF NOT EXISTS idx_expenses_deleted ON expenses(user_id, deleted_at DESC) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Migration: 021_user_states.sql
+-- Description: Keep conversation state in the database so it survives restarts and is shared across replicas
+-- Created: 2026-10-16
+
+-- The conversation the bot is having with a user in a chat, such as an expense being added,
+-- serialized as JSON. A conversation idle past expires_at is over and is purged periodically.
+CREATE TABLE IF NOT EXISTS user_states (
+    chat_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    state JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (chat_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_states_expires_at ON user_states(expires_at);
@@ -166,6 +166,22 @@ run_migrations() {
             "003_add_budgets_table.sql"
             "004_add_views.sql"
             "005_add_pgvector.sql"
+            "006_budget_constraints.sql"
+            "007_recurring_anchor_day.sql"
+            "008_add_reminders.sql"
+            "009_add_user_settings.sql"
+            "010_multi_currency.sql"
+            "011_import_profiles.sql"
+            "012_embedding_jobs.sql"
+            "013_expense_search.sql"
+            "014_vehicles.sql"
+            "015_custom_categories.sql"
+            "016_shared_ledgers.sql"
+            "017_expense_splits.sql"
+            "018_group_chats.sql"
+            "019_expense_attachments.sql"
+            "020_expense_history.sql"
+            "021_user_states.sql"
         )
         
         for migration in "${MIGRATION_FILES[@]}"; do